@@ -21,6 +21,36 @@ type Config struct {
 		Secret string `yaml:"secret"`
 		TTL    int    `yaml:"ttl_seconds"`
 	} `yaml:"jwt"`
+	Barcode struct {
+		Prefix  string `yaml:"prefix"`
+		Pattern string `yaml:"pattern"`
+	} `yaml:"barcode"`
+	WorkOrder struct {
+		Prefix        string `yaml:"prefix"`
+		NumberPattern string `yaml:"number_pattern"`
+	} `yaml:"work_order"`
+	Storage struct {
+		Driver   string `yaml:"driver"`
+		LocalDir string `yaml:"local_dir"`
+		S3       struct {
+			Endpoint  string `yaml:"endpoint"`
+			Region    string `yaml:"region"`
+			Bucket    string `yaml:"bucket"`
+			AccessKey string `yaml:"access_key"`
+			SecretKey string `yaml:"secret_key"`
+			PathStyle bool   `yaml:"path_style"`
+		} `yaml:"s3"`
+	} `yaml:"storage"`
+	Attachment struct {
+		MaxSizeMB    int      `yaml:"max_size_mb"`
+		AllowedTypes []string `yaml:"allowed_types"`
+	} `yaml:"attachment"`
+	Calendar struct {
+		Timezone string `yaml:"timezone"`
+	} `yaml:"calendar"`
+	Incident struct {
+		EscalationInterval int `yaml:"escalation_interval_seconds"`
+	} `yaml:"incident"`
 }
 
 func LoadConfig(path string) (*Config, error) {
@@ -1,12 +1,14 @@
 package main
 
 import (
+	"context"
 	"fmt"
 	"log"
 	"log/slog"
 	"net/http"
 	"os"
 	"time"
+	_ "time/tzdata" // часовой пояс завода не зависит от tzdata образа
 
 	"github.com/go-chi/chi/v5"
 	"github.com/go-chi/chi/v5/middleware"
@@ -16,9 +18,26 @@ import (
 	httpSwagger "github.com/swaggo/http-swagger"
 
 	"mes-lite-back/internal/db"
+	"mes-lite-back/internal/features/attachment"
+	"mes-lite-back/internal/features/calendar"
+	"mes-lite-back/internal/features/execution"
+	"mes-lite-back/internal/features/hold"
+	"mes-lite-back/internal/features/incident"
+	"mes-lite-back/internal/features/instance"
+	"mes-lite-back/internal/features/machine"
+	"mes-lite-back/internal/features/ncr"
+	"mes-lite-back/internal/features/notification"
 	"mes-lite-back/internal/features/permission"
+	"mes-lite-back/internal/features/product"
+	"mes-lite-back/internal/features/quality"
 	"mes-lite-back/internal/features/role"
+	"mes-lite-back/internal/features/schedule"
+	"mes-lite-back/internal/features/skill"
+	"mes-lite-back/internal/features/stage"
 	"mes-lite-back/internal/features/user"
+	"mes-lite-back/internal/features/workorder"
+	authmw "mes-lite-back/internal/http/middleware"
+	"mes-lite-back/internal/storage"
 
 	config "mes-lite-back/cmd/config"
 
@@ -53,6 +72,21 @@ func main() {
 	refreshRepo := user.NewRefreshTokenRepository(dbConn)
 	roleRepo := role.NewGormRepository(dbConn)
 	permissionRepo := permission.NewGormRepository(dbConn)
+	productRepo := product.NewGormRepository(dbConn)
+	stageRepo := stage.NewGormRepository(dbConn)
+	instanceRepo := instance.NewGormRepository(dbConn)
+	executionRepo := execution.NewGormRepository(dbConn)
+	skillRepo := skill.NewGormRepository(dbConn)
+	workOrderRepo := workorder.NewGormRepository(dbConn)
+	notificationRepo := notification.NewGormRepository(dbConn)
+	scheduleRepo := schedule.NewGormRepository(dbConn)
+	calendarRepo := calendar.NewGormRepository(dbConn)
+	machineRepo := machine.NewGormRepository(dbConn)
+	incidentRepo := incident.NewGormRepository(dbConn)
+	attachmentRepo := attachment.NewGormRepository(dbConn)
+	qualityRepo := quality.NewGormRepository(dbConn)
+	holdRepo := hold.NewGormRepository(dbConn)
+	ncrRepo := ncr.NewGormRepository(dbConn)
 
 	userService := user.NewService(userRepo)
 
@@ -65,11 +99,86 @@ func main() {
 
 	roleService := role.NewService(roleRepo)
 	permissionService := permission.NewService(permissionRepo)
+	skillService := skill.NewService(skillRepo, permissionService)
+	productService := product.NewService(productRepo)
+	stageService := stage.NewService(stageRepo)
+	notificationService := notification.NewService(notificationRepo)
+	plantLocation, err := time.LoadLocation(cfg.Calendar.Timezone)
+	if err != nil {
+		log.Fatalf("invalid calendar timezone: %v", err)
+	}
+	calendarService := calendar.NewService(calendarRepo, plantLocation)
+	scheduleService := schedule.NewService(scheduleRepo, calendarService, notificationService)
+	machineService := machine.NewService(machineRepo, scheduleService)
+	incidentService := incident.NewService(incidentRepo, machineService, notificationService)
+	qualityService := quality.NewService(qualityRepo, incidentService)
+	executionService := execution.NewService(
+		executionRepo,
+		skillService,
+		authService,
+		permissionService,
+		qualityService,
+	)
+	holdService := hold.NewService(holdRepo, executionService)
+	ncrService := ncr.NewService(ncrRepo, permissionService, notificationService)
+
+	barcodePattern, err := instance.NewSerialPattern(cfg.Barcode.Pattern, cfg.Barcode.Prefix)
+	if err != nil {
+		log.Fatalf("invalid barcode pattern: %v", err)
+	}
+	instanceService := instance.NewService(instanceRepo, barcodePattern)
+
+	woNumberPattern, err := workorder.NewNumberPattern(cfg.WorkOrder.NumberPattern, cfg.WorkOrder.Prefix)
+	if err != nil {
+		log.Fatalf("invalid work order number pattern: %v", err)
+	}
+	workOrderService := workorder.NewService(
+		workOrderRepo,
+		woNumberPattern,
+		stageService,
+		permissionService,
+		notificationService,
+	)
+
+	fileStorage, err := storage.New(storage.Config{
+		Driver:   cfg.Storage.Driver,
+		LocalDir: cfg.Storage.LocalDir,
+		S3: storage.S3Config{
+			Endpoint:  cfg.Storage.S3.Endpoint,
+			Region:    cfg.Storage.S3.Region,
+			Bucket:    cfg.Storage.S3.Bucket,
+			AccessKey: cfg.Storage.S3.AccessKey,
+			SecretKey: cfg.Storage.S3.SecretKey,
+			PathStyle: cfg.Storage.S3.PathStyle,
+		},
+	})
+	if err != nil {
+		log.Fatalf("failed to init file storage: %v", err)
+	}
+	attachmentService := attachment.NewService(attachmentRepo, fileStorage, attachment.Limits{
+		MaxSize:      int64(cfg.Attachment.MaxSizeMB) << 20,
+		AllowedTypes: cfg.Attachment.AllowedTypes,
+	})
 
 	userHandler := user.NewHandler(userService)
 	authHandler := user.NewAuthHandler(authService)
 	roleHandler := role.NewHandler(roleService)
 	permissionHandler := permission.NewHandler(permissionService)
+	productHandler := product.NewHandler(productService, permissionService)
+	stageHandler := stage.NewHandler(stageService, permissionService)
+	instanceHandler := instance.NewHandler(instanceService, permissionService)
+	executionHandler := execution.NewHandler(executionService, permissionService)
+	skillHandler := skill.NewHandler(skillService, permissionService)
+	workOrderHandler := workorder.NewHandler(workOrderService, permissionService)
+	notificationHandler := notification.NewHandler(notificationService)
+	scheduleHandler := schedule.NewHandler(scheduleService, permissionService)
+	calendarHandler := calendar.NewHandler(calendarService, permissionService)
+	machineHandler := machine.NewHandler(machineService, permissionService)
+	incidentHandler := incident.NewHandler(incidentService, permissionService)
+	attachmentHandler := attachment.NewHandler(attachmentService, permissionService)
+	qualityHandler := quality.NewHandler(qualityService, permissionService)
+	holdHandler := hold.NewHandler(holdService, permissionService)
+	ncrHandler := ncr.NewHandler(ncrService, permissionService)
 
 	r := chi.NewRouter()
 
@@ -98,6 +207,111 @@ func main() {
 		r.Mount("/", permissionHandler.Routes())
 	})
 
+	apiRouter.Route("/users/{userID}/skills", func(r chi.Router) {
+		r.Use(authmw.AuthMiddleware(cfg.JWT.Secret))
+		r.Mount("/", skillHandler.Routes())
+	})
+
+	apiRouter.Route("/products", func(r chi.Router) {
+		r.Use(authmw.AuthMiddleware(cfg.JWT.Secret))
+		r.Mount("/", productHandler.Routes())
+	})
+
+	apiRouter.Route("/products/{productID}/routing", func(r chi.Router) {
+		r.Use(authmw.AuthMiddleware(cfg.JWT.Secret))
+		r.Mount("/", stageHandler.RoutingRoutes())
+	})
+
+	apiRouter.Route("/stages", func(r chi.Router) {
+		r.Use(authmw.AuthMiddleware(cfg.JWT.Secret))
+		r.Mount("/", stageHandler.Routes())
+	})
+
+	apiRouter.Route("/instances", func(r chi.Router) {
+		r.Use(authmw.AuthMiddleware(cfg.JWT.Secret))
+		r.Mount("/", instanceHandler.Routes())
+	})
+
+	apiRouter.Route("/work-orders", func(r chi.Router) {
+		r.Use(authmw.AuthMiddleware(cfg.JWT.Secret))
+		r.Mount("/", workOrderHandler.Routes())
+	})
+
+	apiRouter.Route("/work-orders/{id}/attachments", func(r chi.Router) {
+		r.Use(authmw.AuthMiddleware(cfg.JWT.Secret))
+		r.Mount("/", attachmentHandler.Routes(attachment.Parent{
+			Entity: attachment.EntityWorkOrder,
+			View:   "order.view",
+			Upload: "order.comment",
+			Remove: "order.edit",
+		}))
+	})
+
+	apiRouter.Route("/schedule", func(r chi.Router) {
+		r.Use(authmw.AuthMiddleware(cfg.JWT.Secret))
+		r.Mount("/", scheduleHandler.Routes())
+	})
+
+	apiRouter.Route("/calendar", func(r chi.Router) {
+		r.Use(authmw.AuthMiddleware(cfg.JWT.Secret))
+		r.Mount("/", calendarHandler.Routes())
+	})
+
+	apiRouter.Route("/machines", func(r chi.Router) {
+		r.Use(authmw.AuthMiddleware(cfg.JWT.Secret))
+		r.Mount("/", machineHandler.Routes())
+	})
+
+	apiRouter.Route("/incidents", func(r chi.Router) {
+		r.Use(authmw.AuthMiddleware(cfg.JWT.Secret))
+		r.Mount("/", incidentHandler.Routes())
+	})
+
+	apiRouter.Route("/incidents/{id}/attachments", func(r chi.Router) {
+		r.Use(authmw.AuthMiddleware(cfg.JWT.Secret))
+		r.Mount("/", attachmentHandler.Routes(attachment.Parent{
+			Entity: attachment.EntityIncident,
+			View:   "incident.view",
+			Upload: "incident.create",
+			Remove: "incident.edit",
+		}))
+	})
+
+	apiRouter.Route("/quality", func(r chi.Router) {
+		r.Use(authmw.AuthMiddleware(cfg.JWT.Secret))
+		r.Mount("/", qualityHandler.Routes())
+	})
+
+	apiRouter.Route("/quality/inspections/{id}/attachments", func(r chi.Router) {
+		r.Use(authmw.AuthMiddleware(cfg.JWT.Secret))
+		r.Mount("/", attachmentHandler.Routes(attachment.Parent{
+			Entity: attachment.EntityInspection,
+			View:   "quality.view",
+			Upload: "quality.inspect",
+			Remove: "quality.edit",
+		}))
+	})
+
+	apiRouter.Route("/holds", func(r chi.Router) {
+		r.Use(authmw.AuthMiddleware(cfg.JWT.Secret))
+		r.Mount("/", holdHandler.Routes())
+	})
+
+	apiRouter.Route("/ncr", func(r chi.Router) {
+		r.Use(authmw.AuthMiddleware(cfg.JWT.Secret))
+		r.Mount("/", ncrHandler.Routes())
+	})
+
+	apiRouter.Route("/notifications", func(r chi.Router) {
+		r.Use(authmw.AuthMiddleware(cfg.JWT.Secret))
+		r.Mount("/", notificationHandler.Routes())
+	})
+
+	apiRouter.Route("/executions", func(r chi.Router) {
+		r.Use(authmw.AuthMiddleware(cfg.JWT.Secret))
+		r.Mount("/", executionHandler.Routes())
+	})
+
 	r.Mount("/api/v1", apiRouter)
 
 	r.Get("/", func(w http.ResponseWriter, r *http.Request) {
@@ -105,6 +319,12 @@ func main() {
 		w.Write([]byte("MES Lite API v1.0"))
 	})
 
+	escalationInterval := time.Duration(cfg.Incident.EscalationInterval) * time.Second
+	if escalationInterval <= 0 {
+		escalationInterval = time.Minute
+	}
+	go incident.NewEscalator(incidentService, escalationInterval).Run(context.Background())
+
 	addr := fmt.Sprintf(":%d", cfg.Server.Port)
 	log.Printf("🚀 server started on %s", addr)
 
@@ -107,36 +107,72 @@ const docTemplate = `{
                 }
             }
         },
-        "/permissions": {
+        "/calendar/exceptions": {
             "get": {
-                "description": "Получить все разрешения",
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Праздники, плановые остановки и дополнительное рабочее время, пересекающиеся с периодом",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "permissions"
+                    "calendar"
+                ],
+                "summary": "Получить исключения календаря",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Начало периода (RFC3339 или ГГГГ-ММ-ДД)",
+                        "name": "from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Конец периода (RFC3339 или ГГГГ-ММ-ДД включительно)",
+                        "name": "to",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "ID линии",
+                        "name": "line_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Вид: holiday, shutdown, working",
+                        "name": "kind",
+                        "in": "query"
+                    }
                 ],
-                "summary": "Получить список разрешений",
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
                             "type": "array",
                             "items": {
-                                "$ref": "#/definitions/permission.PermissionResponse"
+                                "$ref": "#/definitions/calendar.Exception"
                             }
                         }
                     },
-                    "500": {
-                        "description": "Internal Server Error",
+                    "400": {
+                        "description": "Bad Request",
                         "schema": {
-                            "$ref": "#/definitions/permission.ErrorResponse"
+                            "$ref": "#/definitions/calendar.ErrorResponse"
                         }
                     }
                 }
             },
             "post": {
-                "description": "Создать новое разрешение",
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "holiday и shutdown исключают время из рабочего, working добавляет; без line_id — для всего завода",
                 "consumes": [
                     "application/json"
                 ],
@@ -144,17 +180,17 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "permissions"
+                    "calendar"
                 ],
-                "summary": "Создать разрешение",
+                "summary": "Добавить исключение календаря",
                 "parameters": [
                     {
-                        "description": "Данные разрешения",
-                        "name": "input",
+                        "description": "Исключение",
+                        "name": "request",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/permission.CreatePermissionRequest"
+                            "$ref": "#/definitions/calendar.ExceptionRequest"
                         }
                     }
                 ],
@@ -162,95 +198,31 @@ const docTemplate = `{
                     "201": {
                         "description": "Created",
                         "schema": {
-                            "$ref": "#/definitions/permission.PermissionResponse"
+                            "$ref": "#/definitions/calendar.Exception"
                         }
                     },
                     "400": {
                         "description": "Bad Request",
                         "schema": {
-                            "$ref": "#/definitions/permission.ErrorResponse"
-                        }
-                    },
-                    "500": {
-                        "description": "Internal Server Error",
-                        "schema": {
-                            "$ref": "#/definitions/permission.ErrorResponse"
-                        }
-                    }
-                }
-            }
-        },
-        "/permissions/name/{name}": {
-            "get": {
-                "description": "Получить разрешение по имени",
-                "produces": [
-                    "application/json"
-                ],
-                "tags": [
-                    "permissions"
-                ],
-                "summary": "Получить разрешение по имени",
-                "parameters": [
-                    {
-                        "type": "string",
-                        "description": "Имя разрешения",
-                        "name": "name",
-                        "in": "path",
-                        "required": true
-                    }
-                ],
-                "responses": {
-                    "200": {
-                        "description": "OK",
-                        "schema": {
-                            "$ref": "#/definitions/permission.PermissionResponse"
+                            "$ref": "#/definitions/calendar.ErrorResponse"
                         }
                     },
                     "404": {
                         "description": "Not Found",
                         "schema": {
-                            "$ref": "#/definitions/permission.ErrorResponse"
+                            "$ref": "#/definitions/calendar.ErrorResponse"
                         }
                     }
                 }
             }
         },
-        "/permissions/{id}": {
-            "get": {
-                "description": "Получить разрешение по идентификатору",
-                "produces": [
-                    "application/json"
-                ],
-                "tags": [
-                    "permissions"
-                ],
-                "summary": "Получить разрешение по ID",
-                "parameters": [
+        "/calendar/exceptions/{id}": {
+            "put": {
+                "security": [
                     {
-                        "type": "integer",
-                        "description": "ID разрешения",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
+                        "BearerAuth": []
                     }
                 ],
-                "responses": {
-                    "200": {
-                        "description": "OK",
-                        "schema": {
-                            "$ref": "#/definitions/permission.PermissionResponse"
-                        }
-                    },
-                    "404": {
-                        "description": "Not Found",
-                        "schema": {
-                            "$ref": "#/definitions/permission.ErrorResponse"
-                        }
-                    }
-                }
-            },
-            "put": {
-                "description": "Обновить данные разрешения",
                 "consumes": [
                     "application/json"
                 ],
@@ -258,24 +230,24 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "permissions"
+                    "calendar"
                 ],
-                "summary": "Обновить разрешение",
+                "summary": "Обновить исключение календаря",
                 "parameters": [
                     {
                         "type": "integer",
-                        "description": "ID разрешения",
+                        "description": "ID исключения",
                         "name": "id",
                         "in": "path",
                         "required": true
                     },
                     {
-                        "description": "Данные разрешения",
-                        "name": "input",
+                        "description": "Исключение",
+                        "name": "request",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/permission.UpdatePermissionRequest"
+                            "$ref": "#/definitions/calendar.ExceptionRequest"
                         }
                     }
                 ],
@@ -283,33 +255,37 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/permission.PermissionResponse"
+                            "$ref": "#/definitions/calendar.Exception"
                         }
                     },
                     "400": {
                         "description": "Bad Request",
                         "schema": {
-                            "$ref": "#/definitions/permission.ErrorResponse"
+                            "$ref": "#/definitions/calendar.ErrorResponse"
                         }
                     },
-                    "500": {
-                        "description": "Internal Server Error",
+                    "404": {
+                        "description": "Not Found",
                         "schema": {
-                            "$ref": "#/definitions/permission.ErrorResponse"
+                            "$ref": "#/definitions/calendar.ErrorResponse"
                         }
                     }
                 }
             },
             "delete": {
-                "description": "Удалить разрешение по ID",
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
                 "tags": [
-                    "permissions"
+                    "calendar"
                 ],
-                "summary": "Удалить разрешение",
+                "summary": "Удалить исключение календаря",
                 "parameters": [
                     {
                         "type": "integer",
-                        "description": "ID разрешения",
+                        "description": "ID исключения",
                         "name": "id",
                         "in": "path",
                         "required": true
@@ -319,54 +295,69 @@ const docTemplate = `{
                     "204": {
                         "description": "No Content"
                     },
-                    "400": {
-                        "description": "Bad Request",
-                        "schema": {
-                            "$ref": "#/definitions/permission.ErrorResponse"
-                        }
-                    },
-                    "500": {
-                        "description": "Internal Server Error",
+                    "404": {
+                        "description": "Not Found",
                         "schema": {
-                            "$ref": "#/definitions/permission.ErrorResponse"
+                            "$ref": "#/definitions/calendar.ErrorResponse"
                         }
                     }
                 }
             }
         },
-        "/roles": {
+        "/calendar/shifts": {
             "get": {
-                "description": "Возвращает список всех ролей с их разрешениями",
-                "consumes": [
-                    "application/json"
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
                 ],
+                "description": "Смены завода или линии; all=true — все смены",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "roles"
+                    "calendar"
+                ],
+                "summary": "Получить смены",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID линии",
+                        "name": "line_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Все смены, включая смены линий",
+                        "name": "all",
+                        "in": "query"
+                    }
                 ],
-                "summary": "Получить список всех ролей",
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
                             "type": "array",
                             "items": {
-                                "$ref": "#/definitions/role.Role"
+                                "$ref": "#/definitions/calendar.Shift"
                             }
                         }
                     },
-                    "500": {
-                        "description": "Internal Server Error",
+                    "404": {
+                        "description": "Not Found",
                         "schema": {
-                            "$ref": "#/definitions/role.ErrorResponse"
+                            "$ref": "#/definitions/calendar.ErrorResponse"
                         }
                     }
                 }
             },
             "post": {
-                "description": "Создает новую роль с указанными разрешениями",
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Время ЧЧ:ММ, окончание не позже начала — смена через полночь; weekdays — маска дней (пн=1 … вс=64)",
                 "consumes": [
                     "application/json"
                 ],
@@ -374,17 +365,17 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "roles"
+                    "calendar"
                 ],
-                "summary": "Создать новую роль",
+                "summary": "Создать смену",
                 "parameters": [
                     {
-                        "description": "Данные для создания роли",
+                        "description": "Смена",
                         "name": "request",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/role.CreateRequest"
+                            "$ref": "#/definitions/calendar.ShiftRequest"
                         }
                     }
                 ],
@@ -392,33 +383,31 @@ const docTemplate = `{
                     "201": {
                         "description": "Created",
                         "schema": {
-                            "$ref": "#/definitions/role.Role"
+                            "$ref": "#/definitions/calendar.Shift"
                         }
                     },
                     "400": {
                         "description": "Bad Request",
                         "schema": {
-                            "$ref": "#/definitions/role.ErrorResponse"
-                        }
-                    },
-                    "409": {
-                        "description": "Conflict",
-                        "schema": {
-                            "$ref": "#/definitions/role.ErrorResponse"
+                            "$ref": "#/definitions/calendar.ErrorResponse"
                         }
                     },
-                    "500": {
-                        "description": "Internal Server Error",
+                    "404": {
+                        "description": "Not Found",
                         "schema": {
-                            "$ref": "#/definitions/role.ErrorResponse"
+                            "$ref": "#/definitions/calendar.ErrorResponse"
                         }
                     }
                 }
             }
         },
-        "/roles/{id}": {
-            "get": {
-                "description": "Возвращает роль по указанному ID со списком разрешений",
+        "/calendar/shifts/{id}": {
+            "put": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
                 "consumes": [
                     "application/json"
                 ],
@@ -426,157 +415,189 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "roles"
+                    "calendar"
                 ],
-                "summary": "Получить роль по ID",
+                "summary": "Обновить смену",
                 "parameters": [
                     {
                         "type": "integer",
-                        "description": "ID роли",
+                        "description": "ID смены",
                         "name": "id",
                         "in": "path",
                         "required": true
+                    },
+                    {
+                        "description": "Смена",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/calendar.ShiftRequest"
+                        }
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/role.Role"
+                            "$ref": "#/definitions/calendar.Shift"
                         }
                     },
                     "400": {
                         "description": "Bad Request",
                         "schema": {
-                            "$ref": "#/definitions/role.ErrorResponse"
+                            "$ref": "#/definitions/calendar.ErrorResponse"
                         }
                     },
                     "404": {
                         "description": "Not Found",
                         "schema": {
-                            "$ref": "#/definitions/role.ErrorResponse"
-                        }
-                    },
-                    "500": {
-                        "description": "Internal Server Error",
-                        "schema": {
-                            "$ref": "#/definitions/role.ErrorResponse"
+                            "$ref": "#/definitions/calendar.ErrorResponse"
                         }
                     }
                 }
             },
-            "put": {
-                "description": "Обновляет информацию о роли и её разрешения",
-                "consumes": [
-                    "application/json"
-                ],
-                "produces": [
-                    "application/json"
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
                 ],
                 "tags": [
-                    "roles"
+                    "calendar"
                 ],
-                "summary": "Обновить роль",
+                "summary": "Удалить смену",
                 "parameters": [
                     {
                         "type": "integer",
-                        "description": "ID роли",
+                        "description": "ID смены",
                         "name": "id",
                         "in": "path",
                         "required": true
-                    },
-                    {
-                        "description": "Данные для обновления роли",
-                        "name": "request",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/role.UpdateRequest"
-                        }
                     }
                 ],
                 "responses": {
-                    "200": {
-                        "description": "OK",
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "404": {
+                        "description": "Not Found",
                         "schema": {
-                            "$ref": "#/definitions/role.Role"
-                        }
-                    },
-                    "400": {
-                        "description": "Bad Request",
-                        "schema": {
-                            "$ref": "#/definitions/role.ErrorResponse"
+                            "$ref": "#/definitions/calendar.ErrorResponse"
                         }
+                    }
+                }
+            }
+        },
+        "/calendar/working-time": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Рабочие периоды и их сумма в минутах с учетом смен, праздников, остановок и исключений линии",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "calendar"
+                ],
+                "summary": "Рабочее время за период",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Начало периода (RFC3339 или ГГГГ-ММ-ДД)",
+                        "name": "from",
+                        "in": "query",
+                        "required": true
                     },
-                    "404": {
-                        "description": "Not Found",
+                    {
+                        "type": "string",
+                        "description": "Конец периода (RFC3339 или ГГГГ-ММ-ДД включительно)",
+                        "name": "to",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "ID линии; без него — общезаводской календарь",
+                        "name": "line_id",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/role.ErrorResponse"
+                            "$ref": "#/definitions/calendar.WorkingTime"
                         }
                     },
-                    "409": {
-                        "description": "Conflict",
+                    "400": {
+                        "description": "Bad Request",
                         "schema": {
-                            "$ref": "#/definitions/role.ErrorResponse"
+                            "$ref": "#/definitions/calendar.ErrorResponse"
                         }
                     },
-                    "500": {
-                        "description": "Internal Server Error",
+                    "404": {
+                        "description": "Not Found",
                         "schema": {
-                            "$ref": "#/definitions/role.ErrorResponse"
+                            "$ref": "#/definitions/calendar.ErrorResponse"
                         }
                     }
                 }
-            },
-            "delete": {
-                "description": "Удаляет роль по указанному ID",
-                "consumes": [
-                    "application/json"
+            }
+        },
+        "/executions": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
                 ],
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "roles"
+                    "executions"
                 ],
-                "summary": "Удалить роль",
+                "summary": "История выполнения этапов экземпляра",
                 "parameters": [
                     {
-                        "type": "integer",
-                        "description": "ID роли",
-                        "name": "id",
-                        "in": "path",
+                        "type": "string",
+                        "description": "Штрихкод экземпляра",
+                        "name": "barcode",
+                        "in": "query",
                         "required": true
                     }
                 ],
                 "responses": {
-                    "204": {
-                        "description": "No Content"
-                    },
-                    "400": {
-                        "description": "Bad Request",
+                    "200": {
+                        "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/role.ErrorResponse"
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/execution.Execution"
+                            }
                         }
                     },
                     "404": {
                         "description": "Not Found",
                         "schema": {
-                            "$ref": "#/definitions/role.ErrorResponse"
-                        }
-                    },
-                    "500": {
-                        "description": "Internal Server Error",
-                        "schema": {
-                            "$ref": "#/definitions/role.ErrorResponse"
+                            "$ref": "#/definitions/execution.ErrorResponse"
                         }
                     }
                 }
             }
         },
-        "/roles/{id}/permissions": {
-            "get": {
-                "description": "Возвращает список разрешений для указанной роли",
+        "/executions/disposition": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Решение по последнему браковочному результату контроля экземпляра: rework возвращает экземпляр на пройденный этап маршрута не позже этапа контроля (выполнения этого и последующих этапов заменяются, этапы проходятся заново), scrap списывает экземпляр в брак с причиной. Решение принимается один раз, при незавершенном этапе — 409",
                 "consumes": [
                     "application/json"
                 ],
@@ -584,50 +605,56 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "roles"
+                    "executions"
                 ],
-                "summary": "Получить разрешения роли",
+                "summary": "Решение по браку",
                 "parameters": [
                     {
-                        "type": "integer",
-                        "description": "ID роли",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
+                        "description": "Решение",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/execution.DispositionRequest"
+                        }
                     }
                 ],
                 "responses": {
-                    "200": {
-                        "description": "OK",
+                    "201": {
+                        "description": "Created",
                         "schema": {
-                            "type": "array",
-                            "items": {
-                                "$ref": "#/definitions/permission.Permission"
-                            }
+                            "$ref": "#/definitions/execution.Disposition"
                         }
                     },
                     "400": {
                         "description": "Bad Request",
                         "schema": {
-                            "$ref": "#/definitions/role.ErrorResponse"
+                            "$ref": "#/definitions/execution.ErrorResponse"
                         }
                     },
                     "404": {
                         "description": "Not Found",
                         "schema": {
-                            "$ref": "#/definitions/role.ErrorResponse"
+                            "$ref": "#/definitions/execution.ErrorResponse"
                         }
                     },
-                    "500": {
-                        "description": "Internal Server Error",
+                    "409": {
+                        "description": "Conflict",
                         "schema": {
-                            "$ref": "#/definitions/role.ErrorResponse"
+                            "$ref": "#/definitions/execution.ErrorResponse"
                         }
                     }
                 }
-            },
-            "put": {
-                "description": "Полностью заменяет список разрешений для указанной роли",
+            }
+        },
+        "/executions/finish": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Закрывает открытое выполнение этапа для экземпляра по штрихкоду",
                 "consumes": [
                     "application/json"
                 ],
@@ -635,24 +662,17 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "roles"
+                    "executions"
                 ],
-                "summary": "Обновить разрешения роли",
+                "summary": "Завершить этап",
                 "parameters": [
                     {
-                        "type": "integer",
-                        "description": "ID роли",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
-                    },
-                    {
-                        "description": "Список ID разрешений",
+                        "description": "Штрихкод и этап",
                         "name": "request",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/role.UpdateRequest"
+                            "$ref": "#/definitions/execution.ScanRequest"
                         }
                     }
                 ],
@@ -660,463 +680,12797 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/role.SuccessResponse"
+                            "$ref": "#/definitions/execution.Execution"
                         }
                     },
                     "400": {
                         "description": "Bad Request",
                         "schema": {
-                            "$ref": "#/definitions/role.ErrorResponse"
+                            "$ref": "#/definitions/execution.ErrorResponse"
                         }
                     },
                     "404": {
                         "description": "Not Found",
                         "schema": {
-                            "$ref": "#/definitions/role.ErrorResponse"
+                            "$ref": "#/definitions/execution.ErrorResponse"
                         }
                     },
-                    "500": {
-                        "description": "Internal Server Error",
+                    "409": {
+                        "description": "Conflict",
                         "schema": {
-                            "$ref": "#/definitions/role.ErrorResponse"
+                            "$ref": "#/definitions/execution.ErrorResponse"
                         }
                     }
                 }
             }
         },
-        "/users": {
+        "/executions/reworks": {
             "get": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Возвращает список всех пользователей",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "users"
+                    "executions"
+                ],
+                "summary": "Доработки экземпляра",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Штрихкод экземпляра",
+                        "name": "barcode",
+                        "in": "query",
+                        "required": true
+                    }
                 ],
-                "summary": "Получить список пользователей",
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
                             "type": "array",
                             "items": {
-                                "$ref": "#/definitions/user.User"
+                                "$ref": "#/definitions/execution.Rework"
                             }
                         }
                     },
-                    "500": {
-                        "description": "internal error",
+                    "404": {
+                        "description": "Not Found",
                         "schema": {
-                            "type": "string"
+                            "$ref": "#/definitions/execution.ErrorResponse"
                         }
                     }
                 }
-            },
-            "post": {
+            }
+        },
+        "/executions/scrap-reasons": {
+            "get": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Создает нового пользователя",
-                "consumes": [
-                    "application/json"
-                ],
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "users"
+                    "executions"
                 ],
-                "summary": "Создать пользователя",
+                "summary": "Справочник причин брака",
                 "parameters": [
                     {
-                        "description": "Данные пользователя",
-                        "name": "request",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/user.CreateRequest"
-                        }
+                        "type": "boolean",
+                        "description": "Только активные",
+                        "name": "active",
+                        "in": "query"
                     }
                 ],
                 "responses": {
-                    "201": {
-                        "description": "Created"
-                    },
-                    "400": {
-                        "description": "bad request",
+                    "200": {
+                        "description": "OK",
                         "schema": {
-                            "type": "string"
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/execution.ScrapReason"
+                            }
                         }
                     }
                 }
             }
         },
-        "/users/{id}": {
-            "get": {
+        "/executions/start": {
+            "post": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Возвращает пользователя по ID",
+                "description": "Открывает выполнение этапа для экземпляра по штрихкоду; исполнитель берется из JWT.\nОператору без действующей квалификации нужен допуск мастера (поле override).\nПосле завершенного этапа с обязательным планом контроля экземпляр идет дальше только с годным результатом контроля (иначе 423).\nЭкземпляр в блокировке (карантине) или забракованный при ее снятии на этапы не запускается (423); списанный в брак — 409.\nВыполнение этапа не раньше этапа возврата последней доработки ссылается на нее (rework_id).",
+                "consumes": [
+                    "application/json"
+                ],
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "users"
+                    "executions"
                 ],
-                "summary": "Получить пользователя",
+                "summary": "Начать этап",
                 "parameters": [
                     {
-                        "type": "integer",
-                        "description": "User ID",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
+                        "description": "Штрихкод и этап",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/execution.ScanRequest"
+                        }
                     }
                 ],
                 "responses": {
-                    "200": {
-                        "description": "OK",
+                    "201": {
+                        "description": "Created",
                         "schema": {
-                            "$ref": "#/definitions/user.User"
+                            "$ref": "#/definitions/execution.Execution"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/execution.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/execution.ErrorResponse"
                         }
                     },
                     "404": {
-                        "description": "not found",
+                        "description": "Not Found",
                         "schema": {
-                            "type": "string"
+                            "$ref": "#/definitions/execution.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/execution.SequenceErrorResponse"
+                        }
+                    },
+                    "423": {
+                        "description": "Locked",
+                        "schema": {
+                            "$ref": "#/definitions/execution.InspectionErrorResponse"
                         }
                     }
                 }
-            },
-            "put": {
+            }
+        },
+        "/holds": {
+            "get": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Обновляет данные пользователя",
-                "consumes": [
-                    "application/json"
-                ],
+                "description": "Новые первыми; фильтры по статусу, штрихкоду и заказу экземпляра, машине",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "users"
+                    "holds"
                 ],
-                "summary": "Обновить пользователя",
+                "summary": "Получить блокировки",
                 "parameters": [
                     {
-                        "type": "integer",
-                        "description": "User ID",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
+                        "type": "string",
+                        "description": "Статус: active, released",
+                        "name": "status",
+                        "in": "query"
                     },
                     {
-                        "description": "Данные пользователя",
+                        "type": "string",
+                        "description": "Штрихкод экземпляра",
+                        "name": "barcode",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "ID заказа экземпляра",
+                        "name": "work_order_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "ID машины блокировки",
+                        "name": "machine_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Размер страницы (не более 200)",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Смещение",
+                        "name": "offset",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/hold.Hold"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/hold.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Укажите ровно одно: barcodes, work_order_id или machine_id с окном from–to. По машине блокируются экземпляры ее заказов, этапы которых выполнялись в окне. Состав фиксируется при постановке; заблокированные экземпляры не запускаются на этапы и не отгружаются",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "holds"
+                ],
+                "summary": "Поставить блокировку",
+                "parameters": [
+                    {
+                        "description": "Блокировка",
                         "name": "request",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/user.CreateRequest"
+                            "$ref": "#/definitions/hold.Placement"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/hold.Hold"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/hold.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/hold.ErrorResponse"
                         }
                     }
+                }
+            }
+        },
+        "/holds/{id}": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Блокировка с экземплярами и решениями по снятым",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "holds"
+                ],
+                "summary": "Получить блокировку по ID",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID блокировки",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
                 ],
                 "responses": {
                     "200": {
-                        "description": "OK"
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/hold.Hold"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/hold.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/holds/{id}/events": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "holds"
+                ],
+                "summary": "Журнал блокировки",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID блокировки",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/hold.Event"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/hold.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/holds/{id}/release": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Снимает указанные экземпляры (без barcodes — все оставшиеся) с решением use_as_is, rework с этапом возврата stage_id или scrap с причиной scrap_reason_id. Доработка и списание записываются как решения по браку и учитываются в выходе годных. Блокировка закрывается, когда сняты все экземпляры",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "holds"
+                ],
+                "summary": "Снять блокировку",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID блокировки",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Решение",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/hold.Release"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/hold.Hold"
+                        }
                     },
                     "400": {
-                        "description": "bad request",
+                        "description": "Bad Request",
                         "schema": {
-                            "type": "string"
+                            "$ref": "#/definitions/hold.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/hold.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/hold.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/incidents": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Новые первыми; фильтры по статусу, серьезности, машине, заказу и исполнителю",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "incidents"
+                ],
+                "summary": "Получить инциденты",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Статус: open, in_progress, resolved",
+                        "name": "status",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "ID серьезности",
+                        "name": "severity_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "ID машины",
+                        "name": "machine_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "ID заказа",
+                        "name": "work_order_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "ID исполнителя",
+                        "name": "assigned_to",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Размер страницы (не более 200)",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Смещение",
+                        "name": "offset",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/incident.Incident"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/incident.ErrorResponse"
                         }
                     }
                 }
-            },
-            "delete": {
-                "security": [
-                    {
-                        "BearerAuth": []
+            },
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Инцидент высокой серьезности (или с stop_machine) переводит машину в статус down, что запускает перепланирование ее слотов",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "incidents"
+                ],
+                "summary": "Зарегистрировать инцидент",
+                "parameters": [
+                    {
+                        "description": "Инцидент",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/incident.Report"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/incident.Incident"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/incident.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/incident.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/incidents/escalation-rules": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "incidents"
+                ],
+                "summary": "Правила эскалации инцидентов",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID серьезности",
+                        "name": "severity_id",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/incident.EscalationRule"
+                            }
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Через after_min минут после регистрации нерешенного инцидента уведомляются пользователи роли и/или пользователь; stop_on=assigned отключает правило после назначения исполнителя",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "incidents"
+                ],
+                "summary": "Добавить правило эскалации",
+                "parameters": [
+                    {
+                        "description": "Правило",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/incident.RuleRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/incident.EscalationRule"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/incident.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/incident.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/incidents/escalation-rules/{id}": {
+            "put": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Уже сработавшие по инцидентам эскалации не повторяются",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "incidents"
+                ],
+                "summary": "Изменить правило эскалации",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID правила",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Правило",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/incident.RuleRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/incident.EscalationRule"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/incident.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/incident.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Срабатывания правила удаляются вместе с ним; записи в журнале инцидентов сохраняются",
+                "tags": [
+                    "incidents"
+                ],
+                "summary": "Удалить правило эскалации",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID правила",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/incident.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/incidents/metrics": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Число инцидентов, назначенных, решенных и эскалированных, MTTA и MTTR в минутах по инцидентам, зарегистрированным за период; в целом, по серьезности и по линиям",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "incidents"
+                ],
+                "summary": "Показатели инцидентов",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Начало периода (RFC3339 или ГГГГ-ММ-ДД)",
+                        "name": "from",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Конец периода (RFC3339 или ГГГГ-ММ-ДД включительно)",
+                        "name": "to",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/incident.Metrics"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/incident.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/incidents/severities": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "incidents"
+                ],
+                "summary": "Справочник серьезности инцидентов",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/incident.Severity"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/incidents/{id}": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "incidents"
+                ],
+                "summary": "Получить инцидент по ID",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID инцидента",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/incident.Incident"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/incident.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Решенный инцидент изменить нельзя; машину нельзя сменить, если инцидент ее остановил",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "incidents"
+                ],
+                "summary": "Изменить инцидент",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID инцидента",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Инцидент",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/incident.Edit"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/incident.Incident"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/incident.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/incident.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/incident.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/incidents/{id}/assign": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Открытый инцидент переходит в статус in_progress; assigned_at фиксирует первое назначение и при переназначении не меняется",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "incidents"
+                ],
+                "summary": "Назначить исполнителя",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID инцидента",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Исполнитель",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/incident.AssignRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/incident.Incident"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/incident.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/incident.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/incident.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/incidents/{id}/attachments": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "В порядке загрузки",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "attachments"
+                ],
+                "summary": "Вложения объекта",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID инцидента, заказа или результата контроля",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/attachment.Attachment"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/attachment.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Фото, PDF или текст; тип определяется по содержимому. Для изображений строится уменьшенная копия",
+                "consumes": [
+                    "multipart/form-data"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "attachments"
+                ],
+                "summary": "Прикрепить файл",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID инцидента, заказа или результата контроля",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "file",
+                        "description": "Файл",
+                        "name": "file",
+                        "in": "formData",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/attachment.Attachment"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/attachment.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/attachment.ErrorResponse"
+                        }
+                    },
+                    "413": {
+                        "description": "Request Entity Too Large",
+                        "schema": {
+                            "$ref": "#/definitions/attachment.ErrorResponse"
+                        }
+                    },
+                    "415": {
+                        "description": "Unsupported Media Type",
+                        "schema": {
+                            "$ref": "#/definitions/attachment.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/incidents/{id}/attachments/{attachmentID}": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Изображения и PDF отдаются для просмотра в браузере, остальные — как загрузка",
+                "produces": [
+                    "application/octet-stream"
+                ],
+                "tags": [
+                    "attachments"
+                ],
+                "summary": "Скачать файл",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID инцидента, заказа или результата контроля",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "ID вложения",
+                        "name": "attachmentID",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "file"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/attachment.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "tags": [
+                    "attachments"
+                ],
+                "summary": "Удалить вложение",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID инцидента, заказа или результата контроля",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "ID вложения",
+                        "name": "attachmentID",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/attachment.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/incidents/{id}/attachments/{attachmentID}/thumbnail": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "JPEG не больше 320 пикселей по большей стороне; есть только у вложений с has_thumbnail",
+                "produces": [
+                    "image/jpeg"
+                ],
+                "tags": [
+                    "attachments"
+                ],
+                "summary": "Уменьшенная копия изображения",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID инцидента, заказа или результата контроля",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "ID вложения",
+                        "name": "attachmentID",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "file"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/attachment.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/incidents/{id}/escalations": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "incidents"
+                ],
+                "summary": "История эскалаций инцидента",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID инцидента",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/incident.Escalation"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/incident.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/incidents/{id}/events": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Регистрация, изменения, назначения, решение и повторные открытия в хронологическом порядке",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "incidents"
+                ],
+                "summary": "Журнал инцидента",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID инцидента",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/incident.Event"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/incident.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/incidents/{id}/reopen": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Снимает решение; инцидент с исполнителем возвращается в in_progress, без него — в open",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "incidents"
+                ],
+                "summary": "Открыть инцидент повторно",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID инцидента",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Причина",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/incident.ReopenRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/incident.Incident"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/incident.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/incident.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/incident.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/incidents/{id}/resolve": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Устанавливает resolved_at; restore_machine возвращает остановленную инцидентом машину в статус idle",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "incidents"
+                ],
+                "summary": "Решить инцидент",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID инцидента",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Решение",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/incident.Resolution"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/incident.Incident"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/incident.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/incident.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/incident.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/instances": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "instances"
+                ],
+                "summary": "Получить список экземпляров продукции",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID продукта",
+                        "name": "product_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "ID заказа",
+                        "name": "work_order_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Количество записей",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Смещение",
+                        "name": "offset",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/instance.Instance"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/instance.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Выдает следующий серийный штрихкод для продукта",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "instances"
+                ],
+                "summary": "Создать экземпляр продукции",
+                "parameters": [
+                    {
+                        "description": "Продукт",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/instance.CreateRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/instance.Instance"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/instance.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/instance.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/instances/barcode/{barcode}": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "instances"
+                ],
+                "summary": "Найти экземпляр по штрихкоду",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Штрихкод",
+                        "name": "barcode",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/instance.Instance"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/instance.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/instances/barcode/{barcode}/image": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Рисует штрихкод экземпляра в Code128 или DataMatrix, в SVG или PNG",
+                "produces": [
+                    "image/svg+xml",
+                    "image/png"
+                ],
+                "tags": [
+                    "instances"
+                ],
+                "summary": "Изображение штрихкода",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Штрихкод",
+                        "name": "barcode",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "code128 (по умолчанию) или datamatrix",
+                        "name": "symbology",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "svg (по умолчанию) или png",
+                        "name": "format",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Размер модуля в пикселях",
+                        "name": "module",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Высота Code128 в модулях",
+                        "name": "height",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "file"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/instance.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/instance.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/instances/bulk": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Выдает count серийных штрихкодов для продукта рабочего заказа",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "instances"
+                ],
+                "summary": "Создать экземпляры для заказа",
+                "parameters": [
+                    {
+                        "description": "Заказ и количество",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/instance.BulkCreateRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/instance.Instance"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/instance.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/instance.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/instance.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/instances/ship": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Отмечает отгрузку экземпляров по штрихкодам: все или ни одного. Экземпляры в блокировке или забракованные при ее снятии не отгружаются (423), списанные в брак — 409",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "instances"
+                ],
+                "summary": "Отгрузить экземпляры",
+                "parameters": [
+                    {
+                        "description": "Штрихкоды",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/instance.ShipRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/instance.Instance"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/instance.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/instance.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/instance.ErrorResponse"
+                        }
+                    },
+                    "423": {
+                        "description": "Locked",
+                        "schema": {
+                            "$ref": "#/definitions/instance.HoldErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/instances/{id}": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "instances"
+                ],
+                "summary": "Получить экземпляр по ID",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID экземпляра",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/instance.Instance"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/instance.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/machines/statuses": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "machines"
+                ],
+                "summary": "Справочник статусов машин",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/machine.Status"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/machines/{id}/status": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Закрывает текущий период статуса и открывает новый; наладку можно привязать к заказу. Перевод в down, maintenance или offline создает предложение перепланирования слотов машины",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "machines"
+                ],
+                "summary": "Изменить статус машины",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID машины",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Новый статус",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/machine.StatusChange"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/machine.Machine"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/machine.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/machine.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/machine.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/machines/{id}/status-history": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Периоды статусов, пересекающиеся с интервалом",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "machines"
+                ],
+                "summary": "История статусов машины",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID машины",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Начало периода (RFC3339 или ГГГГ-ММ-ДД)",
+                        "name": "from",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Конец периода (RFC3339 или ГГГГ-ММ-ДД включительно)",
+                        "name": "to",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/machine.StatusHistory"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/machine.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/machine.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/machines/{id}/time-summary": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Минуты выпуска, наладки, простоя и недоступности за период; наладка считается отдельно от выпуска",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "machines"
+                ],
+                "summary": "Время машины по статусам",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID машины",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Начало периода (RFC3339 или ГГГГ-ММ-ДД)",
+                        "name": "from",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Конец периода (RFC3339 или ГГГГ-ММ-ДД включительно)",
+                        "name": "to",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/machine.TimeSummary"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/machine.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/machine.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/ncr": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Новые первыми; фильтры по статусу, штрихкоду затронутого экземпляра, заказу, инциденту и коду дефекта",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "ncr"
+                ],
+                "summary": "Получить отчеты о несоответствии",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Статус: open, pending_approval, in_progress, verification, verified, closed, cancelled",
+                        "name": "status",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Штрихкод экземпляра",
+                        "name": "barcode",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "ID заказа",
+                        "name": "work_order_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "ID инцидента",
+                        "name": "incident_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "ID кода дефекта",
+                        "name": "defect_code_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Размер страницы (не более 200)",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Смещение",
+                        "name": "offset",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/ncr.NCR"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/ncr.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Номер выдается автоматически (NCR-\u003cгод\u003e-\u003cномер\u003e); затронутые экземпляры указываются штрихкодами",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "ncr"
+                ],
+                "summary": "Зарегистрировать отчет о несоответствии",
+                "parameters": [
+                    {
+                        "description": "Отчет",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/ncr.Report"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/ncr.NCR"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/ncr.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/ncr.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/ncr/actions": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Действия незакрытых отчетов в порядке срока; overdue=true — невыполненные с прошедшим сроком",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "ncr"
+                ],
+                "summary": "Действия CAPA",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID ответственного",
+                        "name": "owner_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Статус: open, done",
+                        "name": "status",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Только просроченные",
+                        "name": "overdue",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Размер страницы (не более 200)",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Смещение",
+                        "name": "offset",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/ncr.Action"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/ncr.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/ncr/{id}": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Отчет со связями, планом CAPA и решениями по утверждению",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "ncr"
+                ],
+                "summary": "Получить отчет о несоответствии по ID",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID отчета",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/ncr.NCR"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/ncr.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Описание меняется, пока план CAPA не отправлен на утверждение",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "ncr"
+                ],
+                "summary": "Изменить отчет о несоответствии",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID отчета",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Описание",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/ncr.Edit"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/ncr.NCR"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/ncr.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/ncr.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/ncr.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/ncr/{id}/actions": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "План меняется, пока не отправлен на утверждение",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "ncr"
+                ],
+                "summary": "Добавить действие CAPA",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID отчета",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Действие",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/ncr.ActionInput"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/ncr.NCR"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/ncr.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/ncr.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/ncr.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/ncr/{id}/actions/{actionID}": {
+            "put": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "ncr"
+                ],
+                "summary": "Изменить действие CAPA",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID отчета",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "ID действия",
+                        "name": "actionID",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Действие",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/ncr.ActionInput"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/ncr.NCR"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/ncr.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/ncr.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/ncr.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "ncr"
+                ],
+                "summary": "Удалить действие CAPA",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID отчета",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "ID действия",
+                        "name": "actionID",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/ncr.NCR"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/ncr.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/ncr.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/ncr/{id}/actions/{actionID}/complete": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Выполняет ответственный или пользователь с правом ncr.create; после последнего действия отчет переходит к проверке эффективности",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "ncr"
+                ],
+                "summary": "Выполнить действие CAPA",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID отчета",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "ID действия",
+                        "name": "actionID",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Результат",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/ncr.CompleteRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/ncr.NCR"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/ncr.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/ncr.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/ncr.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/ncr.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/ncr/{id}/approve": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Утверждающий не может быть автором плана; ответственные за действия получают уведомление",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "ncr"
+                ],
+                "summary": "Утвердить план CAPA",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID отчета",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Комментарий",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/ncr.CommentRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/ncr.NCR"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/ncr.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/ncr.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/ncr.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/ncr/{id}/cancel": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Отменяется отчет, план CAPA которого не утвержден; комментарий обязателен",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "ncr"
+                ],
+                "summary": "Отменить отчет о несоответствии",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID отчета",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Причина отмены",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/ncr.CommentRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/ncr.NCR"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/ncr.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/ncr.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/ncr.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/ncr/{id}/close": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Закрывается отчет с подтвержденной эффективностью действий",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "ncr"
+                ],
+                "summary": "Закрыть отчет о несоответствии",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID отчета",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Комментарий",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/ncr.CommentRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/ncr.NCR"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/ncr.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/ncr.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/ncr/{id}/events": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "ncr"
+                ],
+                "summary": "Журнал отчета о несоответствии",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID отчета",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/ncr.Event"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/ncr.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/ncr/{id}/links": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Добавляет затронутые экземпляры, заказы и инциденты к незакрытому отчету; существующие связи не дублируются",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "ncr"
+                ],
+                "summary": "Добавить связи отчета",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID отчета",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Связи",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/ncr.LinkRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/ncr.NCR"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/ncr.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/ncr.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/ncr.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/ncr/{id}/reject": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Возвращает отчет на доработку плана; комментарий обязателен",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "ncr"
+                ],
+                "summary": "Отклонить план CAPA",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID отчета",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Причина отклонения",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/ncr.CommentRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/ncr.NCR"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/ncr.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/ncr.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/ncr.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/ncr.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/ncr/{id}/root-cause": {
+            "put": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "ncr"
+                ],
+                "summary": "Указать коренную причину",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID отчета",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Коренная причина",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/ncr.RootCauseRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/ncr.NCR"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/ncr.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/ncr.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/ncr.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/ncr/{id}/submit": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Нужны коренная причина и хотя бы одно невыполненное корректирующее действие",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "ncr"
+                ],
+                "summary": "Отправить план CAPA на утверждение",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID отчета",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Комментарий",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/ncr.CommentRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/ncr.NCR"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/ncr.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/ncr.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/ncr.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/ncr/{id}/verify": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Эффективные действия позволяют закрыть отчет; неэффективные (комментарий обязателен) возвращают его в open для нового плана",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "ncr"
+                ],
+                "summary": "Проверка эффективности действий",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID отчета",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Итог проверки",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/ncr.Verification"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/ncr.NCR"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/ncr.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/ncr.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/ncr.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/notifications": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "notifications"
+                ],
+                "summary": "Уведомления текущего пользователя",
+                "parameters": [
+                    {
+                        "type": "boolean",
+                        "description": "Только непрочитанные",
+                        "name": "unread",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Количество записей (не более 200)",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Смещение",
+                        "name": "offset",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/notification.Notification"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/notification.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/notifications/read-all": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "tags": [
+                    "notifications"
+                ],
+                "summary": "Отметить все уведомления прочитанными",
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/notification.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/notifications/unread-count": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "notifications"
+                ],
+                "summary": "Количество непрочитанных уведомлений",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/notification.UnreadCountResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/notification.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/notifications/{id}/read": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "tags": [
+                    "notifications"
+                ],
+                "summary": "Отметить уведомление прочитанным",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID уведомления",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/notification.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/permissions": {
+            "get": {
+                "description": "Получить все разрешения",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "permissions"
+                ],
+                "summary": "Получить список разрешений",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/permission.PermissionResponse"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/permission.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "Создать новое разрешение",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "permissions"
+                ],
+                "summary": "Создать разрешение",
+                "parameters": [
+                    {
+                        "description": "Данные разрешения",
+                        "name": "input",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/permission.CreatePermissionRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/permission.PermissionResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/permission.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/permission.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/permissions/name/{name}": {
+            "get": {
+                "description": "Получить разрешение по имени",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "permissions"
+                ],
+                "summary": "Получить разрешение по имени",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Имя разрешения",
+                        "name": "name",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/permission.PermissionResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/permission.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/permissions/{id}": {
+            "get": {
+                "description": "Получить разрешение по идентификатору",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "permissions"
+                ],
+                "summary": "Получить разрешение по ID",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID разрешения",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/permission.PermissionResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/permission.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "description": "Обновить данные разрешения",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "permissions"
+                ],
+                "summary": "Обновить разрешение",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID разрешения",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Данные разрешения",
+                        "name": "input",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/permission.UpdatePermissionRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/permission.PermissionResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/permission.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/permission.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "Удалить разрешение по ID",
+                "tags": [
+                    "permissions"
+                ],
+                "summary": "Удалить разрешение",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID разрешения",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/permission.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/permission.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/products": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Возвращает список продукции с поиском по названию или SKU",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "products"
+                ],
+                "summary": "Получить список продукции",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Поиск по названию или SKU",
+                        "name": "q",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Количество записей",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Смещение",
+                        "name": "offset",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/product.Product"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/product.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Создает новый продукт; автором становится текущий пользователь",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "products"
+                ],
+                "summary": "Создать продукт",
+                "parameters": [
+                    {
+                        "description": "Данные продукта",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/product.CreateRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/product.Product"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/product.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/product.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/product.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/products/{id}": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Возвращает карточку продукта",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "products"
+                ],
+                "summary": "Получить продукт по ID",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID продукта",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/product.Product"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/product.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/product.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/product.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Обновляет название, SKU и время такта продукта",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "products"
+                ],
+                "summary": "Обновить продукт",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID продукта",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Данные продукта",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/product.CreateRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/product.Product"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/product.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/product.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/product.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/product.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Удаляет продукт вместе с маршрутом, если на него не ссылаются заказы, экземпляры и результаты контроля",
+                "tags": [
+                    "products"
+                ],
+                "summary": "Удалить продукт",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID продукта",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/product.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/product.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/product.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/product.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/products/{productID}/routing": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Возвращает этапы версии маршрута в порядке выполнения с ожидаемым временем цикла; по умолчанию действующую версию",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "routing"
+                ],
+                "summary": "Получить маршрут продукта",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID продукта",
+                        "name": "productID",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Номер версии",
+                        "name": "version",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/stage.Routing"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/stage.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Атомарно заменяет маршрут в черновике версии; stage_order должен идти подряд с 1",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "routing"
+                ],
+                "summary": "Заменить маршрут продукта",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID продукта",
+                        "name": "productID",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Шаги маршрута",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/stage.ReplaceRoutingRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/stage.Routing"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/stage.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/stage.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/products/{productID}/routing/diff": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Возвращает добавленные, удаленные и измененные этапы между версиями from и to",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "routing"
+                ],
+                "summary": "Сравнить версии маршрута",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID продукта",
+                        "name": "productID",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Исходная версия",
+                        "name": "from",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Новая версия",
+                        "name": "to",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/stage.RoutingDiff"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/stage.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/stage.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/products/{productID}/routing/order": {
+            "put": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Задает новый порядок этапов черновика списком ID этапов",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "routing"
+                ],
+                "summary": "Изменить порядок этапов",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID продукта",
+                        "name": "productID",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Этапы в новом порядке",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/stage.ReorderRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/stage.Routing"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/stage.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/stage.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/products/{productID}/routing/steps": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Вставляет этап в черновик на позицию stage_order со сдвигом последующих; без позиции — в конец",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "routing"
+                ],
+                "summary": "Вставить этап в маршрут",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID продукта",
+                        "name": "productID",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Этап и позиция",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/stage.RoutingStep"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/stage.Routing"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/stage.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/stage.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/stage.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/products/{productID}/routing/steps/{stageID}": {
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Удаляет этап из черновика и перенумеровывает оставшиеся",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "routing"
+                ],
+                "summary": "Удалить этап из маршрута",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID продукта",
+                        "name": "productID",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "ID этапа",
+                        "name": "stageID",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/stage.Routing"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/stage.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/products/{productID}/routing/versions": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "routing"
+                ],
+                "summary": "Получить версии маршрута",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID продукта",
+                        "name": "productID",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/stage.RoutingVersion"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/stage.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/products/{productID}/routing/versions/{version}": {
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "tags": [
+                    "routing"
+                ],
+                "summary": "Удалить черновик маршрута",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID продукта",
+                        "name": "productID",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Номер версии",
+                        "name": "version",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/stage.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/stage.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/products/{productID}/routing/versions/{version}/release": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Переводит черновик в действующую версию; прежняя действующая становится устаревшей. Автор и последний редактор черновика утвердить его не могут",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "routing"
+                ],
+                "summary": "Утвердить версию маршрута",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID продукта",
+                        "name": "productID",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Номер версии",
+                        "name": "version",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/stage.Routing"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/stage.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/stage.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/stage.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/quality/defect-codes": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "quality"
+                ],
+                "summary": "Справочник кодов дефектов",
+                "parameters": [
+                    {
+                        "type": "boolean",
+                        "description": "Только активные коды",
+                        "name": "active",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/quality.DefectCode"
+                            }
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Код приводится к верхнему регистру",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "quality"
+                ],
+                "summary": "Добавить код дефекта",
+                "parameters": [
+                    {
+                        "description": "Код дефекта",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/quality.DefectCodeRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/quality.DefectCode"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/quality.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/quality.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/quality/defect-codes/{id}": {
+            "put": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Вместо удаления код отключается (active=false); записанные результаты контроля не меняются",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "quality"
+                ],
+                "summary": "Изменить код дефекта",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID кода дефекта",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Код дефекта",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/quality.DefectCodeRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/quality.DefectCode"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/quality.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/quality.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/quality.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/quality/inspections": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Новые первыми; фильтры по заказу, продукту, экземпляру, результату, этапу, машине, дефекту, контролеру и периоду",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "quality"
+                ],
+                "summary": "Получить результаты контроля",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID заказа",
+                        "name": "work_order_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "ID продукта",
+                        "name": "product_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "ID экземпляра",
+                        "name": "instance_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Штрихкод экземпляра",
+                        "name": "barcode",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Результат: passed, failed, conditional",
+                        "name": "status",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "ID этапа",
+                        "name": "stage_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "ID машины",
+                        "name": "machine_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "ID кода дефекта",
+                        "name": "defect_code_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "ID контролера",
+                        "name": "inspected_by",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Начало периода (RFC3339 или ГГГГ-ММ-ДД)",
+                        "name": "from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Конец периода (RFC3339 или ГГГГ-ММ-ДД включительно)",
+                        "name": "to",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Размер страницы (не более 200)",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Смещение",
+                        "name": "offset",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/quality.Inspection"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/quality.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Экземпляр ищется по штрихкоду, контролер — текущий пользователь, машина по умолчанию — машина заказа. Для failed и conditional нужен хотя бы один код дефекта, для passed дефекты не указываются. С stage_id результат вычисляется по измерениям плана контроля этапа, status не указывается",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "quality"
+                ],
+                "summary": "Записать результат контроля",
+                "parameters": [
+                    {
+                        "description": "Результат контроля",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/quality.Record"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/quality.Inspection"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/quality.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/quality.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/quality/inspections/{id}": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "quality"
+                ],
+                "summary": "Получить результат контроля по ID",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID результата контроля",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/quality.Inspection"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/quality.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/quality/inspections/{id}/attachments": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "В порядке загрузки",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "attachments"
+                ],
+                "summary": "Вложения объекта",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID инцидента, заказа или результата контроля",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/attachment.Attachment"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/attachment.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Фото, PDF или текст; тип определяется по содержимому. Для изображений строится уменьшенная копия",
+                "consumes": [
+                    "multipart/form-data"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "attachments"
+                ],
+                "summary": "Прикрепить файл",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID инцидента, заказа или результата контроля",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "file",
+                        "description": "Файл",
+                        "name": "file",
+                        "in": "formData",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/attachment.Attachment"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/attachment.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/attachment.ErrorResponse"
+                        }
+                    },
+                    "413": {
+                        "description": "Request Entity Too Large",
+                        "schema": {
+                            "$ref": "#/definitions/attachment.ErrorResponse"
+                        }
+                    },
+                    "415": {
+                        "description": "Unsupported Media Type",
+                        "schema": {
+                            "$ref": "#/definitions/attachment.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/quality/inspections/{id}/attachments/{attachmentID}": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Изображения и PDF отдаются для просмотра в браузере, остальные — как загрузка",
+                "produces": [
+                    "application/octet-stream"
+                ],
+                "tags": [
+                    "attachments"
+                ],
+                "summary": "Скачать файл",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID инцидента, заказа или результата контроля",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "ID вложения",
+                        "name": "attachmentID",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "file"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/attachment.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "tags": [
+                    "attachments"
+                ],
+                "summary": "Удалить вложение",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID инцидента, заказа или результата контроля",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "ID вложения",
+                        "name": "attachmentID",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/attachment.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/quality/inspections/{id}/attachments/{attachmentID}/thumbnail": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "JPEG не больше 320 пикселей по большей стороне; есть только у вложений с has_thumbnail",
+                "produces": [
+                    "image/jpeg"
+                ],
+                "tags": [
+                    "attachments"
+                ],
+                "summary": "Уменьшенная копия изображения",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID инцидента, заказа или результата контроля",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "ID вложения",
+                        "name": "attachmentID",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "file"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/attachment.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/quality/plans": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "quality"
+                ],
+                "summary": "Планы контроля",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID продукта",
+                        "name": "product_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "ID этапа",
+                        "name": "stage_id",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/quality.Plan"
+                            }
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Один план на этап маршрута продукта. Характеристики: numeric (nominal, tol_minus, tol_plus), boolean (expected, по умолчанию true), choice (options с признаком accepted). Обязательный план не пускает экземпляр на следующие этапы без годного результата",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "quality"
+                ],
+                "summary": "Добавить план контроля этапа",
+                "parameters": [
+                    {
+                        "description": "План контроля",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/quality.PlanRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/quality.Plan"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/quality.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/quality.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/quality.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/quality/plans/{id}": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "quality"
+                ],
+                "summary": "Получить план контроля по ID",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID плана",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/quality.Plan"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/quality.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Характеристики с id изменяются и остаются на своих контрольных картах, без id — добавляются, не переданные — удаляются; записанные измерения хранят копии прежних характеристик",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "quality"
+                ],
+                "summary": "Изменить план контроля",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID плана",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "План контроля",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/quality.PlanRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/quality.Plan"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/quality.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/quality.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/quality.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Результаты контроля по плану сохраняются",
+                "tags": [
+                    "quality"
+                ],
+                "summary": "Удалить план контроля",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID плана",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/quality.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/quality/spc": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Карта по числовым измерениям характеристики плана за период, по одной машине или по всем. Тип individuals (I-MR), xbar-r или xbar-s; без chart выбирается по subgroup_size (по умолчанию 5). Возвращает границы, Cp/Cpk и Pp/Ppk по допуску и нарушения правил Western Electric",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "quality"
+                ],
+                "summary": "Контрольная карта характеристики",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID характеристики плана",
+                        "name": "characteristic_id",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "ID машины",
+                        "name": "machine_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Начало периода (RFC3339 или ГГГГ-ММ-ДД)",
+                        "name": "from",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Конец периода (RFC3339 или ГГГГ-ММ-ДД включительно)",
+                        "name": "to",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Тип карты: individuals, xbar-r, xbar-s",
+                        "name": "chart",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Размер подгруппы: 1 для individuals, 2–10 для xbar-r, 2–25 для xbar-s",
+                        "name": "subgroup_size",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/quality.ControlChart"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/quality.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/quality.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/quality/spc/check": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Строит карту как GET /quality/spc. Если в точках после последней проверки с инцидентом есть нарушения правил Western Electric, регистрирует по ним инцидент на машину карты; повторная проверка без новых точек инцидент не создает",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "quality"
+                ],
+                "summary": "Проверить контрольную карту и зарегистрировать инцидент",
+                "parameters": [
+                    {
+                        "description": "Параметры карты",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/quality.SPCCheckRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/quality.SPCCheck"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/quality.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/quality.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/quality/statuses": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "quality"
+                ],
+                "summary": "Справочник результатов контроля",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/quality.Status"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/roles": {
+            "get": {
+                "description": "Возвращает список всех ролей с их разрешениями",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "roles"
+                ],
+                "summary": "Получить список всех ролей",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/role.Role"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/role.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "Создает новую роль с указанными разрешениями",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "roles"
+                ],
+                "summary": "Создать новую роль",
+                "parameters": [
+                    {
+                        "description": "Данные для создания роли",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/role.CreateRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/role.Role"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/role.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/role.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/role.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/roles/{id}": {
+            "get": {
+                "description": "Возвращает роль по указанному ID со списком разрешений",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "roles"
+                ],
+                "summary": "Получить роль по ID",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID роли",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/role.Role"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/role.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/role.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/role.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "description": "Обновляет информацию о роли и её разрешения",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "roles"
+                ],
+                "summary": "Обновить роль",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID роли",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Данные для обновления роли",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/role.UpdateRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/role.Role"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/role.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/role.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/role.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/role.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "Удаляет роль по указанному ID",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "roles"
+                ],
+                "summary": "Удалить роль",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID роли",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/role.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/role.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/role.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/roles/{id}/permissions": {
+            "get": {
+                "description": "Возвращает список разрешений для указанной роли",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "roles"
+                ],
+                "summary": "Получить разрешения роли",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID роли",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/permission.Permission"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/role.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/role.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/role.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "description": "Полностью заменяет список разрешений для указанной роли",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "roles"
+                ],
+                "summary": "Обновить разрешения роли",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID роли",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Список ID разрешений",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/role.UpdateRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/role.SuccessResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/role.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/role.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/role.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/schedule": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Возвращает слоты, пересекающиеся с периодом, с фильтрами по машине, линии и заказу",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "schedule"
+                ],
+                "summary": "Получить слоты расписания",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Начало периода (RFC3339 или ГГГГ-ММ-ДД)",
+                        "name": "from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Конец периода (RFC3339 или ГГГГ-ММ-ДД включительно)",
+                        "name": "to",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "ID машины",
+                        "name": "machine_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "ID линии",
+                        "name": "line_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "ID заказа",
+                        "name": "work_order_id",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/schedule.Slot"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/schedule.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Создает слот; пересечение с другими слотами машины возвращает 409 со списком конфликтов",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "schedule"
+                ],
+                "summary": "Запланировать заказ на машину",
+                "parameters": [
+                    {
+                        "description": "Слот",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/schedule.SlotRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/schedule.Slot"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/schedule.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/schedule.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/schedule.ConflictResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/schedule/auto/commit": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Заново строит предложение по параметрам предпросмотра (from — начало из ответа предпросмотра) и сверяет его с присланными слотами; при расхождении возвращает 409. Снимаемые слоты удаляются, предложенные создаются одной транзакцией",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "schedule"
+                ],
+                "summary": "Зафиксировать предложение",
+                "parameters": [
+                    {
+                        "description": "Параметры и слоты предпросмотра",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/schedule.CommitRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/schedule.Slot"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/schedule.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/schedule.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/schedule/auto/compare": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Опоздания, размещенность заказов и загрузка машин текущего и предлагаемого расписания на общем горизонте",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "schedule"
+                ],
+                "summary": "Сравнить расписание с предложением",
+                "parameters": [
+                    {
+                        "description": "Параметры планирования",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/schedule.PlanRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/schedule.Comparison"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/schedule.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/schedule/auto/preview": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Размещает выпущенные заказы на допустимых машинах с учетом приоритета, срока, такта и занятости; расписание не меняется",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "schedule"
+                ],
+                "summary": "Предложение автопланирования",
+                "parameters": [
+                    {
+                        "description": "Параметры планирования",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/schedule.PlanRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/schedule.Plan"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/schedule.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/schedule/changeovers": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Строки матрицы с фильтрами по машине, линии и продукту (исходному или целевому)",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "schedule"
+                ],
+                "summary": "Матрица переналадок",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID машины",
+                        "name": "machine_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "ID линии",
+                        "name": "line_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "ID продукта",
+                        "name": "product_id",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/schedule.Changeover"
+                            }
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Время переналадки с продукта на продукт для машины, линии или всего завода",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "schedule"
+                ],
+                "summary": "Добавить строку матрицы переналадок",
+                "parameters": [
+                    {
+                        "description": "Строка матрицы",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/schedule.ChangeoverRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/schedule.Changeover"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/schedule.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/schedule.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/schedule.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/schedule/changeovers/lookup": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Действующее время переналадки: строка машины, затем линии машины, затем общезаводская; без строки — 0",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "schedule"
+                ],
+                "summary": "Время переналадки машины",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID машины",
+                        "name": "machine_id",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "ID текущего продукта",
+                        "name": "from_product_id",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "ID следующего продукта",
+                        "name": "to_product_id",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/schedule.ChangeoverTime"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/schedule.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/schedule.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/schedule/changeovers/{id}": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "schedule"
+                ],
+                "summary": "Получить строку матрицы переналадок",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID строки",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/schedule.Changeover"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/schedule.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "schedule"
+                ],
+                "summary": "Изменить строку матрицы переналадок",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID строки",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Строка матрицы",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/schedule.ChangeoverRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/schedule.Changeover"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/schedule.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/schedule.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/schedule.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "tags": [
+                    "schedule"
+                ],
+                "summary": "Удалить строку матрицы переналадок",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID строки",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/schedule.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/schedule/check": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Возвращает слоты той же машины, с которыми пересекся бы слот; ничего не сохраняет",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "schedule"
+                ],
+                "summary": "Проверить слот на пересечения",
+                "parameters": [
+                    {
+                        "description": "Слот",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/schedule.SlotRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/schedule.Slot"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/schedule.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/schedule.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/schedule/gantt": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Слоты за период, сгруппированные по линиям и машинам; свободные машины включаются с пустым списком",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "schedule"
+                ],
+                "summary": "Диаграмма Ганта",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Начало периода (RFC3339 или ГГГГ-ММ-ДД)",
+                        "name": "from",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Конец периода (RFC3339 или ГГГГ-ММ-ДД включительно)",
+                        "name": "to",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "ID линии",
+                        "name": "line_id",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/schedule.Gantt"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/schedule.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/schedule/products/{productID}/machines": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "schedule"
+                ],
+                "summary": "Допустимые машины продукта",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID продукта",
+                        "name": "productID",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "type": "integer"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/schedule.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Заменяет список машин, на которых автопланирование может размещать заказы продукта",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "schedule"
+                ],
+                "summary": "Задать допустимые машины продукта",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID продукта",
+                        "name": "productID",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Машины",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/schedule.ProductMachinesRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "type": "integer"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/schedule.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/schedule.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/schedule/proposals": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Предложения по остановленным машинам с изменениями по заказам и влиянием на сроки; новые первыми",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "schedule"
+                ],
+                "summary": "Предложения перепланирования",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID машины",
+                        "name": "machine_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Статус: pending, committed, rejected, superseded",
+                        "name": "status",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/schedule.Proposal"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/schedule.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Переносит предстоящие слоты машины на другие допустимые машины или сдвигает после available_from; расписание меняется только после подтверждения. Создается автоматически при переводе машины в недоступный статус",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "schedule"
+                ],
+                "summary": "Перепланировать слоты машины",
+                "parameters": [
+                    {
+                        "description": "Машина и прогноз ее возврата",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/schedule.RescheduleRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/schedule.Proposal"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/schedule.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/schedule.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/schedule.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/schedule/proposals/{id}": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "schedule"
+                ],
+                "summary": "Получить предложение перепланирования",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID предложения",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/schedule.Proposal"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/schedule.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/schedule/proposals/{id}/commit": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Снимает слоты размещенных заказов с машины (начатые обрезаются) и создает новые одной транзакцией; если расписание машины изменилось, возвращает 409",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "schedule"
+                ],
+                "summary": "Подтвердить перепланирование",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID предложения",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/schedule.Proposal"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/schedule.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/schedule.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/schedule/proposals/{id}/reject": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "schedule"
+                ],
+                "summary": "Отклонить перепланирование",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID предложения",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/schedule.Proposal"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/schedule.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/schedule.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/schedule/{id}": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "schedule"
+                ],
+                "summary": "Получить слот по ID",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID слота",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/schedule.Slot"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/schedule.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Меняет заказ, машину или время слота с проверкой пересечений",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "schedule"
+                ],
+                "summary": "Перенести слот",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID слота",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Слот",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/schedule.SlotRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/schedule.Slot"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/schedule.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/schedule.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/schedule.ConflictResponse"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "tags": [
+                    "schedule"
+                ],
+                "summary": "Удалить слот",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID слота",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/schedule.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/stages": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Возвращает справочник производственных этапов",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "stages"
+                ],
+                "summary": "Получить список этапов",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/stage.Stage"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/stage.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "stages"
+                ],
+                "summary": "Создать этап",
+                "parameters": [
+                    {
+                        "description": "Данные этапа",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/stage.CreateRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/stage.Stage"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/stage.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/stage.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/stages/{id}": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "stages"
+                ],
+                "summary": "Получить этап по ID",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID этапа",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/stage.Stage"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/stage.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/stage.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "stages"
+                ],
+                "summary": "Обновить этап",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID этапа",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Данные этапа",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/stage.CreateRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/stage.Stage"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/stage.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/stage.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Удаляет этап, если он не используется в маршрутах и исполнении",
+                "tags": [
+                    "stages"
+                ],
+                "summary": "Удалить этап",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID этапа",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/stage.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/stage.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/users": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Возвращает список всех пользователей",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "summary": "Получить список пользователей",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/user.User"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "internal error",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Создает нового пользователя",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "summary": "Создать пользователя",
+                "parameters": [
+                    {
+                        "description": "Данные пользователя",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/user.CreateRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created"
+                    },
+                    "400": {
+                        "description": "bad request",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/users/{id}": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Возвращает пользователя по ID",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "summary": "Получить пользователя",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "User ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/user.User"
+                        }
+                    },
+                    "404": {
+                        "description": "not found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Обновляет данные пользователя",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "summary": "Обновить пользователя",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "User ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Данные пользователя",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/user.CreateRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK"
+                    },
+                    "400": {
+                        "description": "bad request",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Удаляет пользователя по ID",
+                "tags": [
+                    "users"
+                ],
+                "summary": "Удалить пользователя",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "User ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "404": {
+                        "description": "not found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/users/{userID}/skills": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Возвращает матрицу квалификаций пользователя по этапам",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "skills"
+                ],
+                "summary": "Квалификации пользователя",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID пользователя",
+                        "name": "userID",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/skill.Skill"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/skill.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Добавляет квалификацию пользователя на этапе; уровни 1–2 требуют подтверждения наставником",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "skills"
+                ],
+                "summary": "Добавить квалификацию",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID пользователя",
+                        "name": "userID",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Квалификация",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/skill.SkillRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/skill.Skill"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/skill.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/skill.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/skill.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/users/{userID}/skills/{stageID}": {
+            "put": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Меняет уровень и срок аттестации; при смене уровня подтверждение наставника сбрасывается",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "skills"
+                ],
+                "summary": "Обновить квалификацию",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID пользователя",
+                        "name": "userID",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "ID этапа",
+                        "name": "stageID",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Квалификация",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/skill.SkillRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/skill.Skill"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/skill.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/skill.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "tags": [
+                    "skills"
+                ],
+                "summary": "Удалить квалификацию",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID пользователя",
+                        "name": "userID",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "ID этапа",
+                        "name": "stageID",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/skill.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/users/{userID}/skills/{stageID}/sign-off": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Подтвержденный наставник (уровень 3 на этом этапе) подтверждает квалификацию пользователя любого уровня. Первого наставника этапа подтверждает мастер с правом stage.override",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "skills"
+                ],
+                "summary": "Подтвердить квалификацию",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID пользователя",
+                        "name": "userID",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "ID этапа",
+                        "name": "stageID",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/skill.Skill"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/skill.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/skill.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/work-orders": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Возвращает заказы с фильтрами по статусу, приоритету, станку, продукту и сроку",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "work-orders"
+                ],
+                "summary": "Получить список заказов",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Поиск по номеру заказа",
+                        "name": "q",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "ID статуса",
+                        "name": "status_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "ID приоритета",
+                        "name": "priority_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "ID станка",
+                        "name": "machine_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "ID продукта",
+                        "name": "product_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Срок не раньше (ГГГГ-ММ-ДД)",
+                        "name": "deadline_from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Срок не позже (ГГГГ-ММ-ДД)",
+                        "name": "deadline_to",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Количество записей",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Смещение",
+                        "name": "offset",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/workorder.WorkOrder"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/workorder.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/workorder.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Создает заказ в статусе «Запланирован»; без wo_number номер генерируется по шаблону",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "work-orders"
+                ],
+                "summary": "Создать заказ",
+                "parameters": [
+                    {
+                        "description": "Данные заказа",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/workorder.CreateRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/workorder.WorkOrder"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/workorder.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/workorder.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/workorder.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/work-orders/priorities": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "work-orders"
+                ],
+                "summary": "Справочник приоритетов заказов",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/workorder.Priority"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/workorder.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/work-orders/statuses": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "work-orders"
+                ],
+                "summary": "Справочник статусов заказов",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/workorder.Status"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/workorder.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/work-orders/workflow": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Переходы workflow с требуемыми правами и условиями; to = 0 — возврат в статус до приостановки",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "work-orders"
+                ],
+                "summary": "Схема статусов заказа",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/workorder.Transition"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/work-orders/{id}": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "work-orders"
+                ],
+                "summary": "Получить заказ по ID",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID заказа",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/workorder.WorkOrder"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/workorder.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/workorder.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/workorder.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Обновляет продукт, станок, количество, приоритет и срок; статус меняется отдельно",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "work-orders"
+                ],
+                "summary": "Обновить заказ",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID заказа",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Данные заказа",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/workorder.UpdateRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/workorder.WorkOrder"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/workorder.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/workorder.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/workorder.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/workorder.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Удаляет запланированный или отмененный заказ без экземпляров, расписания и инцидентов",
+                "tags": [
+                    "work-orders"
+                ],
+                "summary": "Удалить заказ",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID заказа",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/workorder.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/workorder.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/workorder.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/workorder.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/work-orders/{id}/activity": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Смены статусов и комментарии в хронологическом порядке",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "work-orders"
+                ],
+                "summary": "Лента активности заказа",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID заказа",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/workorder.ActivityEvent"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/workorder.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/workorder.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/work-orders/{id}/attachments": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "В порядке загрузки",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "attachments"
+                ],
+                "summary": "Вложения объекта",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID инцидента, заказа или результата контроля",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/attachment.Attachment"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/attachment.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Фото, PDF или текст; тип определяется по содержимому. Для изображений строится уменьшенная копия",
+                "consumes": [
+                    "multipart/form-data"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "attachments"
+                ],
+                "summary": "Прикрепить файл",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID инцидента, заказа или результата контроля",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "file",
+                        "description": "Файл",
+                        "name": "file",
+                        "in": "formData",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/attachment.Attachment"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/attachment.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/attachment.ErrorResponse"
+                        }
+                    },
+                    "413": {
+                        "description": "Request Entity Too Large",
+                        "schema": {
+                            "$ref": "#/definitions/attachment.ErrorResponse"
+                        }
+                    },
+                    "415": {
+                        "description": "Unsupported Media Type",
+                        "schema": {
+                            "$ref": "#/definitions/attachment.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/work-orders/{id}/attachments/{attachmentID}": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Изображения и PDF отдаются для просмотра в браузере, остальные — как загрузка",
+                "produces": [
+                    "application/octet-stream"
+                ],
+                "tags": [
+                    "attachments"
+                ],
+                "summary": "Скачать файл",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID инцидента, заказа или результата контроля",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "ID вложения",
+                        "name": "attachmentID",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "file"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/attachment.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "tags": [
+                    "attachments"
+                ],
+                "summary": "Удалить вложение",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID инцидента, заказа или результата контроля",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "ID вложения",
+                        "name": "attachmentID",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/attachment.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/work-orders/{id}/attachments/{attachmentID}/thumbnail": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "JPEG не больше 320 пикселей по большей стороне; есть только у вложений с has_thumbnail",
+                "produces": [
+                    "image/jpeg"
+                ],
+                "tags": [
+                    "attachments"
+                ],
+                "summary": "Уменьшенная копия изображения",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID инцидента, заказа или результата контроля",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "ID вложения",
+                        "name": "attachmentID",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "file"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/attachment.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/work-orders/{id}/comments": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "work-orders"
+                ],
+                "summary": "Комментарии к заказу",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID заказа",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/workorder.Comment"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/workorder.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/workorder.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Упомянутые через @username пользователи получают уведомление",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "work-orders"
+                ],
+                "summary": "Добавить комментарий к заказу",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID заказа",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Текст комментария",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/workorder.CommentRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/workorder.Comment"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/workorder.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/workorder.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/workorder.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/work-orders/{id}/comments/{commentID}": {
+            "put": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Автор может изменить комментарий в течение 15 минут после создания",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "work-orders"
+                ],
+                "summary": "Изменить комментарий",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID заказа",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "ID комментария",
+                        "name": "commentID",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Текст комментария",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/workorder.CommentRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/workorder.Comment"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/workorder.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/workorder.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/workorder.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/workorder.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Автор может удалить комментарий в течение 5 минут; с правом order.delete — в любое время",
+                "tags": [
+                    "work-orders"
+                ],
+                "summary": "Удалить комментарий",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID заказа",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "ID комментария",
+                        "name": "commentID",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/workorder.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/workorder.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/workorder.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/work-orders/{id}/complete-partial": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Закрывает заказ в работе по количеству годных; с backorder остаток выделяется в новый заказ",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "work-orders"
+                ],
+                "summary": "Частично завершить заказ",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID заказа",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Параметры завершения",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/workorder.PartialCompleteRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/workorder.SplitResult"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/workorder.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/workorder.TransitionErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/work-orders/{id}/history": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "work-orders"
+                ],
+                "summary": "История статусов заказа",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID заказа",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/workorder.StatusHistory"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/workorder.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/workorder.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/work-orders/{id}/merge": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Вливает заказы того же продукта и версии маршрута в текущий; источники отменяются",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "work-orders"
+                ],
+                "summary": "Объединить заказы",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID целевого заказа",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Заказы-источники",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/workorder.MergeRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/workorder.WorkOrder"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/workorder.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/workorder.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/workorder.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/work-orders/{id}/progress": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Годные, брак и экземпляры в работе по этапам маршрута, прогноз завершения и признак опоздания к сроку.\nВремя цикла этапа: медиана фактических выполнений, норматив этапа или такт продукта.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "work-orders"
+                ],
+                "summary": "Ход выполнения заказа",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID заказа",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/workorder.Progress"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/workorder.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/workorder.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/work-orders/{id}/split": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Выделяет часть количества в дочерний заказ; выпущенные экземпляры остаются в исходном",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "work-orders"
+                ],
+                "summary": "Разделить заказ",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID заказа",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Количество для выделения",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/workorder.SplitRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/workorder.SplitResult"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/workorder.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/workorder.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/workorder.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/work-orders/{id}/transitions": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Возвращает действия, допустимые из текущего статуса, с отметкой о правах пользователя",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "work-orders"
+                ],
+                "summary": "Доступные действия над статусом заказа",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID заказа",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/workorder.AvailableAction"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/workorder.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/workorder.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Выполняет действие workflow: release, start, hold, resume, complete, cancel.\nКаждое действие требует своего права (order.status, production.start, production.complete)",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "work-orders"
+                ],
+                "summary": "Изменить статус заказа",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID заказа",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Действие",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/workorder.TransitionRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/workorder.WorkOrder"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/workorder.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/workorder.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/workorder.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/workorder.TransitionErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/workorder.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/work-orders/{id}/yield": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Выход годного с первого предъявления (FPY), число доработок и брак по причинам списания",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "work-orders"
+                ],
+                "summary": "Выход годного заказа",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID заказа",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/workorder.Yield"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/workorder.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/workorder.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        }
+    },
+    "definitions": {
+        "attachment.Attachment": {
+            "type": "object",
+            "properties": {
+                "content_type": {
+                    "type": "string",
+                    "example": "image/jpeg"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "entity_id": {
+                    "type": "integer",
+                    "example": 12
+                },
+                "entity_type": {
+                    "type": "string",
+                    "example": "incident"
+                },
+                "file_name": {
+                    "type": "string",
+                    "example": "fixture.jpg"
+                },
+                "has_thumbnail": {
+                    "type": "boolean"
+                },
+                "height": {
+                    "type": "integer",
+                    "example": 1080
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "size": {
+                    "type": "integer",
+                    "example": 482133
+                },
+                "uploaded_by": {
+                    "type": "integer"
+                },
+                "width": {
+                    "type": "integer",
+                    "example": 1920
+                }
+            }
+        },
+        "attachment.ErrorResponse": {
+            "type": "object",
+            "properties": {
+                "error": {
+                    "type": "string",
+                    "example": "Описание ошибки"
+                }
+            }
+        },
+        "calendar.ErrorResponse": {
+            "type": "object",
+            "properties": {
+                "error": {
+                    "type": "string",
+                    "example": "Описание ошибки"
+                }
+            }
+        },
+        "calendar.Exception": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "created_by": {
+                    "type": "integer"
+                },
+                "end_time": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "kind": {
+                    "type": "string",
+                    "example": "holiday"
+                },
+                "line_id": {
+                    "type": "integer"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "start_time": {
+                    "type": "string"
+                }
+            }
+        },
+        "calendar.ExceptionRequest": {
+            "type": "object",
+            "properties": {
+                "end_time": {
+                    "type": "string",
+                    "example": "2027-01-02T00:00:00Z"
+                },
+                "kind": {
+                    "type": "string",
+                    "example": "holiday"
+                },
+                "line_id": {
+                    "type": "integer"
+                },
+                "name": {
+                    "type": "string",
+                    "example": "Новый год"
+                },
+                "start_time": {
+                    "type": "string",
+                    "example": "2027-01-01T00:00:00Z"
+                }
+            }
+        },
+        "calendar.Interval": {
+            "type": "object",
+            "properties": {
+                "end": {
+                    "type": "string"
+                },
+                "start": {
+                    "type": "string"
+                }
+            }
+        },
+        "calendar.Shift": {
+            "type": "object",
+            "properties": {
+                "active": {
+                    "type": "boolean"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "end_time": {
+                    "type": "string",
+                    "example": "16:00"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "line_id": {
+                    "type": "integer"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "start_time": {
+                    "type": "string",
+                    "example": "08:00"
+                },
+                "weekdays": {
+                    "type": "integer",
+                    "example": 31
+                }
+            }
+        },
+        "calendar.ShiftRequest": {
+            "type": "object",
+            "properties": {
+                "active": {
+                    "type": "boolean",
+                    "example": true
+                },
+                "end_time": {
+                    "type": "string",
+                    "example": "16:00"
+                },
+                "line_id": {
+                    "type": "integer"
+                },
+                "name": {
+                    "type": "string",
+                    "example": "Первая смена"
+                },
+                "start_time": {
+                    "type": "string",
+                    "example": "08:00"
+                },
+                "weekdays": {
+                    "description": "Weekdays по умолчанию — ежедневно (127)",
+                    "type": "integer",
+                    "example": 31
+                }
+            }
+        },
+        "calendar.WorkingTime": {
+            "type": "object",
+            "properties": {
+                "from": {
+                    "type": "string"
+                },
+                "intervals": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/calendar.Interval"
+                    }
+                },
+                "line_id": {
+                    "type": "integer"
+                },
+                "minutes": {
+                    "type": "number"
+                },
+                "to": {
+                    "type": "string"
+                }
+            }
+        },
+        "execution.Disposition": {
+            "type": "object",
+            "properties": {
+                "action": {
+                    "type": "string",
+                    "example": "rework"
+                },
+                "rework": {
+                    "$ref": "#/definitions/execution.Rework"
+                },
+                "scrap": {
+                    "$ref": "#/definitions/execution.Scrap"
+                }
+            }
+        },
+        "execution.DispositionRequest": {
+            "type": "object",
+            "properties": {
+                "action": {
+                    "type": "string",
+                    "example": "rework"
+                },
+                "comment": {
+                    "type": "string",
+                    "example": "Переварить шов"
+                },
+                "inspection_id": {
+                    "type": "integer",
+                    "example": 12
+                },
+                "scrap_reason_id": {
+                    "type": "integer",
+                    "example": 2
+                },
+                "stage_id": {
+                    "type": "integer",
+                    "example": 2
+                }
+            }
+        },
+        "execution.ErrorResponse": {
+            "type": "object",
+            "properties": {
+                "error": {
+                    "type": "string",
+                    "example": "Описание ошибки"
+                }
+            }
+        },
+        "execution.Execution": {
+            "type": "object",
+            "properties": {
+                "end_time": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "override": {
+                    "$ref": "#/definitions/execution.Override"
+                },
+                "product_instance_id": {
+                    "type": "integer"
+                },
+                "rework_id": {
+                    "type": "integer"
+                },
+                "stage_id": {
+                    "type": "integer"
+                },
+                "start_time": {
+                    "type": "string"
+                },
+                "superseded_by": {
+                    "type": "integer"
+                },
+                "user_id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "execution.InspectionErrorResponse": {
+            "type": "object",
+            "properties": {
+                "error": {
+                    "type": "string",
+                    "example": "Не пройден обязательный контроль предыдущих этапов"
+                },
+                "pending_stage_ids": {
+                    "type": "array",
+                    "items": {
+                        "type": "integer"
+                    },
+                    "example": [
+                        2
+                    ]
+                }
+            }
+        },
+        "execution.Override": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "execution_id": {
+                    "type": "integer"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "reason": {
+                    "type": "string"
+                },
+                "supervisor_id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "execution.OverrideRequest": {
+            "type": "object",
+            "properties": {
+                "password": {
+                    "type": "string",
+                    "example": "secret"
+                },
+                "reason": {
+                    "type": "string",
+                    "example": "Плановая подмена, оператор на обучении"
+                },
+                "username": {
+                    "type": "string",
+                    "example": "master"
+                }
+            }
+        },
+        "execution.Rework": {
+            "type": "object",
+            "properties": {
+                "comment": {
+                    "type": "string"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "created_by": {
+                    "type": "integer"
+                },
+                "hold_id": {
+                    "type": "integer"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "inspection_id": {
+                    "type": "integer"
+                },
+                "product_instance_id": {
+                    "type": "integer"
+                },
+                "to_stage_id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "execution.ScanRequest": {
+            "type": "object",
+            "properties": {
+                "barcode": {
+                    "type": "string",
+                    "example": "MES-GB01-260105-000042-2"
+                },
+                "override": {
+                    "$ref": "#/definitions/execution.OverrideRequest"
+                },
+                "stage_id": {
+                    "type": "integer",
+                    "example": 1
+                }
+            }
+        },
+        "execution.Scrap": {
+            "type": "object",
+            "properties": {
+                "comment": {
+                    "type": "string"
+                },
+                "inspection_id": {
+                    "type": "integer"
+                },
+                "product_instance_id": {
+                    "type": "integer"
+                },
+                "reason_id": {
+                    "type": "integer"
+                },
+                "scrapped_at": {
+                    "type": "string"
+                },
+                "scrapped_by": {
+                    "type": "integer"
+                }
+            }
+        },
+        "execution.ScrapReason": {
+            "type": "object",
+            "properties": {
+                "active": {
+                    "type": "boolean",
+                    "example": true
+                },
+                "code": {
+                    "type": "string",
+                    "example": "MATERIAL"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "name": {
+                    "type": "string",
+                    "example": "Дефект материала"
+                }
+            }
+        },
+        "execution.SequenceErrorResponse": {
+            "type": "object",
+            "properties": {
+                "error": {
+                    "type": "string",
+                    "example": "Не завершены предыдущие этапы"
+                },
+                "missing_stage_ids": {
+                    "type": "array",
+                    "items": {
+                        "type": "integer"
+                    },
+                    "example": [
+                        1,
+                        2
+                    ]
+                }
+            }
+        },
+        "hold.ErrorResponse": {
+            "type": "object",
+            "properties": {
+                "error": {
+                    "type": "string",
+                    "example": "Описание ошибки"
+                }
+            }
+        },
+        "hold.Event": {
+            "type": "object",
+            "properties": {
+                "action": {
+                    "type": "string"
+                },
+                "comment": {
+                    "type": "string"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "disposition": {
+                    "type": "string"
+                },
+                "hold_id": {
+                    "type": "integer"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "product_instance_id": {
+                    "type": "integer"
+                },
+                "user_id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "hold.Hold": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "created_by": {
+                    "type": "integer"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "items": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/hold.Item"
+                    }
+                },
+                "machine_id": {
+                    "type": "integer"
+                },
+                "reason": {
+                    "type": "string"
+                },
+                "released_at": {
+                    "type": "string"
+                },
+                "scope": {
+                    "type": "string",
+                    "example": "work_order"
+                },
+                "status": {
+                    "type": "string",
+                    "example": "active"
+                },
+                "window_from": {
+                    "type": "string"
+                },
+                "window_to": {
+                    "type": "string"
+                },
+                "work_order_id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "hold.Item": {
+            "type": "object",
+            "properties": {
+                "barcode": {
+                    "type": "string"
+                },
+                "comment": {
+                    "type": "string"
+                },
+                "disposition": {
+                    "type": "string",
+                    "example": "rework"
+                },
+                "hold_id": {
+                    "type": "integer"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "product_instance_id": {
+                    "type": "integer"
+                },
+                "released_at": {
+                    "type": "string"
+                },
+                "released_by": {
+                    "type": "integer"
+                },
+                "status": {
+                    "type": "string",
+                    "example": "active"
+                }
+            }
+        },
+        "hold.Placement": {
+            "type": "object",
+            "properties": {
+                "barcodes": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "from": {
+                    "type": "string",
+                    "example": "2026-11-02T06:00:00Z"
+                },
+                "machine_id": {
+                    "type": "integer"
+                },
+                "reason": {
+                    "type": "string",
+                    "example": "Трещины сварного шва в партии"
+                },
+                "to": {
+                    "type": "string",
+                    "example": "2026-11-02T14:00:00Z"
+                },
+                "work_order_id": {
+                    "type": "integer",
+                    "example": 1
+                }
+            }
+        },
+        "hold.Release": {
+            "type": "object",
+            "properties": {
+                "barcodes": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "comment": {
+                    "type": "string",
+                    "example": "Дефект в пределах допуска по решению ОТК"
+                },
+                "disposition": {
+                    "type": "string",
+                    "example": "use_as_is"
+                },
+                "scrap_reason_id": {
+                    "type": "integer",
+                    "example": 2
+                },
+                "stage_id": {
+                    "type": "integer",
+                    "example": 2
+                }
+            }
+        },
+        "incident.AssignRequest": {
+            "type": "object",
+            "properties": {
+                "assignee_id": {
+                    "type": "integer",
+                    "example": 5
+                },
+                "comment": {
+                    "type": "string",
+                    "example": "Бригада ремонта, смена 2"
+                }
+            }
+        },
+        "incident.Edit": {
+            "type": "object",
+            "properties": {
+                "description": {
+                    "type": "string"
+                },
+                "machine_id": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "severity_id": {
+                    "type": "integer",
+                    "example": 3
+                },
+                "title": {
+                    "type": "string",
+                    "example": "Утечка масла на прессе"
+                },
+                "work_order_id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "incident.ErrorResponse": {
+            "type": "object",
+            "properties": {
+                "error": {
+                    "type": "string",
+                    "example": "Описание ошибки"
+                }
+            }
+        },
+        "incident.Escalation": {
+            "type": "object",
+            "properties": {
+                "after_min": {
+                    "type": "integer"
+                },
+                "escalated_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "incident_id": {
+                    "type": "integer"
+                },
+                "recipients": {
+                    "type": "integer"
+                },
+                "rule_id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "incident.EscalationRule": {
+            "type": "object",
+            "properties": {
+                "active": {
+                    "type": "boolean",
+                    "example": true
+                },
+                "after_min": {
+                    "type": "integer",
+                    "example": 10
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "name": {
+                    "type": "string",
+                    "example": "Начальник смены"
+                },
+                "notify_role_id": {
+                    "type": "integer",
+                    "example": 2
+                },
+                "notify_user_id": {
+                    "type": "integer"
+                },
+                "severity_id": {
+                    "type": "integer",
+                    "example": 4
+                },
+                "stop_on": {
+                    "type": "string",
+                    "example": "resolved"
+                },
+                "updated_at": {
+                    "type": "string"
+                }
+            }
+        },
+        "incident.Event": {
+            "type": "object",
+            "properties": {
+                "action": {
+                    "type": "string"
+                },
+                "comment": {
+                    "type": "string"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "incident_id": {
+                    "type": "integer"
+                },
+                "user_id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "incident.Incident": {
+            "type": "object",
+            "properties": {
+                "assigned_at": {
+                    "type": "string"
+                },
+                "assigned_to": {
+                    "type": "integer"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "description": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "machine_id": {
+                    "type": "integer"
+                },
+                "machine_stopped": {
+                    "type": "boolean"
+                },
+                "reported_by": {
+                    "type": "integer"
+                },
+                "resolution": {
+                    "type": "string"
+                },
+                "resolved_at": {
+                    "type": "string"
+                },
+                "resolved_by": {
+                    "type": "integer"
+                },
+                "severity_id": {
+                    "type": "integer"
+                },
+                "status": {
+                    "type": "string",
+                    "example": "open"
+                },
+                "title": {
+                    "type": "string"
+                },
+                "updated_at": {
+                    "type": "string"
+                },
+                "work_order_id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "incident.Metrics": {
+            "type": "object",
+            "properties": {
+                "by_line": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/incident.MetricsRow"
+                    }
+                },
+                "by_severity": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/incident.MetricsRow"
+                    }
+                },
+                "from": {
+                    "type": "string"
+                },
+                "to": {
+                    "type": "string"
+                },
+                "total": {
+                    "$ref": "#/definitions/incident.MetricsRow"
+                }
+            }
+        },
+        "incident.MetricsRow": {
+            "type": "object",
+            "properties": {
+                "acknowledged": {
+                    "type": "integer"
+                },
+                "escalated": {
+                    "type": "integer"
+                },
+                "incidents": {
+                    "type": "integer"
+                },
+                "line_id": {
+                    "type": "integer"
+                },
+                "line_name": {
+                    "type": "string"
+                },
+                "mtta_min": {
+                    "type": "number"
+                },
+                "mttr_min": {
+                    "type": "number"
+                },
+                "resolved": {
+                    "type": "integer"
+                },
+                "severity_id": {
+                    "type": "integer"
+                },
+                "severity_name": {
+                    "type": "string"
+                }
+            }
+        },
+        "incident.ReopenRequest": {
+            "type": "object",
+            "properties": {
+                "comment": {
+                    "type": "string",
+                    "example": "Утечка повторилась"
+                }
+            }
+        },
+        "incident.Report": {
+            "type": "object",
+            "properties": {
+                "description": {
+                    "type": "string",
+                    "example": "Под прессом лужа масла, давление падает"
+                },
+                "expected_until": {
+                    "type": "string",
+                    "example": "2026-11-02T14:00:00Z"
+                },
+                "machine_id": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "severity_id": {
+                    "type": "integer",
+                    "example": 3
+                },
+                "stop_machine": {
+                    "type": "boolean"
+                },
+                "title": {
+                    "type": "string",
+                    "example": "Утечка масла на прессе"
+                },
+                "work_order_id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "incident.Resolution": {
+            "type": "object",
+            "properties": {
+                "resolution": {
+                    "type": "string",
+                    "example": "Заменено уплотнение гидроцилиндра"
+                },
+                "restore_machine": {
+                    "type": "boolean",
+                    "example": true
+                }
+            }
+        },
+        "incident.RuleRequest": {
+            "type": "object",
+            "properties": {
+                "active": {
+                    "type": "boolean",
+                    "example": true
+                },
+                "after_min": {
+                    "type": "integer",
+                    "example": 10
+                },
+                "name": {
+                    "type": "string",
+                    "example": "Начальник смены"
+                },
+                "notify_role_id": {
+                    "type": "integer",
+                    "example": 2
+                },
+                "notify_user_id": {
+                    "type": "integer"
+                },
+                "severity_id": {
+                    "type": "integer",
+                    "example": 4
+                },
+                "stop_on": {
+                    "type": "string",
+                    "example": "resolved"
+                }
+            }
+        },
+        "incident.Severity": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "level": {
+                    "type": "integer"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "stops_machine": {
+                    "type": "boolean"
+                }
+            }
+        },
+        "instance.BulkCreateRequest": {
+            "type": "object",
+            "properties": {
+                "count": {
+                    "type": "integer",
+                    "example": 50
+                },
+                "work_order_id": {
+                    "type": "integer",
+                    "example": 1
+                }
+            }
+        },
+        "instance.CreateRequest": {
+            "type": "object",
+            "properties": {
+                "product_id": {
+                    "type": "integer",
+                    "example": 1
+                }
+            }
+        },
+        "instance.ErrorResponse": {
+            "type": "object",
+            "properties": {
+                "error": {
+                    "type": "string",
+                    "example": "Описание ошибки"
+                }
+            }
+        },
+        "instance.HoldErrorResponse": {
+            "type": "object",
+            "properties": {
+                "barcodes": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "error": {
+                    "type": "string",
+                    "example": "Экземпляры заблокированы (карантин)"
+                }
+            }
+        },
+        "instance.Instance": {
+            "type": "object",
+            "properties": {
+                "barcode": {
+                    "type": "string"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "product_id": {
+                    "type": "integer"
+                },
+                "scrap_reason_id": {
+                    "type": "integer"
+                },
+                "scrapped_at": {
+                    "type": "string"
+                },
+                "shipped_at": {
+                    "type": "string"
+                },
+                "shipped_by": {
+                    "type": "integer"
+                },
+                "work_order_id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "instance.ShipRequest": {
+            "type": "object",
+            "properties": {
+                "barcodes": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "MES-GB01-260105-000042-2"
+                    ]
+                }
+            }
+        },
+        "machine.ErrorResponse": {
+            "type": "object",
+            "properties": {
+                "error": {
+                    "type": "string",
+                    "example": "Описание ошибки"
+                }
+            }
+        },
+        "machine.Machine": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "line_id": {
+                    "type": "integer"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "status_id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "machine.Status": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "name": {
+                    "type": "string"
+                }
+            }
+        },
+        "machine.StatusChange": {
+            "type": "object",
+            "properties": {
+                "comment": {
+                    "type": "string",
+                    "example": "Переналадка под заказ WO-2026-000124"
+                },
+                "expected_until": {
+                    "type": "string",
+                    "example": "2026-11-02T14:00:00Z"
+                },
+                "status_id": {
+                    "type": "integer",
+                    "example": 3
+                },
+                "work_order_id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "machine.StatusHistory": {
+            "type": "object",
+            "properties": {
+                "changed_by": {
+                    "type": "integer"
+                },
+                "comment": {
+                    "type": "string"
+                },
+                "ended_at": {
+                    "type": "string"
+                },
+                "expected_until": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "machine_id": {
+                    "type": "integer"
+                },
+                "started_at": {
+                    "type": "string"
+                },
+                "status_id": {
+                    "type": "integer"
+                },
+                "work_order_id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "machine.StatusTime": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "type": "string"
+                },
+                "minutes": {
+                    "type": "number"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "status_id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "machine.TimeSummary": {
+            "type": "object",
+            "properties": {
+                "by_status": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/machine.StatusTime"
+                    }
+                },
+                "down_min": {
+                    "type": "number"
+                },
+                "from": {
+                    "type": "string"
+                },
+                "idle_min": {
+                    "type": "number"
+                },
+                "machine_id": {
+                    "type": "integer"
+                },
+                "run_min": {
+                    "type": "number"
+                },
+                "setup_min": {
+                    "type": "number"
+                },
+                "to": {
+                    "type": "string"
+                },
+                "unknown_min": {
+                    "type": "number"
+                }
+            }
+        },
+        "ncr.Action": {
+            "type": "object",
+            "properties": {
+                "completed_at": {
+                    "type": "string"
+                },
+                "completed_by": {
+                    "type": "integer"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "description": {
+                    "type": "string",
+                    "example": "Заменить наконечники на всех сварочных постах линии"
+                },
+                "due_date": {
+                    "type": "string",
+                    "example": "2026-11-15T00:00:00Z"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "kind": {
+                    "type": "string",
+                    "example": "corrective"
+                },
+                "ncr_id": {
+                    "type": "integer"
+                },
+                "overdue": {
+                    "type": "boolean"
+                },
+                "owner_id": {
+                    "type": "integer",
+                    "example": 5
+                },
+                "result": {
+                    "type": "string"
+                },
+                "status": {
+                    "type": "string",
+                    "example": "open"
+                }
+            }
+        },
+        "ncr.ActionInput": {
+            "type": "object",
+            "properties": {
+                "description": {
+                    "type": "string",
+                    "example": "Заменить наконечники на всех сварочных постах линии"
+                },
+                "due_date": {
+                    "type": "string",
+                    "example": "2026-11-15T00:00:00Z"
+                },
+                "kind": {
+                    "type": "string",
+                    "example": "corrective"
+                },
+                "owner_id": {
+                    "type": "integer",
+                    "example": 5
+                }
+            }
+        },
+        "ncr.Approval": {
+            "type": "object",
+            "properties": {
+                "comment": {
+                    "type": "string"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "decision": {
+                    "type": "string",
+                    "example": "approved"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "step": {
+                    "type": "string",
+                    "example": "plan"
+                },
+                "user_id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "ncr.CommentRequest": {
+            "type": "object",
+            "properties": {
+                "comment": {
+                    "type": "string",
+                    "example": "План согласован с технологом"
+                }
+            }
+        },
+        "ncr.CompleteRequest": {
+            "type": "object",
+            "properties": {
+                "result": {
+                    "type": "string",
+                    "example": "Наконечники заменены на 6 постах, регламент обновлен"
+                }
+            }
+        },
+        "ncr.Edit": {
+            "type": "object",
+            "properties": {
+                "defect_code_id": {
+                    "type": "integer",
+                    "example": 3
+                },
+                "description": {
+                    "type": "string"
+                },
+                "title": {
+                    "type": "string",
+                    "example": "Повторяющиеся трещины сварного шва"
+                }
+            }
+        },
+        "ncr.ErrorResponse": {
+            "type": "object",
+            "properties": {
+                "error": {
+                    "type": "string",
+                    "example": "Описание ошибки"
+                }
+            }
+        },
+        "ncr.Event": {
+            "type": "object",
+            "properties": {
+                "action": {
+                    "type": "string"
+                },
+                "comment": {
+                    "type": "string"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "ncr_id": {
+                    "type": "integer"
+                },
+                "user_id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "ncr.IncidentLink": {
+            "type": "object",
+            "properties": {
+                "incident_id": {
+                    "type": "integer"
+                },
+                "status": {
+                    "type": "string"
+                },
+                "title": {
+                    "type": "string"
+                }
+            }
+        },
+        "ncr.InstanceLink": {
+            "type": "object",
+            "properties": {
+                "barcode": {
+                    "type": "string"
+                },
+                "product_instance_id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "ncr.LinkRequest": {
+            "type": "object",
+            "properties": {
+                "barcodes": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "incident_ids": {
+                    "type": "array",
+                    "items": {
+                        "type": "integer"
+                    }
+                },
+                "work_order_ids": {
+                    "type": "array",
+                    "items": {
+                        "type": "integer"
+                    }
+                }
+            }
+        },
+        "ncr.NCR": {
+            "type": "object",
+            "properties": {
+                "actions": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/ncr.Action"
+                    }
+                },
+                "approvals": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/ncr.Approval"
+                    }
+                },
+                "closed_at": {
+                    "type": "string"
+                },
+                "closed_by": {
+                    "type": "integer"
+                },
+                "closure_comment": {
+                    "type": "string"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "created_by": {
+                    "type": "integer"
+                },
+                "defect_code_id": {
+                    "type": "integer"
+                },
+                "description": {
+                    "type": "string"
+                },
+                "effective": {
+                    "type": "boolean"
+                },
+                "effectiveness_comment": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "incidents": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/ncr.IncidentLink"
+                    }
+                },
+                "instances": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/ncr.InstanceLink"
+                    }
+                },
+                "number": {
+                    "type": "string",
+                    "example": "NCR-2026-0007"
+                },
+                "root_cause": {
+                    "type": "string",
+                    "example": "Износ токоподводящего наконечника"
+                },
+                "status": {
+                    "type": "string",
+                    "example": "open"
+                },
+                "submitted_at": {
+                    "type": "string"
+                },
+                "submitted_by": {
+                    "type": "integer"
+                },
+                "title": {
+                    "type": "string",
+                    "example": "Повторяющиеся трещины сварного шва"
+                },
+                "updated_at": {
+                    "type": "string"
+                },
+                "verified_at": {
+                    "type": "string"
+                },
+                "verified_by": {
+                    "type": "integer"
+                },
+                "work_orders": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/ncr.WorkOrderLink"
+                    }
+                }
+            }
+        },
+        "ncr.Report": {
+            "type": "object",
+            "properties": {
+                "barcodes": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "defect_code_id": {
+                    "type": "integer",
+                    "example": 3
+                },
+                "description": {
+                    "type": "string",
+                    "example": "Трещины в 4 экземплярах за смену, код дефекта CRACK"
+                },
+                "incident_ids": {
+                    "type": "array",
+                    "items": {
+                        "type": "integer"
+                    }
+                },
+                "title": {
+                    "type": "string",
+                    "example": "Повторяющиеся трещины сварного шва"
+                },
+                "work_order_ids": {
+                    "type": "array",
+                    "items": {
+                        "type": "integer"
+                    }
+                }
+            }
+        },
+        "ncr.RootCauseRequest": {
+            "type": "object",
+            "properties": {
+                "root_cause": {
+                    "type": "string",
+                    "example": "Износ токоподводящего наконечника, замена не предусмотрена регламентом"
+                }
+            }
+        },
+        "ncr.Verification": {
+            "type": "object",
+            "properties": {
+                "comment": {
+                    "type": "string",
+                    "example": "За 30 дней трещин шва не выявлено"
+                },
+                "effective": {
+                    "type": "boolean",
+                    "example": true
+                }
+            }
+        },
+        "ncr.WorkOrderLink": {
+            "type": "object",
+            "properties": {
+                "wo_number": {
+                    "type": "string"
+                },
+                "work_order_id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "notification.ErrorResponse": {
+            "type": "object",
+            "properties": {
+                "error": {
+                    "type": "string",
+                    "example": "Описание ошибки"
+                }
+            }
+        },
+        "notification.Notification": {
+            "type": "object",
+            "properties": {
+                "body": {
+                    "type": "string"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "created_by": {
+                    "type": "integer"
+                },
+                "entity_id": {
+                    "type": "integer"
+                },
+                "entity_type": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "kind": {
+                    "type": "string"
+                },
+                "read_at": {
+                    "type": "string"
+                },
+                "title": {
+                    "type": "string"
+                },
+                "user_id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "notification.UnreadCountResponse": {
+            "type": "object",
+            "properties": {
+                "unread": {
+                    "type": "integer",
+                    "example": 3
+                }
+            }
+        },
+        "permission.CreatePermissionRequest": {
+            "type": "object",
+            "properties": {
+                "description": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                }
+            }
+        },
+        "permission.ErrorResponse": {
+            "type": "object",
+            "properties": {
+                "message": {
+                    "type": "string"
+                }
+            }
+        },
+        "permission.Permission": {
+            "type": "object",
+            "properties": {
+                "category": {
+                    "type": "string"
+                },
+                "code": {
+                    "type": "string"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "description": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "name": {
+                    "type": "string"
+                }
+            }
+        },
+        "permission.PermissionResponse": {
+            "type": "object",
+            "properties": {
+                "description": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "name": {
+                    "type": "string"
+                }
+            }
+        },
+        "permission.UpdatePermissionRequest": {
+            "type": "object",
+            "properties": {
+                "description": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                }
+            }
+        },
+        "product.CreateRequest": {
+            "type": "object",
+            "properties": {
+                "name": {
+                    "type": "string",
+                    "example": "Корпус редуктора"
+                },
+                "sku": {
+                    "type": "string",
+                    "example": "GB-HOUSING-01"
+                },
+                "tech_cycle_min": {
+                    "type": "integer",
+                    "example": 45
+                }
+            }
+        },
+        "product.ErrorResponse": {
+            "type": "object",
+            "properties": {
+                "error": {
+                    "type": "string",
+                    "example": "Описание ошибки"
+                }
+            }
+        },
+        "product.Product": {
+            "type": "object",
+            "properties": {
+                "created_by": {
+                    "type": "integer"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "sku": {
+                    "type": "string"
+                },
+                "tech_cycle_min": {
+                    "type": "integer"
+                }
+            }
+        },
+        "quality.Capability": {
+            "type": "object",
+            "properties": {
+                "cp": {
+                    "type": "number"
+                },
+                "cpk": {
+                    "type": "number"
+                },
+                "mean": {
+                    "type": "number"
+                },
+                "pp": {
+                    "type": "number"
+                },
+                "ppk": {
+                    "type": "number"
+                },
+                "sigma_overall": {
+                    "type": "number"
+                },
+                "sigma_within": {
+                    "type": "number"
+                }
+            }
+        },
+        "quality.Characteristic": {
+            "type": "object",
+            "properties": {
+                "expected": {
+                    "type": "boolean"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "kind": {
+                    "type": "string",
+                    "example": "numeric"
+                },
+                "name": {
+                    "type": "string",
+                    "example": "Длина шва"
+                },
+                "nominal": {
+                    "type": "number",
+                    "example": 120
+                },
+                "options": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/quality.Option"
+                    }
+                },
+                "position": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "required": {
+                    "type": "boolean",
+                    "example": true
+                },
+                "tol_minus": {
+                    "type": "number",
+                    "example": 0.5
+                },
+                "tol_plus": {
+                    "type": "number",
+                    "example": 1
+                },
+                "unit": {
+                    "type": "string",
+                    "example": "мм"
+                }
+            }
+        },
+        "quality.ChartPoint": {
+            "type": "object",
+            "properties": {
+                "dispersion": {
+                    "type": "number"
+                },
+                "from": {
+                    "type": "string"
+                },
+                "index": {
+                    "type": "integer"
+                },
+                "last_inspection_id": {
+                    "type": "integer"
+                },
+                "to": {
+                    "type": "string"
+                },
+                "value": {
+                    "type": "number"
+                }
+            }
+        },
+        "quality.ControlChart": {
+            "type": "object",
+            "properties": {
+                "capability": {
+                    "$ref": "#/definitions/quality.Capability"
+                },
+                "characteristic_id": {
+                    "type": "integer"
+                },
+                "chart": {
+                    "type": "string",
+                    "example": "xbar-r"
+                },
+                "dispersion": {
+                    "$ref": "#/definitions/quality.Limits"
+                },
+                "excluded": {
+                    "type": "integer"
+                },
+                "from": {
+                    "type": "string"
+                },
+                "lsl": {
+                    "type": "number"
+                },
+                "machine_id": {
+                    "type": "integer"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "plan_id": {
+                    "type": "integer"
+                },
+                "points": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/quality.ChartPoint"
+                    }
+                },
+                "product_id": {
+                    "type": "integer"
+                },
+                "samples": {
+                    "type": "integer"
+                },
+                "stage_id": {
+                    "type": "integer"
+                },
+                "subgroup_size": {
+                    "type": "integer",
+                    "example": 5
+                },
+                "to": {
+                    "type": "string"
+                },
+                "unit": {
+                    "type": "string"
+                },
+                "usl": {
+                    "type": "number"
+                },
+                "violations": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/quality.Violation"
+                    }
+                },
+                "x": {
+                    "$ref": "#/definitions/quality.Limits"
+                }
+            }
+        },
+        "quality.Defect": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "type": "string"
+                },
+                "comment": {
+                    "type": "string"
+                },
+                "defect_code_id": {
+                    "type": "integer"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "quantity": {
+                    "type": "integer"
+                }
+            }
+        },
+        "quality.DefectCode": {
+            "type": "object",
+            "properties": {
+                "active": {
+                    "type": "boolean",
+                    "example": true
+                },
+                "code": {
+                    "type": "string",
+                    "example": "SCRATCH"
+                },
+                "description": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "name": {
+                    "type": "string",
+                    "example": "Царапина"
+                }
+            }
+        },
+        "quality.DefectCodeRequest": {
+            "type": "object",
+            "properties": {
+                "active": {
+                    "type": "boolean",
+                    "example": true
+                },
+                "code": {
+                    "type": "string",
+                    "example": "SCRATCH"
+                },
+                "description": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string",
+                    "example": "Царапина"
+                }
+            }
+        },
+        "quality.DefectInput": {
+            "type": "object",
+            "properties": {
+                "comment": {
+                    "type": "string",
+                    "example": "Левый угол"
+                },
+                "defect_code_id": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "quantity": {
+                    "type": "integer",
+                    "example": 1
+                }
+            }
+        },
+        "quality.ErrorResponse": {
+            "type": "object",
+            "properties": {
+                "error": {
+                    "type": "string",
+                    "example": "Описание ошибки"
+                }
+            }
+        },
+        "quality.Inspection": {
+            "type": "object",
+            "properties": {
+                "barcode": {
+                    "type": "string"
+                },
+                "defects": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/quality.Defect"
+                    }
+                },
+                "description": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "inspected_at": {
+                    "type": "string"
+                },
+                "inspected_by": {
+                    "type": "integer"
+                },
+                "machine_id": {
+                    "type": "integer"
+                },
+                "measurements": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/quality.Measurement"
+                    }
+                },
+                "plan_id": {
+                    "type": "integer"
+                },
+                "product_id": {
+                    "type": "integer"
+                },
+                "product_instance_id": {
+                    "type": "integer"
+                },
+                "quality_status_id": {
+                    "type": "integer"
+                },
+                "stage_id": {
+                    "type": "integer"
+                },
+                "status": {
+                    "type": "string"
+                },
+                "work_order_id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "quality.Limits": {
+            "type": "object",
+            "properties": {
+                "center": {
+                    "type": "number"
+                },
+                "lcl": {
+                    "type": "number"
+                },
+                "ucl": {
+                    "type": "number"
+                }
+            }
+        },
+        "quality.Measurement": {
+            "type": "object",
+            "properties": {
+                "bool_value": {
+                    "type": "boolean"
+                },
+                "characteristic_id": {
+                    "type": "integer"
+                },
+                "choice_value": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "kind": {
+                    "type": "string"
+                },
+                "lower_limit": {
+                    "type": "number"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "numeric_value": {
+                    "type": "number"
+                },
+                "passed": {
+                    "type": "boolean"
+                },
+                "unit": {
+                    "type": "string"
+                },
+                "upper_limit": {
+                    "type": "number"
+                }
+            }
+        },
+        "quality.MeasurementInput": {
+            "type": "object",
+            "properties": {
+                "characteristic_id": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "choice": {
+                    "type": "string"
+                },
+                "flag": {
+                    "type": "boolean"
+                },
+                "value": {
+                    "type": "number",
+                    "example": 120.4
+                }
+            }
+        },
+        "quality.Option": {
+            "type": "object",
+            "properties": {
+                "accepted": {
+                    "type": "boolean",
+                    "example": true
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "value": {
+                    "type": "string",
+                    "example": "Без пор"
+                }
+            }
+        },
+        "quality.Plan": {
+            "type": "object",
+            "properties": {
+                "active": {
+                    "type": "boolean",
+                    "example": true
+                },
+                "characteristics": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/quality.Characteristic"
+                    }
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "mandatory": {
+                    "type": "boolean",
+                    "example": true
+                },
+                "name": {
+                    "type": "string",
+                    "example": "Контроль после сварки"
+                },
+                "product_id": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "stage_id": {
+                    "type": "integer",
+                    "example": 2
+                },
+                "updated_at": {
+                    "type": "string"
+                }
+            }
+        },
+        "quality.PlanRequest": {
+            "type": "object",
+            "properties": {
+                "active": {
+                    "type": "boolean",
+                    "example": true
+                },
+                "characteristics": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/quality.Characteristic"
+                    }
+                },
+                "mandatory": {
+                    "type": "boolean",
+                    "example": true
+                },
+                "name": {
+                    "type": "string",
+                    "example": "Контроль после сварки"
+                },
+                "product_id": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "stage_id": {
+                    "type": "integer",
+                    "example": 2
+                }
+            }
+        },
+        "quality.Record": {
+            "type": "object",
+            "properties": {
+                "barcode": {
+                    "type": "string",
+                    "example": "MES-BRK-001-261102-000017-4"
+                },
+                "defects": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/quality.DefectInput"
+                    }
+                },
+                "description": {
+                    "type": "string",
+                    "example": "Глубокая царапина на лицевой панели"
+                },
+                "machine_id": {
+                    "type": "integer"
+                },
+                "measurements": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/quality.MeasurementInput"
+                    }
+                },
+                "stage_id": {
+                    "type": "integer"
+                },
+                "status": {
+                    "type": "string",
+                    "example": "failed"
+                }
+            }
+        },
+        "quality.SPCCheck": {
+            "type": "object",
+            "properties": {
+                "chart": {
+                    "$ref": "#/definitions/quality.ControlChart"
+                },
+                "incident": {
+                    "$ref": "#/definitions/incident.Incident"
+                },
+                "new_violations": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/quality.Violation"
+                    }
+                }
+            }
+        },
+        "quality.SPCCheckRequest": {
+            "type": "object",
+            "properties": {
+                "characteristic_id": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "chart": {
+                    "type": "string",
+                    "example": "xbar-r"
+                },
+                "from": {
+                    "type": "string",
+                    "example": "2026-10-01T00:00:00Z"
+                },
+                "machine_id": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "severity_id": {
+                    "type": "integer",
+                    "example": 2
+                },
+                "subgroup_size": {
+                    "type": "integer",
+                    "example": 5
+                },
+                "to": {
+                    "type": "string",
+                    "example": "2026-11-01T00:00:00Z"
+                }
+            }
+        },
+        "quality.Status": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "name": {
+                    "type": "string"
+                }
+            }
+        },
+        "quality.Violation": {
+            "type": "object",
+            "properties": {
+                "at": {
+                    "type": "string"
+                },
+                "chart": {
+                    "type": "string",
+                    "example": "x"
+                },
+                "description": {
+                    "type": "string",
+                    "example": "Точка за контрольной границей (3σ)"
+                },
+                "point": {
+                    "type": "integer"
+                },
+                "rule": {
+                    "type": "integer",
+                    "example": 1
+                }
+            }
+        },
+        "role.CreateRequest": {
+            "type": "object",
+            "required": [
+                "name"
+            ],
+            "properties": {
+                "name": {
+                    "type": "string",
+                    "example": "Администратор"
+                },
+                "permission_ids": {
+                    "type": "array",
+                    "items": {
+                        "type": "integer"
+                    },
+                    "example": [
+                        1,
+                        2,
+                        3,
+                        4,
+                        5
+                    ]
+                }
+            }
+        },
+        "role.ErrorResponse": {
+            "type": "object",
+            "properties": {
+                "error": {
+                    "type": "string",
+                    "example": "Описание ошибки"
+                }
+            }
+        },
+        "role.Role": {
+            "type": "object",
+            "properties": {
+                "id": {
+                    "type": "integer"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "permissions": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/permission.Permission"
+                    }
+                }
+            }
+        },
+        "role.SuccessResponse": {
+            "type": "object",
+            "properties": {
+                "message": {
+                    "type": "string",
+                    "example": "Операция выполнена успешно"
+                }
+            }
+        },
+        "role.UpdateRequest": {
+            "type": "object",
+            "required": [
+                "name"
+            ],
+            "properties": {
+                "name": {
+                    "type": "string",
+                    "example": "Модератор"
+                },
+                "permission_ids": {
+                    "type": "array",
+                    "items": {
+                        "type": "integer"
+                    },
+                    "example": [
+                        1,
+                        2,
+                        3
+                    ]
+                },
+                "role_id": {
+                    "type": "integer",
+                    "example": 1
+                }
+            }
+        },
+        "schedule.Changeover": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "from_product_id": {
+                    "type": "integer"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "line_id": {
+                    "type": "integer"
+                },
+                "machine_id": {
+                    "type": "integer"
+                },
+                "setup_min": {
+                    "type": "integer"
+                },
+                "to_product_id": {
+                    "type": "integer"
+                },
+                "updated_at": {
+                    "type": "string"
+                }
+            }
+        },
+        "schedule.ChangeoverRequest": {
+            "type": "object",
+            "properties": {
+                "from_product_id": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "line_id": {
+                    "type": "integer"
+                },
+                "machine_id": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "setup_min": {
+                    "type": "integer",
+                    "example": 45
+                },
+                "to_product_id": {
+                    "type": "integer",
+                    "example": 2
+                }
+            }
+        },
+        "schedule.ChangeoverTime": {
+            "type": "object",
+            "properties": {
+                "changeover_id": {
+                    "type": "integer"
+                },
+                "from_product_id": {
+                    "type": "integer"
+                },
+                "machine_id": {
+                    "type": "integer"
+                },
+                "setup_min": {
+                    "type": "integer"
+                },
+                "source": {
+                    "type": "string"
+                },
+                "to_product_id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "schedule.CommitRequest": {
+            "type": "object",
+            "properties": {
+                "from": {
+                    "type": "string",
+                    "example": "2026-11-02T08:00:00Z"
+                },
+                "replaced_slot_ids": {
+                    "type": "array",
+                    "items": {
+                        "type": "integer"
+                    }
+                },
+                "reschedule": {
+                    "type": "boolean",
+                    "example": false
+                },
+                "slots": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/schedule.PlannedSlot"
+                    }
+                },
+                "work_order_ids": {
+                    "type": "array",
+                    "items": {
+                        "type": "integer"
+                    }
+                }
+            }
+        },
+        "schedule.Comparison": {
+            "type": "object",
+            "properties": {
+                "current": {
+                    "$ref": "#/definitions/schedule.Metrics"
+                },
+                "plan": {
+                    "$ref": "#/definitions/schedule.Plan"
+                },
+                "proposed": {
+                    "$ref": "#/definitions/schedule.Metrics"
+                }
+            }
+        },
+        "schedule.ConflictResponse": {
+            "type": "object",
+            "properties": {
+                "conflicts": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/schedule.Slot"
+                    }
+                },
+                "error": {
+                    "type": "string",
+                    "example": "Машина занята в указанный период"
+                }
+            }
+        },
+        "schedule.ErrorResponse": {
+            "type": "object",
+            "properties": {
+                "error": {
+                    "type": "string",
+                    "example": "Описание ошибки"
+                }
+            }
+        },
+        "schedule.Gantt": {
+            "type": "object",
+            "properties": {
+                "from": {
+                    "type": "string"
+                },
+                "lines": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/schedule.GanttLine"
+                    }
+                },
+                "to": {
+                    "type": "string"
+                }
+            }
+        },
+        "schedule.GanttLine": {
+            "type": "object",
+            "properties": {
+                "line_id": {
+                    "type": "integer"
+                },
+                "line_name": {
+                    "type": "string"
+                },
+                "machines": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/schedule.GanttMachine"
+                    }
+                }
+            }
+        },
+        "schedule.GanttMachine": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "type": "string"
+                },
+                "machine_id": {
+                    "type": "integer"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "slots": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/schedule.GanttSlot"
+                    }
+                },
+                "status_id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "schedule.GanttSlot": {
+            "type": "object",
+            "properties": {
+                "deadline": {
+                    "type": "string"
+                },
+                "end_time": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "kind": {
+                    "type": "string"
+                },
+                "late": {
+                    "type": "boolean"
+                },
+                "machine_id": {
+                    "type": "integer"
+                },
+                "priority_id": {
+                    "type": "integer"
+                },
+                "product_id": {
+                    "type": "integer"
+                },
+                "product_name": {
+                    "type": "string"
+                },
+                "quantity": {
+                    "type": "integer"
+                },
+                "start_time": {
+                    "type": "string"
+                },
+                "status_id": {
+                    "type": "integer"
+                },
+                "wo_number": {
+                    "type": "string"
+                },
+                "work_order_id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "schedule.Impact": {
+            "type": "object",
+            "properties": {
+                "delayed": {
+                    "type": "integer"
+                },
+                "late_after": {
+                    "type": "integer"
+                },
+                "late_before": {
+                    "type": "integer"
+                },
+                "moved": {
+                    "type": "integer"
+                },
+                "orders": {
+                    "type": "integer"
+                },
+                "total_delay_min": {
+                    "type": "number"
+                },
+                "unplaced": {
+                    "type": "integer"
+                }
+            }
+        },
+        "schedule.MachineLoad": {
+            "type": "object",
+            "properties": {
+                "busy_min": {
+                    "type": "number"
+                },
+                "code": {
+                    "type": "string"
+                },
+                "machine_id": {
+                    "type": "integer"
+                },
+                "setup_min": {
+                    "type": "number"
+                },
+                "utilization": {
+                    "type": "number"
+                }
+            }
+        },
+        "schedule.Metrics": {
+            "type": "object",
+            "properties": {
+                "horizon_end": {
+                    "type": "string"
+                },
+                "late_orders": {
+                    "type": "integer"
+                },
+                "machines": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/schedule.MachineLoad"
+                    }
+                },
+                "max_lateness_min": {
+                    "type": "number"
+                },
+                "scheduled_orders": {
+                    "type": "integer"
+                },
+                "total_lateness_min": {
+                    "type": "number"
+                },
+                "unscheduled_orders": {
+                    "type": "integer"
+                },
+                "utilization": {
+                    "type": "number"
+                }
+            }
+        },
+        "schedule.Plan": {
+            "type": "object",
+            "properties": {
+                "from": {
+                    "type": "string"
+                },
+                "metrics": {
+                    "$ref": "#/definitions/schedule.Metrics"
+                },
+                "replaced_slot_ids": {
+                    "type": "array",
+                    "items": {
+                        "type": "integer"
+                    }
+                },
+                "slots": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/schedule.PlannedSlot"
+                    }
+                },
+                "unplaced": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/schedule.Unplaced"
+                    }
+                }
+            }
+        },
+        "schedule.PlanRequest": {
+            "type": "object",
+            "properties": {
+                "from": {
+                    "type": "string",
+                    "example": "2026-11-02T08:00:00Z"
+                },
+                "reschedule": {
+                    "type": "boolean",
+                    "example": false
+                },
+                "work_order_ids": {
+                    "type": "array",
+                    "items": {
+                        "type": "integer"
+                    }
+                }
+            }
+        },
+        "schedule.PlannedSlot": {
+            "type": "object",
+            "properties": {
+                "deadline": {
+                    "type": "string"
+                },
+                "end_time": {
+                    "type": "string"
+                },
+                "kind": {
+                    "type": "string"
+                },
+                "lateness_min": {
+                    "type": "number"
+                },
+                "machine_id": {
+                    "type": "integer"
+                },
+                "start_time": {
+                    "type": "string"
+                },
+                "wo_number": {
+                    "type": "string"
+                },
+                "work_order_id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "schedule.ProductMachinesRequest": {
+            "type": "object",
+            "properties": {
+                "machine_ids": {
+                    "type": "array",
+                    "items": {
+                        "type": "integer"
+                    },
+                    "example": [
+                        1,
+                        2
+                    ]
+                }
+            }
+        },
+        "schedule.Proposal": {
+            "type": "object",
+            "properties": {
+                "available_from": {
+                    "type": "string"
+                },
+                "changes": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/schedule.ProposalChange"
+                    }
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "created_by": {
+                    "type": "integer"
+                },
+                "decided_at": {
+                    "type": "string"
+                },
+                "decided_by": {
+                    "type": "integer"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "impact": {
+                    "$ref": "#/definitions/schedule.Impact"
+                },
+                "machine_id": {
+                    "type": "integer"
+                },
+                "planned_from": {
+                    "type": "string"
+                },
+                "reason": {
+                    "type": "string"
+                },
+                "status": {
+                    "type": "string",
+                    "example": "pending"
+                },
+                "status_history_id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "schedule.ProposalChange": {
+            "type": "object",
+            "properties": {
+                "action": {
+                    "type": "string",
+                    "example": "moved"
+                },
+                "deadline": {
+                    "type": "string"
+                },
+                "delay_min": {
+                    "type": "number"
+                },
+                "from_end": {
+                    "type": "string"
+                },
+                "from_start": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "lateness_after_min": {
+                    "type": "number"
+                },
+                "lateness_before_min": {
+                    "type": "number"
+                },
+                "proposal_id": {
+                    "type": "integer"
+                },
+                "reason": {
+                    "type": "string"
+                },
+                "run_start": {
+                    "type": "string"
+                },
+                "to_end": {
+                    "type": "string"
+                },
+                "to_machine_id": {
+                    "type": "integer"
+                },
+                "to_start": {
+                    "type": "string"
+                },
+                "wo_number": {
+                    "type": "string"
+                },
+                "work_order_id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "schedule.RescheduleRequest": {
+            "type": "object",
+            "properties": {
+                "available_from": {
+                    "type": "string",
+                    "example": "2026-11-02T14:00:00Z"
+                },
+                "machine_id": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "reason": {
+                    "type": "string",
+                    "example": "Поломка шпинделя"
+                }
+            }
+        },
+        "schedule.Slot": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "created_by": {
+                    "type": "integer"
+                },
+                "end_time": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "kind": {
+                    "type": "string",
+                    "example": "run"
+                },
+                "machine_id": {
+                    "type": "integer"
+                },
+                "start_time": {
+                    "type": "string"
+                },
+                "updated_at": {
+                    "type": "string"
+                },
+                "work_order_id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "schedule.SlotRequest": {
+            "type": "object",
+            "properties": {
+                "end_time": {
+                    "type": "string",
+                    "example": "2026-11-02T16:00:00Z"
+                },
+                "kind": {
+                    "type": "string",
+                    "example": "run"
+                },
+                "machine_id": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "start_time": {
+                    "type": "string",
+                    "example": "2026-11-02T08:00:00Z"
+                },
+                "work_order_id": {
+                    "type": "integer",
+                    "example": 1
+                }
+            }
+        },
+        "schedule.Unplaced": {
+            "type": "object",
+            "properties": {
+                "reason": {
+                    "type": "string"
+                },
+                "wo_number": {
+                    "type": "string"
+                },
+                "work_order_id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "skill.ErrorResponse": {
+            "type": "object",
+            "properties": {
+                "error": {
+                    "type": "string",
+                    "example": "Описание ошибки"
+                }
+            }
+        },
+        "skill.Skill": {
+            "type": "object",
+            "properties": {
+                "certified_until": {
+                    "type": "string"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "qualification_level": {
+                    "type": "integer"
+                },
+                "signed_off_at": {
+                    "type": "string"
+                },
+                "stage_id": {
+                    "type": "integer"
+                },
+                "trainer_id": {
+                    "type": "integer"
+                },
+                "user_id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "skill.SkillRequest": {
+            "type": "object",
+            "properties": {
+                "certified_until": {
+                    "type": "string",
+                    "example": "2027-06-30"
+                },
+                "qualification_level": {
+                    "type": "integer",
+                    "example": 2
+                },
+                "stage_id": {
+                    "type": "integer",
+                    "example": 1
+                }
+            }
+        },
+        "stage.CreateRequest": {
+            "type": "object",
+            "properties": {
+                "description": {
+                    "type": "string",
+                    "example": "Сборка узла на линии 1"
+                },
+                "is_strict_sequence": {
+                    "type": "boolean",
+                    "example": true
+                },
+                "name": {
+                    "type": "string",
+                    "example": "Сборка"
+                }
+            }
+        },
+        "stage.DiffChange": {
+            "type": "object",
+            "properties": {
+                "new_cycle_min": {
+                    "type": "integer"
+                },
+                "new_order": {
+                    "type": "integer"
+                },
+                "old_cycle_min": {
+                    "type": "integer"
+                },
+                "old_order": {
+                    "type": "integer"
+                },
+                "stage_id": {
+                    "type": "integer"
+                },
+                "stage_name": {
+                    "type": "string"
+                }
+            }
+        },
+        "stage.DiffStep": {
+            "type": "object",
+            "properties": {
+                "expected_cycle_min": {
+                    "type": "integer"
+                },
+                "stage_id": {
+                    "type": "integer"
+                },
+                "stage_name": {
+                    "type": "string"
+                },
+                "stage_order": {
+                    "type": "integer"
+                }
+            }
+        },
+        "stage.ErrorResponse": {
+            "type": "object",
+            "properties": {
+                "error": {
+                    "type": "string",
+                    "example": "Описание ошибки"
+                }
+            }
+        },
+        "stage.ProductStage": {
+            "type": "object",
+            "properties": {
+                "expected_cycle_min": {
+                    "type": "integer"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "product_id": {
+                    "type": "integer"
+                },
+                "routing_version_id": {
+                    "type": "integer"
+                },
+                "stage": {
+                    "$ref": "#/definitions/stage.Stage"
+                },
+                "stage_id": {
+                    "type": "integer"
+                },
+                "stage_order": {
+                    "type": "integer"
+                }
+            }
+        },
+        "stage.ReorderRequest": {
+            "type": "object",
+            "properties": {
+                "stage_ids": {
+                    "type": "array",
+                    "items": {
+                        "type": "integer"
+                    },
+                    "example": [
+                        3,
+                        1,
+                        2
+                    ]
+                }
+            }
+        },
+        "stage.ReplaceRoutingRequest": {
+            "type": "object",
+            "properties": {
+                "steps": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/stage.RoutingStep"
+                    }
+                }
+            }
+        },
+        "stage.Routing": {
+            "type": "object",
+            "properties": {
+                "product_id": {
+                    "type": "integer"
+                },
+                "steps": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/stage.ProductStage"
                     }
-                ],
-                "description": "Удаляет пользователя по ID",
-                "tags": [
-                    "users"
-                ],
-                "summary": "Удалить пользователя",
-                "parameters": [
-                    {
-                        "type": "integer",
-                        "description": "User ID",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
+                },
+                "total_cycle_min": {
+                    "description": "TotalCycleMin сумма ожидаемых времён по шагам, где они заданы",
+                    "type": "integer"
+                },
+                "version": {
+                    "$ref": "#/definitions/stage.RoutingVersion"
+                }
+            }
+        },
+        "stage.RoutingDiff": {
+            "type": "object",
+            "properties": {
+                "added": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/stage.DiffStep"
                     }
-                ],
-                "responses": {
-                    "204": {
-                        "description": "No Content"
-                    },
-                    "404": {
-                        "description": "not found",
-                        "schema": {
-                            "type": "string"
-                        }
+                },
+                "changed": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/stage.DiffChange"
+                    }
+                },
+                "from_version": {
+                    "type": "integer"
+                },
+                "product_id": {
+                    "type": "integer"
+                },
+                "removed": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/stage.DiffStep"
                     }
+                },
+                "to_version": {
+                    "type": "integer"
                 }
             }
-        }
-    },
-    "definitions": {
-        "permission.CreatePermissionRequest": {
+        },
+        "stage.RoutingStep": {
+            "type": "object",
+            "properties": {
+                "expected_cycle_min": {
+                    "type": "integer",
+                    "example": 15
+                },
+                "stage_id": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "stage_order": {
+                    "type": "integer",
+                    "example": 1
+                }
+            }
+        },
+        "stage.RoutingVersion": {
+            "type": "object",
+            "properties": {
+                "approved_by": {
+                    "type": "integer"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "created_by": {
+                    "type": "integer"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "obsoleted_at": {
+                    "type": "string"
+                },
+                "product_id": {
+                    "type": "integer"
+                },
+                "released_at": {
+                    "type": "string"
+                },
+                "status": {
+                    "type": "string"
+                },
+                "updated_by": {
+                    "type": "integer"
+                },
+                "version": {
+                    "type": "integer"
+                }
+            }
+        },
+        "stage.Stage": {
             "type": "object",
             "properties": {
                 "description": {
                     "type": "string"
                 },
+                "id": {
+                    "type": "integer"
+                },
+                "is_strict_sequence": {
+                    "type": "boolean"
+                },
                 "name": {
                     "type": "string"
                 }
             }
         },
-        "permission.ErrorResponse": {
+        "user.CreateRequest": {
             "type": "object",
             "properties": {
-                "message": {
-                    "type": "string"
+                "full_name": {
+                    "type": "string",
+                    "example": "Admin User"
+                },
+                "password": {
+                    "type": "string",
+                    "example": "123456"
+                },
+                "role_id": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "username": {
+                    "type": "string",
+                    "example": "admin"
                 }
             }
         },
-        "permission.Permission": {
+        "user.User": {
             "type": "object",
             "properties": {
-                "category": {
+                "createdAt": {
                     "type": "string"
                 },
-                "code": {
+                "email": {
                     "type": "string"
                 },
-                "created_at": {
+                "fullName": {
                     "type": "string"
                 },
-                "description": {
+                "id": {
+                    "type": "integer"
+                },
+                "password": {
                     "type": "string"
                 },
-                "id": {
+                "role": {
+                    "$ref": "#/definitions/role.Role"
+                },
+                "roleID": {
+                    "type": "integer",
+                    "format": "int64"
+                },
+                "username": {
+                    "type": "string"
+                }
+            }
+        },
+        "user.loginRequest": {
+            "type": "object",
+            "properties": {
+                "password": {
+                    "type": "string",
+                    "example": "123456"
+                },
+                "username": {
+                    "type": "string",
+                    "example": "admin"
+                }
+            }
+        },
+        "user.refreshRequest": {
+            "type": "object",
+            "properties": {
+                "refresh_token": {
+                    "type": "string",
+                    "example": "uuid-refresh-token"
+                }
+            }
+        },
+        "user.tokenResponse": {
+            "type": "object",
+            "properties": {
+                "access_token": {
+                    "type": "string"
+                },
+                "refresh_token": {
+                    "type": "string"
+                },
+                "user": {
+                    "$ref": "#/definitions/user.User"
+                }
+            }
+        },
+        "workorder.ActivityEvent": {
+            "type": "object",
+            "properties": {
+                "action": {
+                    "type": "string",
+                    "example": "release"
+                },
+                "at": {
+                    "type": "string"
+                },
+                "comment_id": {
                     "type": "integer"
                 },
-                "name": {
+                "edited": {
+                    "type": "boolean"
+                },
+                "message": {
                     "type": "string"
+                },
+                "new_status_id": {
+                    "type": "integer"
+                },
+                "old_status_id": {
+                    "type": "integer"
+                },
+                "type": {
+                    "type": "string",
+                    "example": "comment"
+                },
+                "user_id": {
+                    "type": "integer"
                 }
             }
         },
-        "permission.PermissionResponse": {
+        "workorder.AvailableAction": {
             "type": "object",
             "properties": {
-                "description": {
+                "action": {
+                    "type": "string",
+                    "example": "start"
+                },
+                "permission": {
+                    "type": "string",
+                    "example": "production.start"
+                },
+                "permitted": {
+                    "description": "Permitted есть ли у пользователя право на действие; условия перехода проверяются при выполнении",
+                    "type": "boolean"
+                },
+                "to_status_id": {
+                    "type": "integer",
+                    "example": 3
+                }
+            }
+        },
+        "workorder.Comment": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "edited_at": {
                     "type": "string"
                 },
                 "id": {
                     "type": "integer"
                 },
-                "name": {
+                "message": {
                     "type": "string"
+                },
+                "user_id": {
+                    "type": "integer"
+                },
+                "work_order_id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "workorder.CommentRequest": {
+            "type": "object",
+            "properties": {
+                "message": {
+                    "type": "string",
+                    "example": "@ivanov проверь оснастку перед запуском"
+                }
+            }
+        },
+        "workorder.CreateRequest": {
+            "type": "object",
+            "properties": {
+                "deadline": {
+                    "type": "string",
+                    "example": "2026-11-30"
+                },
+                "machine_id": {
+                    "type": "integer",
+                    "example": 2
+                },
+                "priority_id": {
+                    "type": "integer",
+                    "example": 2
+                },
+                "product_id": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "quantity": {
+                    "type": "integer",
+                    "example": 100
+                },
+                "wo_number": {
+                    "description": "WONumber необязателен: пустой номер генерируется по шаблону",
+                    "type": "string",
+                    "example": "WO-2026-000123"
+                }
+            }
+        },
+        "workorder.ErrorResponse": {
+            "type": "object",
+            "properties": {
+                "error": {
+                    "type": "string",
+                    "example": "Описание ошибки"
                 }
             }
         },
-        "permission.UpdatePermissionRequest": {
+        "workorder.Guard": {
             "type": "object",
             "properties": {
-                "description": {
-                    "type": "string"
-                },
                 "name": {
                     "type": "string"
                 }
             }
         },
-        "role.CreateRequest": {
+        "workorder.MergeRequest": {
             "type": "object",
-            "required": [
-                "name"
-            ],
             "properties": {
-                "name": {
+                "comment": {
                     "type": "string",
-                    "example": "Администратор"
+                    "example": "Один SKU подряд"
                 },
-                "permission_ids": {
+                "source_ids": {
                     "type": "array",
                     "items": {
                         "type": "integer"
                     },
                     "example": [
-                        1,
-                        2,
-                        3,
-                        4,
-                        5
+                        12,
+                        13
                     ]
                 }
             }
         },
-        "role.ErrorResponse": {
+        "workorder.PartialCompleteRequest": {
             "type": "object",
             "properties": {
-                "error": {
+                "backorder": {
+                    "description": "Backorder выделить недостающее количество в новый заказ",
+                    "type": "boolean",
+                    "example": true
+                },
+                "comment": {
                     "type": "string",
-                    "example": "Описание ошибки"
+                    "example": "Закрываем по окончании смены"
                 }
             }
         },
-        "role.Role": {
+        "workorder.Priority": {
             "type": "object",
             "properties": {
+                "code": {
+                    "type": "string"
+                },
                 "id": {
                     "type": "integer"
                 },
                 "name": {
                     "type": "string"
                 },
-                "permissions": {
+                "weight": {
+                    "type": "integer"
+                }
+            }
+        },
+        "workorder.Progress": {
+            "type": "object",
+            "properties": {
+                "deadline": {
+                    "type": "string"
+                },
+                "eta": {
+                    "description": "ETA не задан, если время цикла хотя бы одного этапа неизвестно",
+                    "type": "string"
+                },
+                "good": {
+                    "description": "Good экземпляры, прошедшие весь маршрут без брака",
+                    "type": "integer",
+                    "example": 38
+                },
+                "in_progress": {
+                    "type": "integer",
+                    "example": 3
+                },
+                "late": {
+                    "description": "Late прогноз завершения позже срока заказа",
+                    "type": "boolean"
+                },
+                "percent_complete": {
+                    "type": "number",
+                    "example": 41.5
+                },
+                "produced": {
+                    "type": "integer",
+                    "example": 45
+                },
+                "quantity": {
+                    "type": "integer",
+                    "example": 100
+                },
+                "remaining_min": {
+                    "type": "number",
+                    "example": 270
+                },
+                "scrap": {
+                    "type": "integer",
+                    "example": 2
+                },
+                "stages": {
                     "type": "array",
                     "items": {
-                        "$ref": "#/definitions/permission.Permission"
+                        "$ref": "#/definitions/workorder.StageProgress"
                     }
+                },
+                "status_id": {
+                    "type": "integer",
+                    "example": 3
+                },
+                "work_order_id": {
+                    "type": "integer",
+                    "example": 1
                 }
             }
         },
-        "role.SuccessResponse": {
+        "workorder.ScrapCount": {
             "type": "object",
             "properties": {
-                "message": {
-                    "type": "string",
-                    "example": "Операция выполнена успешно"
+                "quantity": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "reason_id": {
+                    "type": "integer",
+                    "example": 2
                 }
             }
         },
-        "role.UpdateRequest": {
+        "workorder.SplitRequest": {
             "type": "object",
-            "required": [
-                "name"
-            ],
             "properties": {
-                "name": {
+                "comment": {
                     "type": "string",
-                    "example": "Модератор"
+                    "example": "Станок 2 в ремонте"
                 },
-                "permission_ids": {
-                    "type": "array",
-                    "items": {
-                        "type": "integer"
-                    },
-                    "example": [
-                        1,
-                        2,
-                        3
-                    ]
+                "machine_id": {
+                    "description": "MachineID станок дочернего заказа; по умолчанию — станок исходного",
+                    "type": "integer",
+                    "example": 3
                 },
-                "role_id": {
+                "quantity": {
                     "type": "integer",
-                    "example": 1
+                    "example": 40
                 }
             }
         },
-        "user.CreateRequest": {
+        "workorder.SplitResult": {
             "type": "object",
             "properties": {
-                "full_name": {
+                "child": {
+                    "$ref": "#/definitions/workorder.WorkOrder"
+                },
+                "parent": {
+                    "$ref": "#/definitions/workorder.WorkOrder"
+                }
+            }
+        },
+        "workorder.StageProgress": {
+            "type": "object",
+            "properties": {
+                "completed": {
+                    "description": "Completed экземпляры, прошедшие этап и не признанные браком",
+                    "type": "integer",
+                    "example": 40
+                },
+                "cycle_min": {
+                    "description": "CycleMin время обработки одного экземпляра, мин; 0 — неизвестно",
+                    "type": "number",
+                    "example": 4.5
+                },
+                "cycle_source": {
                     "type": "string",
-                    "example": "Admin User"
+                    "example": "observed"
                 },
-                "password": {
+                "in_progress": {
+                    "type": "integer",
+                    "example": 3
+                },
+                "name": {
                     "type": "string",
-                    "example": "123456"
+                    "example": "Сборка"
                 },
-                "role_id": {
+                "remaining": {
+                    "type": "integer",
+                    "example": 60
+                },
+                "samples": {
+                    "type": "integer",
+                    "example": 38
+                },
+                "scrap": {
+                    "description": "Scrap брак, выявленный после этого этапа как последнего выполненного",
+                    "type": "integer",
+                    "example": 2
+                },
+                "stage_id": {
                     "type": "integer",
                     "example": 1
                 },
-                "username": {
-                    "type": "string",
-                    "example": "admin"
+                "stage_order": {
+                    "type": "integer",
+                    "example": 1
                 }
             }
         },
-        "user.User": {
+        "workorder.Status": {
             "type": "object",
             "properties": {
-                "createdAt": {
+                "code": {
                     "type": "string"
                 },
-                "email": {
+                "id": {
+                    "type": "integer"
+                },
+                "name": {
+                    "type": "string"
+                }
+            }
+        },
+        "workorder.StatusHistory": {
+            "type": "object",
+            "properties": {
+                "action": {
                     "type": "string"
                 },
-                "fullName": {
+                "changed_at": {
+                    "type": "string"
+                },
+                "changed_by": {
+                    "type": "integer"
+                },
+                "comment": {
                     "type": "string"
                 },
                 "id": {
                     "type": "integer"
                 },
-                "password": {
+                "new_status_id": {
+                    "type": "integer"
+                },
+                "old_status_id": {
+                    "type": "integer"
+                },
+                "work_order_id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "workorder.Transition": {
+            "type": "object",
+            "properties": {
+                "action": {
                     "type": "string"
                 },
-                "role": {
-                    "$ref": "#/definitions/role.Role"
+                "from": {
+                    "type": "array",
+                    "items": {
+                        "type": "integer"
+                    }
                 },
-                "roleID": {
-                    "type": "integer",
-                    "format": "int64"
+                "guards": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/workorder.Guard"
+                    }
                 },
-                "username": {
+                "permission": {
                     "type": "string"
+                },
+                "to": {
+                    "type": "integer"
                 }
             }
         },
-        "user.loginRequest": {
+        "workorder.TransitionErrorResponse": {
             "type": "object",
             "properties": {
-                "password": {
+                "action": {
                     "type": "string",
-                    "example": "123456"
+                    "example": "complete"
                 },
-                "username": {
+                "allowed_actions": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "start",
+                        "hold",
+                        "cancel"
+                    ]
+                },
+                "code": {
+                    "description": "Code invalid_transition — действие недопустимо из статуса, guard_failed — не выполнено условие",
                     "type": "string",
-                    "example": "admin"
+                    "example": "invalid_transition"
+                },
+                "error": {
+                    "type": "string",
+                    "example": "Переход недопустим из текущего статуса"
+                },
+                "guard": {
+                    "type": "string",
+                    "example": "no_open_executions"
+                },
+                "status_id": {
+                    "type": "integer",
+                    "example": 2
                 }
             }
         },
-        "user.refreshRequest": {
+        "workorder.TransitionRequest": {
             "type": "object",
             "properties": {
-                "refresh_token": {
+                "action": {
                     "type": "string",
-                    "example": "uuid-refresh-token"
+                    "example": "release"
+                },
+                "comment": {
+                    "type": "string",
+                    "example": "Материалы получены"
                 }
             }
         },
-        "user.tokenResponse": {
+        "workorder.UpdateRequest": {
             "type": "object",
             "properties": {
-                "access_token": {
+                "deadline": {
+                    "type": "string",
+                    "example": "2026-12-15"
+                },
+                "machine_id": {
+                    "type": "integer",
+                    "example": 2
+                },
+                "priority_id": {
+                    "type": "integer",
+                    "example": 3
+                },
+                "product_id": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "quantity": {
+                    "type": "integer",
+                    "example": 120
+                }
+            }
+        },
+        "workorder.WorkOrder": {
+            "type": "object",
+            "properties": {
+                "completed_quantity": {
+                    "type": "integer"
+                },
+                "created_at": {
                     "type": "string"
                 },
-                "refresh_token": {
+                "created_by": {
+                    "type": "integer"
+                },
+                "deadline": {
                     "type": "string"
                 },
-                "user": {
-                    "$ref": "#/definitions/user.User"
+                "id": {
+                    "type": "integer"
+                },
+                "machine_id": {
+                    "type": "integer"
+                },
+                "merged_into_id": {
+                    "type": "integer"
+                },
+                "parent_id": {
+                    "type": "integer"
+                },
+                "priority_id": {
+                    "type": "integer"
+                },
+                "product_id": {
+                    "type": "integer"
+                },
+                "quantity": {
+                    "type": "integer"
+                },
+                "routing_version_id": {
+                    "type": "integer"
+                },
+                "status_id": {
+                    "type": "integer"
+                },
+                "updated_at": {
+                    "type": "string"
+                },
+                "wo_number": {
+                    "type": "string"
+                }
+            }
+        },
+        "workorder.Yield": {
+            "type": "object",
+            "properties": {
+                "evaluated": {
+                    "type": "integer",
+                    "example": 40
+                },
+                "first_pass": {
+                    "type": "integer",
+                    "example": 36
+                },
+                "first_pass_yield": {
+                    "description": "FirstPassYield доля годных с первого предъявления среди оцененных, %; не задана, пока нет оцененных",
+                    "type": "number",
+                    "example": 90
+                },
+                "produced": {
+                    "type": "integer",
+                    "example": 45
+                },
+                "reworked": {
+                    "type": "integer",
+                    "example": 3
+                },
+                "reworks": {
+                    "description": "Reworks назначенные доработки; Reworked — экземпляры, отправленные на доработку хотя бы раз",
+                    "type": "integer",
+                    "example": 4
+                },
+                "scrap_by_reason": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/workorder.ScrapCount"
+                    }
+                },
+                "scrapped": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "work_order_id": {
+                    "type": "integer",
+                    "example": 1
                 }
             }
         }
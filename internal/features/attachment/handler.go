@@ -0,0 +1,260 @@
+package attachment
+
+import (
+	"errors"
+	"io"
+	"log/slog"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"mes-lite-back/internal/http/middleware"
+	"mes-lite-back/pkg"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// multipartOverhead запас на заголовки multipart сверх размера файла
+const multipartOverhead = 1 << 20
+
+// Parent объект, к которому монтируются вложения, и права на него:
+// View — просмотр и скачивание, Upload — загрузка, Remove — удаление
+type Parent struct {
+	Entity string
+	View   string
+	Upload string
+	Remove string
+}
+
+type Handler struct {
+	service ServiceInterface
+	perms   middleware.PermissionChecker
+}
+
+func NewHandler(service ServiceInterface, perms middleware.PermissionChecker) *Handler {
+	return &Handler{
+		service: service,
+		perms:   perms,
+	}
+}
+
+// Routes маршруты вложений объекта; монтируются под /<объект>/{id}/attachments
+func (h *Handler) Routes(p Parent) chi.Router {
+	r := chi.NewRouter()
+
+	view := middleware.PermissionGuard(h.perms, p.View)
+	upload := middleware.PermissionGuard(h.perms, p.Upload)
+	remove := middleware.PermissionGuard(h.perms, p.Remove)
+
+	r.With(view).Get("/", h.list(p.Entity))
+	r.With(upload).Post("/", h.upload(p.Entity))
+	r.With(view).Get("/{attachmentID}", h.download(p.Entity))
+	r.With(view).Get("/{attachmentID}/thumbnail", h.thumbnail(p.Entity))
+	r.With(remove).Delete("/{attachmentID}", h.delete(p.Entity))
+
+	return r
+}
+
+type ErrorResponse struct {
+	Error string `json:"error" example:"Описание ошибки"`
+}
+
+// ListAttachments godoc
+// @Summary Вложения объекта
+// @Description В порядке загрузки
+// @Tags attachments
+// @Security BearerAuth
+// @Produce json
+// @Param id path int true "ID инцидента, заказа или результата контроля"
+// @Success 200 {array} Attachment
+// @Failure 404 {object} ErrorResponse
+// @Router /incidents/{id}/attachments [get]
+// @Router /work-orders/{id}/attachments [get]
+// @Router /quality/inspections/{id}/attachments [get]
+func (h *Handler) list(entity string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		attachments, err := h.service.List(entity, pkg.ParamID(r))
+		if err != nil {
+			h.respondError(w, err)
+			return
+		}
+		pkg.RespondJSON(w, http.StatusOK, attachments)
+	}
+}
+
+// UploadAttachment godoc
+// @Summary Прикрепить файл
+// @Description Фото, PDF или текст; тип определяется по содержимому. Для изображений строится уменьшенная копия
+// @Tags attachments
+// @Security BearerAuth
+// @Accept multipart/form-data
+// @Produce json
+// @Param id path int true "ID инцидента, заказа или результата контроля"
+// @Param file formData file true "Файл"
+// @Success 201 {object} Attachment
+// @Failure 400 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 413 {object} ErrorResponse
+// @Failure 415 {object} ErrorResponse
+// @Router /incidents/{id}/attachments [post]
+// @Router /work-orders/{id}/attachments [post]
+// @Router /quality/inspections/{id}/attachments [post]
+func (h *Handler) upload(entity string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		maxSize := h.service.MaxSize()
+		r.Body = http.MaxBytesReader(w, r.Body, maxSize+multipartOverhead)
+
+		file, header, err := r.FormFile("file")
+		if err != nil {
+			var tooLarge *http.MaxBytesError
+			if errors.As(err, &tooLarge) {
+				h.respondError(w, ErrTooLarge)
+				return
+			}
+			pkg.RespondJSON(w, http.StatusBadRequest, ErrorResponse{Error: "Передайте файл в поле file формы multipart/form-data"})
+			return
+		}
+		defer file.Close()
+		defer r.MultipartForm.RemoveAll()
+
+		data, err := io.ReadAll(io.LimitReader(file, maxSize+1))
+		if err != nil {
+			h.respondError(w, err)
+			return
+		}
+
+		a, err := h.service.Upload(Upload{
+			EntityType: entity,
+			EntityID:   pkg.ParamID(r),
+			FileName:   header.Filename,
+			Data:       data,
+			UploadedBy: currentUser(r),
+		})
+		if err != nil {
+			h.respondError(w, err)
+			return
+		}
+		pkg.RespondJSON(w, http.StatusCreated, a)
+	}
+}
+
+// DownloadAttachment godoc
+// @Summary Скачать файл
+// @Description Изображения и PDF отдаются для просмотра в браузере, остальные — как загрузка
+// @Tags attachments
+// @Security BearerAuth
+// @Produce octet-stream
+// @Param id path int true "ID инцидента, заказа или результата контроля"
+// @Param attachmentID path int true "ID вложения"
+// @Success 200 {file} file
+// @Failure 404 {object} ErrorResponse
+// @Router /incidents/{id}/attachments/{attachmentID} [get]
+// @Router /work-orders/{id}/attachments/{attachmentID} [get]
+// @Router /quality/inspections/{id}/attachments/{attachmentID} [get]
+func (h *Handler) download(entity string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		a, content, err := h.service.Open(entity, pkg.ParamID(r), pkg.ParamInt64(r, "attachmentID"))
+		if err != nil {
+			h.respondError(w, err)
+			return
+		}
+		defer content.Close()
+
+		disposition := "attachment"
+		if strings.HasPrefix(a.ContentType, "image/") || strings.HasPrefix(a.ContentType, "application/pdf") {
+			disposition = "inline"
+		}
+
+		w.Header().Set("Content-Type", a.ContentType)
+		w.Header().Set("Content-Length", strconv.FormatInt(a.Size, 10))
+		w.Header().Set("Content-Disposition", mime.FormatMediaType(disposition, map[string]string{"filename": a.FileName}))
+		w.Header().Set("X-Content-Type-Options", "nosniff")
+		w.WriteHeader(http.StatusOK)
+		copyContent(w, content, a)
+	}
+}
+
+// AttachmentThumbnail godoc
+// @Summary Уменьшенная копия изображения
+// @Description JPEG не больше 320 пикселей по большей стороне; есть только у вложений с has_thumbnail
+// @Tags attachments
+// @Security BearerAuth
+// @Produce jpeg
+// @Param id path int true "ID инцидента, заказа или результата контроля"
+// @Param attachmentID path int true "ID вложения"
+// @Success 200 {file} file
+// @Failure 404 {object} ErrorResponse
+// @Router /incidents/{id}/attachments/{attachmentID}/thumbnail [get]
+// @Router /work-orders/{id}/attachments/{attachmentID}/thumbnail [get]
+// @Router /quality/inspections/{id}/attachments/{attachmentID}/thumbnail [get]
+func (h *Handler) thumbnail(entity string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		a, content, err := h.service.OpenThumbnail(entity, pkg.ParamID(r), pkg.ParamInt64(r, "attachmentID"))
+		if err != nil {
+			h.respondError(w, err)
+			return
+		}
+		defer content.Close()
+
+		w.Header().Set("Content-Type", "image/jpeg")
+		w.Header().Set("X-Content-Type-Options", "nosniff")
+		w.WriteHeader(http.StatusOK)
+		copyContent(w, content, a)
+	}
+}
+
+// DeleteAttachment godoc
+// @Summary Удалить вложение
+// @Tags attachments
+// @Security BearerAuth
+// @Param id path int true "ID инцидента, заказа или результата контроля"
+// @Param attachmentID path int true "ID вложения"
+// @Success 204
+// @Failure 404 {object} ErrorResponse
+// @Router /incidents/{id}/attachments/{attachmentID} [delete]
+// @Router /work-orders/{id}/attachments/{attachmentID} [delete]
+// @Router /quality/inspections/{id}/attachments/{attachmentID} [delete]
+func (h *Handler) delete(entity string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if err := h.service.Delete(entity, pkg.ParamID(r), pkg.ParamInt64(r, "attachmentID")); err != nil {
+			h.respondError(w, err)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+// copyContent передает содержимое клиенту; заголовки уже отправлены, поэтому ошибку можно только залогировать
+func copyContent(w io.Writer, content io.Reader, a *Attachment) {
+	if _, err := io.Copy(w, content); err != nil {
+		slog.Error("attachment download failed", slog.Int64("attachment_id", a.ID), slog.Any("err", err))
+	}
+}
+
+func currentUser(r *http.Request) int64 {
+	id, _ := middleware.UserIDFromContext(r.Context())
+	return id
+}
+
+func (h *Handler) respondError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, ErrNotFound):
+		pkg.RespondJSON(w, http.StatusNotFound, ErrorResponse{Error: "Вложение не найдено"})
+	case errors.Is(err, ErrParentNotFound):
+		pkg.RespondJSON(w, http.StatusNotFound, ErrorResponse{Error: "Объект вложения не найден"})
+	case errors.Is(err, ErrNoThumbnail):
+		pkg.RespondJSON(w, http.StatusNotFound, ErrorResponse{Error: "У вложения нет уменьшенной копии"})
+	case errors.Is(err, ErrContentNotFound):
+		pkg.RespondJSON(w, http.StatusNotFound, ErrorResponse{Error: "Файл вложения отсутствует в хранилище"})
+	case errors.Is(err, ErrEmptyFile):
+		pkg.RespondJSON(w, http.StatusBadRequest, ErrorResponse{Error: "Файл пустой"})
+	case errors.Is(err, ErrTooLarge):
+		pkg.RespondJSON(w, http.StatusRequestEntityTooLarge, ErrorResponse{Error: "Файл превышает допустимый размер"})
+	case errors.Is(err, ErrTypeNotAllowed):
+		pkg.RespondJSON(w, http.StatusUnsupportedMediaType, ErrorResponse{Error: "Недопустимый тип файла"})
+	default:
+		slog.Error("attachment request failed", slog.Any("err", err))
+		pkg.RespondJSON(w, http.StatusInternalServerError, ErrorResponse{Error: "Внутренняя ошибка сервера"})
+	}
+}
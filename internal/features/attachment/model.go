@@ -0,0 +1,57 @@
+package attachment
+
+import "time"
+
+// Объекты, к которым прикрепляются файлы
+const (
+	EntityIncident   = "incident"
+	EntityWorkOrder  = "work_order"
+	EntityInspection = "inspection"
+)
+
+// parents таблицы объектов-владельцев вложений
+var parents = map[string]string{
+	EntityIncident:   "incidents",
+	EntityWorkOrder:  "work_orders",
+	EntityInspection: "product_quality",
+}
+
+// Attachment файл, прикрепленный к объекту; содержимое лежит в хранилище
+type Attachment struct {
+	ID           int64     `gorm:"primaryKey;autoIncrement" json:"id"`
+	EntityType   string    `json:"entity_type" example:"incident"`
+	EntityID     int64     `json:"entity_id" example:"12"`
+	FileName     string    `json:"file_name" example:"fixture.jpg"`
+	ContentType  string    `json:"content_type" example:"image/jpeg"`
+	Size         int64     `gorm:"column:size_bytes" json:"size" example:"482133"`
+	StorageKey   string    `json:"-"`
+	HasThumbnail bool      `json:"has_thumbnail"`
+	Width        *int      `json:"width,omitempty" example:"1920"`
+	Height       *int      `json:"height,omitempty" example:"1080"`
+	UploadedBy   *int64    `json:"uploaded_by,omitempty"`
+	CreatedAt    time.Time `gorm:"autoCreateTime" json:"created_at"`
+}
+
+func (Attachment) TableName() string {
+	return "attachments"
+}
+
+// ThumbnailKey ключ уменьшенной копии изображения в хранилище
+func (a *Attachment) ThumbnailKey() string {
+	return a.StorageKey + ".thumb.jpg"
+}
+
+// Limits ограничения на загружаемые файлы
+type Limits struct {
+	MaxSize      int64
+	AllowedTypes []string
+}
+
+// Upload загружаемый файл
+type Upload struct {
+	EntityType string
+	EntityID   int64
+	FileName   string
+	Data       []byte
+	UploadedBy int64
+}
@@ -0,0 +1,10 @@
+package attachment
+
+type Repository interface {
+	List(entityType string, entityID int64) ([]*Attachment, error)
+	Get(id int64) (*Attachment, error)
+	Create(a *Attachment) error
+	Delete(a *Attachment) error
+
+	ParentExists(entityType string, entityID int64) (bool, error)
+}
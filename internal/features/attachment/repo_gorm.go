@@ -0,0 +1,52 @@
+package attachment
+
+import (
+	"fmt"
+
+	"gorm.io/gorm"
+)
+
+type GormRepository struct {
+	db *gorm.DB
+}
+
+func NewGormRepository(db *gorm.DB) *GormRepository {
+	return &GormRepository{db: db}
+}
+
+func (r *GormRepository) List(entityType string, entityID int64) ([]*Attachment, error) {
+	var attachments []*Attachment
+	err := r.db.
+		Where("entity_type = ? AND entity_id = ?", entityType, entityID).
+		Order("created_at, id").
+		Find(&attachments).
+		Error
+	return attachments, err
+}
+
+func (r *GormRepository) Get(id int64) (*Attachment, error) {
+	var a Attachment
+	if err := r.db.First(&a, id).Error; err != nil {
+		return nil, err
+	}
+	return &a, nil
+}
+
+func (r *GormRepository) Create(a *Attachment) error {
+	return r.db.Create(a).Error
+}
+
+func (r *GormRepository) Delete(a *Attachment) error {
+	return r.db.Delete(a).Error
+}
+
+func (r *GormRepository) ParentExists(entityType string, entityID int64) (bool, error) {
+	table, ok := parents[entityType]
+	if !ok {
+		return false, fmt.Errorf("unknown attachment entity %q", entityType)
+	}
+
+	var n int64
+	err := r.db.Table(table).Where("id = ?", entityID).Count(&n).Error
+	return n > 0, err
+}
@@ -0,0 +1,290 @@
+package attachment
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"mime"
+	"net/http"
+	"path"
+	"slices"
+	"strings"
+	"unicode/utf8"
+
+	"mes-lite-back/internal/storage"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+const (
+	// DefaultMaxSize ограничение размера файла по умолчанию, 10 МБ
+	DefaultMaxSize     = 10 << 20
+	maxFileNameLength  = 255
+	maxExtensionLength = 10
+)
+
+// DefaultAllowedTypes типы файлов, разрешенные по умолчанию: фото, PDF и текст
+var DefaultAllowedTypes = []string{
+	"image/jpeg",
+	"image/png",
+	"image/gif",
+	"image/webp",
+	"application/pdf",
+	"text/plain",
+}
+
+var (
+	ErrNotFound        = errors.New("attachment not found")
+	ErrParentNotFound  = errors.New("attachment owner not found")
+	ErrUnknownEntity   = errors.New("unknown attachment entity")
+	ErrEmptyFile       = errors.New("file is empty")
+	ErrTooLarge        = errors.New("file is too large")
+	ErrTypeNotAllowed  = errors.New("file type is not allowed")
+	ErrNoThumbnail     = errors.New("attachment has no thumbnail")
+	ErrContentNotFound = errors.New("attachment content is missing in storage")
+)
+
+// ServiceInterface определяет методы, используемые handler’ом
+type ServiceInterface interface {
+	MaxSize() int64
+	List(entityType string, entityID int64) ([]*Attachment, error)
+	Upload(u Upload) (*Attachment, error)
+	Open(entityType string, entityID, id int64) (*Attachment, io.ReadCloser, error)
+	OpenThumbnail(entityType string, entityID, id int64) (*Attachment, io.ReadCloser, error)
+	Delete(entityType string, entityID, id int64) error
+}
+
+type Service struct {
+	repo   Repository
+	store  storage.Storage
+	limits Limits
+}
+
+func NewService(repo Repository, store storage.Storage, limits Limits) *Service {
+	if limits.MaxSize <= 0 {
+		limits.MaxSize = DefaultMaxSize
+	}
+	if len(limits.AllowedTypes) == 0 {
+		limits.AllowedTypes = DefaultAllowedTypes
+	}
+
+	return &Service{
+		repo:   repo,
+		store:  store,
+		limits: limits,
+	}
+}
+
+func (s *Service) MaxSize() int64 {
+	return s.limits.MaxSize
+}
+
+func (s *Service) List(entityType string, entityID int64) ([]*Attachment, error) {
+	if err := s.checkParent(entityType, entityID); err != nil {
+		return nil, err
+	}
+	return s.repo.List(entityType, entityID)
+}
+
+// Upload сохраняет файл в хранилище и регистрирует вложение.
+// Тип определяется по содержимому, а не по имени файла; для изображений строится уменьшенная копия.
+func (s *Service) Upload(u Upload) (*Attachment, error) {
+	if err := s.checkParent(u.EntityType, u.EntityID); err != nil {
+		return nil, err
+	}
+	if len(u.Data) == 0 {
+		return nil, ErrEmptyFile
+	}
+	if int64(len(u.Data)) > s.limits.MaxSize {
+		return nil, ErrTooLarge
+	}
+
+	contentType := http.DetectContentType(u.Data)
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil || !slices.Contains(s.limits.AllowedTypes, mediaType) {
+		return nil, ErrTypeNotAllowed
+	}
+
+	name := fileName(u.FileName)
+	a := &Attachment{
+		EntityType:  u.EntityType,
+		EntityID:    u.EntityID,
+		FileName:    name,
+		ContentType: contentType,
+		Size:        int64(len(u.Data)),
+		StorageKey:  fmt.Sprintf("%s/%d/%s%s", u.EntityType, u.EntityID, uuid.NewString(), extension(name)),
+		UploadedBy:  &u.UploadedBy,
+	}
+
+	if err := s.store.Put(a.StorageKey, u.Data, contentType); err != nil {
+		return nil, err
+	}
+
+	if strings.HasPrefix(mediaType, "image/") {
+		s.addThumbnail(a, u.Data)
+	}
+
+	if err := s.repo.Create(a); err != nil {
+		s.removeContent(a)
+		return nil, err
+	}
+	return a, nil
+}
+
+func (s *Service) Open(entityType string, entityID, id int64) (*Attachment, io.ReadCloser, error) {
+	a, err := s.get(entityType, entityID, id)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	content, err := s.store.Get(a.StorageKey)
+	if errors.Is(err, storage.ErrNotFound) {
+		return nil, nil, ErrContentNotFound
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+	return a, content, nil
+}
+
+func (s *Service) OpenThumbnail(entityType string, entityID, id int64) (*Attachment, io.ReadCloser, error) {
+	a, err := s.get(entityType, entityID, id)
+	if err != nil {
+		return nil, nil, err
+	}
+	if !a.HasThumbnail {
+		return nil, nil, ErrNoThumbnail
+	}
+
+	content, err := s.store.Get(a.ThumbnailKey())
+	if errors.Is(err, storage.ErrNotFound) {
+		return nil, nil, ErrContentNotFound
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+	return a, content, nil
+}
+
+// Delete удаляет вложение; ошибки удаления содержимого из хранилища только логируются
+func (s *Service) Delete(entityType string, entityID, id int64) error {
+	a, err := s.get(entityType, entityID, id)
+	if err != nil {
+		return err
+	}
+	if err := s.repo.Delete(a); err != nil {
+		return err
+	}
+
+	s.removeContent(a)
+	return nil
+}
+
+func (s *Service) get(entityType string, entityID, id int64) (*Attachment, error) {
+	if _, ok := parents[entityType]; !ok {
+		return nil, ErrUnknownEntity
+	}
+
+	a, err := s.repo.Get(id)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	// вложение доступно только через свой объект, права на который проверены
+	if a.EntityType != entityType || a.EntityID != entityID {
+		return nil, ErrNotFound
+	}
+	return a, nil
+}
+
+func (s *Service) checkParent(entityType string, entityID int64) error {
+	if _, ok := parents[entityType]; !ok {
+		return ErrUnknownEntity
+	}
+
+	ok, err := s.repo.ParentExists(entityType, entityID)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return ErrParentNotFound
+	}
+	return nil
+}
+
+// addThumbnail сохраняет размеры и уменьшенную копию изображения; без копии вложение остается доступным
+func (s *Service) addThumbnail(a *Attachment, data []byte) {
+	width, height, ok := imageSize(data)
+	if !ok {
+		return
+	}
+	a.Width, a.Height = &width, &height
+
+	if width*height > maxPixels {
+		return
+	}
+
+	thumb, err := thumbnail(data)
+	if err != nil {
+		return
+	}
+	if err := s.store.Put(a.ThumbnailKey(), thumb, "image/jpeg"); err != nil {
+		slog.Error("attachment thumbnail upload failed",
+			slog.String("key", a.StorageKey),
+			slog.Any("err", err),
+		)
+		return
+	}
+	a.HasThumbnail = true
+}
+
+func (s *Service) removeContent(a *Attachment) {
+	keys := []string{a.StorageKey}
+	if a.HasThumbnail {
+		keys = append(keys, a.ThumbnailKey())
+	}
+
+	for _, key := range keys {
+		if err := s.store.Delete(key); err != nil {
+			slog.Error("attachment content removal failed",
+				slog.String("key", key),
+				slog.Any("err", err),
+			)
+		}
+	}
+}
+
+// fileName имя файла без пути, не длиннее 255 символов
+func fileName(name string) string {
+	name = strings.TrimSpace(path.Base(strings.ReplaceAll(name, "\\", "/")))
+	if name == "." || name == "/" || !utf8.ValidString(name) {
+		name = ""
+	}
+	if name == "" {
+		return "file"
+	}
+
+	if runes := []rune(name); len(runes) > maxFileNameLength {
+		ext := []rune(extension(name))
+		name = string(runes[:maxFileNameLength-len(ext)]) + string(ext)
+	}
+	return name
+}
+
+// extension расширение имени файла для ключа хранилища: латиница и цифры в нижнем регистре
+func extension(name string) string {
+	ext := strings.ToLower(path.Ext(name))
+	if len(ext) < 2 || len(ext) > maxExtensionLength {
+		return ""
+	}
+	for _, c := range ext[1:] {
+		if (c < 'a' || c > 'z') && (c < '0' || c > '9') {
+			return ""
+		}
+	}
+	return ext
+}
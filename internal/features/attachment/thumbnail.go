@@ -0,0 +1,84 @@
+package attachment
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/jpeg"
+
+	_ "image/gif"
+	_ "image/png"
+
+	_ "golang.org/x/image/webp"
+)
+
+const (
+	// thumbnailSize наибольшая сторона уменьшенной копии, пикселей
+	thumbnailSize = 320
+	// maxPixels изображения больше не декодируются целиком
+	maxPixels = 50_000_000
+)
+
+// imageSize размеры изображения без декодирования; ok = false для неизвестных форматов
+func imageSize(data []byte) (width, height int, ok bool) {
+	cfg, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return 0, 0, false
+	}
+	return cfg.Width, cfg.Height, true
+}
+
+// thumbnail уменьшенная копия изображения в JPEG; прозрачность заливается белым
+func thumbnail(data []byte) ([]byte, error) {
+	src, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+
+	b := src.Bounds()
+	w, h := b.Dx(), b.Dy()
+	if w > thumbnailSize || h > thumbnailSize {
+		if w >= h {
+			w, h = thumbnailSize, max(1, h*thumbnailSize/w)
+		} else {
+			w, h = max(1, w*thumbnailSize/h), thumbnailSize
+		}
+	}
+
+	dst := image.NewRGBA(image.Rect(0, 0, w, h))
+	for y := range h {
+		y0 := b.Min.Y + y*b.Dy()/h
+		y1 := max(y0+1, b.Min.Y+(y+1)*b.Dy()/h)
+		for x := range w {
+			x0 := b.Min.X + x*b.Dx()/w
+			x1 := max(x0+1, b.Min.X+(x+1)*b.Dx()/w)
+			dst.SetRGBA(x, y, average(src, x0, y0, x1, y1))
+		}
+	}
+
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, dst, &jpeg.Options{Quality: 80}); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// average средний цвет прямоугольника [x0, x1) × [y0, y1) на белом фоне
+func average(img image.Image, x0, y0, x1, y1 int) color.RGBA {
+	var r, g, b, n uint64
+	for y := y0; y < y1; y++ {
+		for x := x0; x < x1; x++ {
+			cr, cg, cb, ca := img.At(x, y).RGBA()
+			r += uint64(cr + 0xffff - ca)
+			g += uint64(cg + 0xffff - ca)
+			b += uint64(cb + 0xffff - ca)
+			n++
+		}
+	}
+	return color.RGBA{
+		R: uint8(r / n >> 8),
+		G: uint8(g / n >> 8),
+		B: uint8(b / n >> 8),
+		A: 0xff,
+	}
+}
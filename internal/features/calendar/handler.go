@@ -0,0 +1,391 @@
+package calendar
+
+import (
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"time"
+
+	"mes-lite-back/internal/http/middleware"
+	"mes-lite-back/pkg"
+
+	"github.com/go-chi/chi/v5"
+)
+
+type Handler struct {
+	service ServiceInterface
+	perms   middleware.PermissionChecker
+}
+
+func NewHandler(service ServiceInterface, perms middleware.PermissionChecker) *Handler {
+	return &Handler{
+		service: service,
+		perms:   perms,
+	}
+}
+
+func (h *Handler) Routes() chi.Router {
+	r := chi.NewRouter()
+
+	view := middleware.PermissionGuard(h.perms, "schedule.view")
+	edit := middleware.PermissionGuard(h.perms, "schedule.edit")
+
+	r.With(view).Get("/working-time", h.workingTime)
+	r.With(view).Get("/shifts", h.listShifts)
+	r.With(edit).Post("/shifts", h.createShift)
+	r.With(edit).Put("/shifts/{id}", h.updateShift)
+	r.With(edit).Delete("/shifts/{id}", h.deleteShift)
+	r.With(view).Get("/exceptions", h.listExceptions)
+	r.With(edit).Post("/exceptions", h.createException)
+	r.With(edit).Put("/exceptions/{id}", h.updateException)
+	r.With(edit).Delete("/exceptions/{id}", h.deleteException)
+
+	return r
+}
+
+type ShiftRequest struct {
+	Name      string `json:"name" example:"Первая смена"`
+	LineID    *int64 `json:"line_id,omitempty"`
+	StartTime string `json:"start_time" example:"08:00"`
+	EndTime   string `json:"end_time" example:"16:00"`
+	// Weekdays по умолчанию — ежедневно (127)
+	Weekdays *int  `json:"weekdays,omitempty" example:"31"`
+	Active   *bool `json:"active,omitempty" example:"true"`
+}
+
+type ExceptionRequest struct {
+	Kind      string    `json:"kind" example:"holiday"`
+	Name      string    `json:"name" example:"Новый год"`
+	LineID    *int64    `json:"line_id,omitempty"`
+	StartTime time.Time `json:"start_time" example:"2027-01-01T00:00:00Z"`
+	EndTime   time.Time `json:"end_time" example:"2027-01-02T00:00:00Z"`
+}
+
+type ErrorResponse struct {
+	Error string `json:"error" example:"Описание ошибки"`
+}
+
+// WorkingTime godoc
+// @Summary Рабочее время за период
+// @Description Рабочие периоды и их сумма в минутах с учетом смен, праздников, остановок и исключений линии
+// @Tags calendar
+// @Security BearerAuth
+// @Produce json
+// @Param from query string true "Начало периода (RFC3339 или ГГГГ-ММ-ДД)"
+// @Param to query string true "Конец периода (RFC3339 или ГГГГ-ММ-ДД включительно)"
+// @Param line_id query int false "ID линии; без него — общезаводской календарь"
+// @Success 200 {object} WorkingTime
+// @Failure 400 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Router /calendar/working-time [get]
+func (h *Handler) workingTime(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	lineID, _ := strconv.ParseInt(query.Get("line_id"), 10, 64)
+
+	from, ok := parseBound(w, query.Get("from"), false)
+	if !ok {
+		return
+	}
+	to, ok := parseBound(w, query.Get("to"), true)
+	if !ok {
+		return
+	}
+	if from == nil || to == nil {
+		pkg.RespondJSON(w, http.StatusBadRequest, ErrorResponse{Error: "Укажите период from и to"})
+		return
+	}
+
+	wt, err := h.service.WorkingTime(lineID, *from, *to)
+	if err != nil {
+		h.respondError(w, err)
+		return
+	}
+	pkg.RespondJSON(w, http.StatusOK, wt)
+}
+
+// ListShifts godoc
+// @Summary Получить смены
+// @Description Смены завода или линии; all=true — все смены
+// @Tags calendar
+// @Security BearerAuth
+// @Produce json
+// @Param line_id query int false "ID линии"
+// @Param all query bool false "Все смены, включая смены линий"
+// @Success 200 {array} Shift
+// @Failure 404 {object} ErrorResponse
+// @Router /calendar/shifts [get]
+func (h *Handler) listShifts(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	lineID, _ := strconv.ParseInt(query.Get("line_id"), 10, 64)
+	all, _ := strconv.ParseBool(query.Get("all"))
+
+	shifts, err := h.service.ListShifts(lineID, all)
+	if err != nil {
+		h.respondError(w, err)
+		return
+	}
+	pkg.RespondJSON(w, http.StatusOK, shifts)
+}
+
+// CreateShift godoc
+// @Summary Создать смену
+// @Description Время ЧЧ:ММ, окончание не позже начала — смена через полночь; weekdays — маска дней (пн=1 … вс=64)
+// @Tags calendar
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param request body ShiftRequest true "Смена"
+// @Success 201 {object} Shift
+// @Failure 400 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Router /calendar/shifts [post]
+func (h *Handler) createShift(w http.ResponseWriter, r *http.Request) {
+	var req ShiftRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		pkg.RespondJSON(w, http.StatusBadRequest, ErrorResponse{Error: "Некорректный JSON"})
+		return
+	}
+
+	sh := req.shift()
+	if err := h.service.CreateShift(sh); err != nil {
+		h.respondError(w, err)
+		return
+	}
+	pkg.RespondJSON(w, http.StatusCreated, sh)
+}
+
+// UpdateShift godoc
+// @Summary Обновить смену
+// @Tags calendar
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param id path int true "ID смены"
+// @Param request body ShiftRequest true "Смена"
+// @Success 200 {object} Shift
+// @Failure 400 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Router /calendar/shifts/{id} [put]
+func (h *Handler) updateShift(w http.ResponseWriter, r *http.Request) {
+	var req ShiftRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		pkg.RespondJSON(w, http.StatusBadRequest, ErrorResponse{Error: "Некорректный JSON"})
+		return
+	}
+
+	sh := req.shift()
+	sh.ID = pkg.ParamID(r)
+
+	if err := h.service.UpdateShift(sh); err != nil {
+		h.respondError(w, err)
+		return
+	}
+	pkg.RespondJSON(w, http.StatusOK, sh)
+}
+
+// DeleteShift godoc
+// @Summary Удалить смену
+// @Tags calendar
+// @Security BearerAuth
+// @Param id path int true "ID смены"
+// @Success 204
+// @Failure 404 {object} ErrorResponse
+// @Router /calendar/shifts/{id} [delete]
+func (h *Handler) deleteShift(w http.ResponseWriter, r *http.Request) {
+	if err := h.service.DeleteShift(pkg.ParamID(r)); err != nil {
+		h.respondError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// ListExceptions godoc
+// @Summary Получить исключения календаря
+// @Description Праздники, плановые остановки и дополнительное рабочее время, пересекающиеся с периодом
+// @Tags calendar
+// @Security BearerAuth
+// @Produce json
+// @Param from query string false "Начало периода (RFC3339 или ГГГГ-ММ-ДД)"
+// @Param to query string false "Конец периода (RFC3339 или ГГГГ-ММ-ДД включительно)"
+// @Param line_id query int false "ID линии"
+// @Param kind query string false "Вид: holiday, shutdown, working"
+// @Success 200 {array} Exception
+// @Failure 400 {object} ErrorResponse
+// @Router /calendar/exceptions [get]
+func (h *Handler) listExceptions(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	lineID, _ := strconv.ParseInt(query.Get("line_id"), 10, 64)
+
+	from, ok := parseBound(w, query.Get("from"), false)
+	if !ok {
+		return
+	}
+	to, ok := parseBound(w, query.Get("to"), true)
+	if !ok {
+		return
+	}
+
+	exceptions, err := h.service.ListExceptions(ExceptionFilter{
+		From:   from,
+		To:     to,
+		LineID: lineID,
+		Kind:   query.Get("kind"),
+	})
+	if err != nil {
+		h.respondError(w, err)
+		return
+	}
+	pkg.RespondJSON(w, http.StatusOK, exceptions)
+}
+
+// CreateException godoc
+// @Summary Добавить исключение календаря
+// @Description holiday и shutdown исключают время из рабочего, working добавляет; без line_id — для всего завода
+// @Tags calendar
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param request body ExceptionRequest true "Исключение"
+// @Success 201 {object} Exception
+// @Failure 400 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Router /calendar/exceptions [post]
+func (h *Handler) createException(w http.ResponseWriter, r *http.Request) {
+	var req ExceptionRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		pkg.RespondJSON(w, http.StatusBadRequest, ErrorResponse{Error: "Некорректный JSON"})
+		return
+	}
+
+	e := req.exception()
+	userID, _ := middleware.UserIDFromContext(r.Context())
+	e.CreatedBy = &userID
+
+	if err := h.service.CreateException(e); err != nil {
+		h.respondError(w, err)
+		return
+	}
+	pkg.RespondJSON(w, http.StatusCreated, e)
+}
+
+// UpdateException godoc
+// @Summary Обновить исключение календаря
+// @Tags calendar
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param id path int true "ID исключения"
+// @Param request body ExceptionRequest true "Исключение"
+// @Success 200 {object} Exception
+// @Failure 400 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Router /calendar/exceptions/{id} [put]
+func (h *Handler) updateException(w http.ResponseWriter, r *http.Request) {
+	var req ExceptionRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		pkg.RespondJSON(w, http.StatusBadRequest, ErrorResponse{Error: "Некорректный JSON"})
+		return
+	}
+
+	e := req.exception()
+	e.ID = pkg.ParamID(r)
+
+	if err := h.service.UpdateException(e); err != nil {
+		h.respondError(w, err)
+		return
+	}
+	pkg.RespondJSON(w, http.StatusOK, e)
+}
+
+// DeleteException godoc
+// @Summary Удалить исключение календаря
+// @Tags calendar
+// @Security BearerAuth
+// @Param id path int true "ID исключения"
+// @Success 204
+// @Failure 404 {object} ErrorResponse
+// @Router /calendar/exceptions/{id} [delete]
+func (h *Handler) deleteException(w http.ResponseWriter, r *http.Request) {
+	if err := h.service.DeleteException(pkg.ParamID(r)); err != nil {
+		h.respondError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (req ShiftRequest) shift() *Shift {
+	sh := &Shift{
+		Name:      req.Name,
+		LineID:    req.LineID,
+		StartTime: req.StartTime,
+		EndTime:   req.EndTime,
+		Weekdays:  EveryDay,
+		Active:    true,
+	}
+	if req.Weekdays != nil {
+		sh.Weekdays = *req.Weekdays
+	}
+	if req.Active != nil {
+		sh.Active = *req.Active
+	}
+	return sh
+}
+
+func (req ExceptionRequest) exception() *Exception {
+	return &Exception{
+		Kind:      req.Kind,
+		Name:      req.Name,
+		LineID:    req.LineID,
+		StartTime: req.StartTime,
+		EndTime:   req.EndTime,
+	}
+}
+
+// parseBound разбирает границу периода в RFC3339 или ГГГГ-ММ-ДД; пустая строка — без границы.
+// Дата в конце периода включает весь день.
+func parseBound(w http.ResponseWriter, value string, end bool) (*time.Time, bool) {
+	if value == "" {
+		return nil, true
+	}
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return &t, true
+	}
+
+	t, err := time.ParseInLocation(time.DateOnly, value, time.Local)
+	if err != nil {
+		pkg.RespondJSON(w, http.StatusBadRequest, ErrorResponse{Error: "Время должно быть в формате RFC3339 или ГГГГ-ММ-ДД"})
+		return nil, false
+	}
+	if end {
+		t = t.AddDate(0, 0, 1)
+	}
+	return &t, true
+}
+
+func (h *Handler) respondError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, ErrShiftNotFound):
+		pkg.RespondJSON(w, http.StatusNotFound, ErrorResponse{Error: "Смена не найдена"})
+	case errors.Is(err, ErrExceptionNotFound):
+		pkg.RespondJSON(w, http.StatusNotFound, ErrorResponse{Error: "Исключение календаря не найдено"})
+	case errors.Is(err, ErrLineNotFound):
+		pkg.RespondJSON(w, http.StatusNotFound, ErrorResponse{Error: "Линия не найдена"})
+	case errors.Is(err, ErrNameRequired):
+		pkg.RespondJSON(w, http.StatusBadRequest, ErrorResponse{Error: "Укажите название"})
+	case errors.Is(err, ErrInvalidTime):
+		pkg.RespondJSON(w, http.StatusBadRequest, ErrorResponse{Error: "Время смены должно быть в формате ЧЧ:ММ"})
+	case errors.Is(err, ErrInvalidWeekdays):
+		pkg.RespondJSON(w, http.StatusBadRequest, ErrorResponse{Error: "Маска дней недели должна быть от 1 до 127"})
+	case errors.Is(err, ErrInvalidKind):
+		pkg.RespondJSON(w, http.StatusBadRequest, ErrorResponse{Error: "Вид исключения: holiday, shutdown или working"})
+	case errors.Is(err, ErrInvalidPeriod):
+		pkg.RespondJSON(w, http.StatusBadRequest, ErrorResponse{Error: "Конец периода должен быть позже начала"})
+	case errors.Is(err, ErrRangeTooLong):
+		pkg.RespondJSON(w, http.StatusBadRequest, ErrorResponse{Error: "Период не должен превышать 400 дней"})
+	default:
+		slog.Error("calendar request failed", slog.Any("err", err))
+		pkg.RespondJSON(w, http.StatusInternalServerError, ErrorResponse{Error: "Внутренняя ошибка сервера"})
+	}
+}
@@ -0,0 +1,82 @@
+package calendar
+
+import "time"
+
+// Виды исключений заводского календаря
+const (
+	KindHoliday  = "holiday"  // праздник: нерабочее время
+	KindShutdown = "shutdown" // плановая остановка: нерабочее время
+	KindWorking  = "working"  // рабочее время сверх смен
+)
+
+// EveryDay маска weekdays смены, работающей ежедневно
+const EveryDay = 127
+
+// Shift смена; EndTime не позже StartTime означает переход через полночь.
+// Weekdays — битовая маска дней начала смены: пн=1, вт=2, ср=4, чт=8, пт=16, сб=32, вс=64.
+// Смены линии (LineID) заменяют для нее общезаводские.
+type Shift struct {
+	ID        int64     `gorm:"primaryKey;autoIncrement" json:"id"`
+	Name      string    `gorm:"not null" json:"name"`
+	LineID    *int64    `json:"line_id,omitempty"`
+	StartTime string    `gorm:"not null" json:"start_time" example:"08:00"`
+	EndTime   string    `gorm:"not null" json:"end_time" example:"16:00"`
+	Weekdays  int       `json:"weekdays" example:"31"`
+	Active    bool      `json:"active"`
+	CreatedAt time.Time `gorm:"autoCreateTime" json:"created_at"`
+}
+
+func (Shift) TableName() string {
+	return "shifts"
+}
+
+// RunsOn начинается ли смена в день недели wd
+func (s *Shift) RunsOn(wd time.Weekday) bool {
+	bit := (int(wd) + 6) % 7 // понедельник — младший бит
+	return s.Weekdays&(1<<bit) != 0
+}
+
+// Exception праздник, плановая остановка или дополнительное рабочее время;
+// без LineID действует на весь завод
+type Exception struct {
+	ID        int64     `gorm:"primaryKey;autoIncrement" json:"id"`
+	Kind      string    `gorm:"not null" json:"kind" example:"holiday"`
+	Name      string    `gorm:"not null" json:"name"`
+	LineID    *int64    `json:"line_id,omitempty"`
+	StartTime time.Time `gorm:"not null" json:"start_time"`
+	EndTime   time.Time `gorm:"not null" json:"end_time"`
+	CreatedBy *int64    `json:"created_by,omitempty"`
+	CreatedAt time.Time `gorm:"autoCreateTime" json:"created_at"`
+}
+
+func (Exception) TableName() string {
+	return "calendar_exceptions"
+}
+
+// ExceptionFilter параметры выборки исключений; From/To отбирают пересекающиеся с периодом
+type ExceptionFilter struct {
+	From   *time.Time
+	To     *time.Time
+	LineID int64
+	Kind   string
+}
+
+// Interval рабочий период [Start, End)
+type Interval struct {
+	Start time.Time `json:"start"`
+	End   time.Time `json:"end"`
+}
+
+// Minutes длительность периода в минутах
+func (iv Interval) Minutes() float64 {
+	return iv.End.Sub(iv.Start).Minutes()
+}
+
+// WorkingTime рабочее время линии (или завода) за период
+type WorkingTime struct {
+	LineID    *int64     `json:"line_id,omitempty"`
+	From      time.Time  `json:"from"`
+	To        time.Time  `json:"to"`
+	Minutes   float64    `json:"minutes"`
+	Intervals []Interval `json:"intervals"`
+}
@@ -0,0 +1,23 @@
+package calendar
+
+import "time"
+
+type Repository interface {
+	CreateShift(s *Shift) error
+	UpdateShift(s *Shift) error
+	DeleteShift(s *Shift) error
+	GetShift(id int64) (*Shift, error)
+	// ListShifts смены завода (lineID = 0) или линии
+	ListShifts(lineID int64) ([]*Shift, error)
+	ListAllShifts() ([]*Shift, error)
+
+	CreateException(e *Exception) error
+	UpdateException(e *Exception) error
+	DeleteException(e *Exception) error
+	GetException(id int64) (*Exception, error)
+	ListExceptions(filter ExceptionFilter) ([]*Exception, error)
+	// ExceptionsFor общезаводские исключения и исключения линии, пересекающиеся с периодом
+	ExceptionsFor(lineID int64, from, to time.Time) ([]*Exception, error)
+
+	LineExists(id int64) (bool, error)
+}
@@ -0,0 +1,114 @@
+package calendar
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type GormRepository struct {
+	db *gorm.DB
+}
+
+func NewGormRepository(db *gorm.DB) *GormRepository {
+	return &GormRepository{db: db}
+}
+
+func (r *GormRepository) CreateShift(s *Shift) error {
+	return r.db.Create(s).Error
+}
+
+func (r *GormRepository) UpdateShift(s *Shift) error {
+	return r.db.Save(s).Error
+}
+
+func (r *GormRepository) DeleteShift(s *Shift) error {
+	return r.db.Delete(s).Error
+}
+
+func (r *GormRepository) GetShift(id int64) (*Shift, error) {
+	var s Shift
+	if err := r.db.First(&s, id).Error; err != nil {
+		return nil, err
+	}
+	return &s, nil
+}
+
+func (r *GormRepository) ListShifts(lineID int64) ([]*Shift, error) {
+	var shifts []*Shift
+
+	q := r.db.Order("start_time, id")
+	if lineID > 0 {
+		q = q.Where("line_id = ?", lineID)
+	} else {
+		q = q.Where("line_id IS NULL")
+	}
+
+	return shifts, q.Find(&shifts).Error
+}
+
+func (r *GormRepository) ListAllShifts() ([]*Shift, error) {
+	var shifts []*Shift
+	return shifts, r.db.Order("line_id NULLS FIRST, start_time, id").Find(&shifts).Error
+}
+
+func (r *GormRepository) CreateException(e *Exception) error {
+	return r.db.Create(e).Error
+}
+
+func (r *GormRepository) UpdateException(e *Exception) error {
+	return r.db.Save(e).Error
+}
+
+func (r *GormRepository) DeleteException(e *Exception) error {
+	return r.db.Delete(e).Error
+}
+
+func (r *GormRepository) GetException(id int64) (*Exception, error) {
+	var e Exception
+	if err := r.db.First(&e, id).Error; err != nil {
+		return nil, err
+	}
+	return &e, nil
+}
+
+func (r *GormRepository) ListExceptions(filter ExceptionFilter) ([]*Exception, error) {
+	var exceptions []*Exception
+
+	q := r.db.Order("start_time, id")
+	if filter.From != nil {
+		q = q.Where("end_time > ?", *filter.From)
+	}
+	if filter.To != nil {
+		q = q.Where("start_time < ?", *filter.To)
+	}
+	if filter.LineID > 0 {
+		q = q.Where("line_id = ?", filter.LineID)
+	}
+	if filter.Kind != "" {
+		q = q.Where("kind = ?", filter.Kind)
+	}
+
+	return exceptions, q.Find(&exceptions).Error
+}
+
+func (r *GormRepository) ExceptionsFor(lineID int64, from, to time.Time) ([]*Exception, error) {
+	var exceptions []*Exception
+
+	q := r.db.
+		Where("end_time > ? AND start_time < ?", from, to).
+		Order("start_time")
+	if lineID > 0 {
+		q = q.Where("line_id IS NULL OR line_id = ?", lineID)
+	} else {
+		q = q.Where("line_id IS NULL")
+	}
+
+	return exceptions, q.Find(&exceptions).Error
+}
+
+func (r *GormRepository) LineExists(id int64) (bool, error) {
+	var n int64
+	err := r.db.Table("lines").Where("id = ?", id).Count(&n).Error
+	return n > 0, err
+}
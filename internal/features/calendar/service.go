@@ -0,0 +1,216 @@
+package calendar
+
+import (
+	"errors"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// MaxRange наибольший период расчета рабочего времени
+const MaxRange = 400 * 24 * time.Hour
+
+var (
+	ErrShiftNotFound     = errors.New("shift not found")
+	ErrExceptionNotFound = errors.New("calendar exception not found")
+	ErrLineNotFound      = errors.New("line not found")
+	ErrNameRequired      = errors.New("name required")
+	ErrInvalidTime       = errors.New("shift time must be HH:MM")
+	ErrInvalidWeekdays   = errors.New("weekdays mask must be between 1 and 127")
+	ErrInvalidKind       = errors.New("unknown calendar exception kind")
+	ErrInvalidPeriod     = errors.New("period end must be after start")
+	ErrRangeTooLong      = errors.New("range is too long")
+)
+
+// ServiceInterface определяет методы, используемые handler’ом
+type ServiceInterface interface {
+	ListShifts(lineID int64, all bool) ([]*Shift, error)
+	CreateShift(s *Shift) error
+	UpdateShift(s *Shift) error
+	DeleteShift(id int64) error
+
+	ListExceptions(filter ExceptionFilter) ([]*Exception, error)
+	CreateException(e *Exception) error
+	UpdateException(e *Exception) error
+	DeleteException(id int64) error
+
+	WorkingTime(lineID int64, from, to time.Time) (*WorkingTime, error)
+}
+
+type Service struct {
+	repo Repository
+	// loc часовой пояс завода: в нем заданы время начала и окончания смен
+	loc *time.Location
+}
+
+// NewService календарь завода в часовом поясе loc; nil — UTC
+func NewService(repo Repository, loc *time.Location) *Service {
+	if loc == nil {
+		loc = time.UTC
+	}
+	return &Service{repo: repo, loc: loc}
+}
+
+// ListShifts смены завода или линии; all — все смены, включая смены линий
+func (s *Service) ListShifts(lineID int64, all bool) ([]*Shift, error) {
+	if all {
+		return s.repo.ListAllShifts()
+	}
+	if err := s.checkLine(lineID); err != nil {
+		return nil, err
+	}
+	return s.repo.ListShifts(lineID)
+}
+
+func (s *Service) CreateShift(sh *Shift) error {
+	if err := s.validateShift(sh); err != nil {
+		return err
+	}
+	return s.repo.CreateShift(sh)
+}
+
+func (s *Service) UpdateShift(sh *Shift) error {
+	existing, err := s.getShift(sh.ID)
+	if err != nil {
+		return err
+	}
+	if err := s.validateShift(sh); err != nil {
+		return err
+	}
+
+	existing.Name = sh.Name
+	existing.LineID = sh.LineID
+	existing.StartTime = sh.StartTime
+	existing.EndTime = sh.EndTime
+	existing.Weekdays = sh.Weekdays
+	existing.Active = sh.Active
+
+	if err := s.repo.UpdateShift(existing); err != nil {
+		return err
+	}
+
+	*sh = *existing
+	return nil
+}
+
+func (s *Service) DeleteShift(id int64) error {
+	sh, err := s.getShift(id)
+	if err != nil {
+		return err
+	}
+	return s.repo.DeleteShift(sh)
+}
+
+func (s *Service) ListExceptions(filter ExceptionFilter) ([]*Exception, error) {
+	if filter.From != nil && filter.To != nil && !filter.To.After(*filter.From) {
+		return nil, ErrInvalidPeriod
+	}
+	return s.repo.ListExceptions(filter)
+}
+
+func (s *Service) CreateException(e *Exception) error {
+	if err := s.validateException(e); err != nil {
+		return err
+	}
+	return s.repo.CreateException(e)
+}
+
+func (s *Service) UpdateException(e *Exception) error {
+	existing, err := s.getException(e.ID)
+	if err != nil {
+		return err
+	}
+	if err := s.validateException(e); err != nil {
+		return err
+	}
+
+	existing.Kind = e.Kind
+	existing.Name = e.Name
+	existing.LineID = e.LineID
+	existing.StartTime = e.StartTime
+	existing.EndTime = e.EndTime
+
+	if err := s.repo.UpdateException(existing); err != nil {
+		return err
+	}
+
+	*e = *existing
+	return nil
+}
+
+func (s *Service) DeleteException(id int64) error {
+	e, err := s.getException(id)
+	if err != nil {
+		return err
+	}
+	return s.repo.DeleteException(e)
+}
+
+func (s *Service) validateShift(sh *Shift) error {
+	sh.Name = strings.TrimSpace(sh.Name)
+	if sh.Name == "" {
+		return ErrNameRequired
+	}
+	if _, err := time.Parse(shiftLayout, sh.StartTime); err != nil {
+		return ErrInvalidTime
+	}
+	if _, err := time.Parse(shiftLayout, sh.EndTime); err != nil {
+		return ErrInvalidTime
+	}
+	if sh.Weekdays < 1 || sh.Weekdays > EveryDay {
+		return ErrInvalidWeekdays
+	}
+	if sh.LineID != nil {
+		return s.checkLine(*sh.LineID)
+	}
+	return nil
+}
+
+func (s *Service) validateException(e *Exception) error {
+	e.Name = strings.TrimSpace(e.Name)
+	if e.Name == "" {
+		return ErrNameRequired
+	}
+	if e.Kind != KindHoliday && e.Kind != KindShutdown && e.Kind != KindWorking {
+		return ErrInvalidKind
+	}
+	if !e.EndTime.After(e.StartTime) {
+		return ErrInvalidPeriod
+	}
+	if e.LineID != nil {
+		return s.checkLine(*e.LineID)
+	}
+	return nil
+}
+
+func (s *Service) getShift(id int64) (*Shift, error) {
+	sh, err := s.repo.GetShift(id)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrShiftNotFound
+	}
+	return sh, err
+}
+
+func (s *Service) getException(id int64) (*Exception, error) {
+	e, err := s.repo.GetException(id)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrExceptionNotFound
+	}
+	return e, err
+}
+
+// checkLine lineID = 0 — весь завод
+func (s *Service) checkLine(lineID int64) error {
+	if lineID == 0 {
+		return nil
+	}
+	ok, err := s.repo.LineExists(lineID)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return ErrLineNotFound
+	}
+	return nil
+}
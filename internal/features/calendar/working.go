@@ -0,0 +1,183 @@
+package calendar
+
+import (
+	"slices"
+	"time"
+)
+
+// shiftLayout формат времени начала и окончания смены
+const shiftLayout = "15:04"
+
+// WorkingTime рабочее время линии (lineID = 0 — завода) за период [from, to)
+func (s *Service) WorkingTime(lineID int64, from, to time.Time) (*WorkingTime, error) {
+	if !to.After(from) {
+		return nil, ErrInvalidPeriod
+	}
+	if to.Sub(from) > MaxRange {
+		return nil, ErrRangeTooLong
+	}
+	if err := s.checkLine(lineID); err != nil {
+		return nil, err
+	}
+
+	intervals, err := s.WorkingIntervals(lineID, from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	wt := &WorkingTime{From: from, To: to, Intervals: intervals}
+	if lineID > 0 {
+		wt.LineID = &lineID
+	}
+	for _, iv := range intervals {
+		wt.Minutes += iv.Minutes()
+	}
+	return wt, nil
+}
+
+// WorkingIntervals рабочие периоды линии внутри [from, to), упорядоченные и без пересечений.
+//
+// Основа — активные смены линии, а если у линии их нет, общезаводские; без смен завод
+// считается работающим круглосуточно. К основе добавляются исключения working,
+// затем вычитаются holiday и shutdown — общезаводские и линии.
+func (s *Service) WorkingIntervals(lineID int64, from, to time.Time) ([]Interval, error) {
+	shifts, err := s.activeShifts(lineID)
+	if err != nil {
+		return nil, err
+	}
+	exceptions, err := s.repo.ExceptionsFor(lineID, from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	var base []Interval
+	if len(shifts) == 0 {
+		base = []Interval{{Start: from, End: to}}
+	} else {
+		base = expandShifts(shifts, from, to, s.loc)
+	}
+
+	var cut []Interval
+	for _, e := range exceptions {
+		iv := Interval{Start: e.StartTime, End: e.EndTime}
+		if e.Kind == KindWorking {
+			base = append(base, iv)
+		} else {
+			cut = append(cut, iv)
+		}
+	}
+
+	return clip(subtract(merge(base), merge(cut)), from, to), nil
+}
+
+func (s *Service) activeShifts(lineID int64) ([]*Shift, error) {
+	if lineID > 0 {
+		shifts, err := s.repo.ListShifts(lineID)
+		if err != nil {
+			return nil, err
+		}
+		if active := onlyActive(shifts); len(active) > 0 {
+			return active, nil
+		}
+	}
+
+	shifts, err := s.repo.ListShifts(0)
+	if err != nil {
+		return nil, err
+	}
+	return onlyActive(shifts), nil
+}
+
+func onlyActive(shifts []*Shift) []*Shift {
+	return slices.DeleteFunc(shifts, func(sh *Shift) bool { return !sh.Active })
+}
+
+// expandShifts периоды смен по дням в часовом поясе завода loc, независимо от пояса from и to;
+// день до from учитывается для ночных смен
+func expandShifts(shifts []*Shift, from, to time.Time, loc *time.Location) []Interval {
+	var out []Interval
+
+	y, m, d := from.In(loc).Date()
+	day := time.Date(y, m, d, 0, 0, 0, 0, loc).AddDate(0, 0, -1)
+
+	for ; day.Before(to); day = day.AddDate(0, 0, 1) {
+		for _, sh := range shifts {
+			if !sh.RunsOn(day.Weekday()) {
+				continue
+			}
+			start, _ := time.Parse(shiftLayout, sh.StartTime)
+			end, _ := time.Parse(shiftLayout, sh.EndTime)
+
+			iv := Interval{
+				Start: time.Date(day.Year(), day.Month(), day.Day(), start.Hour(), start.Minute(), 0, 0, day.Location()),
+				End:   time.Date(day.Year(), day.Month(), day.Day(), end.Hour(), end.Minute(), 0, 0, day.Location()),
+			}
+			if !iv.End.After(iv.Start) {
+				iv.End = iv.End.AddDate(0, 0, 1)
+			}
+			out = append(out, iv)
+		}
+	}
+	return out
+}
+
+// merge упорядочивает периоды и объединяет пересекающиеся и смежные
+func merge(ivs []Interval) []Interval {
+	sorted := slices.Clone(ivs)
+	slices.SortFunc(sorted, func(a, b Interval) int { return a.Start.Compare(b.Start) })
+
+	var out []Interval
+	for _, iv := range sorted {
+		if n := len(out); n > 0 && !iv.Start.After(out[n-1].End) {
+			if iv.End.After(out[n-1].End) {
+				out[n-1].End = iv.End
+			}
+			continue
+		}
+		out = append(out, iv)
+	}
+	return out
+}
+
+// subtract вычитает из base периоды cut; оба списка упорядочены и без пересечений
+func subtract(base, cut []Interval) []Interval {
+	var out []Interval
+	for _, iv := range base {
+		rest := []Interval{iv}
+		for _, c := range cut {
+			var next []Interval
+			for _, r := range rest {
+				if !c.Start.Before(r.End) || !r.Start.Before(c.End) {
+					next = append(next, r)
+					continue
+				}
+				if r.Start.Before(c.Start) {
+					next = append(next, Interval{Start: r.Start, End: c.Start})
+				}
+				if c.End.Before(r.End) {
+					next = append(next, Interval{Start: c.End, End: r.End})
+				}
+			}
+			rest = next
+		}
+		out = append(out, rest...)
+	}
+	return out
+}
+
+// clip обрезает периоды по границам [from, to)
+func clip(ivs []Interval, from, to time.Time) []Interval {
+	out := make([]Interval, 0, len(ivs))
+	for _, iv := range ivs {
+		if iv.Start.Before(from) {
+			iv.Start = from
+		}
+		if iv.End.After(to) {
+			iv.End = to
+		}
+		if iv.End.After(iv.Start) {
+			out = append(out, iv)
+		}
+	}
+	return out
+}
@@ -0,0 +1,266 @@
+package calendar
+
+import (
+	"testing"
+	"time"
+	_ "time/tzdata"
+)
+
+func utc(day, hour, minute int) time.Time {
+	return time.Date(2026, 11, 2+day, hour, minute, 0, 0, time.UTC)
+}
+
+func iv(start, end time.Time) Interval {
+	return Interval{Start: start, End: end}
+}
+
+func equalIntervals(a, b []Interval) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if !a[i].Start.Equal(b[i].Start) || !a[i].End.Equal(b[i].End) {
+			return false
+		}
+	}
+	return true
+}
+
+func TestMerge(t *testing.T) {
+	tests := []struct {
+		name string
+		in   []Interval
+		want []Interval
+	}{
+		{"пусто", nil, nil},
+		{"без пересечений по порядку", []Interval{iv(utc(0, 10, 0), utc(0, 11, 0)), iv(utc(0, 8, 0), utc(0, 9, 0))},
+			[]Interval{iv(utc(0, 8, 0), utc(0, 9, 0)), iv(utc(0, 10, 0), utc(0, 11, 0))}},
+		{"пересекающиеся", []Interval{iv(utc(0, 8, 0), utc(0, 10, 0)), iv(utc(0, 9, 0), utc(0, 11, 0))},
+			[]Interval{iv(utc(0, 8, 0), utc(0, 11, 0))}},
+		{"смежные", []Interval{iv(utc(0, 8, 0), utc(0, 9, 0)), iv(utc(0, 9, 0), utc(0, 10, 0))},
+			[]Interval{iv(utc(0, 8, 0), utc(0, 10, 0))}},
+		{"вложенный", []Interval{iv(utc(0, 8, 0), utc(0, 12, 0)), iv(utc(0, 9, 0), utc(0, 10, 0)), iv(utc(0, 11, 0), utc(0, 13, 0))},
+			[]Interval{iv(utc(0, 8, 0), utc(0, 13, 0))}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := merge(tt.in); !equalIntervals(got, tt.want) {
+				t.Errorf("merge = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSubtract(t *testing.T) {
+	day := []Interval{iv(utc(0, 8, 0), utc(0, 16, 0))}
+
+	tests := []struct {
+		name string
+		base []Interval
+		cut  []Interval
+		want []Interval
+	}{
+		{"без вычитания", day, nil, day},
+		{"обед посередине", day, []Interval{iv(utc(0, 12, 0), utc(0, 13, 0))},
+			[]Interval{iv(utc(0, 8, 0), utc(0, 12, 0)), iv(utc(0, 13, 0), utc(0, 16, 0))}},
+		{"срез начала", day, []Interval{iv(utc(0, 6, 0), utc(0, 9, 0))}, []Interval{iv(utc(0, 9, 0), utc(0, 16, 0))}},
+		{"срез конца", day, []Interval{iv(utc(0, 15, 0), utc(0, 18, 0))}, []Interval{iv(utc(0, 8, 0), utc(0, 15, 0))}},
+		{"весь период", day, []Interval{iv(utc(0, 0, 0), utc(1, 0, 0))}, nil},
+		{"смежный не режет", day, []Interval{iv(utc(0, 16, 0), utc(0, 18, 0))}, day},
+		{"несколько вырезов", day, []Interval{iv(utc(0, 9, 0), utc(0, 10, 0)), iv(utc(0, 12, 0), utc(0, 13, 0))},
+			[]Interval{iv(utc(0, 8, 0), utc(0, 9, 0)), iv(utc(0, 10, 0), utc(0, 12, 0)), iv(utc(0, 13, 0), utc(0, 16, 0))}},
+		{"вырез на два периода", []Interval{iv(utc(0, 8, 0), utc(0, 12, 0)), iv(utc(0, 13, 0), utc(0, 17, 0))},
+			[]Interval{iv(utc(0, 11, 0), utc(0, 14, 0))},
+			[]Interval{iv(utc(0, 8, 0), utc(0, 11, 0)), iv(utc(0, 14, 0), utc(0, 17, 0))}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := subtract(tt.base, tt.cut); !equalIntervals(got, tt.want) {
+				t.Errorf("subtract = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestClip(t *testing.T) {
+	in := []Interval{
+		iv(utc(0, 6, 0), utc(0, 9, 0)),
+		iv(utc(0, 10, 0), utc(0, 11, 0)),
+		iv(utc(0, 15, 0), utc(0, 18, 0)),
+		iv(utc(0, 19, 0), utc(0, 20, 0)),
+	}
+	want := []Interval{
+		iv(utc(0, 8, 0), utc(0, 9, 0)),
+		iv(utc(0, 10, 0), utc(0, 11, 0)),
+		iv(utc(0, 15, 0), utc(0, 16, 0)),
+	}
+	if got := clip(in, utc(0, 8, 0), utc(0, 16, 0)); !equalIntervals(got, want) {
+		t.Errorf("clip = %v, want %v", got, want)
+	}
+}
+
+func TestShiftRunsOn(t *testing.T) {
+	weekdays := &Shift{Weekdays: 31}
+	weekend := &Shift{Weekdays: 32 | 64}
+	for wd := time.Sunday; wd <= time.Saturday; wd++ {
+		workday := wd != time.Saturday && wd != time.Sunday
+		if weekdays.RunsOn(wd) != workday || weekend.RunsOn(wd) == workday {
+			t.Errorf("RunsOn(%s) mismatch", wd)
+		}
+	}
+}
+
+func TestExpandShifts(t *testing.T) {
+	moscow, err := time.LoadLocation("Europe/Moscow")
+	if err != nil {
+		t.Fatal(err)
+	}
+	berlin, err := time.LoadLocation("Europe/Berlin")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	day := &Shift{StartTime: "08:00", EndTime: "16:00", Weekdays: EveryDay}
+	night := &Shift{StartTime: "22:00", EndTime: "06:00", Weekdays: EveryDay}
+	monday := &Shift{StartTime: "08:00", EndTime: "16:00", Weekdays: 1}
+
+	tests := []struct {
+		name     string
+		shifts   []*Shift
+		from, to time.Time
+		loc      *time.Location
+		want     []Interval
+	}{
+		{
+			// 2 ноября 2026 — понедельник; смена 8–16 по Москве — 5–13 UTC
+			name:   "смена в поясе завода",
+			shifts: []*Shift{monday},
+			from:   utc(0, 0, 0), to: utc(1, 0, 0),
+			loc:  moscow,
+			want: []Interval{iv(utc(0, 5, 0), utc(0, 13, 0))},
+		},
+		{
+			name:   "пояс from не влияет на дни смен",
+			shifts: []*Shift{monday},
+			from:   utc(0, 0, 0).In(time.FixedZone("PST", -8*60*60)), to: utc(1, 0, 0),
+			loc:  time.UTC,
+			want: []Interval{iv(utc(0, 8, 0), utc(0, 16, 0))},
+		},
+		{
+			name:   "ночная смена предыдущего дня",
+			shifts: []*Shift{night},
+			from:   utc(0, 0, 0), to: utc(0, 23, 0),
+			loc: time.UTC,
+			want: []Interval{
+				iv(utc(-1, 22, 0), utc(0, 6, 0)),
+				iv(utc(0, 22, 0), utc(1, 6, 0)),
+			},
+		},
+		{
+			// 29 марта 2026 — переход на летнее время: смена 8–16 сдвигается на час раньше по UTC
+			name:   "переход на летнее время",
+			shifts: []*Shift{day},
+			from:   time.Date(2026, 3, 28, 0, 0, 0, 0, time.UTC), to: time.Date(2026, 3, 29, 23, 0, 0, 0, time.UTC),
+			loc: berlin,
+			want: []Interval{
+				iv(time.Date(2026, 3, 28, 7, 0, 0, 0, time.UTC), time.Date(2026, 3, 28, 15, 0, 0, 0, time.UTC)),
+				iv(time.Date(2026, 3, 29, 6, 0, 0, 0, time.UTC), time.Date(2026, 3, 29, 14, 0, 0, 0, time.UTC)),
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := clipless(expandShifts(tt.shifts, tt.from, tt.to, tt.loc), tt.from, tt.to)
+			if !equalIntervals(got, tt.want) {
+				t.Errorf("expandShifts = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+// clipless отбрасывает периоды, не пересекающиеся с [from, to), не обрезая остальные
+func clipless(ivs []Interval, from, to time.Time) []Interval {
+	var out []Interval
+	for _, iv := range ivs {
+		if iv.End.After(from) && iv.Start.Before(to) {
+			out = append(out, iv)
+		}
+	}
+	return out
+}
+
+type fakeRepo struct {
+	Repository
+	shifts     map[int64][]*Shift
+	exceptions []*Exception
+}
+
+func (r *fakeRepo) ListShifts(lineID int64) ([]*Shift, error) {
+	return append([]*Shift(nil), r.shifts[lineID]...), nil
+}
+
+func (r *fakeRepo) ExceptionsFor(int64, time.Time, time.Time) ([]*Exception, error) {
+	return r.exceptions, nil
+}
+
+func TestWorkingIntervals(t *testing.T) {
+	repo := &fakeRepo{
+		shifts: map[int64][]*Shift{
+			0: {{StartTime: "08:00", EndTime: "16:00", Weekdays: EveryDay, Active: true}},
+			1: {
+				{StartTime: "06:00", EndTime: "14:00", Weekdays: EveryDay, Active: true},
+				{StartTime: "14:00", EndTime: "22:00", Weekdays: EveryDay, Active: false},
+			},
+			2: {{StartTime: "06:00", EndTime: "14:00", Weekdays: EveryDay, Active: false}},
+		},
+		exceptions: []*Exception{
+			{Kind: KindShutdown, StartTime: utc(0, 10, 0), EndTime: utc(0, 11, 0)},
+			{Kind: KindWorking, StartTime: utc(0, 16, 0), EndTime: utc(0, 18, 0)},
+			{Kind: KindHoliday, StartTime: utc(1, 0, 0), EndTime: utc(2, 0, 0)},
+		},
+	}
+	s := NewService(repo, nil)
+	from, to := utc(0, 0, 0), utc(2, 0, 0)
+
+	tests := []struct {
+		name   string
+		lineID int64
+		want   []Interval
+	}{
+		{"смены завода", 0, []Interval{
+			iv(utc(0, 8, 0), utc(0, 10, 0)),
+			iv(utc(0, 11, 0), utc(0, 18, 0)),
+		}},
+		{"активные смены линии заменяют заводские", 1, []Interval{
+			iv(utc(0, 6, 0), utc(0, 10, 0)),
+			iv(utc(0, 11, 0), utc(0, 14, 0)),
+			iv(utc(0, 16, 0), utc(0, 18, 0)),
+		}},
+		{"линия без активных смен — заводские", 2, []Interval{
+			iv(utc(0, 8, 0), utc(0, 10, 0)),
+			iv(utc(0, 11, 0), utc(0, 18, 0)),
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := s.WorkingIntervals(tt.lineID, from, to)
+			if err != nil {
+				t.Fatalf("WorkingIntervals: %v", err)
+			}
+			if !equalIntervals(got, tt.want) {
+				t.Errorf("WorkingIntervals = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	// без смен завод работает круглосуточно, кроме исключений
+	s = NewService(&fakeRepo{exceptions: repo.exceptions[:1]}, nil)
+	got, err := s.WorkingIntervals(0, utc(0, 0, 0), utc(1, 0, 0))
+	if err != nil {
+		t.Fatalf("WorkingIntervals: %v", err)
+	}
+	want := []Interval{iv(utc(0, 0, 0), utc(0, 10, 0)), iv(utc(0, 11, 0), utc(1, 0, 0))}
+	if !equalIntervals(got, want) {
+		t.Errorf("WorkingIntervals without shifts = %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,208 @@
+package execution
+
+import (
+	"errors"
+	"slices"
+	"strings"
+
+	"mes-lite-back/internal/features/hold"
+
+	"gorm.io/gorm"
+)
+
+// Dispose принимает решение по последнему браковочному результату контроля экземпляра.
+// rework возвращает экземпляр на уже пройденный этап маршрута, не позже этапа контроля:
+// выполнения этого и последующих этапов заменяются, и экземпляр проходит их заново.
+// scrap списывает экземпляр в брак с причиной; списанный экземпляр не запускается на этапы и не отгружается.
+// Решение принимается один раз и только когда у экземпляра нет незавершенного этапа.
+func (s *Service) Dispose(req DispositionRequest, userID int64) (*Disposition, error) {
+	if userID <= 0 {
+		return nil, ErrNoUser
+	}
+
+	insp, err := s.repo.GetInspection(req.InspectionID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrInspectionNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	if insp.Status != inspectionFailed {
+		return nil, ErrNotFailed
+	}
+	if !insp.Latest {
+		return nil, ErrNotLatest
+	}
+
+	inst, err := s.repo.GetInstance(insp.ProductInstanceID)
+	if err != nil {
+		return nil, err
+	}
+	if inst.ScrappedAt != nil {
+		return nil, ErrScrapped
+	}
+
+	comment := strings.TrimSpace(req.Comment)
+
+	switch req.Action {
+	case ActionRework:
+		if req.StageID == nil {
+			return nil, ErrReworkStage
+		}
+		rw := &Rework{
+			ProductInstanceID: inst.ID,
+			InspectionID:      &insp.ID,
+			ToStageID:         *req.StageID,
+			Comment:           comment,
+			CreatedBy:         &userID,
+		}
+		// этап возврата не может быть позже этапа, на котором выявлен брак
+		if err := s.rework(inst, rw, insp.StageID); err != nil {
+			return nil, err
+		}
+		return &Disposition{Action: ActionRework, Rework: rw}, nil
+
+	case ActionScrap:
+		reason, err := s.scrapReason(req.ScrapReasonID)
+		if err != nil {
+			return nil, err
+		}
+		sc := &Scrap{
+			ProductInstanceID: inst.ID,
+			InspectionID:      &insp.ID,
+			ReasonID:          reason.ID,
+			Comment:           comment,
+			ScrappedBy:        userID,
+			ScrappedAt:        s.now(),
+		}
+		if err := s.repo.Scrap(sc); err != nil {
+			return nil, err
+		}
+		return &Disposition{Action: ActionScrap, Scrap: sc}, nil
+
+	default:
+		return nil, ErrInvalidAction
+	}
+}
+
+// HoldRework возвращает экземпляр на пройденный этап маршрута при снятии блокировки holdID с решением rework.
+// Доработка по той же блокировке назначается один раз: повторный вызов ничего не меняет.
+func (s *Service) HoldRework(instanceID, holdID, stageID int64, comment string, userID int64) error {
+	inst, err := s.repo.GetInstance(instanceID)
+	if err != nil {
+		return err
+	}
+
+	err = s.rework(inst, &Rework{
+		ProductInstanceID: inst.ID,
+		HoldID:            &holdID,
+		ToStageID:         stageID,
+		Comment:           comment,
+		CreatedBy:         &userID,
+	}, nil)
+	if errors.Is(err, ErrAlreadyDisposed) {
+		return nil
+	}
+	return holdError(err)
+}
+
+// HoldScrap списывает экземпляр в брак при снятии блокировки с решением scrap; уже списанный экземпляр не меняется
+func (s *Service) HoldScrap(instanceID int64, reasonID int, comment string, userID int64) error {
+	reason, err := s.scrapReason(&reasonID)
+	if err != nil {
+		return holdError(err)
+	}
+
+	err = s.repo.Scrap(&Scrap{
+		ProductInstanceID: instanceID,
+		ReasonID:          reason.ID,
+		Comment:           comment,
+		ScrappedBy:        userID,
+		ScrappedAt:        s.now(),
+	})
+	if errors.Is(err, ErrScrapped) {
+		return nil
+	}
+	return holdError(err)
+}
+
+// holdError переводит ошибки решения по экземпляру в ошибки модуля блокировок
+func holdError(err error) error {
+	switch {
+	case errors.Is(err, ErrStageNotInRoute), errors.Is(err, ErrReworkStage):
+		return hold.ErrReworkStage
+	case errors.Is(err, ErrScrapReasonRequired), errors.Is(err, ErrScrapReasonNotFound):
+		return hold.ErrScrapReasonNotFound
+	case errors.Is(err, ErrAlreadyOpen):
+		return hold.ErrInProgress
+	case errors.Is(err, ErrScrapped):
+		return hold.ErrScrapped
+	}
+	return err
+}
+
+// rework назначает доработку rw; этап возврата должен быть пройден и не позже этапа limit (nil — без ограничения).
+// Выполнения этапа возврата и последующих этапов маршрута заменяются доработкой.
+func (s *Service) rework(inst *InstanceRef, rw *Rework, limit *int64) error {
+	routing, err := s.repo.GetRouting(inst)
+	if err != nil {
+		return err
+	}
+	target := slices.IndexFunc(routing, func(st *StageRef) bool { return st.StageID == rw.ToStageID })
+	if target < 0 {
+		return ErrStageNotInRoute
+	}
+
+	if limit != nil {
+		last := slices.IndexFunc(routing, func(st *StageRef) bool { return st.StageID == *limit })
+		if last >= 0 && routing[target].StageOrder > routing[last].StageOrder {
+			return ErrReworkStage
+		}
+	}
+
+	return s.repo.Rework(rw, func(finished []int64) ([]int64, error) {
+		if !slices.Contains(finished, rw.ToStageID) {
+			return nil, ErrReworkStage
+		}
+
+		var superseded []int64
+		for _, st := range routing {
+			if st.StageOrder >= routing[target].StageOrder {
+				superseded = append(superseded, st.StageID)
+			}
+		}
+		return superseded, nil
+	})
+}
+
+// scrapReason действующая причина списания из справочника
+func (s *Service) scrapReason(id *int) (*ScrapReason, error) {
+	if id == nil {
+		return nil, ErrScrapReasonRequired
+	}
+
+	reason, err := s.repo.GetScrapReason(*id)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrScrapReasonNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	if !reason.Active {
+		return nil, ErrScrapReasonNotFound
+	}
+	return reason, nil
+}
+
+// Reworks доработки экземпляра в порядке назначения
+func (s *Service) Reworks(barcode string) ([]*Rework, error) {
+	inst, err := s.instance(barcode)
+	if err != nil {
+		return nil, err
+	}
+	return s.repo.ListReworks(inst.ID)
+}
+
+func (s *Service) ScrapReasons(activeOnly bool) ([]*ScrapReason, error) {
+	return s.repo.ScrapReasons(activeOnly)
+}
@@ -0,0 +1,274 @@
+package execution
+
+import (
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strconv"
+
+	"mes-lite-back/internal/features/skill"
+	"mes-lite-back/internal/http/middleware"
+	"mes-lite-back/pkg"
+
+	"github.com/go-chi/chi/v5"
+)
+
+type Handler struct {
+	service ServiceInterface
+	perms   middleware.PermissionChecker
+}
+
+func NewHandler(service ServiceInterface, perms middleware.PermissionChecker) *Handler {
+	return &Handler{
+		service: service,
+		perms:   perms,
+	}
+}
+
+func (h *Handler) Routes() chi.Router {
+	r := chi.NewRouter()
+
+	view := middleware.PermissionGuard(h.perms, "stage.view")
+	execute := middleware.PermissionGuard(h.perms, "stage.execute")
+	dispose := middleware.PermissionGuard(h.perms, "quality.edit")
+
+	r.With(view).Get("/", h.history)
+	r.With(execute).Post("/start", h.start)
+	r.With(execute).Post("/finish", h.finish)
+	r.With(view).Get("/reworks", h.reworks)
+	r.With(view).Get("/scrap-reasons", h.scrapReasons)
+	r.With(dispose).Post("/disposition", h.dispose)
+
+	return r
+}
+
+type ErrorResponse struct {
+	Error string `json:"error" example:"Описание ошибки"`
+}
+
+type SequenceErrorResponse struct {
+	Error           string  `json:"error" example:"Не завершены предыдущие этапы"`
+	MissingStageIDs []int64 `json:"missing_stage_ids" example:"1,2"`
+}
+
+// InspectionErrorResponse предшествующие этапы без годного результата обязательного контроля
+type InspectionErrorResponse struct {
+	Error           string  `json:"error" example:"Не пройден обязательный контроль предыдущих этапов"`
+	PendingStageIDs []int64 `json:"pending_stage_ids" example:"2"`
+}
+
+// StartExecution godoc
+// @Summary Начать этап
+// @Description Открывает выполнение этапа для экземпляра по штрихкоду; исполнитель берется из JWT.
+// @Description Оператору без действующей квалификации нужен допуск мастера (поле override).
+// @Description После завершенного этапа с обязательным планом контроля экземпляр идет дальше только с годным результатом контроля (иначе 423).
+// @Description Экземпляр в блокировке (карантине) или забракованный при ее снятии на этапы не запускается (423); списанный в брак — 409.
+// @Description Выполнение этапа не раньше этапа возврата последней доработки ссылается на нее (rework_id).
+// @Tags executions
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param request body ScanRequest true "Штрихкод и этап"
+// @Success 201 {object} Execution
+// @Failure 400 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 409 {object} SequenceErrorResponse
+// @Failure 423 {object} InspectionErrorResponse
+// @Router /executions/start [post]
+func (h *Handler) start(w http.ResponseWriter, r *http.Request) {
+	var req ScanRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		pkg.RespondJSON(w, http.StatusBadRequest, ErrorResponse{Error: "Некорректный JSON"})
+		return
+	}
+
+	e, err := h.service.Start(req, currentUser(r))
+	if err != nil {
+		h.respondError(w, err)
+		return
+	}
+	pkg.RespondJSON(w, http.StatusCreated, e)
+}
+
+// FinishExecution godoc
+// @Summary Завершить этап
+// @Description Закрывает открытое выполнение этапа для экземпляра по штрихкоду
+// @Tags executions
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param request body ScanRequest true "Штрихкод и этап"
+// @Success 200 {object} Execution
+// @Failure 400 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse
+// @Router /executions/finish [post]
+func (h *Handler) finish(w http.ResponseWriter, r *http.Request) {
+	var req ScanRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		pkg.RespondJSON(w, http.StatusBadRequest, ErrorResponse{Error: "Некорректный JSON"})
+		return
+	}
+
+	e, err := h.service.Finish(req, currentUser(r))
+	if err != nil {
+		h.respondError(w, err)
+		return
+	}
+	pkg.RespondJSON(w, http.StatusOK, e)
+}
+
+// ExecutionHistory godoc
+// @Summary История выполнения этапов экземпляра
+// @Tags executions
+// @Security BearerAuth
+// @Produce json
+// @Param barcode query string true "Штрихкод экземпляра"
+// @Success 200 {array} Execution
+// @Failure 404 {object} ErrorResponse
+// @Router /executions [get]
+func (h *Handler) history(w http.ResponseWriter, r *http.Request) {
+	executions, err := h.service.History(r.URL.Query().Get("barcode"))
+	if err != nil {
+		h.respondError(w, err)
+		return
+	}
+	pkg.RespondJSON(w, http.StatusOK, executions)
+}
+
+// DisposeInspection godoc
+// @Summary Решение по браку
+// @Description Решение по последнему браковочному результату контроля экземпляра: rework возвращает экземпляр на пройденный этап маршрута не позже этапа контроля (выполнения этого и последующих этапов заменяются, этапы проходятся заново), scrap списывает экземпляр в брак с причиной. Решение принимается один раз, при незавершенном этапе — 409
+// @Tags executions
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param request body DispositionRequest true "Решение"
+// @Success 201 {object} Disposition
+// @Failure 400 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse
+// @Router /executions/disposition [post]
+func (h *Handler) dispose(w http.ResponseWriter, r *http.Request) {
+	var req DispositionRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		pkg.RespondJSON(w, http.StatusBadRequest, ErrorResponse{Error: "Некорректный JSON"})
+		return
+	}
+
+	d, err := h.service.Dispose(req, currentUser(r))
+	if err != nil {
+		h.respondError(w, err)
+		return
+	}
+	pkg.RespondJSON(w, http.StatusCreated, d)
+}
+
+// ListReworks godoc
+// @Summary Доработки экземпляра
+// @Tags executions
+// @Security BearerAuth
+// @Produce json
+// @Param barcode query string true "Штрихкод экземпляра"
+// @Success 200 {array} Rework
+// @Failure 404 {object} ErrorResponse
+// @Router /executions/reworks [get]
+func (h *Handler) reworks(w http.ResponseWriter, r *http.Request) {
+	reworks, err := h.service.Reworks(r.URL.Query().Get("barcode"))
+	if err != nil {
+		h.respondError(w, err)
+		return
+	}
+	pkg.RespondJSON(w, http.StatusOK, reworks)
+}
+
+// ListScrapReasons godoc
+// @Summary Справочник причин брака
+// @Tags executions
+// @Security BearerAuth
+// @Produce json
+// @Param active query bool false "Только активные"
+// @Success 200 {array} ScrapReason
+// @Router /executions/scrap-reasons [get]
+func (h *Handler) scrapReasons(w http.ResponseWriter, r *http.Request) {
+	activeOnly, _ := strconv.ParseBool(r.URL.Query().Get("active"))
+
+	reasons, err := h.service.ScrapReasons(activeOnly)
+	if err != nil {
+		h.respondError(w, err)
+		return
+	}
+	pkg.RespondJSON(w, http.StatusOK, reasons)
+}
+
+func currentUser(r *http.Request) int64 {
+	id, _ := middleware.UserIDFromContext(r.Context())
+	return id
+}
+
+func (h *Handler) respondError(w http.ResponseWriter, err error) {
+	var (
+		seqErr        *SequenceError
+		inspectionErr *InspectionError
+	)
+
+	switch {
+	case errors.As(err, &seqErr):
+		pkg.RespondJSON(w, http.StatusConflict, SequenceErrorResponse{
+			Error:           "Не завершены предыдущие этапы маршрута",
+			MissingStageIDs: seqErr.Missing,
+		})
+	case errors.As(err, &inspectionErr):
+		pkg.RespondJSON(w, http.StatusLocked, InspectionErrorResponse{
+			Error:           "Не пройден обязательный контроль предыдущих этапов",
+			PendingStageIDs: inspectionErr.Pending,
+		})
+	case errors.Is(err, ErrOnHold):
+		pkg.RespondJSON(w, http.StatusLocked, ErrorResponse{Error: "Экземпляр заблокирован (карантин)"})
+	case errors.Is(err, skill.ErrNotSignedOff):
+		pkg.RespondJSON(w, http.StatusForbidden, ErrorResponse{Error: "Квалификация оператора не подтверждена наставником"})
+	case errors.Is(err, skill.ErrCertificationExpired):
+		pkg.RespondJSON(w, http.StatusForbidden, ErrorResponse{Error: "Срок аттестации оператора истек"})
+	case errors.Is(err, ErrNotQualified):
+		pkg.RespondJSON(w, http.StatusForbidden, ErrorResponse{Error: "Оператор не допущен к этапу"})
+	case errors.Is(err, ErrOverrideDenied):
+		pkg.RespondJSON(w, http.StatusForbidden, ErrorResponse{Error: "Допуск мастера отклонен"})
+	case errors.Is(err, ErrOverrideReason):
+		pkg.RespondJSON(w, http.StatusBadRequest, ErrorResponse{Error: "Укажите причину допуска"})
+	case errors.Is(err, ErrInstanceNotFound):
+		pkg.RespondJSON(w, http.StatusNotFound, ErrorResponse{Error: "Экземпляр с таким штрихкодом не найден"})
+	case errors.Is(err, ErrStageNotInRoute):
+		pkg.RespondJSON(w, http.StatusBadRequest, ErrorResponse{Error: "Этап не входит в маршрут продукта"})
+	case errors.Is(err, ErrNoUser):
+		pkg.RespondJSON(w, http.StatusUnauthorized, ErrorResponse{Error: "Не удалось определить исполнителя"})
+	case errors.Is(err, ErrAlreadyOpen):
+		pkg.RespondJSON(w, http.StatusConflict, ErrorResponse{Error: "У экземпляра уже есть незавершенный этап"})
+	case errors.Is(err, ErrAlreadyFinished):
+		pkg.RespondJSON(w, http.StatusConflict, ErrorResponse{Error: "Этап уже выполнен для этого экземпляра"})
+	case errors.Is(err, ErrNotStarted):
+		pkg.RespondJSON(w, http.StatusConflict, ErrorResponse{Error: "Этап не начат для этого экземпляра"})
+	case errors.Is(err, ErrScrapped):
+		pkg.RespondJSON(w, http.StatusConflict, ErrorResponse{Error: "Экземпляр списан в брак"})
+	case errors.Is(err, ErrInspectionNotFound):
+		pkg.RespondJSON(w, http.StatusNotFound, ErrorResponse{Error: "Результат контроля не найден"})
+	case errors.Is(err, ErrNotFailed):
+		pkg.RespondJSON(w, http.StatusBadRequest, ErrorResponse{Error: "Решение принимается только по браковочному результату"})
+	case errors.Is(err, ErrNotLatest):
+		pkg.RespondJSON(w, http.StatusConflict, ErrorResponse{Error: "После этого результата экземпляр уже проходил контроль"})
+	case errors.Is(err, ErrAlreadyDisposed):
+		pkg.RespondJSON(w, http.StatusConflict, ErrorResponse{Error: "Решение по результату уже принято"})
+	case errors.Is(err, ErrInvalidAction):
+		pkg.RespondJSON(w, http.StatusBadRequest, ErrorResponse{Error: "Решение должно быть rework или scrap"})
+	case errors.Is(err, ErrReworkStage):
+		pkg.RespondJSON(w, http.StatusBadRequest, ErrorResponse{Error: "Укажите пройденный этап маршрута не позже этапа контроля"})
+	case errors.Is(err, ErrScrapReasonRequired):
+		pkg.RespondJSON(w, http.StatusBadRequest, ErrorResponse{Error: "Укажите причину брака"})
+	case errors.Is(err, ErrScrapReasonNotFound):
+		pkg.RespondJSON(w, http.StatusNotFound, ErrorResponse{Error: "Причина брака не найдена"})
+	default:
+		slog.Error("stage execution failed", slog.Any("err", err))
+		pkg.RespondJSON(w, http.StatusInternalServerError, ErrorResponse{Error: "Внутренняя ошибка сервера"})
+	}
+}
@@ -0,0 +1,138 @@
+package execution
+
+import "time"
+
+// Execution выполнение этапа над экземпляром продукции.
+// ReworkID — повторное выполнение по доработке; SupersededBy — выполнение отменено доработкой и этап нужно пройти заново.
+type Execution struct {
+	ID                int64      `gorm:"primaryKey;autoIncrement" json:"id"`
+	ProductInstanceID int64      `json:"product_instance_id"`
+	StageID           int64      `json:"stage_id"`
+	UserID            int64      `json:"user_id"`
+	StartTime         time.Time  `json:"start_time"`
+	EndTime           *time.Time `json:"end_time,omitempty"`
+	ReworkID          *int64     `json:"rework_id,omitempty"`
+	SupersededBy      *int64     `json:"superseded_by,omitempty"`
+	Override          *Override  `gorm:"foreignKey:ExecutionID" json:"override,omitempty"`
+}
+
+func (Execution) TableName() string {
+	return "stage_execution"
+}
+
+// Override допуск мастера к этапу оператора без действующей квалификации
+type Override struct {
+	ID           int64     `gorm:"primaryKey;autoIncrement" json:"id"`
+	ExecutionID  int64     `json:"execution_id"`
+	SupervisorID int64     `json:"supervisor_id"`
+	Reason       string    `json:"reason"`
+	CreatedAt    time.Time `gorm:"autoCreateTime" json:"created_at"`
+}
+
+func (Override) TableName() string {
+	return "stage_execution_overrides"
+}
+
+// InstanceRef экземпляр, найденный по штрихкоду; ScrappedAt — списан в брак
+type InstanceRef struct {
+	ID          int64
+	ProductID   int64
+	WorkOrderID *int64
+	Barcode     string
+	ScrappedAt  *time.Time
+}
+
+// StageRef этап маршрута экземпляра
+type StageRef struct {
+	StageID          int64
+	StageOrder       int
+	IsStrictSequence bool
+}
+
+// ScanRequest данные сканирования: штрихкод экземпляра и этап
+type ScanRequest struct {
+	Barcode  string           `json:"barcode" example:"MES-GB01-260105-000042-2"`
+	StageID  int64            `json:"stage_id" example:"1"`
+	Override *OverrideRequest `json:"override,omitempty"`
+}
+
+// OverrideRequest учетные данные мастера, допускающего оператора без квалификации
+type OverrideRequest struct {
+	Username string `json:"username" example:"master"`
+	Password string `json:"password" example:"secret"`
+	Reason   string `json:"reason" example:"Плановая подмена, оператор на обучении"`
+}
+
+// Решения по браку
+const (
+	ActionRework = "rework"
+	ActionScrap  = "scrap"
+)
+
+// ScrapReason причина списания в брак из справочника
+type ScrapReason struct {
+	ID     int    `gorm:"primaryKey" json:"id"`
+	Code   string `json:"code" example:"MATERIAL"`
+	Name   string `json:"name" example:"Дефект материала"`
+	Active bool   `json:"active" example:"true"`
+}
+
+func (ScrapReason) TableName() string {
+	return "scrap_reasons"
+}
+
+// Rework возврат экземпляра на этап маршрута по браковочному результату контроля (InspectionID)
+// или при снятии блокировки с решением rework (HoldID)
+type Rework struct {
+	ID                int64     `gorm:"primaryKey;autoIncrement" json:"id"`
+	ProductInstanceID int64     `json:"product_instance_id"`
+	InspectionID      *int64    `json:"inspection_id,omitempty"`
+	HoldID            *int64    `json:"hold_id,omitempty"`
+	ToStageID         int64     `json:"to_stage_id"`
+	Comment           string    `json:"comment,omitempty"`
+	CreatedBy         *int64    `json:"created_by,omitempty"`
+	CreatedAt         time.Time `gorm:"autoCreateTime" json:"created_at"`
+}
+
+func (Rework) TableName() string {
+	return "instance_reworks"
+}
+
+// Scrap списание экземпляра в брак по браковочному результату контроля или при снятии блокировки
+type Scrap struct {
+	ProductInstanceID int64     `json:"product_instance_id"`
+	InspectionID      *int64    `json:"inspection_id,omitempty"`
+	ReasonID          int       `json:"reason_id"`
+	Comment           string    `json:"comment,omitempty"`
+	ScrappedBy        int64     `json:"scrapped_by"`
+	ScrappedAt        time.Time `json:"scrapped_at"`
+}
+
+// inspectionFailed код браковочного результата контроля (справочник quality_statuses)
+const inspectionFailed = "failed"
+
+// InspectionRef результат контроля, по которому принимается решение; Latest — последний результат экземпляра
+type InspectionRef struct {
+	ID                int64
+	ProductInstanceID int64
+	StageID           *int64
+	Status            string
+	Latest            bool
+}
+
+// DispositionRequest решение по браковочному результату контроля: rework с этапом возврата
+// или scrap с причиной списания
+type DispositionRequest struct {
+	InspectionID  int64  `json:"inspection_id" example:"12"`
+	Action        string `json:"action" example:"rework"`
+	StageID       *int64 `json:"stage_id,omitempty" example:"2"`
+	ScrapReasonID *int   `json:"scrap_reason_id,omitempty" example:"2"`
+	Comment       string `json:"comment,omitempty" example:"Переварить шов"`
+}
+
+// Disposition принятое решение: Rework или Scrap
+type Disposition struct {
+	Action string  `json:"action" example:"rework"`
+	Rework *Rework `json:"rework,omitempty"`
+	Scrap  *Scrap  `json:"scrap,omitempty"`
+}
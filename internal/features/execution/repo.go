@@ -0,0 +1,31 @@
+package execution
+
+type Repository interface {
+	GetInstanceByBarcode(barcode string) (*InstanceRef, error)
+	GetInstance(id int64) (*InstanceRef, error)
+	// GetRouting возвращает этапы маршрута экземпляра: версии, закрепленной за заказом,
+	// или действующей версии продукта, если экземпляр выпущен без заказа
+	GetRouting(inst *InstanceRef) ([]*StageRef, error)
+
+	// Start создает выполнение, если у экземпляра нет другого незавершенного, он не списан и не в блокировке;
+	// check вызывается под блокировкой экземпляра с завершенными и не замененными доработкой этапами
+	// и последней доработкой экземпляра (nil — не было)
+	Start(e *Execution, check func(finished []int64, rework *Rework) error) error
+	// Finish закрывает выполнение, если оно еще открыто; gorm.ErrRecordNotFound, если его уже закрыли
+	Finish(e *Execution) error
+
+	GetOpen(instanceID int64) (*Execution, error)
+	ListByInstance(instanceID int64) ([]*Execution, error)
+
+	// GetInspection результат контроля с кодом статуса
+	GetInspection(id int64) (*InspectionRef, error)
+	ScrapReasons(activeOnly bool) ([]*ScrapReason, error)
+	GetScrapReason(id int) (*ScrapReason, error)
+
+	// Rework сохраняет доработку под блокировкой экземпляра; check получает завершенные этапы
+	// и возвращает этапы, выполнения которых заменяются доработкой
+	Rework(rw *Rework, check func(finished []int64) ([]int64, error)) error
+	// Scrap списывает экземпляр в брак
+	Scrap(sc *Scrap) error
+	ListReworks(instanceID int64) ([]*Rework, error)
+}
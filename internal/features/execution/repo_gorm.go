@@ -0,0 +1,300 @@
+package execution
+
+import (
+	"errors"
+
+	"mes-lite-back/internal/features/hold"
+
+	"github.com/jackc/pgx/v5/pgconn"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const pgUniqueViolation = "23505"
+
+type GormRepository struct {
+	db *gorm.DB
+}
+
+func NewGormRepository(db *gorm.DB) *GormRepository {
+	return &GormRepository{db: db}
+}
+
+func (r *GormRepository) GetInstanceByBarcode(barcode string) (*InstanceRef, error) {
+	var inst InstanceRef
+	err := r.db.Table("product_instances").
+		Select("id, product_id, work_order_id, barcode, scrapped_at").
+		Where("barcode = ?", barcode).
+		Take(&inst).
+		Error
+	if err != nil {
+		return nil, err
+	}
+	return &inst, nil
+}
+
+func (r *GormRepository) GetInstance(id int64) (*InstanceRef, error) {
+	var inst InstanceRef
+	err := r.db.Table("product_instances").
+		Select("id, product_id, work_order_id, barcode, scrapped_at").
+		Where("id = ?", id).
+		Take(&inst).
+		Error
+	if err != nil {
+		return nil, err
+	}
+	return &inst, nil
+}
+
+func (r *GormRepository) GetRouting(inst *InstanceRef) ([]*StageRef, error) {
+	var steps []*StageRef
+
+	q := r.db.Table("product_stages ps").
+		Select("ps.stage_id, ps.stage_order, s.is_strict_sequence").
+		Joins("JOIN stages s ON s.id = ps.stage_id").
+		Order("ps.stage_order")
+
+	pinned, err := r.pinnedVersion(inst)
+	if err != nil {
+		return nil, err
+	}
+
+	if pinned != nil {
+		q = q.Where("ps.routing_version_id = ?", *pinned)
+	} else {
+		q = q.Where("ps.routing_version_id = (?)", r.db.Table("routing_versions").
+			Select("id").
+			Where("product_id = ? AND status = 'released'", inst.ProductID))
+	}
+
+	return steps, q.Scan(&steps).Error
+}
+
+// pinnedVersion версия маршрута, закрепленная за заказом экземпляра
+func (r *GormRepository) pinnedVersion(inst *InstanceRef) (*int64, error) {
+	if inst.WorkOrderID == nil {
+		return nil, nil
+	}
+
+	var wo struct{ RoutingVersionID *int64 }
+	err := r.db.Table("work_orders").
+		Select("routing_version_id").
+		Where("id = ?", *inst.WorkOrderID).
+		Take(&wo).
+		Error
+	return wo.RoutingVersionID, err
+}
+
+func (r *GormRepository) Start(e *Execution, check func(finished []int64, rework *Rework) error) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := lockInstance(tx, e.ProductInstanceID); err != nil {
+			return err
+		}
+
+		blocked, err := hold.QueryBlocked(tx, []int64{e.ProductInstanceID})
+		if err != nil {
+			return err
+		}
+		if len(blocked) > 0 {
+			return ErrOnHold
+		}
+
+		finished, err := finishedStages(tx, e.ProductInstanceID)
+		if err != nil {
+			return err
+		}
+
+		var rework *Rework
+		var last Rework
+		err = tx.Where("product_instance_id = ?", e.ProductInstanceID).Order("id DESC").First(&last).Error
+		if err == nil {
+			rework = &last
+		} else if !errors.Is(err, gorm.ErrRecordNotFound) {
+			return err
+		}
+
+		if err := check(finished, rework); err != nil {
+			return err
+		}
+
+		return tx.Create(e).Error
+	})
+}
+
+// lockInstance блокирует экземпляр, сериализуя параллельные сканирования и решения по браку;
+// списанный экземпляр и экземпляр с незавершенным этапом не изменяются
+func lockInstance(tx *gorm.DB, instanceID int64) error {
+	var locked InstanceRef
+	if err := tx.Table("product_instances").
+		Clauses(clause.Locking{Strength: "UPDATE"}).
+		Select("id, scrapped_at").
+		Where("id = ?", instanceID).
+		Take(&locked).Error; err != nil {
+		return err
+	}
+	if locked.ScrappedAt != nil {
+		return ErrScrapped
+	}
+
+	var open Execution
+	err := tx.Where("product_instance_id = ? AND end_time IS NULL", instanceID).
+		First(&open).Error
+	if err == nil {
+		return ErrAlreadyOpen
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return err
+	}
+	return nil
+}
+
+// finishedStages этапы, завершенные экземпляром и не замененные доработкой
+func finishedStages(tx *gorm.DB, instanceID int64) ([]int64, error) {
+	var finished []int64
+	err := tx.Model(&Execution{}).
+		Distinct("stage_id").
+		Where("product_instance_id = ? AND end_time IS NOT NULL AND superseded_by IS NULL", instanceID).
+		Pluck("stage_id", &finished).
+		Error
+	return finished, err
+}
+
+func (r *GormRepository) Finish(e *Execution) error {
+	res := r.db.Model(e).Where("end_time IS NULL").Update("end_time", e.EndTime)
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+func (r *GormRepository) GetOpen(instanceID int64) (*Execution, error) {
+	var e Execution
+	err := r.db.
+		Where("product_instance_id = ? AND end_time IS NULL", instanceID).
+		First(&e).
+		Error
+	if err != nil {
+		return nil, err
+	}
+	return &e, nil
+}
+
+func (r *GormRepository) ListByInstance(instanceID int64) ([]*Execution, error) {
+	var executions []*Execution
+	err := r.db.
+		Preload("Override").
+		Where("product_instance_id = ?", instanceID).
+		Order("start_time").
+		Find(&executions).
+		Error
+	return executions, err
+}
+
+func (r *GormRepository) GetInspection(id int64) (*InspectionRef, error) {
+	var ref InspectionRef
+	err := r.db.Table("product_quality pq").
+		Select(`pq.id, pq.product_instance_id, pq.stage_id, qs.code AS status,
+			NOT EXISTS (
+				SELECT 1 FROM product_quality later
+				WHERE later.product_instance_id = pq.product_instance_id
+				  AND (later.inspected_at, later.id) > (pq.inspected_at, pq.id)
+			) AS latest`).
+		Joins("JOIN quality_statuses qs ON qs.id = pq.quality_status_id").
+		Where("pq.id = ?", id).
+		Take(&ref).
+		Error
+	if err != nil {
+		return nil, err
+	}
+	return &ref, nil
+}
+
+func (r *GormRepository) ScrapReasons(activeOnly bool) ([]*ScrapReason, error) {
+	var reasons []*ScrapReason
+	q := r.db.Order("id")
+	if activeOnly {
+		q = q.Where("active")
+	}
+	return reasons, q.Find(&reasons).Error
+}
+
+func (r *GormRepository) GetScrapReason(id int) (*ScrapReason, error) {
+	var reason ScrapReason
+	if err := r.db.First(&reason, id).Error; err != nil {
+		return nil, err
+	}
+	return &reason, nil
+}
+
+func (r *GormRepository) Rework(rw *Rework, check func(finished []int64) ([]int64, error)) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := lockInstance(tx, rw.ProductInstanceID); err != nil {
+			return err
+		}
+
+		finished, err := finishedStages(tx, rw.ProductInstanceID)
+		if err != nil {
+			return err
+		}
+		superseded, err := check(finished)
+		if err != nil {
+			return err
+		}
+
+		err = tx.Create(rw).Error
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == pgUniqueViolation {
+			return ErrAlreadyDisposed
+		}
+		if err != nil {
+			return err
+		}
+
+		return tx.Model(&Execution{}).
+			Where("product_instance_id = ? AND stage_id IN ? AND superseded_by IS NULL", rw.ProductInstanceID, superseded).
+			Update("superseded_by", rw.ID).
+			Error
+	})
+}
+
+func (r *GormRepository) Scrap(sc *Scrap) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := lockInstance(tx, sc.ProductInstanceID); err != nil {
+			return err
+		}
+
+		if sc.InspectionID != nil {
+			var reworked int64
+			if err := tx.Model(&Rework{}).Where("inspection_id = ?", *sc.InspectionID).Count(&reworked).Error; err != nil {
+				return err
+			}
+			if reworked > 0 {
+				return ErrAlreadyDisposed
+			}
+		}
+
+		return tx.Table("product_instances").
+			Where("id = ?", sc.ProductInstanceID).
+			Updates(map[string]any{
+				"scrapped_at":         sc.ScrappedAt,
+				"scrapped_by":         sc.ScrappedBy,
+				"scrap_reason_id":     sc.ReasonID,
+				"scrap_inspection_id": sc.InspectionID,
+				"scrap_comment":       sc.Comment,
+			}).
+			Error
+	})
+}
+
+func (r *GormRepository) ListReworks(instanceID int64) ([]*Rework, error) {
+	var reworks []*Rework
+	err := r.db.
+		Where("product_instance_id = ?", instanceID).
+		Order("id").
+		Find(&reworks).
+		Error
+	return reworks, err
+}
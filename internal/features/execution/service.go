@@ -0,0 +1,308 @@
+package execution
+
+import (
+	"errors"
+	"slices"
+	"strings"
+	"time"
+
+	"mes-lite-back/internal/features/skill"
+	"mes-lite-back/internal/features/user"
+	"mes-lite-back/internal/http/middleware"
+
+	"gorm.io/gorm"
+)
+
+var (
+	ErrInstanceNotFound = errors.New("product instance not found")
+	ErrStageNotInRoute  = errors.New("stage is not part of the instance routing")
+	ErrAlreadyOpen      = errors.New("instance already has an open stage execution")
+	ErrAlreadyFinished  = errors.New("stage already finished for this instance")
+	ErrSequence         = errors.New("previous stages are not finished")
+	ErrNotStarted       = errors.New("stage is not started for this instance")
+	ErrNoUser           = errors.New("executing user is unknown")
+	ErrNotQualified     = errors.New("operator is not qualified for this stage")
+	ErrOverrideDenied   = errors.New("supervisor override is not permitted")
+	ErrOverrideReason   = errors.New("override reason is required")
+	ErrInspection       = errors.New("mandatory inspection of a previous stage has not passed")
+	ErrOnHold           = errors.New("instance is on hold")
+	ErrScrapped         = errors.New("instance is scrapped")
+
+	ErrInspectionNotFound  = errors.New("inspection not found")
+	ErrNotFailed           = errors.New("only a failed inspection result can be dispositioned")
+	ErrNotLatest           = errors.New("inspection is not the latest result of the instance")
+	ErrAlreadyDisposed     = errors.New("inspection result is already dispositioned")
+	ErrInvalidAction       = errors.New("disposition action must be rework or scrap")
+	ErrReworkStage         = errors.New("rework needs a finished stage of the routing")
+	ErrScrapReasonRequired = errors.New("scrap reason is required")
+	ErrScrapReasonNotFound = errors.New("scrap reason not found")
+)
+
+// QualificationError оператор не допущен к этапу; Reason — причина из матрицы квалификаций
+type QualificationError struct {
+	Reason error
+}
+
+func (e *QualificationError) Error() string {
+	return ErrNotQualified.Error() + ": " + e.Reason.Error()
+}
+
+func (e *QualificationError) Unwrap() []error {
+	return []error{ErrNotQualified, e.Reason}
+}
+
+// QualificationChecker проверка допуска оператора по матрице квалификаций
+type QualificationChecker interface {
+	CheckQualification(userID, stageID int64, at time.Time) error
+}
+
+// CredentialVerifier проверка логина и пароля мастера при допуске
+type CredentialVerifier interface {
+	VerifyCredentials(username, password string) (*user.User, error)
+}
+
+// SequenceError перечисляет незавершенные предшествующие этапы
+type SequenceError struct {
+	Missing []int64
+}
+
+func (e *SequenceError) Error() string {
+	return ErrSequence.Error()
+}
+
+func (e *SequenceError) Unwrap() error {
+	return ErrSequence
+}
+
+// InspectionError перечисляет предшествующие этапы, обязательный контроль которых не пройден
+type InspectionError struct {
+	Pending []int64
+}
+
+func (e *InspectionError) Error() string {
+	return ErrInspection.Error()
+}
+
+func (e *InspectionError) Unwrap() error {
+	return ErrInspection
+}
+
+// InspectionGate обязательный контроль качества по этапам маршрута: возвращает этапы из stageIDs,
+// по которым у экземпляра нет годного результата контроля
+type InspectionGate interface {
+	PendingInspections(instanceID, productID int64, stageIDs []int64) ([]int64, error)
+}
+
+// ServiceInterface определяет методы, используемые handler’ом
+type ServiceInterface interface {
+	Start(req ScanRequest, userID int64) (*Execution, error)
+	Finish(req ScanRequest, userID int64) (*Execution, error)
+	History(barcode string) ([]*Execution, error)
+
+	Dispose(req DispositionRequest, userID int64) (*Disposition, error)
+	Reworks(barcode string) ([]*Rework, error)
+	ScrapReasons(activeOnly bool) ([]*ScrapReason, error)
+}
+
+type Service struct {
+	repo        Repository
+	skills      QualificationChecker
+	creds       CredentialVerifier
+	perms       middleware.PermissionChecker
+	inspections InspectionGate
+	now         func() time.Time
+}
+
+func NewService(
+	repo Repository,
+	skills QualificationChecker,
+	creds CredentialVerifier,
+	perms middleware.PermissionChecker,
+	inspections InspectionGate,
+) *Service {
+	return &Service{
+		repo:        repo,
+		skills:      skills,
+		creds:       creds,
+		perms:       perms,
+		inspections: inspections,
+		now:         time.Now,
+	}
+}
+
+// Start открывает выполнение этапа по скану штрихкода. Этап запускается, если экземпляр не списан
+// и не в блокировке, этап еще не завершен, а для этапа со строгой последовательностью завершены
+// все предыдущие этапы маршрута с годным результатом обязательного контроля. Оператор без
+// действующей квалификации допускается под учетными данными мастера. Выполнение на этапе
+// возврата последней доработки или после него относится к этой доработке.
+func (s *Service) Start(req ScanRequest, userID int64) (*Execution, error) {
+	if userID <= 0 {
+		return nil, ErrNoUser
+	}
+
+	inst, err := s.instance(req.Barcode)
+	if err != nil {
+		return nil, err
+	}
+	if inst.ScrappedAt != nil {
+		return nil, ErrScrapped
+	}
+
+	routing, err := s.repo.GetRouting(inst)
+	if err != nil {
+		return nil, err
+	}
+
+	idx := slices.IndexFunc(routing, func(st *StageRef) bool { return st.StageID == req.StageID })
+	if idx < 0 {
+		return nil, ErrStageNotInRoute
+	}
+	step := routing[idx]
+
+	e := &Execution{
+		ProductInstanceID: inst.ID,
+		StageID:           req.StageID,
+		UserID:            userID,
+		StartTime:         s.now(),
+	}
+
+	override, err := s.qualify(userID, req, e.StartTime)
+	if err != nil {
+		return nil, err
+	}
+	e.Override = override
+
+	err = s.repo.Start(e, func(finished []int64, rework *Rework) error {
+		if slices.Contains(finished, step.StageID) {
+			return ErrAlreadyFinished
+		}
+		if rework != nil {
+			target := slices.IndexFunc(routing, func(st *StageRef) bool { return st.StageID == rework.ToStageID })
+			if target >= 0 && routing[target].StageOrder <= step.StageOrder {
+				e.ReworkID = &rework.ID
+			}
+		}
+
+		var missing, done []int64
+		for _, prev := range routing[:idx] {
+			if slices.Contains(finished, prev.StageID) {
+				done = append(done, prev.StageID)
+			} else {
+				missing = append(missing, prev.StageID)
+			}
+		}
+		if step.IsStrictSequence && len(missing) > 0 {
+			return &SequenceError{Missing: missing}
+		}
+
+		if s.inspections == nil {
+			return nil
+		}
+		pending, err := s.inspections.PendingInspections(inst.ID, inst.ProductID, done)
+		if err != nil {
+			return err
+		}
+		if len(pending) > 0 {
+			return &InspectionError{Pending: pending}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return e, nil
+}
+
+// Finish закрывает открытое выполнение этапа экземпляра
+func (s *Service) Finish(req ScanRequest, userID int64) (*Execution, error) {
+	if userID <= 0 {
+		return nil, ErrNoUser
+	}
+
+	inst, err := s.instance(req.Barcode)
+	if err != nil {
+		return nil, err
+	}
+
+	e, err := s.repo.GetOpen(inst.ID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrNotStarted
+	}
+	if err != nil {
+		return nil, err
+	}
+	if e.StageID != req.StageID {
+		return nil, ErrNotStarted
+	}
+
+	end := s.now()
+	e.EndTime = &end
+
+	err = s.repo.Finish(e)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrNotStarted
+	}
+	if err != nil {
+		return nil, err
+	}
+	return e, nil
+}
+
+// qualify проверяет квалификацию оператора; при ее отсутствии требуется допуск мастера с правом stage.override
+func (s *Service) qualify(userID int64, req ScanRequest, at time.Time) (*Override, error) {
+	err := s.skills.CheckQualification(userID, req.StageID, at)
+	if err == nil {
+		return nil, nil
+	}
+	if !errors.Is(err, skill.ErrNotQualified) &&
+		!errors.Is(err, skill.ErrNotSignedOff) &&
+		!errors.Is(err, skill.ErrCertificationExpired) {
+		return nil, err
+	}
+	if req.Override == nil {
+		return nil, &QualificationError{Reason: err}
+	}
+
+	reason := strings.TrimSpace(req.Override.Reason)
+	if reason == "" {
+		return nil, ErrOverrideReason
+	}
+
+	supervisor, err := s.creds.VerifyCredentials(req.Override.Username, req.Override.Password)
+	if err != nil {
+		return nil, ErrOverrideDenied
+	}
+	if supervisor.ID == userID {
+		return nil, ErrOverrideDenied
+	}
+
+	ok, err := s.perms.HasPermission(supervisor.ID, "stage.override")
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, ErrOverrideDenied
+	}
+
+	return &Override{
+		SupervisorID: supervisor.ID,
+		Reason:       reason,
+	}, nil
+}
+
+func (s *Service) History(barcode string) ([]*Execution, error) {
+	inst, err := s.instance(barcode)
+	if err != nil {
+		return nil, err
+	}
+	return s.repo.ListByInstance(inst.ID)
+}
+
+func (s *Service) instance(barcode string) (*InstanceRef, error) {
+	inst, err := s.repo.GetInstanceByBarcode(strings.TrimSpace(barcode))
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrInstanceNotFound
+	}
+	return inst, err
+}
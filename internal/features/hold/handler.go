@@ -0,0 +1,223 @@
+package hold
+
+import (
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strconv"
+
+	"mes-lite-back/internal/http/middleware"
+	"mes-lite-back/pkg"
+
+	"github.com/go-chi/chi/v5"
+)
+
+type Handler struct {
+	service ServiceInterface
+	perms   middleware.PermissionChecker
+}
+
+func NewHandler(service ServiceInterface, perms middleware.PermissionChecker) *Handler {
+	return &Handler{
+		service: service,
+		perms:   perms,
+	}
+}
+
+func (h *Handler) Routes() chi.Router {
+	r := chi.NewRouter()
+
+	view := middleware.PermissionGuard(h.perms, "quality.view")
+	place := middleware.PermissionGuard(h.perms, "quality.inspect")
+	release := middleware.PermissionGuard(h.perms, "quality.edit")
+
+	r.With(view).Get("/", h.list)
+	r.With(view).Get("/{id}", h.getByID)
+	r.With(view).Get("/{id}/events", h.events)
+	r.With(place).Post("/", h.place)
+	r.With(release).Post("/{id}/release", h.release)
+
+	return r
+}
+
+type ErrorResponse struct {
+	Error string `json:"error" example:"Описание ошибки"`
+}
+
+// ListHolds godoc
+// @Summary Получить блокировки
+// @Description Новые первыми; фильтры по статусу, штрихкоду и заказу экземпляра, машине
+// @Tags holds
+// @Security BearerAuth
+// @Produce json
+// @Param status query string false "Статус: active, released"
+// @Param barcode query string false "Штрихкод экземпляра"
+// @Param work_order_id query int false "ID заказа экземпляра"
+// @Param machine_id query int false "ID машины блокировки"
+// @Param limit query int false "Размер страницы (не более 200)"
+// @Param offset query int false "Смещение"
+// @Success 200 {array} Hold
+// @Failure 400 {object} ErrorResponse
+// @Router /holds [get]
+func (h *Handler) list(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	workOrderID, _ := strconv.ParseInt(query.Get("work_order_id"), 10, 64)
+	machineID, _ := strconv.ParseInt(query.Get("machine_id"), 10, 64)
+	limit, _ := strconv.Atoi(query.Get("limit"))
+	offset, _ := strconv.Atoi(query.Get("offset"))
+
+	holds, err := h.service.List(ListFilter{
+		Status:      query.Get("status"),
+		Barcode:     query.Get("barcode"),
+		WorkOrderID: workOrderID,
+		MachineID:   machineID,
+		Limit:       limit,
+		Offset:      offset,
+	})
+	if err != nil {
+		h.respondError(w, err)
+		return
+	}
+	pkg.RespondJSON(w, http.StatusOK, holds)
+}
+
+// GetHold godoc
+// @Summary Получить блокировку по ID
+// @Description Блокировка с экземплярами и решениями по снятым
+// @Tags holds
+// @Security BearerAuth
+// @Produce json
+// @Param id path int true "ID блокировки"
+// @Success 200 {object} Hold
+// @Failure 404 {object} ErrorResponse
+// @Router /holds/{id} [get]
+func (h *Handler) getByID(w http.ResponseWriter, r *http.Request) {
+	hold, err := h.service.Get(pkg.ParamID(r))
+	if err != nil {
+		h.respondError(w, err)
+		return
+	}
+	pkg.RespondJSON(w, http.StatusOK, hold)
+}
+
+// HoldEvents godoc
+// @Summary Журнал блокировки
+// @Tags holds
+// @Security BearerAuth
+// @Produce json
+// @Param id path int true "ID блокировки"
+// @Success 200 {array} Event
+// @Failure 404 {object} ErrorResponse
+// @Router /holds/{id}/events [get]
+func (h *Handler) events(w http.ResponseWriter, r *http.Request) {
+	events, err := h.service.Events(pkg.ParamID(r))
+	if err != nil {
+		h.respondError(w, err)
+		return
+	}
+	pkg.RespondJSON(w, http.StatusOK, events)
+}
+
+// PlaceHold godoc
+// @Summary Поставить блокировку
+// @Description Укажите ровно одно: barcodes, work_order_id или machine_id с окном from–to. По машине блокируются экземпляры ее заказов, этапы которых выполнялись в окне. Состав фиксируется при постановке; заблокированные экземпляры не запускаются на этапы и не отгружаются
+// @Tags holds
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param request body Placement true "Блокировка"
+// @Success 201 {object} Hold
+// @Failure 400 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Router /holds [post]
+func (h *Handler) place(w http.ResponseWriter, r *http.Request) {
+	var req Placement
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		pkg.RespondJSON(w, http.StatusBadRequest, ErrorResponse{Error: "Некорректный JSON"})
+		return
+	}
+
+	hold, err := h.service.Place(req, currentUser(r))
+	if err != nil {
+		h.respondError(w, err)
+		return
+	}
+	pkg.RespondJSON(w, http.StatusCreated, hold)
+}
+
+// ReleaseHold godoc
+// @Summary Снять блокировку
+// @Description Снимает указанные экземпляры (без barcodes — все оставшиеся) с решением use_as_is, rework с этапом возврата stage_id или scrap с причиной scrap_reason_id. Доработка и списание записываются как решения по браку и учитываются в выходе годных. Блокировка закрывается, когда сняты все экземпляры
+// @Tags holds
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param id path int true "ID блокировки"
+// @Param request body Release true "Решение"
+// @Success 200 {object} Hold
+// @Failure 400 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse
+// @Router /holds/{id}/release [post]
+func (h *Handler) release(w http.ResponseWriter, r *http.Request) {
+	var req Release
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		pkg.RespondJSON(w, http.StatusBadRequest, ErrorResponse{Error: "Некорректный JSON"})
+		return
+	}
+
+	hold, err := h.service.Release(pkg.ParamID(r), req, currentUser(r))
+	if err != nil {
+		h.respondError(w, err)
+		return
+	}
+	pkg.RespondJSON(w, http.StatusOK, hold)
+}
+
+func currentUser(r *http.Request) int64 {
+	id, _ := middleware.UserIDFromContext(r.Context())
+	return id
+}
+
+func (h *Handler) respondError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, ErrNotFound):
+		pkg.RespondJSON(w, http.StatusNotFound, ErrorResponse{Error: "Блокировка не найдена"})
+	case errors.Is(err, ErrInstanceNotFound):
+		pkg.RespondJSON(w, http.StatusNotFound, ErrorResponse{Error: "Экземпляр со штрихкодом не найден"})
+	case errors.Is(err, ErrWorkOrderNotFound):
+		pkg.RespondJSON(w, http.StatusNotFound, ErrorResponse{Error: "Заказ не найден"})
+	case errors.Is(err, ErrMachineNotFound):
+		pkg.RespondJSON(w, http.StatusNotFound, ErrorResponse{Error: "Машина не найдена"})
+	case errors.Is(err, ErrReasonRequired):
+		pkg.RespondJSON(w, http.StatusBadRequest, ErrorResponse{Error: "Укажите причину блокировки"})
+	case errors.Is(err, ErrInvalidScope):
+		pkg.RespondJSON(w, http.StatusBadRequest, ErrorResponse{Error: "Укажите ровно одно: штрихкоды, заказ или машину с окном"})
+	case errors.Is(err, ErrInvalidWindow):
+		pkg.RespondJSON(w, http.StatusBadRequest, ErrorResponse{Error: "Укажите окно from–to, конец позже начала"})
+	case errors.Is(err, ErrWindowTooLong):
+		pkg.RespondJSON(w, http.StatusBadRequest, ErrorResponse{Error: "Окно не должно превышать 400 дней"})
+	case errors.Is(err, ErrNoInstances):
+		pkg.RespondJSON(w, http.StatusBadRequest, ErrorResponse{Error: "Под блокировку не попал ни один экземпляр"})
+	case errors.Is(err, ErrInvalidDisposition):
+		pkg.RespondJSON(w, http.StatusBadRequest, ErrorResponse{Error: "Решение должно быть use_as_is, rework или scrap"})
+	case errors.Is(err, ErrInvalidStatus):
+		pkg.RespondJSON(w, http.StatusBadRequest, ErrorResponse{Error: "Статус должен быть active или released"})
+	case errors.Is(err, ErrNotHeld):
+		pkg.RespondJSON(w, http.StatusConflict, ErrorResponse{Error: "Экземпляр не находится в этой блокировке"})
+	case errors.Is(err, ErrReleased):
+		pkg.RespondJSON(w, http.StatusConflict, ErrorResponse{Error: "Блокировка уже снята"})
+	case errors.Is(err, ErrReworkStage):
+		pkg.RespondJSON(w, http.StatusBadRequest, ErrorResponse{Error: "Для доработки укажите этап маршрута, уже пройденный экземплярами"})
+	case errors.Is(err, ErrScrapReasonNotFound):
+		pkg.RespondJSON(w, http.StatusBadRequest, ErrorResponse{Error: "Для списания укажите действующую причину брака"})
+	case errors.Is(err, ErrInProgress):
+		pkg.RespondJSON(w, http.StatusConflict, ErrorResponse{Error: "У экземпляра есть незавершенный этап"})
+	case errors.Is(err, ErrScrapped):
+		pkg.RespondJSON(w, http.StatusConflict, ErrorResponse{Error: "Экземпляр уже списан в брак"})
+	default:
+		slog.Error("hold request failed", slog.Any("err", err))
+		pkg.RespondJSON(w, http.StatusInternalServerError, ErrorResponse{Error: "Внутренняя ошибка сервера"})
+	}
+}
@@ -0,0 +1,101 @@
+package hold
+
+import "time"
+
+// Области блокировки
+const (
+	ScopeInstances = "instances"
+	ScopeWorkOrder = "work_order"
+	ScopeMachine   = "machine"
+)
+
+// Статусы блокировки и экземпляра в ней
+const (
+	StatusActive   = "active"
+	StatusReleased = "released"
+)
+
+// Решения при снятии блокировки
+const (
+	DispositionUseAsIs = "use_as_is"
+	DispositionRework  = "rework"
+	DispositionScrap   = "scrap"
+)
+
+// Действия журнала блокировки
+const (
+	ActionPlaced   = "placed"
+	ActionReleased = "released"
+)
+
+// Hold блокировка (карантин) экземпляров продукции. Состав фиксируется при постановке:
+// по штрихкодам, по заказу или по машине в окне [WindowFrom, WindowTo).
+// Пока экземпляр в активной блокировке, его этапы не запускаются и он не отгружается.
+// Блокировка по заказу распространяется и на экземпляры, выпущенные после постановки, пока она активна.
+type Hold struct {
+	ID          int64      `gorm:"primaryKey;autoIncrement" json:"id"`
+	Reason      string     `gorm:"not null" json:"reason"`
+	Scope       string     `gorm:"not null" json:"scope" example:"work_order"`
+	WorkOrderID *int64     `json:"work_order_id,omitempty"`
+	MachineID   *int64     `json:"machine_id,omitempty"`
+	WindowFrom  *time.Time `json:"window_from,omitempty"`
+	WindowTo    *time.Time `json:"window_to,omitempty"`
+	Status      string     `gorm:"not null;default:active" json:"status" example:"active"`
+	CreatedBy   *int64     `json:"created_by,omitempty"`
+	CreatedAt   time.Time  `gorm:"autoCreateTime" json:"created_at"`
+	ReleasedAt  *time.Time `json:"released_at,omitempty"`
+	Items       []*Item    `gorm:"foreignKey:HoldID" json:"items,omitempty"`
+}
+
+func (Hold) TableName() string {
+	return "holds"
+}
+
+// Item экземпляр в блокировке; Disposition — решение при снятии
+type Item struct {
+	ID                int64      `gorm:"primaryKey;autoIncrement" json:"id"`
+	HoldID            int64      `json:"hold_id"`
+	ProductInstanceID int64      `json:"product_instance_id"`
+	Barcode           string     `gorm:"->" json:"barcode"`
+	Status            string     `gorm:"not null;default:active" json:"status" example:"active"`
+	Disposition       *string    `json:"disposition,omitempty" example:"rework"`
+	Comment           string     `json:"comment,omitempty"`
+	ReleasedBy        *int64     `json:"released_by,omitempty"`
+	ReleasedAt        *time.Time `json:"released_at,omitempty"`
+}
+
+func (Item) TableName() string {
+	return "hold_items"
+}
+
+// Event запись журнала блокировки; ProductInstanceID = nil — событие всей блокировки
+type Event struct {
+	ID                int64     `gorm:"primaryKey;autoIncrement" json:"id"`
+	HoldID            int64     `json:"hold_id"`
+	ProductInstanceID *int64    `json:"product_instance_id,omitempty"`
+	Action            string    `json:"action"`
+	Disposition       *string   `json:"disposition,omitempty"`
+	UserID            *int64    `json:"user_id,omitempty"`
+	Comment           string    `json:"comment,omitempty"`
+	CreatedAt         time.Time `gorm:"autoCreateTime" json:"created_at"`
+}
+
+func (Event) TableName() string {
+	return "hold_events"
+}
+
+// InstanceRef экземпляр, найденный по штрихкоду
+type InstanceRef struct {
+	ID      int64
+	Barcode string
+}
+
+// ListFilter параметры выборки блокировок
+type ListFilter struct {
+	Status      string
+	Barcode     string
+	WorkOrderID int64
+	MachineID   int64
+	Limit       int
+	Offset      int
+}
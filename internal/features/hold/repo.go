@@ -0,0 +1,23 @@
+package hold
+
+import "time"
+
+type Repository interface {
+	// Create сохраняет блокировку с экземплярами и записью журнала под блокировкой строк экземпляров
+	Create(h *Hold, e *Event) error
+	// Release снимает активные экземпляры блокировки под блокировкой строки; instanceIDs = nil — все.
+	// Когда активных экземпляров не остается, блокировка получает статус released.
+	Release(holdID int64, instanceIDs []int64, change func(h *Hold, items []*Item) ([]*Event, error)) (*Hold, error)
+
+	Get(id int64) (*Hold, error)
+	List(filter ListFilter) ([]*Hold, error)
+	ListEvents(holdID int64) ([]*Event, error)
+
+	InstancesByBarcode(barcodes []string) ([]*InstanceRef, error)
+	InstancesByWorkOrder(workOrderID int64) ([]int64, error)
+	// InstancesByMachine экземпляры заказов машины, этапы которых выполнялись в [from, to)
+	InstancesByMachine(machineID int64, from, to time.Time) ([]int64, error)
+
+	WorkOrderExists(id int64) (bool, error)
+	MachineExists(id int64) (bool, error)
+}
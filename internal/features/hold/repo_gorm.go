@@ -0,0 +1,229 @@
+package hold
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type GormRepository struct {
+	db *gorm.DB
+}
+
+func NewGormRepository(db *gorm.DB) *GormRepository {
+	return &GormRepository{db: db}
+}
+
+func (r *GormRepository) Create(h *Hold, e *Event) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		// блокировка строк экземпляров сериализует постановку с запуском этапов
+		ids := make([]int64, 0, len(h.Items))
+		for _, item := range h.Items {
+			ids = append(ids, item.ProductInstanceID)
+		}
+		var locked []int64
+		err := tx.Table("product_instances").
+			Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("id IN ?", ids).
+			Order("id").
+			Pluck("id", &locked).
+			Error
+		if err != nil {
+			return err
+		}
+
+		if err := tx.Create(h).Error; err != nil {
+			return err
+		}
+		e.HoldID = h.ID
+		return tx.Create(e).Error
+	})
+}
+
+func (r *GormRepository) Release(holdID int64, instanceIDs []int64, change func(h *Hold, items []*Item) ([]*Event, error)) (*Hold, error) {
+	var h Hold
+
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&h, holdID).Error; err != nil {
+			return err
+		}
+
+		var items []*Item
+		q := tx.Where("hold_id = ? AND status = ?", holdID, StatusActive)
+		if instanceIDs != nil {
+			q = q.Where("product_instance_id IN ?", instanceIDs)
+		}
+		if err := q.Order("id").Find(&items).Error; err != nil {
+			return err
+		}
+
+		events, err := change(&h, items)
+		if err != nil {
+			return err
+		}
+		for _, item := range items {
+			if err := tx.Save(item).Error; err != nil {
+				return err
+			}
+		}
+		for _, e := range events {
+			e.HoldID = h.ID
+		}
+		if len(events) > 0 {
+			if err := tx.Create(&events).Error; err != nil {
+				return err
+			}
+		}
+
+		var active int64
+		err = tx.Model(&Item{}).
+			Where("hold_id = ? AND status = ?", holdID, StatusActive).
+			Count(&active).
+			Error
+		if err != nil || active > 0 {
+			return err
+		}
+
+		h.Status = StatusReleased
+		h.ReleasedAt = items[len(items)-1].ReleasedAt
+		return tx.Omit(clause.Associations).Save(&h).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return r.Get(h.ID)
+}
+
+func (r *GormRepository) Get(id int64) (*Hold, error) {
+	var h Hold
+	err := r.db.
+		Preload("Items", func(db *gorm.DB) *gorm.DB {
+			return db.
+				Select("hold_items.*, pi.barcode").
+				Joins("JOIN product_instances pi ON pi.id = hold_items.product_instance_id").
+				Order("hold_items.id")
+		}).
+		First(&h, id).
+		Error
+	if err != nil {
+		return nil, err
+	}
+	return &h, nil
+}
+
+func (r *GormRepository) List(filter ListFilter) ([]*Hold, error) {
+	var holds []*Hold
+
+	q := r.db.Order("id DESC")
+	if filter.Status != "" {
+		q = q.Where("status = ?", filter.Status)
+	}
+	if filter.Barcode != "" {
+		q = q.Where(
+			"EXISTS (SELECT 1 FROM hold_items hi JOIN product_instances pi ON pi.id = hi.product_instance_id WHERE hi.hold_id = holds.id AND pi.barcode = ?)",
+			filter.Barcode,
+		)
+	}
+	if filter.WorkOrderID > 0 {
+		q = q.Where(
+			"EXISTS (SELECT 1 FROM hold_items hi JOIN product_instances pi ON pi.id = hi.product_instance_id WHERE hi.hold_id = holds.id AND pi.work_order_id = ?)",
+			filter.WorkOrderID,
+		)
+	}
+	if filter.MachineID > 0 {
+		q = q.Where("machine_id = ?", filter.MachineID)
+	}
+
+	return holds, q.Limit(filter.Limit).Offset(filter.Offset).Find(&holds).Error
+}
+
+func (r *GormRepository) ListEvents(holdID int64) ([]*Event, error) {
+	var events []*Event
+	err := r.db.
+		Where("hold_id = ?", holdID).
+		Order("created_at, id").
+		Find(&events).
+		Error
+	return events, err
+}
+
+func (r *GormRepository) InstancesByBarcode(barcodes []string) ([]*InstanceRef, error) {
+	var refs []*InstanceRef
+	err := r.db.Table("product_instances").
+		Select("id, barcode").
+		Where("barcode IN ?", barcodes).
+		Scan(&refs).
+		Error
+	return refs, err
+}
+
+func (r *GormRepository) InstancesByWorkOrder(workOrderID int64) ([]int64, error) {
+	var ids []int64
+	err := r.db.Table("product_instances").
+		Where("work_order_id = ?", workOrderID).
+		Order("id").
+		Pluck("id", &ids).
+		Error
+	return ids, err
+}
+
+func (r *GormRepository) InstancesByMachine(machineID int64, from, to time.Time) ([]int64, error) {
+	var ids []int64
+	err := r.db.Raw(`
+		SELECT DISTINCT pi.id
+		FROM product_instances pi
+		JOIN stage_execution se ON se.product_instance_id = pi.id
+		WHERE se.start_time < ? AND (se.end_time IS NULL OR se.end_time > ?)
+		  AND (
+			EXISTS (SELECT 1 FROM work_orders wo WHERE wo.id = pi.work_order_id AND wo.machine_id = ?)
+			OR EXISTS (
+				SELECT 1 FROM schedule s
+				WHERE s.work_order_id = pi.work_order_id AND s.machine_id = ? AND s.kind = 'run'
+				  AND s.start_time < ? AND s.end_time > ?
+			)
+		  )
+		ORDER BY pi.id`,
+		to, from, machineID, machineID, to, from,
+	).Scan(&ids).Error
+	return ids, err
+}
+
+// QueryBlocked экземпляры из ids в активной блокировке, снятые с решением scrap или выпущенные
+// по заказу под активной блокировкой после ее постановки. Такие экземпляры не запускаются на этапы
+// и не отгружаются; принимает транзакцию вызывающего модуля, чтобы проверка шла под его блокировкой
+// экземпляров.
+func QueryBlocked(db *gorm.DB, ids []int64) ([]int64, error) {
+	var blocked []int64
+	err := db.Raw(`
+		SELECT pi.id
+		FROM product_instances pi
+		WHERE pi.id IN ?
+		  AND (
+			EXISTS (
+				SELECT 1 FROM hold_items hi
+				WHERE hi.product_instance_id = pi.id AND (hi.status = ? OR hi.disposition = ?)
+			)
+			OR EXISTS (
+				SELECT 1 FROM holds h
+				WHERE h.scope = ? AND h.status = ? AND h.work_order_id = pi.work_order_id
+				  AND NOT EXISTS (SELECT 1 FROM hold_items hi WHERE hi.hold_id = h.id AND hi.product_instance_id = pi.id)
+			)
+		  )
+		ORDER BY pi.id`,
+		ids, StatusActive, DispositionScrap, ScopeWorkOrder, StatusActive,
+	).Scan(&blocked).Error
+	return blocked, err
+}
+
+func (r *GormRepository) WorkOrderExists(id int64) (bool, error) {
+	var n int64
+	err := r.db.Table("work_orders").Where("id = ?", id).Count(&n).Error
+	return n > 0, err
+}
+
+func (r *GormRepository) MachineExists(id int64) (bool, error) {
+	var n int64
+	err := r.db.Table("machines").Where("id = ?", id).Count(&n).Error
+	return n > 0, err
+}
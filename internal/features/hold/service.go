@@ -0,0 +1,314 @@
+package hold
+
+import (
+	"errors"
+	"slices"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+const (
+	// MaxWindow наибольшее окно блокировки по машине
+	MaxWindow = 400 * 24 * time.Hour
+	// maxLimit ограничивает размер страницы блокировок
+	maxLimit = 200
+)
+
+var (
+	ErrNotFound           = errors.New("hold not found")
+	ErrReasonRequired     = errors.New("hold reason is required")
+	ErrInvalidScope       = errors.New("hold needs exactly one of barcodes, work order or machine window")
+	ErrInstanceNotFound   = errors.New("product instance not found")
+	ErrWorkOrderNotFound  = errors.New("work order not found")
+	ErrMachineNotFound    = errors.New("machine not found")
+	ErrInvalidWindow      = errors.New("window end must be after start")
+	ErrWindowTooLong      = errors.New("window is too long")
+	ErrNoInstances        = errors.New("no product instances match the hold")
+	ErrInvalidDisposition = errors.New("disposition must be use_as_is, rework or scrap")
+	ErrNotHeld            = errors.New("instance is not held by this hold")
+	ErrReleased           = errors.New("hold is already released")
+	ErrInvalidStatus      = errors.New("unknown hold status")
+
+	ErrReworkStage         = errors.New("rework needs a finished stage of the routing")
+	ErrScrapReasonNotFound = errors.New("scrap needs an active scrap reason")
+	ErrInProgress          = errors.New("instance has an open stage execution")
+	ErrScrapped            = errors.New("instance is scrapped")
+)
+
+// Placement постановка блокировки: ровно одно из Barcodes, WorkOrderID или MachineID с окном [From, To)
+type Placement struct {
+	Reason      string     `json:"reason" example:"Трещины сварного шва в партии"`
+	Barcodes    []string   `json:"barcodes,omitempty"`
+	WorkOrderID *int64     `json:"work_order_id,omitempty" example:"1"`
+	MachineID   *int64     `json:"machine_id,omitempty"`
+	From        *time.Time `json:"from,omitempty" example:"2026-11-02T06:00:00Z"`
+	To          *time.Time `json:"to,omitempty" example:"2026-11-02T14:00:00Z"`
+}
+
+// Release снятие блокировки с решением; без Barcodes снимаются все оставшиеся экземпляры.
+// rework требует этап возврата StageID, scrap — причину списания ScrapReasonID.
+type Release struct {
+	Barcodes      []string `json:"barcodes,omitempty"`
+	Disposition   string   `json:"disposition" example:"use_as_is"`
+	StageID       *int64   `json:"stage_id,omitempty" example:"2"`
+	ScrapReasonID *int     `json:"scrap_reason_id,omitempty" example:"2"`
+	Comment       string   `json:"comment,omitempty" example:"Дефект в пределах допуска по решению ОТК"`
+}
+
+// Disposer проводит решения rework и scrap через выполнение этапов: доработка и списание
+// записываются так же, как по результату контроля, и учитываются в выходе годных
+type Disposer interface {
+	HoldRework(instanceID, holdID, stageID int64, comment string, userID int64) error
+	HoldScrap(instanceID int64, reasonID int, comment string, userID int64) error
+}
+
+// ServiceInterface определяет методы, используемые handler’ом
+type ServiceInterface interface {
+	List(filter ListFilter) ([]*Hold, error)
+	Get(id int64) (*Hold, error)
+	Events(id int64) ([]*Event, error)
+
+	Place(p Placement, userID int64) (*Hold, error)
+	Release(id int64, r Release, userID int64) (*Hold, error)
+}
+
+type Service struct {
+	repo     Repository
+	disposer Disposer
+	now      func() time.Time
+}
+
+func NewService(repo Repository, disposer Disposer) *Service {
+	return &Service{
+		repo:     repo,
+		disposer: disposer,
+		now:      time.Now,
+	}
+}
+
+func (s *Service) List(filter ListFilter) ([]*Hold, error) {
+	switch filter.Status {
+	case "", StatusActive, StatusReleased:
+	default:
+		return nil, ErrInvalidStatus
+	}
+	filter.Barcode = strings.TrimSpace(filter.Barcode)
+	if filter.Limit <= 0 || filter.Limit > maxLimit {
+		filter.Limit = maxLimit
+	}
+	return s.repo.List(filter)
+}
+
+func (s *Service) Get(id int64) (*Hold, error) {
+	h, err := s.repo.Get(id)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrNotFound
+	}
+	return h, err
+}
+
+func (s *Service) Events(id int64) ([]*Event, error) {
+	if _, err := s.Get(id); err != nil {
+		return nil, err
+	}
+	return s.repo.ListEvents(id)
+}
+
+// Place ставит блокировку на экземпляры, найденные по области на момент постановки.
+// Экземпляр может входить в несколько блокировок и свободен, только когда сняты все.
+func (s *Service) Place(p Placement, userID int64) (*Hold, error) {
+	h := &Hold{
+		Reason:    strings.TrimSpace(p.Reason),
+		Status:    StatusActive,
+		CreatedBy: &userID,
+	}
+	if h.Reason == "" {
+		return nil, ErrReasonRequired
+	}
+
+	ids, err := s.scope(h, p)
+	if err != nil {
+		return nil, err
+	}
+	if len(ids) == 0 {
+		return nil, ErrNoInstances
+	}
+
+	h.Items = make([]*Item, 0, len(ids))
+	for _, id := range ids {
+		h.Items = append(h.Items, &Item{ProductInstanceID: id, Status: StatusActive})
+	}
+
+	if err := s.repo.Create(h, &Event{Action: ActionPlaced, UserID: &userID, Comment: h.Reason}); err != nil {
+		return nil, err
+	}
+	return s.Get(h.ID)
+}
+
+// Release снимает экземпляры с блокировки с решением: use_as_is освобождает экземпляр, rework
+// возвращает его на этап StageID, scrap списывает в брак с причиной ScrapReasonID. Решения
+// проводятся под блокировкой строки блокировки; уже проведенные повторно не применяются.
+func (s *Service) Release(id int64, r Release, userID int64) (*Hold, error) {
+	switch r.Disposition {
+	case DispositionUseAsIs:
+	case DispositionRework:
+		if r.StageID == nil {
+			return nil, ErrReworkStage
+		}
+	case DispositionScrap:
+		if r.ScrapReasonID == nil {
+			return nil, ErrScrapReasonNotFound
+		}
+	default:
+		return nil, ErrInvalidDisposition
+	}
+	comment := strings.TrimSpace(r.Comment)
+
+	var ids []int64
+	if len(r.Barcodes) > 0 {
+		refs, err := s.instances(r.Barcodes)
+		if err != nil {
+			return nil, err
+		}
+		for _, ref := range refs {
+			ids = append(ids, ref.ID)
+		}
+	}
+
+	h, err := s.repo.Release(id, ids, func(h *Hold, items []*Item) ([]*Event, error) {
+		if h.Status == StatusReleased {
+			return nil, ErrReleased
+		}
+		if len(items) == 0 || (ids != nil && len(items) != len(ids)) {
+			return nil, ErrNotHeld
+		}
+
+		for _, item := range items {
+			if err := s.dispose(h.ID, item.ProductInstanceID, r, comment, userID); err != nil {
+				return nil, err
+			}
+		}
+
+		now := s.now()
+		events := make([]*Event, 0, len(items))
+		for _, item := range items {
+			item.Status = StatusReleased
+			item.Disposition = &r.Disposition
+			item.Comment = comment
+			item.ReleasedBy = &userID
+			item.ReleasedAt = &now
+
+			events = append(events, &Event{
+				ProductInstanceID: &item.ProductInstanceID,
+				Action:            ActionReleased,
+				Disposition:       &r.Disposition,
+				UserID:            &userID,
+				Comment:           comment,
+			})
+		}
+		return events, nil
+	})
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrNotFound
+	}
+	return h, err
+}
+
+// dispose проводит решение по экземпляру; use_as_is ничего не меняет в выполнении этапов
+func (s *Service) dispose(holdID, instanceID int64, r Release, comment string, userID int64) error {
+	switch r.Disposition {
+	case DispositionRework:
+		return s.disposer.HoldRework(instanceID, holdID, *r.StageID, comment, userID)
+	case DispositionScrap:
+		return s.disposer.HoldScrap(instanceID, *r.ScrapReasonID, comment, userID)
+	}
+	return nil
+}
+
+// scope заполняет область блокировки и возвращает ее экземпляры
+func (s *Service) scope(h *Hold, p Placement) ([]int64, error) {
+	n := 0
+	if len(p.Barcodes) > 0 {
+		n++
+	}
+	if p.WorkOrderID != nil {
+		n++
+	}
+	if p.MachineID != nil {
+		n++
+	}
+	if n != 1 || (p.MachineID == nil && (p.From != nil || p.To != nil)) {
+		return nil, ErrInvalidScope
+	}
+
+	switch {
+	case len(p.Barcodes) > 0:
+		h.Scope = ScopeInstances
+		refs, err := s.instances(p.Barcodes)
+		if err != nil {
+			return nil, err
+		}
+		ids := make([]int64, 0, len(refs))
+		for _, ref := range refs {
+			ids = append(ids, ref.ID)
+		}
+		return ids, nil
+
+	case p.WorkOrderID != nil:
+		h.Scope = ScopeWorkOrder
+		h.WorkOrderID = p.WorkOrderID
+		ok, err := s.repo.WorkOrderExists(*p.WorkOrderID)
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			return nil, ErrWorkOrderNotFound
+		}
+		return s.repo.InstancesByWorkOrder(*p.WorkOrderID)
+
+	default:
+		h.Scope = ScopeMachine
+		h.MachineID = p.MachineID
+		if p.From == nil || p.To == nil || !p.To.After(*p.From) {
+			return nil, ErrInvalidWindow
+		}
+		if p.To.Sub(*p.From) > MaxWindow {
+			return nil, ErrWindowTooLong
+		}
+		h.WindowFrom, h.WindowTo = p.From, p.To
+
+		ok, err := s.repo.MachineExists(*p.MachineID)
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			return nil, ErrMachineNotFound
+		}
+		return s.repo.InstancesByMachine(*p.MachineID, *p.From, *p.To)
+	}
+}
+
+// instances находит экземпляры по штрихкодам; повторы не учитываются, все штрихкоды должны существовать
+func (s *Service) instances(barcodes []string) ([]*InstanceRef, error) {
+	var unique []string
+	for _, b := range barcodes {
+		b = strings.TrimSpace(b)
+		if b != "" && !slices.Contains(unique, b) {
+			unique = append(unique, b)
+		}
+	}
+	if len(unique) == 0 {
+		return nil, ErrInstanceNotFound
+	}
+
+	refs, err := s.repo.InstancesByBarcode(unique)
+	if err != nil {
+		return nil, err
+	}
+	if len(refs) != len(unique) {
+		return nil, ErrInstanceNotFound
+	}
+	return refs, nil
+}
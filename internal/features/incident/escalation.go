@@ -0,0 +1,291 @@
+package incident
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"math"
+	"slices"
+	"strings"
+	"time"
+
+	"mes-lite-back/internal/features/notification"
+
+	"gorm.io/gorm"
+)
+
+// MaxMetricsRange наибольший период показателей инцидентов
+const MaxMetricsRange = 400 * 24 * time.Hour
+
+var (
+	ErrRuleNotFound    = errors.New("escalation rule not found")
+	ErrRoleNotFound    = errors.New("role not found")
+	ErrInvalidAfter    = errors.New("escalation delay must be positive")
+	ErrNoRecipient     = errors.New("escalation rule needs a role or a user to notify")
+	ErrInvalidStopOn   = errors.New("stop_on must be assigned or resolved")
+	ErrInvalidPeriod   = errors.New("period end must be after start")
+	ErrRangeTooLong    = errors.New("range is too long")
+	ErrRuleNameTooLong = errors.New("escalation rule name is too long")
+)
+
+// Notifier рассылка уведомлений об эскалации
+type Notifier interface {
+	Notify(userIDs []int64, msg notification.Message) error
+}
+
+func (s *Service) ListRules(severityID int) ([]*EscalationRule, error) {
+	return s.repo.ListRules(severityID)
+}
+
+func (s *Service) GetRule(id int64) (*EscalationRule, error) {
+	rule, err := s.repo.GetRule(id)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrRuleNotFound
+	}
+	return rule, err
+}
+
+func (s *Service) CreateRule(rule *EscalationRule) error {
+	if err := s.validateRule(rule); err != nil {
+		return err
+	}
+
+	rule.ID = 0
+	return s.repo.SaveRule(rule)
+}
+
+func (s *Service) UpdateRule(rule *EscalationRule) error {
+	existing, err := s.GetRule(rule.ID)
+	if err != nil {
+		return err
+	}
+	if err := s.validateRule(rule); err != nil {
+		return err
+	}
+
+	existing.SeverityID = rule.SeverityID
+	existing.Name = rule.Name
+	existing.AfterMin = rule.AfterMin
+	existing.NotifyRoleID = rule.NotifyRoleID
+	existing.NotifyUserID = rule.NotifyUserID
+	existing.StopOn = rule.StopOn
+	existing.Active = rule.Active
+
+	if err := s.repo.SaveRule(existing); err != nil {
+		return err
+	}
+
+	*rule = *existing
+	return nil
+}
+
+func (s *Service) DeleteRule(id int64) error {
+	rule, err := s.GetRule(id)
+	if err != nil {
+		return err
+	}
+	return s.repo.DeleteRule(rule)
+}
+
+func (s *Service) Escalations(incidentID int64) ([]*Escalation, error) {
+	if _, err := s.Get(incidentID); err != nil {
+		return nil, err
+	}
+	return s.repo.ListEscalations(incidentID)
+}
+
+// Escalate рассылает эскалации по нерешенным инцидентам, у которых наступил срок правила.
+// Каждое правило срабатывает по инциденту один раз; возвращает число сработавших правил.
+func (s *Service) Escalate() (int, error) {
+	now := s.now()
+
+	due, err := s.repo.DueEscalations(now)
+	if err != nil {
+		return 0, err
+	}
+
+	var fired int
+	for _, d := range due {
+		var recipients []int64
+		if d.NotifyRoleID != nil {
+			ids, err := s.repo.RoleUsers(*d.NotifyRoleID)
+			if err != nil {
+				return fired, err
+			}
+			recipients = ids
+		}
+		if d.NotifyUserID != nil && !slices.Contains(recipients, *d.NotifyUserID) {
+			recipients = append(recipients, *d.NotifyUserID)
+		}
+
+		target := d.RuleName
+		if target == "" {
+			target = fmt.Sprintf("правило #%d", d.RuleID)
+		}
+		recorded, err := s.repo.RecordEscalation(&Escalation{
+			IncidentID:  d.IncidentID,
+			RuleID:      d.RuleID,
+			AfterMin:    d.AfterMin,
+			Recipients:  len(recipients),
+			EscalatedAt: now,
+		}, &Event{
+			Action:  ActionEscalated,
+			Comment: fmt.Sprintf("%s: не решен за %d мин", target, d.AfterMin),
+		})
+		if err != nil {
+			return fired, err
+		}
+		if !recorded {
+			continue
+		}
+		fired++
+
+		s.notifyEscalation(d, recipients)
+	}
+	return fired, nil
+}
+
+// Metrics MTTA/MTTR и число инцидентов, зарегистрированных за [from, to), в целом,
+// по серьезности и по линиям машин
+func (s *Service) Metrics(from, to time.Time) (*Metrics, error) {
+	if !to.After(from) {
+		return nil, ErrInvalidPeriod
+	}
+	if to.Sub(from) > MaxMetricsRange {
+		return nil, ErrRangeTooLong
+	}
+
+	m := &Metrics{From: from, To: to, Total: &MetricsRow{}}
+
+	total, err := s.repo.Metrics(from, to, "")
+	if err != nil {
+		return nil, err
+	}
+	if len(total) > 0 {
+		m.Total = total[0]
+	}
+	if m.BySeverity, err = s.repo.Metrics(from, to, "severity"); err != nil {
+		return nil, err
+	}
+	if m.ByLine, err = s.repo.Metrics(from, to, "line"); err != nil {
+		return nil, err
+	}
+
+	for _, row := range slices.Concat([]*MetricsRow{m.Total}, m.BySeverity, m.ByLine) {
+		row.MTTAMin = round1(row.MTTAMin)
+		row.MTTRMin = round1(row.MTTRMin)
+	}
+	return m, nil
+}
+
+func (s *Service) notifyEscalation(d *DueEscalation, recipients []int64) {
+	if s.notifier == nil || len(recipients) == 0 {
+		return
+	}
+
+	err := s.notifier.Notify(recipients, notification.Message{
+		Kind:       notification.KindEscalation,
+		Title:      fmt.Sprintf("Инцидент #%d не решен %d мин", d.IncidentID, d.AfterMin),
+		Body:       d.Title,
+		EntityType: "incident",
+		EntityID:   d.IncidentID,
+	})
+	if err != nil {
+		slog.Error("escalation notification failed",
+			slog.Int64("incident_id", d.IncidentID),
+			slog.Int64("rule_id", d.RuleID),
+			slog.Any("err", err),
+		)
+	}
+}
+
+func (s *Service) validateRule(rule *EscalationRule) error {
+	rule.Name = strings.TrimSpace(rule.Name)
+	if len([]rune(rule.Name)) > maxTitleLength {
+		return ErrRuleNameTooLong
+	}
+	if rule.AfterMin <= 0 {
+		return ErrInvalidAfter
+	}
+	if rule.NotifyRoleID == nil && rule.NotifyUserID == nil {
+		return ErrNoRecipient
+	}
+	switch rule.StopOn {
+	case "":
+		rule.StopOn = StopOnResolved
+	case StopOnAssigned, StopOnResolved:
+	default:
+		return ErrInvalidStopOn
+	}
+
+	_, err := s.repo.GetSeverity(rule.SeverityID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return ErrSeverityNotFound
+	}
+	if err != nil {
+		return err
+	}
+
+	if rule.NotifyRoleID != nil {
+		ok, err := s.repo.RoleExists(*rule.NotifyRoleID)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			return ErrRoleNotFound
+		}
+	}
+	if rule.NotifyUserID != nil {
+		ok, err := s.repo.UserExists(*rule.NotifyUserID)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			return ErrUserNotFound
+		}
+	}
+	return nil
+}
+
+// Escalator фоновая проверка сроков эскалации
+type Escalator struct {
+	service  *Service
+	interval time.Duration
+}
+
+func NewEscalator(service *Service, interval time.Duration) *Escalator {
+	return &Escalator{
+		service:  service,
+		interval: interval,
+	}
+}
+
+// Run проверяет инциденты каждые interval до отмены ctx
+func (e *Escalator) Run(ctx context.Context) {
+	ticker := time.NewTicker(e.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			n, err := e.service.Escalate()
+			if err != nil {
+				slog.Error("incident escalation failed", slog.Any("err", err))
+			}
+			if n > 0 {
+				slog.Info("incidents escalated", slog.Int("rules", n))
+			}
+		}
+	}
+}
+
+func round1(v *float64) *float64 {
+	if v == nil {
+		return nil
+	}
+	r := math.Round(*v*10) / 10
+	return &r
+}
@@ -0,0 +1,556 @@
+package incident
+
+import (
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"time"
+
+	"mes-lite-back/internal/http/middleware"
+	"mes-lite-back/pkg"
+
+	"github.com/go-chi/chi/v5"
+)
+
+type Handler struct {
+	service ServiceInterface
+	perms   middleware.PermissionChecker
+}
+
+func NewHandler(service ServiceInterface, perms middleware.PermissionChecker) *Handler {
+	return &Handler{
+		service: service,
+		perms:   perms,
+	}
+}
+
+func (h *Handler) Routes() chi.Router {
+	r := chi.NewRouter()
+
+	view := middleware.PermissionGuard(h.perms, "incident.view")
+	create := middleware.PermissionGuard(h.perms, "incident.create")
+	edit := middleware.PermissionGuard(h.perms, "incident.edit")
+	resolve := middleware.PermissionGuard(h.perms, "incident.resolve")
+
+	r.With(view).Get("/severities", h.severities)
+	r.With(view).Get("/escalation-rules", h.listRules)
+	r.With(edit).Post("/escalation-rules", h.createRule)
+	r.With(edit).Put("/escalation-rules/{id}", h.updateRule)
+	r.With(edit).Delete("/escalation-rules/{id}", h.deleteRule)
+	r.With(view).Get("/metrics", h.metrics)
+	r.With(view).Get("/", h.list)
+	r.With(view).Get("/{id}", h.getByID)
+	r.With(view).Get("/{id}/events", h.events)
+	r.With(view).Get("/{id}/escalations", h.escalations)
+	r.With(create).Post("/", h.create)
+	r.With(edit).Put("/{id}", h.update)
+	r.With(edit).Post("/{id}/assign", h.assign)
+	r.With(resolve).Post("/{id}/resolve", h.resolve)
+	r.With(resolve).Post("/{id}/reopen", h.reopen)
+
+	return r
+}
+
+type AssignRequest struct {
+	AssigneeID int64  `json:"assignee_id" example:"5"`
+	Comment    string `json:"comment,omitempty" example:"Бригада ремонта, смена 2"`
+}
+
+type ReopenRequest struct {
+	Comment string `json:"comment,omitempty" example:"Утечка повторилась"`
+}
+
+type RuleRequest struct {
+	SeverityID   int    `json:"severity_id" example:"4"`
+	Name         string `json:"name,omitempty" example:"Начальник смены"`
+	AfterMin     int    `json:"after_min" example:"10"`
+	NotifyRoleID *int64 `json:"notify_role_id,omitempty" example:"2"`
+	NotifyUserID *int64 `json:"notify_user_id,omitempty"`
+	StopOn       string `json:"stop_on,omitempty" example:"resolved"`
+	Active       *bool  `json:"active,omitempty" example:"true"`
+}
+
+func (req RuleRequest) rule() *EscalationRule {
+	active := true
+	if req.Active != nil {
+		active = *req.Active
+	}
+	return &EscalationRule{
+		SeverityID:   req.SeverityID,
+		Name:         req.Name,
+		AfterMin:     req.AfterMin,
+		NotifyRoleID: req.NotifyRoleID,
+		NotifyUserID: req.NotifyUserID,
+		StopOn:       req.StopOn,
+		Active:       active,
+	}
+}
+
+type ErrorResponse struct {
+	Error string `json:"error" example:"Описание ошибки"`
+}
+
+// IncidentSeverities godoc
+// @Summary Справочник серьезности инцидентов
+// @Tags incidents
+// @Security BearerAuth
+// @Produce json
+// @Success 200 {array} Severity
+// @Router /incidents/severities [get]
+func (h *Handler) severities(w http.ResponseWriter, r *http.Request) {
+	severities, err := h.service.Severities()
+	if err != nil {
+		h.respondError(w, err)
+		return
+	}
+	pkg.RespondJSON(w, http.StatusOK, severities)
+}
+
+// ListIncidents godoc
+// @Summary Получить инциденты
+// @Description Новые первыми; фильтры по статусу, серьезности, машине, заказу и исполнителю
+// @Tags incidents
+// @Security BearerAuth
+// @Produce json
+// @Param status query string false "Статус: open, in_progress, resolved"
+// @Param severity_id query int false "ID серьезности"
+// @Param machine_id query int false "ID машины"
+// @Param work_order_id query int false "ID заказа"
+// @Param assigned_to query int false "ID исполнителя"
+// @Param limit query int false "Размер страницы (не более 200)"
+// @Param offset query int false "Смещение"
+// @Success 200 {array} Incident
+// @Failure 400 {object} ErrorResponse
+// @Router /incidents [get]
+func (h *Handler) list(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	severityID, _ := strconv.Atoi(query.Get("severity_id"))
+	machineID, _ := strconv.ParseInt(query.Get("machine_id"), 10, 64)
+	workOrderID, _ := strconv.ParseInt(query.Get("work_order_id"), 10, 64)
+	assignedTo, _ := strconv.ParseInt(query.Get("assigned_to"), 10, 64)
+	limit, _ := strconv.Atoi(query.Get("limit"))
+	offset, _ := strconv.Atoi(query.Get("offset"))
+
+	incidents, err := h.service.List(ListFilter{
+		Status:      query.Get("status"),
+		SeverityID:  severityID,
+		MachineID:   machineID,
+		WorkOrderID: workOrderID,
+		AssignedTo:  assignedTo,
+		Limit:       limit,
+		Offset:      offset,
+	})
+	if err != nil {
+		h.respondError(w, err)
+		return
+	}
+	pkg.RespondJSON(w, http.StatusOK, incidents)
+}
+
+// GetIncident godoc
+// @Summary Получить инцидент по ID
+// @Tags incidents
+// @Security BearerAuth
+// @Produce json
+// @Param id path int true "ID инцидента"
+// @Success 200 {object} Incident
+// @Failure 404 {object} ErrorResponse
+// @Router /incidents/{id} [get]
+func (h *Handler) getByID(w http.ResponseWriter, r *http.Request) {
+	i, err := h.service.Get(pkg.ParamID(r))
+	if err != nil {
+		h.respondError(w, err)
+		return
+	}
+	pkg.RespondJSON(w, http.StatusOK, i)
+}
+
+// IncidentEvents godoc
+// @Summary Журнал инцидента
+// @Description Регистрация, изменения, назначения, решение и повторные открытия в хронологическом порядке
+// @Tags incidents
+// @Security BearerAuth
+// @Produce json
+// @Param id path int true "ID инцидента"
+// @Success 200 {array} Event
+// @Failure 404 {object} ErrorResponse
+// @Router /incidents/{id}/events [get]
+func (h *Handler) events(w http.ResponseWriter, r *http.Request) {
+	events, err := h.service.Events(pkg.ParamID(r))
+	if err != nil {
+		h.respondError(w, err)
+		return
+	}
+	pkg.RespondJSON(w, http.StatusOK, events)
+}
+
+// CreateIncident godoc
+// @Summary Зарегистрировать инцидент
+// @Description Инцидент высокой серьезности (или с stop_machine) переводит машину в статус down, что запускает перепланирование ее слотов
+// @Tags incidents
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param request body Report true "Инцидент"
+// @Success 201 {object} Incident
+// @Failure 400 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Router /incidents [post]
+func (h *Handler) create(w http.ResponseWriter, r *http.Request) {
+	var req Report
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		pkg.RespondJSON(w, http.StatusBadRequest, ErrorResponse{Error: "Некорректный JSON"})
+		return
+	}
+
+	i, err := h.service.Create(req, currentUser(r))
+	if err != nil {
+		h.respondError(w, err)
+		return
+	}
+	pkg.RespondJSON(w, http.StatusCreated, i)
+}
+
+// UpdateIncident godoc
+// @Summary Изменить инцидент
+// @Description Решенный инцидент изменить нельзя; машину нельзя сменить, если инцидент ее остановил
+// @Tags incidents
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param id path int true "ID инцидента"
+// @Param request body Edit true "Инцидент"
+// @Success 200 {object} Incident
+// @Failure 400 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse
+// @Router /incidents/{id} [put]
+func (h *Handler) update(w http.ResponseWriter, r *http.Request) {
+	var req Edit
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		pkg.RespondJSON(w, http.StatusBadRequest, ErrorResponse{Error: "Некорректный JSON"})
+		return
+	}
+
+	i, err := h.service.Update(pkg.ParamID(r), req, currentUser(r))
+	if err != nil {
+		h.respondError(w, err)
+		return
+	}
+	pkg.RespondJSON(w, http.StatusOK, i)
+}
+
+// AssignIncident godoc
+// @Summary Назначить исполнителя
+// @Description Открытый инцидент переходит в статус in_progress; assigned_at фиксирует первое назначение и при переназначении не меняется
+// @Tags incidents
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param id path int true "ID инцидента"
+// @Param request body AssignRequest true "Исполнитель"
+// @Success 200 {object} Incident
+// @Failure 400 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse
+// @Router /incidents/{id}/assign [post]
+func (h *Handler) assign(w http.ResponseWriter, r *http.Request) {
+	var req AssignRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		pkg.RespondJSON(w, http.StatusBadRequest, ErrorResponse{Error: "Некорректный JSON"})
+		return
+	}
+
+	i, err := h.service.Assign(pkg.ParamID(r), req.AssigneeID, req.Comment, currentUser(r))
+	if err != nil {
+		h.respondError(w, err)
+		return
+	}
+	pkg.RespondJSON(w, http.StatusOK, i)
+}
+
+// ResolveIncident godoc
+// @Summary Решить инцидент
+// @Description Устанавливает resolved_at; restore_machine возвращает остановленную инцидентом машину в статус idle
+// @Tags incidents
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param id path int true "ID инцидента"
+// @Param request body Resolution true "Решение"
+// @Success 200 {object} Incident
+// @Failure 400 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse
+// @Router /incidents/{id}/resolve [post]
+func (h *Handler) resolve(w http.ResponseWriter, r *http.Request) {
+	var req Resolution
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		pkg.RespondJSON(w, http.StatusBadRequest, ErrorResponse{Error: "Некорректный JSON"})
+		return
+	}
+
+	i, err := h.service.Resolve(pkg.ParamID(r), req, currentUser(r))
+	if err != nil {
+		h.respondError(w, err)
+		return
+	}
+	pkg.RespondJSON(w, http.StatusOK, i)
+}
+
+// ReopenIncident godoc
+// @Summary Открыть инцидент повторно
+// @Description Снимает решение; инцидент с исполнителем возвращается в in_progress, без него — в open
+// @Tags incidents
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param id path int true "ID инцидента"
+// @Param request body ReopenRequest true "Причина"
+// @Success 200 {object} Incident
+// @Failure 400 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse
+// @Router /incidents/{id}/reopen [post]
+func (h *Handler) reopen(w http.ResponseWriter, r *http.Request) {
+	var req ReopenRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		pkg.RespondJSON(w, http.StatusBadRequest, ErrorResponse{Error: "Некорректный JSON"})
+		return
+	}
+
+	i, err := h.service.Reopen(pkg.ParamID(r), req.Comment, currentUser(r))
+	if err != nil {
+		h.respondError(w, err)
+		return
+	}
+	pkg.RespondJSON(w, http.StatusOK, i)
+}
+
+// ListEscalationRules godoc
+// @Summary Правила эскалации инцидентов
+// @Tags incidents
+// @Security BearerAuth
+// @Produce json
+// @Param severity_id query int false "ID серьезности"
+// @Success 200 {array} EscalationRule
+// @Router /incidents/escalation-rules [get]
+func (h *Handler) listRules(w http.ResponseWriter, r *http.Request) {
+	severityID, _ := strconv.Atoi(r.URL.Query().Get("severity_id"))
+
+	rules, err := h.service.ListRules(severityID)
+	if err != nil {
+		h.respondError(w, err)
+		return
+	}
+	pkg.RespondJSON(w, http.StatusOK, rules)
+}
+
+// CreateEscalationRule godoc
+// @Summary Добавить правило эскалации
+// @Description Через after_min минут после регистрации нерешенного инцидента уведомляются пользователи роли и/или пользователь; stop_on=assigned отключает правило после назначения исполнителя
+// @Tags incidents
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param request body RuleRequest true "Правило"
+// @Success 201 {object} EscalationRule
+// @Failure 400 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Router /incidents/escalation-rules [post]
+func (h *Handler) createRule(w http.ResponseWriter, r *http.Request) {
+	var req RuleRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		pkg.RespondJSON(w, http.StatusBadRequest, ErrorResponse{Error: "Некорректный JSON"})
+		return
+	}
+
+	rule := req.rule()
+	if err := h.service.CreateRule(rule); err != nil {
+		h.respondError(w, err)
+		return
+	}
+	pkg.RespondJSON(w, http.StatusCreated, rule)
+}
+
+// UpdateEscalationRule godoc
+// @Summary Изменить правило эскалации
+// @Description Уже сработавшие по инцидентам эскалации не повторяются
+// @Tags incidents
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param id path int true "ID правила"
+// @Param request body RuleRequest true "Правило"
+// @Success 200 {object} EscalationRule
+// @Failure 400 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Router /incidents/escalation-rules/{id} [put]
+func (h *Handler) updateRule(w http.ResponseWriter, r *http.Request) {
+	var req RuleRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		pkg.RespondJSON(w, http.StatusBadRequest, ErrorResponse{Error: "Некорректный JSON"})
+		return
+	}
+
+	rule := req.rule()
+	rule.ID = pkg.ParamID(r)
+	if err := h.service.UpdateRule(rule); err != nil {
+		h.respondError(w, err)
+		return
+	}
+	pkg.RespondJSON(w, http.StatusOK, rule)
+}
+
+// DeleteEscalationRule godoc
+// @Summary Удалить правило эскалации
+// @Description Срабатывания правила удаляются вместе с ним; записи в журнале инцидентов сохраняются
+// @Tags incidents
+// @Security BearerAuth
+// @Param id path int true "ID правила"
+// @Success 204
+// @Failure 404 {object} ErrorResponse
+// @Router /incidents/escalation-rules/{id} [delete]
+func (h *Handler) deleteRule(w http.ResponseWriter, r *http.Request) {
+	if err := h.service.DeleteRule(pkg.ParamID(r)); err != nil {
+		h.respondError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// IncidentEscalations godoc
+// @Summary История эскалаций инцидента
+// @Tags incidents
+// @Security BearerAuth
+// @Produce json
+// @Param id path int true "ID инцидента"
+// @Success 200 {array} Escalation
+// @Failure 404 {object} ErrorResponse
+// @Router /incidents/{id}/escalations [get]
+func (h *Handler) escalations(w http.ResponseWriter, r *http.Request) {
+	escalations, err := h.service.Escalations(pkg.ParamID(r))
+	if err != nil {
+		h.respondError(w, err)
+		return
+	}
+	pkg.RespondJSON(w, http.StatusOK, escalations)
+}
+
+// IncidentMetrics godoc
+// @Summary Показатели инцидентов
+// @Description Число инцидентов, назначенных, решенных и эскалированных, MTTA и MTTR в минутах по инцидентам, зарегистрированным за период; в целом, по серьезности и по линиям
+// @Tags incidents
+// @Security BearerAuth
+// @Produce json
+// @Param from query string true "Начало периода (RFC3339 или ГГГГ-ММ-ДД)"
+// @Param to query string true "Конец периода (RFC3339 или ГГГГ-ММ-ДД включительно)"
+// @Success 200 {object} Metrics
+// @Failure 400 {object} ErrorResponse
+// @Router /incidents/metrics [get]
+func (h *Handler) metrics(w http.ResponseWriter, r *http.Request) {
+	from, to, ok := parseRange(w, r)
+	if !ok {
+		return
+	}
+
+	m, err := h.service.Metrics(from, to)
+	if err != nil {
+		h.respondError(w, err)
+		return
+	}
+	pkg.RespondJSON(w, http.StatusOK, m)
+}
+
+// parseRange разбирает обязательный период from/to в RFC3339 или ГГГГ-ММ-ДД;
+// дата в конце периода включает весь день
+func parseRange(w http.ResponseWriter, r *http.Request) (time.Time, time.Time, bool) {
+	query := r.URL.Query()
+	if query.Get("from") == "" || query.Get("to") == "" {
+		pkg.RespondJSON(w, http.StatusBadRequest, ErrorResponse{Error: "Укажите период from и to"})
+		return time.Time{}, time.Time{}, false
+	}
+
+	from, err := parseTime(query.Get("from"), false)
+	if err != nil {
+		pkg.RespondJSON(w, http.StatusBadRequest, ErrorResponse{Error: "Время должно быть в формате RFC3339 или ГГГГ-ММ-ДД"})
+		return time.Time{}, time.Time{}, false
+	}
+	to, err := parseTime(query.Get("to"), true)
+	if err != nil {
+		pkg.RespondJSON(w, http.StatusBadRequest, ErrorResponse{Error: "Время должно быть в формате RFC3339 или ГГГГ-ММ-ДД"})
+		return time.Time{}, time.Time{}, false
+	}
+	return from, to, true
+}
+
+func parseTime(value string, end bool) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+	t, err := time.ParseInLocation(time.DateOnly, value, time.Local)
+	if err != nil {
+		return time.Time{}, err
+	}
+	if end {
+		t = t.AddDate(0, 0, 1)
+	}
+	return t, nil
+}
+
+func currentUser(r *http.Request) int64 {
+	id, _ := middleware.UserIDFromContext(r.Context())
+	return id
+}
+
+func (h *Handler) respondError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, ErrNotFound):
+		pkg.RespondJSON(w, http.StatusNotFound, ErrorResponse{Error: "Инцидент не найден"})
+	case errors.Is(err, ErrSeverityNotFound):
+		pkg.RespondJSON(w, http.StatusBadRequest, ErrorResponse{Error: "Неизвестная серьезность инцидента"})
+	case errors.Is(err, ErrMachineNotFound):
+		pkg.RespondJSON(w, http.StatusNotFound, ErrorResponse{Error: "Машина не найдена"})
+	case errors.Is(err, ErrWorkOrderNotFound):
+		pkg.RespondJSON(w, http.StatusNotFound, ErrorResponse{Error: "Заказ не найден"})
+	case errors.Is(err, ErrUserNotFound):
+		pkg.RespondJSON(w, http.StatusNotFound, ErrorResponse{Error: "Пользователь не найден"})
+	case errors.Is(err, ErrTitleRequired):
+		pkg.RespondJSON(w, http.StatusBadRequest, ErrorResponse{Error: "Укажите заголовок инцидента"})
+	case errors.Is(err, ErrTitleTooLong):
+		pkg.RespondJSON(w, http.StatusBadRequest, ErrorResponse{Error: "Заголовок не должен превышать 200 символов"})
+	case errors.Is(err, ErrInvalidStatus):
+		pkg.RespondJSON(w, http.StatusBadRequest, ErrorResponse{Error: "Неизвестный статус инцидента"})
+	case errors.Is(err, ErrInvalidExpected):
+		pkg.RespondJSON(w, http.StatusBadRequest, ErrorResponse{Error: "Прогноз возврата машины должен быть в будущем"})
+	case errors.Is(err, ErrResolutionRequired):
+		pkg.RespondJSON(w, http.StatusBadRequest, ErrorResponse{Error: "Опишите решение инцидента"})
+	case errors.Is(err, ErrResolved):
+		pkg.RespondJSON(w, http.StatusConflict, ErrorResponse{Error: "Инцидент уже решен"})
+	case errors.Is(err, ErrMachineLocked):
+		pkg.RespondJSON(w, http.StatusConflict, ErrorResponse{Error: "Инцидент остановил машину, сменить ее нельзя"})
+	case errors.Is(err, ErrNotResolved):
+		pkg.RespondJSON(w, http.StatusConflict, ErrorResponse{Error: "Инцидент еще не решен"})
+	case errors.Is(err, ErrRuleNotFound):
+		pkg.RespondJSON(w, http.StatusNotFound, ErrorResponse{Error: "Правило эскалации не найдено"})
+	case errors.Is(err, ErrRoleNotFound):
+		pkg.RespondJSON(w, http.StatusNotFound, ErrorResponse{Error: "Роль не найдена"})
+	case errors.Is(err, ErrInvalidAfter):
+		pkg.RespondJSON(w, http.StatusBadRequest, ErrorResponse{Error: "Срок эскалации должен быть больше нуля"})
+	case errors.Is(err, ErrNoRecipient):
+		pkg.RespondJSON(w, http.StatusBadRequest, ErrorResponse{Error: "Укажите роль или пользователя для уведомления"})
+	case errors.Is(err, ErrInvalidStopOn):
+		pkg.RespondJSON(w, http.StatusBadRequest, ErrorResponse{Error: "stop_on должен быть assigned или resolved"})
+	case errors.Is(err, ErrRuleNameTooLong):
+		pkg.RespondJSON(w, http.StatusBadRequest, ErrorResponse{Error: "Название правила не должно превышать 200 символов"})
+	case errors.Is(err, ErrInvalidPeriod):
+		pkg.RespondJSON(w, http.StatusBadRequest, ErrorResponse{Error: "Конец периода должен быть позже начала"})
+	case errors.Is(err, ErrRangeTooLong):
+		pkg.RespondJSON(w, http.StatusBadRequest, ErrorResponse{Error: "Период не должен превышать 400 дней"})
+	default:
+		slog.Error("incident request failed", slog.Any("err", err))
+		pkg.RespondJSON(w, http.StatusInternalServerError, ErrorResponse{Error: "Внутренняя ошибка сервера"})
+	}
+}
@@ -0,0 +1,159 @@
+package incident
+
+import "time"
+
+// Статусы инцидента
+const (
+	StatusOpen       = "open"
+	StatusInProgress = "in_progress"
+	StatusResolved   = "resolved"
+)
+
+// Действия журнала инцидента
+const (
+	ActionCreated   = "created"
+	ActionUpdated   = "updated"
+	ActionAssigned  = "assigned"
+	ActionResolved  = "resolved"
+	ActionReopened  = "reopened"
+	ActionEscalated = "escalated"
+)
+
+// Incident инцидент на производстве, связанный с машиной и/или заказом.
+// MachineStopped — при регистрации машина была переведена в статус down.
+type Incident struct {
+	ID             int64      `gorm:"primaryKey;autoIncrement" json:"id"`
+	Title          string     `gorm:"not null" json:"title"`
+	Description    string     `json:"description"`
+	SeverityID     int        `json:"severity_id"`
+	MachineID      *int64     `json:"machine_id,omitempty"`
+	WorkOrderID    *int64     `json:"work_order_id,omitempty"`
+	Status         string     `gorm:"not null;default:open" json:"status" example:"open"`
+	ReportedBy     *int64     `json:"reported_by,omitempty"`
+	AssignedTo     *int64     `json:"assigned_to,omitempty"`
+	AssignedAt     *time.Time `json:"assigned_at,omitempty"`
+	ResolvedBy     *int64     `json:"resolved_by,omitempty"`
+	ResolvedAt     *time.Time `json:"resolved_at,omitempty"`
+	Resolution     string     `json:"resolution,omitempty"`
+	MachineStopped bool       `json:"machine_stopped"`
+	CreatedAt      time.Time  `gorm:"autoCreateTime" json:"created_at"`
+	UpdatedAt      time.Time  `gorm:"autoUpdateTime" json:"updated_at"`
+}
+
+func (Incident) TableName() string {
+	return "incidents"
+}
+
+// Severity серьезность инцидента; Level — порядок серьезности,
+// StopsMachine — инцидент по умолчанию останавливает машину
+type Severity struct {
+	ID           int    `gorm:"primaryKey" json:"id"`
+	Code         string `json:"code"`
+	Name         string `json:"name"`
+	Level        int    `json:"level"`
+	StopsMachine bool   `json:"stops_machine"`
+}
+
+func (Severity) TableName() string {
+	return "incident_severities"
+}
+
+// Event запись журнала инцидента
+type Event struct {
+	ID         int64     `gorm:"primaryKey;autoIncrement" json:"id"`
+	IncidentID int64     `json:"incident_id"`
+	Action     string    `json:"action"`
+	UserID     *int64    `json:"user_id,omitempty"`
+	Comment    string    `json:"comment,omitempty"`
+	CreatedAt  time.Time `gorm:"autoCreateTime" json:"created_at"`
+}
+
+func (Event) TableName() string {
+	return "incident_events"
+}
+
+// ListFilter параметры выборки инцидентов
+type ListFilter struct {
+	Status      string
+	SeverityID  int
+	MachineID   int64
+	WorkOrderID int64
+	AssignedTo  int64
+	Limit       int
+	Offset      int
+}
+
+// Условия прекращения эскалации
+const (
+	StopOnAssigned = "assigned" // правило не срабатывает после назначения исполнителя
+	StopOnResolved = "resolved" // правило срабатывает, пока инцидент не решен
+)
+
+// EscalationRule правило эскалации: через AfterMin минут после регистрации инцидента
+// серьезности SeverityID уведомляются пользователи роли NotifyRoleID и/или NotifyUserID
+type EscalationRule struct {
+	ID           int64     `gorm:"primaryKey;autoIncrement" json:"id"`
+	SeverityID   int       `gorm:"not null" json:"severity_id" example:"4"`
+	Name         string    `json:"name" example:"Начальник смены"`
+	AfterMin     int       `gorm:"not null" json:"after_min" example:"10"`
+	NotifyRoleID *int64    `json:"notify_role_id,omitempty" example:"2"`
+	NotifyUserID *int64    `json:"notify_user_id,omitempty"`
+	StopOn       string    `gorm:"not null;default:resolved" json:"stop_on" example:"resolved"`
+	Active       bool      `gorm:"not null" json:"active" example:"true"`
+	CreatedAt    time.Time `gorm:"autoCreateTime" json:"created_at"`
+	UpdatedAt    time.Time `gorm:"autoUpdateTime" json:"updated_at"`
+}
+
+func (EscalationRule) TableName() string {
+	return "escalation_rules"
+}
+
+// Escalation срабатывание правила эскалации по инциденту
+type Escalation struct {
+	ID          int64     `gorm:"primaryKey;autoIncrement" json:"id"`
+	IncidentID  int64     `json:"incident_id"`
+	RuleID      int64     `json:"rule_id"`
+	AfterMin    int       `json:"after_min"`
+	Recipients  int       `json:"recipients"`
+	EscalatedAt time.Time `json:"escalated_at"`
+}
+
+func (Escalation) TableName() string {
+	return "incident_escalations"
+}
+
+// DueEscalation нерешенный инцидент и правило, срок которого наступил
+type DueEscalation struct {
+	IncidentID   int64
+	Title        string
+	SeverityID   int
+	RuleID       int64
+	RuleName     string
+	AfterMin     int
+	NotifyRoleID *int64
+	NotifyUserID *int64
+}
+
+// MetricsRow показатели инцидентов группы. MTTA — среднее время до назначения исполнителя,
+// MTTR — среднее время до решения; считаются по инцидентам, где это произошло.
+type MetricsRow struct {
+	SeverityID   *int     `json:"severity_id,omitempty"`
+	SeverityName *string  `json:"severity_name,omitempty"`
+	LineID       *int64   `json:"line_id,omitempty"`
+	LineName     *string  `json:"line_name,omitempty"`
+	Incidents    int      `json:"incidents"`
+	Acknowledged int      `json:"acknowledged"`
+	Resolved     int      `json:"resolved"`
+	Escalated    int      `json:"escalated"`
+	MTTAMin      *float64 `gorm:"column:mtta_min" json:"mtta_min,omitempty"`
+	MTTRMin      *float64 `gorm:"column:mttr_min" json:"mttr_min,omitempty"`
+}
+
+// Metrics показатели инцидентов, зарегистрированных за [From, To)
+type Metrics struct {
+	From       time.Time     `json:"from"`
+	To         time.Time     `json:"to"`
+	Total      *MetricsRow   `json:"total"`
+	BySeverity []*MetricsRow `json:"by_severity"`
+	ByLine     []*MetricsRow `json:"by_line"`
+}
@@ -0,0 +1,37 @@
+package incident
+
+import "time"
+
+type Repository interface {
+	// Create сохраняет инцидент и первую запись журнала
+	Create(i *Incident, e *Event) error
+	// Update изменяет инцидент под блокировкой; change может вернуть запись журнала
+	Update(id int64, change func(i *Incident) (*Event, error)) (*Incident, error)
+
+	Get(id int64) (*Incident, error)
+	List(filter ListFilter) ([]*Incident, error)
+	ListEvents(incidentID int64) ([]*Event, error)
+
+	Severities() ([]*Severity, error)
+	GetSeverity(id int) (*Severity, error)
+
+	ListRules(severityID int) ([]*EscalationRule, error)
+	GetRule(id int64) (*EscalationRule, error)
+	SaveRule(rule *EscalationRule) error
+	DeleteRule(rule *EscalationRule) error
+
+	// DueEscalations пары «инцидент — правило», срок которых наступил к now и которые еще не срабатывали
+	DueEscalations(now time.Time) ([]*DueEscalation, error)
+	// RecordEscalation сохраняет срабатывание и запись журнала; false — правило по инциденту уже сработало
+	RecordEscalation(e *Escalation, event *Event) (bool, error)
+	ListEscalations(incidentID int64) ([]*Escalation, error)
+
+	// Metrics показатели инцидентов за [from, to), сгруппированные по groupBy: "", "severity" или "line"
+	Metrics(from, to time.Time, groupBy string) ([]*MetricsRow, error)
+
+	RoleUsers(roleID int64) ([]int64, error)
+	RoleExists(id int64) (bool, error)
+	MachineExists(id int64) (bool, error)
+	WorkOrderExists(id int64) (bool, error)
+	UserExists(id int64) (bool, error)
+}
@@ -0,0 +1,251 @@
+package incident
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type GormRepository struct {
+	db *gorm.DB
+}
+
+func NewGormRepository(db *gorm.DB) *GormRepository {
+	return &GormRepository{db: db}
+}
+
+func (r *GormRepository) Create(i *Incident, e *Event) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(i).Error; err != nil {
+			return err
+		}
+		e.IncidentID = i.ID
+		return tx.Create(e).Error
+	})
+}
+
+func (r *GormRepository) Update(id int64, change func(i *Incident) (*Event, error)) (*Incident, error) {
+	var i Incident
+
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&i, id).Error; err != nil {
+			return err
+		}
+
+		e, err := change(&i)
+		if err != nil {
+			return err
+		}
+		if err := tx.Save(&i).Error; err != nil {
+			return err
+		}
+		if e == nil {
+			return nil
+		}
+
+		e.IncidentID = i.ID
+		return tx.Create(e).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &i, nil
+}
+
+func (r *GormRepository) Get(id int64) (*Incident, error) {
+	var i Incident
+	if err := r.db.First(&i, id).Error; err != nil {
+		return nil, err
+	}
+	return &i, nil
+}
+
+func (r *GormRepository) List(filter ListFilter) ([]*Incident, error) {
+	var incidents []*Incident
+
+	q := r.db.Order("id DESC")
+	if filter.Status != "" {
+		q = q.Where("status = ?", filter.Status)
+	}
+	if filter.SeverityID > 0 {
+		q = q.Where("severity_id = ?", filter.SeverityID)
+	}
+	if filter.MachineID > 0 {
+		q = q.Where("machine_id = ?", filter.MachineID)
+	}
+	if filter.WorkOrderID > 0 {
+		q = q.Where("work_order_id = ?", filter.WorkOrderID)
+	}
+	if filter.AssignedTo > 0 {
+		q = q.Where("assigned_to = ?", filter.AssignedTo)
+	}
+	if filter.Limit > 0 {
+		q = q.Limit(filter.Limit)
+	}
+	if filter.Offset > 0 {
+		q = q.Offset(filter.Offset)
+	}
+
+	return incidents, q.Find(&incidents).Error
+}
+
+func (r *GormRepository) ListEvents(incidentID int64) ([]*Event, error) {
+	var events []*Event
+	err := r.db.
+		Where("incident_id = ?", incidentID).
+		Order("created_at, id").
+		Find(&events).
+		Error
+	return events, err
+}
+
+func (r *GormRepository) Severities() ([]*Severity, error) {
+	var severities []*Severity
+	return severities, r.db.Order("level, id").Find(&severities).Error
+}
+
+func (r *GormRepository) GetSeverity(id int) (*Severity, error) {
+	var s Severity
+	if err := r.db.First(&s, id).Error; err != nil {
+		return nil, err
+	}
+	return &s, nil
+}
+
+func (r *GormRepository) ListRules(severityID int) ([]*EscalationRule, error) {
+	var rules []*EscalationRule
+
+	q := r.db.Order("severity_id, after_min, id")
+	if severityID > 0 {
+		q = q.Where("severity_id = ?", severityID)
+	}
+
+	return rules, q.Find(&rules).Error
+}
+
+func (r *GormRepository) GetRule(id int64) (*EscalationRule, error) {
+	var rule EscalationRule
+	if err := r.db.First(&rule, id).Error; err != nil {
+		return nil, err
+	}
+	return &rule, nil
+}
+
+func (r *GormRepository) SaveRule(rule *EscalationRule) error {
+	return r.db.Save(rule).Error
+}
+
+func (r *GormRepository) DeleteRule(rule *EscalationRule) error {
+	return r.db.Delete(rule).Error
+}
+
+func (r *GormRepository) DueEscalations(now time.Time) ([]*DueEscalation, error) {
+	var due []*DueEscalation
+	err := r.db.
+		Table("incidents i").
+		Select(`i.id AS incident_id, i.title, i.severity_id, er.id AS rule_id, er.name AS rule_name,
+			er.after_min, er.notify_role_id, er.notify_user_id`).
+		Joins("JOIN escalation_rules er ON er.severity_id = i.severity_id AND er.active").
+		Where("i.status <> ?", StatusResolved).
+		Where("er.stop_on = ? OR i.assigned_to IS NULL", StopOnResolved).
+		Where("i.created_at + er.after_min * INTERVAL '1 minute' <= ?", now).
+		Where("NOT EXISTS (SELECT 1 FROM incident_escalations e WHERE e.incident_id = i.id AND e.rule_id = er.id)").
+		Order("i.id, er.after_min").
+		Scan(&due).
+		Error
+	return due, err
+}
+
+func (r *GormRepository) RecordEscalation(e *Escalation, event *Event) (bool, error) {
+	var recorded bool
+
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		// параллельный обработчик мог записать срабатывание раньше
+		res := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(e)
+		if res.Error != nil {
+			return res.Error
+		}
+		if res.RowsAffected == 0 {
+			return nil
+		}
+		recorded = true
+
+		event.IncidentID = e.IncidentID
+		return tx.Create(event).Error
+	})
+	return recorded, err
+}
+
+func (r *GormRepository) ListEscalations(incidentID int64) ([]*Escalation, error) {
+	var escalations []*Escalation
+	err := r.db.
+		Where("incident_id = ?", incidentID).
+		Order("escalated_at, id").
+		Find(&escalations).
+		Error
+	return escalations, err
+}
+
+func (r *GormRepository) Metrics(from, to time.Time, groupBy string) ([]*MetricsRow, error) {
+	var rows []*MetricsRow
+
+	q := r.db.
+		Table("incidents i").
+		Joins("LEFT JOIN incident_severities s ON s.id = i.severity_id").
+		Joins("LEFT JOIN machines m ON m.id = i.machine_id").
+		Joins("LEFT JOIN lines l ON l.id = m.line_id").
+		Where("i.created_at >= ? AND i.created_at < ?", from, to)
+
+	aggregates := `COUNT(*) AS incidents,
+		COUNT(i.assigned_at) AS acknowledged,
+		COUNT(i.resolved_at) AS resolved,
+		COUNT(*) FILTER (WHERE EXISTS (SELECT 1 FROM incident_escalations e WHERE e.incident_id = i.id)) AS escalated,
+		AVG(EXTRACT(EPOCH FROM i.assigned_at - i.created_at) / 60) AS mtta_min,
+		AVG(EXTRACT(EPOCH FROM i.resolved_at - i.created_at) / 60) AS mttr_min`
+
+	switch groupBy {
+	case "severity":
+		q = q.Select("i.severity_id, s.name AS severity_name, " + aggregates).
+			Group("i.severity_id, s.name, s.level").
+			Order("s.level NULLS LAST, i.severity_id")
+	case "line":
+		q = q.Select("m.line_id, l.name AS line_name, " + aggregates).
+			Group("m.line_id, l.name").
+			Order("l.name NULLS LAST, m.line_id")
+	default:
+		q = q.Select(aggregates)
+	}
+
+	return rows, q.Scan(&rows).Error
+}
+
+func (r *GormRepository) RoleUsers(roleID int64) ([]int64, error) {
+	var ids []int64
+	err := r.db.Table("users").Where("role_id = ?", roleID).Order("id").Pluck("id", &ids).Error
+	return ids, err
+}
+
+func (r *GormRepository) RoleExists(id int64) (bool, error) {
+	var n int64
+	err := r.db.Table("roles").Where("id = ?", id).Count(&n).Error
+	return n > 0, err
+}
+
+func (r *GormRepository) MachineExists(id int64) (bool, error) {
+	var n int64
+	err := r.db.Table("machines").Where("id = ?", id).Count(&n).Error
+	return n > 0, err
+}
+
+func (r *GormRepository) WorkOrderExists(id int64) (bool, error) {
+	var n int64
+	err := r.db.Table("work_orders").Where("id = ?", id).Count(&n).Error
+	return n > 0, err
+}
+
+func (r *GormRepository) UserExists(id int64) (bool, error) {
+	var n int64
+	err := r.db.Table("users").Where("id = ?", id).Count(&n).Error
+	return n > 0, err
+}
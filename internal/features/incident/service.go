@@ -0,0 +1,372 @@
+package incident
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"mes-lite-back/internal/features/machine"
+	"mes-lite-back/pkg"
+
+	"gorm.io/gorm"
+)
+
+const (
+	// maxTitleLength ограничивает длину заголовка инцидента в символах
+	maxTitleLength = 200
+	// maxLimit ограничивает размер страницы инцидентов
+	maxLimit = 200
+)
+
+var (
+	ErrNotFound           = errors.New("incident not found")
+	ErrSeverityNotFound   = errors.New("incident severity not found")
+	ErrMachineNotFound    = errors.New("machine not found")
+	ErrWorkOrderNotFound  = errors.New("work order not found")
+	ErrUserNotFound       = errors.New("user not found")
+	ErrTitleRequired      = errors.New("incident title is required")
+	ErrTitleTooLong       = errors.New("incident title is too long")
+	ErrInvalidStatus      = errors.New("unknown incident status")
+	ErrInvalidExpected    = errors.New("expected machine return must be in the future")
+	ErrResolved           = errors.New("resolved incident cannot be changed")
+	ErrNotResolved        = errors.New("incident is not resolved")
+	ErrResolutionRequired = errors.New("resolution is required")
+	ErrMachineLocked      = errors.New("machine of an incident that stopped it cannot be changed")
+)
+
+// Report регистрация инцидента. StopMachine переопределяет остановку машины, заданную серьезностью;
+// ExpectedUntil — прогноз возврата машины в работу.
+type Report struct {
+	Title         string     `json:"title" example:"Утечка масла на прессе"`
+	Description   string     `json:"description,omitempty" example:"Под прессом лужа масла, давление падает"`
+	SeverityID    int        `json:"severity_id" example:"3"`
+	MachineID     *int64     `json:"machine_id,omitempty" example:"1"`
+	WorkOrderID   *int64     `json:"work_order_id,omitempty"`
+	StopMachine   *bool      `json:"stop_machine,omitempty"`
+	ExpectedUntil *time.Time `json:"expected_until,omitempty" example:"2026-11-02T14:00:00Z"`
+}
+
+// Edit изменение описания инцидента
+type Edit struct {
+	Title       string `json:"title" example:"Утечка масла на прессе"`
+	Description string `json:"description,omitempty"`
+	SeverityID  int    `json:"severity_id" example:"3"`
+	MachineID   *int64 `json:"machine_id,omitempty" example:"1"`
+	WorkOrderID *int64 `json:"work_order_id,omitempty"`
+}
+
+// Resolution решение инцидента; RestoreMachine возвращает остановленную инцидентом машину в статус idle
+type Resolution struct {
+	Resolution     string `json:"resolution" example:"Заменено уплотнение гидроцилиндра"`
+	RestoreMachine bool   `json:"restore_machine" example:"true"`
+}
+
+// MachineStatusChanger смена статуса машины при остановке и возврате в работу
+type MachineStatusChanger interface {
+	ChangeStatus(machineID int64, change machine.StatusChange, userID int64) (*machine.Machine, error)
+}
+
+// ServiceInterface определяет методы, используемые handler’ом
+type ServiceInterface interface {
+	Severities() ([]*Severity, error)
+	List(filter ListFilter) ([]*Incident, error)
+	Get(id int64) (*Incident, error)
+	Events(id int64) ([]*Event, error)
+
+	Create(r Report, userID int64) (*Incident, error)
+	Update(id int64, e Edit, userID int64) (*Incident, error)
+	Assign(id, assigneeID int64, comment string, userID int64) (*Incident, error)
+	Resolve(id int64, r Resolution, userID int64) (*Incident, error)
+	Reopen(id int64, comment string, userID int64) (*Incident, error)
+
+	ListRules(severityID int) ([]*EscalationRule, error)
+	GetRule(id int64) (*EscalationRule, error)
+	CreateRule(rule *EscalationRule) error
+	UpdateRule(rule *EscalationRule) error
+	DeleteRule(id int64) error
+	Escalations(incidentID int64) ([]*Escalation, error)
+	Metrics(from, to time.Time) (*Metrics, error)
+}
+
+type Service struct {
+	repo     Repository
+	machines MachineStatusChanger
+	notifier Notifier
+	now      func() time.Time
+}
+
+func NewService(repo Repository, machines MachineStatusChanger, notifier Notifier) *Service {
+	return &Service{
+		repo:     repo,
+		machines: machines,
+		notifier: notifier,
+		now:      time.Now,
+	}
+}
+
+func (s *Service) Severities() ([]*Severity, error) {
+	return s.repo.Severities()
+}
+
+func (s *Service) List(filter ListFilter) ([]*Incident, error) {
+	switch filter.Status {
+	case "", StatusOpen, StatusInProgress, StatusResolved:
+	default:
+		return nil, ErrInvalidStatus
+	}
+	if filter.Limit <= 0 || filter.Limit > maxLimit {
+		filter.Limit = maxLimit
+	}
+	return s.repo.List(filter)
+}
+
+func (s *Service) Get(id int64) (*Incident, error) {
+	i, err := s.repo.Get(id)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrNotFound
+	}
+	return i, err
+}
+
+func (s *Service) Events(id int64) ([]*Event, error) {
+	if _, err := s.Get(id); err != nil {
+		return nil, err
+	}
+	return s.repo.ListEvents(id)
+}
+
+// Create регистрирует инцидент. Если серьезность останавливает машину (или это запрошено явно),
+// машина переводится в статус down, что запускает перепланирование ее слотов.
+func (s *Service) Create(r Report, userID int64) (*Incident, error) {
+	if r.ExpectedUntil != nil && !r.ExpectedUntil.After(s.now()) {
+		return nil, ErrInvalidExpected
+	}
+
+	i := &Incident{
+		Title:       r.Title,
+		Description: r.Description,
+		SeverityID:  r.SeverityID,
+		MachineID:   r.MachineID,
+		WorkOrderID: r.WorkOrderID,
+		Status:      StatusOpen,
+		ReportedBy:  &userID,
+	}
+	severity, err := s.validate(i)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.repo.Create(i, &Event{Action: ActionCreated, UserID: &userID}); err != nil {
+		return nil, err
+	}
+
+	stop := severity.StopsMachine
+	if r.StopMachine != nil {
+		stop = *r.StopMachine
+	}
+	if stop && i.MachineID != nil {
+		s.stopMachine(i, r.ExpectedUntil, userID)
+	}
+	return i, nil
+}
+
+// Update меняет описание, серьезность и привязки нерешенного инцидента
+func (s *Service) Update(id int64, e Edit, userID int64) (*Incident, error) {
+	changed := &Incident{
+		Title:       e.Title,
+		Description: e.Description,
+		SeverityID:  e.SeverityID,
+		MachineID:   e.MachineID,
+		WorkOrderID: e.WorkOrderID,
+	}
+	if _, err := s.validate(changed); err != nil {
+		return nil, err
+	}
+
+	return s.update(id, func(i *Incident) (*Event, error) {
+		if i.Status == StatusResolved {
+			return nil, ErrResolved
+		}
+		// остановленную машину возвращает в работу решение инцидента, поэтому она не меняется
+		if i.MachineStopped && !pkg.EqualPtr(i.MachineID, changed.MachineID) {
+			return nil, ErrMachineLocked
+		}
+		i.Title = changed.Title
+		i.Description = changed.Description
+		i.SeverityID = changed.SeverityID
+		i.MachineID = changed.MachineID
+		i.WorkOrderID = changed.WorkOrderID
+
+		return &Event{Action: ActionUpdated, UserID: &userID}, nil
+	})
+}
+
+// Assign назначает исполнителя; открытый инцидент переходит в работу
+func (s *Service) Assign(id, assigneeID int64, comment string, userID int64) (*Incident, error) {
+	ok, err := s.repo.UserExists(assigneeID)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, ErrUserNotFound
+	}
+
+	return s.update(id, func(i *Incident) (*Event, error) {
+		if i.Status == StatusResolved {
+			return nil, ErrResolved
+		}
+		// время первого подтверждения определяет MTTA и при переназначении не меняется
+		if i.AssignedAt == nil {
+			now := s.now()
+			i.AssignedAt = &now
+		}
+		i.AssignedTo = &assigneeID
+		i.Status = StatusInProgress
+
+		return &Event{Action: ActionAssigned, UserID: &userID, Comment: strings.TrimSpace(comment)}, nil
+	})
+}
+
+// Resolve закрывает инцидент с описанием решения и при необходимости возвращает машину в работу
+func (s *Service) Resolve(id int64, r Resolution, userID int64) (*Incident, error) {
+	resolution := strings.TrimSpace(r.Resolution)
+	if resolution == "" {
+		return nil, ErrResolutionRequired
+	}
+
+	i, err := s.update(id, func(i *Incident) (*Event, error) {
+		if i.Status == StatusResolved {
+			return nil, ErrResolved
+		}
+		now := s.now()
+		i.Status = StatusResolved
+		i.ResolvedAt = &now
+		i.ResolvedBy = &userID
+		i.Resolution = resolution
+
+		return &Event{Action: ActionResolved, UserID: &userID, Comment: resolution}, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if r.RestoreMachine && i.MachineStopped && i.MachineID != nil {
+		s.changeMachine(i, machine.StatusChange{
+			StatusID: machine.StatusIdle,
+			Comment:  fmt.Sprintf("Инцидент #%d решен: %s", i.ID, resolution),
+		}, userID)
+	}
+	return i, nil
+}
+
+// Reopen возвращает решенный инцидент в работу (или в открытые, если исполнитель не назначен)
+func (s *Service) Reopen(id int64, comment string, userID int64) (*Incident, error) {
+	return s.update(id, func(i *Incident) (*Event, error) {
+		if i.Status != StatusResolved {
+			return nil, ErrNotResolved
+		}
+		i.Status = StatusOpen
+		if i.AssignedTo != nil {
+			i.Status = StatusInProgress
+		}
+		i.ResolvedAt = nil
+		i.ResolvedBy = nil
+		i.Resolution = ""
+
+		return &Event{Action: ActionReopened, UserID: &userID, Comment: strings.TrimSpace(comment)}, nil
+	})
+}
+
+func (s *Service) update(id int64, change func(i *Incident) (*Event, error)) (*Incident, error) {
+	i, err := s.repo.Update(id, change)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrNotFound
+	}
+	return i, err
+}
+
+// stopMachine переводит машину инцидента в статус down. Ошибка смены статуса не отменяет
+// регистрацию инцидента: она логируется, а MachineStopped остается false.
+func (s *Service) stopMachine(i *Incident, expectedUntil *time.Time, userID int64) {
+	ok := s.changeMachine(i, machine.StatusChange{
+		StatusID:      machine.StatusDown,
+		WorkOrderID:   i.WorkOrderID,
+		ExpectedUntil: expectedUntil,
+		Comment:       fmt.Sprintf("Инцидент #%d: %s", i.ID, i.Title),
+	}, userID)
+	if !ok {
+		return
+	}
+
+	updated, err := s.repo.Update(i.ID, func(i *Incident) (*Event, error) {
+		i.MachineStopped = true
+		return nil, nil
+	})
+	if err != nil {
+		slog.Error("mark incident machine stopped failed", slog.Int64("incident_id", i.ID), slog.Any("err", err))
+		return
+	}
+	*i = *updated
+}
+
+// changeMachine меняет статус машины инцидента; машина уже в нужном статусе — не ошибка
+func (s *Service) changeMachine(i *Incident, change machine.StatusChange, userID int64) bool {
+	if s.machines == nil {
+		return false
+	}
+
+	_, err := s.machines.ChangeStatus(*i.MachineID, change, userID)
+	if errors.Is(err, machine.ErrSameStatus) {
+		return false
+	}
+	if err != nil {
+		slog.Error("incident machine status change failed",
+			slog.Int64("incident_id", i.ID),
+			slog.Int64("machine_id", *i.MachineID),
+			slog.Any("err", err),
+		)
+		return false
+	}
+	return true
+}
+
+func (s *Service) validate(i *Incident) (*Severity, error) {
+	i.Title = strings.TrimSpace(i.Title)
+	i.Description = strings.TrimSpace(i.Description)
+	if i.Title == "" {
+		return nil, ErrTitleRequired
+	}
+	if len([]rune(i.Title)) > maxTitleLength {
+		return nil, ErrTitleTooLong
+	}
+
+	severity, err := s.repo.GetSeverity(i.SeverityID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrSeverityNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if i.MachineID != nil {
+		ok, err := s.repo.MachineExists(*i.MachineID)
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			return nil, ErrMachineNotFound
+		}
+	}
+	if i.WorkOrderID != nil {
+		ok, err := s.repo.WorkOrderExists(*i.WorkOrderID)
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			return nil, ErrWorkOrderNotFound
+		}
+	}
+	return severity, nil
+}
@@ -0,0 +1,297 @@
+package instance
+
+import (
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strconv"
+
+	"mes-lite-back/internal/http/middleware"
+	"mes-lite-back/pkg"
+
+	"github.com/go-chi/chi/v5"
+)
+
+type Handler struct {
+	service ServiceInterface
+	perms   middleware.PermissionChecker
+}
+
+func NewHandler(service ServiceInterface, perms middleware.PermissionChecker) *Handler {
+	return &Handler{
+		service: service,
+		perms:   perms,
+	}
+}
+
+func (h *Handler) Routes() chi.Router {
+	r := chi.NewRouter()
+
+	view := middleware.PermissionGuard(h.perms, "product.instance.view")
+	create := middleware.PermissionGuard(h.perms, "product.instance.create")
+	ship := middleware.PermissionGuard(h.perms, "product.instance.ship")
+
+	r.With(view).Get("/", h.list)
+	r.With(view).Get("/{id}", h.getByID)
+	r.With(view).Get("/barcode/{barcode}", h.getByBarcode)
+	r.With(view).Get("/barcode/{barcode}/image", h.image)
+	r.With(create).Post("/", h.create)
+	r.With(create).Post("/bulk", h.createBulk)
+	r.With(ship).Post("/ship", h.ship)
+
+	return r
+}
+
+type CreateRequest struct {
+	ProductID int64 `json:"product_id" example:"1"`
+}
+
+type BulkCreateRequest struct {
+	WorkOrderID int64 `json:"work_order_id" example:"1"`
+	Count       int   `json:"count" example:"50"`
+}
+
+type ShipRequest struct {
+	Barcodes []string `json:"barcodes" example:"MES-GB01-260105-000042-2"`
+}
+
+// HoldErrorResponse штрихкоды экземпляров, отгрузку которых не пускает блокировка
+type HoldErrorResponse struct {
+	Error    string   `json:"error" example:"Экземпляры заблокированы (карантин)"`
+	Barcodes []string `json:"barcodes"`
+}
+
+type ErrorResponse struct {
+	Error string `json:"error" example:"Описание ошибки"`
+}
+
+// ListInstances godoc
+// @Summary Получить список экземпляров продукции
+// @Tags instances
+// @Security BearerAuth
+// @Produce json
+// @Param product_id query int false "ID продукта"
+// @Param work_order_id query int false "ID заказа"
+// @Param limit query int false "Количество записей"
+// @Param offset query int false "Смещение"
+// @Success 200 {array} Instance
+// @Failure 500 {object} ErrorResponse
+// @Router /instances [get]
+func (h *Handler) list(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	productID, _ := strconv.ParseInt(q.Get("product_id"), 10, 64)
+	workOrderID, _ := strconv.ParseInt(q.Get("work_order_id"), 10, 64)
+	limit, _ := strconv.Atoi(q.Get("limit"))
+	offset, _ := strconv.Atoi(q.Get("offset"))
+
+	instances, err := h.service.ListInstances(ListFilter{
+		ProductID:   productID,
+		WorkOrderID: workOrderID,
+		Limit:       limit,
+		Offset:      offset,
+	})
+	if err != nil {
+		slog.Error("list instances failed", slog.Any("err", err))
+		pkg.RespondJSON(w, http.StatusInternalServerError, ErrorResponse{Error: "Не удалось получить список экземпляров"})
+		return
+	}
+	pkg.RespondJSON(w, http.StatusOK, instances)
+}
+
+// GetInstance godoc
+// @Summary Получить экземпляр по ID
+// @Tags instances
+// @Security BearerAuth
+// @Produce json
+// @Param id path int true "ID экземпляра"
+// @Success 200 {object} Instance
+// @Failure 404 {object} ErrorResponse
+// @Router /instances/{id} [get]
+func (h *Handler) getByID(w http.ResponseWriter, r *http.Request) {
+	inst, err := h.service.GetInstance(pkg.ParamID(r))
+	if err != nil {
+		h.respondError(w, err)
+		return
+	}
+	pkg.RespondJSON(w, http.StatusOK, inst)
+}
+
+// GetInstanceByBarcode godoc
+// @Summary Найти экземпляр по штрихкоду
+// @Tags instances
+// @Security BearerAuth
+// @Produce json
+// @Param barcode path string true "Штрихкод"
+// @Success 200 {object} Instance
+// @Failure 404 {object} ErrorResponse
+// @Router /instances/barcode/{barcode} [get]
+func (h *Handler) getByBarcode(w http.ResponseWriter, r *http.Request) {
+	inst, err := h.service.GetByBarcode(chi.URLParam(r, "barcode"))
+	if err != nil {
+		h.respondError(w, err)
+		return
+	}
+	pkg.RespondJSON(w, http.StatusOK, inst)
+}
+
+// GetBarcodeImage godoc
+// @Summary Изображение штрихкода
+// @Description Рисует штрихкод экземпляра в Code128 или DataMatrix, в SVG или PNG
+// @Tags instances
+// @Security BearerAuth
+// @Produce image/svg+xml
+// @Produce image/png
+// @Param barcode path string true "Штрихкод"
+// @Param symbology query string false "code128 (по умолчанию) или datamatrix"
+// @Param format query string false "svg (по умолчанию) или png"
+// @Param module query int false "Размер модуля в пикселях"
+// @Param height query int false "Высота Code128 в модулях"
+// @Success 200 {file} file
+// @Failure 400 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Router /instances/barcode/{barcode}/image [get]
+func (h *Handler) image(w http.ResponseWriter, r *http.Request) {
+	inst, err := h.service.GetByBarcode(chi.URLParam(r, "barcode"))
+	if err != nil {
+		h.respondError(w, err)
+		return
+	}
+
+	q := r.URL.Query()
+	module, _ := strconv.Atoi(q.Get("module"))
+	height, _ := strconv.Atoi(q.Get("height"))
+
+	data, contentType, err := Render(inst.Barcode, RenderOptions{
+		Symbology: q.Get("symbology"),
+		Format:    q.Get("format"),
+		Module:    min(module, 20),
+		Height:    min(height, 200),
+	})
+	if err != nil {
+		h.respondError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", contentType)
+	w.WriteHeader(http.StatusOK)
+	_, _ = w.Write(data)
+}
+
+// CreateInstance godoc
+// @Summary Создать экземпляр продукции
+// @Description Выдает следующий серийный штрихкод для продукта
+// @Tags instances
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param request body CreateRequest true "Продукт"
+// @Success 201 {object} Instance
+// @Failure 400 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Router /instances [post]
+func (h *Handler) create(w http.ResponseWriter, r *http.Request) {
+	var req CreateRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		pkg.RespondJSON(w, http.StatusBadRequest, ErrorResponse{Error: "Некорректный JSON"})
+		return
+	}
+
+	inst, err := h.service.CreateInstance(req.ProductID)
+	if err != nil {
+		h.respondError(w, err)
+		return
+	}
+	pkg.RespondJSON(w, http.StatusCreated, inst)
+}
+
+// CreateInstancesBulk godoc
+// @Summary Создать экземпляры для заказа
+// @Description Выдает count серийных штрихкодов для продукта рабочего заказа
+// @Tags instances
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param request body BulkCreateRequest true "Заказ и количество"
+// @Success 201 {array} Instance
+// @Failure 400 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse
+// @Router /instances/bulk [post]
+func (h *Handler) createBulk(w http.ResponseWriter, r *http.Request) {
+	var req BulkCreateRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		pkg.RespondJSON(w, http.StatusBadRequest, ErrorResponse{Error: "Некорректный JSON"})
+		return
+	}
+
+	instances, err := h.service.CreateForWorkOrder(req.WorkOrderID, req.Count)
+	if err != nil {
+		h.respondError(w, err)
+		return
+	}
+	pkg.RespondJSON(w, http.StatusCreated, instances)
+}
+
+// ShipInstances godoc
+// @Summary Отгрузить экземпляры
+// @Description Отмечает отгрузку экземпляров по штрихкодам: все или ни одного. Экземпляры в блокировке или забракованные при ее снятии не отгружаются (423), списанные в брак — 409
+// @Tags instances
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param request body ShipRequest true "Штрихкоды"
+// @Success 200 {array} Instance
+// @Failure 400 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse
+// @Failure 423 {object} HoldErrorResponse
+// @Router /instances/ship [post]
+func (h *Handler) ship(w http.ResponseWriter, r *http.Request) {
+	var req ShipRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		pkg.RespondJSON(w, http.StatusBadRequest, ErrorResponse{Error: "Некорректный JSON"})
+		return
+	}
+
+	userID, _ := middleware.UserIDFromContext(r.Context())
+	instances, err := h.service.Ship(req.Barcodes, userID)
+	if err != nil {
+		h.respondError(w, err)
+		return
+	}
+	pkg.RespondJSON(w, http.StatusOK, instances)
+}
+
+func (h *Handler) respondError(w http.ResponseWriter, err error) {
+	var holdErr *HoldError
+
+	switch {
+	case errors.As(err, &holdErr):
+		pkg.RespondJSON(w, http.StatusLocked, HoldErrorResponse{
+			Error:    "Экземпляры заблокированы (карантин)",
+			Barcodes: holdErr.Barcodes,
+		})
+	case errors.Is(err, ErrAlreadyShipped):
+		pkg.RespondJSON(w, http.StatusConflict, ErrorResponse{Error: "Экземпляр уже отгружен"})
+	case errors.Is(err, ErrScrapped):
+		pkg.RespondJSON(w, http.StatusConflict, ErrorResponse{Error: "Экземпляр списан в брак"})
+	case errors.Is(err, ErrNotFound):
+		pkg.RespondJSON(w, http.StatusNotFound, ErrorResponse{Error: "Экземпляр не найден"})
+	case errors.Is(err, ErrProductNotFound):
+		pkg.RespondJSON(w, http.StatusNotFound, ErrorResponse{Error: "Продукт не найден"})
+	case errors.Is(err, ErrWorkOrderNotFound):
+		pkg.RespondJSON(w, http.StatusNotFound, ErrorResponse{Error: "Заказ не найден"})
+	case errors.Is(err, ErrInvalidCount):
+		pkg.RespondJSON(w, http.StatusBadRequest, ErrorResponse{Error: "Количество должно быть от 1 до 10000"})
+	case errors.Is(err, ErrQuantityExceeded):
+		pkg.RespondJSON(w, http.StatusConflict, ErrorResponse{Error: "Количество экземпляров превысит количество в заказе"})
+	case errors.Is(err, ErrSeqOverflow):
+		pkg.RespondJSON(w, http.StatusConflict, ErrorResponse{Error: "Исчерпан диапазон серийных номеров шаблона"})
+	case errors.Is(err, ErrUnknownSymbology), errors.Is(err, ErrUnknownFormat):
+		pkg.RespondJSON(w, http.StatusBadRequest, ErrorResponse{Error: "Неподдерживаемый тип или формат штрихкода"})
+	default:
+		slog.Error("instance request failed", slog.Any("err", err))
+		pkg.RespondJSON(w, http.StatusInternalServerError, ErrorResponse{Error: "Внутренняя ошибка сервера"})
+	}
+}
@@ -0,0 +1,36 @@
+package instance
+
+import "time"
+
+// Instance экземпляр продукции со штрихкодом; ShippedAt — отгружен, ScrappedAt — списан в брак.
+// Списание выполняется решением по браку (executions/disposition), поэтому поля списания только читаются.
+type Instance struct {
+	ID            int64      `gorm:"primaryKey;autoIncrement" json:"id"`
+	ProductID     int64      `json:"product_id"`
+	WorkOrderID   *int64     `json:"work_order_id,omitempty"`
+	Barcode       string     `gorm:"unique;not null" json:"barcode"`
+	CreatedAt     time.Time  `gorm:"autoCreateTime" json:"created_at"`
+	ShippedAt     *time.Time `json:"shipped_at,omitempty"`
+	ShippedBy     *int64     `json:"shipped_by,omitempty"`
+	ScrappedAt    *time.Time `gorm:"->" json:"scrapped_at,omitempty"`
+	ScrapReasonID *int       `gorm:"->" json:"scrap_reason_id,omitempty"`
+}
+
+func (Instance) TableName() string {
+	return "product_instances"
+}
+
+// WorkOrderRef данные заказа, нужные для серийной выдачи
+type WorkOrderRef struct {
+	ID        int64
+	ProductID int64
+	Quantity  int
+}
+
+// ListFilter параметры выборки экземпляров
+type ListFilter struct {
+	ProductID   int64
+	WorkOrderID int64
+	Limit       int
+	Offset      int
+}
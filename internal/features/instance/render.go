@@ -0,0 +1,127 @@
+package instance
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"image/png"
+
+	"github.com/boombuler/barcode"
+	"github.com/boombuler/barcode/code128"
+	"github.com/boombuler/barcode/datamatrix"
+)
+
+const (
+	SymbologyCode128    = "code128"
+	SymbologyDataMatrix = "datamatrix"
+
+	FormatSVG = "svg"
+	FormatPNG = "png"
+)
+
+var (
+	ErrUnknownSymbology = errors.New("unknown barcode symbology")
+	ErrUnknownFormat    = errors.New("unknown image format")
+)
+
+// RenderOptions параметры изображения штрихкода; размеры задаются в пикселях на модуль
+type RenderOptions struct {
+	Symbology string
+	Format    string
+	Module    int
+	// Height высота полосы Code128 в модулях; для DataMatrix не используется
+	Height int
+}
+
+// Render рисует штрихкод и возвращает изображение и его Content-Type
+func Render(content string, opts RenderOptions) ([]byte, string, error) {
+	if opts.Module <= 0 {
+		opts.Module = 2
+	}
+	if opts.Height <= 0 {
+		opts.Height = 40
+	}
+
+	var (
+		bc  barcode.Barcode
+		err error
+	)
+	switch opts.Symbology {
+	case SymbologyCode128, "":
+		bc, err = code128.Encode(content)
+	case SymbologyDataMatrix:
+		bc, err = datamatrix.Encode(content)
+	default:
+		return nil, "", ErrUnknownSymbology
+	}
+	if err != nil {
+		return nil, "", err
+	}
+
+	cols := bc.Bounds().Dx()
+	rows := bc.Bounds().Dy()
+	if opts.Symbology != SymbologyDataMatrix {
+		// Code128 одномерный: растягиваем единственную строку на нужную высоту
+		rows = opts.Height
+	}
+
+	switch opts.Format {
+	case FormatSVG, "":
+		return renderSVG(bc, cols, rows, opts.Module), "image/svg+xml", nil
+	case FormatPNG:
+		scaled, err := barcode.Scale(bc, cols*opts.Module, rows*opts.Module)
+		if err != nil {
+			return nil, "", err
+		}
+		var buf bytes.Buffer
+		if err := png.Encode(&buf, scaled); err != nil {
+			return nil, "", err
+		}
+		return buf.Bytes(), "image/png", nil
+	default:
+		return nil, "", ErrUnknownFormat
+	}
+}
+
+// renderSVG строит SVG из прямоугольников, склеивая подряд идущие темные модули строки
+func renderSVG(bc barcode.Barcode, cols, rows, module int) []byte {
+	var buf bytes.Buffer
+	b := bc.Bounds()
+	oneDimensional := b.Dy() == 1
+
+	fmt.Fprintf(&buf, `<svg xmlns="http://www.w3.org/2000/svg" width="%d" height="%d" viewBox="0 0 %d %d" shape-rendering="crispEdges">`,
+		cols*module, rows*module, cols, rows)
+	fmt.Fprintf(&buf, `<rect width="%d" height="%d" fill="#fff"/>`, cols, rows)
+
+	srcRows := rows
+	if oneDimensional {
+		srcRows = 1
+	}
+
+	for y := 0; y < srcRows; y++ {
+		for x := 0; x < cols; {
+			if !isDark(bc, b.Min.X+x, b.Min.Y+y) {
+				x++
+				continue
+			}
+			start := x
+			for x < cols && isDark(bc, b.Min.X+x, b.Min.Y+y) {
+				x++
+			}
+
+			height := 1
+			if oneDimensional {
+				height = rows
+			}
+			fmt.Fprintf(&buf, `<rect x="%d" y="%d" width="%d" height="%d"/>`, start, y, x-start, height)
+		}
+	}
+
+	buf.WriteString(`</svg>`)
+	return buf.Bytes()
+}
+
+func isDark(bc barcode.Barcode, x, y int) bool {
+	r, g, b, _ := bc.At(x, y).RGBA()
+	return r+g+b < 3*0x8000
+}
@@ -0,0 +1,22 @@
+package instance
+
+import "time"
+
+type Repository interface {
+	// AllocateSerials атомарно резервирует n номеров в счетчике scope и возвращает первый из них
+	AllocateSerials(scope string, n int) (int64, error)
+	CreateBatch(instances []*Instance) error
+	// CreateForWorkOrder блокирует заказ и сохраняет экземпляры, сформированные issue по числу уже выпущенных;
+	// проверка количества и выпуск идут в одной транзакции
+	CreateForWorkOrder(workOrderID int64, issue func(wo *WorkOrderRef, existing int64) ([]*Instance, error)) ([]*Instance, error)
+
+	GetByID(id int64) (*Instance, error)
+	GetByBarcode(barcode string) (*Instance, error)
+	List(filter ListFilter) ([]*Instance, error)
+	ListByBarcodes(barcodes []string) ([]*Instance, error)
+	// MarkShipped отмечает отгрузку экземпляров: все или ни одного. ErrAlreadyShipped, ErrScrapped
+	// или *HoldError, если часть уже отгружена, списана или в блокировке — тогда ничего не меняется
+	MarkShipped(ids []int64, at time.Time, userID int64) error
+
+	GetProductSKU(productID int64) (string, error)
+}
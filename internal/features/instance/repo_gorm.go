@@ -0,0 +1,158 @@
+package instance
+
+import (
+	"slices"
+	"time"
+
+	"mes-lite-back/internal/features/hold"
+	"mes-lite-back/internal/numbering"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type GormRepository struct {
+	db *gorm.DB
+}
+
+func NewGormRepository(db *gorm.DB) *GormRepository {
+	return &GormRepository{db: db}
+}
+
+func (r *GormRepository) AllocateSerials(scope string, n int) (int64, error) {
+	return numbering.Allocate(r.db, scope, n)
+}
+
+func (r *GormRepository) CreateBatch(instances []*Instance) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		return tx.CreateInBatches(instances, 500).Error
+	})
+}
+
+func (r *GormRepository) CreateForWorkOrder(workOrderID int64, issue func(wo *WorkOrderRef, existing int64) ([]*Instance, error)) ([]*Instance, error) {
+	var instances []*Instance
+
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		var wo WorkOrderRef
+		if err := tx.Table("work_orders").
+			Clauses(clause.Locking{Strength: "UPDATE"}).
+			Select("id, product_id, quantity").
+			Where("id = ?", workOrderID).
+			Take(&wo).Error; err != nil {
+			return err
+		}
+
+		var existing int64
+		if err := tx.Model(&Instance{}).
+			Where("work_order_id = ?", workOrderID).
+			Count(&existing).Error; err != nil {
+			return err
+		}
+
+		var err error
+		instances, err = issue(&wo, existing)
+		if err != nil {
+			return err
+		}
+		return tx.CreateInBatches(instances, 500).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return instances, nil
+}
+
+func (r *GormRepository) GetByID(id int64) (*Instance, error) {
+	var inst Instance
+	if err := r.db.First(&inst, id).Error; err != nil {
+		return nil, err
+	}
+	return &inst, nil
+}
+
+func (r *GormRepository) GetByBarcode(barcode string) (*Instance, error) {
+	var inst Instance
+	if err := r.db.Where("barcode = ?", barcode).First(&inst).Error; err != nil {
+		return nil, err
+	}
+	return &inst, nil
+}
+
+func (r *GormRepository) List(filter ListFilter) ([]*Instance, error) {
+	var instances []*Instance
+
+	q := r.db.Order("id")
+	if filter.ProductID > 0 {
+		q = q.Where("product_id = ?", filter.ProductID)
+	}
+	if filter.WorkOrderID > 0 {
+		q = q.Where("work_order_id = ?", filter.WorkOrderID)
+	}
+	if filter.Limit > 0 {
+		q = q.Limit(filter.Limit)
+	}
+	if filter.Offset > 0 {
+		q = q.Offset(filter.Offset)
+	}
+
+	return instances, q.Find(&instances).Error
+}
+
+func (r *GormRepository) ListByBarcodes(barcodes []string) ([]*Instance, error) {
+	var instances []*Instance
+	return instances, r.db.Where("barcode IN ?", barcodes).Order("id").Find(&instances).Error
+}
+
+// MarkShipped блокирует экземпляры и под блокировкой повторяет проверки отгрузки, списания и карантина:
+// блокировка или списание, оформленные после проверок сервиса, отгрузку уже не пропустят
+func (r *GormRepository) MarkShipped(ids []int64, at time.Time, userID int64) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		var locked []*Instance
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("id IN ?", ids).
+			Order("id").
+			Find(&locked).Error; err != nil {
+			return err
+		}
+		if len(locked) != len(ids) {
+			return ErrNotFound
+		}
+		for _, inst := range locked {
+			if inst.ShippedAt != nil {
+				return ErrAlreadyShipped
+			}
+			if inst.ScrappedAt != nil {
+				return ErrScrapped
+			}
+		}
+
+		blocked, err := hold.QueryBlocked(tx, ids)
+		if err != nil {
+			return err
+		}
+		if len(blocked) > 0 {
+			holdErr := &HoldError{}
+			for _, inst := range locked {
+				if slices.Contains(blocked, inst.ID) {
+					holdErr.Barcodes = append(holdErr.Barcodes, inst.Barcode)
+				}
+			}
+			return holdErr
+		}
+
+		return tx.Model(&Instance{}).
+			Where("id IN ?", ids).
+			Updates(map[string]any{"shipped_at": at, "shipped_by": userID}).
+			Error
+	})
+}
+
+func (r *GormRepository) GetProductSKU(productID int64) (string, error) {
+	var row struct{ SKU string }
+	err := r.db.Table("products").
+		Select("sku").
+		Where("id = ?", productID).
+		Take(&row).
+		Error
+	return row.SKU, err
+}
@@ -0,0 +1,54 @@
+package instance
+
+import (
+	"time"
+
+	"mes-lite-back/internal/numbering"
+)
+
+// DefaultPattern шаблон штрихкода по умолчанию, например MES-GB01-260105-000042-2
+const DefaultPattern = "{PREFIX}-{SKU}-{YY}{MM}{DD}-{SEQ:6}-{CHECK}"
+
+var (
+	ErrPatternNoSeq = numbering.ErrPatternNoSeq
+	ErrPatternToken = numbering.ErrPatternToken
+	ErrSeqOverflow  = numbering.ErrSeqOverflow
+)
+
+// SerialPattern шаблон штрихкода из токенов:
+// {PREFIX}, {SKU}, {YYYY}, {YY}, {MM}, {DD}, {SEQ:n} — порядковый номер с дополнением нулями до n знаков,
+// {CHECK} — контрольная цифра (Luhn mod 10) по цифрам остальной части кода
+type SerialPattern struct {
+	pattern *numbering.Pattern
+	prefix  string
+}
+
+func NewSerialPattern(pattern, prefix string) (*SerialPattern, error) {
+	if pattern == "" {
+		pattern = DefaultPattern
+	}
+
+	p, err := numbering.Parse(pattern, "PREFIX", "SKU", "YYYY", "YY", "MM", "DD", numbering.TokenCheck)
+	if err != nil {
+		return nil, err
+	}
+	return &SerialPattern{pattern: p, prefix: prefix}, nil
+}
+
+// Scope возвращает ключ счетчика: шаблон со всеми значениями, кроме {SEQ} и {CHECK}.
+// Номера идут подряд внутри одного scope, например по SKU за день.
+func (p *SerialPattern) Scope(sku string, at time.Time) string {
+	return p.pattern.Scope(p.values(sku, at))
+}
+
+// Format собирает штрихкод для порядкового номера seq
+func (p *SerialPattern) Format(sku string, at time.Time, seq int64) (string, error) {
+	return p.pattern.Format(p.values(sku, at), seq)
+}
+
+func (p *SerialPattern) values(sku string, at time.Time) map[string]string {
+	values := numbering.DateValues(at)
+	values["PREFIX"] = p.prefix
+	values["SKU"] = sku
+	return values
+}
@@ -0,0 +1,59 @@
+package instance
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestSerialPattern(t *testing.T) {
+	at := time.Date(2026, 1, 5, 10, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		pattern   string
+		seq       int64
+		want      string
+		wantScope string
+	}{
+		{"", 42, "MES-GB01-260105-000042-2", "MES-GB01-260105-{SEQ}-{CHECK}"},
+		{"{SKU}/{YYYY}/{SEQ:4}", 7, "GB01/2026/0007", "GB01/2026/{SEQ}"},
+		{"{PREFIX}{MM}{DD}{SEQ}", 12345, "MES010512345", "MES0105{SEQ}"},
+	}
+	for _, tt := range tests {
+		p, err := NewSerialPattern(tt.pattern, "MES")
+		if err != nil {
+			t.Fatalf("NewSerialPattern(%q): %v", tt.pattern, err)
+		}
+		if got := p.Scope("GB01", at); got != tt.wantScope {
+			t.Errorf("Scope(%q) = %q, want %q", tt.pattern, got, tt.wantScope)
+		}
+		got, err := p.Format("GB01", at, tt.seq)
+		if err != nil || got != tt.want {
+			t.Errorf("Format(%q, %d) = %q, %v, want %q", tt.pattern, tt.seq, got, err, tt.want)
+		}
+	}
+}
+
+func TestSerialPatternErrors(t *testing.T) {
+	tests := []struct {
+		pattern string
+		wantErr error
+	}{
+		{"{PREFIX}-{SKU}", ErrPatternNoSeq},
+		{"{PREFIX}-{SEQ}-{SEQ}", ErrPatternNoSeq},
+		{"{PREFIX}-{LOT}-{SEQ}", ErrPatternToken},
+	}
+	for _, tt := range tests {
+		if _, err := NewSerialPattern(tt.pattern, "MES"); !errors.Is(err, tt.wantErr) {
+			t.Errorf("NewSerialPattern(%q): err = %v, want %v", tt.pattern, err, tt.wantErr)
+		}
+	}
+
+	p, err := NewSerialPattern("{SKU}-{SEQ:2}", "")
+	if err != nil {
+		t.Fatalf("NewSerialPattern: %v", err)
+	}
+	if _, err := p.Format("GB01", time.Now(), 100); !errors.Is(err, ErrSeqOverflow) {
+		t.Errorf("Format overflow: err = %v, want ErrSeqOverflow", err)
+	}
+}
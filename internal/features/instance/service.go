@@ -0,0 +1,198 @@
+package instance
+
+import (
+	"errors"
+	"slices"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// MaxBatch ограничивает количество экземпляров, создаваемых одним запросом
+const MaxBatch = 10000
+
+var (
+	ErrNotFound          = errors.New("product instance not found")
+	ErrProductNotFound   = errors.New("product not found")
+	ErrWorkOrderNotFound = errors.New("work order not found")
+	ErrInvalidCount      = errors.New("invalid instance count")
+	ErrQuantityExceeded  = errors.New("instances would exceed work order quantity")
+	ErrAlreadyShipped    = errors.New("instance is already shipped")
+	ErrOnHold            = errors.New("instance is on hold")
+	ErrScrapped          = errors.New("instance is scrapped")
+)
+
+// HoldError перечисляет штрихкоды экземпляров, отгрузку которых не пускает блокировка
+type HoldError struct {
+	Barcodes []string
+}
+
+func (e *HoldError) Error() string {
+	return ErrOnHold.Error()
+}
+
+func (e *HoldError) Unwrap() error {
+	return ErrOnHold
+}
+
+// ServiceInterface определяет методы, используемые handler’ом
+type ServiceInterface interface {
+	CreateInstance(productID int64) (*Instance, error)
+	CreateForWorkOrder(workOrderID int64, count int) ([]*Instance, error)
+	GetInstance(id int64) (*Instance, error)
+	GetByBarcode(barcode string) (*Instance, error)
+	ListInstances(filter ListFilter) ([]*Instance, error)
+	Ship(barcodes []string, userID int64) ([]*Instance, error)
+}
+
+type Service struct {
+	repo    Repository
+	pattern *SerialPattern
+	now     func() time.Time
+}
+
+func NewService(repo Repository, pattern *SerialPattern) *Service {
+	return &Service{
+		repo:    repo,
+		pattern: pattern,
+		now:     time.Now,
+	}
+}
+
+func (s *Service) CreateInstance(productID int64) (*Instance, error) {
+	instances, err := s.issue(productID, nil, 1)
+	if err != nil {
+		return nil, err
+	}
+	return instances[0], nil
+}
+
+// CreateForWorkOrder выпускает count экземпляров продукта заказа;
+// суммарно их не может быть больше количества в заказе
+func (s *Service) CreateForWorkOrder(workOrderID int64, count int) ([]*Instance, error) {
+	if count <= 0 || count > MaxBatch {
+		return nil, ErrInvalidCount
+	}
+
+	instances, err := s.repo.CreateForWorkOrder(workOrderID, func(wo *WorkOrderRef, existing int64) ([]*Instance, error) {
+		if existing+int64(count) > int64(wo.Quantity) {
+			return nil, ErrQuantityExceeded
+		}
+		return s.build(wo.ProductID, &wo.ID, count)
+	})
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrWorkOrderNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return instances, nil
+}
+
+func (s *Service) GetInstance(id int64) (*Instance, error) {
+	inst, err := s.repo.GetByID(id)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrNotFound
+	}
+	return inst, err
+}
+
+func (s *Service) GetByBarcode(barcode string) (*Instance, error) {
+	inst, err := s.repo.GetByBarcode(barcode)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrNotFound
+	}
+	return inst, err
+}
+
+func (s *Service) ListInstances(filter ListFilter) ([]*Instance, error) {
+	return s.repo.List(filter)
+}
+
+// Ship отмечает отгрузку экземпляров по штрихкодам: все или ни одного.
+// Экземпляры в блокировке, забракованные при ее снятии или списанные в брак не отгружаются.
+func (s *Service) Ship(barcodes []string, userID int64) ([]*Instance, error) {
+	var unique []string
+	for _, b := range barcodes {
+		b = strings.TrimSpace(b)
+		if b != "" && !slices.Contains(unique, b) {
+			unique = append(unique, b)
+		}
+	}
+	if len(unique) == 0 || len(unique) > MaxBatch {
+		return nil, ErrInvalidCount
+	}
+
+	instances, err := s.repo.ListByBarcodes(unique)
+	if err != nil {
+		return nil, err
+	}
+	if len(instances) != len(unique) {
+		return nil, ErrNotFound
+	}
+
+	ids := make([]int64, 0, len(instances))
+	for _, inst := range instances {
+		if inst.ShippedAt != nil {
+			return nil, ErrAlreadyShipped
+		}
+		if inst.ScrappedAt != nil {
+			return nil, ErrScrapped
+		}
+		ids = append(ids, inst.ID)
+	}
+
+	now := s.now()
+	if err := s.repo.MarkShipped(ids, now, userID); err != nil {
+		return nil, err
+	}
+	for _, inst := range instances {
+		inst.ShippedAt = &now
+		inst.ShippedBy = &userID
+	}
+	return instances, nil
+}
+
+// issue резервирует диапазон номеров и создает экземпляры со штрихкодами по шаблону
+func (s *Service) issue(productID int64, workOrderID *int64, count int) ([]*Instance, error) {
+	instances, err := s.build(productID, workOrderID, count)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.repo.CreateBatch(instances); err != nil {
+		return nil, err
+	}
+	return instances, nil
+}
+
+// build резервирует серийные номера и формирует экземпляры без сохранения
+func (s *Service) build(productID int64, workOrderID *int64, count int) ([]*Instance, error) {
+	sku, err := s.repo.GetProductSKU(productID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrProductNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	now := s.now()
+	first, err := s.repo.AllocateSerials(s.pattern.Scope(sku, now), count)
+	if err != nil {
+		return nil, err
+	}
+
+	instances := make([]*Instance, 0, count)
+	for i := 0; i < count; i++ {
+		code, err := s.pattern.Format(sku, now, first+int64(i))
+		if err != nil {
+			return nil, err
+		}
+		instances = append(instances, &Instance{
+			ProductID:   productID,
+			WorkOrderID: workOrderID,
+			Barcode:     code,
+		})
+	}
+	return instances, nil
+}
@@ -0,0 +1,203 @@
+package machine
+
+import (
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"mes-lite-back/internal/http/middleware"
+	"mes-lite-back/pkg"
+
+	"github.com/go-chi/chi/v5"
+)
+
+type Handler struct {
+	service ServiceInterface
+	perms   middleware.PermissionChecker
+}
+
+func NewHandler(service ServiceInterface, perms middleware.PermissionChecker) *Handler {
+	return &Handler{
+		service: service,
+		perms:   perms,
+	}
+}
+
+func (h *Handler) Routes() chi.Router {
+	r := chi.NewRouter()
+
+	view := middleware.PermissionGuard(h.perms, "machine.view")
+	status := middleware.PermissionGuard(h.perms, "machine.status")
+
+	r.With(view).Get("/statuses", h.statuses)
+	r.With(view).Get("/{id}/status-history", h.history)
+	r.With(view).Get("/{id}/time-summary", h.timeSummary)
+	r.With(status).Post("/{id}/status", h.changeStatus)
+
+	return r
+}
+
+type ErrorResponse struct {
+	Error string `json:"error" example:"Описание ошибки"`
+}
+
+// MachineStatuses godoc
+// @Summary Справочник статусов машин
+// @Tags machines
+// @Security BearerAuth
+// @Produce json
+// @Success 200 {array} Status
+// @Router /machines/statuses [get]
+func (h *Handler) statuses(w http.ResponseWriter, r *http.Request) {
+	statuses, err := h.service.Statuses()
+	if err != nil {
+		h.respondError(w, err)
+		return
+	}
+	pkg.RespondJSON(w, http.StatusOK, statuses)
+}
+
+// ChangeMachineStatus godoc
+// @Summary Изменить статус машины
+// @Description Закрывает текущий период статуса и открывает новый; наладку можно привязать к заказу. Перевод в down, maintenance или offline создает предложение перепланирования слотов машины
+// @Tags machines
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param id path int true "ID машины"
+// @Param request body StatusChange true "Новый статус"
+// @Success 200 {object} Machine
+// @Failure 400 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse
+// @Router /machines/{id}/status [post]
+func (h *Handler) changeStatus(w http.ResponseWriter, r *http.Request) {
+	var req StatusChange
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		pkg.RespondJSON(w, http.StatusBadRequest, ErrorResponse{Error: "Некорректный JSON"})
+		return
+	}
+
+	userID, _ := middleware.UserIDFromContext(r.Context())
+
+	m, err := h.service.ChangeStatus(pkg.ParamID(r), req, userID)
+	if err != nil {
+		h.respondError(w, err)
+		return
+	}
+	pkg.RespondJSON(w, http.StatusOK, m)
+}
+
+// MachineStatusHistory godoc
+// @Summary История статусов машины
+// @Description Периоды статусов, пересекающиеся с интервалом
+// @Tags machines
+// @Security BearerAuth
+// @Produce json
+// @Param id path int true "ID машины"
+// @Param from query string true "Начало периода (RFC3339 или ГГГГ-ММ-ДД)"
+// @Param to query string true "Конец периода (RFC3339 или ГГГГ-ММ-ДД включительно)"
+// @Success 200 {array} StatusHistory
+// @Failure 400 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Router /machines/{id}/status-history [get]
+func (h *Handler) history(w http.ResponseWriter, r *http.Request) {
+	from, to, ok := parseRange(w, r)
+	if !ok {
+		return
+	}
+
+	history, err := h.service.History(pkg.ParamID(r), from, to)
+	if err != nil {
+		h.respondError(w, err)
+		return
+	}
+	pkg.RespondJSON(w, http.StatusOK, history)
+}
+
+// MachineTimeSummary godoc
+// @Summary Время машины по статусам
+// @Description Минуты выпуска, наладки, простоя и недоступности за период; наладка считается отдельно от выпуска
+// @Tags machines
+// @Security BearerAuth
+// @Produce json
+// @Param id path int true "ID машины"
+// @Param from query string true "Начало периода (RFC3339 или ГГГГ-ММ-ДД)"
+// @Param to query string true "Конец периода (RFC3339 или ГГГГ-ММ-ДД включительно)"
+// @Success 200 {object} TimeSummary
+// @Failure 400 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Router /machines/{id}/time-summary [get]
+func (h *Handler) timeSummary(w http.ResponseWriter, r *http.Request) {
+	from, to, ok := parseRange(w, r)
+	if !ok {
+		return
+	}
+
+	sum, err := h.service.TimeSummary(pkg.ParamID(r), from, to)
+	if err != nil {
+		h.respondError(w, err)
+		return
+	}
+	pkg.RespondJSON(w, http.StatusOK, sum)
+}
+
+// parseRange разбирает обязательный период from/to в RFC3339 или ГГГГ-ММ-ДД;
+// дата в конце периода включает весь день
+func parseRange(w http.ResponseWriter, r *http.Request) (time.Time, time.Time, bool) {
+	query := r.URL.Query()
+	if query.Get("from") == "" || query.Get("to") == "" {
+		pkg.RespondJSON(w, http.StatusBadRequest, ErrorResponse{Error: "Укажите период from и to"})
+		return time.Time{}, time.Time{}, false
+	}
+
+	from, err := parseTime(query.Get("from"), false)
+	if err != nil {
+		pkg.RespondJSON(w, http.StatusBadRequest, ErrorResponse{Error: "Время должно быть в формате RFC3339 или ГГГГ-ММ-ДД"})
+		return time.Time{}, time.Time{}, false
+	}
+	to, err := parseTime(query.Get("to"), true)
+	if err != nil {
+		pkg.RespondJSON(w, http.StatusBadRequest, ErrorResponse{Error: "Время должно быть в формате RFC3339 или ГГГГ-ММ-ДД"})
+		return time.Time{}, time.Time{}, false
+	}
+	return from, to, true
+}
+
+func parseTime(value string, end bool) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+	t, err := time.ParseInLocation(time.DateOnly, value, time.Local)
+	if err != nil {
+		return time.Time{}, err
+	}
+	if end {
+		t = t.AddDate(0, 0, 1)
+	}
+	return t, nil
+}
+
+func (h *Handler) respondError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, ErrNotFound):
+		pkg.RespondJSON(w, http.StatusNotFound, ErrorResponse{Error: "Машина не найдена"})
+	case errors.Is(err, ErrStatusNotFound):
+		pkg.RespondJSON(w, http.StatusBadRequest, ErrorResponse{Error: "Неизвестный статус машины"})
+	case errors.Is(err, ErrWorkOrderNotFound):
+		pkg.RespondJSON(w, http.StatusNotFound, ErrorResponse{Error: "Заказ не найден"})
+	case errors.Is(err, ErrInvalidExpected):
+		pkg.RespondJSON(w, http.StatusBadRequest, ErrorResponse{Error: "Прогноз окончания статуса должен быть в будущем"})
+	case errors.Is(err, ErrSameStatus):
+		pkg.RespondJSON(w, http.StatusConflict, ErrorResponse{Error: "Машина уже в этом статусе"})
+	case errors.Is(err, ErrInvalidPeriod):
+		pkg.RespondJSON(w, http.StatusBadRequest, ErrorResponse{Error: "Конец периода должен быть позже начала"})
+	case errors.Is(err, ErrRangeTooLong):
+		pkg.RespondJSON(w, http.StatusBadRequest, ErrorResponse{Error: "Период не должен превышать 400 дней"})
+	default:
+		slog.Error("machine request failed", slog.Any("err", err))
+		pkg.RespondJSON(w, http.StatusInternalServerError, ErrorResponse{Error: "Внутренняя ошибка сервера"})
+	}
+}
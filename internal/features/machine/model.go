@@ -0,0 +1,87 @@
+package machine
+
+import (
+	"slices"
+	"time"
+)
+
+// Статусы машин (значения из справочника machine_statuses)
+const (
+	StatusIdle        = 1
+	StatusRunning     = 2
+	StatusSetup       = 3
+	StatusDown        = 4
+	StatusMaintenance = 5
+	StatusOffline     = 6
+)
+
+// Unavailable статусы, в которых машина недоступна для планирования
+var Unavailable = []int{StatusDown, StatusMaintenance, StatusOffline}
+
+// IsUnavailable недоступна ли машина в статусе statusID
+func IsUnavailable(statusID int) bool {
+	return slices.Contains(Unavailable, statusID)
+}
+
+// Machine машина (оборудование) линии
+type Machine struct {
+	ID       int64  `gorm:"primaryKey;autoIncrement" json:"id"`
+	Code     string `json:"code"`
+	Name     string `json:"name"`
+	LineID   *int64 `json:"line_id,omitempty"`
+	StatusID *int   `json:"status_id,omitempty"`
+}
+
+func (Machine) TableName() string {
+	return "machines"
+}
+
+// Status статус машины
+type Status struct {
+	ID   int    `gorm:"primaryKey" json:"id"`
+	Code string `json:"code"`
+	Name string `json:"name"`
+}
+
+func (Status) TableName() string {
+	return "machine_statuses"
+}
+
+// StatusHistory период пребывания машины в статусе; EndedAt = nil — текущий статус.
+// ExpectedUntil — прогноз окончания периода (например, простоя).
+type StatusHistory struct {
+	ID            int64      `gorm:"primaryKey;autoIncrement" json:"id"`
+	MachineID     int64      `json:"machine_id"`
+	StatusID      int        `json:"status_id"`
+	StartedAt     time.Time  `json:"started_at"`
+	EndedAt       *time.Time `json:"ended_at,omitempty"`
+	ExpectedUntil *time.Time `json:"expected_until,omitempty"`
+	WorkOrderID   *int64     `json:"work_order_id,omitempty"`
+	Comment       string     `json:"comment,omitempty"`
+	ChangedBy     *int64     `json:"changed_by,omitempty"`
+}
+
+func (StatusHistory) TableName() string {
+	return "machine_status_history"
+}
+
+// StatusTime время в статусе за период
+type StatusTime struct {
+	StatusID int     `json:"status_id"`
+	Code     string  `json:"code"`
+	Name     string  `json:"name"`
+	Minutes  float64 `json:"minutes"`
+}
+
+// TimeSummary распределение времени машины по статусам; наладка учитывается отдельно от выпуска
+type TimeSummary struct {
+	MachineID  int64         `json:"machine_id"`
+	From       time.Time     `json:"from"`
+	To         time.Time     `json:"to"`
+	RunMin     float64       `json:"run_min"`
+	SetupMin   float64       `json:"setup_min"`
+	DownMin    float64       `json:"down_min"`
+	IdleMin    float64       `json:"idle_min"`
+	UnknownMin float64       `json:"unknown_min"`
+	ByStatus   []*StatusTime `json:"by_status"`
+}
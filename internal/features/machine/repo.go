@@ -0,0 +1,16 @@
+package machine
+
+import "time"
+
+type Repository interface {
+	Get(id int64) (*Machine, error)
+	Statuses() ([]*Status, error)
+
+	// ChangeStatus закрывает текущий период статуса, открывает новый и обновляет машину под блокировкой
+	ChangeStatus(machineID int64, change func(m *Machine) (*StatusHistory, error)) (*Machine, error)
+	// ListHistory периоды статусов, пересекающиеся с [from, to)
+	ListHistory(machineID int64, from, to time.Time) ([]*StatusHistory, error)
+
+	StatusExists(id int) (bool, error)
+	WorkOrderExists(id int64) (bool, error)
+}
@@ -0,0 +1,83 @@
+package machine
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type GormRepository struct {
+	db *gorm.DB
+}
+
+func NewGormRepository(db *gorm.DB) *GormRepository {
+	return &GormRepository{db: db}
+}
+
+func (r *GormRepository) Get(id int64) (*Machine, error) {
+	var m Machine
+	if err := r.db.First(&m, id).Error; err != nil {
+		return nil, err
+	}
+	return &m, nil
+}
+
+func (r *GormRepository) Statuses() ([]*Status, error) {
+	var statuses []*Status
+	return statuses, r.db.Order("id").Find(&statuses).Error
+}
+
+func (r *GormRepository) ChangeStatus(machineID int64, change func(m *Machine) (*StatusHistory, error)) (*Machine, error) {
+	var m Machine
+
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&m, machineID).Error; err != nil {
+			return err
+		}
+
+		h, err := change(&m)
+		if err != nil {
+			return err
+		}
+
+		err = tx.Model(&StatusHistory{}).
+			Where("machine_id = ? AND ended_at IS NULL", machineID).
+			Update("ended_at", h.StartedAt).
+			Error
+		if err != nil {
+			return err
+		}
+		if err := tx.Create(h).Error; err != nil {
+			return err
+		}
+
+		return tx.Model(&m).Update("status_id", m.StatusID).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &m, nil
+}
+
+func (r *GormRepository) ListHistory(machineID int64, from, to time.Time) ([]*StatusHistory, error) {
+	var history []*StatusHistory
+	err := r.db.
+		Where("machine_id = ? AND started_at < ? AND (ended_at IS NULL OR ended_at > ?)", machineID, to, from).
+		Order("started_at").
+		Find(&history).
+		Error
+	return history, err
+}
+
+func (r *GormRepository) StatusExists(id int) (bool, error) {
+	var n int64
+	err := r.db.Model(&Status{}).Where("id = ?", id).Count(&n).Error
+	return n > 0, err
+}
+
+func (r *GormRepository) WorkOrderExists(id int64) (bool, error) {
+	var n int64
+	err := r.db.Table("work_orders").Where("id = ?", id).Count(&n).Error
+	return n > 0, err
+}
@@ -0,0 +1,218 @@
+package machine
+
+import (
+	"errors"
+	"math"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// MaxSummaryRange наибольший период сводки по статусам
+const MaxSummaryRange = 400 * 24 * time.Hour
+
+var (
+	ErrNotFound          = errors.New("machine not found")
+	ErrStatusNotFound    = errors.New("machine status not found")
+	ErrWorkOrderNotFound = errors.New("work order not found")
+	ErrSameStatus        = errors.New("machine already has this status")
+	ErrInvalidExpected   = errors.New("expected end of status must be in the future")
+	ErrInvalidPeriod     = errors.New("period end must be after start")
+	ErrRangeTooLong      = errors.New("range is too long")
+)
+
+// StatusChange смена статуса машины
+type StatusChange struct {
+	StatusID      int        `json:"status_id" example:"3"`
+	WorkOrderID   *int64     `json:"work_order_id,omitempty"`
+	ExpectedUntil *time.Time `json:"expected_until,omitempty" example:"2026-11-02T14:00:00Z"`
+	Comment       string     `json:"comment,omitempty" example:"Переналадка под заказ WO-2026-000124"`
+}
+
+// ServiceInterface определяет методы, используемые handler’ом
+type ServiceInterface interface {
+	Statuses() ([]*Status, error)
+	ChangeStatus(machineID int64, change StatusChange, userID int64) (*Machine, error)
+	History(machineID int64, from, to time.Time) ([]*StatusHistory, error)
+	TimeSummary(machineID int64, from, to time.Time) (*TimeSummary, error)
+}
+
+// StatusListener получает смены статусов машин после их сохранения; prev — прежний статус
+type StatusListener interface {
+	MachineStatusChanged(m *Machine, prev *int, h *StatusHistory)
+}
+
+type Service struct {
+	repo     Repository
+	listener StatusListener
+	now      func() time.Time
+}
+
+func NewService(repo Repository, listener StatusListener) *Service {
+	return &Service{
+		repo:     repo,
+		listener: listener,
+		now:      time.Now,
+	}
+}
+
+func (s *Service) Statuses() ([]*Status, error) {
+	return s.repo.Statuses()
+}
+
+// ChangeStatus переводит машину в новый статус и открывает период в истории статусов.
+// Смена передается слушателю, например для перепланирования при остановке машины.
+func (s *Service) ChangeStatus(machineID int64, change StatusChange, userID int64) (*Machine, error) {
+	now := s.now()
+	if change.ExpectedUntil != nil && !change.ExpectedUntil.After(now) {
+		return nil, ErrInvalidExpected
+	}
+
+	ok, err := s.repo.StatusExists(change.StatusID)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, ErrStatusNotFound
+	}
+	if change.WorkOrderID != nil {
+		ok, err := s.repo.WorkOrderExists(*change.WorkOrderID)
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			return nil, ErrWorkOrderNotFound
+		}
+	}
+
+	var (
+		prev *int
+		h    *StatusHistory
+	)
+	m, err := s.repo.ChangeStatus(machineID, func(m *Machine) (*StatusHistory, error) {
+		if m.StatusID != nil && *m.StatusID == change.StatusID {
+			return nil, ErrSameStatus
+		}
+		prev = m.StatusID
+		m.StatusID = &change.StatusID
+
+		h = &StatusHistory{
+			MachineID:     m.ID,
+			StatusID:      change.StatusID,
+			StartedAt:     now,
+			ExpectedUntil: change.ExpectedUntil,
+			WorkOrderID:   change.WorkOrderID,
+			Comment:       strings.TrimSpace(change.Comment),
+			ChangedBy:     &userID,
+		}
+		return h, nil
+	})
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if s.listener != nil {
+		s.listener.MachineStatusChanged(m, prev, h)
+	}
+	return m, nil
+}
+
+func (s *Service) History(machineID int64, from, to time.Time) ([]*StatusHistory, error) {
+	if err := s.checkRange(machineID, from, to); err != nil {
+		return nil, err
+	}
+	return s.repo.ListHistory(machineID, from, to)
+}
+
+// TimeSummary время машины по статусам за [from, to); текущий период считается до настоящего момента.
+// Время без записей истории попадает в UnknownMin.
+func (s *Service) TimeSummary(machineID int64, from, to time.Time) (*TimeSummary, error) {
+	if err := s.checkRange(machineID, from, to); err != nil {
+		return nil, err
+	}
+
+	history, err := s.repo.ListHistory(machineID, from, to)
+	if err != nil {
+		return nil, err
+	}
+	statuses, err := s.repo.Statuses()
+	if err != nil {
+		return nil, err
+	}
+
+	now := s.now()
+	minutes := make(map[int]float64)
+	var covered float64
+
+	for _, h := range history {
+		start, end := h.StartedAt, now
+		if h.EndedAt != nil {
+			end = *h.EndedAt
+		}
+		if start.Before(from) {
+			start = from
+		}
+		if end.After(to) {
+			end = to
+		}
+		if end.After(start) {
+			d := end.Sub(start).Minutes()
+			minutes[h.StatusID] += d
+			covered += d
+		}
+	}
+
+	sum := &TimeSummary{
+		MachineID: machineID,
+		From:      from,
+		To:        to,
+		RunMin:    round1(minutes[StatusRunning]),
+		SetupMin:  round1(minutes[StatusSetup]),
+		IdleMin:   round1(minutes[StatusIdle]),
+		ByStatus:  make([]*StatusTime, 0, len(statuses)),
+	}
+	for _, id := range Unavailable {
+		sum.DownMin += minutes[id]
+	}
+	sum.DownMin = round1(sum.DownMin)
+
+	// будущее время не относится ни к одному статусу
+	end := to
+	if end.After(now) {
+		end = now
+	}
+	if end.After(from) {
+		sum.UnknownMin = round1(max(0, end.Sub(from).Minutes()-covered))
+	}
+
+	for _, st := range statuses {
+		if m, ok := minutes[st.ID]; ok {
+			sum.ByStatus = append(sum.ByStatus, &StatusTime{StatusID: st.ID, Code: st.Code, Name: st.Name, Minutes: round1(m)})
+		}
+	}
+
+	return sum, nil
+}
+
+func (s *Service) checkRange(machineID int64, from, to time.Time) error {
+	if !to.After(from) {
+		return ErrInvalidPeriod
+	}
+	if to.Sub(from) > MaxSummaryRange {
+		return ErrRangeTooLong
+	}
+
+	_, err := s.repo.Get(machineID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return ErrNotFound
+	}
+	return err
+}
+
+func round1(v float64) float64 {
+	return math.Round(v*10) / 10
+}
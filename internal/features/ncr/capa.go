@@ -0,0 +1,243 @@
+package ncr
+
+import (
+	"fmt"
+	"log/slog"
+	"slices"
+	"strings"
+
+	"mes-lite-back/internal/features/notification"
+)
+
+// Verification итог проверки эффективности выполненных действий
+type Verification struct {
+	Effective bool   `json:"effective" example:"true"`
+	Comment   string `json:"comment,omitempty" example:"За 30 дней трещин шва не выявлено"`
+}
+
+// Submit отправляет план CAPA на утверждение: нужны коренная причина и хотя бы одно корректирующее действие
+func (s *Service) Submit(id int64, comment string, userID int64) (*NCR, error) {
+	return s.update(id, func(n *NCR) (*Entry, error) {
+		if n.Status != StatusOpen {
+			return nil, ErrStatus
+		}
+		if n.RootCause == "" {
+			return nil, ErrRootCauseRequired
+		}
+		if !slices.ContainsFunc(n.Actions, func(a *Action) bool { return a.Kind == KindCorrective && a.Status == ActionOpen }) {
+			return nil, ErrNoActions
+		}
+
+		now := s.now()
+		n.Status = StatusPendingApproval
+		n.SubmittedBy = &userID
+		n.SubmittedAt = &now
+
+		return &Entry{Event: &Event{Action: EventSubmitted, UserID: &userID, Comment: strings.TrimSpace(comment)}}, nil
+	})
+}
+
+// Approve утверждает план CAPA; утверждающий не может быть автором плана.
+// Ответственные за действия получают уведомление.
+func (s *Service) Approve(id int64, comment string, userID int64) (*NCR, error) {
+	comment = strings.TrimSpace(comment)
+
+	n, err := s.update(id, func(n *NCR) (*Entry, error) {
+		if n.Status != StatusPendingApproval {
+			return nil, ErrStatus
+		}
+		if n.SubmittedBy != nil && *n.SubmittedBy == userID {
+			return nil, ErrSelfApproval
+		}
+		n.Status = StatusInProgress
+
+		return &Entry{
+			Event:    &Event{Action: EventApproved, UserID: &userID, Comment: comment},
+			Approval: &Approval{Step: StepPlan, Decision: DecisionApproved, UserID: userID, Comment: comment},
+		}, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var owners []int64
+	for _, a := range n.Actions {
+		if a.Status == ActionOpen && !slices.Contains(owners, a.OwnerID) {
+			owners = append(owners, a.OwnerID)
+		}
+	}
+	s.notify(n, owners, fmt.Sprintf("%s: план CAPA утвержден, назначены действия", n.Number), userID)
+	return n, nil
+}
+
+// Reject возвращает план CAPA на доработку с обязательным комментарием; автор плана получает уведомление
+func (s *Service) Reject(id int64, comment string, userID int64) (*NCR, error) {
+	comment = strings.TrimSpace(comment)
+	if comment == "" {
+		return nil, ErrCommentRequired
+	}
+
+	var submitter *int64
+	n, err := s.update(id, func(n *NCR) (*Entry, error) {
+		if n.Status != StatusPendingApproval {
+			return nil, ErrStatus
+		}
+		if n.SubmittedBy != nil && *n.SubmittedBy == userID {
+			return nil, ErrSelfApproval
+		}
+		submitter = n.SubmittedBy
+		n.Status = StatusOpen
+		n.SubmittedBy = nil
+		n.SubmittedAt = nil
+
+		return &Entry{
+			Event:    &Event{Action: EventRejected, UserID: &userID, Comment: comment},
+			Approval: &Approval{Step: StepPlan, Decision: DecisionRejected, UserID: userID, Comment: comment},
+		}, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if submitter != nil {
+		s.notify(n, []int64{*submitter}, fmt.Sprintf("%s: план CAPA отклонен", n.Number), userID)
+	}
+	return n, nil
+}
+
+// CompleteAction отмечает действие выполненным с результатом. Выполнить действие может ответственный
+// или пользователь с правом ncr.create. Когда выполнены все действия, отчет переходит к проверке эффективности.
+func (s *Service) CompleteAction(id, actionID int64, result string, userID int64) (*NCR, error) {
+	result = strings.TrimSpace(result)
+	if result == "" {
+		return nil, ErrResultRequired
+	}
+
+	canManage, err := s.perms.HasPermission(userID, "ncr.create")
+	if err != nil {
+		return nil, err
+	}
+
+	return s.update(id, func(n *NCR) (*Entry, error) {
+		a := findAction(n, actionID)
+		if a == nil {
+			return nil, ErrActionNotFound
+		}
+		if n.Status != StatusInProgress {
+			return nil, ErrStatus
+		}
+		if a.Status == ActionDone {
+			return nil, ErrActionDone
+		}
+		if a.OwnerID != userID && !canManage {
+			return nil, ErrNotOwner
+		}
+
+		now := s.now()
+		a.Status = ActionDone
+		a.Result = result
+		a.CompletedBy = &userID
+		a.CompletedAt = &now
+
+		if !slices.ContainsFunc(n.Actions, func(a *Action) bool { return a.Status == ActionOpen }) {
+			n.Status = StatusVerification
+		}
+		return &Entry{Event: &Event{Action: EventActionCompleted, UserID: &userID, Comment: a.Description + ": " + result}}, nil
+	})
+}
+
+// Verify фиксирует проверку эффективности. Неэффективные действия возвращают отчет в open:
+// причина пересматривается и план дополняется новыми действиями, которые снова утверждаются.
+func (s *Service) Verify(id int64, v Verification, userID int64) (*NCR, error) {
+	comment := strings.TrimSpace(v.Comment)
+	if !v.Effective && comment == "" {
+		return nil, ErrCommentRequired
+	}
+
+	return s.update(id, func(n *NCR) (*Entry, error) {
+		if n.Status != StatusVerification {
+			return nil, ErrStatus
+		}
+
+		now := s.now()
+		n.Effective = &v.Effective
+		n.EffectivenessComment = comment
+		n.VerifiedBy = &userID
+		n.VerifiedAt = &now
+
+		action := EventVerified
+		n.Status = StatusVerified
+		if !v.Effective {
+			action = EventIneffective
+			n.Status = StatusOpen
+			n.SubmittedBy = nil
+			n.SubmittedAt = nil
+		}
+		return &Entry{Event: &Event{Action: action, UserID: &userID, Comment: comment}}, nil
+	})
+}
+
+// Close закрывает отчет после подтвержденной эффективности действий
+func (s *Service) Close(id int64, comment string, userID int64) (*NCR, error) {
+	comment = strings.TrimSpace(comment)
+
+	return s.update(id, func(n *NCR) (*Entry, error) {
+		if n.Status != StatusVerified {
+			return nil, ErrStatus
+		}
+
+		now := s.now()
+		n.Status = StatusClosed
+		n.ClosureComment = comment
+		n.ClosedBy = &userID
+		n.ClosedAt = &now
+
+		return &Entry{
+			Event:    &Event{Action: EventClosed, UserID: &userID, Comment: comment},
+			Approval: &Approval{Step: StepClosure, Decision: DecisionApproved, UserID: userID, Comment: comment},
+		}, nil
+	})
+}
+
+// Cancel отменяет отчет, по которому план CAPA еще не утвержден (например, несоответствие не подтвердилось)
+func (s *Service) Cancel(id int64, comment string, userID int64) (*NCR, error) {
+	comment = strings.TrimSpace(comment)
+	if comment == "" {
+		return nil, ErrCommentRequired
+	}
+
+	return s.update(id, func(n *NCR) (*Entry, error) {
+		if n.Status != StatusOpen && n.Status != StatusPendingApproval {
+			return nil, ErrStatus
+		}
+
+		now := s.now()
+		n.Status = StatusCancelled
+		n.ClosureComment = comment
+		n.ClosedBy = &userID
+		n.ClosedAt = &now
+
+		return &Entry{Event: &Event{Action: EventCancelled, UserID: &userID, Comment: comment}}, nil
+	})
+}
+
+// notify уведомляет пользователей об изменении отчета; автор изменения не уведомляется.
+// Ошибка отправки не отменяет изменение и только логируется.
+func (s *Service) notify(n *NCR, userIDs []int64, title string, userID int64) {
+	recipients := slices.DeleteFunc(slices.Clone(userIDs), func(id int64) bool { return id == userID })
+	if s.notifier == nil || len(recipients) == 0 {
+		return
+	}
+
+	err := s.notifier.Notify(recipients, notification.Message{
+		Kind:       notification.KindCAPA,
+		Title:      title,
+		Body:       n.Title,
+		EntityType: "ncr",
+		EntityID:   n.ID,
+		CreatedBy:  &userID,
+	})
+	if err != nil {
+		slog.Error("ncr notification failed", slog.Int64("ncr_id", n.ID), slog.Any("err", err))
+	}
+}
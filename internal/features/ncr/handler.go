@@ -0,0 +1,601 @@
+package ncr
+
+import (
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strconv"
+
+	"mes-lite-back/internal/http/middleware"
+	"mes-lite-back/pkg"
+
+	"github.com/go-chi/chi/v5"
+)
+
+type Handler struct {
+	service ServiceInterface
+	perms   middleware.PermissionChecker
+}
+
+func NewHandler(service ServiceInterface, perms middleware.PermissionChecker) *Handler {
+	return &Handler{
+		service: service,
+		perms:   perms,
+	}
+}
+
+func (h *Handler) Routes() chi.Router {
+	r := chi.NewRouter()
+
+	view := middleware.PermissionGuard(h.perms, "ncr.view")
+	create := middleware.PermissionGuard(h.perms, "ncr.create")
+	approve := middleware.PermissionGuard(h.perms, "ncr.approve")
+	closeNCR := middleware.PermissionGuard(h.perms, "ncr.close")
+
+	r.With(view).Get("/", h.list)
+	r.With(view).Get("/actions", h.actions)
+	r.With(view).Get("/{id}", h.getByID)
+	r.With(view).Get("/{id}/events", h.events)
+	r.With(create).Post("/", h.create)
+	r.With(create).Put("/{id}", h.update)
+	r.With(create).Post("/{id}/links", h.link)
+	r.With(create).Put("/{id}/root-cause", h.rootCause)
+	r.With(create).Post("/{id}/actions", h.addAction)
+	r.With(create).Put("/{id}/actions/{actionID}", h.updateAction)
+	r.With(create).Delete("/{id}/actions/{actionID}", h.removeAction)
+	// ответственный выполняет свое действие без права ncr.create; проверяется в сервисе
+	r.With(view).Post("/{id}/actions/{actionID}/complete", h.completeAction)
+	r.With(create).Post("/{id}/submit", h.submit)
+	r.With(approve).Post("/{id}/approve", h.approve)
+	r.With(approve).Post("/{id}/reject", h.reject)
+	r.With(closeNCR).Post("/{id}/verify", h.verify)
+	r.With(closeNCR).Post("/{id}/close", h.closeNCR)
+	r.With(closeNCR).Post("/{id}/cancel", h.cancel)
+
+	return r
+}
+
+type ErrorResponse struct {
+	Error string `json:"error" example:"Описание ошибки"`
+}
+
+type RootCauseRequest struct {
+	RootCause string `json:"root_cause" example:"Износ токоподводящего наконечника, замена не предусмотрена регламентом"`
+}
+
+type CommentRequest struct {
+	Comment string `json:"comment,omitempty" example:"План согласован с технологом"`
+}
+
+type CompleteRequest struct {
+	Result string `json:"result" example:"Наконечники заменены на 6 постах, регламент обновлен"`
+}
+
+// ListNCRs godoc
+// @Summary Получить отчеты о несоответствии
+// @Description Новые первыми; фильтры по статусу, штрихкоду затронутого экземпляра, заказу, инциденту и коду дефекта
+// @Tags ncr
+// @Security BearerAuth
+// @Produce json
+// @Param status query string false "Статус: open, pending_approval, in_progress, verification, verified, closed, cancelled"
+// @Param barcode query string false "Штрихкод экземпляра"
+// @Param work_order_id query int false "ID заказа"
+// @Param incident_id query int false "ID инцидента"
+// @Param defect_code_id query int false "ID кода дефекта"
+// @Param limit query int false "Размер страницы (не более 200)"
+// @Param offset query int false "Смещение"
+// @Success 200 {array} NCR
+// @Failure 400 {object} ErrorResponse
+// @Router /ncr [get]
+func (h *Handler) list(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	workOrderID, _ := strconv.ParseInt(query.Get("work_order_id"), 10, 64)
+	incidentID, _ := strconv.ParseInt(query.Get("incident_id"), 10, 64)
+	defectCodeID, _ := strconv.Atoi(query.Get("defect_code_id"))
+	limit, _ := strconv.Atoi(query.Get("limit"))
+	offset, _ := strconv.Atoi(query.Get("offset"))
+
+	ncrs, err := h.service.List(ListFilter{
+		Status:       query.Get("status"),
+		Barcode:      query.Get("barcode"),
+		WorkOrderID:  workOrderID,
+		IncidentID:   incidentID,
+		DefectCodeID: defectCodeID,
+		Limit:        limit,
+		Offset:       offset,
+	})
+	if err != nil {
+		h.respondError(w, err)
+		return
+	}
+	pkg.RespondJSON(w, http.StatusOK, ncrs)
+}
+
+// ListCAPAActions godoc
+// @Summary Действия CAPA
+// @Description Действия незакрытых отчетов в порядке срока; overdue=true — невыполненные с прошедшим сроком
+// @Tags ncr
+// @Security BearerAuth
+// @Produce json
+// @Param owner_id query int false "ID ответственного"
+// @Param status query string false "Статус: open, done"
+// @Param overdue query bool false "Только просроченные"
+// @Param limit query int false "Размер страницы (не более 200)"
+// @Param offset query int false "Смещение"
+// @Success 200 {array} Action
+// @Failure 400 {object} ErrorResponse
+// @Router /ncr/actions [get]
+func (h *Handler) actions(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	ownerID, _ := strconv.ParseInt(query.Get("owner_id"), 10, 64)
+	overdue, _ := strconv.ParseBool(query.Get("overdue"))
+	limit, _ := strconv.Atoi(query.Get("limit"))
+	offset, _ := strconv.Atoi(query.Get("offset"))
+
+	actions, err := h.service.Actions(ActionFilter{
+		OwnerID: ownerID,
+		Status:  query.Get("status"),
+		Overdue: overdue,
+		Limit:   limit,
+		Offset:  offset,
+	})
+	if err != nil {
+		h.respondError(w, err)
+		return
+	}
+	pkg.RespondJSON(w, http.StatusOK, actions)
+}
+
+// GetNCR godoc
+// @Summary Получить отчет о несоответствии по ID
+// @Description Отчет со связями, планом CAPA и решениями по утверждению
+// @Tags ncr
+// @Security BearerAuth
+// @Produce json
+// @Param id path int true "ID отчета"
+// @Success 200 {object} NCR
+// @Failure 404 {object} ErrorResponse
+// @Router /ncr/{id} [get]
+func (h *Handler) getByID(w http.ResponseWriter, r *http.Request) {
+	n, err := h.service.Get(pkg.ParamID(r))
+	if err != nil {
+		h.respondError(w, err)
+		return
+	}
+	pkg.RespondJSON(w, http.StatusOK, n)
+}
+
+// NCREvents godoc
+// @Summary Журнал отчета о несоответствии
+// @Tags ncr
+// @Security BearerAuth
+// @Produce json
+// @Param id path int true "ID отчета"
+// @Success 200 {array} Event
+// @Failure 404 {object} ErrorResponse
+// @Router /ncr/{id}/events [get]
+func (h *Handler) events(w http.ResponseWriter, r *http.Request) {
+	events, err := h.service.Events(pkg.ParamID(r))
+	if err != nil {
+		h.respondError(w, err)
+		return
+	}
+	pkg.RespondJSON(w, http.StatusOK, events)
+}
+
+// CreateNCR godoc
+// @Summary Зарегистрировать отчет о несоответствии
+// @Description Номер выдается автоматически (NCR-<год>-<номер>); затронутые экземпляры указываются штрихкодами
+// @Tags ncr
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param request body Report true "Отчет"
+// @Success 201 {object} NCR
+// @Failure 400 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Router /ncr [post]
+func (h *Handler) create(w http.ResponseWriter, r *http.Request) {
+	var req Report
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		pkg.RespondJSON(w, http.StatusBadRequest, ErrorResponse{Error: "Некорректный JSON"})
+		return
+	}
+
+	n, err := h.service.Create(req, currentUser(r))
+	if err != nil {
+		h.respondError(w, err)
+		return
+	}
+	pkg.RespondJSON(w, http.StatusCreated, n)
+}
+
+// UpdateNCR godoc
+// @Summary Изменить отчет о несоответствии
+// @Description Описание меняется, пока план CAPA не отправлен на утверждение
+// @Tags ncr
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param id path int true "ID отчета"
+// @Param request body Edit true "Описание"
+// @Success 200 {object} NCR
+// @Failure 400 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse
+// @Router /ncr/{id} [put]
+func (h *Handler) update(w http.ResponseWriter, r *http.Request) {
+	var req Edit
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		pkg.RespondJSON(w, http.StatusBadRequest, ErrorResponse{Error: "Некорректный JSON"})
+		return
+	}
+
+	n, err := h.service.Update(pkg.ParamID(r), req, currentUser(r))
+	if err != nil {
+		h.respondError(w, err)
+		return
+	}
+	pkg.RespondJSON(w, http.StatusOK, n)
+}
+
+// LinkNCR godoc
+// @Summary Добавить связи отчета
+// @Description Добавляет затронутые экземпляры, заказы и инциденты к незакрытому отчету; существующие связи не дублируются
+// @Tags ncr
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param id path int true "ID отчета"
+// @Param request body LinkRequest true "Связи"
+// @Success 200 {object} NCR
+// @Failure 400 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse
+// @Router /ncr/{id}/links [post]
+func (h *Handler) link(w http.ResponseWriter, r *http.Request) {
+	var req LinkRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		pkg.RespondJSON(w, http.StatusBadRequest, ErrorResponse{Error: "Некорректный JSON"})
+		return
+	}
+
+	n, err := h.service.Link(pkg.ParamID(r), req, currentUser(r))
+	if err != nil {
+		h.respondError(w, err)
+		return
+	}
+	pkg.RespondJSON(w, http.StatusOK, n)
+}
+
+// SetNCRRootCause godoc
+// @Summary Указать коренную причину
+// @Tags ncr
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param id path int true "ID отчета"
+// @Param request body RootCauseRequest true "Коренная причина"
+// @Success 200 {object} NCR
+// @Failure 400 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse
+// @Router /ncr/{id}/root-cause [put]
+func (h *Handler) rootCause(w http.ResponseWriter, r *http.Request) {
+	var req RootCauseRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		pkg.RespondJSON(w, http.StatusBadRequest, ErrorResponse{Error: "Некорректный JSON"})
+		return
+	}
+
+	n, err := h.service.SetRootCause(pkg.ParamID(r), req.RootCause, currentUser(r))
+	if err != nil {
+		h.respondError(w, err)
+		return
+	}
+	pkg.RespondJSON(w, http.StatusOK, n)
+}
+
+// AddCAPAAction godoc
+// @Summary Добавить действие CAPA
+// @Description План меняется, пока не отправлен на утверждение
+// @Tags ncr
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param id path int true "ID отчета"
+// @Param request body ActionInput true "Действие"
+// @Success 201 {object} NCR
+// @Failure 400 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse
+// @Router /ncr/{id}/actions [post]
+func (h *Handler) addAction(w http.ResponseWriter, r *http.Request) {
+	var req ActionInput
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		pkg.RespondJSON(w, http.StatusBadRequest, ErrorResponse{Error: "Некорректный JSON"})
+		return
+	}
+
+	n, err := h.service.AddAction(pkg.ParamID(r), req, currentUser(r))
+	if err != nil {
+		h.respondError(w, err)
+		return
+	}
+	pkg.RespondJSON(w, http.StatusCreated, n)
+}
+
+// UpdateCAPAAction godoc
+// @Summary Изменить действие CAPA
+// @Tags ncr
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param id path int true "ID отчета"
+// @Param actionID path int true "ID действия"
+// @Param request body ActionInput true "Действие"
+// @Success 200 {object} NCR
+// @Failure 400 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse
+// @Router /ncr/{id}/actions/{actionID} [put]
+func (h *Handler) updateAction(w http.ResponseWriter, r *http.Request) {
+	var req ActionInput
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		pkg.RespondJSON(w, http.StatusBadRequest, ErrorResponse{Error: "Некорректный JSON"})
+		return
+	}
+
+	n, err := h.service.UpdateAction(pkg.ParamID(r), pkg.ParamInt64(r, "actionID"), req, currentUser(r))
+	if err != nil {
+		h.respondError(w, err)
+		return
+	}
+	pkg.RespondJSON(w, http.StatusOK, n)
+}
+
+// RemoveCAPAAction godoc
+// @Summary Удалить действие CAPA
+// @Tags ncr
+// @Security BearerAuth
+// @Produce json
+// @Param id path int true "ID отчета"
+// @Param actionID path int true "ID действия"
+// @Success 200 {object} NCR
+// @Failure 404 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse
+// @Router /ncr/{id}/actions/{actionID} [delete]
+func (h *Handler) removeAction(w http.ResponseWriter, r *http.Request) {
+	n, err := h.service.RemoveAction(pkg.ParamID(r), pkg.ParamInt64(r, "actionID"), currentUser(r))
+	if err != nil {
+		h.respondError(w, err)
+		return
+	}
+	pkg.RespondJSON(w, http.StatusOK, n)
+}
+
+// CompleteCAPAAction godoc
+// @Summary Выполнить действие CAPA
+// @Description Выполняет ответственный или пользователь с правом ncr.create; после последнего действия отчет переходит к проверке эффективности
+// @Tags ncr
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param id path int true "ID отчета"
+// @Param actionID path int true "ID действия"
+// @Param request body CompleteRequest true "Результат"
+// @Success 200 {object} NCR
+// @Failure 400 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse
+// @Router /ncr/{id}/actions/{actionID}/complete [post]
+func (h *Handler) completeAction(w http.ResponseWriter, r *http.Request) {
+	var req CompleteRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		pkg.RespondJSON(w, http.StatusBadRequest, ErrorResponse{Error: "Некорректный JSON"})
+		return
+	}
+
+	n, err := h.service.CompleteAction(pkg.ParamID(r), pkg.ParamInt64(r, "actionID"), req.Result, currentUser(r))
+	if err != nil {
+		h.respondError(w, err)
+		return
+	}
+	pkg.RespondJSON(w, http.StatusOK, n)
+}
+
+// SubmitNCR godoc
+// @Summary Отправить план CAPA на утверждение
+// @Description Нужны коренная причина и хотя бы одно невыполненное корректирующее действие
+// @Tags ncr
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param id path int true "ID отчета"
+// @Param request body CommentRequest true "Комментарий"
+// @Success 200 {object} NCR
+// @Failure 400 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse
+// @Router /ncr/{id}/submit [post]
+func (h *Handler) submit(w http.ResponseWriter, r *http.Request) {
+	h.transition(w, r, h.service.Submit)
+}
+
+// ApproveNCR godoc
+// @Summary Утвердить план CAPA
+// @Description Утверждающий не может быть автором плана; ответственные за действия получают уведомление
+// @Tags ncr
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param id path int true "ID отчета"
+// @Param request body CommentRequest true "Комментарий"
+// @Success 200 {object} NCR
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse
+// @Router /ncr/{id}/approve [post]
+func (h *Handler) approve(w http.ResponseWriter, r *http.Request) {
+	h.transition(w, r, h.service.Approve)
+}
+
+// RejectNCR godoc
+// @Summary Отклонить план CAPA
+// @Description Возвращает отчет на доработку плана; комментарий обязателен
+// @Tags ncr
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param id path int true "ID отчета"
+// @Param request body CommentRequest true "Причина отклонения"
+// @Success 200 {object} NCR
+// @Failure 400 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse
+// @Router /ncr/{id}/reject [post]
+func (h *Handler) reject(w http.ResponseWriter, r *http.Request) {
+	h.transition(w, r, h.service.Reject)
+}
+
+// VerifyNCR godoc
+// @Summary Проверка эффективности действий
+// @Description Эффективные действия позволяют закрыть отчет; неэффективные (комментарий обязателен) возвращают его в open для нового плана
+// @Tags ncr
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param id path int true "ID отчета"
+// @Param request body Verification true "Итог проверки"
+// @Success 200 {object} NCR
+// @Failure 400 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse
+// @Router /ncr/{id}/verify [post]
+func (h *Handler) verify(w http.ResponseWriter, r *http.Request) {
+	var req Verification
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		pkg.RespondJSON(w, http.StatusBadRequest, ErrorResponse{Error: "Некорректный JSON"})
+		return
+	}
+
+	n, err := h.service.Verify(pkg.ParamID(r), req, currentUser(r))
+	if err != nil {
+		h.respondError(w, err)
+		return
+	}
+	pkg.RespondJSON(w, http.StatusOK, n)
+}
+
+// CloseNCR godoc
+// @Summary Закрыть отчет о несоответствии
+// @Description Закрывается отчет с подтвержденной эффективностью действий
+// @Tags ncr
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param id path int true "ID отчета"
+// @Param request body CommentRequest true "Комментарий"
+// @Success 200 {object} NCR
+// @Failure 404 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse
+// @Router /ncr/{id}/close [post]
+func (h *Handler) closeNCR(w http.ResponseWriter, r *http.Request) {
+	h.transition(w, r, h.service.Close)
+}
+
+// CancelNCR godoc
+// @Summary Отменить отчет о несоответствии
+// @Description Отменяется отчет, план CAPA которого не утвержден; комментарий обязателен
+// @Tags ncr
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param id path int true "ID отчета"
+// @Param request body CommentRequest true "Причина отмены"
+// @Success 200 {object} NCR
+// @Failure 400 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse
+// @Router /ncr/{id}/cancel [post]
+func (h *Handler) cancel(w http.ResponseWriter, r *http.Request) {
+	h.transition(w, r, h.service.Cancel)
+}
+
+// transition выполняет переход отчета с комментарием из тела запроса
+func (h *Handler) transition(w http.ResponseWriter, r *http.Request, do func(id int64, comment string, userID int64) (*NCR, error)) {
+	var req CommentRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		pkg.RespondJSON(w, http.StatusBadRequest, ErrorResponse{Error: "Некорректный JSON"})
+		return
+	}
+
+	n, err := do(pkg.ParamID(r), req.Comment, currentUser(r))
+	if err != nil {
+		h.respondError(w, err)
+		return
+	}
+	pkg.RespondJSON(w, http.StatusOK, n)
+}
+
+func currentUser(r *http.Request) int64 {
+	id, _ := middleware.UserIDFromContext(r.Context())
+	return id
+}
+
+func (h *Handler) respondError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, ErrNotFound):
+		pkg.RespondJSON(w, http.StatusNotFound, ErrorResponse{Error: "Отчет о несоответствии не найден"})
+	case errors.Is(err, ErrActionNotFound):
+		pkg.RespondJSON(w, http.StatusNotFound, ErrorResponse{Error: "Действие CAPA не найдено"})
+	case errors.Is(err, ErrDefectCodeNotFound):
+		pkg.RespondJSON(w, http.StatusNotFound, ErrorResponse{Error: "Код дефекта не найден"})
+	case errors.Is(err, ErrInstanceNotFound):
+		pkg.RespondJSON(w, http.StatusNotFound, ErrorResponse{Error: "Экземпляр со штрихкодом не найден"})
+	case errors.Is(err, ErrWorkOrderNotFound):
+		pkg.RespondJSON(w, http.StatusNotFound, ErrorResponse{Error: "Заказ не найден"})
+	case errors.Is(err, ErrIncidentNotFound):
+		pkg.RespondJSON(w, http.StatusNotFound, ErrorResponse{Error: "Инцидент не найден"})
+	case errors.Is(err, ErrUserNotFound):
+		pkg.RespondJSON(w, http.StatusNotFound, ErrorResponse{Error: "Ответственный не найден"})
+	case errors.Is(err, ErrTitleRequired):
+		pkg.RespondJSON(w, http.StatusBadRequest, ErrorResponse{Error: "Укажите заголовок отчета"})
+	case errors.Is(err, ErrTitleTooLong):
+		pkg.RespondJSON(w, http.StatusBadRequest, ErrorResponse{Error: "Заголовок не должен превышать 200 символов"})
+	case errors.Is(err, ErrInvalidStatus):
+		pkg.RespondJSON(w, http.StatusBadRequest, ErrorResponse{Error: "Неизвестный статус"})
+	case errors.Is(err, ErrTooManyLinks):
+		pkg.RespondJSON(w, http.StatusBadRequest, ErrorResponse{Error: "Не более 1000 связей каждого типа за раз"})
+	case errors.Is(err, ErrInvalidKind):
+		pkg.RespondJSON(w, http.StatusBadRequest, ErrorResponse{Error: "Вид действия должен быть corrective или preventive"})
+	case errors.Is(err, ErrDescriptionRequired):
+		pkg.RespondJSON(w, http.StatusBadRequest, ErrorResponse{Error: "Укажите описание действия"})
+	case errors.Is(err, ErrDueDateRequired):
+		pkg.RespondJSON(w, http.StatusBadRequest, ErrorResponse{Error: "Укажите срок действия"})
+	case errors.Is(err, ErrDueDatePast):
+		pkg.RespondJSON(w, http.StatusBadRequest, ErrorResponse{Error: "Срок действия не может быть в прошлом"})
+	case errors.Is(err, ErrRootCauseRequired):
+		pkg.RespondJSON(w, http.StatusBadRequest, ErrorResponse{Error: "Укажите коренную причину"})
+	case errors.Is(err, ErrNoActions):
+		pkg.RespondJSON(w, http.StatusBadRequest, ErrorResponse{Error: "План должен содержать хотя бы одно невыполненное корректирующее действие"})
+	case errors.Is(err, ErrCommentRequired):
+		pkg.RespondJSON(w, http.StatusBadRequest, ErrorResponse{Error: "Укажите комментарий"})
+	case errors.Is(err, ErrResultRequired):
+		pkg.RespondJSON(w, http.StatusBadRequest, ErrorResponse{Error: "Укажите результат выполнения действия"})
+	case errors.Is(err, ErrSelfApproval):
+		pkg.RespondJSON(w, http.StatusForbidden, ErrorResponse{Error: "Автор плана не может принимать по нему решение"})
+	case errors.Is(err, ErrNotOwner):
+		pkg.RespondJSON(w, http.StatusForbidden, ErrorResponse{Error: "Действие выполняет ответственный"})
+	case errors.Is(err, ErrActionDone):
+		pkg.RespondJSON(w, http.StatusConflict, ErrorResponse{Error: "Действие уже выполнено"})
+	case errors.Is(err, ErrStatus):
+		pkg.RespondJSON(w, http.StatusConflict, ErrorResponse{Error: "Операция недоступна в текущем статусе отчета"})
+	default:
+		slog.Error("ncr request failed", slog.Any("err", err))
+		pkg.RespondJSON(w, http.StatusInternalServerError, ErrorResponse{Error: "Внутренняя ошибка сервера"})
+	}
+}
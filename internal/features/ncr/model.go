@@ -0,0 +1,209 @@
+package ncr
+
+import "time"
+
+// Статусы отчета о несоответствии
+const (
+	StatusOpen            = "open"
+	StatusPendingApproval = "pending_approval"
+	StatusInProgress      = "in_progress"
+	StatusVerification    = "verification"
+	StatusVerified        = "verified"
+	StatusClosed          = "closed"
+	StatusCancelled       = "cancelled"
+)
+
+// Виды и статусы действий CAPA
+const (
+	KindCorrective = "corrective"
+	KindPreventive = "preventive"
+
+	ActionOpen = "open"
+	ActionDone = "done"
+)
+
+// Этапы и решения утверждения
+const (
+	StepPlan    = "plan"
+	StepClosure = "closure"
+
+	DecisionApproved = "approved"
+	DecisionRejected = "rejected"
+)
+
+// Действия журнала отчета
+const (
+	EventCreated         = "created"
+	EventUpdated         = "updated"
+	EventLinked          = "linked"
+	EventRootCause       = "root_cause"
+	EventActionAdded     = "action_added"
+	EventActionUpdated   = "action_updated"
+	EventActionRemoved   = "action_removed"
+	EventActionCompleted = "action_completed"
+	EventSubmitted       = "submitted"
+	EventApproved        = "approved"
+	EventRejected        = "rejected"
+	EventVerified        = "verified"
+	EventIneffective     = "ineffective"
+	EventClosed          = "closed"
+	EventCancelled       = "cancelled"
+)
+
+// NCR отчет о несоответствии с анализом причины и планом CAPA.
+// Effective — итог проверки эффективности действий; nil, пока проверка не проводилась.
+type NCR struct {
+	ID                   int64            `gorm:"primaryKey;autoIncrement" json:"id"`
+	Number               string           `json:"number" example:"NCR-2026-0007"`
+	Title                string           `json:"title" example:"Повторяющиеся трещины сварного шва"`
+	Description          string           `json:"description,omitempty"`
+	DefectCodeID         *int             `json:"defect_code_id,omitempty"`
+	Status               string           `json:"status" example:"open"`
+	RootCause            string           `json:"root_cause,omitempty" example:"Износ токоподводящего наконечника"`
+	SubmittedBy          *int64           `json:"submitted_by,omitempty"`
+	SubmittedAt          *time.Time       `json:"submitted_at,omitempty"`
+	Effective            *bool            `json:"effective,omitempty"`
+	EffectivenessComment string           `json:"effectiveness_comment,omitempty"`
+	VerifiedBy           *int64           `json:"verified_by,omitempty"`
+	VerifiedAt           *time.Time       `json:"verified_at,omitempty"`
+	ClosureComment       string           `json:"closure_comment,omitempty"`
+	ClosedBy             *int64           `json:"closed_by,omitempty"`
+	ClosedAt             *time.Time       `json:"closed_at,omitempty"`
+	CreatedBy            *int64           `json:"created_by,omitempty"`
+	CreatedAt            time.Time        `gorm:"autoCreateTime" json:"created_at"`
+	UpdatedAt            time.Time        `gorm:"autoUpdateTime" json:"updated_at"`
+	Instances            []*InstanceLink  `gorm:"foreignKey:NCRID" json:"instances"`
+	WorkOrders           []*WorkOrderLink `gorm:"foreignKey:NCRID" json:"work_orders"`
+	Incidents            []*IncidentLink  `gorm:"foreignKey:NCRID" json:"incidents"`
+	Actions              []*Action        `gorm:"foreignKey:NCRID" json:"actions"`
+	Approvals            []*Approval      `gorm:"foreignKey:NCRID" json:"approvals"`
+}
+
+func (NCR) TableName() string {
+	return "ncrs"
+}
+
+// InstanceLink затронутый экземпляр продукции
+type InstanceLink struct {
+	NCRID             int64  `gorm:"column:ncr_id;primaryKey" json:"-"`
+	ProductInstanceID int64  `gorm:"primaryKey" json:"product_instance_id"`
+	Barcode           string `gorm:"->" json:"barcode"`
+}
+
+func (InstanceLink) TableName() string {
+	return "ncr_instances"
+}
+
+// WorkOrderLink затронутый заказ
+type WorkOrderLink struct {
+	NCRID       int64  `gorm:"column:ncr_id;primaryKey" json:"-"`
+	WorkOrderID int64  `gorm:"primaryKey" json:"work_order_id"`
+	WoNumber    string `gorm:"->" json:"wo_number"`
+}
+
+func (WorkOrderLink) TableName() string {
+	return "ncr_work_orders"
+}
+
+// IncidentLink связанный инцидент
+type IncidentLink struct {
+	NCRID      int64  `gorm:"column:ncr_id;primaryKey" json:"-"`
+	IncidentID int64  `gorm:"primaryKey" json:"incident_id"`
+	Title      string `gorm:"->" json:"title"`
+	Status     string `gorm:"->" json:"status"`
+}
+
+func (IncidentLink) TableName() string {
+	return "ncr_incidents"
+}
+
+// Action корректирующее или предупреждающее действие с ответственным и сроком.
+// Overdue — не выполнено к концу срока; вычисляется при выборке.
+type Action struct {
+	ID          int64      `gorm:"primaryKey;autoIncrement" json:"id"`
+	NCRID       int64      `gorm:"column:ncr_id" json:"ncr_id"`
+	Kind        string     `json:"kind" example:"corrective"`
+	Description string     `json:"description" example:"Заменить наконечники на всех сварочных постах линии"`
+	OwnerID     int64      `json:"owner_id" example:"5"`
+	DueDate     time.Time  `gorm:"type:date" json:"due_date" example:"2026-11-15T00:00:00Z"`
+	Status      string     `json:"status" example:"open"`
+	Result      string     `json:"result,omitempty"`
+	CompletedBy *int64     `json:"completed_by,omitempty"`
+	CompletedAt *time.Time `json:"completed_at,omitempty"`
+	CreatedAt   time.Time  `gorm:"autoCreateTime" json:"created_at"`
+	Overdue     bool       `gorm:"-" json:"overdue"`
+}
+
+func (Action) TableName() string {
+	return "capa_actions"
+}
+
+// Approval решение по утверждению плана CAPA или закрытию отчета
+type Approval struct {
+	ID        int64     `gorm:"primaryKey;autoIncrement" json:"id"`
+	NCRID     int64     `gorm:"column:ncr_id" json:"-"`
+	Step      string    `json:"step" example:"plan"`
+	Decision  string    `json:"decision" example:"approved"`
+	UserID    int64     `json:"user_id"`
+	Comment   string    `json:"comment,omitempty"`
+	CreatedAt time.Time `gorm:"autoCreateTime" json:"created_at"`
+}
+
+func (Approval) TableName() string {
+	return "ncr_approvals"
+}
+
+// Event запись журнала отчета
+type Event struct {
+	ID        int64     `gorm:"primaryKey;autoIncrement" json:"id"`
+	NCRID     int64     `gorm:"column:ncr_id" json:"ncr_id"`
+	Action    string    `json:"action"`
+	UserID    *int64    `json:"user_id,omitempty"`
+	Comment   string    `json:"comment,omitempty"`
+	CreatedAt time.Time `gorm:"autoCreateTime" json:"created_at"`
+}
+
+func (Event) TableName() string {
+	return "ncr_events"
+}
+
+// Links связи отчета, добавляемые при изменении
+type Links struct {
+	InstanceIDs  []int64
+	WorkOrderIDs []int64
+	IncidentIDs  []int64
+}
+
+// Entry записи, сохраняемые вместе с изменением отчета
+type Entry struct {
+	Event    *Event
+	Approval *Approval
+	Links    *Links
+}
+
+// InstanceRef экземпляр, найденный по штрихкоду
+type InstanceRef struct {
+	ID      int64
+	Barcode string
+}
+
+// ListFilter параметры выборки отчетов
+type ListFilter struct {
+	Status       string
+	Barcode      string
+	WorkOrderID  int64
+	IncidentID   int64
+	DefectCodeID int
+	Limit        int
+	Offset       int
+}
+
+// ActionFilter параметры выборки действий CAPA; Overdue — только просроченные на дату Today
+type ActionFilter struct {
+	OwnerID int64
+	Status  string
+	Overdue bool
+	Today   time.Time
+	Limit   int
+	Offset  int
+}
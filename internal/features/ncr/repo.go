@@ -0,0 +1,26 @@
+package ncr
+
+type Repository interface {
+	// Create сохраняет отчет со связями и первую запись журнала
+	Create(n *NCR, links *Links, e *Event) error
+	// Update изменяет отчет под блокировкой вместе с его действиями: действия с ID изменяются,
+	// без ID добавляются, отсутствующие в n.Actions удаляются. change может вернуть записи журнала,
+	// утверждения и новые связи.
+	Update(id int64, change func(n *NCR) (*Entry, error)) (*NCR, error)
+
+	Get(id int64) (*NCR, error)
+	List(filter ListFilter) ([]*NCR, error)
+	ListEvents(ncrID int64) ([]*Event, error)
+	ListActions(filter ActionFilter) ([]*Action, error)
+
+	// AllocateNumber атомарно выдает следующий номер в счетчике scope
+	AllocateNumber(scope string) (int64, error)
+
+	InstancesByBarcode(barcodes []string) ([]*InstanceRef, error)
+	// CountWorkOrders число существующих заказов из ids
+	CountWorkOrders(ids []int64) (int64, error)
+	// CountIncidents число существующих инцидентов из ids
+	CountIncidents(ids []int64) (int64, error)
+	DefectCodeExists(id int) (bool, error)
+	UserExists(id int64) (bool, error)
+}
@@ -0,0 +1,252 @@
+package ncr
+
+import (
+	"mes-lite-back/internal/numbering"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type GormRepository struct {
+	db *gorm.DB
+}
+
+func NewGormRepository(db *gorm.DB) *GormRepository {
+	return &GormRepository{db: db}
+}
+
+func (r *GormRepository) Create(n *NCR, links *Links, e *Event) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Omit(clause.Associations).Create(n).Error; err != nil {
+			return err
+		}
+		if err := saveLinks(tx, n.ID, links); err != nil {
+			return err
+		}
+		e.NCRID = n.ID
+		return tx.Create(e).Error
+	})
+}
+
+func (r *GormRepository) Update(id int64, change func(n *NCR) (*Entry, error)) (*NCR, error) {
+	var n NCR
+
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&n, id).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("ncr_id = ?", id).Order("id").Find(&n.Actions).Error; err != nil {
+			return err
+		}
+
+		entry, err := change(&n)
+		if err != nil {
+			return err
+		}
+		if err := tx.Omit(clause.Associations).Save(&n).Error; err != nil {
+			return err
+		}
+
+		keep := make([]int64, 0, len(n.Actions))
+		for _, a := range n.Actions {
+			if a.ID > 0 {
+				keep = append(keep, a.ID)
+			}
+		}
+		q := tx.Where("ncr_id = ?", id)
+		if len(keep) > 0 {
+			q = q.Where("id NOT IN ?", keep)
+		}
+		if err := q.Delete(&Action{}).Error; err != nil {
+			return err
+		}
+		for _, a := range n.Actions {
+			a.NCRID = id
+			if err := tx.Save(a).Error; err != nil {
+				return err
+			}
+		}
+
+		if entry == nil {
+			return nil
+		}
+		if err := saveLinks(tx, id, entry.Links); err != nil {
+			return err
+		}
+		if entry.Approval != nil {
+			entry.Approval.NCRID = id
+			if err := tx.Create(entry.Approval).Error; err != nil {
+				return err
+			}
+		}
+		if entry.Event != nil {
+			entry.Event.NCRID = id
+			return tx.Create(entry.Event).Error
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return r.Get(id)
+}
+
+// saveLinks добавляет связи отчета; уже существующие пропускаются
+func saveLinks(tx *gorm.DB, ncrID int64, links *Links) error {
+	if links == nil {
+		return nil
+	}
+
+	var instances []*InstanceLink
+	for _, id := range links.InstanceIDs {
+		instances = append(instances, &InstanceLink{NCRID: ncrID, ProductInstanceID: id})
+	}
+	var workOrders []*WorkOrderLink
+	for _, id := range links.WorkOrderIDs {
+		workOrders = append(workOrders, &WorkOrderLink{NCRID: ncrID, WorkOrderID: id})
+	}
+	var incidents []*IncidentLink
+	for _, id := range links.IncidentIDs {
+		incidents = append(incidents, &IncidentLink{NCRID: ncrID, IncidentID: id})
+	}
+
+	ignore := tx.Clauses(clause.OnConflict{DoNothing: true})
+	if len(instances) > 0 {
+		if err := ignore.Create(&instances).Error; err != nil {
+			return err
+		}
+	}
+	if len(workOrders) > 0 {
+		if err := ignore.Create(&workOrders).Error; err != nil {
+			return err
+		}
+	}
+	if len(incidents) > 0 {
+		return ignore.Create(&incidents).Error
+	}
+	return nil
+}
+
+func (r *GormRepository) Get(id int64) (*NCR, error) {
+	var n NCR
+	err := r.db.
+		Preload("Instances", func(db *gorm.DB) *gorm.DB {
+			return db.
+				Select("ncr_instances.*, pi.barcode").
+				Joins("JOIN product_instances pi ON pi.id = ncr_instances.product_instance_id").
+				Order("ncr_instances.product_instance_id")
+		}).
+		Preload("WorkOrders", func(db *gorm.DB) *gorm.DB {
+			return db.
+				Select("ncr_work_orders.*, wo.wo_number").
+				Joins("JOIN work_orders wo ON wo.id = ncr_work_orders.work_order_id").
+				Order("ncr_work_orders.work_order_id")
+		}).
+		Preload("Incidents", func(db *gorm.DB) *gorm.DB {
+			return db.
+				Select("ncr_incidents.*, i.title, i.status").
+				Joins("JOIN incidents i ON i.id = ncr_incidents.incident_id").
+				Order("ncr_incidents.incident_id")
+		}).
+		Preload("Actions", func(db *gorm.DB) *gorm.DB { return db.Order("id") }).
+		Preload("Approvals", func(db *gorm.DB) *gorm.DB { return db.Order("created_at, id") }).
+		First(&n, id).
+		Error
+	if err != nil {
+		return nil, err
+	}
+	return &n, nil
+}
+
+func (r *GormRepository) List(filter ListFilter) ([]*NCR, error) {
+	var ncrs []*NCR
+
+	q := r.db.Order("id DESC")
+	if filter.Status != "" {
+		q = q.Where("status = ?", filter.Status)
+	}
+	if filter.Barcode != "" {
+		q = q.Where(
+			"EXISTS (SELECT 1 FROM ncr_instances ni JOIN product_instances pi ON pi.id = ni.product_instance_id WHERE ni.ncr_id = ncrs.id AND pi.barcode = ?)",
+			filter.Barcode,
+		)
+	}
+	if filter.WorkOrderID > 0 {
+		q = q.Where("EXISTS (SELECT 1 FROM ncr_work_orders nw WHERE nw.ncr_id = ncrs.id AND nw.work_order_id = ?)", filter.WorkOrderID)
+	}
+	if filter.IncidentID > 0 {
+		q = q.Where("EXISTS (SELECT 1 FROM ncr_incidents ni WHERE ni.ncr_id = ncrs.id AND ni.incident_id = ?)", filter.IncidentID)
+	}
+	if filter.DefectCodeID > 0 {
+		q = q.Where("defect_code_id = ?", filter.DefectCodeID)
+	}
+
+	return ncrs, q.Limit(filter.Limit).Offset(filter.Offset).Find(&ncrs).Error
+}
+
+func (r *GormRepository) ListEvents(ncrID int64) ([]*Event, error) {
+	var events []*Event
+	err := r.db.
+		Where("ncr_id = ?", ncrID).
+		Order("created_at, id").
+		Find(&events).
+		Error
+	return events, err
+}
+
+func (r *GormRepository) ListActions(filter ActionFilter) ([]*Action, error) {
+	var actions []*Action
+
+	q := r.db.Order("due_date, id")
+	if filter.OwnerID > 0 {
+		q = q.Where("owner_id = ?", filter.OwnerID)
+	}
+	if filter.Status != "" {
+		q = q.Where("status = ?", filter.Status)
+	}
+	if filter.Overdue {
+		q = q.Where("status = ? AND due_date < ?", ActionOpen, filter.Today)
+	}
+	// действия отмененных и закрытых отчетов не ведутся
+	q = q.Where("ncr_id IN (SELECT id FROM ncrs WHERE status NOT IN ?)", []string{StatusCancelled, StatusClosed})
+
+	return actions, q.Limit(filter.Limit).Offset(filter.Offset).Find(&actions).Error
+}
+
+func (r *GormRepository) AllocateNumber(scope string) (int64, error) {
+	return numbering.Allocate(r.db, scope, 1)
+}
+
+func (r *GormRepository) InstancesByBarcode(barcodes []string) ([]*InstanceRef, error) {
+	var refs []*InstanceRef
+	err := r.db.Table("product_instances").
+		Select("id, barcode").
+		Where("barcode IN ?", barcodes).
+		Scan(&refs).
+		Error
+	return refs, err
+}
+
+func (r *GormRepository) CountWorkOrders(ids []int64) (int64, error) {
+	var n int64
+	err := r.db.Table("work_orders").Where("id IN ?", ids).Count(&n).Error
+	return n, err
+}
+
+func (r *GormRepository) CountIncidents(ids []int64) (int64, error) {
+	var n int64
+	err := r.db.Table("incidents").Where("id IN ?", ids).Count(&n).Error
+	return n, err
+}
+
+func (r *GormRepository) DefectCodeExists(id int) (bool, error) {
+	var n int64
+	err := r.db.Table("defect_codes").Where("id = ?", id).Count(&n).Error
+	return n > 0, err
+}
+
+func (r *GormRepository) UserExists(id int64) (bool, error) {
+	var n int64
+	err := r.db.Table("users").Where("id = ?", id).Count(&n).Error
+	return n > 0, err
+}
@@ -0,0 +1,479 @@
+package ncr
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"mes-lite-back/internal/features/notification"
+	"mes-lite-back/internal/http/middleware"
+
+	"gorm.io/gorm"
+)
+
+const (
+	// maxTitleLength ограничивает длину заголовка отчета в символах
+	maxTitleLength = 200
+	// maxLimit ограничивает размер страницы отчетов и действий
+	maxLimit = 200
+	// maxLinks ограничивает число связей одного типа, добавляемых за раз
+	maxLinks = 1000
+)
+
+var (
+	ErrNotFound            = errors.New("ncr not found")
+	ErrActionNotFound      = errors.New("capa action not found")
+	ErrTitleRequired       = errors.New("ncr title is required")
+	ErrTitleTooLong        = errors.New("ncr title is too long")
+	ErrInvalidStatus       = errors.New("unknown ncr status")
+	ErrStatus              = errors.New("operation is not allowed in the current ncr status")
+	ErrDefectCodeNotFound  = errors.New("defect code not found")
+	ErrInstanceNotFound    = errors.New("product instance not found")
+	ErrWorkOrderNotFound   = errors.New("work order not found")
+	ErrIncidentNotFound    = errors.New("incident not found")
+	ErrTooManyLinks        = errors.New("too many links")
+	ErrUserNotFound        = errors.New("user not found")
+	ErrInvalidKind         = errors.New("action kind must be corrective or preventive")
+	ErrDescriptionRequired = errors.New("action description is required")
+	ErrDueDateRequired     = errors.New("action due date is required")
+	ErrDueDatePast         = errors.New("action due date is in the past")
+	ErrRootCauseRequired   = errors.New("root cause is required")
+	ErrNoActions           = errors.New("capa plan needs at least one corrective action")
+	ErrSelfApproval        = errors.New("capa plan cannot be approved by its submitter")
+	ErrCommentRequired     = errors.New("comment is required")
+	ErrNotOwner            = errors.New("only the action owner can complete it")
+	ErrResultRequired      = errors.New("action result is required")
+	ErrActionDone          = errors.New("action is already done")
+)
+
+// Report регистрация отчета о несоответствии с затронутыми экземплярами, заказами и инцидентами
+type Report struct {
+	Title        string   `json:"title" example:"Повторяющиеся трещины сварного шва"`
+	Description  string   `json:"description,omitempty" example:"Трещины в 4 экземплярах за смену, код дефекта CRACK"`
+	DefectCodeID *int     `json:"defect_code_id,omitempty" example:"3"`
+	Barcodes     []string `json:"barcodes,omitempty"`
+	WorkOrderIDs []int64  `json:"work_order_ids,omitempty"`
+	IncidentIDs  []int64  `json:"incident_ids,omitempty"`
+}
+
+// Edit изменение описания отчета
+type Edit struct {
+	Title        string `json:"title" example:"Повторяющиеся трещины сварного шва"`
+	Description  string `json:"description,omitempty"`
+	DefectCodeID *int   `json:"defect_code_id,omitempty" example:"3"`
+}
+
+// LinkRequest связи, добавляемые к отчету
+type LinkRequest struct {
+	Barcodes     []string `json:"barcodes,omitempty"`
+	WorkOrderIDs []int64  `json:"work_order_ids,omitempty"`
+	IncidentIDs  []int64  `json:"incident_ids,omitempty"`
+}
+
+// ActionInput действие CAPA: вид, описание, ответственный и срок
+type ActionInput struct {
+	Kind        string    `json:"kind" example:"corrective"`
+	Description string    `json:"description" example:"Заменить наконечники на всех сварочных постах линии"`
+	OwnerID     int64     `json:"owner_id" example:"5"`
+	DueDate     time.Time `json:"due_date" example:"2026-11-15T00:00:00Z"`
+}
+
+// Notifier уведомления ответственным за действия и автору плана
+type Notifier interface {
+	Notify(userIDs []int64, msg notification.Message) error
+}
+
+// ServiceInterface определяет методы, используемые handler’ом
+type ServiceInterface interface {
+	List(filter ListFilter) ([]*NCR, error)
+	Get(id int64) (*NCR, error)
+	Events(id int64) ([]*Event, error)
+	Actions(filter ActionFilter) ([]*Action, error)
+
+	Create(r Report, userID int64) (*NCR, error)
+	Update(id int64, e Edit, userID int64) (*NCR, error)
+	Link(id int64, req LinkRequest, userID int64) (*NCR, error)
+	SetRootCause(id int64, rootCause string, userID int64) (*NCR, error)
+	AddAction(id int64, in ActionInput, userID int64) (*NCR, error)
+	UpdateAction(id, actionID int64, in ActionInput, userID int64) (*NCR, error)
+	RemoveAction(id, actionID int64, userID int64) (*NCR, error)
+
+	Submit(id int64, comment string, userID int64) (*NCR, error)
+	Approve(id int64, comment string, userID int64) (*NCR, error)
+	Reject(id int64, comment string, userID int64) (*NCR, error)
+	CompleteAction(id, actionID int64, result string, userID int64) (*NCR, error)
+	Verify(id int64, v Verification, userID int64) (*NCR, error)
+	Close(id int64, comment string, userID int64) (*NCR, error)
+	Cancel(id int64, comment string, userID int64) (*NCR, error)
+}
+
+type Service struct {
+	repo     Repository
+	perms    middleware.PermissionChecker
+	notifier Notifier
+	now      func() time.Time
+}
+
+func NewService(repo Repository, perms middleware.PermissionChecker, notifier Notifier) *Service {
+	return &Service{
+		repo:     repo,
+		perms:    perms,
+		notifier: notifier,
+		now:      time.Now,
+	}
+}
+
+func (s *Service) List(filter ListFilter) ([]*NCR, error) {
+	switch filter.Status {
+	case "", StatusOpen, StatusPendingApproval, StatusInProgress, StatusVerification, StatusVerified, StatusClosed, StatusCancelled:
+	default:
+		return nil, ErrInvalidStatus
+	}
+	filter.Barcode = strings.TrimSpace(filter.Barcode)
+	if filter.Limit <= 0 || filter.Limit > maxLimit {
+		filter.Limit = maxLimit
+	}
+	return s.repo.List(filter)
+}
+
+func (s *Service) Get(id int64) (*NCR, error) {
+	n, err := s.repo.Get(id)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	s.markOverdue(n.Actions)
+	return n, nil
+}
+
+func (s *Service) Events(id int64) ([]*Event, error) {
+	if _, err := s.Get(id); err != nil {
+		return nil, err
+	}
+	return s.repo.ListEvents(id)
+}
+
+// Actions действия CAPA незакрытых отчетов в порядке срока
+func (s *Service) Actions(filter ActionFilter) ([]*Action, error) {
+	switch filter.Status {
+	case "", ActionOpen, ActionDone:
+	default:
+		return nil, ErrInvalidStatus
+	}
+	if filter.Limit <= 0 || filter.Limit > maxLimit {
+		filter.Limit = maxLimit
+	}
+	filter.Today = today(s.now())
+
+	actions, err := s.repo.ListActions(filter)
+	if err != nil {
+		return nil, err
+	}
+	s.markOverdue(actions)
+	return actions, nil
+}
+
+// Create регистрирует отчет с номером NCR-<год>-<порядковый номер за год>
+func (s *Service) Create(r Report, userID int64) (*NCR, error) {
+	n := &NCR{
+		Title:        r.Title,
+		Description:  r.Description,
+		DefectCodeID: r.DefectCodeID,
+		Status:       StatusOpen,
+		CreatedBy:    &userID,
+	}
+	if err := s.validate(n); err != nil {
+		return nil, err
+	}
+
+	links, err := s.links(LinkRequest{Barcodes: r.Barcodes, WorkOrderIDs: r.WorkOrderIDs, IncidentIDs: r.IncidentIDs})
+	if err != nil {
+		return nil, err
+	}
+
+	year := s.now().Year()
+	seq, err := s.repo.AllocateNumber(fmt.Sprintf("ncr-%d", year))
+	if err != nil {
+		return nil, err
+	}
+	n.Number = fmt.Sprintf("NCR-%d-%04d", year, seq)
+
+	if err := s.repo.Create(n, links, &Event{Action: EventCreated, UserID: &userID}); err != nil {
+		return nil, err
+	}
+	return s.Get(n.ID)
+}
+
+// Update меняет описание отчета, пока план CAPA не отправлен на утверждение
+func (s *Service) Update(id int64, e Edit, userID int64) (*NCR, error) {
+	changed := &NCR{
+		Title:        e.Title,
+		Description:  e.Description,
+		DefectCodeID: e.DefectCodeID,
+	}
+	if err := s.validate(changed); err != nil {
+		return nil, err
+	}
+
+	return s.update(id, func(n *NCR) (*Entry, error) {
+		if n.Status != StatusOpen {
+			return nil, ErrStatus
+		}
+		n.Title = changed.Title
+		n.Description = changed.Description
+		n.DefectCodeID = changed.DefectCodeID
+
+		return &Entry{Event: &Event{Action: EventUpdated, UserID: &userID}}, nil
+	})
+}
+
+// Link добавляет к незакрытому отчету экземпляры, заказы и инциденты; существующие связи не дублируются
+func (s *Service) Link(id int64, req LinkRequest, userID int64) (*NCR, error) {
+	links, err := s.links(req)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.update(id, func(n *NCR) (*Entry, error) {
+		if n.Status == StatusClosed || n.Status == StatusCancelled {
+			return nil, ErrStatus
+		}
+		return &Entry{
+			Event: &Event{Action: EventLinked, UserID: &userID},
+			Links: links,
+		}, nil
+	})
+}
+
+// SetRootCause фиксирует результат анализа коренной причины
+func (s *Service) SetRootCause(id int64, rootCause string, userID int64) (*NCR, error) {
+	rootCause = strings.TrimSpace(rootCause)
+	if rootCause == "" {
+		return nil, ErrRootCauseRequired
+	}
+
+	return s.update(id, func(n *NCR) (*Entry, error) {
+		if n.Status != StatusOpen {
+			return nil, ErrStatus
+		}
+		n.RootCause = rootCause
+		return &Entry{Event: &Event{Action: EventRootCause, UserID: &userID, Comment: rootCause}}, nil
+	})
+}
+
+// AddAction добавляет действие в план CAPA; план меняется только до отправки на утверждение
+func (s *Service) AddAction(id int64, in ActionInput, userID int64) (*NCR, error) {
+	a, err := s.action(in)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.update(id, func(n *NCR) (*Entry, error) {
+		if n.Status != StatusOpen {
+			return nil, ErrStatus
+		}
+		n.Actions = append(n.Actions, a)
+		return &Entry{Event: &Event{Action: EventActionAdded, UserID: &userID, Comment: a.Description}}, nil
+	})
+}
+
+// UpdateAction изменяет невыполненное действие плана
+func (s *Service) UpdateAction(id, actionID int64, in ActionInput, userID int64) (*NCR, error) {
+	changed, err := s.action(in)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.update(id, func(n *NCR) (*Entry, error) {
+		if n.Status != StatusOpen {
+			return nil, ErrStatus
+		}
+		a := findAction(n, actionID)
+		if a == nil {
+			return nil, ErrActionNotFound
+		}
+		if a.Status == ActionDone {
+			return nil, ErrActionDone
+		}
+		a.Kind = changed.Kind
+		a.Description = changed.Description
+		a.OwnerID = changed.OwnerID
+		a.DueDate = changed.DueDate
+
+		return &Entry{Event: &Event{Action: EventActionUpdated, UserID: &userID, Comment: a.Description}}, nil
+	})
+}
+
+// RemoveAction удаляет невыполненное действие из плана
+func (s *Service) RemoveAction(id, actionID int64, userID int64) (*NCR, error) {
+	return s.update(id, func(n *NCR) (*Entry, error) {
+		if n.Status != StatusOpen {
+			return nil, ErrStatus
+		}
+		a := findAction(n, actionID)
+		if a == nil {
+			return nil, ErrActionNotFound
+		}
+		if a.Status == ActionDone {
+			return nil, ErrActionDone
+		}
+		n.Actions = slices.DeleteFunc(n.Actions, func(x *Action) bool { return x.ID == actionID })
+
+		return &Entry{Event: &Event{Action: EventActionRemoved, UserID: &userID, Comment: a.Description}}, nil
+	})
+}
+
+func (s *Service) update(id int64, change func(n *NCR) (*Entry, error)) (*NCR, error) {
+	n, err := s.repo.Update(id, change)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	s.markOverdue(n.Actions)
+	return n, nil
+}
+
+func (s *Service) validate(n *NCR) error {
+	n.Title = strings.TrimSpace(n.Title)
+	n.Description = strings.TrimSpace(n.Description)
+	if n.Title == "" {
+		return ErrTitleRequired
+	}
+	if len([]rune(n.Title)) > maxTitleLength {
+		return ErrTitleTooLong
+	}
+
+	if n.DefectCodeID != nil {
+		ok, err := s.repo.DefectCodeExists(*n.DefectCodeID)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			return ErrDefectCodeNotFound
+		}
+	}
+	return nil
+}
+
+// links проверяет связи: все штрихкоды, заказы и инциденты должны существовать; повторы не учитываются
+func (s *Service) links(req LinkRequest) (*Links, error) {
+	links := &Links{
+		WorkOrderIDs: unique(req.WorkOrderIDs),
+		IncidentIDs:  unique(req.IncidentIDs),
+	}
+
+	var barcodes []string
+	for _, b := range req.Barcodes {
+		b = strings.TrimSpace(b)
+		if b != "" && !slices.Contains(barcodes, b) {
+			barcodes = append(barcodes, b)
+		}
+	}
+	if len(barcodes) > maxLinks || len(links.WorkOrderIDs) > maxLinks || len(links.IncidentIDs) > maxLinks {
+		return nil, ErrTooManyLinks
+	}
+
+	if len(barcodes) > 0 {
+		refs, err := s.repo.InstancesByBarcode(barcodes)
+		if err != nil {
+			return nil, err
+		}
+		if len(refs) != len(barcodes) {
+			return nil, ErrInstanceNotFound
+		}
+		for _, ref := range refs {
+			links.InstanceIDs = append(links.InstanceIDs, ref.ID)
+		}
+	}
+
+	if len(links.WorkOrderIDs) > 0 {
+		n, err := s.repo.CountWorkOrders(links.WorkOrderIDs)
+		if err != nil {
+			return nil, err
+		}
+		if n != int64(len(links.WorkOrderIDs)) {
+			return nil, ErrWorkOrderNotFound
+		}
+	}
+
+	if len(links.IncidentIDs) > 0 {
+		n, err := s.repo.CountIncidents(links.IncidentIDs)
+		if err != nil {
+			return nil, err
+		}
+		if n != int64(len(links.IncidentIDs)) {
+			return nil, ErrIncidentNotFound
+		}
+	}
+	return links, nil
+}
+
+// action проверяет действие плана: вид, описание, существующий ответственный и срок не раньше сегодняшнего дня
+func (s *Service) action(in ActionInput) (*Action, error) {
+	a := &Action{
+		Kind:        in.Kind,
+		Description: strings.TrimSpace(in.Description),
+		OwnerID:     in.OwnerID,
+		Status:      ActionOpen,
+	}
+	if a.Kind != KindCorrective && a.Kind != KindPreventive {
+		return nil, ErrInvalidKind
+	}
+	if a.Description == "" {
+		return nil, ErrDescriptionRequired
+	}
+	if in.DueDate.IsZero() {
+		return nil, ErrDueDateRequired
+	}
+	a.DueDate = today(in.DueDate)
+	if a.DueDate.Before(today(s.now())) {
+		return nil, ErrDueDatePast
+	}
+
+	ok, err := s.repo.UserExists(a.OwnerID)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, ErrUserNotFound
+	}
+	return a, nil
+}
+
+// markOverdue отмечает невыполненные действия, срок которых прошел
+func (s *Service) markOverdue(actions []*Action) {
+	now := today(s.now())
+	for _, a := range actions {
+		a.Overdue = a.Status == ActionOpen && a.DueDate.Before(now)
+	}
+}
+
+func findAction(n *NCR, actionID int64) *Action {
+	i := slices.IndexFunc(n.Actions, func(a *Action) bool { return a.ID == actionID })
+	if i < 0 {
+		return nil
+	}
+	return n.Actions[i]
+}
+
+func unique(ids []int64) []int64 {
+	var out []int64
+	for _, id := range ids {
+		if !slices.Contains(out, id) {
+			out = append(out, id)
+		}
+	}
+	return out
+}
+
+// today начало календарного дня t в UTC; сроки действий хранятся датой
+func today(t time.Time) time.Time {
+	y, m, d := t.Date()
+	return time.Date(y, m, d, 0, 0, 0, 0, time.UTC)
+}
@@ -0,0 +1,137 @@
+package notification
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+	"strconv"
+
+	"mes-lite-back/internal/http/middleware"
+	"mes-lite-back/pkg"
+
+	"github.com/go-chi/chi/v5"
+)
+
+type Handler struct {
+	service ServiceInterface
+}
+
+func NewHandler(service ServiceInterface) *Handler {
+	return &Handler{service: service}
+}
+
+// Routes уведомления текущего пользователя: отдельные права не нужны,
+// каждый видит только свои уведомления
+func (h *Handler) Routes() chi.Router {
+	r := chi.NewRouter()
+
+	r.Get("/", h.list)
+	r.Get("/unread-count", h.unreadCount)
+	r.Post("/read-all", h.markAllRead)
+	r.Post("/{id}/read", h.markRead)
+
+	return r
+}
+
+type ErrorResponse struct {
+	Error string `json:"error" example:"Описание ошибки"`
+}
+
+type UnreadCountResponse struct {
+	Unread int64 `json:"unread" example:"3"`
+}
+
+// ListNotifications godoc
+// @Summary Уведомления текущего пользователя
+// @Tags notifications
+// @Security BearerAuth
+// @Produce json
+// @Param unread query bool false "Только непрочитанные"
+// @Param limit query int false "Количество записей (не более 200)"
+// @Param offset query int false "Смещение"
+// @Success 200 {array} Notification
+// @Failure 401 {object} ErrorResponse
+// @Router /notifications [get]
+func (h *Handler) list(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	unread, _ := strconv.ParseBool(query.Get("unread"))
+	limit, _ := strconv.Atoi(query.Get("limit"))
+	offset, _ := strconv.Atoi(query.Get("offset"))
+
+	notifications, err := h.service.List(ListFilter{
+		UserID:     currentUser(r),
+		UnreadOnly: unread,
+		Limit:      limit,
+		Offset:     offset,
+	})
+	if err != nil {
+		h.respondError(w, err)
+		return
+	}
+	pkg.RespondJSON(w, http.StatusOK, notifications)
+}
+
+// UnreadNotificationCount godoc
+// @Summary Количество непрочитанных уведомлений
+// @Tags notifications
+// @Security BearerAuth
+// @Produce json
+// @Success 200 {object} UnreadCountResponse
+// @Failure 401 {object} ErrorResponse
+// @Router /notifications/unread-count [get]
+func (h *Handler) unreadCount(w http.ResponseWriter, r *http.Request) {
+	n, err := h.service.CountUnread(currentUser(r))
+	if err != nil {
+		h.respondError(w, err)
+		return
+	}
+	pkg.RespondJSON(w, http.StatusOK, UnreadCountResponse{Unread: n})
+}
+
+// MarkNotificationRead godoc
+// @Summary Отметить уведомление прочитанным
+// @Tags notifications
+// @Security BearerAuth
+// @Param id path int true "ID уведомления"
+// @Success 204
+// @Failure 404 {object} ErrorResponse
+// @Router /notifications/{id}/read [post]
+func (h *Handler) markRead(w http.ResponseWriter, r *http.Request) {
+	if err := h.service.MarkRead(pkg.ParamID(r), currentUser(r)); err != nil {
+		h.respondError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// MarkAllNotificationsRead godoc
+// @Summary Отметить все уведомления прочитанными
+// @Tags notifications
+// @Security BearerAuth
+// @Success 204
+// @Failure 401 {object} ErrorResponse
+// @Router /notifications/read-all [post]
+func (h *Handler) markAllRead(w http.ResponseWriter, r *http.Request) {
+	if err := h.service.MarkAllRead(currentUser(r)); err != nil {
+		h.respondError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func currentUser(r *http.Request) int64 {
+	id, _ := middleware.UserIDFromContext(r.Context())
+	return id
+}
+
+func (h *Handler) respondError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, ErrNotFound):
+		pkg.RespondJSON(w, http.StatusNotFound, ErrorResponse{Error: "Уведомление не найдено"})
+	case errors.Is(err, ErrNoUser):
+		pkg.RespondJSON(w, http.StatusUnauthorized, ErrorResponse{Error: "Не удалось определить пользователя"})
+	default:
+		slog.Error("notification request failed", slog.Any("err", err))
+		pkg.RespondJSON(w, http.StatusInternalServerError, ErrorResponse{Error: "Внутренняя ошибка сервера"})
+	}
+}
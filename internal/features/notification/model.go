@@ -0,0 +1,47 @@
+package notification
+
+import "time"
+
+// Типы уведомлений
+const (
+	KindMention    = "mention"
+	KindReschedule = "reschedule"
+	KindEscalation = "escalation"
+	KindCAPA       = "capa"
+)
+
+// Notification уведомление пользователя внутри приложения
+type Notification struct {
+	ID         int64      `gorm:"primaryKey;autoIncrement" json:"id"`
+	UserID     int64      `json:"user_id"`
+	Kind       string     `json:"kind"`
+	Title      string     `json:"title"`
+	Body       string     `json:"body,omitempty"`
+	EntityType string     `json:"entity_type,omitempty"`
+	EntityID   *int64     `json:"entity_id,omitempty"`
+	CreatedBy  *int64     `json:"created_by,omitempty"`
+	CreatedAt  time.Time  `gorm:"autoCreateTime" json:"created_at"`
+	ReadAt     *time.Time `json:"read_at,omitempty"`
+}
+
+func (Notification) TableName() string {
+	return "notifications"
+}
+
+// Message содержимое уведомления, рассылаемого нескольким получателям
+type Message struct {
+	Kind       string
+	Title      string
+	Body       string
+	EntityType string
+	EntityID   int64
+	CreatedBy  *int64
+}
+
+// ListFilter параметры выборки уведомлений пользователя
+type ListFilter struct {
+	UserID     int64
+	UnreadOnly bool
+	Limit      int
+	Offset     int
+}
@@ -0,0 +1,11 @@
+package notification
+
+type Repository interface {
+	CreateBatch(notifications []*Notification) error
+	List(filter ListFilter) ([]*Notification, error)
+	CountUnread(userID int64) (int64, error)
+
+	// MarkRead отмечает прочитанным уведомление пользователя; false — уведомление не найдено
+	MarkRead(id, userID int64) (bool, error)
+	MarkAllRead(userID int64) error
+}
@@ -0,0 +1,57 @@
+package notification
+
+import (
+	"gorm.io/gorm"
+)
+
+type GormRepository struct {
+	db *gorm.DB
+}
+
+func NewGormRepository(db *gorm.DB) *GormRepository {
+	return &GormRepository{db: db}
+}
+
+func (r *GormRepository) CreateBatch(notifications []*Notification) error {
+	return r.db.Create(notifications).Error
+}
+
+func (r *GormRepository) List(filter ListFilter) ([]*Notification, error) {
+	var notifications []*Notification
+
+	q := r.db.Where("user_id = ?", filter.UserID).Order("created_at DESC, id DESC")
+	if filter.UnreadOnly {
+		q = q.Where("read_at IS NULL")
+	}
+	if filter.Limit > 0 {
+		q = q.Limit(filter.Limit)
+	}
+	if filter.Offset > 0 {
+		q = q.Offset(filter.Offset)
+	}
+
+	return notifications, q.Find(&notifications).Error
+}
+
+func (r *GormRepository) CountUnread(userID int64) (int64, error) {
+	var n int64
+	err := r.db.Model(&Notification{}).
+		Where("user_id = ? AND read_at IS NULL", userID).
+		Count(&n).
+		Error
+	return n, err
+}
+
+func (r *GormRepository) MarkRead(id, userID int64) (bool, error) {
+	res := r.db.Model(&Notification{}).
+		Where("id = ? AND user_id = ?", id, userID).
+		Update("read_at", gorm.Expr("COALESCE(read_at, NOW())"))
+	return res.RowsAffected > 0, res.Error
+}
+
+func (r *GormRepository) MarkAllRead(userID int64) error {
+	return r.db.Model(&Notification{}).
+		Where("user_id = ? AND read_at IS NULL", userID).
+		Update("read_at", gorm.Expr("NOW()")).
+		Error
+}
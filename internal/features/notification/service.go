@@ -0,0 +1,97 @@
+package notification
+
+import (
+	"errors"
+	"slices"
+)
+
+// maxLimit ограничивает размер страницы уведомлений
+const maxLimit = 200
+
+var (
+	ErrNotFound = errors.New("notification not found")
+	ErrNoUser   = errors.New("notification recipient is unknown")
+)
+
+// ServiceInterface определяет методы, используемые handler’ом
+type ServiceInterface interface {
+	List(filter ListFilter) ([]*Notification, error)
+	CountUnread(userID int64) (int64, error)
+	MarkRead(id, userID int64) error
+	MarkAllRead(userID int64) error
+}
+
+type Service struct {
+	repo Repository
+}
+
+func NewService(repo Repository) *Service {
+	return &Service{repo: repo}
+}
+
+// Notify рассылает сообщение получателям; повторы в userIDs отбрасываются
+func (s *Service) Notify(userIDs []int64, msg Message) error {
+	ids := slices.Clone(userIDs)
+	slices.Sort(ids)
+	ids = slices.Compact(ids)
+	if len(ids) == 0 {
+		return nil
+	}
+
+	var entityID *int64
+	if msg.EntityID > 0 {
+		entityID = &msg.EntityID
+	}
+
+	notifications := make([]*Notification, 0, len(ids))
+	for _, id := range ids {
+		notifications = append(notifications, &Notification{
+			UserID:     id,
+			Kind:       msg.Kind,
+			Title:      msg.Title,
+			Body:       msg.Body,
+			EntityType: msg.EntityType,
+			EntityID:   entityID,
+			CreatedBy:  msg.CreatedBy,
+		})
+	}
+	return s.repo.CreateBatch(notifications)
+}
+
+func (s *Service) List(filter ListFilter) ([]*Notification, error) {
+	if filter.UserID <= 0 {
+		return nil, ErrNoUser
+	}
+	if filter.Limit <= 0 || filter.Limit > maxLimit {
+		filter.Limit = maxLimit
+	}
+	return s.repo.List(filter)
+}
+
+func (s *Service) CountUnread(userID int64) (int64, error) {
+	if userID <= 0 {
+		return 0, ErrNoUser
+	}
+	return s.repo.CountUnread(userID)
+}
+
+func (s *Service) MarkRead(id, userID int64) error {
+	if userID <= 0 {
+		return ErrNoUser
+	}
+	ok, err := s.repo.MarkRead(id, userID)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return ErrNotFound
+	}
+	return nil
+}
+
+func (s *Service) MarkAllRead(userID int64) error {
+	if userID <= 0 {
+		return ErrNoUser
+	}
+	return s.repo.MarkAllRead(userID)
+}
@@ -8,4 +8,6 @@ type Repository interface {
 	GetPermissionById(id int64) (*Permission, error)
 	GetPermissionByName(name string) (*Permission, error)
 	List() ([]*Permission, error)
+
+	HasPermission(userID int64, code string) (bool, error)
 }
@@ -44,3 +44,10 @@ func (r *GormRepository) List() ([]*Permission, error) {
 	var perms []*Permission
 	return perms, r.db.Find(&perms).Error
 }
+
+// HasPermission использует SQL-функцию has_permission из миграции 0003
+func (r *GormRepository) HasPermission(userID int64, code string) (bool, error) {
+	var ok bool
+	err := r.db.Raw("SELECT has_permission(?, ?)", userID, code).Scan(&ok).Error
+	return ok, err
+}
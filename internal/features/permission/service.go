@@ -45,3 +45,7 @@ func (s *Service) DeletePermission(id int64) error {
 	}
 	return s.repo.Delete(p)
 }
+
+func (s *Service) HasPermission(userID int64, code string) (bool, error) {
+	return s.repo.HasPermission(userID, code)
+}
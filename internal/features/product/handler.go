@@ -0,0 +1,238 @@
+package product
+
+import (
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strconv"
+
+	"mes-lite-back/internal/http/middleware"
+	"mes-lite-back/pkg"
+
+	"github.com/go-chi/chi/v5"
+)
+
+type Handler struct {
+	service ServiceInterface
+	perms   middleware.PermissionChecker
+}
+
+func NewHandler(service ServiceInterface, perms middleware.PermissionChecker) *Handler {
+	return &Handler{
+		service: service,
+		perms:   perms,
+	}
+}
+
+func (h *Handler) Routes() chi.Router {
+	r := chi.NewRouter()
+
+	view := middleware.PermissionGuard(h.perms, "product.view")
+	edit := middleware.PermissionGuard(h.perms, "product.edit")
+	del := middleware.PermissionGuard(h.perms, "product.delete")
+
+	r.With(view).Get("/", h.list)
+	r.With(view).Get("/{id}", h.getByID)
+	r.With(edit).Post("/", h.create)
+	r.With(edit).Put("/{id}", h.update)
+	r.With(del).Delete("/{id}", h.delete)
+
+	return r
+}
+
+type CreateRequest struct {
+	Name         string `json:"name" example:"Корпус редуктора"`
+	SKU          string `json:"sku" example:"GB-HOUSING-01"`
+	TechCycleMin *int   `json:"tech_cycle_min,omitempty" example:"45"`
+}
+
+type ErrorResponse struct {
+	Error string `json:"error" example:"Описание ошибки"`
+}
+
+// ListProducts godoc
+// @Summary Получить список продукции
+// @Description Возвращает список продукции с поиском по названию или SKU
+// @Tags products
+// @Security BearerAuth
+// @Produce json
+// @Param q query string false "Поиск по названию или SKU"
+// @Param limit query int false "Количество записей"
+// @Param offset query int false "Смещение"
+// @Success 200 {array} Product
+// @Failure 500 {object} ErrorResponse
+// @Router /products [get]
+func (h *Handler) list(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	limit, _ := strconv.Atoi(query.Get("limit"))
+	offset, _ := strconv.Atoi(query.Get("offset"))
+
+	products, err := h.service.ListProducts(ListFilter{
+		Query:  query.Get("q"),
+		Limit:  limit,
+		Offset: offset,
+	})
+	if err != nil {
+		slog.Error("list products failed", slog.Any("err", err))
+		pkg.RespondJSON(w, http.StatusInternalServerError, ErrorResponse{Error: "Не удалось получить список продукции"})
+		return
+	}
+
+	pkg.RespondJSON(w, http.StatusOK, products)
+}
+
+// GetProduct godoc
+// @Summary Получить продукт по ID
+// @Description Возвращает карточку продукта
+// @Tags products
+// @Security BearerAuth
+// @Produce json
+// @Param id path int true "ID продукта"
+// @Success 200 {object} Product
+// @Failure 400 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /products/{id} [get]
+func (h *Handler) getByID(w http.ResponseWriter, r *http.Request) {
+	id := pkg.ParamID(r)
+	if id == 0 {
+		pkg.RespondJSON(w, http.StatusBadRequest, ErrorResponse{Error: "Некорректный ID продукта"})
+		return
+	}
+
+	p, err := h.service.GetProduct(id)
+	if err != nil {
+		h.respondError(w, err)
+		return
+	}
+
+	pkg.RespondJSON(w, http.StatusOK, p)
+}
+
+// CreateProduct godoc
+// @Summary Создать продукт
+// @Description Создает новый продукт; автором становится текущий пользователь
+// @Tags products
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param request body CreateRequest true "Данные продукта"
+// @Success 201 {object} Product
+// @Failure 400 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /products [post]
+func (h *Handler) create(w http.ResponseWriter, r *http.Request) {
+	var req CreateRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		pkg.RespondJSON(w, http.StatusBadRequest, ErrorResponse{Error: "Некорректный JSON"})
+		return
+	}
+
+	p := &Product{
+		Name:         req.Name,
+		SKU:          req.SKU,
+		TechCycleMin: req.TechCycleMin,
+	}
+	if userID, ok := middleware.UserIDFromContext(r.Context()); ok {
+		p.CreatedBy = &userID
+	}
+
+	if err := h.service.CreateProduct(p); err != nil {
+		h.respondError(w, err)
+		return
+	}
+
+	pkg.RespondJSON(w, http.StatusCreated, p)
+}
+
+// UpdateProduct godoc
+// @Summary Обновить продукт
+// @Description Обновляет название, SKU и время такта продукта
+// @Tags products
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param id path int true "ID продукта"
+// @Param request body CreateRequest true "Данные продукта"
+// @Success 200 {object} Product
+// @Failure 400 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /products/{id} [put]
+func (h *Handler) update(w http.ResponseWriter, r *http.Request) {
+	id := pkg.ParamID(r)
+	if id == 0 {
+		pkg.RespondJSON(w, http.StatusBadRequest, ErrorResponse{Error: "Некорректный ID продукта"})
+		return
+	}
+
+	var req CreateRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		pkg.RespondJSON(w, http.StatusBadRequest, ErrorResponse{Error: "Некорректный JSON"})
+		return
+	}
+
+	p := &Product{
+		ID:           id,
+		Name:         req.Name,
+		SKU:          req.SKU,
+		TechCycleMin: req.TechCycleMin,
+	}
+
+	if err := h.service.UpdateProduct(p); err != nil {
+		h.respondError(w, err)
+		return
+	}
+
+	pkg.RespondJSON(w, http.StatusOK, p)
+}
+
+// DeleteProduct godoc
+// @Summary Удалить продукт
+// @Description Удаляет продукт вместе с маршрутом, если на него не ссылаются заказы, экземпляры и результаты контроля
+// @Tags products
+// @Security BearerAuth
+// @Param id path int true "ID продукта"
+// @Success 204
+// @Failure 400 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /products/{id} [delete]
+func (h *Handler) delete(w http.ResponseWriter, r *http.Request) {
+	id := pkg.ParamID(r)
+	if id == 0 {
+		pkg.RespondJSON(w, http.StatusBadRequest, ErrorResponse{Error: "Некорректный ID продукта"})
+		return
+	}
+
+	if err := h.service.DeleteProduct(id); err != nil {
+		h.respondError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *Handler) respondError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, ErrNotFound):
+		pkg.RespondJSON(w, http.StatusNotFound, ErrorResponse{Error: "Продукт не найден"})
+	case errors.Is(err, ErrNameEmpty):
+		pkg.RespondJSON(w, http.StatusBadRequest, ErrorResponse{Error: "Название продукта обязательно"})
+	case errors.Is(err, ErrInvalidSKU):
+		pkg.RespondJSON(w, http.StatusBadRequest, ErrorResponse{Error: "SKU может содержать только латинские буквы, цифры, '.', '_' и '-'"})
+	case errors.Is(err, ErrInvalidTime):
+		pkg.RespondJSON(w, http.StatusBadRequest, ErrorResponse{Error: "Время такта должно быть положительным"})
+	case errors.Is(err, ErrSKUTaken):
+		pkg.RespondJSON(w, http.StatusConflict, ErrorResponse{Error: "Продукт с таким SKU уже существует"})
+	case errors.Is(err, ErrInUse):
+		pkg.RespondJSON(w, http.StatusConflict, ErrorResponse{Error: "Продукт используется в заказах, экземплярах или результатах контроля"})
+	default:
+		slog.Error("product request failed", slog.Any("err", err))
+		pkg.RespondJSON(w, http.StatusInternalServerError, ErrorResponse{Error: "Внутренняя ошибка сервера"})
+	}
+}
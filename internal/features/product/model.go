@@ -0,0 +1,21 @@
+package product
+
+type Product struct {
+	ID           int64  `gorm:"primaryKey;autoIncrement" json:"id"`
+	Name         string `gorm:"not null" json:"name"`
+	SKU          string `gorm:"column:sku;unique;not null" json:"sku"`
+	TechCycleMin *int   `json:"tech_cycle_min,omitempty"`
+	CreatedBy    *int64 `json:"created_by,omitempty"`
+}
+
+func (Product) TableName() string {
+	return "products"
+}
+
+// ListFilter параметры поиска продукции
+type ListFilter struct {
+	// Query ищет по вхождению в name или sku без учёта регистра
+	Query  string
+	Limit  int
+	Offset int
+}
@@ -0,0 +1,14 @@
+package product
+
+type Repository interface {
+	Create(p *Product) error
+	Update(p *Product) error
+	Delete(p *Product) error
+
+	GetByID(id int64) (*Product, error)
+	GetBySKU(sku string) (*Product, error)
+	List(filter ListFilter) ([]*Product, error)
+
+	// CountReferences возвращает число заказов, экземпляров и результатов контроля, ссылающихся на продукт
+	CountReferences(id int64) (int64, error)
+}
@@ -0,0 +1,104 @@
+package product
+
+import (
+	"errors"
+
+	"github.com/jackc/pgx/v5/pgconn"
+	"gorm.io/gorm"
+)
+
+const pgForeignKeyViolation = "23503"
+
+type GormRepository struct {
+	db *gorm.DB
+}
+
+func NewGormRepository(db *gorm.DB) *GormRepository {
+	return &GormRepository{db: db}
+}
+
+func (r *GormRepository) Create(p *Product) error {
+	return r.db.Create(p).Error
+}
+
+func (r *GormRepository) Update(p *Product) error {
+	return r.db.Save(p).Error
+}
+
+// Delete удаляет продукт вместе с его маршрутом (версиями и этапами);
+// ссылка, появившаяся после проверки CountReferences, возвращает ErrInUse
+func (r *GormRepository) Delete(p *Product) error {
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Exec("DELETE FROM product_stages WHERE product_id = ?", p.ID).Error; err != nil {
+			return err
+		}
+		if err := tx.Exec("DELETE FROM routing_versions WHERE product_id = ?", p.ID).Error; err != nil {
+			return err
+		}
+		return tx.Delete(p).Error
+	})
+
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && pgErr.Code == pgForeignKeyViolation {
+		return ErrInUse
+	}
+	return err
+}
+
+func (r *GormRepository) GetByID(id int64) (*Product, error) {
+	var p Product
+	if err := r.db.First(&p, id).Error; err != nil {
+		return nil, err
+	}
+	return &p, nil
+}
+
+func (r *GormRepository) GetBySKU(sku string) (*Product, error) {
+	var p Product
+	if err := r.db.Where("sku = ?", sku).First(&p).Error; err != nil {
+		return nil, err
+	}
+	return &p, nil
+}
+
+func (r *GormRepository) List(filter ListFilter) ([]*Product, error) {
+	var products []*Product
+
+	q := r.db.Order("id")
+	if filter.Query != "" {
+		like := "%" + filter.Query + "%"
+		q = q.Where("name ILIKE ? OR sku ILIKE ?", like, like)
+	}
+	if filter.Limit > 0 {
+		q = q.Limit(filter.Limit)
+	}
+	if filter.Offset > 0 {
+		q = q.Offset(filter.Offset)
+	}
+
+	return products, q.Find(&products).Error
+}
+
+// referencingTables производственные данные, при которых продукт удалять нельзя;
+// маршрут удаляется вместе с продуктом
+var referencingTables = []string{
+	"work_orders",
+	"product_instances",
+	"product_quality",
+}
+
+func (r *GormRepository) CountReferences(id int64) (int64, error) {
+	var total int64
+
+	for _, table := range referencingTables {
+		var n int64
+		if err := r.db.Table(table).
+			Where("product_id = ?", id).
+			Count(&n).Error; err != nil {
+			return 0, err
+		}
+		total += n
+	}
+
+	return total, nil
+}
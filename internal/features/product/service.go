@@ -0,0 +1,117 @@
+package product
+
+import (
+	"errors"
+	"regexp"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+var (
+	ErrNotFound    = errors.New("product not found")
+	ErrNameEmpty   = errors.New("product name required")
+	ErrInvalidSKU  = errors.New("invalid sku")
+	ErrSKUTaken    = errors.New("sku already exists")
+	ErrInvalidTime = errors.New("tech cycle must be positive")
+	ErrInUse       = errors.New("product is referenced by work orders, instances or inspections")
+)
+
+// skuPattern: латиница, цифры, '-', '_', '.'; начинается с буквы или цифры
+var skuPattern = regexp.MustCompile(`^[A-Z0-9][A-Z0-9._-]{0,63}$`)
+
+// ServiceInterface определяет методы, используемые handler’ом
+type ServiceInterface interface {
+	CreateProduct(p *Product) error
+	GetProduct(id int64) (*Product, error)
+	ListProducts(filter ListFilter) ([]*Product, error)
+	UpdateProduct(p *Product) error
+	DeleteProduct(id int64) error
+}
+
+type Service struct {
+	repo Repository
+}
+
+func NewService(repo Repository) *Service {
+	return &Service{repo: repo}
+}
+
+func (s *Service) CreateProduct(p *Product) error {
+	if err := s.validate(p); err != nil {
+		return err
+	}
+	return s.repo.Create(p)
+}
+
+func (s *Service) GetProduct(id int64) (*Product, error) {
+	p, err := s.repo.GetByID(id)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrNotFound
+	}
+	return p, err
+}
+
+func (s *Service) ListProducts(filter ListFilter) ([]*Product, error) {
+	filter.Query = strings.TrimSpace(filter.Query)
+	return s.repo.List(filter)
+}
+
+func (s *Service) UpdateProduct(p *Product) error {
+	existing, err := s.GetProduct(p.ID)
+	if err != nil {
+		return err
+	}
+
+	// автор карточки не меняется при редактировании
+	p.CreatedBy = existing.CreatedBy
+
+	if err := s.validate(p); err != nil {
+		return err
+	}
+	return s.repo.Update(p)
+}
+
+func (s *Service) DeleteProduct(id int64) error {
+	p, err := s.GetProduct(id)
+	if err != nil {
+		return err
+	}
+
+	refs, err := s.repo.CountReferences(id)
+	if err != nil {
+		return err
+	}
+	if refs > 0 {
+		return ErrInUse
+	}
+
+	return s.repo.Delete(p)
+}
+
+// validate нормализует SKU и проверяет его уникальность
+func (s *Service) validate(p *Product) error {
+	p.Name = strings.TrimSpace(p.Name)
+	if p.Name == "" {
+		return ErrNameEmpty
+	}
+
+	p.SKU = strings.ToUpper(strings.TrimSpace(p.SKU))
+	if !skuPattern.MatchString(p.SKU) {
+		return ErrInvalidSKU
+	}
+
+	if p.TechCycleMin != nil && *p.TechCycleMin <= 0 {
+		return ErrInvalidTime
+	}
+
+	other, err := s.repo.GetBySKU(p.SKU)
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return err
+	}
+	if other != nil && other.ID != p.ID {
+		return ErrSKUTaken
+	}
+
+	return nil
+}
@@ -0,0 +1,615 @@
+package quality
+
+import (
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"time"
+
+	"mes-lite-back/internal/features/incident"
+	"mes-lite-back/internal/http/middleware"
+	"mes-lite-back/pkg"
+
+	"github.com/go-chi/chi/v5"
+)
+
+type Handler struct {
+	service ServiceInterface
+	perms   middleware.PermissionChecker
+}
+
+func NewHandler(service ServiceInterface, perms middleware.PermissionChecker) *Handler {
+	return &Handler{
+		service: service,
+		perms:   perms,
+	}
+}
+
+func (h *Handler) Routes() chi.Router {
+	r := chi.NewRouter()
+
+	view := middleware.PermissionGuard(h.perms, "quality.view")
+	inspect := middleware.PermissionGuard(h.perms, "quality.inspect")
+	edit := middleware.PermissionGuard(h.perms, "quality.edit")
+	report := middleware.PermissionGuard(h.perms, "incident.create")
+
+	r.With(view).Get("/statuses", h.statuses)
+	r.With(view).Get("/defect-codes", h.defectCodes)
+	r.With(edit).Post("/defect-codes", h.createDefectCode)
+	r.With(edit).Put("/defect-codes/{id}", h.updateDefectCode)
+	r.With(view).Get("/plans", h.listPlans)
+	r.With(edit).Post("/plans", h.createPlan)
+	r.With(view).Get("/plans/{id}", h.getPlan)
+	r.With(edit).Put("/plans/{id}", h.updatePlan)
+	r.With(edit).Delete("/plans/{id}", h.deletePlan)
+	r.With(view).Get("/inspections", h.list)
+	r.With(view).Get("/inspections/{id}", h.getByID)
+	r.With(inspect).Post("/inspections", h.inspect)
+	r.With(view).Get("/spc", h.controlChart)
+	r.With(view, report).Post("/spc/check", h.checkControlChart)
+
+	return r
+}
+
+type DefectCodeRequest struct {
+	Code        string `json:"code" example:"SCRATCH"`
+	Name        string `json:"name" example:"Царапина"`
+	Description string `json:"description,omitempty"`
+	Active      *bool  `json:"active,omitempty" example:"true"`
+}
+
+func (req DefectCodeRequest) defectCode() *DefectCode {
+	active := true
+	if req.Active != nil {
+		active = *req.Active
+	}
+	return &DefectCode{
+		Code:        req.Code,
+		Name:        req.Name,
+		Description: req.Description,
+		Active:      active,
+	}
+}
+
+type PlanRequest struct {
+	ProductID       int64             `json:"product_id" example:"1"`
+	StageID         int64             `json:"stage_id" example:"2"`
+	Name            string            `json:"name,omitempty" example:"Контроль после сварки"`
+	Mandatory       *bool             `json:"mandatory,omitempty" example:"true"`
+	Active          *bool             `json:"active,omitempty" example:"true"`
+	Characteristics []*Characteristic `json:"characteristics"`
+}
+
+func (req PlanRequest) plan() *Plan {
+	mandatory, active := true, true
+	if req.Mandatory != nil {
+		mandatory = *req.Mandatory
+	}
+	if req.Active != nil {
+		active = *req.Active
+	}
+	return &Plan{
+		ProductID:       req.ProductID,
+		StageID:         req.StageID,
+		Name:            req.Name,
+		Mandatory:       mandatory,
+		Active:          active,
+		Characteristics: req.Characteristics,
+	}
+}
+
+// SPCCheckRequest параметры карты и серьезность инцидента по нарушениям (по умолчанию medium)
+type SPCCheckRequest struct {
+	SPCQuery
+	SeverityID int `json:"severity_id,omitempty" example:"2"`
+}
+
+type ErrorResponse struct {
+	Error string `json:"error" example:"Описание ошибки"`
+}
+
+// QualityStatuses godoc
+// @Summary Справочник результатов контроля
+// @Tags quality
+// @Security BearerAuth
+// @Produce json
+// @Success 200 {array} Status
+// @Router /quality/statuses [get]
+func (h *Handler) statuses(w http.ResponseWriter, r *http.Request) {
+	statuses, err := h.service.Statuses()
+	if err != nil {
+		h.respondError(w, err)
+		return
+	}
+	pkg.RespondJSON(w, http.StatusOK, statuses)
+}
+
+// DefectCodes godoc
+// @Summary Справочник кодов дефектов
+// @Tags quality
+// @Security BearerAuth
+// @Produce json
+// @Param active query bool false "Только активные коды"
+// @Success 200 {array} DefectCode
+// @Router /quality/defect-codes [get]
+func (h *Handler) defectCodes(w http.ResponseWriter, r *http.Request) {
+	activeOnly, _ := strconv.ParseBool(r.URL.Query().Get("active"))
+
+	codes, err := h.service.DefectCodes(activeOnly)
+	if err != nil {
+		h.respondError(w, err)
+		return
+	}
+	pkg.RespondJSON(w, http.StatusOK, codes)
+}
+
+// CreateDefectCode godoc
+// @Summary Добавить код дефекта
+// @Description Код приводится к верхнему регистру
+// @Tags quality
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param request body DefectCodeRequest true "Код дефекта"
+// @Success 201 {object} DefectCode
+// @Failure 400 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse
+// @Router /quality/defect-codes [post]
+func (h *Handler) createDefectCode(w http.ResponseWriter, r *http.Request) {
+	var req DefectCodeRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		pkg.RespondJSON(w, http.StatusBadRequest, ErrorResponse{Error: "Некорректный JSON"})
+		return
+	}
+
+	c := req.defectCode()
+	if err := h.service.CreateDefectCode(c); err != nil {
+		h.respondError(w, err)
+		return
+	}
+	pkg.RespondJSON(w, http.StatusCreated, c)
+}
+
+// UpdateDefectCode godoc
+// @Summary Изменить код дефекта
+// @Description Вместо удаления код отключается (active=false); записанные результаты контроля не меняются
+// @Tags quality
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param id path int true "ID кода дефекта"
+// @Param request body DefectCodeRequest true "Код дефекта"
+// @Success 200 {object} DefectCode
+// @Failure 400 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse
+// @Router /quality/defect-codes/{id} [put]
+func (h *Handler) updateDefectCode(w http.ResponseWriter, r *http.Request) {
+	var req DefectCodeRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		pkg.RespondJSON(w, http.StatusBadRequest, ErrorResponse{Error: "Некорректный JSON"})
+		return
+	}
+
+	c := req.defectCode()
+	c.ID = int(pkg.ParamID(r))
+	if err := h.service.UpdateDefectCode(c); err != nil {
+		h.respondError(w, err)
+		return
+	}
+	pkg.RespondJSON(w, http.StatusOK, c)
+}
+
+// ListInspections godoc
+// @Summary Получить результаты контроля
+// @Description Новые первыми; фильтры по заказу, продукту, экземпляру, результату, этапу, машине, дефекту, контролеру и периоду
+// @Tags quality
+// @Security BearerAuth
+// @Produce json
+// @Param work_order_id query int false "ID заказа"
+// @Param product_id query int false "ID продукта"
+// @Param instance_id query int false "ID экземпляра"
+// @Param barcode query string false "Штрихкод экземпляра"
+// @Param status query string false "Результат: passed, failed, conditional"
+// @Param stage_id query int false "ID этапа"
+// @Param machine_id query int false "ID машины"
+// @Param defect_code_id query int false "ID кода дефекта"
+// @Param inspected_by query int false "ID контролера"
+// @Param from query string false "Начало периода (RFC3339 или ГГГГ-ММ-ДД)"
+// @Param to query string false "Конец периода (RFC3339 или ГГГГ-ММ-ДД включительно)"
+// @Param limit query int false "Размер страницы (не более 200)"
+// @Param offset query int false "Смещение"
+// @Success 200 {array} Inspection
+// @Failure 400 {object} ErrorResponse
+// @Router /quality/inspections [get]
+func (h *Handler) list(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	workOrderID, _ := strconv.ParseInt(query.Get("work_order_id"), 10, 64)
+	productID, _ := strconv.ParseInt(query.Get("product_id"), 10, 64)
+	instanceID, _ := strconv.ParseInt(query.Get("instance_id"), 10, 64)
+	stageID, _ := strconv.ParseInt(query.Get("stage_id"), 10, 64)
+	machineID, _ := strconv.ParseInt(query.Get("machine_id"), 10, 64)
+	defectCodeID, _ := strconv.Atoi(query.Get("defect_code_id"))
+	inspectedBy, _ := strconv.ParseInt(query.Get("inspected_by"), 10, 64)
+	limit, _ := strconv.Atoi(query.Get("limit"))
+	offset, _ := strconv.Atoi(query.Get("offset"))
+
+	from, ok := parseBound(w, query.Get("from"), false)
+	if !ok {
+		return
+	}
+	to, ok := parseBound(w, query.Get("to"), true)
+	if !ok {
+		return
+	}
+
+	inspections, err := h.service.List(ListFilter{
+		WorkOrderID:  workOrderID,
+		ProductID:    productID,
+		InstanceID:   instanceID,
+		Barcode:      query.Get("barcode"),
+		Status:       query.Get("status"),
+		StageID:      stageID,
+		MachineID:    machineID,
+		DefectCodeID: defectCodeID,
+		InspectedBy:  inspectedBy,
+		From:         from,
+		To:           to,
+		Limit:        limit,
+		Offset:       offset,
+	})
+	if err != nil {
+		h.respondError(w, err)
+		return
+	}
+	pkg.RespondJSON(w, http.StatusOK, inspections)
+}
+
+// GetInspection godoc
+// @Summary Получить результат контроля по ID
+// @Tags quality
+// @Security BearerAuth
+// @Produce json
+// @Param id path int true "ID результата контроля"
+// @Success 200 {object} Inspection
+// @Failure 404 {object} ErrorResponse
+// @Router /quality/inspections/{id} [get]
+func (h *Handler) getByID(w http.ResponseWriter, r *http.Request) {
+	i, err := h.service.Get(pkg.ParamID(r))
+	if err != nil {
+		h.respondError(w, err)
+		return
+	}
+	pkg.RespondJSON(w, http.StatusOK, i)
+}
+
+// RecordInspection godoc
+// @Summary Записать результат контроля
+// @Description Экземпляр ищется по штрихкоду, контролер — текущий пользователь, машина по умолчанию — машина заказа. Для failed и conditional нужен хотя бы один код дефекта, для passed дефекты не указываются. С stage_id результат вычисляется по измерениям плана контроля этапа, status не указывается
+// @Tags quality
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param request body Record true "Результат контроля"
+// @Success 201 {object} Inspection
+// @Failure 400 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Router /quality/inspections [post]
+func (h *Handler) inspect(w http.ResponseWriter, r *http.Request) {
+	var req Record
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		pkg.RespondJSON(w, http.StatusBadRequest, ErrorResponse{Error: "Некорректный JSON"})
+		return
+	}
+
+	i, err := h.service.Inspect(req, currentUser(r))
+	if err != nil {
+		h.respondError(w, err)
+		return
+	}
+	pkg.RespondJSON(w, http.StatusCreated, i)
+}
+
+// ListInspectionPlans godoc
+// @Summary Планы контроля
+// @Tags quality
+// @Security BearerAuth
+// @Produce json
+// @Param product_id query int false "ID продукта"
+// @Param stage_id query int false "ID этапа"
+// @Success 200 {array} Plan
+// @Router /quality/plans [get]
+func (h *Handler) listPlans(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	productID, _ := strconv.ParseInt(query.Get("product_id"), 10, 64)
+	stageID, _ := strconv.ParseInt(query.Get("stage_id"), 10, 64)
+
+	plans, err := h.service.ListPlans(PlanFilter{ProductID: productID, StageID: stageID})
+	if err != nil {
+		h.respondError(w, err)
+		return
+	}
+	pkg.RespondJSON(w, http.StatusOK, plans)
+}
+
+// GetInspectionPlan godoc
+// @Summary Получить план контроля по ID
+// @Tags quality
+// @Security BearerAuth
+// @Produce json
+// @Param id path int true "ID плана"
+// @Success 200 {object} Plan
+// @Failure 404 {object} ErrorResponse
+// @Router /quality/plans/{id} [get]
+func (h *Handler) getPlan(w http.ResponseWriter, r *http.Request) {
+	p, err := h.service.GetPlan(pkg.ParamID(r))
+	if err != nil {
+		h.respondError(w, err)
+		return
+	}
+	pkg.RespondJSON(w, http.StatusOK, p)
+}
+
+// CreateInspectionPlan godoc
+// @Summary Добавить план контроля этапа
+// @Description Один план на этап маршрута продукта. Характеристики: numeric (nominal, tol_minus, tol_plus), boolean (expected, по умолчанию true), choice (options с признаком accepted). Обязательный план не пускает экземпляр на следующие этапы без годного результата
+// @Tags quality
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param request body PlanRequest true "План контроля"
+// @Success 201 {object} Plan
+// @Failure 400 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse
+// @Router /quality/plans [post]
+func (h *Handler) createPlan(w http.ResponseWriter, r *http.Request) {
+	var req PlanRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		pkg.RespondJSON(w, http.StatusBadRequest, ErrorResponse{Error: "Некорректный JSON"})
+		return
+	}
+
+	p := req.plan()
+	if err := h.service.CreatePlan(p); err != nil {
+		h.respondError(w, err)
+		return
+	}
+	pkg.RespondJSON(w, http.StatusCreated, p)
+}
+
+// UpdateInspectionPlan godoc
+// @Summary Изменить план контроля
+// @Description Характеристики с id изменяются и остаются на своих контрольных картах, без id — добавляются, не переданные — удаляются; записанные измерения хранят копии прежних характеристик
+// @Tags quality
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param id path int true "ID плана"
+// @Param request body PlanRequest true "План контроля"
+// @Success 200 {object} Plan
+// @Failure 400 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse
+// @Router /quality/plans/{id} [put]
+func (h *Handler) updatePlan(w http.ResponseWriter, r *http.Request) {
+	var req PlanRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		pkg.RespondJSON(w, http.StatusBadRequest, ErrorResponse{Error: "Некорректный JSON"})
+		return
+	}
+
+	p := req.plan()
+	p.ID = pkg.ParamID(r)
+	if err := h.service.UpdatePlan(p); err != nil {
+		h.respondError(w, err)
+		return
+	}
+	pkg.RespondJSON(w, http.StatusOK, p)
+}
+
+// DeleteInspectionPlan godoc
+// @Summary Удалить план контроля
+// @Description Результаты контроля по плану сохраняются
+// @Tags quality
+// @Security BearerAuth
+// @Param id path int true "ID плана"
+// @Success 204
+// @Failure 404 {object} ErrorResponse
+// @Router /quality/plans/{id} [delete]
+func (h *Handler) deletePlan(w http.ResponseWriter, r *http.Request) {
+	if err := h.service.DeletePlan(pkg.ParamID(r)); err != nil {
+		h.respondError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// ControlChart godoc
+// @Summary Контрольная карта характеристики
+// @Description Карта по числовым измерениям характеристики плана за период, по одной машине или по всем. Тип individuals (I-MR), xbar-r или xbar-s; без chart выбирается по subgroup_size (по умолчанию 5). Возвращает границы, Cp/Cpk и Pp/Ppk по допуску и нарушения правил Western Electric
+// @Tags quality
+// @Security BearerAuth
+// @Produce json
+// @Param characteristic_id query int true "ID характеристики плана"
+// @Param machine_id query int false "ID машины"
+// @Param from query string true "Начало периода (RFC3339 или ГГГГ-ММ-ДД)"
+// @Param to query string true "Конец периода (RFC3339 или ГГГГ-ММ-ДД включительно)"
+// @Param chart query string false "Тип карты: individuals, xbar-r, xbar-s"
+// @Param subgroup_size query int false "Размер подгруппы: 1 для individuals, 2–10 для xbar-r, 2–25 для xbar-s"
+// @Success 200 {object} ControlChart
+// @Failure 400 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Router /quality/spc [get]
+func (h *Handler) controlChart(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	characteristicID, _ := strconv.ParseInt(query.Get("characteristic_id"), 10, 64)
+	machineID, _ := strconv.ParseInt(query.Get("machine_id"), 10, 64)
+	subgroupSize, _ := strconv.Atoi(query.Get("subgroup_size"))
+
+	from, ok := parseBound(w, query.Get("from"), false)
+	if !ok {
+		return
+	}
+	to, ok := parseBound(w, query.Get("to"), true)
+	if !ok {
+		return
+	}
+	if from == nil || to == nil {
+		pkg.RespondJSON(w, http.StatusBadRequest, ErrorResponse{Error: "Укажите период from и to"})
+		return
+	}
+
+	cc, err := h.service.ControlChart(SPCQuery{
+		CharacteristicID: characteristicID,
+		MachineID:        machineID,
+		From:             *from,
+		To:               *to,
+		Chart:            query.Get("chart"),
+		SubgroupSize:     subgroupSize,
+	})
+	if err != nil {
+		h.respondError(w, err)
+		return
+	}
+	pkg.RespondJSON(w, http.StatusOK, cc)
+}
+
+// CheckControlChart godoc
+// @Summary Проверить контрольную карту и зарегистрировать инцидент
+// @Description Строит карту как GET /quality/spc. Если в точках после последней проверки с инцидентом есть нарушения правил Western Electric, регистрирует по ним инцидент на машину карты; повторная проверка без новых точек инцидент не создает
+// @Tags quality
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param request body SPCCheckRequest true "Параметры карты"
+// @Success 200 {object} SPCCheck
+// @Failure 400 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Router /quality/spc/check [post]
+func (h *Handler) checkControlChart(w http.ResponseWriter, r *http.Request) {
+	var req SPCCheckRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		pkg.RespondJSON(w, http.StatusBadRequest, ErrorResponse{Error: "Некорректный JSON"})
+		return
+	}
+
+	check, err := h.service.CheckControlChart(req.SPCQuery, req.SeverityID, currentUser(r))
+	if err != nil {
+		h.respondError(w, err)
+		return
+	}
+	pkg.RespondJSON(w, http.StatusOK, check)
+}
+
+// parseBound разбирает границу периода в RFC3339 или ГГГГ-ММ-ДД; пустая строка — без границы.
+// Дата в конце периода включает весь день.
+func parseBound(w http.ResponseWriter, value string, end bool) (*time.Time, bool) {
+	if value == "" {
+		return nil, true
+	}
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return &t, true
+	}
+
+	t, err := time.ParseInLocation(time.DateOnly, value, time.Local)
+	if err != nil {
+		pkg.RespondJSON(w, http.StatusBadRequest, ErrorResponse{Error: "Время должно быть в формате RFC3339 или ГГГГ-ММ-ДД"})
+		return nil, false
+	}
+	if end {
+		t = t.AddDate(0, 0, 1)
+	}
+	return &t, true
+}
+
+func currentUser(r *http.Request) int64 {
+	id, _ := middleware.UserIDFromContext(r.Context())
+	return id
+}
+
+func (h *Handler) respondError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, ErrNotFound):
+		pkg.RespondJSON(w, http.StatusNotFound, ErrorResponse{Error: "Результат контроля не найден"})
+	case errors.Is(err, ErrInstanceNotFound):
+		pkg.RespondJSON(w, http.StatusNotFound, ErrorResponse{Error: "Экземпляр со штрихкодом не найден"})
+	case errors.Is(err, ErrDefectCodeNotFound):
+		pkg.RespondJSON(w, http.StatusNotFound, ErrorResponse{Error: "Код дефекта не найден"})
+	case errors.Is(err, ErrBarcodeRequired):
+		pkg.RespondJSON(w, http.StatusBadRequest, ErrorResponse{Error: "Укажите штрихкод экземпляра"})
+	case errors.Is(err, ErrInvalidStatus):
+		pkg.RespondJSON(w, http.StatusBadRequest, ErrorResponse{Error: "Результат должен быть passed, failed или conditional"})
+	case errors.Is(err, ErrDefectRequired):
+		pkg.RespondJSON(w, http.StatusBadRequest, ErrorResponse{Error: "Для брака и условной годности укажите код дефекта"})
+	case errors.Is(err, ErrDefectsOnPass):
+		pkg.RespondJSON(w, http.StatusBadRequest, ErrorResponse{Error: "Годный результат не может содержать дефектов"})
+	case errors.Is(err, ErrDuplicateDefect):
+		pkg.RespondJSON(w, http.StatusBadRequest, ErrorResponse{Error: "Код дефекта указан дважды"})
+	case errors.Is(err, ErrInvalidQuantity):
+		pkg.RespondJSON(w, http.StatusBadRequest, ErrorResponse{Error: "Количество дефектов должно быть положительным"})
+	case errors.Is(err, ErrDefectCodeInactive):
+		pkg.RespondJSON(w, http.StatusBadRequest, ErrorResponse{Error: "Код дефекта отключен"})
+	case errors.Is(err, ErrCodeRequired):
+		pkg.RespondJSON(w, http.StatusBadRequest, ErrorResponse{Error: "Укажите код дефекта"})
+	case errors.Is(err, ErrNameRequired):
+		pkg.RespondJSON(w, http.StatusBadRequest, ErrorResponse{Error: "Укажите название дефекта"})
+	case errors.Is(err, ErrInvalidPeriod):
+		pkg.RespondJSON(w, http.StatusBadRequest, ErrorResponse{Error: "Конец периода должен быть позже начала"})
+	case errors.Is(err, ErrPlanNotFound):
+		pkg.RespondJSON(w, http.StatusNotFound, ErrorResponse{Error: "План контроля не найден"})
+	case errors.Is(err, ErrProductNotFound):
+		pkg.RespondJSON(w, http.StatusNotFound, ErrorResponse{Error: "Продукт не найден"})
+	case errors.Is(err, ErrStageNotInRouting):
+		pkg.RespondJSON(w, http.StatusBadRequest, ErrorResponse{Error: "Этап не входит в маршрут продукта"})
+	case errors.Is(err, ErrNoCharacteristics):
+		pkg.RespondJSON(w, http.StatusBadRequest, ErrorResponse{Error: "Добавьте в план хотя бы одну характеристику"})
+	case errors.Is(err, ErrCharacteristicName):
+		pkg.RespondJSON(w, http.StatusBadRequest, ErrorResponse{Error: "Укажите название характеристики"})
+	case errors.Is(err, ErrInvalidKind):
+		pkg.RespondJSON(w, http.StatusBadRequest, ErrorResponse{Error: "Тип характеристики должен быть numeric, boolean или choice"})
+	case errors.Is(err, ErrInvalidTolerance):
+		pkg.RespondJSON(w, http.StatusBadRequest, ErrorResponse{Error: "Для числовой характеристики укажите номинал и неотрицательные допуски"})
+	case errors.Is(err, ErrInvalidOptions):
+		pkg.RespondJSON(w, http.StatusBadRequest, ErrorResponse{Error: "Для выбора нужно не меньше двух разных вариантов, хотя бы один допустимый"})
+	case errors.Is(err, ErrStageRequired):
+		pkg.RespondJSON(w, http.StatusBadRequest, ErrorResponse{Error: "Измерения записываются только с этапом, у которого есть план контроля"})
+	case errors.Is(err, ErrStatusWithPlan):
+		pkg.RespondJSON(w, http.StatusBadRequest, ErrorResponse{Error: "Результат контроля по плану вычисляется по измерениям, status не указывается"})
+	case errors.Is(err, ErrUnknownCharacteristic):
+		pkg.RespondJSON(w, http.StatusBadRequest, ErrorResponse{Error: "Характеристика не входит в план контроля"})
+	case errors.Is(err, ErrDuplicateMeasurement):
+		pkg.RespondJSON(w, http.StatusBadRequest, ErrorResponse{Error: "Характеристика измерена дважды"})
+	case errors.Is(err, ErrMeasurementMissing):
+		pkg.RespondJSON(w, http.StatusBadRequest, ErrorResponse{Error: "Не измерена обязательная характеристика"})
+	case errors.Is(err, ErrInvalidMeasurement):
+		pkg.RespondJSON(w, http.StatusBadRequest, ErrorResponse{Error: "Значение не соответствует типу характеристики"})
+	case errors.Is(err, ErrMachineNotFound):
+		pkg.RespondJSON(w, http.StatusNotFound, ErrorResponse{Error: "Машина не найдена"})
+	case errors.Is(err, ErrCharacteristicNotFound):
+		pkg.RespondJSON(w, http.StatusNotFound, ErrorResponse{Error: "Характеристика не найдена"})
+	case errors.Is(err, incident.ErrSeverityNotFound):
+		pkg.RespondJSON(w, http.StatusNotFound, ErrorResponse{Error: "Серьезность инцидента не найдена"})
+	case errors.Is(err, ErrNotNumeric):
+		pkg.RespondJSON(w, http.StatusBadRequest, ErrorResponse{Error: "Контрольная карта строится только по числовой характеристике"})
+	case errors.Is(err, ErrInvalidChart):
+		pkg.RespondJSON(w, http.StatusBadRequest, ErrorResponse{Error: "Тип карты должен быть individuals, xbar-r или xbar-s"})
+	case errors.Is(err, ErrInvalidSubgroup):
+		pkg.RespondJSON(w, http.StatusBadRequest, ErrorResponse{Error: "Размер подгруппы: 1 для individuals, 2–10 для xbar-r, 2–25 для xbar-s"})
+	case errors.Is(err, ErrRangeTooLong):
+		pkg.RespondJSON(w, http.StatusBadRequest, ErrorResponse{Error: "Период карты не должен превышать 400 дней"})
+	case errors.Is(err, ErrNotEnoughData):
+		pkg.RespondJSON(w, http.StatusBadRequest, ErrorResponse{Error: "Недостаточно измерений: нужно хотя бы две полные подгруппы"})
+	case errors.Is(err, ErrTooManySamples):
+		pkg.RespondJSON(w, http.StatusBadRequest, ErrorResponse{Error: "Слишком много измерений, сократите период"})
+	case errors.Is(err, ErrPlanExists):
+		pkg.RespondJSON(w, http.StatusConflict, ErrorResponse{Error: "У этапа уже есть план контроля"})
+	case errors.Is(err, ErrDefectCodeExists):
+		pkg.RespondJSON(w, http.StatusConflict, ErrorResponse{Error: "Код дефекта уже существует"})
+	default:
+		slog.Error("quality request failed", slog.Any("err", err))
+		pkg.RespondJSON(w, http.StatusInternalServerError, ErrorResponse{Error: "Внутренняя ошибка сервера"})
+	}
+}
@@ -0,0 +1,283 @@
+package quality
+
+import "time"
+
+// Результаты контроля (коды справочника quality_statuses)
+const (
+	StatusPassed      = "passed"
+	StatusFailed      = "failed"
+	StatusConditional = "conditional"
+)
+
+// Status результат контроля качества
+type Status struct {
+	ID   int    `gorm:"primaryKey" json:"id"`
+	Code string `json:"code"`
+	Name string `json:"name"`
+}
+
+func (Status) TableName() string {
+	return "quality_statuses"
+}
+
+// DefectCode код дефекта из справочника; неактивные коды не принимаются в новых результатах
+type DefectCode struct {
+	ID          int    `gorm:"primaryKey;autoIncrement" json:"id"`
+	Code        string `json:"code" example:"SCRATCH"`
+	Name        string `json:"name" example:"Царапина"`
+	Description string `json:"description,omitempty"`
+	Active      bool   `gorm:"not null" json:"active" example:"true"`
+}
+
+func (DefectCode) TableName() string {
+	return "defect_codes"
+}
+
+// Inspection результат контроля экземпляра продукции
+type Inspection struct {
+	ID                int64          `gorm:"primaryKey;autoIncrement" json:"id"`
+	ProductInstanceID int64          `json:"product_instance_id"`
+	Barcode           string         `gorm:"->" json:"barcode"`
+	ProductID         *int64         `json:"product_id,omitempty"`
+	WorkOrderID       *int64         `json:"work_order_id,omitempty"`
+	MachineID         *int64         `json:"machine_id,omitempty"`
+	QualityStatusID   int            `json:"quality_status_id"`
+	Status            string         `gorm:"->" json:"status"`
+	Description       string         `json:"description,omitempty"`
+	StageID           *int64         `json:"stage_id,omitempty"`
+	PlanID            *int64         `json:"plan_id,omitempty"`
+	InspectedBy       int64          `json:"inspected_by"`
+	InspectedAt       time.Time      `json:"inspected_at"`
+	Defects           []*Defect      `gorm:"foreignKey:QualityID" json:"defects"`
+	Measurements      []*Measurement `gorm:"foreignKey:QualityID" json:"measurements,omitempty"`
+}
+
+func (Inspection) TableName() string {
+	return "product_quality"
+}
+
+// Defect дефект, обнаруженный при контроле
+type Defect struct {
+	ID           int64  `gorm:"primaryKey;autoIncrement" json:"id"`
+	QualityID    int64  `json:"-"`
+	DefectCodeID int    `json:"defect_code_id"`
+	Code         string `gorm:"->" json:"code"`
+	Name         string `gorm:"->" json:"name"`
+	Quantity     int    `json:"quantity"`
+	Comment      string `json:"comment,omitempty"`
+}
+
+func (Defect) TableName() string {
+	return "product_quality_defects"
+}
+
+// InstanceRef данные экземпляра, нужные для контроля; MachineID — машина заказа
+type InstanceRef struct {
+	ID          int64
+	ProductID   int64
+	WorkOrderID *int64
+	MachineID   *int64
+}
+
+// ListFilter параметры выборки результатов контроля; период [From, To) по inspected_at
+type ListFilter struct {
+	WorkOrderID  int64
+	ProductID    int64
+	InstanceID   int64
+	Barcode      string
+	Status       string
+	StageID      int64
+	MachineID    int64
+	DefectCodeID int
+	InspectedBy  int64
+	From         *time.Time
+	To           *time.Time
+	Limit        int
+	Offset       int
+}
+
+// Типы характеристик плана контроля
+const (
+	KindNumeric = "numeric"
+	KindBoolean = "boolean"
+	KindChoice  = "choice"
+)
+
+// Plan план контроля этапа маршрута продукта; действует во всех версиях маршрута с этим этапом.
+// Обязательный план не пускает экземпляр на следующие этапы без годного результата.
+type Plan struct {
+	ID              int64             `gorm:"primaryKey;autoIncrement" json:"id"`
+	ProductID       int64             `json:"product_id" example:"1"`
+	StageID         int64             `json:"stage_id" example:"2"`
+	Name            string            `json:"name" example:"Контроль после сварки"`
+	Mandatory       bool              `gorm:"not null" json:"mandatory" example:"true"`
+	Active          bool              `gorm:"not null" json:"active" example:"true"`
+	CreatedAt       time.Time         `gorm:"autoCreateTime" json:"created_at"`
+	UpdatedAt       time.Time         `gorm:"autoUpdateTime" json:"updated_at"`
+	Characteristics []*Characteristic `gorm:"foreignKey:PlanID" json:"characteristics"`
+}
+
+func (Plan) TableName() string {
+	return "inspection_plans"
+}
+
+// Characteristic контролируемая характеристика: числовая с допуском, да/нет или выбор из вариантов
+type Characteristic struct {
+	ID       int64     `gorm:"primaryKey;autoIncrement" json:"id"`
+	PlanID   int64     `json:"-"`
+	Position int       `json:"position" example:"1"`
+	Name     string    `json:"name" example:"Длина шва"`
+	Kind     string    `json:"kind" example:"numeric"`
+	Unit     string    `json:"unit,omitempty" example:"мм"`
+	Nominal  *float64  `json:"nominal,omitempty" example:"120"`
+	TolMinus *float64  `json:"tol_minus,omitempty" example:"0.5"`
+	TolPlus  *float64  `json:"tol_plus,omitempty" example:"1"`
+	Expected *bool     `json:"expected,omitempty"`
+	Required bool      `gorm:"not null" json:"required" example:"true"`
+	Options  []*Option `gorm:"foreignKey:CharacteristicID" json:"options,omitempty"`
+}
+
+func (Characteristic) TableName() string {
+	return "inspection_characteristics"
+}
+
+// Option вариант характеристики типа choice; Accepted — вариант считается годным
+type Option struct {
+	ID               int64  `gorm:"primaryKey;autoIncrement" json:"id"`
+	CharacteristicID int64  `json:"-"`
+	Position         int    `json:"-"`
+	Value            string `json:"value" example:"Без пор"`
+	Accepted         bool   `gorm:"not null" json:"accepted" example:"true"`
+}
+
+func (Option) TableName() string {
+	return "inspection_choice_options"
+}
+
+// Measurement измеренное значение характеристики с копией ее границ на момент контроля
+type Measurement struct {
+	ID               int64    `gorm:"primaryKey;autoIncrement" json:"id"`
+	QualityID        int64    `json:"-"`
+	CharacteristicID *int64   `json:"characteristic_id,omitempty"`
+	Name             string   `json:"name"`
+	Kind             string   `json:"kind"`
+	Unit             string   `json:"unit,omitempty"`
+	LowerLimit       *float64 `json:"lower_limit,omitempty"`
+	UpperLimit       *float64 `json:"upper_limit,omitempty"`
+	NumericValue     *float64 `json:"numeric_value,omitempty"`
+	BoolValue        *bool    `json:"bool_value,omitempty"`
+	ChoiceValue      *string  `json:"choice_value,omitempty"`
+	Passed           bool     `json:"passed"`
+}
+
+func (Measurement) TableName() string {
+	return "inspection_measurements"
+}
+
+// PlanFilter параметры выборки планов контроля
+type PlanFilter struct {
+	ProductID int64
+	StageID   int64
+}
+
+// Типы контрольных карт
+const (
+	ChartIndividuals = "individuals"
+	ChartXbarR       = "xbar-r"
+	ChartXbarS       = "xbar-s"
+)
+
+// Карты правил Western Electric
+const (
+	ChartX          = "x"
+	ChartDispersion = "dispersion"
+)
+
+// Sample числовое измерение характеристики для контрольной карты
+type Sample struct {
+	InspectionID int64
+	MachineID    *int64
+	InspectedAt  time.Time
+	Value        float64
+}
+
+// Limits центральная линия и контрольные границы карты
+type Limits struct {
+	Center float64 `json:"center"`
+	UCL    float64 `json:"ucl"`
+	LCL    float64 `json:"lcl"`
+}
+
+// ChartPoint точка карты: среднее подгруппы (для individuals — отдельное значение) и разброс —
+// размах, стандартное отклонение или скользящий размах (у первой точки individuals его нет)
+type ChartPoint struct {
+	Index            int       `json:"index"`
+	From             time.Time `json:"from"`
+	To               time.Time `json:"to"`
+	LastInspectionID int64     `json:"last_inspection_id"`
+	Value            float64   `json:"value"`
+	Dispersion       *float64  `json:"dispersion,omitempty"`
+}
+
+// Capability воспроизводимость процесса: Cp/Cpk по разбросу внутри подгрупп, Pp/Ppk по общему разбросу;
+// индексы не считаются при нулевом разбросе
+type Capability struct {
+	Mean         float64  `json:"mean"`
+	SigmaWithin  float64  `json:"sigma_within"`
+	SigmaOverall float64  `json:"sigma_overall"`
+	Cp           *float64 `json:"cp,omitempty"`
+	Cpk          *float64 `json:"cpk,omitempty"`
+	Pp           *float64 `json:"pp,omitempty"`
+	Ppk          *float64 `json:"ppk,omitempty"`
+}
+
+// Violation нарушение правила Western Electric в точке Point:
+// 1 — точка за 3σ; 2 — две из трех подряд за 2σ по одну сторону; 3 — четыре из пяти подряд за 1σ по одну сторону;
+// 4 — восемь подряд по одну сторону от центра. На карте разброса проверяется только правило 1.
+type Violation struct {
+	Rule        int       `json:"rule" example:"1"`
+	Chart       string    `json:"chart" example:"x"`
+	Point       int       `json:"point"`
+	At          time.Time `json:"at"`
+	Description string    `json:"description" example:"Точка за контрольной границей (3σ)"`
+}
+
+// ControlChart контрольная карта числовой характеристики плана за период.
+// LSL/USL — границы допуска по текущей характеристике; Excluded — значения неполной последней подгруппы.
+type ControlChart struct {
+	CharacteristicID int64         `json:"characteristic_id"`
+	Name             string        `json:"name"`
+	Unit             string        `json:"unit,omitempty"`
+	PlanID           int64         `json:"plan_id"`
+	ProductID        int64         `json:"product_id"`
+	StageID          int64         `json:"stage_id"`
+	MachineID        *int64        `json:"machine_id,omitempty"`
+	From             time.Time     `json:"from"`
+	To               time.Time     `json:"to"`
+	Chart            string        `json:"chart" example:"xbar-r"`
+	SubgroupSize     int           `json:"subgroup_size" example:"5"`
+	Samples          int           `json:"samples"`
+	Excluded         int           `json:"excluded"`
+	LSL              *float64      `json:"lsl,omitempty"`
+	USL              *float64      `json:"usl,omitempty"`
+	X                Limits        `json:"x"`
+	Dispersion       Limits        `json:"dispersion"`
+	Points           []*ChartPoint `json:"points"`
+	Capability       *Capability   `json:"capability"`
+	Violations       []*Violation  `json:"violations"`
+}
+
+// SPCAlert последний результат контроля, нарушения карты по которому уже переданы в инцидент;
+// MachineID = nil — карта по всем машинам
+type SPCAlert struct {
+	ID               int64     `gorm:"primaryKey;autoIncrement" json:"id"`
+	CharacteristicID int64     `json:"characteristic_id"`
+	MachineID        *int64    `json:"machine_id,omitempty"`
+	LastInspectionID int64     `json:"last_inspection_id"`
+	IncidentID       *int64    `json:"incident_id,omitempty"`
+	CreatedAt        time.Time `gorm:"autoCreateTime" json:"created_at"`
+}
+
+func (SPCAlert) TableName() string {
+	return "spc_alerts"
+}
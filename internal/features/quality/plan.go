@@ -0,0 +1,279 @@
+package quality
+
+import (
+	"errors"
+	"slices"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+var (
+	ErrPlanNotFound          = errors.New("inspection plan not found")
+	ErrPlanExists            = errors.New("stage already has an inspection plan")
+	ErrProductNotFound       = errors.New("product not found")
+	ErrStageNotInRouting     = errors.New("stage is not part of the product routing")
+	ErrNoCharacteristics     = errors.New("inspection plan needs at least one characteristic")
+	ErrCharacteristicName    = errors.New("characteristic name is required")
+	ErrInvalidKind           = errors.New("characteristic kind must be numeric, boolean or choice")
+	ErrInvalidTolerance      = errors.New("numeric characteristic needs a nominal and non-negative tolerances")
+	ErrInvalidOptions        = errors.New("choice characteristic needs two or more distinct options, one of them accepted")
+	ErrStatusWithPlan        = errors.New("status of a plan inspection is evaluated from measurements")
+	ErrUnknownCharacteristic = errors.New("characteristic is not part of the plan")
+	ErrDuplicateMeasurement  = errors.New("characteristic is measured twice")
+	ErrMeasurementMissing    = errors.New("required characteristic is not measured")
+	ErrInvalidMeasurement    = errors.New("measurement value does not match characteristic kind")
+)
+
+// MeasurementInput значение характеристики плана: value для numeric, flag для boolean, choice для choice
+type MeasurementInput struct {
+	CharacteristicID int64    `json:"characteristic_id" example:"1"`
+	Value            *float64 `json:"value,omitempty" example:"120.4"`
+	Flag             *bool    `json:"flag,omitempty"`
+	Choice           *string  `json:"choice,omitempty"`
+}
+
+func (s *Service) ListPlans(filter PlanFilter) ([]*Plan, error) {
+	return s.repo.ListPlans(filter)
+}
+
+func (s *Service) GetPlan(id int64) (*Plan, error) {
+	p, err := s.repo.GetPlan(id)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrPlanNotFound
+	}
+	return p, err
+}
+
+func (s *Service) CreatePlan(p *Plan) error {
+	if err := s.validatePlan(p); err != nil {
+		return err
+	}
+
+	p.ID = 0
+	for _, c := range p.Characteristics {
+		c.ID = 0
+		for _, o := range c.Options {
+			o.ID = 0
+		}
+	}
+	return s.repo.CreatePlan(p)
+}
+
+// UpdatePlan изменяет план. Характеристика с ID сохраняет идентичность, и ее измерения остаются
+// на одной контрольной карте; характеристики без ID добавляются, не переданные — удаляются.
+// Записанные результаты контроля не меняются.
+func (s *Service) UpdatePlan(p *Plan) error {
+	existing, err := s.GetPlan(p.ID)
+	if err != nil {
+		return err
+	}
+	if err := s.validatePlan(p); err != nil {
+		return err
+	}
+
+	var ids []int64
+	for _, c := range p.Characteristics {
+		if c.ID == 0 {
+			continue
+		}
+		if slices.Contains(ids, c.ID) || !slices.ContainsFunc(existing.Characteristics, func(e *Characteristic) bool { return e.ID == c.ID }) {
+			return ErrUnknownCharacteristic
+		}
+		ids = append(ids, c.ID)
+	}
+
+	p.CreatedAt = existing.CreatedAt
+	if err := s.repo.UpdatePlan(p); err != nil {
+		return err
+	}
+
+	updated, err := s.GetPlan(p.ID)
+	if err != nil {
+		return err
+	}
+	*p = *updated
+	return nil
+}
+
+func (s *Service) DeletePlan(id int64) error {
+	p, err := s.GetPlan(id)
+	if err != nil {
+		return err
+	}
+	return s.repo.DeletePlan(p)
+}
+
+// PendingInspections этапы из stageIDs с обязательным планом, по которым последний результат
+// контроля экземпляра не годен или отсутствует; условно годный результат пропускает экземпляр дальше
+func (s *Service) PendingInspections(instanceID, productID int64, stageIDs []int64) ([]int64, error) {
+	if len(stageIDs) == 0 {
+		return nil, nil
+	}
+
+	planned, err := s.repo.MandatoryPlanStages(productID, stageIDs)
+	if err != nil || len(planned) == 0 {
+		return nil, err
+	}
+
+	results, err := s.repo.LatestStageResults(instanceID, planned)
+	if err != nil {
+		return nil, err
+	}
+
+	var pending []int64
+	for _, id := range stageIDs {
+		if !slices.Contains(planned, id) {
+			continue
+		}
+		if status := results[id]; status != StatusPassed && status != StatusConditional {
+			pending = append(pending, id)
+		}
+	}
+	return pending, nil
+}
+
+// evaluate сопоставляет измерения с характеристиками плана; результат годен, если годны все измерения
+func evaluate(p *Plan, input []MeasurementInput) ([]*Measurement, bool, error) {
+	byID := make(map[int64]MeasurementInput, len(input))
+	for _, m := range input {
+		if _, ok := byID[m.CharacteristicID]; ok {
+			return nil, false, ErrDuplicateMeasurement
+		}
+		if !slices.ContainsFunc(p.Characteristics, func(c *Characteristic) bool { return c.ID == m.CharacteristicID }) {
+			return nil, false, ErrUnknownCharacteristic
+		}
+		byID[m.CharacteristicID] = m
+	}
+
+	passed := true
+	measurements := make([]*Measurement, 0, len(input))
+	for _, c := range p.Characteristics {
+		in, ok := byID[c.ID]
+		if !ok {
+			if c.Required {
+				return nil, false, ErrMeasurementMissing
+			}
+			continue
+		}
+
+		m, err := measure(c, in)
+		if err != nil {
+			return nil, false, err
+		}
+		passed = passed && m.Passed
+		measurements = append(measurements, m)
+	}
+	return measurements, passed, nil
+}
+
+func measure(c *Characteristic, in MeasurementInput) (*Measurement, error) {
+	m := &Measurement{
+		CharacteristicID: &c.ID,
+		Name:             c.Name,
+		Kind:             c.Kind,
+		Unit:             c.Unit,
+	}
+
+	switch c.Kind {
+	case KindNumeric:
+		if in.Value == nil || in.Flag != nil || in.Choice != nil {
+			return nil, ErrInvalidMeasurement
+		}
+		lower, upper := *c.Nominal-*c.TolMinus, *c.Nominal+*c.TolPlus
+		m.LowerLimit, m.UpperLimit = &lower, &upper
+		m.NumericValue = in.Value
+		m.Passed = *in.Value >= lower && *in.Value <= upper
+	case KindBoolean:
+		if in.Flag == nil || in.Value != nil || in.Choice != nil {
+			return nil, ErrInvalidMeasurement
+		}
+		m.BoolValue = in.Flag
+		m.Passed = *in.Flag == *c.Expected
+	case KindChoice:
+		if in.Choice == nil || in.Value != nil || in.Flag != nil {
+			return nil, ErrInvalidMeasurement
+		}
+		idx := slices.IndexFunc(c.Options, func(o *Option) bool { return o.Value == strings.TrimSpace(*in.Choice) })
+		if idx < 0 {
+			return nil, ErrInvalidMeasurement
+		}
+		m.ChoiceValue = &c.Options[idx].Value
+		m.Passed = c.Options[idx].Accepted
+	}
+	return m, nil
+}
+
+func (s *Service) validatePlan(p *Plan) error {
+	p.Name = strings.TrimSpace(p.Name)
+
+	ok, err := s.repo.ProductExists(p.ProductID)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return ErrProductNotFound
+	}
+	ok, err = s.repo.StageInRouting(p.ProductID, p.StageID)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return ErrStageNotInRouting
+	}
+
+	if len(p.Characteristics) == 0 {
+		return ErrNoCharacteristics
+	}
+	for i, c := range p.Characteristics {
+		c.Position = i + 1
+		if err := validateCharacteristic(c); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func validateCharacteristic(c *Characteristic) error {
+	c.Name = strings.TrimSpace(c.Name)
+	c.Unit = strings.TrimSpace(c.Unit)
+	if c.Name == "" {
+		return ErrCharacteristicName
+	}
+
+	switch c.Kind {
+	case KindNumeric:
+		if c.Nominal == nil || c.TolMinus == nil || c.TolPlus == nil || *c.TolMinus < 0 || *c.TolPlus < 0 {
+			return ErrInvalidTolerance
+		}
+		c.Expected, c.Options = nil, nil
+	case KindBoolean:
+		if c.Expected == nil {
+			expected := true
+			c.Expected = &expected
+		}
+		c.Nominal, c.TolMinus, c.TolPlus, c.Options = nil, nil, nil, nil
+	case KindChoice:
+		if len(c.Options) < 2 {
+			return ErrInvalidOptions
+		}
+		var values []string
+		accepted := false
+		for i, o := range c.Options {
+			o.Value = strings.TrimSpace(o.Value)
+			if o.Value == "" || slices.Contains(values, o.Value) {
+				return ErrInvalidOptions
+			}
+			values = append(values, o.Value)
+			o.Position = i + 1
+			accepted = accepted || o.Accepted
+		}
+		if !accepted {
+			return ErrInvalidOptions
+		}
+		c.Nominal, c.TolMinus, c.TolPlus, c.Expected = nil, nil, nil, nil
+	default:
+		return ErrInvalidKind
+	}
+	return nil
+}
@@ -0,0 +1,47 @@
+package quality
+
+import "time"
+
+type Repository interface {
+	Statuses() ([]*Status, error)
+	StatusByCode(code string) (*Status, error)
+
+	DefectCodes(activeOnly bool) ([]*DefectCode, error)
+	DefectCodesByID(ids []int) ([]*DefectCode, error)
+	GetDefectCode(id int) (*DefectCode, error)
+	SaveDefectCode(c *DefectCode) error
+
+	InstanceByBarcode(barcode string) (*InstanceRef, error)
+
+	// Create сохраняет результат контроля вместе с дефектами
+	Create(i *Inspection) error
+	Get(id int64) (*Inspection, error)
+	List(filter ListFilter) ([]*Inspection, error)
+
+	ListPlans(filter PlanFilter) ([]*Plan, error)
+	GetPlan(id int64) (*Plan, error)
+	// PlanFor активный план этапа продукта
+	PlanFor(productID, stageID int64) (*Plan, error)
+	CreatePlan(p *Plan) error
+	// UpdatePlan сохраняет план: характеристики с ID изменяются, без ID добавляются, отсутствующие удаляются
+	UpdatePlan(p *Plan) error
+	DeletePlan(p *Plan) error
+
+	ProductExists(productID int64) (bool, error)
+	MachineExists(machineID int64) (bool, error)
+	// StageInRouting входит ли этап в какую-либо версию маршрута продукта
+	StageInRouting(productID, stageID int64) (bool, error)
+
+	// MandatoryPlanStages этапы из stageIDs с активным обязательным планом контроля продукта
+	MandatoryPlanStages(productID int64, stageIDs []int64) ([]int64, error)
+	// LatestStageResults коды последних результатов контроля экземпляра по этапам
+	LatestStageResults(instanceID int64, stageIDs []int64) (map[int64]string, error)
+
+	GetCharacteristic(id int64) (*Characteristic, error)
+	// Samples числовые измерения характеристики за [from, to) в порядке контроля, не больше limit;
+	// machineID = 0 — по всем машинам
+	Samples(characteristicID, machineID int64, from, to time.Time, limit int) ([]*Sample, error)
+	// LastAlerted последний результат контроля, нарушения карты по которому переданы в инцидент; 0 — не было
+	LastAlerted(characteristicID, machineID int64) (int64, error)
+	SaveAlert(a *SPCAlert) error
+}
@@ -0,0 +1,363 @@
+package quality
+
+import (
+	"errors"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgconn"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// pgUniqueViolation код ошибки Postgres при нарушении уникальности
+const pgUniqueViolation = "23505"
+
+type GormRepository struct {
+	db *gorm.DB
+}
+
+func NewGormRepository(db *gorm.DB) *GormRepository {
+	return &GormRepository{db: db}
+}
+
+func (r *GormRepository) Statuses() ([]*Status, error) {
+	var statuses []*Status
+	return statuses, r.db.Order("id").Find(&statuses).Error
+}
+
+func (r *GormRepository) StatusByCode(code string) (*Status, error) {
+	var s Status
+	if err := r.db.Where("code = ?", code).First(&s).Error; err != nil {
+		return nil, err
+	}
+	return &s, nil
+}
+
+func (r *GormRepository) DefectCodes(activeOnly bool) ([]*DefectCode, error) {
+	var codes []*DefectCode
+
+	q := r.db.Order("code")
+	if activeOnly {
+		q = q.Where("active")
+	}
+	return codes, q.Find(&codes).Error
+}
+
+func (r *GormRepository) DefectCodesByID(ids []int) ([]*DefectCode, error) {
+	var codes []*DefectCode
+	return codes, r.db.Where("id IN ?", ids).Find(&codes).Error
+}
+
+func (r *GormRepository) GetDefectCode(id int) (*DefectCode, error) {
+	var c DefectCode
+	if err := r.db.First(&c, id).Error; err != nil {
+		return nil, err
+	}
+	return &c, nil
+}
+
+func (r *GormRepository) SaveDefectCode(c *DefectCode) error {
+	err := r.db.Save(c).Error
+
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && pgErr.Code == pgUniqueViolation {
+		return ErrDefectCodeExists
+	}
+	return err
+}
+
+func (r *GormRepository) InstanceByBarcode(barcode string) (*InstanceRef, error) {
+	var ref InstanceRef
+	err := r.db.Table("product_instances pi").
+		Select("pi.id, pi.product_id, pi.work_order_id, wo.machine_id").
+		Joins("LEFT JOIN work_orders wo ON wo.id = pi.work_order_id").
+		Where("pi.barcode = ?", barcode).
+		Take(&ref).
+		Error
+	if err != nil {
+		return nil, err
+	}
+	return &ref, nil
+}
+
+func (r *GormRepository) Create(i *Inspection) error {
+	return r.db.Create(i).Error
+}
+
+func (r *GormRepository) Get(id int64) (*Inspection, error) {
+	var i Inspection
+	if err := r.inspections().Where("product_quality.id = ?", id).Take(&i).Error; err != nil {
+		return nil, err
+	}
+	return &i, nil
+}
+
+func (r *GormRepository) List(filter ListFilter) ([]*Inspection, error) {
+	var inspections []*Inspection
+
+	q := r.inspections().Order("product_quality.inspected_at DESC, product_quality.id DESC")
+	if filter.WorkOrderID > 0 {
+		q = q.Where("product_quality.work_order_id = ?", filter.WorkOrderID)
+	}
+	if filter.ProductID > 0 {
+		q = q.Where("product_quality.product_id = ?", filter.ProductID)
+	}
+	if filter.InstanceID > 0 {
+		q = q.Where("product_quality.product_instance_id = ?", filter.InstanceID)
+	}
+	if filter.Barcode != "" {
+		q = q.Where("pi.barcode = ?", filter.Barcode)
+	}
+	if filter.Status != "" {
+		q = q.Where("qs.code = ?", filter.Status)
+	}
+	if filter.StageID > 0 {
+		q = q.Where("product_quality.stage_id = ?", filter.StageID)
+	}
+	if filter.MachineID > 0 {
+		q = q.Where("product_quality.machine_id = ?", filter.MachineID)
+	}
+	if filter.DefectCodeID > 0 {
+		q = q.Where(
+			"EXISTS (SELECT 1 FROM product_quality_defects d WHERE d.quality_id = product_quality.id AND d.defect_code_id = ?)",
+			filter.DefectCodeID,
+		)
+	}
+	if filter.InspectedBy > 0 {
+		q = q.Where("product_quality.inspected_by = ?", filter.InspectedBy)
+	}
+	if filter.From != nil {
+		q = q.Where("product_quality.inspected_at >= ?", *filter.From)
+	}
+	if filter.To != nil {
+		q = q.Where("product_quality.inspected_at < ?", *filter.To)
+	}
+
+	return inspections, q.Limit(filter.Limit).Offset(filter.Offset).Find(&inspections).Error
+}
+
+// inspections выборка результатов со штрихкодом, кодом статуса и дефектами
+func (r *GormRepository) inspections() *gorm.DB {
+	return r.db.Model(&Inspection{}).
+		Select("product_quality.*, pi.barcode, qs.code AS status").
+		Joins("JOIN product_instances pi ON pi.id = product_quality.product_instance_id").
+		Joins("JOIN quality_statuses qs ON qs.id = product_quality.quality_status_id").
+		Preload("Defects", func(db *gorm.DB) *gorm.DB {
+			return db.
+				Select("product_quality_defects.*, dc.code, dc.name").
+				Joins("JOIN defect_codes dc ON dc.id = product_quality_defects.defect_code_id").
+				Order("product_quality_defects.id")
+		}).
+		Preload("Measurements", func(db *gorm.DB) *gorm.DB { return db.Order("id") })
+}
+
+func (r *GormRepository) ListPlans(filter PlanFilter) ([]*Plan, error) {
+	var plans []*Plan
+
+	q := r.plans().Order("product_id, stage_id")
+	if filter.ProductID > 0 {
+		q = q.Where("product_id = ?", filter.ProductID)
+	}
+	if filter.StageID > 0 {
+		q = q.Where("stage_id = ?", filter.StageID)
+	}
+	return plans, q.Find(&plans).Error
+}
+
+func (r *GormRepository) GetPlan(id int64) (*Plan, error) {
+	var p Plan
+	if err := r.plans().First(&p, id).Error; err != nil {
+		return nil, err
+	}
+	return &p, nil
+}
+
+func (r *GormRepository) PlanFor(productID, stageID int64) (*Plan, error) {
+	var p Plan
+	err := r.plans().
+		Where("product_id = ? AND stage_id = ? AND active", productID, stageID).
+		First(&p).
+		Error
+	if err != nil {
+		return nil, err
+	}
+	return &p, nil
+}
+
+func (r *GormRepository) CreatePlan(p *Plan) error {
+	err := r.db.Create(p).Error
+
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && pgErr.Code == pgUniqueViolation {
+		return ErrPlanExists
+	}
+	return err
+}
+
+func (r *GormRepository) UpdatePlan(p *Plan) error {
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Omit(clause.Associations).Save(p).Error; err != nil {
+			return err
+		}
+
+		keep := make([]int64, 0, len(p.Characteristics))
+		for _, c := range p.Characteristics {
+			if c.ID > 0 {
+				keep = append(keep, c.ID)
+			}
+		}
+		q := tx.Where("plan_id = ?", p.ID)
+		if len(keep) > 0 {
+			q = q.Where("id NOT IN ?", keep)
+		}
+		if err := q.Delete(&Characteristic{}).Error; err != nil {
+			return err
+		}
+
+		// позиции временно отрицательные, чтобы перестановка не нарушала уникальность
+		err := tx.Model(&Characteristic{}).
+			Where("plan_id = ?", p.ID).
+			Update("position", gorm.Expr("-position")).
+			Error
+		if err != nil {
+			return err
+		}
+
+		for _, c := range p.Characteristics {
+			c.PlanID = p.ID
+			if err := tx.Omit(clause.Associations).Save(c).Error; err != nil {
+				return err
+			}
+			if err := tx.Where("characteristic_id = ?", c.ID).Delete(&Option{}).Error; err != nil {
+				return err
+			}
+			for _, o := range c.Options {
+				o.ID = 0
+				o.CharacteristicID = c.ID
+			}
+			if len(c.Options) > 0 {
+				if err := tx.Create(&c.Options).Error; err != nil {
+					return err
+				}
+			}
+		}
+		return nil
+	})
+
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && pgErr.Code == pgUniqueViolation {
+		return ErrPlanExists
+	}
+	return err
+}
+
+func (r *GormRepository) DeletePlan(p *Plan) error {
+	return r.db.Delete(p).Error
+}
+
+func (r *GormRepository) ProductExists(productID int64) (bool, error) {
+	var n int64
+	err := r.db.Table("products").Where("id = ?", productID).Count(&n).Error
+	return n > 0, err
+}
+
+func (r *GormRepository) MachineExists(machineID int64) (bool, error) {
+	var n int64
+	err := r.db.Table("machines").Where("id = ?", machineID).Count(&n).Error
+	return n > 0, err
+}
+
+func (r *GormRepository) StageInRouting(productID, stageID int64) (bool, error) {
+	var n int64
+	err := r.db.Table("product_stages").
+		Where("product_id = ? AND stage_id = ?", productID, stageID).
+		Count(&n).
+		Error
+	return n > 0, err
+}
+
+func (r *GormRepository) MandatoryPlanStages(productID int64, stageIDs []int64) ([]int64, error) {
+	var ids []int64
+	err := r.db.Model(&Plan{}).
+		Where("product_id = ? AND stage_id IN ? AND mandatory AND active", productID, stageIDs).
+		Pluck("stage_id", &ids).
+		Error
+	return ids, err
+}
+
+func (r *GormRepository) LatestStageResults(instanceID int64, stageIDs []int64) (map[int64]string, error) {
+	var rows []struct {
+		StageID int64
+		Status  string
+	}
+	err := r.db.Raw(`
+		SELECT DISTINCT ON (pq.stage_id) pq.stage_id, qs.code AS status
+		FROM product_quality pq
+		JOIN quality_statuses qs ON qs.id = pq.quality_status_id
+		WHERE pq.product_instance_id = ? AND pq.stage_id IN ?
+		ORDER BY pq.stage_id, pq.inspected_at DESC, pq.id DESC`,
+		instanceID, stageIDs,
+	).Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+
+	results := make(map[int64]string, len(rows))
+	for _, row := range rows {
+		results[row.StageID] = row.Status
+	}
+	return results, nil
+}
+
+func (r *GormRepository) GetCharacteristic(id int64) (*Characteristic, error) {
+	var c Characteristic
+	if err := r.db.First(&c, id).Error; err != nil {
+		return nil, err
+	}
+	return &c, nil
+}
+
+func (r *GormRepository) Samples(characteristicID, machineID int64, from, to time.Time, limit int) ([]*Sample, error) {
+	var samples []*Sample
+
+	q := r.db.Table("inspection_measurements m").
+		Select("pq.id AS inspection_id, pq.machine_id, pq.inspected_at, m.numeric_value AS value").
+		Joins("JOIN product_quality pq ON pq.id = m.quality_id").
+		Where("m.characteristic_id = ? AND m.numeric_value IS NOT NULL", characteristicID).
+		Where("pq.inspected_at >= ? AND pq.inspected_at < ?", from, to)
+	if machineID > 0 {
+		q = q.Where("pq.machine_id = ?", machineID)
+	}
+	return samples, q.Order("pq.inspected_at, pq.id").Limit(limit).Scan(&samples).Error
+}
+
+func (r *GormRepository) LastAlerted(characteristicID, machineID int64) (int64, error) {
+	var ids []int64
+	err := r.db.Model(&SPCAlert{}).
+		Where("characteristic_id = ? AND COALESCE(machine_id, 0) = ?", characteristicID, machineID).
+		Pluck("last_inspection_id", &ids).
+		Error
+	if err != nil || len(ids) == 0 {
+		return 0, err
+	}
+	return ids[0], nil
+}
+
+func (r *GormRepository) SaveAlert(a *SPCAlert) error {
+	return r.db.Exec(`
+		INSERT INTO spc_alerts (characteristic_id, machine_id, last_inspection_id, incident_id)
+		VALUES (?, ?, ?, ?)
+		ON CONFLICT (characteristic_id, COALESCE(machine_id, 0)) DO UPDATE SET
+			last_inspection_id = EXCLUDED.last_inspection_id,
+			incident_id = EXCLUDED.incident_id,
+			created_at = NOW()`,
+		a.CharacteristicID, a.MachineID, a.LastInspectionID, a.IncidentID,
+	).Error
+}
+
+// plans выборка планов с характеристиками и вариантами в порядке позиций
+func (r *GormRepository) plans() *gorm.DB {
+	return r.db.
+		Preload("Characteristics", func(db *gorm.DB) *gorm.DB { return db.Order("position") }).
+		Preload("Characteristics.Options", func(db *gorm.DB) *gorm.DB { return db.Order("position") })
+}
@@ -0,0 +1,315 @@
+package quality
+
+import (
+	"errors"
+	"slices"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+const (
+	// maxLimit ограничивает размер страницы результатов контроля
+	maxLimit = 200
+)
+
+var (
+	ErrNotFound           = errors.New("inspection not found")
+	ErrInstanceNotFound   = errors.New("product instance not found")
+	ErrBarcodeRequired    = errors.New("barcode is required")
+	ErrInvalidStatus      = errors.New("invalid inspection status")
+	ErrDefectRequired     = errors.New("failed or conditional result needs a defect code")
+	ErrDefectsOnPass      = errors.New("passed result cannot have defects")
+	ErrDuplicateDefect    = errors.New("defect code is listed twice")
+	ErrInvalidQuantity    = errors.New("defect quantity must be positive")
+	ErrDefectCodeNotFound = errors.New("defect code not found")
+	ErrDefectCodeInactive = errors.New("defect code is inactive")
+	ErrDefectCodeExists   = errors.New("defect code already exists")
+	ErrCodeRequired       = errors.New("defect code is required")
+	ErrNameRequired       = errors.New("defect name is required")
+	ErrInvalidPeriod      = errors.New("period end must be after start")
+	ErrStageRequired      = errors.New("measurements need a stage with an inspection plan")
+	ErrMachineNotFound    = errors.New("machine not found")
+)
+
+// Record результат контроля экземпляра по штрихкоду.
+// Со StageID контроль идет по плану этапа: Status не указывается, а вычисляется по Measurements.
+// MachineID — машина, на которой изготовлен экземпляр; по умолчанию машина заказа.
+type Record struct {
+	Barcode      string             `json:"barcode" example:"MES-BRK-001-261102-000017-4"`
+	Status       string             `json:"status,omitempty" example:"failed"`
+	StageID      *int64             `json:"stage_id,omitempty"`
+	MachineID    *int64             `json:"machine_id,omitempty"`
+	Measurements []MeasurementInput `json:"measurements,omitempty"`
+	Defects      []DefectInput      `json:"defects,omitempty"`
+	Description  string             `json:"description,omitempty" example:"Глубокая царапина на лицевой панели"`
+}
+
+// DefectInput дефект в результате контроля; Quantity по умолчанию 1
+type DefectInput struct {
+	DefectCodeID int    `json:"defect_code_id" example:"1"`
+	Quantity     int    `json:"quantity,omitempty" example:"1"`
+	Comment      string `json:"comment,omitempty" example:"Левый угол"`
+}
+
+// ServiceInterface определяет методы, используемые handler’ом
+type ServiceInterface interface {
+	Statuses() ([]*Status, error)
+	DefectCodes(activeOnly bool) ([]*DefectCode, error)
+	CreateDefectCode(c *DefectCode) error
+	UpdateDefectCode(c *DefectCode) error
+
+	Inspect(rec Record, userID int64) (*Inspection, error)
+	Get(id int64) (*Inspection, error)
+	List(filter ListFilter) ([]*Inspection, error)
+
+	ListPlans(filter PlanFilter) ([]*Plan, error)
+	GetPlan(id int64) (*Plan, error)
+	CreatePlan(p *Plan) error
+	UpdatePlan(p *Plan) error
+	DeletePlan(id int64) error
+
+	ControlChart(q SPCQuery) (*ControlChart, error)
+	CheckControlChart(q SPCQuery, severityID int, userID int64) (*SPCCheck, error)
+}
+
+type Service struct {
+	repo      Repository
+	incidents IncidentReporter
+	now       func() time.Time
+}
+
+func NewService(repo Repository, incidents IncidentReporter) *Service {
+	return &Service{
+		repo:      repo,
+		incidents: incidents,
+		now:       time.Now,
+	}
+}
+
+func (s *Service) Statuses() ([]*Status, error) {
+	return s.repo.Statuses()
+}
+
+func (s *Service) DefectCodes(activeOnly bool) ([]*DefectCode, error) {
+	return s.repo.DefectCodes(activeOnly)
+}
+
+func (s *Service) CreateDefectCode(c *DefectCode) error {
+	if err := validateDefectCode(c); err != nil {
+		return err
+	}
+
+	c.ID = 0
+	return s.repo.SaveDefectCode(c)
+}
+
+func (s *Service) UpdateDefectCode(c *DefectCode) error {
+	if _, err := s.getDefectCode(c.ID); err != nil {
+		return err
+	}
+	if err := validateDefectCode(c); err != nil {
+		return err
+	}
+	return s.repo.SaveDefectCode(c)
+}
+
+// Inspect регистрирует результат контроля; контролер берется из токена.
+// Без этапа результат указывает контролер: брак и условная годность требуют хотя бы одного кода дефекта,
+// годный результат — ни одного. С этапом результат вычисляется по измерениям плана контроля этапа.
+func (s *Service) Inspect(rec Record, userID int64) (*Inspection, error) {
+	rec.Barcode = strings.TrimSpace(rec.Barcode)
+	if rec.Barcode == "" {
+		return nil, ErrBarcodeRequired
+	}
+	if rec.StageID == nil && len(rec.Measurements) > 0 {
+		return nil, ErrStageRequired
+	}
+
+	instance, err := s.repo.InstanceByBarcode(rec.Barcode)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrInstanceNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	machineID := instance.MachineID
+	if rec.MachineID != nil {
+		ok, err := s.repo.MachineExists(*rec.MachineID)
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			return nil, ErrMachineNotFound
+		}
+		machineID = rec.MachineID
+	}
+
+	i := &Inspection{
+		ProductInstanceID: instance.ID,
+		ProductID:         &instance.ProductID,
+		WorkOrderID:       instance.WorkOrderID,
+		MachineID:         machineID,
+		StageID:           rec.StageID,
+		Description:       strings.TrimSpace(rec.Description),
+		InspectedBy:       userID,
+		InspectedAt:       s.now(),
+	}
+
+	if rec.StageID != nil {
+		if rec.Status != "" {
+			return nil, ErrStatusWithPlan
+		}
+
+		plan, err := s.repo.PlanFor(instance.ProductID, *rec.StageID)
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrPlanNotFound
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		measurements, passed, err := evaluate(plan, rec.Measurements)
+		if err != nil {
+			return nil, err
+		}
+		i.PlanID = &plan.ID
+		i.Measurements = measurements
+
+		rec.Status = StatusFailed
+		if passed {
+			rec.Status = StatusPassed
+		}
+		if passed && len(rec.Defects) > 0 {
+			return nil, ErrDefectsOnPass
+		}
+	} else {
+		switch rec.Status {
+		case StatusPassed:
+			if len(rec.Defects) > 0 {
+				return nil, ErrDefectsOnPass
+			}
+		case StatusFailed, StatusConditional:
+			if len(rec.Defects) == 0 {
+				return nil, ErrDefectRequired
+			}
+		default:
+			return nil, ErrInvalidStatus
+		}
+	}
+
+	status, err := s.repo.StatusByCode(rec.Status)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrInvalidStatus
+	}
+	if err != nil {
+		return nil, err
+	}
+	i.QualityStatusID = status.ID
+
+	if i.Defects, err = s.defects(rec.Defects); err != nil {
+		return nil, err
+	}
+
+	if err := s.repo.Create(i); err != nil {
+		return nil, err
+	}
+	return s.Get(i.ID)
+}
+
+func (s *Service) Get(id int64) (*Inspection, error) {
+	i, err := s.repo.Get(id)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrNotFound
+	}
+	return i, err
+}
+
+func (s *Service) List(filter ListFilter) ([]*Inspection, error) {
+	switch filter.Status {
+	case "", StatusPassed, StatusFailed, StatusConditional:
+	default:
+		return nil, ErrInvalidStatus
+	}
+	if filter.From != nil && filter.To != nil && !filter.To.After(*filter.From) {
+		return nil, ErrInvalidPeriod
+	}
+	if filter.Limit <= 0 || filter.Limit > maxLimit {
+		filter.Limit = maxLimit
+	}
+	return s.repo.List(filter)
+}
+
+// defects проверяет коды дефектов: каждый код один раз, существует и активен
+func (s *Service) defects(input []DefectInput) ([]*Defect, error) {
+	if len(input) == 0 {
+		return nil, nil
+	}
+
+	ids := make([]int, 0, len(input))
+	for _, d := range input {
+		if slices.Contains(ids, d.DefectCodeID) {
+			return nil, ErrDuplicateDefect
+		}
+		ids = append(ids, d.DefectCodeID)
+	}
+
+	codes, err := s.repo.DefectCodesByID(ids)
+	if err != nil {
+		return nil, err
+	}
+	byID := make(map[int]*DefectCode, len(codes))
+	for _, c := range codes {
+		byID[c.ID] = c
+	}
+
+	defects := make([]*Defect, 0, len(input))
+	for _, d := range input {
+		c, ok := byID[d.DefectCodeID]
+		if !ok {
+			return nil, ErrDefectCodeNotFound
+		}
+		if !c.Active {
+			return nil, ErrDefectCodeInactive
+		}
+
+		quantity := d.Quantity
+		if quantity == 0 {
+			quantity = 1
+		}
+		if quantity < 0 {
+			return nil, ErrInvalidQuantity
+		}
+
+		defects = append(defects, &Defect{
+			DefectCodeID: c.ID,
+			Quantity:     quantity,
+			Comment:      strings.TrimSpace(d.Comment),
+		})
+	}
+	return defects, nil
+}
+
+func (s *Service) getDefectCode(id int) (*DefectCode, error) {
+	c, err := s.repo.GetDefectCode(id)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrDefectCodeNotFound
+	}
+	return c, err
+}
+
+func validateDefectCode(c *DefectCode) error {
+	c.Code = strings.ToUpper(strings.TrimSpace(c.Code))
+	c.Name = strings.TrimSpace(c.Name)
+	c.Description = strings.TrimSpace(c.Description)
+
+	if c.Code == "" {
+		return ErrCodeRequired
+	}
+	if c.Name == "" {
+		return ErrNameRequired
+	}
+	return nil
+}
@@ -0,0 +1,440 @@
+package quality
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"slices"
+	"strings"
+	"time"
+
+	"mes-lite-back/internal/features/incident"
+
+	"gorm.io/gorm"
+)
+
+const (
+	// DefaultSubgroupSize размер подгруппы карты по умолчанию
+	DefaultSubgroupSize = 5
+	// DefaultSPCSeverity серьезность инцидента по нарушениям карты по умолчанию (medium)
+	DefaultSPCSeverity = 2
+	// MaxSPCRange наибольший период контрольной карты
+	MaxSPCRange = 400 * 24 * time.Hour
+
+	// maxSubgroupSize наибольший размер подгруппы карты xbar-s; для xbar-r — maxRangeSubgroup
+	maxSubgroupSize  = 25
+	maxRangeSubgroup = 10
+	// maxSamples ограничивает число измерений на карте
+	maxSamples = 20000
+	// maxIncidentName ограничивает длину названия характеристики в заголовке инцидента
+	maxIncidentName = 100
+)
+
+var (
+	ErrCharacteristicNotFound = errors.New("characteristic not found")
+	ErrNotNumeric             = errors.New("control charts are built for numeric characteristics only")
+	ErrInvalidChart           = errors.New("chart must be individuals, xbar-r or xbar-s")
+	ErrInvalidSubgroup        = errors.New("subgroup size does not fit the chart")
+	ErrRangeTooLong           = errors.New("range is too long")
+	ErrNotEnoughData          = errors.New("not enough measurements for a control chart")
+	ErrTooManySamples         = errors.New("too many measurements, narrow the period")
+)
+
+// d2 и d3 — константы распределения размаха подгрупп размера n
+var (
+	d2 = [...]float64{2: 1.128, 3: 1.693, 4: 2.059, 5: 2.326, 6: 2.534, 7: 2.704, 8: 2.847, 9: 2.970, 10: 3.078}
+	d3 = [...]float64{2: 0.853, 3: 0.888, 4: 0.880, 5: 0.864, 6: 0.848, 7: 0.833, 8: 0.820, 9: 0.808, 10: 0.797}
+)
+
+// SPCQuery параметры контрольной карты: характеристика плана, машина (0 — все машины) и период [From, To)
+// по времени контроля. Без Chart тип выбирается по SubgroupSize: 1 — individuals, до 10 — xbar-r, больше — xbar-s.
+type SPCQuery struct {
+	CharacteristicID int64     `json:"characteristic_id" example:"1"`
+	MachineID        int64     `json:"machine_id,omitempty" example:"1"`
+	From             time.Time `json:"from" example:"2026-10-01T00:00:00Z"`
+	To               time.Time `json:"to" example:"2026-11-01T00:00:00Z"`
+	Chart            string    `json:"chart,omitempty" example:"xbar-r"`
+	SubgroupSize     int       `json:"subgroup_size,omitempty" example:"5"`
+}
+
+// SPCCheck результат проверки карты: нарушения в точках, еще не переданных в инцидент,
+// и инцидент, зарегистрированный по ним
+type SPCCheck struct {
+	Chart         *ControlChart      `json:"chart"`
+	NewViolations []*Violation       `json:"new_violations"`
+	Incident      *incident.Incident `json:"incident,omitempty"`
+}
+
+// IncidentReporter регистрация инцидентов по нарушениям контрольных карт
+type IncidentReporter interface {
+	Create(r incident.Report, userID int64) (*incident.Incident, error)
+}
+
+// ControlChart строит контрольную карту по измерениям характеристики в порядке контроля:
+// границы, воспроизводимость относительно допуска и нарушения правил Western Electric
+func (s *Service) ControlChart(q SPCQuery) (*ControlChart, error) {
+	if !q.To.After(q.From) {
+		return nil, ErrInvalidPeriod
+	}
+	if q.To.Sub(q.From) > MaxSPCRange {
+		return nil, ErrRangeTooLong
+	}
+	chart, n, err := chartType(q.Chart, q.SubgroupSize)
+	if err != nil {
+		return nil, err
+	}
+
+	c, err := s.repo.GetCharacteristic(q.CharacteristicID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrCharacteristicNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	if c.Kind != KindNumeric {
+		return nil, ErrNotNumeric
+	}
+	plan, err := s.GetPlan(c.PlanID)
+	if err != nil {
+		return nil, err
+	}
+
+	var machineID *int64
+	if q.MachineID > 0 {
+		ok, err := s.repo.MachineExists(q.MachineID)
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			return nil, ErrMachineNotFound
+		}
+		machineID = &q.MachineID
+	}
+
+	samples, err := s.repo.Samples(c.ID, q.MachineID, q.From, q.To, maxSamples+1)
+	if err != nil {
+		return nil, err
+	}
+	if len(samples) > maxSamples {
+		return nil, ErrTooManySamples
+	}
+
+	cc := &ControlChart{
+		CharacteristicID: c.ID,
+		Name:             c.Name,
+		Unit:             c.Unit,
+		PlanID:           plan.ID,
+		ProductID:        plan.ProductID,
+		StageID:          plan.StageID,
+		MachineID:        machineID,
+		From:             q.From,
+		To:               q.To,
+		Chart:            chart,
+		SubgroupSize:     n,
+		Samples:          len(samples),
+	}
+	if c.Nominal != nil {
+		lsl, usl := *c.Nominal-*c.TolMinus, *c.Nominal+*c.TolPlus
+		cc.LSL, cc.USL = &lsl, &usl
+	}
+
+	if err := cc.build(samples); err != nil {
+		return nil, err
+	}
+	return cc, nil
+}
+
+// CheckControlChart строит карту и регистрирует инцидент, если в точках после последнего
+// уже переданного в инцидент результата контроля есть нарушения правил. Без новых нарушений инцидент не создается.
+func (s *Service) CheckControlChart(q SPCQuery, severityID int, userID int64) (*SPCCheck, error) {
+	cc, err := s.ControlChart(q)
+	if err != nil {
+		return nil, err
+	}
+
+	last, err := s.repo.LastAlerted(cc.CharacteristicID, q.MachineID)
+	if err != nil {
+		return nil, err
+	}
+
+	check := &SPCCheck{Chart: cc, NewViolations: make([]*Violation, 0)}
+	for _, v := range cc.Violations {
+		if cc.Points[v.Point].LastInspectionID > last {
+			check.NewViolations = append(check.NewViolations, v)
+		}
+	}
+	if len(check.NewViolations) == 0 {
+		return check, nil
+	}
+
+	if severityID == 0 {
+		severityID = DefaultSPCSeverity
+	}
+	report := incident.Report{
+		Title:       spcIncidentTitle(cc),
+		Description: spcIncidentDescription(cc, check.NewViolations),
+		SeverityID:  severityID,
+		MachineID:   cc.MachineID,
+	}
+	if check.Incident, err = s.incidents.Create(report, userID); err != nil {
+		return nil, err
+	}
+
+	err = s.repo.SaveAlert(&SPCAlert{
+		CharacteristicID: cc.CharacteristicID,
+		MachineID:        cc.MachineID,
+		LastInspectionID: cc.Points[len(cc.Points)-1].LastInspectionID,
+		IncidentID:       &check.Incident.ID,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return check, nil
+}
+
+// chartType проверяет тип карты и размер подгруппы, подставляя значения по умолчанию
+func chartType(chart string, n int) (string, int, error) {
+	switch chart {
+	case "":
+		if n == 0 {
+			n = DefaultSubgroupSize
+		}
+		switch {
+		case n < 1 || n > maxSubgroupSize:
+			return "", 0, ErrInvalidSubgroup
+		case n == 1:
+			chart = ChartIndividuals
+		case n <= maxRangeSubgroup:
+			chart = ChartXbarR
+		default:
+			chart = ChartXbarS
+		}
+	case ChartIndividuals:
+		if n == 0 {
+			n = 1
+		}
+		if n != 1 {
+			return "", 0, ErrInvalidSubgroup
+		}
+	case ChartXbarR, ChartXbarS:
+		if n == 0 {
+			n = DefaultSubgroupSize
+		}
+		limit := maxSubgroupSize
+		if chart == ChartXbarR {
+			limit = maxRangeSubgroup
+		}
+		if n < 2 || n > limit {
+			return "", 0, ErrInvalidSubgroup
+		}
+	default:
+		return "", 0, ErrInvalidChart
+	}
+	return chart, n, nil
+}
+
+// build делит измерения на подгруппы по порядку контроля (неполная последняя подгруппа отбрасывается),
+// считает точки, границы, воспроизводимость и нарушения
+func (cc *ControlChart) build(samples []*Sample) error {
+	n := cc.SubgroupSize
+	groups := len(samples) / n
+	cc.Excluded = len(samples) - groups*n
+	if groups < 2 {
+		return ErrNotEnoughData
+	}
+	samples = samples[:groups*n]
+
+	cc.Points = make([]*ChartPoint, 0, groups)
+	values := make([]float64, 0, len(samples))
+	var dispersions []float64
+	for i := range groups {
+		group := samples[i*n : (i+1)*n]
+		x := make([]float64, 0, n)
+		for _, s := range group {
+			x = append(x, s.Value)
+		}
+		values = append(values, x...)
+
+		p := &ChartPoint{
+			Index:            i,
+			From:             group[0].InspectedAt,
+			To:               group[n-1].InspectedAt,
+			LastInspectionID: group[n-1].InspectionID,
+			Value:            mean(x),
+		}
+
+		var d float64
+		switch cc.Chart {
+		case ChartIndividuals:
+			if i == 0 {
+				cc.Points = append(cc.Points, p)
+				continue
+			}
+			d = math.Abs(x[0] - cc.Points[i-1].Value)
+		case ChartXbarR:
+			d = slices.Max(x) - slices.Min(x)
+		case ChartXbarS:
+			d = stddev(x)
+		}
+		p.Dispersion = &d
+		dispersions = append(dispersions, d)
+		cc.Points = append(cc.Points, p)
+	}
+
+	// σ внутри подгрупп и коэффициент k границ карты разброса: UCL = (1 + k)·центр, LCL = (1 − k)·центр
+	center := mean(dispersions)
+	var sigma, k float64
+	switch cc.Chart {
+	case ChartIndividuals:
+		sigma, k = center/d2[2], 3*d3[2]/d2[2]
+	case ChartXbarR:
+		sigma, k = center/d2[n], 3*d3[n]/d2[n]
+	case ChartXbarS:
+		c := c4(n)
+		sigma, k = center/c, 3*math.Sqrt(1-c*c)/c
+	}
+
+	grand := mean(values)
+	spread := 3 * sigma / math.Sqrt(float64(n))
+	cc.X = Limits{Center: grand, UCL: grand + spread, LCL: grand - spread}
+	cc.Dispersion = Limits{Center: center, UCL: (1 + k) * center, LCL: max(0, (1-k)*center)}
+	cc.Capability = capability(grand, sigma, stddev(values), cc.LSL, cc.USL)
+	cc.Violations = westernElectric(cc)
+	return nil
+}
+
+// westernElectric проверяет правила 1–4 на карте средних и правило 1 на карте разброса
+func westernElectric(cc *ControlChart) []*Violation {
+	violations := make([]*Violation, 0)
+	add := func(rule int, chart string, i int, description string) {
+		violations = append(violations, &Violation{
+			Rule:        rule,
+			Chart:       chart,
+			Point:       i,
+			At:          cc.Points[i].To,
+			Description: description,
+		})
+	}
+
+	sigma := (cc.X.UCL - cc.X.Center) / 3
+	zones := make([]float64, len(cc.Points))
+	for i, p := range cc.Points {
+		if sigma > 0 {
+			zones[i] = (p.Value - cc.X.Center) / sigma
+		}
+	}
+
+	for i, p := range cc.Points {
+		if sigma > 0 {
+			z := zones[i]
+			if math.Abs(z) > 3 {
+				add(1, ChartX, i, "Точка за контрольной границей (3σ)")
+			}
+			if beyond(zones, i, 3, 2, 2) {
+				add(2, ChartX, i, "Две из трех точек подряд за 2σ по одну сторону от центра")
+			}
+			if beyond(zones, i, 5, 4, 1) {
+				add(3, ChartX, i, "Четыре из пяти точек подряд за 1σ по одну сторону от центра")
+			}
+			if beyond(zones, i, 8, 8, 0) {
+				add(4, ChartX, i, "Восемь точек подряд по одну сторону от центра")
+			}
+		}
+
+		if p.Dispersion != nil && (*p.Dispersion > cc.Dispersion.UCL || *p.Dispersion < cc.Dispersion.LCL) {
+			add(1, ChartDispersion, i, "Разброс за контрольной границей")
+		}
+	}
+	return violations
+}
+
+// beyond есть ли среди window точек, заканчивающихся точкой i, не меньше count точек дальше limit σ
+// по ту же сторону от центра, что и точка i; сама точка i должна быть за limit
+func beyond(zones []float64, i, window, count int, limit float64) bool {
+	if i+1 < window {
+		return false
+	}
+	side := math.Copysign(1, zones[i])
+	if zones[i] == 0 || zones[i]*side <= limit {
+		return false
+	}
+
+	n := 0
+	for _, z := range zones[i+1-window : i+1] {
+		if z*side > limit {
+			n++
+		}
+	}
+	return n >= count
+}
+
+// capability индексы воспроизводимости по двусторонним границам допуска
+func capability(mean, sigmaWithin, sigmaOverall float64, lsl, usl *float64) *Capability {
+	c := &Capability{Mean: mean, SigmaWithin: sigmaWithin, SigmaOverall: sigmaOverall}
+	if lsl == nil || usl == nil {
+		return c
+	}
+
+	index := func(sigma float64) (*float64, *float64) {
+		if sigma <= 0 {
+			return nil, nil
+		}
+		p := round2((*usl - *lsl) / (6 * sigma))
+		pk := round2(min(*usl-mean, mean-*lsl) / (3 * sigma))
+		return &p, &pk
+	}
+	c.Cp, c.Cpk = index(sigmaWithin)
+	c.Pp, c.Ppk = index(sigmaOverall)
+	return c
+}
+
+func spcIncidentTitle(cc *ControlChart) string {
+	name := []rune(cc.Name)
+	if len(name) > maxIncidentName {
+		name = append(name[:maxIncidentName], '…')
+	}
+	return fmt.Sprintf("Нарушение контрольной карты: %s", string(name))
+}
+
+func spcIncidentDescription(cc *ControlChart, violations []*Violation) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "Характеристика #%d, план #%d, карта %s (n=%d), центр %.4g, границы [%.4g; %.4g]",
+		cc.CharacteristicID, cc.PlanID, cc.Chart, cc.SubgroupSize, cc.X.Center, cc.X.LCL, cc.X.UCL)
+	for _, v := range violations {
+		fmt.Fprintf(&b, "\nПравило %d (%s), точка %d, %s: %s",
+			v.Rule, v.Chart, v.Point, v.At.Format(time.DateTime), v.Description)
+	}
+	return b.String()
+}
+
+// c4 поправочный коэффициент выборочного стандартного отклонения подгруппы размера n
+func c4(n int) float64 {
+	a, _ := math.Lgamma(float64(n) / 2)
+	b, _ := math.Lgamma(float64(n-1) / 2)
+	return math.Sqrt(2/float64(n-1)) * math.Exp(a-b)
+}
+
+func mean(x []float64) float64 {
+	var sum float64
+	for _, v := range x {
+		sum += v
+	}
+	return sum / float64(len(x))
+}
+
+// stddev выборочное стандартное отклонение
+func stddev(x []float64) float64 {
+	if len(x) < 2 {
+		return 0
+	}
+	m := mean(x)
+	var sum float64
+	for _, v := range x {
+		sum += (v - m) * (v - m)
+	}
+	return math.Sqrt(sum / float64(len(x)-1))
+}
+
+func round2(v float64) float64 {
+	return math.Round(v*100) / 100
+}
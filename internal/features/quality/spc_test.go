@@ -0,0 +1,274 @@
+package quality
+
+import (
+	"errors"
+	"math"
+	"testing"
+	"time"
+)
+
+func approx(a, b, eps float64) bool {
+	return math.Abs(a-b) <= eps
+}
+
+func TestRangeConstants(t *testing.T) {
+	// A2 = 3/(d2·√n) и D4 = 1 + 3·d3/d2 из стандартных таблиц коэффициентов карт
+	tests := []struct {
+		n      int
+		d2, d3 float64
+		a2, d4 float64
+	}{
+		{2, 1.128, 0.853, 1.880, 3.267},
+		{3, 1.693, 0.888, 1.023, 2.574},
+		{4, 2.059, 0.880, 0.729, 2.282},
+		{5, 2.326, 0.864, 0.577, 2.114},
+		{6, 2.534, 0.848, 0.483, 2.004},
+		{7, 2.704, 0.833, 0.419, 1.924},
+		{8, 2.847, 0.820, 0.373, 1.864},
+		{9, 2.970, 0.808, 0.337, 1.816},
+		{10, 3.078, 0.797, 0.308, 1.777},
+	}
+	for _, tt := range tests {
+		if d2[tt.n] != tt.d2 || d3[tt.n] != tt.d3 {
+			t.Errorf("n=%d: d2=%v d3=%v, want %v %v", tt.n, d2[tt.n], d3[tt.n], tt.d2, tt.d3)
+		}
+		if a2 := 3 / (d2[tt.n] * math.Sqrt(float64(tt.n))); !approx(a2, tt.a2, 0.001) {
+			t.Errorf("n=%d: A2=%.4f, want %.3f", tt.n, a2, tt.a2)
+		}
+		if d4 := 1 + 3*d3[tt.n]/d2[tt.n]; !approx(d4, tt.d4, 0.002) {
+			t.Errorf("n=%d: D4=%.4f, want %.3f", tt.n, d4, tt.d4)
+		}
+	}
+	if len(d2) != maxRangeSubgroup+1 || len(d3) != maxRangeSubgroup+1 {
+		t.Errorf("range constants must cover subgroups up to %d", maxRangeSubgroup)
+	}
+}
+
+func TestC4(t *testing.T) {
+	tests := []struct {
+		n    int
+		want float64
+	}{
+		{2, 0.7979},
+		{3, 0.8862},
+		{4, 0.9213},
+		{5, 0.9400},
+		{10, 0.9727},
+		{25, 0.9896},
+	}
+	for _, tt := range tests {
+		if got := c4(tt.n); !approx(got, tt.want, 0.00005) {
+			t.Errorf("c4(%d) = %.5f, want %.4f", tt.n, got, tt.want)
+		}
+	}
+}
+
+func TestChartType(t *testing.T) {
+	tests := []struct {
+		chart   string
+		n       int
+		want    string
+		wantN   int
+		wantErr error
+	}{
+		{"", 0, ChartXbarR, DefaultSubgroupSize, nil},
+		{"", 1, ChartIndividuals, 1, nil},
+		{"", 2, ChartXbarR, 2, nil},
+		{"", 10, ChartXbarR, 10, nil},
+		{"", 11, ChartXbarS, 11, nil},
+		{"", 25, ChartXbarS, 25, nil},
+		{"", 26, "", 0, ErrInvalidSubgroup},
+		{"", -1, "", 0, ErrInvalidSubgroup},
+		{ChartIndividuals, 0, ChartIndividuals, 1, nil},
+		{ChartIndividuals, 2, "", 0, ErrInvalidSubgroup},
+		{ChartXbarR, 0, ChartXbarR, DefaultSubgroupSize, nil},
+		{ChartXbarR, 1, "", 0, ErrInvalidSubgroup},
+		{ChartXbarR, 11, "", 0, ErrInvalidSubgroup},
+		{ChartXbarS, 2, ChartXbarS, 2, nil},
+		{ChartXbarS, 25, ChartXbarS, 25, nil},
+		{ChartXbarS, 26, "", 0, ErrInvalidSubgroup},
+		{"p", 5, "", 0, ErrInvalidChart},
+	}
+	for _, tt := range tests {
+		chart, n, err := chartType(tt.chart, tt.n)
+		if !errors.Is(err, tt.wantErr) {
+			t.Errorf("chartType(%q, %d): err = %v, want %v", tt.chart, tt.n, err, tt.wantErr)
+			continue
+		}
+		if chart != tt.want || n != tt.wantN {
+			t.Errorf("chartType(%q, %d) = %q, %d, want %q, %d", tt.chart, tt.n, chart, n, tt.want, tt.wantN)
+		}
+	}
+}
+
+func TestBeyond(t *testing.T) {
+	tests := []struct {
+		name   string
+		zones  []float64
+		i      int
+		window int
+		count  int
+		limit  float64
+		want   bool
+	}{
+		{"правило 2: две из трех за 2σ", []float64{2.5, 0.1, 2.1}, 2, 3, 2, 2, true},
+		{"правило 2: окно не набрано", []float64{2.5, 2.1}, 1, 3, 2, 2, false},
+		{"правило 2: последняя точка в зоне", []float64{2.5, 2.1, 1.0}, 2, 3, 2, 2, false},
+		{"правило 2: по разные стороны", []float64{-2.5, 0.1, 2.1}, 2, 3, 2, 2, false},
+		{"правило 2: снизу", []float64{-2.5, 0.1, -2.1}, 2, 3, 2, 2, true},
+		{"правило 2: точка ровно на 2σ", []float64{2.5, 0.1, 2.0}, 2, 3, 2, 2, false},
+		{"правило 3: четыре из пяти за 1σ", []float64{1.5, 1.2, 0.3, 1.1, 1.9}, 4, 5, 4, 1, true},
+		{"правило 3: три из пяти", []float64{1.5, 0.2, 0.3, 1.1, 1.9}, 4, 5, 4, 1, false},
+		{"правило 3: окно смещено", []float64{0, 1.5, 1.2, 0.3, 1.1, 1.9}, 5, 5, 4, 1, true},
+		{"правило 3: точки вне окна не считаются", []float64{1.5, 1.5, 0.2, 0.3, 1.1, 1.9}, 5, 5, 4, 1, false},
+		{"правило 4: восемь подряд", []float64{0.1, 0.2, 0.3, 0.1, 0.5, 0.2, 0.1, 0.4}, 7, 8, 8, 0, true},
+		{"правило 4: одна на центре", []float64{0.1, 0.2, 0, 0.1, 0.5, 0.2, 0.1, 0.4}, 7, 8, 8, 0, false},
+		{"правило 4: одна с другой стороны", []float64{0.1, 0.2, -0.3, 0.1, 0.5, 0.2, 0.1, 0.4}, 7, 8, 8, 0, false},
+		{"правило 4: последняя на центре", []float64{0.1, 0.2, 0.3, 0.1, 0.5, 0.2, 0.1, 0}, 7, 8, 8, 0, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := beyond(tt.zones, tt.i, tt.window, tt.count, tt.limit); got != tt.want {
+				t.Errorf("beyond = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCapability(t *testing.T) {
+	ptr := func(v float64) *float64 { return &v }
+
+	tests := []struct {
+		name               string
+		mean, within, over float64
+		lsl, usl           *float64
+		cp, cpk, pp, ppk   *float64
+	}{
+		{"по центру допуска", 10, 1, 2, ptr(4), ptr(16), ptr(2), ptr(2), ptr(1), ptr(1)},
+		{"смещение к верхней границе", 12, 1, 1, ptr(4), ptr(16), ptr(2), ptr(1.33), ptr(2), ptr(1.33)},
+		{"смещение к нижней границе", 7, 1, 1, ptr(4), ptr(16), ptr(2), ptr(1), ptr(2), ptr(1)},
+		{"среднее за границей", 17, 1, 1, ptr(4), ptr(16), ptr(2), ptr(-0.33), ptr(2), ptr(-0.33)},
+		{"без допуска", 10, 1, 1, nil, nil, nil, nil, nil, nil},
+		{"односторонний допуск", 10, 1, 1, ptr(4), nil, nil, nil, nil, nil},
+		{"нулевой разброс", 10, 0, 1, ptr(4), ptr(16), nil, nil, ptr(2), ptr(2)},
+	}
+	eq := func(got, want *float64) bool {
+		if got == nil || want == nil {
+			return got == want
+		}
+		return *got == *want
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := capability(tt.mean, tt.within, tt.over, tt.lsl, tt.usl)
+			if !eq(c.Cp, tt.cp) || !eq(c.Cpk, tt.cpk) || !eq(c.Pp, tt.pp) || !eq(c.Ppk, tt.ppk) {
+				t.Errorf("capability = Cp %v Cpk %v Pp %v Ppk %v, want %v %v %v %v",
+					deref(c.Cp), deref(c.Cpk), deref(c.Pp), deref(c.Ppk),
+					deref(tt.cp), deref(tt.cpk), deref(tt.pp), deref(tt.ppk))
+			}
+		})
+	}
+}
+
+func deref(v *float64) any {
+	if v == nil {
+		return nil
+	}
+	return *v
+}
+
+func samplesOf(values ...float64) []*Sample {
+	start := time.Date(2026, 10, 1, 8, 0, 0, 0, time.UTC)
+	samples := make([]*Sample, 0, len(values))
+	for i, v := range values {
+		samples = append(samples, &Sample{
+			InspectionID: int64(i + 1),
+			InspectedAt:  start.Add(time.Duration(i) * time.Minute),
+			Value:        v,
+		})
+	}
+	return samples
+}
+
+func TestBuildIndividuals(t *testing.T) {
+	cc := &ControlChart{Chart: ChartIndividuals, SubgroupSize: 1}
+	if err := cc.build(samplesOf(10, 12, 11, 13)); err != nil {
+		t.Fatalf("build: %v", err)
+	}
+
+	// скользящие размахи 2, 1, 2: центр 5/3, σ = центр/d2(2)
+	mr := 5.0 / 3
+	sigma := mr / 1.128
+	if !approx(cc.Dispersion.Center, mr, 1e-9) {
+		t.Errorf("MR center = %v, want %v", cc.Dispersion.Center, mr)
+	}
+	if !approx(cc.X.Center, 11.5, 1e-9) || !approx(cc.X.UCL, 11.5+3*sigma, 1e-9) || !approx(cc.X.LCL, 11.5-3*sigma, 1e-9) {
+		t.Errorf("X limits = %+v", cc.X)
+	}
+	if !approx(cc.Dispersion.UCL, 3.267*mr, 0.005) || cc.Dispersion.LCL != 0 {
+		t.Errorf("MR limits = %+v", cc.Dispersion)
+	}
+	if cc.Points[0].Dispersion != nil {
+		t.Errorf("first individuals point must have no moving range")
+	}
+	if len(cc.Violations) != 0 {
+		t.Errorf("violations = %d, want none", len(cc.Violations))
+	}
+}
+
+func TestBuildXbarR(t *testing.T) {
+	lsl, usl := 9.0, 13.0
+	cc := &ControlChart{Chart: ChartXbarR, SubgroupSize: 2, LSL: &lsl, USL: &usl}
+	// последнее измерение не образует полной подгруппы
+	if err := cc.build(samplesOf(10, 12, 11, 11, 10, 12, 99)); err != nil {
+		t.Fatalf("build: %v", err)
+	}
+
+	if cc.Excluded != 1 || len(cc.Points) != 3 {
+		t.Fatalf("excluded = %d, points = %d, want 1 and 3", cc.Excluded, len(cc.Points))
+	}
+	if cc.Points[2].LastInspectionID != 6 {
+		t.Errorf("last inspection of point 2 = %d, want 6", cc.Points[2].LastInspectionID)
+	}
+
+	// размахи 2, 0, 2: R̄ = 4/3, σ = R̄/d2(2), границы X̄ = 11 ± A2·R̄
+	rbar := 4.0 / 3
+	if !approx(cc.X.UCL-cc.X.Center, 1.880*rbar, 0.001) {
+		t.Errorf("X spread = %v, want %v", cc.X.UCL-cc.X.Center, 1.880*rbar)
+	}
+	sigma := rbar / 1.128
+	if got := *cc.Capability.Cp; got != round2(4/(6*sigma)) {
+		t.Errorf("Cp = %v, want %v", got, round2(4/(6*sigma)))
+	}
+}
+
+func TestBuildNotEnoughData(t *testing.T) {
+	cc := &ControlChart{Chart: ChartXbarR, SubgroupSize: 5}
+	if err := cc.build(samplesOf(1, 2, 3, 4, 5, 6, 7, 8, 9)); !errors.Is(err, ErrNotEnoughData) {
+		t.Errorf("err = %v, want ErrNotEnoughData", err)
+	}
+}
+
+func TestBuildRuleOneViolation(t *testing.T) {
+	values := make([]float64, 0, 21)
+	for i := range 20 {
+		values = append(values, 10+float64(i%2))
+	}
+	values = append(values, 30)
+
+	cc := &ControlChart{Chart: ChartIndividuals, SubgroupSize: 1}
+	if err := cc.build(samplesOf(values...)); err != nil {
+		t.Fatalf("build: %v", err)
+	}
+
+	var x, mr bool
+	for _, v := range cc.Violations {
+		if v.Rule == 1 && v.Point == 20 {
+			x = x || v.Chart == ChartX
+			mr = mr || v.Chart == ChartDispersion
+		}
+	}
+	if !x || !mr {
+		t.Errorf("violations = %+v, want rule 1 at point 20 on both charts", cc.Violations)
+	}
+}
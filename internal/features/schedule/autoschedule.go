@@ -0,0 +1,665 @@
+package schedule
+
+import (
+	"cmp"
+	"errors"
+	"math"
+	"slices"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+var (
+	ErrEmptyCommit     = errors.New("nothing to commit")
+	ErrProductNotFound = errors.New("product not found")
+	ErrCommitFrom      = errors.New("commit needs the planning start of the preview")
+	ErrPlanChanged     = errors.New("schedule changed after the preview")
+)
+
+// interval занятый период машины; product — продукт заказа, под который машина занята
+type interval struct {
+	start, end time.Time
+	product    int64
+}
+
+// placement размещение заказа на машине: наладка [start, run), выпуск [run, end)
+type placement struct {
+	start, run, end time.Time
+}
+
+// PlanHorizon горизонт рабочего календаря, в пределах которого размещаются заказы
+const PlanHorizon = 180 * 24 * time.Hour
+
+// Preview строит предложение автопланирования, не изменяя расписание.
+//
+// Заказы упорядочиваются по весу приоритета (по убыванию), сроку (без срока — в конце)
+// и дате создания. Работа длительностью tech_cycle_min × quantity выполняется в рабочее
+// время календаря линии машины и ставится в самое раннее окно той машины, на которой
+// закончится раньше всего; наладка и выпуск делятся на слоты по рабочим окнам календаря,
+// так что перерывы между сменами не входят в занятость. Если до заказа машина выпускала
+// другой продукт, перед выпуском ставится слот наладки по матрице переналадок.
+// Кандидаты — назначенная заказу машина, иначе допустимые машины продукта; неисправные
+// и выключенные машины не используются.
+func (s *Service) Preview(req PlanRequest) (*Plan, error) {
+	plan, _, err := s.plan(req)
+	return plan, err
+}
+
+// Compare сравнивает текущее расписание с предложением на общем горизонте
+func (s *Service) Compare(req PlanRequest) (*Comparison, error) {
+	plan, in, err := s.plan(req)
+	if err != nil {
+		return nil, err
+	}
+
+	current := make([]*PlannedSlot, 0, len(in.existing))
+	for _, sl := range in.existing {
+		current = append(current, in.planned(sl))
+	}
+	proposed := slices.Concat(in.kept(), plan.Slots)
+
+	end := plan.From
+	for _, sl := range slices.Concat(current, proposed) {
+		if sl.EndTime.After(end) {
+			end = sl.EndTime
+		}
+	}
+
+	return &Comparison{
+		Current:  metrics(current, in, plan.From, end),
+		Proposed: metrics(proposed, in, plan.From, end),
+		Plan:     plan,
+	}, nil
+}
+
+// Commit фиксирует предложение автопланирования. Предложение заново строится на сервере
+// по параметрам предпросмотра и сверяется с присланным: если расписание, заказы или календарь
+// изменились, возвращается ErrPlanChanged. Фиксируется только серверное предложение, поэтому
+// снимаются лишь слоты перепланируемых заказов, а новые стоят на допустимых машинах в рабочее время.
+func (s *Service) Commit(req CommitRequest, userID int64) ([]*Slot, error) {
+	if req.From.IsZero() {
+		return nil, ErrCommitFrom
+	}
+
+	plan, _, err := s.plan(PlanRequest{
+		From:         &req.From,
+		WorkOrderIDs: req.WorkOrderIDs,
+		Reschedule:   req.Reschedule,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if !sameProposal(plan, req) {
+		return nil, ErrPlanChanged
+	}
+	if len(plan.Slots) == 0 && len(plan.ReplacedSlotIDs) == 0 {
+		return nil, ErrEmptyCommit
+	}
+
+	slots := make([]*Slot, 0, len(plan.Slots))
+	for _, p := range plan.Slots {
+		slots = append(slots, &Slot{
+			WorkOrderID: p.WorkOrderID,
+			MachineID:   p.MachineID,
+			Kind:        p.Kind,
+			StartTime:   p.StartTime,
+			EndTime:     p.EndTime,
+			CreatedBy:   &userID,
+		})
+	}
+
+	// машина удалена между построением предложения и фиксацией
+	err = s.repo.Commit(plan.ReplacedSlotIDs, slots)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrPlanChanged
+	}
+	if err != nil {
+		return nil, err
+	}
+	return slots, nil
+}
+
+// slotKey слот предложения; время в наносекундах, чтобы не зависеть от часового пояса клиента
+type slotKey struct {
+	workOrderID, machineID int64
+	kind                   string
+	start, end             int64
+}
+
+func keyOf(sl *PlannedSlot) slotKey {
+	return slotKey{sl.WorkOrderID, sl.MachineID, sl.Kind, sl.StartTime.UnixNano(), sl.EndTime.UnixNano()}
+}
+
+// sameProposal совпадает ли предложение plan с присланным клиентом; порядок слотов не важен,
+// слоты предложения не пересекаются на машине и поэтому не повторяются
+func sameProposal(plan *Plan, req CommitRequest) bool {
+	if len(plan.Slots) != len(req.Slots) {
+		return false
+	}
+	if !slices.Equal(slices.Sorted(slices.Values(plan.ReplacedSlotIDs)), slices.Sorted(slices.Values(req.ReplacedSlotIDs))) {
+		return false
+	}
+
+	want := make(map[slotKey]bool, len(plan.Slots))
+	for _, sl := range plan.Slots {
+		want[keyOf(sl)] = true
+	}
+	for _, sl := range req.Slots {
+		if sl == nil || !want[keyOf(sl)] {
+			return false
+		}
+		delete(want, keyOf(sl))
+	}
+	return true
+}
+
+// ProductMachines допустимые машины продукта
+func (s *Service) ProductMachines(productID int64) ([]int64, error) {
+	if err := s.checkProduct(productID); err != nil {
+		return nil, err
+	}
+	return s.repo.ProductMachines(productID)
+}
+
+// SetProductMachines заменяет список допустимых машин продукта
+func (s *Service) SetProductMachines(productID int64, machineIDs []int64) ([]int64, error) {
+	if err := s.checkProduct(productID); err != nil {
+		return nil, err
+	}
+
+	ids := slices.Clone(machineIDs)
+	slices.Sort(ids)
+	ids = slices.Compact(ids)
+
+	for _, id := range ids {
+		ok, err := s.repo.MachineExists(id)
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			return nil, ErrMachineNotFound
+		}
+	}
+
+	if err := s.repo.SetProductMachines(productID, ids); err != nil {
+		return nil, err
+	}
+	return ids, nil
+}
+
+// planInput исходные данные планирования
+type planInput struct {
+	from     time.Time
+	orders   map[int64]*PlanOrder
+	machines []*PlanMachine
+	// existing слоты, заканчивающиеся после from; replaced — снимаемые из них
+	existing []*Slot
+	replaced map[int64]bool
+}
+
+func (in *planInput) kept() []*PlannedSlot {
+	out := make([]*PlannedSlot, 0, len(in.existing))
+	for _, sl := range in.existing {
+		if !in.replaced[sl.ID] {
+			out = append(out, in.planned(sl))
+		}
+	}
+	return out
+}
+
+func (in *planInput) planned(sl *Slot) *PlannedSlot {
+	p := &PlannedSlot{
+		WorkOrderID: sl.WorkOrderID,
+		MachineID:   sl.MachineID,
+		Kind:        sl.Kind,
+		StartTime:   sl.StartTime,
+		EndTime:     sl.EndTime,
+	}
+	if o, ok := in.orders[sl.WorkOrderID]; ok {
+		p.WONumber = o.WONumber
+		p.Deadline = o.Deadline
+		p.LatenessMin = lateness(sl.EndTime, o.Deadline)
+	}
+	return p
+}
+
+func (s *Service) plan(req PlanRequest) (*Plan, *planInput, error) {
+	from := s.now().Truncate(time.Minute)
+	if req.From != nil {
+		from = *req.From
+	}
+
+	orders, err := s.repo.ReleasedOrders(req.WorkOrderIDs)
+	if err != nil {
+		return nil, nil, err
+	}
+	machines, err := s.repo.PlanMachines()
+	if err != nil {
+		return nil, nil, err
+	}
+	existing, err := s.repo.List(ListFilter{From: &from})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	in := &planInput{
+		from:     from,
+		orders:   make(map[int64]*PlanOrder, len(orders)),
+		machines: machines,
+		existing: existing,
+		replaced: make(map[int64]bool),
+	}
+	for _, o := range orders {
+		in.orders[o.ID] = o
+	}
+
+	// заказы с актуальными слотами перепланируются только с Reschedule, с начавшимся слотом — никогда;
+	// заказ, все слоты которого остались в прошлом, планируется заново
+	scheduled := make(map[int64]bool)
+	started := make(map[int64]bool)
+	for _, sl := range existing {
+		scheduled[sl.WorkOrderID] = true
+		if sl.StartTime.Before(from) {
+			started[sl.WorkOrderID] = true
+		}
+	}
+
+	var queue []*PlanOrder
+	for _, o := range orders {
+		if started[o.ID] || (scheduled[o.ID] && !req.Reschedule) {
+			continue
+		}
+		queue = append(queue, o)
+	}
+
+	plan := &Plan{
+		From:            from,
+		Slots:           []*PlannedSlot{},
+		ReplacedSlotIDs: []int64{},
+		Unplaced:        []*Unplaced{},
+	}
+	for _, sl := range existing {
+		if req.Reschedule && slices.ContainsFunc(queue, func(o *PlanOrder) bool { return o.ID == sl.WorkOrderID }) {
+			in.replaced[sl.ID] = true
+			plan.ReplacedSlotIDs = append(plan.ReplacedSlotIDs, sl.ID)
+		}
+	}
+
+	productIDs := make([]int64, 0, len(queue))
+	for _, o := range queue {
+		productIDs = append(productIDs, o.ProductID)
+	}
+	eligible := map[int64][]int64{}
+	if len(productIDs) > 0 {
+		if eligible, err = s.repo.EligibleMachines(productIDs); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	// продукты занятых периодов и последний выпущенный продукт машин — для наладки
+	kept := in.kept()
+	keptOrders := make([]int64, 0, len(kept))
+	for _, sl := range kept {
+		keptOrders = append(keptOrders, sl.WorkOrderID)
+	}
+	products := map[int64]int64{}
+	if len(keptOrders) > 0 {
+		if products, err = s.repo.OrderProducts(keptOrders); err != nil {
+			return nil, nil, err
+		}
+	}
+	last, err := s.repo.LastProducts(from)
+	if err != nil {
+		return nil, nil, err
+	}
+	matrix := changeoverMatrix{}
+	if len(productIDs) > 0 {
+		rows, err := s.repo.ChangeoversFor(productIDs)
+		if err != nil {
+			return nil, nil, err
+		}
+		matrix = newChangeoverMatrix(rows)
+	}
+
+	busy := make(map[int64][]interval, len(machines))
+	for _, sl := range kept {
+		busy[sl.MachineID] = append(busy[sl.MachineID], interval{sl.StartTime, sl.EndTime, products[sl.WorkOrderID]})
+	}
+	for id := range busy {
+		sortIntervals(busy[id])
+	}
+
+	// рабочее время машин по календарю их линий
+	working, err := s.machineHours(machines, from)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	slices.SortStableFunc(queue, compareOrders)
+
+	for _, o := range queue {
+		if o.TechCycleMin == nil || *o.TechCycleMin <= 0 {
+			plan.Unplaced = append(plan.Unplaced, &Unplaced{WorkOrderID: o.ID, WONumber: o.WONumber, Reason: ReasonNoTechCycle})
+			continue
+		}
+		duration := time.Duration(*o.TechCycleMin*o.Quantity) * time.Minute
+
+		candidates := eligible[o.ProductID]
+		if o.MachineID != nil {
+			candidates = []int64{*o.MachineID}
+		}
+
+		var (
+			best   int64
+			chosen placement
+			reason = ReasonNoMachine
+		)
+		for _, machineID := range candidates {
+			hours, ok := working[machineID]
+			if !ok {
+				continue
+			}
+			setup := matrix.setupFunc(o.ProductID, machineID, lineOf(machines, machineID))
+			p, ok := place(busy[machineID], hours, from, duration, last[machineID], setup)
+			if !ok {
+				reason = ReasonNoWorkingTime
+				continue
+			}
+			if best == 0 || p.end.Before(chosen.end) {
+				best, chosen = machineID, p
+			}
+		}
+		if best == 0 {
+			plan.Unplaced = append(plan.Unplaced, &Unplaced{WorkOrderID: o.ID, WONumber: o.WONumber, Reason: reason})
+			continue
+		}
+
+		busy[best] = append(busy[best], interval{chosen.start, chosen.end, o.ProductID})
+		sortIntervals(busy[best])
+
+		// наладка и выпуск делятся на слоты по рабочим окнам: перерывы между сменами не заняты
+		for _, iv := range within(working[best], chosen.start, chosen.run) {
+			plan.Slots = append(plan.Slots, &PlannedSlot{
+				WorkOrderID: o.ID,
+				WONumber:    o.WONumber,
+				MachineID:   best,
+				Kind:        KindSetup,
+				StartTime:   iv.start,
+				EndTime:     iv.end,
+				Deadline:    o.Deadline,
+			})
+		}
+		for _, iv := range within(working[best], chosen.run, chosen.end) {
+			plan.Slots = append(plan.Slots, &PlannedSlot{
+				WorkOrderID: o.ID,
+				WONumber:    o.WONumber,
+				MachineID:   best,
+				Kind:        KindRun,
+				StartTime:   iv.start,
+				EndTime:     iv.end,
+				Deadline:    o.Deadline,
+				LatenessMin: lateness(iv.end, o.Deadline),
+			})
+		}
+	}
+
+	horizon := from
+	for _, sl := range slices.Concat(kept, plan.Slots) {
+		if sl.EndTime.After(horizon) {
+			horizon = sl.EndTime
+		}
+	}
+	plan.Metrics = metrics(slices.Concat(kept, plan.Slots), in, from, horizon)
+
+	return plan, in, nil
+}
+
+// machineHours рабочее время машин по календарям их линий
+func (s *Service) machineHours(machines []*PlanMachine, from time.Time) (map[int64][]interval, error) {
+	working := make(map[int64][]interval, len(machines))
+	byLine := make(map[int64][]interval)
+
+	for _, m := range machines {
+		var lineID int64
+		if m.LineID != nil {
+			lineID = *m.LineID
+		}
+		if _, ok := byLine[lineID]; !ok {
+			hours, err := s.workingHours(lineID, from)
+			if err != nil {
+				return nil, err
+			}
+			byLine[lineID] = hours
+		}
+		working[m.ID] = byLine[lineID]
+	}
+	return working, nil
+}
+
+// workingHours рабочее время линии на горизонте планирования от from
+func (s *Service) workingHours(lineID int64, from time.Time) ([]interval, error) {
+	ivs, err := s.calendar.WorkingIntervals(lineID, from, from.Add(PlanHorizon))
+	if err != nil {
+		return nil, err
+	}
+
+	hours := make([]interval, 0, len(ivs))
+	for _, iv := range ivs {
+		hours = append(hours, interval{start: iv.Start, end: iv.End})
+	}
+	return hours, nil
+}
+
+func (s *Service) checkProduct(productID int64) error {
+	ok, err := s.repo.ProductExists(productID)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return ErrProductNotFound
+	}
+	return nil
+}
+
+// compareOrders порядок планирования: приоритет, срок, дата создания
+func compareOrders(a, b *PlanOrder) int {
+	if c := cmp.Compare(b.PriorityWeight, a.PriorityWeight); c != 0 {
+		return c
+	}
+	switch {
+	case a.Deadline != nil && b.Deadline == nil:
+		return -1
+	case a.Deadline == nil && b.Deadline != nil:
+		return 1
+	case a.Deadline != nil && b.Deadline != nil:
+		if c := a.Deadline.Compare(*b.Deadline); c != 0 {
+			return c
+		}
+	}
+	if c := a.CreatedAt.Compare(b.CreatedAt); c != 0 {
+		return c
+	}
+	return cmp.Compare(a.ID, b.ID)
+}
+
+// place самое раннее размещение работы длительностью duration не раньше from.
+// Работа идет только в рабочее время hours и может прерываться между сменами;
+// перед ней ставится наладка setup(prev), где prev — продукт предыдущего занятого периода
+// машины (initial, если до него ничего нет). Размещение занимает машину от начала наладки
+// до окончания работы и не должно пересекать busy. Оба списка упорядочены по началу.
+func place(busy, hours []interval, from time.Time, duration time.Duration, initial int64, setup func(prev int64) time.Duration) (placement, bool) {
+	t := from
+	for {
+		su := setup(previous(busy, initial, t))
+		start, end, ok := fit(hours, t, su+duration)
+		if !ok {
+			return placement{}, false
+		}
+
+		i := slices.IndexFunc(busy, func(iv interval) bool { return iv.start.Before(end) && start.Before(iv.end) })
+		if i >= 0 {
+			t = busy[i].end
+			continue
+		}
+		// до начала рабочего окна мог закончиться другой период — наладка считается от него
+		if setup(previous(busy, initial, start)) != su {
+			t = start
+			continue
+		}
+
+		run := start
+		if su > 0 {
+			_, run, _ = fit(hours, start, su)
+		}
+		return placement{start: start, run: run, end: end}, true
+	}
+}
+
+// previous продукт последнего периода busy, закончившегося не позже t
+func previous(busy []interval, initial int64, t time.Time) int64 {
+	prev := initial
+	for _, iv := range busy {
+		if !iv.end.After(t) {
+			prev = iv.product
+		}
+	}
+	return prev
+}
+
+// fit начало и окончание работы длительностью duration в рабочем времени hours начиная с t
+func fit(hours []interval, t time.Time, duration time.Duration) (time.Time, time.Time, bool) {
+	var start time.Time
+	left := duration
+
+	for _, iv := range hours {
+		if !iv.end.After(t) {
+			continue
+		}
+		from := iv.start
+		if from.Before(t) {
+			from = t
+		}
+		if start.IsZero() {
+			start = from
+		}
+
+		if avail := iv.end.Sub(from); avail >= left {
+			return start, from.Add(left), true
+		} else {
+			left -= avail
+		}
+	}
+	return time.Time{}, time.Time{}, false
+}
+
+// within части периода [start, end), приходящиеся на рабочее время hours
+func within(hours []interval, start, end time.Time) []interval {
+	var out []interval
+	for _, iv := range hours {
+		from, to := iv.start, iv.end
+		if from.Before(start) {
+			from = start
+		}
+		if to.After(end) {
+			to = end
+		}
+		if to.After(from) {
+			out = append(out, interval{start: from, end: to})
+		}
+	}
+	return out
+}
+
+func lineOf(machines []*PlanMachine, machineID int64) *int64 {
+	for _, m := range machines {
+		if m.ID == machineID {
+			return m.LineID
+		}
+	}
+	return nil
+}
+
+func sortIntervals(ivs []interval) {
+	slices.SortFunc(ivs, func(a, b interval) int { return a.start.Compare(b.start) })
+}
+
+// metrics показатели расписания slots на горизонте [from, end).
+// Опоздания и размещенность считаются по заказам планирования, загрузка — по всем слотам.
+func metrics(slots []*PlannedSlot, in *planInput, from, end time.Time) *Metrics {
+	m := &Metrics{Machines: make([]*MachineLoad, 0, len(in.machines))}
+	if end.After(from) {
+		m.HorizonEnd = &end
+	}
+
+	finish := make(map[int64]time.Time)
+	busy := make(map[int64]float64)
+	setup := make(map[int64]float64)
+	for _, sl := range slots {
+		if _, ok := in.orders[sl.WorkOrderID]; ok && sl.EndTime.After(finish[sl.WorkOrderID]) {
+			finish[sl.WorkOrderID] = sl.EndTime
+		}
+
+		start := sl.StartTime
+		if start.Before(from) {
+			start = from
+		}
+		if sl.EndTime.After(start) {
+			busy[sl.MachineID] += sl.EndTime.Sub(start).Minutes()
+			if sl.Kind == KindSetup {
+				setup[sl.MachineID] += sl.EndTime.Sub(start).Minutes()
+			}
+		}
+	}
+
+	for id, o := range in.orders {
+		at, ok := finish[id]
+		if !ok {
+			m.UnscheduledOrders++
+			continue
+		}
+		m.ScheduledOrders++
+		if late := lateness(at, o.Deadline); late > 0 {
+			m.LateOrders++
+			m.TotalLatenessMin += late
+			m.MaxLatenessMin = max(m.MaxLatenessMin, late)
+		}
+	}
+
+	horizon := end.Sub(from).Minutes()
+	var total float64
+	for _, machine := range in.machines {
+		load := &MachineLoad{
+			MachineID: machine.ID,
+			Code:      machine.Code,
+			BusyMin:   round1(busy[machine.ID]),
+			SetupMin:  round1(setup[machine.ID]),
+		}
+		if horizon > 0 {
+			load.Utilization = round1(busy[machine.ID] / horizon * 100)
+		}
+		total += load.Utilization
+		m.Machines = append(m.Machines, load)
+	}
+	if len(in.machines) > 0 {
+		m.Utilization = round1(total / float64(len(in.machines)))
+	}
+	m.TotalLatenessMin = round1(m.TotalLatenessMin)
+	m.MaxLatenessMin = round1(m.MaxLatenessMin)
+
+	return m
+}
+
+// lateness опоздание в минутах относительно конца дня срока
+func lateness(end time.Time, deadline *time.Time) float64 {
+	if !late(end, deadline) {
+		return 0
+	}
+	y, m, d := deadline.Date()
+	endOfDay := time.Date(y, m, d, 23, 59, 59, 0, end.Location())
+	return round1(end.Sub(endOfDay).Minutes())
+}
+
+func round1(v float64) float64 {
+	return math.Round(v*10) / 10
+}
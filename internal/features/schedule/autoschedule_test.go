@@ -0,0 +1,270 @@
+package schedule
+
+import (
+	"testing"
+	"time"
+)
+
+// at время 2 ноября 2026 (day = 0) или следующих дней
+func at(day, hour, minute int) time.Time {
+	return time.Date(2026, 11, 2+day, hour, minute, 0, 0, time.UTC)
+}
+
+// testHours две смены с обедом в первый день и утренняя смена во второй
+var testHours = []interval{
+	{start: at(0, 8, 0), end: at(0, 12, 0)},
+	{start: at(0, 13, 0), end: at(0, 17, 0)},
+	{start: at(1, 8, 0), end: at(1, 12, 0)},
+}
+
+func TestFit(t *testing.T) {
+	tests := []struct {
+		name       string
+		from       time.Time
+		duration   time.Duration
+		start, end time.Time
+		ok         bool
+	}{
+		{"в начале смены", at(0, 8, 0), 2 * time.Hour, at(0, 8, 0), at(0, 10, 0), true},
+		{"до начала смены", at(0, 6, 0), time.Hour, at(0, 8, 0), at(0, 9, 0), true},
+		{"через обед", at(0, 11, 0), 2 * time.Hour, at(0, 11, 0), at(0, 14, 0), true},
+		{"в обед", at(0, 12, 30), time.Hour, at(0, 13, 0), at(0, 14, 0), true},
+		{"ровно до конца окна", at(0, 16, 0), time.Hour, at(0, 16, 0), at(0, 17, 0), true},
+		{"на следующий день", at(0, 16, 0), 5 * time.Hour, at(0, 16, 0), at(1, 12, 0), true},
+		{"не хватает рабочего времени", at(0, 16, 0), 5*time.Hour + time.Minute, time.Time{}, time.Time{}, false},
+		{"после горизонта", at(1, 12, 0), time.Minute, time.Time{}, time.Time{}, false},
+		{"нулевая длительность в конце окна", at(0, 12, 0), 0, at(0, 13, 0), at(0, 13, 0), true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			start, end, ok := fit(testHours, tt.from, tt.duration)
+			if ok != tt.ok || !start.Equal(tt.start) || !end.Equal(tt.end) {
+				t.Errorf("fit = %v, %v, %v, want %v, %v, %v", start, end, ok, tt.start, tt.end, tt.ok)
+			}
+		})
+	}
+}
+
+func TestWithin(t *testing.T) {
+	tests := []struct {
+		name       string
+		start, end time.Time
+		want       []interval
+	}{
+		{"внутри окна", at(0, 9, 0), at(0, 10, 0), []interval{{start: at(0, 9, 0), end: at(0, 10, 0)}}},
+		{"через обед", at(0, 11, 0), at(0, 14, 0), []interval{
+			{start: at(0, 11, 0), end: at(0, 12, 0)},
+			{start: at(0, 13, 0), end: at(0, 14, 0)},
+		}},
+		{"через ночь", at(0, 16, 0), at(1, 9, 0), []interval{
+			{start: at(0, 16, 0), end: at(0, 17, 0)},
+			{start: at(1, 8, 0), end: at(1, 9, 0)},
+		}},
+		{"в обед", at(0, 12, 0), at(0, 13, 0), nil},
+		{"пустой период", at(0, 9, 0), at(0, 9, 0), nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := within(testHours, tt.start, tt.end)
+			if len(got) != len(tt.want) {
+				t.Fatalf("within = %v, want %v", got, tt.want)
+			}
+			for i := range got {
+				if !got[i].start.Equal(tt.want[i].start) || !got[i].end.Equal(tt.want[i].end) {
+					t.Errorf("within[%d] = %v–%v, want %v–%v", i, got[i].start, got[i].end, tt.want[i].start, tt.want[i].end)
+				}
+			}
+		})
+	}
+}
+
+func TestPlace(t *testing.T) {
+	const product = 7
+	// наладка на продукт 7: с того же продукта не нужна, с другого — 30 минут
+	setup := func(prev int64) time.Duration {
+		if prev == product {
+			return 0
+		}
+		return 30 * time.Minute
+	}
+
+	tests := []struct {
+		name     string
+		busy     []interval
+		initial  int64
+		from     time.Time
+		duration time.Duration
+		want     placement
+		ok       bool
+	}{
+		{
+			name:     "свободная машина с тем же продуктом",
+			initial:  product,
+			from:     at(0, 8, 0),
+			duration: 2 * time.Hour,
+			want:     placement{start: at(0, 8, 0), run: at(0, 8, 0), end: at(0, 10, 0)},
+			ok:       true,
+		},
+		{
+			name:     "наладка с другого продукта",
+			initial:  5,
+			from:     at(0, 8, 0),
+			duration: time.Hour,
+			want:     placement{start: at(0, 8, 0), run: at(0, 8, 30), end: at(0, 9, 30)},
+			ok:       true,
+		},
+		{
+			name:     "после занятого периода того же продукта",
+			busy:     []interval{{start: at(0, 8, 0), end: at(0, 9, 0), product: product}},
+			initial:  5,
+			from:     at(0, 8, 0),
+			duration: time.Hour,
+			want:     placement{start: at(0, 9, 0), run: at(0, 9, 0), end: at(0, 10, 0)},
+			ok:       true,
+		},
+		{
+			name:     "после занятого периода другого продукта",
+			busy:     []interval{{start: at(0, 8, 0), end: at(0, 9, 0), product: 5}},
+			initial:  product,
+			from:     at(0, 8, 0),
+			duration: time.Hour,
+			want:     placement{start: at(0, 9, 0), run: at(0, 9, 30), end: at(0, 10, 30)},
+			ok:       true,
+		},
+		{
+			name:     "занятый период вне смены меняет наладку",
+			busy:     []interval{{start: at(0, 6, 0), end: at(0, 7, 0), product: product}},
+			initial:  5,
+			from:     at(0, 5, 0),
+			duration: time.Hour,
+			want:     placement{start: at(0, 8, 0), run: at(0, 8, 0), end: at(0, 9, 0)},
+			ok:       true,
+		},
+		{
+			name:     "наладка и выпуск через обед",
+			initial:  5,
+			from:     at(0, 11, 45),
+			duration: time.Hour,
+			want:     placement{start: at(0, 11, 45), run: at(0, 13, 15), end: at(0, 14, 15)},
+			ok:       true,
+		},
+		{
+			name: "в промежуток между периодами не помещается",
+			busy: []interval{
+				{start: at(0, 8, 0), end: at(0, 9, 0), product: product},
+				{start: at(0, 10, 0), end: at(0, 12, 0), product: product},
+			},
+			initial:  product,
+			from:     at(0, 8, 0),
+			duration: 90 * time.Minute,
+			want:     placement{start: at(0, 13, 0), run: at(0, 13, 0), end: at(0, 14, 30)},
+			ok:       true,
+		},
+		{
+			name:     "не помещается в горизонт",
+			initial:  product,
+			from:     at(0, 8, 0),
+			duration: 13 * time.Hour,
+			ok:       false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := place(tt.busy, testHours, tt.from, tt.duration, tt.initial, setup)
+			if ok != tt.ok {
+				t.Fatalf("place ok = %v, want %v", ok, tt.ok)
+			}
+			if !got.start.Equal(tt.want.start) || !got.run.Equal(tt.want.run) || !got.end.Equal(tt.want.end) {
+				t.Errorf("place = %v / %v / %v, want %v / %v / %v",
+					got.start, got.run, got.end, tt.want.start, tt.want.run, tt.want.end)
+			}
+		})
+	}
+}
+
+func TestSameProposal(t *testing.T) {
+	slot := func(wo int64, kind string, start, end time.Time) *PlannedSlot {
+		return &PlannedSlot{WorkOrderID: wo, MachineID: 1, Kind: kind, StartTime: start, EndTime: end}
+	}
+	plan := &Plan{
+		Slots: []*PlannedSlot{
+			slot(1, "setup", at(0, 8, 0), at(0, 8, 30)),
+			slot(1, "run", at(0, 8, 30), at(0, 12, 0)),
+			slot(1, "run", at(0, 13, 0), at(0, 14, 0)),
+		},
+		ReplacedSlotIDs: []int64{4, 9},
+	}
+	moscow := time.FixedZone("MSK", 3*60*60)
+
+	tests := []struct {
+		name     string
+		slots    []*PlannedSlot
+		replaced []int64
+		want     bool
+	}{
+		{"то же предложение", plan.Slots, []int64{4, 9}, true},
+		{"другой порядок", []*PlannedSlot{plan.Slots[2], plan.Slots[0], plan.Slots[1]}, []int64{9, 4}, true},
+		{"другой часовой пояс клиента", []*PlannedSlot{
+			slot(1, "setup", at(0, 8, 0).In(moscow), at(0, 8, 30).In(moscow)),
+			slot(1, "run", at(0, 8, 30).In(moscow), at(0, 12, 0).In(moscow)),
+			slot(1, "run", at(0, 13, 0).In(moscow), at(0, 14, 0).In(moscow)),
+		}, []int64{4, 9}, true},
+		{"сдвинут слот", []*PlannedSlot{plan.Slots[0], plan.Slots[1], slot(1, "run", at(0, 13, 0), at(0, 14, 1))}, []int64{4, 9}, false},
+		{"повтор вместо слота", []*PlannedSlot{plan.Slots[0], plan.Slots[1], plan.Slots[1]}, []int64{4, 9}, false},
+		{"лишний слот", append([]*PlannedSlot{slot(2, "run", at(1, 8, 0), at(1, 9, 0))}, plan.Slots...), []int64{4, 9}, false},
+		{"пустой слот", []*PlannedSlot{plan.Slots[0], plan.Slots[1], nil}, []int64{4, 9}, false},
+		{"другие заменяемые слоты", plan.Slots, []int64{4}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := CommitRequest{Slots: tt.slots, ReplacedSlotIDs: tt.replaced}
+			if got := sameProposal(plan, req); got != tt.want {
+				t.Errorf("sameProposal = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCompareOrders(t *testing.T) {
+	early, later := at(1, 0, 0), at(3, 0, 0)
+	created := at(-10, 0, 0)
+
+	tests := []struct {
+		name string
+		a, b PlanOrder
+		want int
+	}{
+		{"выше приоритет", PlanOrder{ID: 2, PriorityWeight: 3}, PlanOrder{ID: 1, PriorityWeight: 2, Deadline: &early}, -1},
+		{"срок раньше", PlanOrder{ID: 2, Deadline: &early}, PlanOrder{ID: 1, Deadline: &later}, -1},
+		{"со сроком раньше без срока", PlanOrder{ID: 2, Deadline: &later}, PlanOrder{ID: 1}, -1},
+		{"без срока позже", PlanOrder{ID: 1}, PlanOrder{ID: 2, Deadline: &later}, 1},
+		{"создан раньше", PlanOrder{ID: 2, CreatedAt: created}, PlanOrder{ID: 1, CreatedAt: created.Add(time.Hour)}, -1},
+		{"по ID", PlanOrder{ID: 1, CreatedAt: created}, PlanOrder{ID: 2, CreatedAt: created}, -1},
+	}
+	for _, tt := range tests {
+		if got := compareOrders(&tt.a, &tt.b); got != tt.want {
+			t.Errorf("%s: compareOrders = %d, want %d", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestLateness(t *testing.T) {
+	deadline := time.Date(2026, 11, 2, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		end      time.Time
+		deadline *time.Time
+		want     float64
+	}{
+		{at(0, 23, 59), &deadline, 0},
+		{at(1, 0, 0), &deadline, 0},
+		{at(1, 0, 30), &deadline, 30},
+		{at(2, 0, 0), &deadline, 1440},
+		{at(5, 0, 0), nil, 0},
+	}
+	for _, tt := range tests {
+		if got := lateness(tt.end, tt.deadline); got != tt.want {
+			t.Errorf("lateness(%v) = %v, want %v", tt.end, got, tt.want)
+		}
+	}
+}
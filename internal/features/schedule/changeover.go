@@ -0,0 +1,195 @@
+package schedule
+
+import (
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+var (
+	ErrChangeoverNotFound = errors.New("changeover not found")
+	ErrChangeoverExists   = errors.New("changeover for this transition and scope already exists")
+	ErrChangeoverScope    = errors.New("changeover must be set either for a machine or for a line")
+	ErrSameProduct        = errors.New("changeover must switch between different products")
+	ErrInvalidSetup       = errors.New("setup time must not be negative")
+	ErrLineNotFound       = errors.New("line not found")
+)
+
+func (s *Service) ListChangeovers(filter ChangeoverFilter) ([]*Changeover, error) {
+	return s.repo.ListChangeovers(filter)
+}
+
+func (s *Service) GetChangeover(id int64) (*Changeover, error) {
+	c, err := s.repo.GetChangeover(id)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrChangeoverNotFound
+	}
+	return c, err
+}
+
+func (s *Service) CreateChangeover(c *Changeover) error {
+	if err := s.validateChangeover(c); err != nil {
+		return err
+	}
+
+	c.ID = 0
+	return s.repo.SaveChangeover(c)
+}
+
+func (s *Service) UpdateChangeover(c *Changeover) error {
+	existing, err := s.GetChangeover(c.ID)
+	if err != nil {
+		return err
+	}
+	if err := s.validateChangeover(c); err != nil {
+		return err
+	}
+
+	existing.FromProductID = c.FromProductID
+	existing.ToProductID = c.ToProductID
+	existing.MachineID = c.MachineID
+	existing.LineID = c.LineID
+	existing.SetupMin = c.SetupMin
+
+	if err := s.repo.SaveChangeover(existing); err != nil {
+		return err
+	}
+
+	*c = *existing
+	return nil
+}
+
+func (s *Service) DeleteChangeover(id int64) error {
+	c, err := s.GetChangeover(id)
+	if err != nil {
+		return err
+	}
+	return s.repo.DeleteChangeover(c)
+}
+
+// ChangeoverTime действующее время переналадки машины с продукта fromProductID на toProductID
+func (s *Service) ChangeoverTime(machineID, fromProductID, toProductID int64) (*ChangeoverTime, error) {
+	m, err := s.repo.PlanMachine(machineID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrMachineNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	for _, id := range []int64{fromProductID, toProductID} {
+		if err := s.checkProduct(id); err != nil {
+			return nil, err
+		}
+	}
+
+	rows, err := s.repo.MatchChangeovers(fromProductID, toProductID)
+	if err != nil {
+		return nil, err
+	}
+
+	t := &ChangeoverTime{
+		MachineID:     machineID,
+		FromProductID: fromProductID,
+		ToProductID:   toProductID,
+	}
+	c, source := newChangeoverMatrix(rows).resolve(fromProductID, toProductID, machineID, m.LineID)
+	t.Source = source
+	if c != nil {
+		t.SetupMin = c.SetupMin
+		t.ChangeoverID = &c.ID
+	}
+	return t, nil
+}
+
+func (s *Service) validateChangeover(c *Changeover) error {
+	if c.FromProductID == c.ToProductID {
+		return ErrSameProduct
+	}
+	if c.MachineID != nil && c.LineID != nil {
+		return ErrChangeoverScope
+	}
+	if c.SetupMin < 0 {
+		return ErrInvalidSetup
+	}
+
+	for _, id := range []int64{c.FromProductID, c.ToProductID} {
+		if err := s.checkProduct(id); err != nil {
+			return err
+		}
+	}
+	if c.MachineID != nil {
+		ok, err := s.repo.MachineExists(*c.MachineID)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			return ErrMachineNotFound
+		}
+	}
+	if c.LineID != nil {
+		ok, err := s.repo.LineExists(*c.LineID)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			return ErrLineNotFound
+		}
+	}
+	return nil
+}
+
+// changeoverMatrix строки матрицы переналадок по переходу (с продукта, на продукт)
+type changeoverMatrix map[[2]int64][]*Changeover
+
+func newChangeoverMatrix(rows []*Changeover) changeoverMatrix {
+	m := make(changeoverMatrix, len(rows))
+	for _, c := range rows {
+		key := [2]int64{c.FromProductID, c.ToProductID}
+		m[key] = append(m[key], c)
+	}
+	return m
+}
+
+// resolve строка матрицы для перехода на машине: строка машины, затем линии, затем общезаводская.
+// Без предыдущего продукта (from = 0) и при том же продукте наладка не нужна.
+func (m changeoverMatrix) resolve(from, to, machineID int64, lineID *int64) (*Changeover, string) {
+	if from == 0 || from == to {
+		return nil, SourceNone
+	}
+
+	var byLine, plant *Changeover
+	for _, c := range m[[2]int64{from, to}] {
+		switch {
+		case c.MachineID != nil:
+			if *c.MachineID == machineID {
+				return c, SourceMachine
+			}
+		case c.LineID != nil:
+			if lineID != nil && *c.LineID == *lineID {
+				byLine = c
+			}
+		default:
+			plant = c
+		}
+	}
+
+	switch {
+	case byLine != nil:
+		return byLine, SourceLine
+	case plant != nil:
+		return plant, SourcePlant
+	}
+	return nil, SourceNone
+}
+
+// setupFunc наладка машины перед выпуском продукта to в зависимости от предыдущего продукта
+func (m changeoverMatrix) setupFunc(to, machineID int64, lineID *int64) func(prev int64) time.Duration {
+	return func(prev int64) time.Duration {
+		c, _ := m.resolve(prev, to, machineID, lineID)
+		if c == nil {
+			return 0
+		}
+		return time.Duration(c.SetupMin) * time.Minute
+	}
+}
@@ -0,0 +1,58 @@
+package schedule
+
+import (
+	"testing"
+	"time"
+)
+
+func TestChangeoverResolve(t *testing.T) {
+	id := func(v int64) *int64 { return &v }
+	line1, line2 := id(1), id(2)
+
+	m := newChangeoverMatrix([]*Changeover{
+		{ID: 1, FromProductID: 10, ToProductID: 20, SetupMin: 60},
+		{ID: 2, FromProductID: 10, ToProductID: 20, LineID: line1, SetupMin: 45},
+		{ID: 3, FromProductID: 10, ToProductID: 20, MachineID: id(100), SetupMin: 30},
+		{ID: 4, FromProductID: 20, ToProductID: 10, LineID: line2, SetupMin: 15},
+		{ID: 5, FromProductID: 10, ToProductID: 30, MachineID: id(101), SetupMin: 25},
+	})
+
+	tests := []struct {
+		name       string
+		from, to   int64
+		machineID  int64
+		lineID     *int64
+		wantID     int64
+		wantSource string
+	}{
+		{"строка машины важнее линии и завода", 10, 20, 100, line1, 3, SourceMachine},
+		{"строка линии важнее завода", 10, 20, 200, line1, 2, SourceLine},
+		{"машина без линии — общезаводская", 10, 20, 200, nil, 1, SourcePlant},
+		{"чужая линия — общезаводская", 10, 20, 200, line2, 1, SourcePlant},
+		{"переход несимметричен", 20, 10, 200, line2, 4, SourceLine},
+		{"обратный переход на другой линии не задан", 20, 10, 200, line1, 0, SourceNone},
+		{"строка другой машины не применяется", 10, 30, 100, nil, 0, SourceNone},
+		{"без предыдущего продукта", 0, 20, 100, line1, 0, SourceNone},
+		{"тот же продукт", 20, 20, 100, line1, 0, SourceNone},
+		{"переход не задан", 30, 40, 100, line1, 0, SourceNone},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, source := m.resolve(tt.from, tt.to, tt.machineID, tt.lineID)
+			var gotID int64
+			if c != nil {
+				gotID = c.ID
+			}
+			if gotID != tt.wantID || source != tt.wantSource {
+				t.Errorf("resolve = #%d %s, want #%d %s", gotID, source, tt.wantID, tt.wantSource)
+			}
+		})
+	}
+
+	setup := m.setupFunc(20, 100, line1)
+	for prev, want := range map[int64]time.Duration{0: 0, 10: 30 * time.Minute, 20: 0, 30: 0} {
+		if got := setup(prev); got != want {
+			t.Errorf("setup(%d) = %v, want %v", prev, got, want)
+		}
+	}
+}
@@ -0,0 +1,792 @@
+package schedule
+
+import (
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"time"
+
+	"mes-lite-back/internal/http/middleware"
+	"mes-lite-back/pkg"
+
+	"github.com/go-chi/chi/v5"
+)
+
+type Handler struct {
+	service ServiceInterface
+	perms   middleware.PermissionChecker
+}
+
+func NewHandler(service ServiceInterface, perms middleware.PermissionChecker) *Handler {
+	return &Handler{
+		service: service,
+		perms:   perms,
+	}
+}
+
+func (h *Handler) Routes() chi.Router {
+	r := chi.NewRouter()
+
+	view := middleware.PermissionGuard(h.perms, "schedule.view")
+	edit := middleware.PermissionGuard(h.perms, "schedule.edit")
+
+	r.With(view).Get("/", h.list)
+	r.With(view).Get("/gantt", h.gantt)
+	r.With(view).Post("/check", h.check)
+	r.With(view).Post("/auto/preview", h.preview)
+	r.With(view).Post("/auto/compare", h.compare)
+	r.With(edit).Post("/auto/commit", h.commit)
+	r.With(view).Get("/products/{productID}/machines", h.productMachines)
+	r.With(edit).Put("/products/{productID}/machines", h.setProductMachines)
+	r.With(view).Get("/changeovers", h.listChangeovers)
+	r.With(view).Get("/changeovers/lookup", h.changeoverTime)
+	r.With(view).Get("/changeovers/{id}", h.getChangeover)
+	r.With(edit).Post("/changeovers", h.createChangeover)
+	r.With(edit).Put("/changeovers/{id}", h.updateChangeover)
+	r.With(edit).Delete("/changeovers/{id}", h.deleteChangeover)
+	r.With(view).Get("/proposals", h.listProposals)
+	r.With(edit).Post("/proposals", h.propose)
+	r.With(view).Get("/proposals/{id}", h.getProposal)
+	r.With(edit).Post("/proposals/{id}/commit", h.commitProposal)
+	r.With(edit).Post("/proposals/{id}/reject", h.rejectProposal)
+	r.With(view).Get("/{id}", h.getByID)
+	r.With(edit).Post("/", h.create)
+	r.With(edit).Put("/{id}", h.update)
+	r.With(edit).Delete("/{id}", h.delete)
+
+	return r
+}
+
+type SlotRequest struct {
+	WorkOrderID int64     `json:"work_order_id" example:"1"`
+	MachineID   int64     `json:"machine_id" example:"1"`
+	Kind        string    `json:"kind,omitempty" example:"run"`
+	StartTime   time.Time `json:"start_time" example:"2026-11-02T08:00:00Z"`
+	EndTime     time.Time `json:"end_time" example:"2026-11-02T16:00:00Z"`
+}
+
+// ChangeoverRequest строка матрицы переналадок; без machine_id и line_id — общезаводская
+type ChangeoverRequest struct {
+	FromProductID int64  `json:"from_product_id" example:"1"`
+	ToProductID   int64  `json:"to_product_id" example:"2"`
+	MachineID     *int64 `json:"machine_id,omitempty" example:"1"`
+	LineID        *int64 `json:"line_id,omitempty"`
+	SetupMin      int    `json:"setup_min" example:"45"`
+}
+
+type ProductMachinesRequest struct {
+	MachineIDs []int64 `json:"machine_ids" example:"1,2"`
+}
+
+type ErrorResponse struct {
+	Error string `json:"error" example:"Описание ошибки"`
+}
+
+// ConflictResponse слот пересекается с запланированными на той же машине
+type ConflictResponse struct {
+	Error     string  `json:"error" example:"Машина занята в указанный период"`
+	Conflicts []*Slot `json:"conflicts"`
+}
+
+// ListSlots godoc
+// @Summary Получить слоты расписания
+// @Description Возвращает слоты, пересекающиеся с периодом, с фильтрами по машине, линии и заказу
+// @Tags schedule
+// @Security BearerAuth
+// @Produce json
+// @Param from query string false "Начало периода (RFC3339 или ГГГГ-ММ-ДД)"
+// @Param to query string false "Конец периода (RFC3339 или ГГГГ-ММ-ДД включительно)"
+// @Param machine_id query int false "ID машины"
+// @Param line_id query int false "ID линии"
+// @Param work_order_id query int false "ID заказа"
+// @Success 200 {array} Slot
+// @Failure 400 {object} ErrorResponse
+// @Router /schedule [get]
+func (h *Handler) list(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	machineID, _ := strconv.ParseInt(query.Get("machine_id"), 10, 64)
+	lineID, _ := strconv.ParseInt(query.Get("line_id"), 10, 64)
+	workOrderID, _ := strconv.ParseInt(query.Get("work_order_id"), 10, 64)
+
+	from, ok := parseBound(w, query.Get("from"), false)
+	if !ok {
+		return
+	}
+	to, ok := parseBound(w, query.Get("to"), true)
+	if !ok {
+		return
+	}
+
+	slots, err := h.service.ListSlots(ListFilter{
+		From:        from,
+		To:          to,
+		MachineID:   machineID,
+		LineID:      lineID,
+		WorkOrderID: workOrderID,
+	})
+	if err != nil {
+		h.respondError(w, err)
+		return
+	}
+	pkg.RespondJSON(w, http.StatusOK, slots)
+}
+
+// GanttSchedule godoc
+// @Summary Диаграмма Ганта
+// @Description Слоты за период, сгруппированные по линиям и машинам; свободные машины включаются с пустым списком
+// @Tags schedule
+// @Security BearerAuth
+// @Produce json
+// @Param from query string true "Начало периода (RFC3339 или ГГГГ-ММ-ДД)"
+// @Param to query string true "Конец периода (RFC3339 или ГГГГ-ММ-ДД включительно)"
+// @Param line_id query int false "ID линии"
+// @Success 200 {object} Gantt
+// @Failure 400 {object} ErrorResponse
+// @Router /schedule/gantt [get]
+func (h *Handler) gantt(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	lineID, _ := strconv.ParseInt(query.Get("line_id"), 10, 64)
+
+	from, ok := parseBound(w, query.Get("from"), false)
+	if !ok {
+		return
+	}
+	to, ok := parseBound(w, query.Get("to"), true)
+	if !ok {
+		return
+	}
+	if from == nil || to == nil {
+		pkg.RespondJSON(w, http.StatusBadRequest, ErrorResponse{Error: "Укажите период from и to"})
+		return
+	}
+
+	g, err := h.service.Gantt(*from, *to, lineID)
+	if err != nil {
+		h.respondError(w, err)
+		return
+	}
+	pkg.RespondJSON(w, http.StatusOK, g)
+}
+
+// CheckSlot godoc
+// @Summary Проверить слот на пересечения
+// @Description Возвращает слоты той же машины, с которыми пересекся бы слот; ничего не сохраняет
+// @Tags schedule
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param request body SlotRequest true "Слот"
+// @Success 200 {array} Slot
+// @Failure 400 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Router /schedule/check [post]
+func (h *Handler) check(w http.ResponseWriter, r *http.Request) {
+	var req SlotRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		pkg.RespondJSON(w, http.StatusBadRequest, ErrorResponse{Error: "Некорректный JSON"})
+		return
+	}
+
+	conflicts, err := h.service.CheckSlot(req.slot())
+	if err != nil {
+		h.respondError(w, err)
+		return
+	}
+	pkg.RespondJSON(w, http.StatusOK, conflicts)
+}
+
+// GetSlot godoc
+// @Summary Получить слот по ID
+// @Tags schedule
+// @Security BearerAuth
+// @Produce json
+// @Param id path int true "ID слота"
+// @Success 200 {object} Slot
+// @Failure 404 {object} ErrorResponse
+// @Router /schedule/{id} [get]
+func (h *Handler) getByID(w http.ResponseWriter, r *http.Request) {
+	slot, err := h.service.GetSlot(pkg.ParamID(r))
+	if err != nil {
+		h.respondError(w, err)
+		return
+	}
+	pkg.RespondJSON(w, http.StatusOK, slot)
+}
+
+// CreateSlot godoc
+// @Summary Запланировать заказ на машину
+// @Description Создает слот; пересечение с другими слотами машины возвращает 409 со списком конфликтов
+// @Tags schedule
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param request body SlotRequest true "Слот"
+// @Success 201 {object} Slot
+// @Failure 400 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 409 {object} ConflictResponse
+// @Router /schedule [post]
+func (h *Handler) create(w http.ResponseWriter, r *http.Request) {
+	var req SlotRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		pkg.RespondJSON(w, http.StatusBadRequest, ErrorResponse{Error: "Некорректный JSON"})
+		return
+	}
+
+	slot := req.slot()
+	userID := currentUser(r)
+	slot.CreatedBy = &userID
+
+	if err := h.service.CreateSlot(slot); err != nil {
+		h.respondError(w, err)
+		return
+	}
+	pkg.RespondJSON(w, http.StatusCreated, slot)
+}
+
+// UpdateSlot godoc
+// @Summary Перенести слот
+// @Description Меняет заказ, машину или время слота с проверкой пересечений
+// @Tags schedule
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param id path int true "ID слота"
+// @Param request body SlotRequest true "Слот"
+// @Success 200 {object} Slot
+// @Failure 400 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 409 {object} ConflictResponse
+// @Router /schedule/{id} [put]
+func (h *Handler) update(w http.ResponseWriter, r *http.Request) {
+	var req SlotRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		pkg.RespondJSON(w, http.StatusBadRequest, ErrorResponse{Error: "Некорректный JSON"})
+		return
+	}
+
+	slot := req.slot()
+	slot.ID = pkg.ParamID(r)
+
+	if err := h.service.UpdateSlot(slot); err != nil {
+		h.respondError(w, err)
+		return
+	}
+	pkg.RespondJSON(w, http.StatusOK, slot)
+}
+
+// DeleteSlot godoc
+// @Summary Удалить слот
+// @Tags schedule
+// @Security BearerAuth
+// @Param id path int true "ID слота"
+// @Success 204
+// @Failure 404 {object} ErrorResponse
+// @Router /schedule/{id} [delete]
+func (h *Handler) delete(w http.ResponseWriter, r *http.Request) {
+	if err := h.service.DeleteSlot(pkg.ParamID(r)); err != nil {
+		h.respondError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// PreviewSchedule godoc
+// @Summary Предложение автопланирования
+// @Description Размещает выпущенные заказы на допустимых машинах с учетом приоритета, срока, такта и занятости; расписание не меняется
+// @Tags schedule
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param request body PlanRequest true "Параметры планирования"
+// @Success 200 {object} Plan
+// @Failure 400 {object} ErrorResponse
+// @Router /schedule/auto/preview [post]
+func (h *Handler) preview(w http.ResponseWriter, r *http.Request) {
+	var req PlanRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		pkg.RespondJSON(w, http.StatusBadRequest, ErrorResponse{Error: "Некорректный JSON"})
+		return
+	}
+
+	plan, err := h.service.Preview(req)
+	if err != nil {
+		h.respondError(w, err)
+		return
+	}
+	pkg.RespondJSON(w, http.StatusOK, plan)
+}
+
+// CompareSchedule godoc
+// @Summary Сравнить расписание с предложением
+// @Description Опоздания, размещенность заказов и загрузка машин текущего и предлагаемого расписания на общем горизонте
+// @Tags schedule
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param request body PlanRequest true "Параметры планирования"
+// @Success 200 {object} Comparison
+// @Failure 400 {object} ErrorResponse
+// @Router /schedule/auto/compare [post]
+func (h *Handler) compare(w http.ResponseWriter, r *http.Request) {
+	var req PlanRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		pkg.RespondJSON(w, http.StatusBadRequest, ErrorResponse{Error: "Некорректный JSON"})
+		return
+	}
+
+	cmp, err := h.service.Compare(req)
+	if err != nil {
+		h.respondError(w, err)
+		return
+	}
+	pkg.RespondJSON(w, http.StatusOK, cmp)
+}
+
+// CommitSchedule godoc
+// @Summary Зафиксировать предложение
+// @Description Заново строит предложение по параметрам предпросмотра (from — начало из ответа предпросмотра) и сверяет его с присланными слотами; при расхождении возвращает 409. Снимаемые слоты удаляются, предложенные создаются одной транзакцией
+// @Tags schedule
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param request body CommitRequest true "Параметры и слоты предпросмотра"
+// @Success 201 {array} Slot
+// @Failure 400 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse
+// @Router /schedule/auto/commit [post]
+func (h *Handler) commit(w http.ResponseWriter, r *http.Request) {
+	var req CommitRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		pkg.RespondJSON(w, http.StatusBadRequest, ErrorResponse{Error: "Некорректный JSON"})
+		return
+	}
+
+	slots, err := h.service.Commit(req, currentUser(r))
+	if err != nil {
+		h.respondError(w, err)
+		return
+	}
+	pkg.RespondJSON(w, http.StatusCreated, slots)
+}
+
+// ProductMachines godoc
+// @Summary Допустимые машины продукта
+// @Tags schedule
+// @Security BearerAuth
+// @Produce json
+// @Param productID path int true "ID продукта"
+// @Success 200 {array} int
+// @Failure 404 {object} ErrorResponse
+// @Router /schedule/products/{productID}/machines [get]
+func (h *Handler) productMachines(w http.ResponseWriter, r *http.Request) {
+	ids, err := h.service.ProductMachines(pkg.ParamInt64(r, "productID"))
+	if err != nil {
+		h.respondError(w, err)
+		return
+	}
+	pkg.RespondJSON(w, http.StatusOK, ids)
+}
+
+// SetProductMachines godoc
+// @Summary Задать допустимые машины продукта
+// @Description Заменяет список машин, на которых автопланирование может размещать заказы продукта
+// @Tags schedule
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param productID path int true "ID продукта"
+// @Param request body ProductMachinesRequest true "Машины"
+// @Success 200 {array} int
+// @Failure 400 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Router /schedule/products/{productID}/machines [put]
+func (h *Handler) setProductMachines(w http.ResponseWriter, r *http.Request) {
+	var req ProductMachinesRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		pkg.RespondJSON(w, http.StatusBadRequest, ErrorResponse{Error: "Некорректный JSON"})
+		return
+	}
+
+	ids, err := h.service.SetProductMachines(pkg.ParamInt64(r, "productID"), req.MachineIDs)
+	if err != nil {
+		h.respondError(w, err)
+		return
+	}
+	pkg.RespondJSON(w, http.StatusOK, ids)
+}
+
+// ListChangeovers godoc
+// @Summary Матрица переналадок
+// @Description Строки матрицы с фильтрами по машине, линии и продукту (исходному или целевому)
+// @Tags schedule
+// @Security BearerAuth
+// @Produce json
+// @Param machine_id query int false "ID машины"
+// @Param line_id query int false "ID линии"
+// @Param product_id query int false "ID продукта"
+// @Success 200 {array} Changeover
+// @Router /schedule/changeovers [get]
+func (h *Handler) listChangeovers(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	machineID, _ := strconv.ParseInt(query.Get("machine_id"), 10, 64)
+	lineID, _ := strconv.ParseInt(query.Get("line_id"), 10, 64)
+	productID, _ := strconv.ParseInt(query.Get("product_id"), 10, 64)
+
+	changeovers, err := h.service.ListChangeovers(ChangeoverFilter{
+		MachineID: machineID,
+		LineID:    lineID,
+		ProductID: productID,
+	})
+	if err != nil {
+		h.respondError(w, err)
+		return
+	}
+	pkg.RespondJSON(w, http.StatusOK, changeovers)
+}
+
+// ChangeoverTime godoc
+// @Summary Время переналадки машины
+// @Description Действующее время переналадки: строка машины, затем линии машины, затем общезаводская; без строки — 0
+// @Tags schedule
+// @Security BearerAuth
+// @Produce json
+// @Param machine_id query int true "ID машины"
+// @Param from_product_id query int true "ID текущего продукта"
+// @Param to_product_id query int true "ID следующего продукта"
+// @Success 200 {object} ChangeoverTime
+// @Failure 400 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Router /schedule/changeovers/lookup [get]
+func (h *Handler) changeoverTime(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	machineID, err1 := strconv.ParseInt(query.Get("machine_id"), 10, 64)
+	fromID, err2 := strconv.ParseInt(query.Get("from_product_id"), 10, 64)
+	toID, err3 := strconv.ParseInt(query.Get("to_product_id"), 10, 64)
+	if err := errors.Join(err1, err2, err3); err != nil {
+		pkg.RespondJSON(w, http.StatusBadRequest, ErrorResponse{Error: "Укажите machine_id, from_product_id и to_product_id"})
+		return
+	}
+
+	t, err := h.service.ChangeoverTime(machineID, fromID, toID)
+	if err != nil {
+		h.respondError(w, err)
+		return
+	}
+	pkg.RespondJSON(w, http.StatusOK, t)
+}
+
+// GetChangeover godoc
+// @Summary Получить строку матрицы переналадок
+// @Tags schedule
+// @Security BearerAuth
+// @Produce json
+// @Param id path int true "ID строки"
+// @Success 200 {object} Changeover
+// @Failure 404 {object} ErrorResponse
+// @Router /schedule/changeovers/{id} [get]
+func (h *Handler) getChangeover(w http.ResponseWriter, r *http.Request) {
+	c, err := h.service.GetChangeover(pkg.ParamID(r))
+	if err != nil {
+		h.respondError(w, err)
+		return
+	}
+	pkg.RespondJSON(w, http.StatusOK, c)
+}
+
+// CreateChangeover godoc
+// @Summary Добавить строку матрицы переналадок
+// @Description Время переналадки с продукта на продукт для машины, линии или всего завода
+// @Tags schedule
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param request body ChangeoverRequest true "Строка матрицы"
+// @Success 201 {object} Changeover
+// @Failure 400 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse
+// @Router /schedule/changeovers [post]
+func (h *Handler) createChangeover(w http.ResponseWriter, r *http.Request) {
+	var req ChangeoverRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		pkg.RespondJSON(w, http.StatusBadRequest, ErrorResponse{Error: "Некорректный JSON"})
+		return
+	}
+
+	c := req.changeover()
+	if err := h.service.CreateChangeover(c); err != nil {
+		h.respondError(w, err)
+		return
+	}
+	pkg.RespondJSON(w, http.StatusCreated, c)
+}
+
+// UpdateChangeover godoc
+// @Summary Изменить строку матрицы переналадок
+// @Tags schedule
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param id path int true "ID строки"
+// @Param request body ChangeoverRequest true "Строка матрицы"
+// @Success 200 {object} Changeover
+// @Failure 400 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse
+// @Router /schedule/changeovers/{id} [put]
+func (h *Handler) updateChangeover(w http.ResponseWriter, r *http.Request) {
+	var req ChangeoverRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		pkg.RespondJSON(w, http.StatusBadRequest, ErrorResponse{Error: "Некорректный JSON"})
+		return
+	}
+
+	c := req.changeover()
+	c.ID = pkg.ParamID(r)
+	if err := h.service.UpdateChangeover(c); err != nil {
+		h.respondError(w, err)
+		return
+	}
+	pkg.RespondJSON(w, http.StatusOK, c)
+}
+
+// DeleteChangeover godoc
+// @Summary Удалить строку матрицы переналадок
+// @Tags schedule
+// @Security BearerAuth
+// @Param id path int true "ID строки"
+// @Success 204
+// @Failure 404 {object} ErrorResponse
+// @Router /schedule/changeovers/{id} [delete]
+func (h *Handler) deleteChangeover(w http.ResponseWriter, r *http.Request) {
+	if err := h.service.DeleteChangeover(pkg.ParamID(r)); err != nil {
+		h.respondError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// ListProposals godoc
+// @Summary Предложения перепланирования
+// @Description Предложения по остановленным машинам с изменениями по заказам и влиянием на сроки; новые первыми
+// @Tags schedule
+// @Security BearerAuth
+// @Produce json
+// @Param machine_id query int false "ID машины"
+// @Param status query string false "Статус: pending, committed, rejected, superseded"
+// @Success 200 {array} Proposal
+// @Failure 400 {object} ErrorResponse
+// @Router /schedule/proposals [get]
+func (h *Handler) listProposals(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	machineID, _ := strconv.ParseInt(query.Get("machine_id"), 10, 64)
+
+	proposals, err := h.service.ListProposals(ProposalFilter{
+		MachineID: machineID,
+		Status:    query.Get("status"),
+	})
+	if err != nil {
+		h.respondError(w, err)
+		return
+	}
+	pkg.RespondJSON(w, http.StatusOK, proposals)
+}
+
+// ProposeReschedule godoc
+// @Summary Перепланировать слоты машины
+// @Description Переносит предстоящие слоты машины на другие допустимые машины или сдвигает после available_from; расписание меняется только после подтверждения. Создается автоматически при переводе машины в недоступный статус
+// @Tags schedule
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param request body RescheduleRequest true "Машина и прогноз ее возврата"
+// @Success 201 {object} Proposal
+// @Failure 400 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse
+// @Router /schedule/proposals [post]
+func (h *Handler) propose(w http.ResponseWriter, r *http.Request) {
+	var req RescheduleRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		pkg.RespondJSON(w, http.StatusBadRequest, ErrorResponse{Error: "Некорректный JSON"})
+		return
+	}
+
+	p, err := h.service.ProposeReschedule(req, currentUser(r))
+	if err != nil {
+		h.respondError(w, err)
+		return
+	}
+	pkg.RespondJSON(w, http.StatusCreated, p)
+}
+
+// GetProposal godoc
+// @Summary Получить предложение перепланирования
+// @Tags schedule
+// @Security BearerAuth
+// @Produce json
+// @Param id path int true "ID предложения"
+// @Success 200 {object} Proposal
+// @Failure 404 {object} ErrorResponse
+// @Router /schedule/proposals/{id} [get]
+func (h *Handler) getProposal(w http.ResponseWriter, r *http.Request) {
+	p, err := h.service.GetProposal(pkg.ParamID(r))
+	if err != nil {
+		h.respondError(w, err)
+		return
+	}
+	pkg.RespondJSON(w, http.StatusOK, p)
+}
+
+// CommitProposal godoc
+// @Summary Подтвердить перепланирование
+// @Description Снимает слоты размещенных заказов с машины (начатые обрезаются) и создает новые одной транзакцией; если расписание машины изменилось, возвращает 409
+// @Tags schedule
+// @Security BearerAuth
+// @Produce json
+// @Param id path int true "ID предложения"
+// @Success 200 {object} Proposal
+// @Failure 404 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse
+// @Router /schedule/proposals/{id}/commit [post]
+func (h *Handler) commitProposal(w http.ResponseWriter, r *http.Request) {
+	p, err := h.service.CommitProposal(pkg.ParamID(r), currentUser(r))
+	if err != nil {
+		h.respondError(w, err)
+		return
+	}
+	pkg.RespondJSON(w, http.StatusOK, p)
+}
+
+// RejectProposal godoc
+// @Summary Отклонить перепланирование
+// @Tags schedule
+// @Security BearerAuth
+// @Produce json
+// @Param id path int true "ID предложения"
+// @Success 200 {object} Proposal
+// @Failure 404 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse
+// @Router /schedule/proposals/{id}/reject [post]
+func (h *Handler) rejectProposal(w http.ResponseWriter, r *http.Request) {
+	p, err := h.service.RejectProposal(pkg.ParamID(r), currentUser(r))
+	if err != nil {
+		h.respondError(w, err)
+		return
+	}
+	pkg.RespondJSON(w, http.StatusOK, p)
+}
+
+func (req SlotRequest) slot() *Slot {
+	return &Slot{
+		WorkOrderID: req.WorkOrderID,
+		MachineID:   req.MachineID,
+		Kind:        req.Kind,
+		StartTime:   req.StartTime,
+		EndTime:     req.EndTime,
+	}
+}
+
+func (req ChangeoverRequest) changeover() *Changeover {
+	return &Changeover{
+		FromProductID: req.FromProductID,
+		ToProductID:   req.ToProductID,
+		MachineID:     req.MachineID,
+		LineID:        req.LineID,
+		SetupMin:      req.SetupMin,
+	}
+}
+
+func currentUser(r *http.Request) int64 {
+	id, _ := middleware.UserIDFromContext(r.Context())
+	return id
+}
+
+// parseBound разбирает границу периода в RFC3339 или ГГГГ-ММ-ДД; пустая строка — без границы.
+// Дата в конце периода включает весь день.
+func parseBound(w http.ResponseWriter, value string, end bool) (*time.Time, bool) {
+	if value == "" {
+		return nil, true
+	}
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return &t, true
+	}
+
+	t, err := time.ParseInLocation(time.DateOnly, value, time.Local)
+	if err != nil {
+		pkg.RespondJSON(w, http.StatusBadRequest, ErrorResponse{Error: "Время должно быть в формате RFC3339 или ГГГГ-ММ-ДД"})
+		return nil, false
+	}
+	if end {
+		t = t.AddDate(0, 0, 1)
+	}
+	return &t, true
+}
+
+func (h *Handler) respondError(w http.ResponseWriter, err error) {
+	var conflictErr *ConflictError
+
+	switch {
+	case errors.As(err, &conflictErr):
+		pkg.RespondJSON(w, http.StatusConflict, ConflictResponse{
+			Error:     "Машина занята в указанный период",
+			Conflicts: conflictErr.Conflicts,
+		})
+	case errors.Is(err, ErrOverlap):
+		pkg.RespondJSON(w, http.StatusConflict, ErrorResponse{Error: "Машина занята в указанный период, расписание могло измениться"})
+	case errors.Is(err, ErrNotFound):
+		pkg.RespondJSON(w, http.StatusNotFound, ErrorResponse{Error: "Слот расписания не найден"})
+	case errors.Is(err, ErrWorkOrderNotFound):
+		pkg.RespondJSON(w, http.StatusNotFound, ErrorResponse{Error: "Заказ не найден"})
+	case errors.Is(err, ErrMachineNotFound):
+		pkg.RespondJSON(w, http.StatusNotFound, ErrorResponse{Error: "Машина не найдена"})
+	case errors.Is(err, ErrProposalNotFound):
+		pkg.RespondJSON(w, http.StatusNotFound, ErrorResponse{Error: "Предложение перепланирования не найдено"})
+	case errors.Is(err, ErrProposalDecided):
+		pkg.RespondJSON(w, http.StatusConflict, ErrorResponse{Error: "Предложение уже подтверждено или отклонено"})
+	case errors.Is(err, ErrProposalStale):
+		pkg.RespondJSON(w, http.StatusConflict, ErrorResponse{Error: "Расписание машины изменилось, создайте новое предложение"})
+	case errors.Is(err, ErrNothingAffected):
+		pkg.RespondJSON(w, http.StatusConflict, ErrorResponse{Error: "У машины нет предстоящих слотов"})
+	case errors.Is(err, ErrInvalidAvailable):
+		pkg.RespondJSON(w, http.StatusBadRequest, ErrorResponse{Error: "Время возврата машины должно быть в будущем"})
+	case errors.Is(err, ErrInvalidProposalStatus):
+		pkg.RespondJSON(w, http.StatusBadRequest, ErrorResponse{Error: "Неизвестный статус предложения"})
+	case errors.Is(err, ErrChangeoverNotFound):
+		pkg.RespondJSON(w, http.StatusNotFound, ErrorResponse{Error: "Строка матрицы переналадок не найдена"})
+	case errors.Is(err, ErrLineNotFound):
+		pkg.RespondJSON(w, http.StatusNotFound, ErrorResponse{Error: "Линия не найдена"})
+	case errors.Is(err, ErrChangeoverExists):
+		pkg.RespondJSON(w, http.StatusConflict, ErrorResponse{Error: "Переналадка для этого перехода уже задана"})
+	case errors.Is(err, ErrChangeoverScope):
+		pkg.RespondJSON(w, http.StatusBadRequest, ErrorResponse{Error: "Укажите либо машину, либо линию"})
+	case errors.Is(err, ErrSameProduct):
+		pkg.RespondJSON(w, http.StatusBadRequest, ErrorResponse{Error: "Переналадка задается между разными продуктами"})
+	case errors.Is(err, ErrInvalidSetup):
+		pkg.RespondJSON(w, http.StatusBadRequest, ErrorResponse{Error: "Время переналадки не может быть отрицательным"})
+	case errors.Is(err, ErrInvalidKind):
+		pkg.RespondJSON(w, http.StatusBadRequest, ErrorResponse{Error: "Вид слота должен быть run или setup"})
+	case errors.Is(err, ErrProductNotFound):
+		pkg.RespondJSON(w, http.StatusNotFound, ErrorResponse{Error: "Продукт не найден"})
+	case errors.Is(err, ErrEmptyCommit):
+		pkg.RespondJSON(w, http.StatusBadRequest, ErrorResponse{Error: "Предложение не содержит изменений"})
+	case errors.Is(err, ErrCommitFrom):
+		pkg.RespondJSON(w, http.StatusBadRequest, ErrorResponse{Error: "Укажите начало планирования из предпросмотра"})
+	case errors.Is(err, ErrPlanChanged):
+		pkg.RespondJSON(w, http.StatusConflict, ErrorResponse{Error: "Расписание изменилось после предпросмотра, постройте предложение заново"})
+	case errors.Is(err, ErrInvalidPeriod):
+		pkg.RespondJSON(w, http.StatusBadRequest, ErrorResponse{Error: "Окончание слота должно быть позже начала"})
+	case errors.Is(err, ErrInvalidRange):
+		pkg.RespondJSON(w, http.StatusBadRequest, ErrorResponse{Error: "Конец периода должен быть позже начала"})
+	case errors.Is(err, ErrRangeTooLong):
+		pkg.RespondJSON(w, http.StatusBadRequest, ErrorResponse{Error: "Период не должен превышать 93 дней"})
+	case errors.Is(err, ErrOrderClosed):
+		pkg.RespondJSON(w, http.StatusConflict, ErrorResponse{Error: "Завершенный или отмененный заказ нельзя планировать"})
+	default:
+		slog.Error("schedule request failed", slog.Any("err", err))
+		pkg.RespondJSON(w, http.StatusInternalServerError, ErrorResponse{Error: "Внутренняя ошибка сервера"})
+	}
+}
@@ -0,0 +1,326 @@
+package schedule
+
+import "time"
+
+// Виды слотов расписания
+const (
+	KindRun   = "run"   // выпуск заказа
+	KindSetup = "setup" // переналадка машины под заказ
+)
+
+// Slot интервал работы машины над заказом; EndTime не входит в интервал,
+// поэтому слоты встык не считаются пересечением
+type Slot struct {
+	ID          int64     `gorm:"primaryKey;autoIncrement" json:"id"`
+	WorkOrderID int64     `gorm:"not null" json:"work_order_id"`
+	MachineID   int64     `gorm:"not null" json:"machine_id"`
+	Kind        string    `gorm:"not null;default:run" json:"kind" example:"run"`
+	StartTime   time.Time `gorm:"not null" json:"start_time"`
+	EndTime     time.Time `gorm:"not null" json:"end_time"`
+	CreatedBy   *int64    `json:"created_by,omitempty"`
+	CreatedAt   time.Time `gorm:"autoCreateTime" json:"created_at"`
+	UpdatedAt   time.Time `gorm:"autoUpdateTime" json:"updated_at"`
+}
+
+func (Slot) TableName() string {
+	return "schedule"
+}
+
+// Overlaps пересекается ли слот с интервалом [start, end)
+func (s *Slot) Overlaps(start, end time.Time) bool {
+	return s.StartTime.Before(end) && start.Before(s.EndTime)
+}
+
+// OrderRef сведения о заказе, нужные расписанию
+type OrderRef struct {
+	ID       int64
+	WONumber string
+	StatusID int
+}
+
+// ListFilter параметры выборки слотов; нулевые значения не фильтруют.
+// From/To отбирают слоты, пересекающиеся с интервалом.
+type ListFilter struct {
+	From        *time.Time
+	To          *time.Time
+	MachineID   int64
+	LineID      int64
+	WorkOrderID int64
+}
+
+// GanttLine линия с машинами; машины без линии собираются в группу с LineID = nil
+type GanttLine struct {
+	LineID   *int64          `json:"line_id"`
+	LineName string          `json:"line_name"`
+	Machines []*GanttMachine `json:"machines"`
+}
+
+// GanttMachine строка диаграммы Ганта
+type GanttMachine struct {
+	MachineID int64        `json:"machine_id"`
+	Code      string       `json:"code"`
+	Name      string       `json:"name"`
+	LineID    *int64       `json:"-"`
+	LineName  *string      `json:"-"`
+	StatusID  *int         `json:"status_id,omitempty"`
+	Slots     []*GanttSlot `json:"slots"`
+}
+
+// GanttSlot слот с данными заказа для отображения; Late — слот заканчивается после срока заказа
+type GanttSlot struct {
+	ID          int64      `json:"id"`
+	MachineID   int64      `json:"machine_id"`
+	Kind        string     `json:"kind"`
+	WorkOrderID int64      `json:"work_order_id"`
+	WONumber    string     `json:"wo_number"`
+	ProductID   int64      `json:"product_id"`
+	ProductName string     `json:"product_name"`
+	Quantity    int        `json:"quantity"`
+	PriorityID  int        `json:"priority_id"`
+	StatusID    int        `json:"status_id"`
+	Deadline    *time.Time `json:"deadline,omitempty"`
+	StartTime   time.Time  `json:"start_time"`
+	EndTime     time.Time  `json:"end_time"`
+	Late        bool       `json:"late"`
+}
+
+// Gantt расписание за период, сгруппированное по линиям и машинам
+type Gantt struct {
+	From  time.Time    `json:"from"`
+	To    time.Time    `json:"to"`
+	Lines []*GanttLine `json:"lines"`
+}
+
+// Причины, по которым заказ не размещен автопланированием
+const (
+	ReasonNoTechCycle   = "no_tech_cycle"
+	ReasonNoMachine     = "no_eligible_machine"
+	ReasonNoWorkingTime = "no_working_time"
+)
+
+// PlanRequest параметры автопланирования.
+// From — начало планирования (по умолчанию текущее время), WorkOrderIDs ограничивает выбор
+// выпущенных заказов. Без Reschedule планируются только заказы без будущих слотов;
+// с Reschedule будущие слоты выпущенных заказов снимаются и размещаются заново.
+type PlanRequest struct {
+	From         *time.Time `json:"from,omitempty" example:"2026-11-02T08:00:00Z"`
+	WorkOrderIDs []int64    `json:"work_order_ids,omitempty"`
+	Reschedule   bool       `json:"reschedule" example:"false"`
+}
+
+// PlanOrder выпущенный заказ с данными для планирования
+type PlanOrder struct {
+	ID             int64
+	WONumber       string
+	ProductID      int64
+	MachineID      *int64
+	Quantity       int
+	PriorityWeight int
+	Deadline       *time.Time
+	TechCycleMin   *int
+	CreatedAt      time.Time
+}
+
+// PlanMachine машина, доступная для планирования
+type PlanMachine struct {
+	ID       int64
+	Code     string
+	LineID   *int64
+	StatusID *int
+}
+
+// PlannedSlot предлагаемый слот; LatenessMin — опоздание относительно конца дня срока
+type PlannedSlot struct {
+	WorkOrderID int64      `json:"work_order_id"`
+	WONumber    string     `json:"wo_number"`
+	MachineID   int64      `json:"machine_id"`
+	Kind        string     `json:"kind"`
+	StartTime   time.Time  `json:"start_time"`
+	EndTime     time.Time  `json:"end_time"`
+	Deadline    *time.Time `json:"deadline,omitempty"`
+	LatenessMin float64    `json:"lateness_min"`
+}
+
+// Unplaced заказ, который не удалось разместить
+type Unplaced struct {
+	WorkOrderID int64  `json:"work_order_id"`
+	WONumber    string `json:"wo_number"`
+	Reason      string `json:"reason"`
+}
+
+// Plan предложение автопланирования; ReplacedSlotIDs — снимаемые слоты при Reschedule
+type Plan struct {
+	From            time.Time      `json:"from"`
+	Slots           []*PlannedSlot `json:"slots"`
+	ReplacedSlotIDs []int64        `json:"replaced_slot_ids"`
+	Unplaced        []*Unplaced    `json:"unplaced"`
+	Metrics         *Metrics       `json:"metrics"`
+}
+
+// MachineLoad загрузка машины на горизонте планирования; BusyMin включает наладку SetupMin
+type MachineLoad struct {
+	MachineID   int64   `json:"machine_id"`
+	Code        string  `json:"code"`
+	BusyMin     float64 `json:"busy_min"`
+	SetupMin    float64 `json:"setup_min"`
+	Utilization float64 `json:"utilization"`
+}
+
+// Metrics показатели расписания по заказам плана и машинам на горизонте [From, HorizonEnd)
+type Metrics struct {
+	ScheduledOrders   int            `json:"scheduled_orders"`
+	UnscheduledOrders int            `json:"unscheduled_orders"`
+	LateOrders        int            `json:"late_orders"`
+	TotalLatenessMin  float64        `json:"total_lateness_min"`
+	MaxLatenessMin    float64        `json:"max_lateness_min"`
+	HorizonEnd        *time.Time     `json:"horizon_end,omitempty"`
+	Utilization       float64        `json:"utilization"`
+	Machines          []*MachineLoad `json:"machines"`
+}
+
+// Comparison текущее и предлагаемое расписание на общем горизонте
+type Comparison struct {
+	Current  *Metrics `json:"current"`
+	Proposed *Metrics `json:"proposed"`
+	Plan     *Plan    `json:"plan"`
+}
+
+// CommitRequest фиксация предложения: параметры предпросмотра (From — начало из ответа Preview)
+// и полученные в нем слоты. Снимаемые слоты удаляются, новые создаются одной транзакцией.
+type CommitRequest struct {
+	From            time.Time      `json:"from" example:"2026-11-02T08:00:00Z"`
+	WorkOrderIDs    []int64        `json:"work_order_ids,omitempty"`
+	Reschedule      bool           `json:"reschedule" example:"false"`
+	Slots           []*PlannedSlot `json:"slots"`
+	ReplacedSlotIDs []int64        `json:"replaced_slot_ids"`
+}
+
+// Changeover время переналадки машины с продукта на продукт.
+// Строка машины (MachineID) важнее строки линии (LineID), строка линии — общезаводской.
+type Changeover struct {
+	ID            int64     `gorm:"primaryKey;autoIncrement" json:"id"`
+	FromProductID int64     `gorm:"not null" json:"from_product_id"`
+	ToProductID   int64     `gorm:"not null" json:"to_product_id"`
+	MachineID     *int64    `json:"machine_id,omitempty"`
+	LineID        *int64    `json:"line_id,omitempty"`
+	SetupMin      int       `gorm:"not null" json:"setup_min"`
+	CreatedAt     time.Time `gorm:"autoCreateTime" json:"created_at"`
+	UpdatedAt     time.Time `gorm:"autoUpdateTime" json:"updated_at"`
+}
+
+func (Changeover) TableName() string {
+	return "changeovers"
+}
+
+// ChangeoverFilter параметры выборки матрицы; ProductID ищет по исходному и целевому продукту
+type ChangeoverFilter struct {
+	MachineID int64
+	LineID    int64
+	ProductID int64
+}
+
+// Источники времени переналадки
+const (
+	SourceMachine = "machine"
+	SourceLine    = "line"
+	SourcePlant   = "plant"
+	SourceNone    = "none"
+)
+
+// ChangeoverTime действующее время переналадки машины и строка матрицы, из которой оно взято
+type ChangeoverTime struct {
+	MachineID     int64  `json:"machine_id"`
+	FromProductID int64  `json:"from_product_id"`
+	ToProductID   int64  `json:"to_product_id"`
+	SetupMin      int    `json:"setup_min"`
+	Source        string `json:"source"`
+	ChangeoverID  *int64 `json:"changeover_id,omitempty"`
+}
+
+// Статусы предложений перепланирования
+const (
+	ProposalPending    = "pending"
+	ProposalCommitted  = "committed"
+	ProposalRejected   = "rejected"
+	ProposalSuperseded = "superseded" // заменено более новым предложением по той же машине
+)
+
+// Действия над заказом в предложении перепланирования
+const (
+	ActionMoved    = "moved"    // перенос на другую машину
+	ActionDelayed  = "delayed"  // сдвиг на той же машине после простоя
+	ActionUnplaced = "unplaced" // разместить не удалось, слоты остаются на месте
+)
+
+// Proposal предложение перепланирования слотов остановленной машины.
+// Расписание меняется только после подтверждения планировщиком.
+type Proposal struct {
+	ID              int64             `gorm:"primaryKey;autoIncrement" json:"id"`
+	MachineID       int64             `gorm:"not null" json:"machine_id"`
+	StatusHistoryID *int64            `json:"status_history_id,omitempty"`
+	Reason          string            `json:"reason,omitempty"`
+	PlannedFrom     time.Time         `gorm:"not null" json:"planned_from"`
+	AvailableFrom   *time.Time        `json:"available_from,omitempty"`
+	Status          string            `gorm:"not null;default:pending" json:"status" example:"pending"`
+	CreatedBy       *int64            `json:"created_by,omitempty"`
+	CreatedAt       time.Time         `gorm:"autoCreateTime" json:"created_at"`
+	DecidedBy       *int64            `json:"decided_by,omitempty"`
+	DecidedAt       *time.Time        `json:"decided_at,omitempty"`
+	Changes         []*ProposalChange `gorm:"foreignKey:ProposalID" json:"changes"`
+	Impact          *Impact           `gorm:"-" json:"impact"`
+}
+
+func (Proposal) TableName() string {
+	return "reschedule_proposals"
+}
+
+// ProposalChange изменение размещения заказа: From* — занятость остановленной машины,
+// To* — новое размещение (наладка [ToStart, RunStart), выпуск [RunStart, ToEnd))
+type ProposalChange struct {
+	ID                int64      `gorm:"primaryKey;autoIncrement" json:"id"`
+	ProposalID        int64      `gorm:"not null" json:"proposal_id"`
+	WorkOrderID       int64      `gorm:"not null" json:"work_order_id"`
+	WONumber          string     `gorm:"column:wo_number;not null" json:"wo_number"`
+	Action            string     `gorm:"not null" json:"action" example:"moved"`
+	FromStart         time.Time  `json:"from_start"`
+	FromEnd           time.Time  `json:"from_end"`
+	ToMachineID       *int64     `json:"to_machine_id,omitempty"`
+	ToStart           *time.Time `json:"to_start,omitempty"`
+	RunStart          *time.Time `json:"run_start,omitempty"`
+	ToEnd             *time.Time `json:"to_end,omitempty"`
+	Deadline          *time.Time `json:"deadline,omitempty"`
+	LatenessBeforeMin float64    `json:"lateness_before_min"`
+	LatenessAfterMin  float64    `json:"lateness_after_min"`
+	DelayMin          float64    `json:"delay_min"`
+	Reason            string     `json:"reason,omitempty"`
+}
+
+func (ProposalChange) TableName() string {
+	return "reschedule_changes"
+}
+
+// Impact влияние предложения на сроки заказов
+type Impact struct {
+	Orders        int     `json:"orders"`
+	Moved         int     `json:"moved"`
+	Delayed       int     `json:"delayed"`
+	Unplaced      int     `json:"unplaced"`
+	LateBefore    int     `json:"late_before"`
+	LateAfter     int     `json:"late_after"`
+	TotalDelayMin float64 `json:"total_delay_min"`
+}
+
+// ProposalFilter параметры выборки предложений
+type ProposalFilter struct {
+	MachineID int64
+	Status    string
+}
+
+// RescheduleRequest запуск перепланирования слотов машины; AvailableFrom — прогноз возврата машины,
+// без него машина исключается из размещения
+type RescheduleRequest struct {
+	MachineID       int64      `json:"machine_id" example:"1"`
+	AvailableFrom   *time.Time `json:"available_from,omitempty" example:"2026-11-02T14:00:00Z"`
+	Reason          string     `json:"reason,omitempty" example:"Поломка шпинделя"`
+	StatusHistoryID *int64     `json:"-"`
+}
@@ -0,0 +1,58 @@
+package schedule
+
+import "time"
+
+type Repository interface {
+	// Save создает или обновляет слот под блокировкой машины:
+	// check получает пересекающиеся слоты той же машины и может отменить сохранение
+	Save(s *Slot, check func(overlaps []*Slot) error) error
+	Delete(s *Slot) error
+
+	Get(id int64) (*Slot, error)
+	List(filter ListFilter) ([]*Slot, error)
+
+	GanttMachines(lineID int64) ([]*GanttMachine, error)
+	GanttSlots(filter ListFilter) ([]*GanttSlot, error)
+
+	ReleasedOrders(ids []int64) ([]*PlanOrder, error)
+	// OrdersByID заказы для планирования независимо от статуса
+	OrdersByID(ids []int64) ([]*PlanOrder, error)
+	// PlanMachines машины, доступные для планирования
+	PlanMachines() ([]*PlanMachine, error)
+	PlanMachine(id int64) (*PlanMachine, error)
+	EligibleMachines(productIDs []int64) (map[int64][]int64, error)
+	// Commit удаляет replaced и создает slots одной транзакцией
+	Commit(replaced []int64, slots []*Slot) error
+
+	// OrderProducts продукт каждого заказа: work_order_id -> product_id
+	OrderProducts(ids []int64) (map[int64]int64, error)
+	// LastProducts продукт последнего слота каждой машины, закончившегося не позже before
+	LastProducts(before time.Time) (map[int64]int64, error)
+
+	ListChangeovers(filter ChangeoverFilter) ([]*Changeover, error)
+	// MatchChangeovers строки матрицы для перехода fromProductID -> toProductID на всех уровнях
+	MatchChangeovers(fromProductID, toProductID int64) ([]*Changeover, error)
+	// ChangeoversFor строки матрицы с переходом на любой из продуктов
+	ChangeoversFor(productIDs []int64) ([]*Changeover, error)
+	GetChangeover(id int64) (*Changeover, error)
+	SaveChangeover(c *Changeover) error
+	DeleteChangeover(c *Changeover) error
+
+	// SaveProposal создает предложение; прежние ожидающие предложения по машине помечаются замененными
+	SaveProposal(p *Proposal) error
+	GetProposal(id int64) (*Proposal, error)
+	ListProposals(filter ProposalFilter) ([]*Proposal, error)
+	// DecideProposal под блокировкой предложения передает decide текущие слоты машины с planned_from;
+	// возвращенные decide слоты удаляются и сохраняются, статус предложения обновляется в той же транзакции
+	DecideProposal(id int64, decide func(p *Proposal, current []*Slot) ([]int64, []*Slot, error)) (*Proposal, error)
+	// Planners пользователи с правом изменения расписания
+	Planners() ([]int64, error)
+
+	ProductMachines(productID int64) ([]int64, error)
+	SetProductMachines(productID int64, machineIDs []int64) error
+
+	GetOrder(id int64) (*OrderRef, error)
+	ProductExists(id int64) (bool, error)
+	MachineExists(id int64) (bool, error)
+	LineExists(id int64) (bool, error)
+}
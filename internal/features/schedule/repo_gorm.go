@@ -0,0 +1,515 @@
+package schedule
+
+import (
+	"errors"
+	"slices"
+	"time"
+
+	"mes-lite-back/internal/features/machine"
+	"mes-lite-back/internal/features/workorder"
+
+	"github.com/jackc/pgx/v5/pgconn"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Коды ошибок Postgres
+const (
+	pgExclusionViolation = "23P01"
+	pgUniqueViolation    = "23505"
+)
+
+type GormRepository struct {
+	db *gorm.DB
+}
+
+func NewGormRepository(db *gorm.DB) *GormRepository {
+	return &GormRepository{db: db}
+}
+
+func (r *GormRepository) Save(s *Slot, check func(overlaps []*Slot) error) error {
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		// блокировка машины упорядочивает конкурентные изменения ее расписания
+		var machineID int64
+		err := tx.Raw("SELECT id FROM machines WHERE id = ? FOR UPDATE", s.MachineID).Scan(&machineID).Error
+		if err != nil {
+			return err
+		}
+		if machineID == 0 {
+			return gorm.ErrRecordNotFound
+		}
+
+		var overlaps []*Slot
+		err = tx.
+			Where("machine_id = ? AND start_time < ? AND end_time > ? AND id <> ?",
+				s.MachineID, s.EndTime, s.StartTime, s.ID).
+			Order("start_time").
+			Find(&overlaps).
+			Error
+		if err != nil {
+			return err
+		}
+		if err := check(overlaps); err != nil {
+			return err
+		}
+
+		return tx.Save(s).Error
+	})
+
+	// ограничение исключения — последняя линия защиты от двойного бронирования
+	return overlapError(err)
+}
+
+func (r *GormRepository) Delete(s *Slot) error {
+	return r.db.Delete(s).Error
+}
+
+func (r *GormRepository) Get(id int64) (*Slot, error) {
+	var s Slot
+	if err := r.db.First(&s, id).Error; err != nil {
+		return nil, err
+	}
+	return &s, nil
+}
+
+func (r *GormRepository) List(filter ListFilter) ([]*Slot, error) {
+	var slots []*Slot
+	return slots, r.filter(r.db.Model(&Slot{}), filter).
+		Order("schedule.start_time, schedule.machine_id").
+		Find(&slots).
+		Error
+}
+
+func (r *GormRepository) GanttMachines(lineID int64) ([]*GanttMachine, error) {
+	var machines []*GanttMachine
+
+	q := r.db.
+		Table("machines m").
+		Select("m.id AS machine_id, m.code, m.name, m.line_id, l.name AS line_name, m.status_id").
+		Joins("LEFT JOIN lines l ON l.id = m.line_id").
+		Order("l.name NULLS LAST, m.line_id, m.code")
+	if lineID > 0 {
+		q = q.Where("m.line_id = ?", lineID)
+	}
+
+	return machines, q.Scan(&machines).Error
+}
+
+func (r *GormRepository) GanttSlots(filter ListFilter) ([]*GanttSlot, error) {
+	var slots []*GanttSlot
+
+	q := r.db.
+		Table("schedule").
+		Select(`schedule.id, schedule.machine_id, schedule.kind, schedule.work_order_id, wo.wo_number,
+			wo.product_id, p.name AS product_name, wo.quantity, wo.priority_id, wo.status_id,
+			wo.deadline, schedule.start_time, schedule.end_time`).
+		Joins("JOIN work_orders wo ON wo.id = schedule.work_order_id").
+		Joins("JOIN products p ON p.id = wo.product_id").
+		Order("schedule.machine_id, schedule.start_time")
+
+	return slots, r.filter(q, filter).Scan(&slots).Error
+}
+
+func (r *GormRepository) ReleasedOrders(ids []int64) ([]*PlanOrder, error) {
+	var orders []*PlanOrder
+
+	q := r.planOrders().Where("wo.status_id = ?", workorder.StatusReleased)
+	if len(ids) > 0 {
+		q = q.Where("wo.id IN ?", ids)
+	}
+
+	return orders, q.Scan(&orders).Error
+}
+
+func (r *GormRepository) OrdersByID(ids []int64) ([]*PlanOrder, error) {
+	var orders []*PlanOrder
+	return orders, r.planOrders().Where("wo.id IN ?", ids).Scan(&orders).Error
+}
+
+func (r *GormRepository) planOrders() *gorm.DB {
+	return r.db.
+		Table("work_orders wo").
+		Select(`wo.id, wo.wo_number, wo.product_id, wo.machine_id, wo.quantity,
+			COALESCE(op.weight, 0) AS priority_weight, wo.deadline, p.tech_cycle_min, wo.created_at`).
+		Joins("JOIN products p ON p.id = wo.product_id").
+		Joins("LEFT JOIN order_priorities op ON op.id = wo.priority_id").
+		Order("wo.id")
+}
+
+func (r *GormRepository) PlanMachines() ([]*PlanMachine, error) {
+	var machines []*PlanMachine
+	err := r.db.
+		Table("machines").
+		Select("id, code, line_id, status_id").
+		Where("status_id IS NULL OR status_id NOT IN ?", machine.Unavailable).
+		Order("code").
+		Scan(&machines).
+		Error
+	return machines, err
+}
+
+func (r *GormRepository) PlanMachine(id int64) (*PlanMachine, error) {
+	var m PlanMachine
+	err := r.db.
+		Table("machines").
+		Select("id, code, line_id, status_id").
+		Where("id = ?", id).
+		Take(&m).
+		Error
+	if err != nil {
+		return nil, err
+	}
+	return &m, nil
+}
+
+func (r *GormRepository) EligibleMachines(productIDs []int64) (map[int64][]int64, error) {
+	var rows []struct {
+		ProductID int64
+		MachineID int64
+	}
+	err := r.db.
+		Table("product_machines").
+		Where("product_id IN ?", productIDs).
+		Order("product_id, machine_id").
+		Scan(&rows).
+		Error
+	if err != nil {
+		return nil, err
+	}
+
+	eligible := make(map[int64][]int64)
+	for _, row := range rows {
+		eligible[row.ProductID] = append(eligible[row.ProductID], row.MachineID)
+	}
+	return eligible, nil
+}
+
+func (r *GormRepository) Commit(replaced []int64, slots []*Slot) error {
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		return commitSlots(tx, replaced, slots)
+	})
+	return overlapError(err)
+}
+
+func (r *GormRepository) SaveProposal(p *Proposal) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		err := tx.Model(&Proposal{}).
+			Where("machine_id = ? AND status = ?", p.MachineID, ProposalPending).
+			Update("status", ProposalSuperseded).
+			Error
+		if err != nil {
+			return err
+		}
+		return tx.Create(p).Error
+	})
+}
+
+func (r *GormRepository) GetProposal(id int64) (*Proposal, error) {
+	var p Proposal
+	err := r.db.
+		Preload("Changes", func(db *gorm.DB) *gorm.DB { return db.Order("id") }).
+		First(&p, id).
+		Error
+	if err != nil {
+		return nil, err
+	}
+	return &p, nil
+}
+
+func (r *GormRepository) ListProposals(filter ProposalFilter) ([]*Proposal, error) {
+	var proposals []*Proposal
+
+	q := r.db.
+		Preload("Changes", func(db *gorm.DB) *gorm.DB { return db.Order("id") }).
+		Order("created_at DESC, id DESC")
+	if filter.MachineID > 0 {
+		q = q.Where("machine_id = ?", filter.MachineID)
+	}
+	if filter.Status != "" {
+		q = q.Where("status = ?", filter.Status)
+	}
+
+	return proposals, q.Find(&proposals).Error
+}
+
+func (r *GormRepository) DecideProposal(id int64, decide func(p *Proposal, current []*Slot) ([]int64, []*Slot, error)) (*Proposal, error) {
+	var p Proposal
+
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		err := tx.
+			Clauses(clause.Locking{Strength: "UPDATE"}).
+			Preload("Changes", func(db *gorm.DB) *gorm.DB { return db.Order("id") }).
+			First(&p, id).
+			Error
+		if err != nil {
+			return err
+		}
+
+		var current []*Slot
+		err = tx.
+			Where("machine_id = ? AND end_time > ?", p.MachineID, p.PlannedFrom).
+			Order("start_time").
+			Find(&current).
+			Error
+		if err != nil {
+			return err
+		}
+
+		replaced, slots, err := decide(&p, current)
+		if err != nil {
+			return err
+		}
+		if err := commitSlots(tx, replaced, slots); err != nil {
+			return err
+		}
+
+		return tx.Model(&p).Select("status", "decided_by", "decided_at").Updates(&p).Error
+	})
+	if err != nil {
+		return nil, overlapError(err)
+	}
+	return &p, nil
+}
+
+// commitSlots удаляет replaced и сохраняет slots: новые создаются, существующие (с ID) обновляются
+func commitSlots(tx *gorm.DB, replaced []int64, slots []*Slot) error {
+	if len(replaced) > 0 {
+		if err := tx.Where("id IN ?", replaced).Delete(&Slot{}).Error; err != nil {
+			return err
+		}
+	}
+	if len(slots) == 0 {
+		return nil
+	}
+
+	// блокировки машин в порядке id, как в Save
+	machineIDs := make([]int64, 0, len(slots))
+	for _, s := range slots {
+		machineIDs = append(machineIDs, s.MachineID)
+	}
+	slices.Sort(machineIDs)
+	machineIDs = slices.Compact(machineIDs)
+
+	var locked []int64
+	err := tx.Raw("SELECT id FROM machines WHERE id IN ? ORDER BY id FOR UPDATE", machineIDs).Scan(&locked).Error
+	if err != nil {
+		return err
+	}
+	if len(locked) != len(machineIDs) {
+		return gorm.ErrRecordNotFound
+	}
+
+	var created []*Slot
+	for _, s := range slots {
+		if s.ID == 0 {
+			created = append(created, s)
+			continue
+		}
+		if err := tx.Save(s).Error; err != nil {
+			return err
+		}
+	}
+	if len(created) == 0 {
+		return nil
+	}
+	return tx.Create(created).Error
+}
+
+// overlapError переводит нарушение ограничения исключения в ErrOverlap
+func overlapError(err error) error {
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && pgErr.Code == pgExclusionViolation {
+		return ErrOverlap
+	}
+	return err
+}
+
+func (r *GormRepository) OrderProducts(ids []int64) (map[int64]int64, error) {
+	var rows []struct {
+		ID        int64
+		ProductID int64
+	}
+	err := r.db.Table("work_orders").Select("id, product_id").Where("id IN ?", ids).Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+
+	products := make(map[int64]int64, len(rows))
+	for _, row := range rows {
+		products[row.ID] = row.ProductID
+	}
+	return products, nil
+}
+
+func (r *GormRepository) LastProducts(before time.Time) (map[int64]int64, error) {
+	var rows []struct {
+		MachineID int64
+		ProductID int64
+	}
+
+	err := r.db.
+		Table("schedule s").
+		Select("DISTINCT ON (s.machine_id) s.machine_id, wo.product_id").
+		Joins("JOIN work_orders wo ON wo.id = s.work_order_id").
+		Where("s.end_time <= ?", before).
+		Order("s.machine_id, s.end_time DESC").
+		Scan(&rows).
+		Error
+	if err != nil {
+		return nil, err
+	}
+
+	products := make(map[int64]int64, len(rows))
+	for _, row := range rows {
+		products[row.MachineID] = row.ProductID
+	}
+	return products, nil
+}
+
+func (r *GormRepository) ListChangeovers(filter ChangeoverFilter) ([]*Changeover, error) {
+	var changeovers []*Changeover
+
+	q := r.db.Order("from_product_id, to_product_id, machine_id NULLS LAST, line_id NULLS LAST")
+	if filter.MachineID > 0 {
+		q = q.Where("machine_id = ?", filter.MachineID)
+	}
+	if filter.LineID > 0 {
+		q = q.Where("line_id = ?", filter.LineID)
+	}
+	if filter.ProductID > 0 {
+		q = q.Where("from_product_id = ? OR to_product_id = ?", filter.ProductID, filter.ProductID)
+	}
+
+	return changeovers, q.Find(&changeovers).Error
+}
+
+func (r *GormRepository) MatchChangeovers(fromProductID, toProductID int64) ([]*Changeover, error) {
+	var changeovers []*Changeover
+	err := r.db.
+		Where("from_product_id = ? AND to_product_id = ?", fromProductID, toProductID).
+		Find(&changeovers).
+		Error
+	return changeovers, err
+}
+
+func (r *GormRepository) ChangeoversFor(productIDs []int64) ([]*Changeover, error) {
+	var changeovers []*Changeover
+	err := r.db.
+		Where("to_product_id IN ?", productIDs).
+		Find(&changeovers).
+		Error
+	return changeovers, err
+}
+
+func (r *GormRepository) GetChangeover(id int64) (*Changeover, error) {
+	var c Changeover
+	if err := r.db.First(&c, id).Error; err != nil {
+		return nil, err
+	}
+	return &c, nil
+}
+
+func (r *GormRepository) SaveChangeover(c *Changeover) error {
+	err := r.db.Save(c).Error
+
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && pgErr.Code == pgUniqueViolation {
+		return ErrChangeoverExists
+	}
+	return err
+}
+
+func (r *GormRepository) DeleteChangeover(c *Changeover) error {
+	return r.db.Delete(c).Error
+}
+
+func (r *GormRepository) LineExists(id int64) (bool, error) {
+	var n int64
+	err := r.db.Table("lines").Where("id = ?", id).Count(&n).Error
+	return n > 0, err
+}
+
+func (r *GormRepository) ProductMachines(productID int64) ([]int64, error) {
+	var ids []int64
+	err := r.db.
+		Table("product_machines").
+		Where("product_id = ?", productID).
+		Order("machine_id").
+		Pluck("machine_id", &ids).
+		Error
+	return ids, err
+}
+
+func (r *GormRepository) SetProductMachines(productID int64, machineIDs []int64) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Exec("DELETE FROM product_machines WHERE product_id = ?", productID).Error; err != nil {
+			return err
+		}
+		for _, machineID := range machineIDs {
+			err := tx.Exec("INSERT INTO product_machines (product_id, machine_id) VALUES (?, ?)", productID, machineID).Error
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func (r *GormRepository) ProductExists(id int64) (bool, error) {
+	var n int64
+	err := r.db.Table("products").Where("id = ?", id).Count(&n).Error
+	return n > 0, err
+}
+
+func (r *GormRepository) GetOrder(id int64) (*OrderRef, error) {
+	var o OrderRef
+	err := r.db.
+		Table("work_orders").
+		Select("id, wo_number, status_id").
+		Where("id = ?", id).
+		Take(&o).
+		Error
+	if err != nil {
+		return nil, err
+	}
+	return &o, nil
+}
+
+func (r *GormRepository) Planners() ([]int64, error) {
+	var ids []int64
+	err := r.db.
+		Table("users u").
+		Joins("JOIN role_permissions rp ON rp.role_id = u.role_id").
+		Joins("JOIN permissions p ON p.id = rp.permission_id").
+		Where("p.code = ?", "schedule.edit").
+		Order("u.id").
+		Distinct().
+		Pluck("u.id", &ids).
+		Error
+	return ids, err
+}
+
+func (r *GormRepository) MachineExists(id int64) (bool, error) {
+	var n int64
+	err := r.db.Table("machines").Where("id = ?", id).Count(&n).Error
+	return n > 0, err
+}
+
+func (r *GormRepository) filter(q *gorm.DB, filter ListFilter) *gorm.DB {
+	if filter.From != nil {
+		q = q.Where("schedule.end_time > ?", *filter.From)
+	}
+	if filter.To != nil {
+		q = q.Where("schedule.start_time < ?", *filter.To)
+	}
+	if filter.MachineID > 0 {
+		q = q.Where("schedule.machine_id = ?", filter.MachineID)
+	}
+	if filter.LineID > 0 {
+		q = q.Where("schedule.machine_id IN (SELECT id FROM machines WHERE line_id = ?)", filter.LineID)
+	}
+	if filter.WorkOrderID > 0 {
+		q = q.Where("schedule.work_order_id = ?", filter.WorkOrderID)
+	}
+	return q
+}
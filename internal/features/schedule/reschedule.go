@@ -0,0 +1,516 @@
+package schedule
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"slices"
+	"time"
+
+	"mes-lite-back/internal/features/machine"
+	"mes-lite-back/internal/features/notification"
+
+	"gorm.io/gorm"
+)
+
+var (
+	ErrNothingAffected       = errors.New("machine has no upcoming slots")
+	ErrInvalidAvailable      = errors.New("machine return must be in the future")
+	ErrProposalNotFound      = errors.New("reschedule proposal not found")
+	ErrProposalDecided       = errors.New("reschedule proposal is already decided")
+	ErrProposalStale         = errors.New("machine schedule changed after the proposal was made")
+	ErrInvalidProposalStatus = errors.New("unknown proposal status")
+)
+
+// Notifier рассылка уведомлений планировщикам
+type Notifier interface {
+	Notify(userIDs []int64, msg notification.Message) error
+}
+
+// affectedOrder занятость остановленной машины заказом
+type affectedOrder struct {
+	id         int64
+	slots      []*Slot
+	start, end time.Time
+	remaining  time.Duration
+}
+
+// MachineStatusChanged предлагает перепланирование, когда машина становится недоступной.
+// Прогноз окончания простоя используется как время возврата машины.
+func (s *Service) MachineStatusChanged(m *machine.Machine, prev *int, h *machine.StatusHistory) {
+	if !machine.IsUnavailable(h.StatusID) || (prev != nil && machine.IsUnavailable(*prev)) {
+		return
+	}
+
+	var userID int64
+	if h.ChangedBy != nil {
+		userID = *h.ChangedBy
+	}
+	_, err := s.ProposeReschedule(RescheduleRequest{
+		MachineID:       m.ID,
+		AvailableFrom:   h.ExpectedUntil,
+		Reason:          h.Comment,
+		StatusHistoryID: &h.ID,
+	}, userID)
+	if err != nil && !errors.Is(err, ErrNothingAffected) {
+		slog.Error("machine down reschedule failed", slog.Int64("machine_id", m.ID), slog.Any("err", err))
+	}
+}
+
+// ProposeReschedule перепланирует слоты машины, заканчивающиеся после текущего момента.
+//
+// Оставшаяся работа каждого заказа (начатый слот — с текущего момента) переносится на самую
+// раннюю по окончанию допустимую машину с наладкой по матрице переналадок; остановленная
+// машина участвует только с AvailableFrom. Заказы ставятся в прежнем порядке. Предложение
+// сохраняется и рассылается планировщикам; расписание меняется после CommitProposal.
+func (s *Service) ProposeReschedule(req RescheduleRequest, userID int64) (*Proposal, error) {
+	from := s.now().Truncate(time.Minute)
+	if req.AvailableFrom != nil && !req.AvailableFrom.After(from) {
+		return nil, ErrInvalidAvailable
+	}
+
+	down, err := s.repo.PlanMachine(req.MachineID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrMachineNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	slots, err := s.repo.List(ListFilter{From: &from, MachineID: down.ID})
+	if err != nil {
+		return nil, err
+	}
+	if len(slots) == 0 {
+		return nil, ErrNothingAffected
+	}
+
+	var lineID int64
+	if down.LineID != nil {
+		lineID = *down.LineID
+	}
+	downHours, err := s.workingHours(lineID, from)
+	if err != nil {
+		return nil, err
+	}
+
+	affected := groupAffected(slots, downHours, from)
+	ids := make([]int64, 0, len(affected))
+	for _, a := range affected {
+		ids = append(ids, a.id)
+	}
+	list, err := s.repo.OrdersByID(ids)
+	if err != nil {
+		return nil, err
+	}
+	orders := make(map[int64]*PlanOrder, len(list))
+	productIDs := make([]int64, 0, len(list))
+	for _, o := range list {
+		orders[o.ID] = o
+		productIDs = append(productIDs, o.ProductID)
+	}
+
+	// остановленная машина доступна только после прогноза возврата
+	machines, err := s.repo.PlanMachines()
+	if err != nil {
+		return nil, err
+	}
+	machines = slices.DeleteFunc(machines, func(m *PlanMachine) bool { return m.ID == down.ID })
+	if req.AvailableFrom != nil {
+		machines = append(machines, down)
+	}
+	working, err := s.machineHours(machines, from)
+	if err != nil {
+		return nil, err
+	}
+
+	existing, err := s.repo.List(ListFilter{From: &from})
+	if err != nil {
+		return nil, err
+	}
+	existing = slices.DeleteFunc(existing, func(sl *Slot) bool { return sl.MachineID == down.ID })
+	keptOrders := make([]int64, 0, len(existing))
+	for _, sl := range existing {
+		keptOrders = append(keptOrders, sl.WorkOrderID)
+	}
+	products := map[int64]int64{}
+	if len(keptOrders) > 0 {
+		if products, err = s.repo.OrderProducts(keptOrders); err != nil {
+			return nil, err
+		}
+	}
+	last, err := s.repo.LastProducts(from)
+	if err != nil {
+		return nil, err
+	}
+	eligible, err := s.repo.EligibleMachines(productIDs)
+	if err != nil {
+		return nil, err
+	}
+	rows, err := s.repo.ChangeoversFor(productIDs)
+	if err != nil {
+		return nil, err
+	}
+	matrix := newChangeoverMatrix(rows)
+
+	busy := make(map[int64][]interval, len(machines))
+	for _, sl := range existing {
+		busy[sl.MachineID] = append(busy[sl.MachineID], interval{sl.StartTime, sl.EndTime, products[sl.WorkOrderID]})
+	}
+	if req.AvailableFrom != nil {
+		// до возврата машина занята простоем; после него наладка считается от последнего продукта
+		product := last[down.ID]
+		if o, ok := orders[affected[0].id]; ok && affected[0].start.Before(from) {
+			product = o.ProductID
+		}
+		busy[down.ID] = append(busy[down.ID], interval{from, *req.AvailableFrom, product})
+	}
+	for id := range busy {
+		sortIntervals(busy[id])
+	}
+
+	p := &Proposal{
+		MachineID:       down.ID,
+		StatusHistoryID: req.StatusHistoryID,
+		Reason:          req.Reason,
+		PlannedFrom:     from,
+		AvailableFrom:   req.AvailableFrom,
+		Status:          ProposalPending,
+		Changes:         make([]*ProposalChange, 0, len(affected)),
+	}
+	if userID > 0 {
+		p.CreatedBy = &userID
+	}
+
+	for _, a := range affected {
+		o, ok := orders[a.id]
+		if !ok {
+			continue
+		}
+		if a.remaining <= 0 {
+			// слоты вне рабочего времени календаря переносятся с их календарной длительностью
+			start := a.start
+			if start.Before(from) {
+				start = from
+			}
+			a.remaining = a.end.Sub(start)
+		}
+
+		c := &ProposalChange{
+			WorkOrderID:       o.ID,
+			WONumber:          o.WONumber,
+			FromStart:         a.start,
+			FromEnd:           a.end,
+			Deadline:          o.Deadline,
+			LatenessBeforeMin: lateness(a.end, o.Deadline),
+		}
+		p.Changes = append(p.Changes, c)
+
+		candidates := eligible[o.ProductID]
+		if o.MachineID != nil {
+			candidates = []int64{*o.MachineID}
+		} else if req.AvailableFrom != nil && !slices.Contains(candidates, down.ID) {
+			candidates = append(candidates, down.ID)
+		}
+
+		var (
+			best   int64
+			chosen placement
+			reason = ReasonNoMachine
+		)
+		for _, machineID := range candidates {
+			hours, ok := working[machineID]
+			if !ok {
+				continue
+			}
+			setup := matrix.setupFunc(o.ProductID, machineID, lineOf(machines, machineID))
+			pl, ok := place(busy[machineID], hours, from, a.remaining, last[machineID], setup)
+			if !ok {
+				reason = ReasonNoWorkingTime
+				continue
+			}
+			if best == 0 || pl.end.Before(chosen.end) {
+				best, chosen = machineID, pl
+			}
+		}
+		if best == 0 {
+			// слоты неразмещенного заказа остаются на машине
+			c.Action = ActionUnplaced
+			c.Reason = reason
+			c.LatenessAfterMin = c.LatenessBeforeMin
+			for _, sl := range a.slots {
+				busy[down.ID] = append(busy[down.ID], interval{sl.StartTime, sl.EndTime, o.ProductID})
+			}
+			sortIntervals(busy[down.ID])
+			continue
+		}
+
+		busy[best] = append(busy[best], interval{chosen.start, chosen.end, o.ProductID})
+		sortIntervals(busy[best])
+
+		c.Action = ActionMoved
+		if best == down.ID {
+			c.Action = ActionDelayed
+		}
+		c.ToMachineID = &best
+		c.ToStart = &chosen.start
+		c.RunStart = &chosen.run
+		c.ToEnd = &chosen.end
+		c.LatenessAfterMin = lateness(chosen.end, o.Deadline)
+		c.DelayMin = round1(chosen.end.Sub(a.end).Minutes())
+	}
+
+	if err := s.repo.SaveProposal(p); err != nil {
+		return nil, err
+	}
+	p.Impact = impact(p)
+
+	s.notifyPlanners(p, down.Code)
+	return p, nil
+}
+
+func (s *Service) ListProposals(filter ProposalFilter) ([]*Proposal, error) {
+	switch filter.Status {
+	case "", ProposalPending, ProposalCommitted, ProposalRejected, ProposalSuperseded:
+	default:
+		return nil, ErrInvalidProposalStatus
+	}
+
+	proposals, err := s.repo.ListProposals(filter)
+	if err != nil {
+		return nil, err
+	}
+	for _, p := range proposals {
+		p.Impact = impact(p)
+	}
+	return proposals, nil
+}
+
+func (s *Service) GetProposal(id int64) (*Proposal, error) {
+	p, err := s.repo.GetProposal(id)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrProposalNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	p.Impact = impact(p)
+	return p, nil
+}
+
+// CommitProposal применяет ожидающее предложение: слоты размещенных заказов снимаются с машины
+// (начатые обрезаются по planned_from), новые слоты создаются. Если расписание машины изменилось
+// после создания предложения, возвращается ErrProposalStale.
+func (s *Service) CommitProposal(id, userID int64) (*Proposal, error) {
+	return s.decide(id, func(p *Proposal, current []*Slot) ([]int64, []*Slot, error) {
+		if err := checkCurrent(p, current); err != nil {
+			return nil, nil, err
+		}
+
+		var (
+			replaced []int64
+			slots    []*Slot
+		)
+		for _, c := range p.Changes {
+			if c.Action == ActionUnplaced {
+				continue
+			}
+
+			for _, sl := range current {
+				if sl.WorkOrderID != c.WorkOrderID {
+					continue
+				}
+				if sl.StartTime.Before(p.PlannedFrom) {
+					sl.EndTime = p.PlannedFrom
+					slots = append(slots, sl)
+				} else {
+					replaced = append(replaced, sl.ID)
+				}
+			}
+
+			if c.RunStart.After(*c.ToStart) {
+				slots = append(slots, &Slot{
+					WorkOrderID: c.WorkOrderID,
+					MachineID:   *c.ToMachineID,
+					Kind:        KindSetup,
+					StartTime:   *c.ToStart,
+					EndTime:     *c.RunStart,
+					CreatedBy:   &userID,
+				})
+			}
+			slots = append(slots, &Slot{
+				WorkOrderID: c.WorkOrderID,
+				MachineID:   *c.ToMachineID,
+				Kind:        KindRun,
+				StartTime:   *c.RunStart,
+				EndTime:     *c.ToEnd,
+				CreatedBy:   &userID,
+			})
+		}
+
+		for _, sl := range slots {
+			if err := s.validate(sl); err != nil {
+				return nil, nil, err
+			}
+		}
+
+		p.Status = ProposalCommitted
+		return replaced, slots, nil
+	}, userID)
+}
+
+// RejectProposal отклоняет ожидающее предложение; расписание не меняется
+func (s *Service) RejectProposal(id, userID int64) (*Proposal, error) {
+	return s.decide(id, func(p *Proposal, _ []*Slot) ([]int64, []*Slot, error) {
+		p.Status = ProposalRejected
+		return nil, nil, nil
+	}, userID)
+}
+
+func (s *Service) decide(id int64, apply func(p *Proposal, current []*Slot) ([]int64, []*Slot, error), userID int64) (*Proposal, error) {
+	p, err := s.repo.DecideProposal(id, func(p *Proposal, current []*Slot) ([]int64, []*Slot, error) {
+		if p.Status != ProposalPending {
+			return nil, nil, ErrProposalDecided
+		}
+
+		replaced, slots, err := apply(p, current)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		now := s.now()
+		p.DecidedBy = &userID
+		p.DecidedAt = &now
+		return replaced, slots, nil
+	})
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrProposalNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	p.Impact = impact(p)
+	return p, nil
+}
+
+func (s *Service) notifyPlanners(p *Proposal, machineCode string) {
+	if s.notifier == nil || len(p.Changes) == 0 {
+		return
+	}
+
+	ids, err := s.repo.Planners()
+	if err != nil {
+		slog.Error("resolve planners failed", slog.Int64("proposal_id", p.ID), slog.Any("err", err))
+		return
+	}
+
+	err = s.notifier.Notify(ids, notification.Message{
+		Kind:       notification.KindReschedule,
+		Title:      fmt.Sprintf("Машина %s недоступна: требуется подтвердить перепланирование", machineCode),
+		Body:       fmt.Sprintf("Заказов: %d, перенесено: %d, сдвинуто: %d, не размещено: %d, опаздывают: %d", p.Impact.Orders, p.Impact.Moved, p.Impact.Delayed, p.Impact.Unplaced, p.Impact.LateAfter),
+		EntityType: "reschedule_proposal",
+		EntityID:   p.ID,
+		CreatedBy:  p.CreatedBy,
+	})
+	if err != nil {
+		slog.Error("reschedule notification failed", slog.Int64("proposal_id", p.ID), slog.Any("err", err))
+	}
+}
+
+// groupAffected группирует слоты машины по заказам в порядке начала; оставшаяся работа —
+// рабочее время выпуска после from (наладка на новой машине считается заново)
+func groupAffected(slots []*Slot, hours []interval, from time.Time) []*affectedOrder {
+	var out []*affectedOrder
+	byOrder := make(map[int64]*affectedOrder)
+
+	for _, sl := range slots {
+		a, ok := byOrder[sl.WorkOrderID]
+		if !ok {
+			a = &affectedOrder{id: sl.WorkOrderID, start: sl.StartTime, end: sl.EndTime}
+			byOrder[sl.WorkOrderID] = a
+			out = append(out, a)
+		}
+		a.slots = append(a.slots, sl)
+		if sl.StartTime.Before(a.start) {
+			a.start = sl.StartTime
+		}
+		if sl.EndTime.After(a.end) {
+			a.end = sl.EndTime
+		}
+		if sl.Kind != KindSetup {
+			start := sl.StartTime
+			if start.Before(from) {
+				start = from
+			}
+			a.remaining += worked(hours, start, sl.EndTime)
+		}
+	}
+
+	slices.SortStableFunc(out, func(a, b *affectedOrder) int { return a.start.Compare(b.start) })
+	return out
+}
+
+// checkCurrent совпадает ли текущая занятость машины с той, по которой строилось предложение
+func checkCurrent(p *Proposal, current []*Slot) error {
+	spans := make(map[int64]*affectedOrder)
+	for _, a := range groupAffected(current, nil, p.PlannedFrom) {
+		spans[a.id] = a
+	}
+
+	seen := make(map[int64]bool, len(p.Changes))
+	for _, c := range p.Changes {
+		seen[c.WorkOrderID] = true
+		a, ok := spans[c.WorkOrderID]
+		if !ok || !a.start.Equal(c.FromStart) || !a.end.Equal(c.FromEnd) {
+			return ErrProposalStale
+		}
+	}
+	for id := range spans {
+		if !seen[id] {
+			return ErrProposalStale
+		}
+	}
+	return nil
+}
+
+// worked рабочее время hours внутри [start, end)
+func worked(hours []interval, start, end time.Time) time.Duration {
+	var d time.Duration
+	for _, iv := range hours {
+		s, e := iv.start, iv.end
+		if s.Before(start) {
+			s = start
+		}
+		if e.After(end) {
+			e = end
+		}
+		if e.After(s) {
+			d += e.Sub(s)
+		}
+	}
+	return d
+}
+
+func impact(p *Proposal) *Impact {
+	im := &Impact{Orders: len(p.Changes)}
+	for _, c := range p.Changes {
+		switch c.Action {
+		case ActionMoved:
+			im.Moved++
+		case ActionDelayed:
+			im.Delayed++
+		case ActionUnplaced:
+			im.Unplaced++
+		}
+		if c.LatenessBeforeMin > 0 {
+			im.LateBefore++
+		}
+		if c.LatenessAfterMin > 0 {
+			im.LateAfter++
+		}
+		im.TotalDelayMin += max(0, c.DelayMin)
+	}
+	im.TotalDelayMin = round1(im.TotalDelayMin)
+	return im
+}
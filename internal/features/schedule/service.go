@@ -0,0 +1,278 @@
+package schedule
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"mes-lite-back/internal/features/calendar"
+	"mes-lite-back/internal/features/workorder"
+	"mes-lite-back/pkg"
+
+	"gorm.io/gorm"
+)
+
+// MaxGanttRange наибольший период диаграммы Ганта
+const MaxGanttRange = 93 * 24 * time.Hour
+
+var (
+	ErrNotFound          = errors.New("schedule slot not found")
+	ErrWorkOrderNotFound = errors.New("work order not found")
+	ErrMachineNotFound   = errors.New("machine not found")
+	ErrInvalidPeriod     = errors.New("slot end must be after start")
+	ErrInvalidRange      = errors.New("range end must be after start")
+	ErrRangeTooLong      = errors.New("range is too long")
+	ErrOrderClosed       = errors.New("completed or cancelled work order cannot be scheduled")
+	ErrOverlap           = errors.New("slot overlaps another slot on the same machine")
+	ErrInvalidKind       = errors.New("slot kind must be run or setup")
+)
+
+// ConflictError слот пересекается с уже запланированными на той же машине
+type ConflictError struct {
+	Conflicts []*Slot
+}
+
+func (e *ConflictError) Error() string {
+	return fmt.Sprintf("%s: %d conflicting slot(s)", ErrOverlap, len(e.Conflicts))
+}
+
+func (e *ConflictError) Unwrap() error {
+	return ErrOverlap
+}
+
+// ServiceInterface определяет методы, используемые handler’ом
+type ServiceInterface interface {
+	ListSlots(filter ListFilter) ([]*Slot, error)
+	GetSlot(id int64) (*Slot, error)
+	CreateSlot(s *Slot) error
+	UpdateSlot(s *Slot) error
+	DeleteSlot(id int64) error
+	CheckSlot(s *Slot) ([]*Slot, error)
+	Gantt(from, to time.Time, lineID int64) (*Gantt, error)
+
+	Preview(req PlanRequest) (*Plan, error)
+	Compare(req PlanRequest) (*Comparison, error)
+	Commit(req CommitRequest, userID int64) ([]*Slot, error)
+
+	ProductMachines(productID int64) ([]int64, error)
+	SetProductMachines(productID int64, machineIDs []int64) ([]int64, error)
+
+	ListChangeovers(filter ChangeoverFilter) ([]*Changeover, error)
+	GetChangeover(id int64) (*Changeover, error)
+	CreateChangeover(c *Changeover) error
+	UpdateChangeover(c *Changeover) error
+	DeleteChangeover(id int64) error
+	ChangeoverTime(machineID, fromProductID, toProductID int64) (*ChangeoverTime, error)
+
+	ProposeReschedule(req RescheduleRequest, userID int64) (*Proposal, error)
+	ListProposals(filter ProposalFilter) ([]*Proposal, error)
+	GetProposal(id int64) (*Proposal, error)
+	CommitProposal(id, userID int64) (*Proposal, error)
+	RejectProposal(id, userID int64) (*Proposal, error)
+}
+
+// WorkingCalendar рабочее время линии (lineID = 0 — завода)
+type WorkingCalendar interface {
+	WorkingIntervals(lineID int64, from, to time.Time) ([]calendar.Interval, error)
+}
+
+type Service struct {
+	repo     Repository
+	calendar WorkingCalendar
+	notifier Notifier
+	now      func() time.Time
+}
+
+func NewService(repo Repository, calendar WorkingCalendar, notifier Notifier) *Service {
+	return &Service{
+		repo:     repo,
+		calendar: calendar,
+		notifier: notifier,
+		now:      time.Now,
+	}
+}
+
+func (s *Service) ListSlots(filter ListFilter) ([]*Slot, error) {
+	if filter.From != nil && filter.To != nil && !filter.To.After(*filter.From) {
+		return nil, ErrInvalidRange
+	}
+	return s.repo.List(filter)
+}
+
+func (s *Service) GetSlot(id int64) (*Slot, error) {
+	slot, err := s.repo.Get(id)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrNotFound
+	}
+	return slot, err
+}
+
+func (s *Service) CreateSlot(slot *Slot) error {
+	if err := s.validate(slot); err != nil {
+		return err
+	}
+
+	slot.ID = 0
+	return s.save(slot)
+}
+
+// UpdateSlot переносит слот по времени или на другую машину; автор и дата создания сохраняются
+func (s *Service) UpdateSlot(slot *Slot) error {
+	existing, err := s.GetSlot(slot.ID)
+	if err != nil {
+		return err
+	}
+	if err := s.validate(slot); err != nil {
+		return err
+	}
+
+	existing.WorkOrderID = slot.WorkOrderID
+	existing.MachineID = slot.MachineID
+	existing.Kind = slot.Kind
+	existing.StartTime = slot.StartTime
+	existing.EndTime = slot.EndTime
+
+	if err := s.save(existing); err != nil {
+		return err
+	}
+
+	*slot = *existing
+	return nil
+}
+
+func (s *Service) DeleteSlot(id int64) error {
+	slot, err := s.GetSlot(id)
+	if err != nil {
+		return err
+	}
+	return s.repo.Delete(slot)
+}
+
+// CheckSlot возвращает слоты, с которыми пересекся бы slot, не сохраняя его
+func (s *Service) CheckSlot(slot *Slot) ([]*Slot, error) {
+	if err := s.validate(slot); err != nil {
+		return nil, err
+	}
+
+	overlaps, err := s.repo.List(ListFilter{
+		From:      &slot.StartTime,
+		To:        &slot.EndTime,
+		MachineID: slot.MachineID,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	conflicts := make([]*Slot, 0, len(overlaps))
+	for _, o := range overlaps {
+		if o.ID != slot.ID {
+			conflicts = append(conflicts, o)
+		}
+	}
+	return conflicts, nil
+}
+
+// Gantt слоты за период [from, to), сгруппированные по линиям и машинам.
+// В выдачу попадают все машины линии, включая свободные.
+func (s *Service) Gantt(from, to time.Time, lineID int64) (*Gantt, error) {
+	if !to.After(from) {
+		return nil, ErrInvalidRange
+	}
+	if to.Sub(from) > MaxGanttRange {
+		return nil, ErrRangeTooLong
+	}
+
+	machines, err := s.repo.GanttMachines(lineID)
+	if err != nil {
+		return nil, err
+	}
+	slots, err := s.repo.GanttSlots(ListFilter{From: &from, To: &to, LineID: lineID})
+	if err != nil {
+		return nil, err
+	}
+
+	byMachine := make(map[int64]*GanttMachine, len(machines))
+	for _, m := range machines {
+		m.Slots = []*GanttSlot{}
+		byMachine[m.MachineID] = m
+	}
+	for _, sl := range slots {
+		sl.Late = late(sl.EndTime, sl.Deadline)
+		if m, ok := byMachine[sl.MachineID]; ok {
+			m.Slots = append(m.Slots, sl)
+		}
+	}
+
+	g := &Gantt{From: from, To: to, Lines: []*GanttLine{}}
+
+	// машины отсортированы по линиям, поэтому группа линии непрерывна
+	var line *GanttLine
+	for _, m := range machines {
+		if line == nil || !pkg.EqualPtr(line.LineID, m.LineID) {
+			line = &GanttLine{LineID: m.LineID, LineName: "Без линии"}
+			if m.LineName != nil {
+				line.LineName = *m.LineName
+			}
+			g.Lines = append(g.Lines, line)
+		}
+		line.Machines = append(line.Machines, m)
+	}
+
+	return g, nil
+}
+
+func (s *Service) validate(slot *Slot) error {
+	if !slot.EndTime.After(slot.StartTime) {
+		return ErrInvalidPeriod
+	}
+	switch slot.Kind {
+	case "":
+		slot.Kind = KindRun
+	case KindRun, KindSetup:
+	default:
+		return ErrInvalidKind
+	}
+
+	order, err := s.repo.GetOrder(slot.WorkOrderID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return ErrWorkOrderNotFound
+	}
+	if err != nil {
+		return err
+	}
+	if order.StatusID == workorder.StatusCompleted || order.StatusID == workorder.StatusCancelled {
+		return ErrOrderClosed
+	}
+
+	ok, err := s.repo.MachineExists(slot.MachineID)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return ErrMachineNotFound
+	}
+	return nil
+}
+
+func (s *Service) save(slot *Slot) error {
+	err := s.repo.Save(slot, func(overlaps []*Slot) error {
+		if len(overlaps) > 0 {
+			return &ConflictError{Conflicts: overlaps}
+		}
+		return nil
+	})
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return ErrMachineNotFound
+	}
+	return err
+}
+
+// late заканчивается ли слот после конца дня срока заказа
+func late(end time.Time, deadline *time.Time) bool {
+	if deadline == nil {
+		return false
+	}
+	y, m, d := deadline.Date()
+	endOfDay := time.Date(y, m, d, 23, 59, 59, 0, end.Location())
+	return end.After(endOfDay)
+}
@@ -0,0 +1,232 @@
+package skill
+
+import (
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"mes-lite-back/internal/http/middleware"
+	"mes-lite-back/pkg"
+
+	"github.com/go-chi/chi/v5"
+)
+
+type Handler struct {
+	service ServiceInterface
+	perms   middleware.PermissionChecker
+}
+
+func NewHandler(service ServiceInterface, perms middleware.PermissionChecker) *Handler {
+	return &Handler{
+		service: service,
+		perms:   perms,
+	}
+}
+
+// Routes квалификации пользователя (/users/{userID}/skills)
+func (h *Handler) Routes() chi.Router {
+	r := chi.NewRouter()
+
+	view := middleware.PermissionGuard(h.perms, "user.view")
+	edit := middleware.PermissionGuard(h.perms, "user.edit")
+
+	r.With(view).Get("/", h.list)
+	r.With(edit).Post("/", h.create)
+	r.With(edit).Put("/{stageID}", h.update)
+	r.With(edit).Delete("/{stageID}", h.delete)
+	// права наставника проверяются по матрице квалификаций, а не по роли
+	r.Post("/{stageID}/sign-off", h.signOff)
+
+	return r
+}
+
+type SkillRequest struct {
+	StageID            int64  `json:"stage_id,omitempty" example:"1"`
+	QualificationLevel int    `json:"qualification_level" example:"2"`
+	CertifiedUntil     string `json:"certified_until,omitempty" example:"2027-06-30"`
+}
+
+type ErrorResponse struct {
+	Error string `json:"error" example:"Описание ошибки"`
+}
+
+// ListSkills godoc
+// @Summary Квалификации пользователя
+// @Description Возвращает матрицу квалификаций пользователя по этапам
+// @Tags skills
+// @Security BearerAuth
+// @Produce json
+// @Param userID path int true "ID пользователя"
+// @Success 200 {array} Skill
+// @Failure 404 {object} ErrorResponse
+// @Router /users/{userID}/skills [get]
+func (h *Handler) list(w http.ResponseWriter, r *http.Request) {
+	skills, err := h.service.ListSkills(pkg.ParamInt64(r, "userID"))
+	if err != nil {
+		h.respondError(w, err)
+		return
+	}
+	pkg.RespondJSON(w, http.StatusOK, skills)
+}
+
+// CreateSkill godoc
+// @Summary Добавить квалификацию
+// @Description Добавляет квалификацию пользователя на этапе; уровни 1–2 требуют подтверждения наставником
+// @Tags skills
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param userID path int true "ID пользователя"
+// @Param request body SkillRequest true "Квалификация"
+// @Success 201 {object} Skill
+// @Failure 400 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse
+// @Router /users/{userID}/skills [post]
+func (h *Handler) create(w http.ResponseWriter, r *http.Request) {
+	var req SkillRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		pkg.RespondJSON(w, http.StatusBadRequest, ErrorResponse{Error: "Некорректный JSON"})
+		return
+	}
+
+	until, ok := parseDate(w, req.CertifiedUntil)
+	if !ok {
+		return
+	}
+
+	sk := &Skill{
+		UserID:             pkg.ParamInt64(r, "userID"),
+		StageID:            req.StageID,
+		QualificationLevel: req.QualificationLevel,
+		CertifiedUntil:     until,
+	}
+
+	if err := h.service.CreateSkill(sk); err != nil {
+		h.respondError(w, err)
+		return
+	}
+	pkg.RespondJSON(w, http.StatusCreated, sk)
+}
+
+// UpdateSkill godoc
+// @Summary Обновить квалификацию
+// @Description Меняет уровень и срок аттестации; при смене уровня подтверждение наставника сбрасывается
+// @Tags skills
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param userID path int true "ID пользователя"
+// @Param stageID path int true "ID этапа"
+// @Param request body SkillRequest true "Квалификация"
+// @Success 200 {object} Skill
+// @Failure 400 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Router /users/{userID}/skills/{stageID} [put]
+func (h *Handler) update(w http.ResponseWriter, r *http.Request) {
+	var req SkillRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		pkg.RespondJSON(w, http.StatusBadRequest, ErrorResponse{Error: "Некорректный JSON"})
+		return
+	}
+
+	until, ok := parseDate(w, req.CertifiedUntil)
+	if !ok {
+		return
+	}
+
+	sk := &Skill{
+		UserID:             pkg.ParamInt64(r, "userID"),
+		StageID:            pkg.ParamInt64(r, "stageID"),
+		QualificationLevel: req.QualificationLevel,
+		CertifiedUntil:     until,
+	}
+
+	if err := h.service.UpdateSkill(sk); err != nil {
+		h.respondError(w, err)
+		return
+	}
+	pkg.RespondJSON(w, http.StatusOK, sk)
+}
+
+// DeleteSkill godoc
+// @Summary Удалить квалификацию
+// @Tags skills
+// @Security BearerAuth
+// @Param userID path int true "ID пользователя"
+// @Param stageID path int true "ID этапа"
+// @Success 204
+// @Failure 404 {object} ErrorResponse
+// @Router /users/{userID}/skills/{stageID} [delete]
+func (h *Handler) delete(w http.ResponseWriter, r *http.Request) {
+	err := h.service.DeleteSkill(pkg.ParamInt64(r, "userID"), pkg.ParamInt64(r, "stageID"))
+	if err != nil {
+		h.respondError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// SignOffSkill godoc
+// @Summary Подтвердить квалификацию
+// @Description Подтвержденный наставник (уровень 3 на этом этапе) подтверждает квалификацию пользователя любого уровня. Первого наставника этапа подтверждает мастер с правом stage.override
+// @Tags skills
+// @Security BearerAuth
+// @Produce json
+// @Param userID path int true "ID пользователя"
+// @Param stageID path int true "ID этапа"
+// @Success 200 {object} Skill
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Router /users/{userID}/skills/{stageID}/sign-off [post]
+func (h *Handler) signOff(w http.ResponseWriter, r *http.Request) {
+	trainerID, _ := middleware.UserIDFromContext(r.Context())
+
+	sk, err := h.service.SignOff(
+		pkg.ParamInt64(r, "userID"),
+		pkg.ParamInt64(r, "stageID"),
+		trainerID,
+	)
+	if err != nil {
+		h.respondError(w, err)
+		return
+	}
+	pkg.RespondJSON(w, http.StatusOK, sk)
+}
+
+// parseDate разбирает дату вида 2006-01-02; пустая строка — без срока
+func parseDate(w http.ResponseWriter, value string) (*time.Time, bool) {
+	if value == "" {
+		return nil, true
+	}
+	t, err := time.Parse(time.DateOnly, value)
+	if err != nil {
+		pkg.RespondJSON(w, http.StatusBadRequest, ErrorResponse{Error: "Дата должна быть в формате ГГГГ-ММ-ДД"})
+		return nil, false
+	}
+	return &t, true
+}
+
+func (h *Handler) respondError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, ErrNotFound):
+		pkg.RespondJSON(w, http.StatusNotFound, ErrorResponse{Error: "Квалификация не найдена"})
+	case errors.Is(err, ErrUserNotFound):
+		pkg.RespondJSON(w, http.StatusNotFound, ErrorResponse{Error: "Пользователь не найден"})
+	case errors.Is(err, ErrStageNotFound):
+		pkg.RespondJSON(w, http.StatusNotFound, ErrorResponse{Error: "Этап не найден"})
+	case errors.Is(err, ErrInvalidLevel):
+		pkg.RespondJSON(w, http.StatusBadRequest, ErrorResponse{Error: "Уровень квалификации должен быть от 1 до 3"})
+	case errors.Is(err, ErrDuplicate):
+		pkg.RespondJSON(w, http.StatusConflict, ErrorResponse{Error: "У пользователя уже есть квалификация на этом этапе"})
+	case errors.Is(err, ErrNotTrainer):
+		pkg.RespondJSON(w, http.StatusForbidden, ErrorResponse{Error: "Подтверждать квалификацию может только наставник этого этапа"})
+	case errors.Is(err, ErrSelfSignOff):
+		pkg.RespondJSON(w, http.StatusForbidden, ErrorResponse{Error: "Нельзя подтвердить собственную квалификацию"})
+	default:
+		slog.Error("skill request failed", slog.Any("err", err))
+		pkg.RespondJSON(w, http.StatusInternalServerError, ErrorResponse{Error: "Внутренняя ошибка сервера"})
+	}
+}
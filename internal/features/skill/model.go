@@ -0,0 +1,41 @@
+package skill
+
+import "time"
+
+// Уровни квалификации оператора на этапе
+const (
+	LevelTrainee   = 1 // ученик: работает только под допуском мастера
+	LevelQualified = 2 // квалифицированный оператор
+	LevelTrainer   = 3 // наставник: может подтверждать квалификацию других
+)
+
+// Skill квалификация пользователя на этапе (таблица user_stages)
+type Skill struct {
+	ID                 int64      `gorm:"primaryKey;autoIncrement" json:"id"`
+	UserID             int64      `json:"user_id"`
+	StageID            int64      `json:"stage_id"`
+	QualificationLevel int        `json:"qualification_level"`
+	CertifiedUntil     *time.Time `gorm:"type:date" json:"certified_until,omitempty"`
+	TrainerID          *int64     `json:"trainer_id,omitempty"`
+	SignedOffAt        *time.Time `json:"signed_off_at,omitempty"`
+	CreatedAt          time.Time  `gorm:"autoCreateTime" json:"created_at"`
+}
+
+func (Skill) TableName() string {
+	return "user_stages"
+}
+
+// Expired истекла ли аттестация на момент at
+func (s *Skill) Expired(at time.Time) bool {
+	if s.CertifiedUntil == nil {
+		return false
+	}
+	y, m, d := s.CertifiedUntil.Date()
+	endOfDay := time.Date(y, m, d, 23, 59, 59, 0, at.Location())
+	return at.After(endOfDay)
+}
+
+// SignedOff подтверждена ли квалификация; без подтверждения не действует ни один уровень, включая наставника
+func (s *Skill) SignedOff() bool {
+	return s.SignedOffAt != nil
+}
@@ -0,0 +1,17 @@
+package skill
+
+import "time"
+
+type Repository interface {
+	Create(s *Skill) error
+	Update(s *Skill) error
+	Delete(s *Skill) error
+
+	Get(userID, stageID int64) (*Skill, error)
+	ListByUser(userID int64) ([]*Skill, error)
+	// HasTrainer есть ли на этапе подтвержденный наставник с действующей на дату at аттестацией
+	HasTrainer(stageID int64, at time.Time) (bool, error)
+
+	UserExists(userID int64) (bool, error)
+	StageExists(stageID int64) (bool, error)
+}
@@ -0,0 +1,71 @@
+package skill
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type GormRepository struct {
+	db *gorm.DB
+}
+
+func NewGormRepository(db *gorm.DB) *GormRepository {
+	return &GormRepository{db: db}
+}
+
+func (r *GormRepository) Create(s *Skill) error {
+	return r.db.Create(s).Error
+}
+
+func (r *GormRepository) Update(s *Skill) error {
+	return r.db.Save(s).Error
+}
+
+func (r *GormRepository) Delete(s *Skill) error {
+	return r.db.Delete(s).Error
+}
+
+func (r *GormRepository) Get(userID, stageID int64) (*Skill, error) {
+	var s Skill
+	err := r.db.
+		Where("user_id = ? AND stage_id = ?", userID, stageID).
+		First(&s).
+		Error
+	if err != nil {
+		return nil, err
+	}
+	return &s, nil
+}
+
+func (r *GormRepository) ListByUser(userID int64) ([]*Skill, error) {
+	var skills []*Skill
+	err := r.db.
+		Where("user_id = ?", userID).
+		Order("stage_id").
+		Find(&skills).
+		Error
+	return skills, err
+}
+
+func (r *GormRepository) HasTrainer(stageID int64, at time.Time) (bool, error) {
+	var n int64
+	err := r.db.Model(&Skill{}).
+		Where("stage_id = ? AND qualification_level >= ? AND signed_off_at IS NOT NULL", stageID, LevelTrainer).
+		Where("certified_until IS NULL OR certified_until >= ?", at.Format(time.DateOnly)).
+		Count(&n).
+		Error
+	return n > 0, err
+}
+
+func (r *GormRepository) UserExists(userID int64) (bool, error) {
+	var n int64
+	err := r.db.Table("users").Where("id = ?", userID).Count(&n).Error
+	return n > 0, err
+}
+
+func (r *GormRepository) StageExists(stageID int64) (bool, error) {
+	var n int64
+	err := r.db.Table("stages").Where("id = ?", stageID).Count(&n).Error
+	return n > 0, err
+}
@@ -0,0 +1,205 @@
+package skill
+
+import (
+	"errors"
+	"time"
+
+	"mes-lite-back/internal/http/middleware"
+
+	"gorm.io/gorm"
+)
+
+var (
+	ErrNotFound             = errors.New("skill not found")
+	ErrUserNotFound         = errors.New("user not found")
+	ErrStageNotFound        = errors.New("stage not found")
+	ErrInvalidLevel         = errors.New("qualification level must be between 1 and 3")
+	ErrDuplicate            = errors.New("user already has a qualification for this stage")
+	ErrNotTrainer           = errors.New("sign-off requires a certified trainer for this stage")
+	ErrSelfSignOff          = errors.New("trainer cannot sign off own qualification")
+	ErrNotQualified         = errors.New("operator is not qualified for this stage")
+	ErrNotSignedOff         = errors.New("qualification is not signed off by a trainer")
+	ErrCertificationExpired = errors.New("operator certification has expired")
+)
+
+// ServiceInterface определяет методы, используемые handler’ом
+type ServiceInterface interface {
+	ListSkills(userID int64) ([]*Skill, error)
+	CreateSkill(s *Skill) error
+	UpdateSkill(s *Skill) error
+	DeleteSkill(userID, stageID int64) error
+	SignOff(userID, stageID, trainerID int64) (*Skill, error)
+}
+
+type Service struct {
+	repo  Repository
+	perms middleware.PermissionChecker
+	now   func() time.Time
+}
+
+func NewService(repo Repository, perms middleware.PermissionChecker) *Service {
+	return &Service{
+		repo:  repo,
+		perms: perms,
+		now:   time.Now,
+	}
+}
+
+func (s *Service) ListSkills(userID int64) ([]*Skill, error) {
+	if err := s.checkUser(userID); err != nil {
+		return nil, err
+	}
+	return s.repo.ListByUser(userID)
+}
+
+func (s *Service) CreateSkill(sk *Skill) error {
+	if sk.QualificationLevel < LevelTrainee || sk.QualificationLevel > LevelTrainer {
+		return ErrInvalidLevel
+	}
+	if err := s.checkUser(sk.UserID); err != nil {
+		return err
+	}
+
+	ok, err := s.repo.StageExists(sk.StageID)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return ErrStageNotFound
+	}
+
+	if _, err := s.get(sk.UserID, sk.StageID); err == nil {
+		return ErrDuplicate
+	} else if !errors.Is(err, ErrNotFound) {
+		return err
+	}
+
+	sk.TrainerID = nil
+	sk.SignedOffAt = nil
+	return s.repo.Create(sk)
+}
+
+// UpdateSkill меняет уровень и срок аттестации; смена уровня требует повторного подтверждения наставником
+func (s *Service) UpdateSkill(sk *Skill) error {
+	if sk.QualificationLevel < LevelTrainee || sk.QualificationLevel > LevelTrainer {
+		return ErrInvalidLevel
+	}
+
+	existing, err := s.get(sk.UserID, sk.StageID)
+	if err != nil {
+		return err
+	}
+
+	if existing.QualificationLevel != sk.QualificationLevel {
+		existing.TrainerID = nil
+		existing.SignedOffAt = nil
+	}
+	existing.QualificationLevel = sk.QualificationLevel
+	existing.CertifiedUntil = sk.CertifiedUntil
+
+	if err := s.repo.Update(existing); err != nil {
+		return err
+	}
+
+	*sk = *existing
+	return nil
+}
+
+func (s *Service) DeleteSkill(userID, stageID int64) error {
+	sk, err := s.get(userID, stageID)
+	if err != nil {
+		return err
+	}
+	return s.repo.Delete(sk)
+}
+
+// SignOff подтверждение квалификации подтвержденным наставником с действующей квалификацией на том же этапе.
+// Первого наставника этапа, пока подтвержденных наставников на нем нет, подтверждает мастер с правом stage.override.
+func (s *Service) SignOff(userID, stageID, trainerID int64) (*Skill, error) {
+	if userID == trainerID {
+		return nil, ErrSelfSignOff
+	}
+
+	sk, err := s.get(userID, stageID)
+	if err != nil {
+		return nil, err
+	}
+
+	now := s.now()
+
+	ok, err := s.canSignOff(sk, trainerID, now)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, ErrNotTrainer
+	}
+
+	sk.TrainerID = &trainerID
+	sk.SignedOffAt = &now
+
+	if err := s.repo.Update(sk); err != nil {
+		return nil, err
+	}
+	return sk, nil
+}
+
+func (s *Service) canSignOff(sk *Skill, trainerID int64, now time.Time) (bool, error) {
+	trainer, err := s.get(trainerID, sk.StageID)
+	if err == nil && trainer.QualificationLevel >= LevelTrainer && trainer.SignedOff() && !trainer.Expired(now) {
+		return true, nil
+	}
+	if err != nil && !errors.Is(err, ErrNotFound) {
+		return false, err
+	}
+
+	if sk.QualificationLevel < LevelTrainer {
+		return false, nil
+	}
+	exists, err := s.repo.HasTrainer(sk.StageID, now)
+	if err != nil || exists {
+		return false, err
+	}
+	return s.perms.HasPermission(trainerID, "stage.override")
+}
+
+// CheckQualification проверяет допуск оператора к этапу на момент at
+func (s *Service) CheckQualification(userID, stageID int64, at time.Time) error {
+	sk, err := s.get(userID, stageID)
+	if errors.Is(err, ErrNotFound) {
+		return ErrNotQualified
+	}
+	if err != nil {
+		return err
+	}
+
+	if sk.QualificationLevel < LevelQualified {
+		return ErrNotQualified
+	}
+	if !sk.SignedOff() {
+		return ErrNotSignedOff
+	}
+	if sk.Expired(at) {
+		return ErrCertificationExpired
+	}
+	return nil
+}
+
+func (s *Service) get(userID, stageID int64) (*Skill, error) {
+	sk, err := s.repo.Get(userID, stageID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrNotFound
+	}
+	return sk, err
+}
+
+func (s *Service) checkUser(userID int64) error {
+	ok, err := s.repo.UserExists(userID)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return ErrUserNotFound
+	}
+	return nil
+}
@@ -0,0 +1,489 @@
+package stage
+
+import (
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strconv"
+
+	"mes-lite-back/internal/http/middleware"
+	"mes-lite-back/pkg"
+
+	"github.com/go-chi/chi/v5"
+)
+
+type Handler struct {
+	service ServiceInterface
+	perms   middleware.PermissionChecker
+}
+
+func NewHandler(service ServiceInterface, perms middleware.PermissionChecker) *Handler {
+	return &Handler{
+		service: service,
+		perms:   perms,
+	}
+}
+
+// Routes справочник этапов (/stages)
+func (h *Handler) Routes() chi.Router {
+	r := chi.NewRouter()
+
+	view := middleware.PermissionGuard(h.perms, "stage.view")
+	edit := middleware.PermissionGuard(h.perms, "stage.edit")
+
+	r.With(view).Get("/", h.list)
+	r.With(view).Get("/{id}", h.getByID)
+	r.With(edit).Post("/", h.create)
+	r.With(edit).Put("/{id}", h.update)
+	r.With(edit).Delete("/{id}", h.delete)
+
+	return r
+}
+
+// RoutingRoutes маршрут продукта (/products/{productID}/routing)
+func (h *Handler) RoutingRoutes() chi.Router {
+	r := chi.NewRouter()
+
+	view := middleware.PermissionGuard(h.perms, "stage.view")
+	edit := middleware.PermissionGuard(h.perms, "stage.edit")
+
+	r.With(view).Get("/", h.getRouting)
+	r.With(edit).Put("/", h.replaceRouting)
+	r.With(edit).Post("/steps", h.insertStep)
+	r.With(edit).Delete("/steps/{stageID}", h.removeStep)
+	r.With(edit).Put("/order", h.reorder)
+	r.With(view).Get("/versions", h.listVersions)
+	r.With(view).Get("/diff", h.diff)
+	r.With(edit).Post("/versions/{version}/release", h.release)
+	r.With(edit).Delete("/versions/{version}", h.discardDraft)
+
+	return r
+}
+
+type CreateRequest struct {
+	Name             string `json:"name" example:"Сборка"`
+	Description      string `json:"description,omitempty" example:"Сборка узла на линии 1"`
+	IsStrictSequence *bool  `json:"is_strict_sequence,omitempty" example:"true"`
+}
+
+type ReplaceRoutingRequest struct {
+	Steps []RoutingStep `json:"steps"`
+}
+
+type ReorderRequest struct {
+	StageIDs []int64 `json:"stage_ids" example:"3,1,2"`
+}
+
+type ErrorResponse struct {
+	Error string `json:"error" example:"Описание ошибки"`
+}
+
+// ListStages godoc
+// @Summary Получить список этапов
+// @Description Возвращает справочник производственных этапов
+// @Tags stages
+// @Security BearerAuth
+// @Produce json
+// @Success 200 {array} Stage
+// @Failure 500 {object} ErrorResponse
+// @Router /stages [get]
+func (h *Handler) list(w http.ResponseWriter, r *http.Request) {
+	stages, err := h.service.ListStages()
+	if err != nil {
+		slog.Error("list stages failed", slog.Any("err", err))
+		pkg.RespondJSON(w, http.StatusInternalServerError, ErrorResponse{Error: "Не удалось получить список этапов"})
+		return
+	}
+	pkg.RespondJSON(w, http.StatusOK, stages)
+}
+
+// GetStage godoc
+// @Summary Получить этап по ID
+// @Tags stages
+// @Security BearerAuth
+// @Produce json
+// @Param id path int true "ID этапа"
+// @Success 200 {object} Stage
+// @Failure 400 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Router /stages/{id} [get]
+func (h *Handler) getByID(w http.ResponseWriter, r *http.Request) {
+	id := pkg.ParamID(r)
+	if id == 0 {
+		pkg.RespondJSON(w, http.StatusBadRequest, ErrorResponse{Error: "Некорректный ID этапа"})
+		return
+	}
+
+	st, err := h.service.GetStage(id)
+	if err != nil {
+		h.respondError(w, err)
+		return
+	}
+	pkg.RespondJSON(w, http.StatusOK, st)
+}
+
+// CreateStage godoc
+// @Summary Создать этап
+// @Tags stages
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param request body CreateRequest true "Данные этапа"
+// @Success 201 {object} Stage
+// @Failure 400 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /stages [post]
+func (h *Handler) create(w http.ResponseWriter, r *http.Request) {
+	var req CreateRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		pkg.RespondJSON(w, http.StatusBadRequest, ErrorResponse{Error: "Некорректный JSON"})
+		return
+	}
+
+	st := &Stage{
+		Name:             req.Name,
+		Description:      req.Description,
+		IsStrictSequence: true,
+	}
+	if req.IsStrictSequence != nil {
+		st.IsStrictSequence = *req.IsStrictSequence
+	}
+
+	if err := h.service.CreateStage(st); err != nil {
+		h.respondError(w, err)
+		return
+	}
+	pkg.RespondJSON(w, http.StatusCreated, st)
+}
+
+// UpdateStage godoc
+// @Summary Обновить этап
+// @Tags stages
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param id path int true "ID этапа"
+// @Param request body CreateRequest true "Данные этапа"
+// @Success 200 {object} Stage
+// @Failure 400 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Router /stages/{id} [put]
+func (h *Handler) update(w http.ResponseWriter, r *http.Request) {
+	id := pkg.ParamID(r)
+	if id == 0 {
+		pkg.RespondJSON(w, http.StatusBadRequest, ErrorResponse{Error: "Некорректный ID этапа"})
+		return
+	}
+
+	var req CreateRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		pkg.RespondJSON(w, http.StatusBadRequest, ErrorResponse{Error: "Некорректный JSON"})
+		return
+	}
+
+	st := &Stage{
+		ID:               id,
+		Name:             req.Name,
+		Description:      req.Description,
+		IsStrictSequence: true,
+	}
+	if req.IsStrictSequence != nil {
+		st.IsStrictSequence = *req.IsStrictSequence
+	}
+
+	if err := h.service.UpdateStage(st); err != nil {
+		h.respondError(w, err)
+		return
+	}
+	pkg.RespondJSON(w, http.StatusOK, st)
+}
+
+// DeleteStage godoc
+// @Summary Удалить этап
+// @Description Удаляет этап, если он не используется в маршрутах и исполнении
+// @Tags stages
+// @Security BearerAuth
+// @Param id path int true "ID этапа"
+// @Success 204
+// @Failure 404 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse
+// @Router /stages/{id} [delete]
+func (h *Handler) delete(w http.ResponseWriter, r *http.Request) {
+	id := pkg.ParamID(r)
+	if id == 0 {
+		pkg.RespondJSON(w, http.StatusBadRequest, ErrorResponse{Error: "Некорректный ID этапа"})
+		return
+	}
+
+	if err := h.service.DeleteStage(id); err != nil {
+		h.respondError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// GetRouting godoc
+// @Summary Получить маршрут продукта
+// @Description Возвращает этапы версии маршрута в порядке выполнения с ожидаемым временем цикла; по умолчанию действующую версию
+// @Tags routing
+// @Security BearerAuth
+// @Produce json
+// @Param productID path int true "ID продукта"
+// @Param version query int false "Номер версии"
+// @Success 200 {object} Routing
+// @Failure 404 {object} ErrorResponse
+// @Router /products/{productID}/routing [get]
+func (h *Handler) getRouting(w http.ResponseWriter, r *http.Request) {
+	version, _ := strconv.Atoi(r.URL.Query().Get("version"))
+
+	rt, err := h.service.GetRouting(pkg.ParamInt64(r, "productID"), version)
+	if err != nil {
+		h.respondError(w, err)
+		return
+	}
+	pkg.RespondJSON(w, http.StatusOK, rt)
+}
+
+// ReplaceRouting godoc
+// @Summary Заменить маршрут продукта
+// @Description Атомарно заменяет маршрут в черновике версии; stage_order должен идти подряд с 1
+// @Tags routing
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param productID path int true "ID продукта"
+// @Param request body ReplaceRoutingRequest true "Шаги маршрута"
+// @Success 200 {object} Routing
+// @Failure 400 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Router /products/{productID}/routing [put]
+func (h *Handler) replaceRouting(w http.ResponseWriter, r *http.Request) {
+	var req ReplaceRoutingRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		pkg.RespondJSON(w, http.StatusBadRequest, ErrorResponse{Error: "Некорректный JSON"})
+		return
+	}
+
+	rt, err := h.service.ReplaceRouting(pkg.ParamInt64(r, "productID"), currentUser(r), req.Steps)
+	if err != nil {
+		h.respondError(w, err)
+		return
+	}
+	pkg.RespondJSON(w, http.StatusOK, rt)
+}
+
+// InsertRoutingStep godoc
+// @Summary Вставить этап в маршрут
+// @Description Вставляет этап в черновик на позицию stage_order со сдвигом последующих; без позиции — в конец
+// @Tags routing
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param productID path int true "ID продукта"
+// @Param request body RoutingStep true "Этап и позиция"
+// @Success 200 {object} Routing
+// @Failure 400 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse
+// @Router /products/{productID}/routing/steps [post]
+func (h *Handler) insertStep(w http.ResponseWriter, r *http.Request) {
+	var req RoutingStep
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		pkg.RespondJSON(w, http.StatusBadRequest, ErrorResponse{Error: "Некорректный JSON"})
+		return
+	}
+
+	rt, err := h.service.InsertStep(pkg.ParamInt64(r, "productID"), currentUser(r), req)
+	if err != nil {
+		h.respondError(w, err)
+		return
+	}
+	pkg.RespondJSON(w, http.StatusOK, rt)
+}
+
+// RemoveRoutingStep godoc
+// @Summary Удалить этап из маршрута
+// @Description Удаляет этап из черновика и перенумеровывает оставшиеся
+// @Tags routing
+// @Security BearerAuth
+// @Produce json
+// @Param productID path int true "ID продукта"
+// @Param stageID path int true "ID этапа"
+// @Success 200 {object} Routing
+// @Failure 404 {object} ErrorResponse
+// @Router /products/{productID}/routing/steps/{stageID} [delete]
+func (h *Handler) removeStep(w http.ResponseWriter, r *http.Request) {
+	rt, err := h.service.RemoveStep(
+		pkg.ParamInt64(r, "productID"),
+		currentUser(r),
+		pkg.ParamInt64(r, "stageID"),
+	)
+	if err != nil {
+		h.respondError(w, err)
+		return
+	}
+	pkg.RespondJSON(w, http.StatusOK, rt)
+}
+
+// ReorderRouting godoc
+// @Summary Изменить порядок этапов
+// @Description Задает новый порядок этапов черновика списком ID этапов
+// @Tags routing
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param productID path int true "ID продукта"
+// @Param request body ReorderRequest true "Этапы в новом порядке"
+// @Success 200 {object} Routing
+// @Failure 400 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Router /products/{productID}/routing/order [put]
+func (h *Handler) reorder(w http.ResponseWriter, r *http.Request) {
+	var req ReorderRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		pkg.RespondJSON(w, http.StatusBadRequest, ErrorResponse{Error: "Некорректный JSON"})
+		return
+	}
+
+	rt, err := h.service.ReorderSteps(pkg.ParamInt64(r, "productID"), currentUser(r), req.StageIDs)
+	if err != nil {
+		h.respondError(w, err)
+		return
+	}
+	pkg.RespondJSON(w, http.StatusOK, rt)
+}
+
+// ListRoutingVersions godoc
+// @Summary Получить версии маршрута
+// @Tags routing
+// @Security BearerAuth
+// @Produce json
+// @Param productID path int true "ID продукта"
+// @Success 200 {array} RoutingVersion
+// @Failure 404 {object} ErrorResponse
+// @Router /products/{productID}/routing/versions [get]
+func (h *Handler) listVersions(w http.ResponseWriter, r *http.Request) {
+	versions, err := h.service.ListVersions(pkg.ParamInt64(r, "productID"))
+	if err != nil {
+		h.respondError(w, err)
+		return
+	}
+	pkg.RespondJSON(w, http.StatusOK, versions)
+}
+
+// ReleaseRoutingVersion godoc
+// @Summary Утвердить версию маршрута
+// @Description Переводит черновик в действующую версию; прежняя действующая становится устаревшей. Автор и последний редактор черновика утвердить его не могут
+// @Tags routing
+// @Security BearerAuth
+// @Produce json
+// @Param productID path int true "ID продукта"
+// @Param version path int true "Номер версии"
+// @Success 200 {object} Routing
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse
+// @Router /products/{productID}/routing/versions/{version}/release [post]
+func (h *Handler) release(w http.ResponseWriter, r *http.Request) {
+	rt, err := h.service.ReleaseVersion(
+		pkg.ParamInt64(r, "productID"),
+		int(pkg.ParamInt64(r, "version")),
+		currentUser(r),
+	)
+	if err != nil {
+		h.respondError(w, err)
+		return
+	}
+	pkg.RespondJSON(w, http.StatusOK, rt)
+}
+
+// DiscardRoutingDraft godoc
+// @Summary Удалить черновик маршрута
+// @Tags routing
+// @Security BearerAuth
+// @Param productID path int true "ID продукта"
+// @Param version path int true "Номер версии"
+// @Success 204
+// @Failure 404 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse
+// @Router /products/{productID}/routing/versions/{version} [delete]
+func (h *Handler) discardDraft(w http.ResponseWriter, r *http.Request) {
+	err := h.service.DiscardDraft(
+		pkg.ParamInt64(r, "productID"),
+		int(pkg.ParamInt64(r, "version")),
+	)
+	if err != nil {
+		h.respondError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// DiffRoutingVersions godoc
+// @Summary Сравнить версии маршрута
+// @Description Возвращает добавленные, удаленные и измененные этапы между версиями from и to
+// @Tags routing
+// @Security BearerAuth
+// @Produce json
+// @Param productID path int true "ID продукта"
+// @Param from query int true "Исходная версия"
+// @Param to query int true "Новая версия"
+// @Success 200 {object} RoutingDiff
+// @Failure 400 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Router /products/{productID}/routing/diff [get]
+func (h *Handler) diff(w http.ResponseWriter, r *http.Request) {
+	from, errFrom := strconv.Atoi(r.URL.Query().Get("from"))
+	to, errTo := strconv.Atoi(r.URL.Query().Get("to"))
+	if errFrom != nil || errTo != nil || from <= 0 || to <= 0 {
+		pkg.RespondJSON(w, http.StatusBadRequest, ErrorResponse{Error: "Укажите номера версий from и to"})
+		return
+	}
+
+	diff, err := h.service.DiffVersions(pkg.ParamInt64(r, "productID"), from, to)
+	if err != nil {
+		h.respondError(w, err)
+		return
+	}
+	pkg.RespondJSON(w, http.StatusOK, diff)
+}
+
+func currentUser(r *http.Request) int64 {
+	id, _ := middleware.UserIDFromContext(r.Context())
+	return id
+}
+
+func (h *Handler) respondError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, ErrNotFound):
+		pkg.RespondJSON(w, http.StatusNotFound, ErrorResponse{Error: "Этап не найден"})
+	case errors.Is(err, ErrProductNotFound):
+		pkg.RespondJSON(w, http.StatusNotFound, ErrorResponse{Error: "Продукт не найден"})
+	case errors.Is(err, ErrNameEmpty):
+		pkg.RespondJSON(w, http.StatusBadRequest, ErrorResponse{Error: "Название этапа обязательно"})
+	case errors.Is(err, ErrInvalidOrder):
+		pkg.RespondJSON(w, http.StatusBadRequest, ErrorResponse{Error: "Порядок этапов должен идти подряд, начиная с 1"})
+	case errors.Is(err, ErrInvalidCycle):
+		pkg.RespondJSON(w, http.StatusBadRequest, ErrorResponse{Error: "Время цикла должно быть положительным"})
+	case errors.Is(err, ErrUnknownStage):
+		pkg.RespondJSON(w, http.StatusBadRequest, ErrorResponse{Error: "Маршрут ссылается на несуществующий этап"})
+	case errors.Is(err, ErrStageNotInRouting):
+		pkg.RespondJSON(w, http.StatusBadRequest, ErrorResponse{Error: "Этап не входит в маршрут продукта"})
+	case errors.Is(err, ErrDuplicateStage):
+		pkg.RespondJSON(w, http.StatusConflict, ErrorResponse{Error: "Этап уже есть в маршруте"})
+	case errors.Is(err, ErrVersionNotFound):
+		pkg.RespondJSON(w, http.StatusNotFound, ErrorResponse{Error: "Версия маршрута не найдена"})
+	case errors.Is(err, ErrNotDraft):
+		pkg.RespondJSON(w, http.StatusConflict, ErrorResponse{Error: "Версия маршрута не является черновиком"})
+	case errors.Is(err, ErrEmptyRouting):
+		pkg.RespondJSON(w, http.StatusConflict, ErrorResponse{Error: "Нельзя утвердить пустой маршрут"})
+	case errors.Is(err, ErrSelfApproval):
+		pkg.RespondJSON(w, http.StatusForbidden, ErrorResponse{Error: "Автор или редактор черновика не может его утвердить"})
+	case errors.Is(err, ErrInUse):
+		pkg.RespondJSON(w, http.StatusConflict, ErrorResponse{Error: "Этап используется в маршрутах или исполнении"})
+	default:
+		slog.Error("stage request failed", slog.Any("err", err))
+		pkg.RespondJSON(w, http.StatusInternalServerError, ErrorResponse{Error: "Внутренняя ошибка сервера"})
+	}
+}
@@ -0,0 +1,98 @@
+package stage
+
+import "time"
+
+type Stage struct {
+	ID               int64  `gorm:"primaryKey;autoIncrement" json:"id"`
+	Name             string `gorm:"not null" json:"name"`
+	Description      string `gorm:"type:text" json:"description,omitempty"`
+	IsStrictSequence bool   `gorm:"default:true" json:"is_strict_sequence"`
+}
+
+func (Stage) TableName() string {
+	return "stages"
+}
+
+// ProductStage шаг маршрута: этап в составе маршрута продукта
+type ProductStage struct {
+	ID               int64 `gorm:"primaryKey;autoIncrement" json:"id"`
+	ProductID        int64 `json:"product_id"`
+	RoutingVersionID int64 `json:"routing_version_id"`
+	StageID          int64 `json:"stage_id"`
+	StageOrder       int   `json:"stage_order"`
+	ExpectedCycleMin *int  `json:"expected_cycle_min,omitempty"`
+
+	Stage Stage `gorm:"foreignKey:StageID" json:"stage"`
+}
+
+func (ProductStage) TableName() string {
+	return "product_stages"
+}
+
+// Статусы версии маршрута: draft → released → obsolete
+const (
+	VersionDraft    = "draft"
+	VersionReleased = "released"
+	VersionObsolete = "obsolete"
+)
+
+// RoutingVersion версия маршрута продукта
+type RoutingVersion struct {
+	ID          int64      `gorm:"primaryKey;autoIncrement" json:"id"`
+	ProductID   int64      `json:"product_id"`
+	Version     int        `json:"version"`
+	Status      string     `json:"status"`
+	CreatedBy   *int64     `json:"created_by,omitempty"`
+	CreatedAt   time.Time  `gorm:"autoCreateTime" json:"created_at"`
+	UpdatedBy   *int64     `json:"updated_by,omitempty"`
+	ApprovedBy  *int64     `json:"approved_by,omitempty"`
+	ReleasedAt  *time.Time `json:"released_at,omitempty"`
+	ObsoletedAt *time.Time `json:"obsoleted_at,omitempty"`
+}
+
+func (RoutingVersion) TableName() string {
+	return "routing_versions"
+}
+
+// Routing полный маршрут продукта в одной из версий
+type Routing struct {
+	ProductID int64           `json:"product_id"`
+	Version   *RoutingVersion `json:"version,omitempty"`
+	Steps     []*ProductStage `json:"steps"`
+	// TotalCycleMin сумма ожидаемых времён по шагам, где они заданы
+	TotalCycleMin int `json:"total_cycle_min"`
+}
+
+// RoutingStep шаг маршрута во входных данных редактора
+type RoutingStep struct {
+	StageID          int64 `json:"stage_id" example:"1"`
+	StageOrder       int   `json:"stage_order" example:"1"`
+	ExpectedCycleMin *int  `json:"expected_cycle_min,omitempty" example:"15"`
+}
+
+// RoutingDiff различия между двумя версиями маршрута
+type RoutingDiff struct {
+	ProductID   int64         `json:"product_id"`
+	FromVersion int           `json:"from_version"`
+	ToVersion   int           `json:"to_version"`
+	Added       []*DiffStep   `json:"added"`
+	Removed     []*DiffStep   `json:"removed"`
+	Changed     []*DiffChange `json:"changed"`
+}
+
+type DiffStep struct {
+	StageID          int64  `json:"stage_id"`
+	StageName        string `json:"stage_name"`
+	StageOrder       int    `json:"stage_order"`
+	ExpectedCycleMin *int   `json:"expected_cycle_min,omitempty"`
+}
+
+// DiffChange этап есть в обеих версиях, но изменился порядок или время цикла
+type DiffChange struct {
+	StageID     int64  `json:"stage_id"`
+	StageName   string `json:"stage_name"`
+	OldOrder    int    `json:"old_order"`
+	NewOrder    int    `json:"new_order"`
+	OldCycleMin *int   `json:"old_cycle_min,omitempty"`
+	NewCycleMin *int   `json:"new_cycle_min,omitempty"`
+}
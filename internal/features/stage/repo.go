@@ -0,0 +1,30 @@
+package stage
+
+// RoutingMutator получает текущие шаги маршрута и возвращает новые
+type RoutingMutator func(current []*ProductStage) ([]*ProductStage, error)
+
+type Repository interface {
+	Create(s *Stage) error
+	Update(s *Stage) error
+	Delete(s *Stage) error
+
+	GetByID(id int64) (*Stage, error)
+	GetByIDs(ids []int64) ([]*Stage, error)
+	List() ([]*Stage, error)
+	// IsReferenced проверяет, используется ли этап в маршрутах или исполнении
+	IsReferenced(id int64) (bool, error)
+
+	ProductExists(productID int64) (bool, error)
+
+	ListVersions(productID int64) ([]*RoutingVersion, error)
+	GetVersion(productID int64, version int) (*RoutingVersion, error)
+	GetVersionByStatus(productID int64, status string) (*RoutingVersion, error)
+	GetVersionSteps(versionID int64) ([]*ProductStage, error)
+
+	// UpdateDraft атомарно применяет mutate к черновику маршрута;
+	// если черновика нет, он создаётся копией действующей версии
+	UpdateDraft(productID, userID int64, mutate RoutingMutator) (*RoutingVersion, error)
+	// ReleaseVersion переводит черновик в released, а прежнюю действующую версию — в obsolete
+	ReleaseVersion(productID int64, version int, approverID int64) error
+	DeleteDraft(productID int64, version int) error
+}
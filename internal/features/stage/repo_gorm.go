@@ -0,0 +1,288 @@
+package stage
+
+import (
+	"errors"
+	"time"
+
+	"mes-lite-back/pkg"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type GormRepository struct {
+	db *gorm.DB
+}
+
+func NewGormRepository(db *gorm.DB) *GormRepository {
+	return &GormRepository{db: db}
+}
+
+func (r *GormRepository) Create(s *Stage) error {
+	return r.db.Create(s).Error
+}
+
+func (r *GormRepository) Update(s *Stage) error {
+	return r.db.Save(s).Error
+}
+
+func (r *GormRepository) Delete(s *Stage) error {
+	return r.db.Delete(s).Error
+}
+
+func (r *GormRepository) GetByID(id int64) (*Stage, error) {
+	var s Stage
+	if err := r.db.First(&s, id).Error; err != nil {
+		return nil, err
+	}
+	return &s, nil
+}
+
+func (r *GormRepository) GetByIDs(ids []int64) ([]*Stage, error) {
+	var stages []*Stage
+	return stages, r.db.Where("id IN ?", ids).Find(&stages).Error
+}
+
+func (r *GormRepository) List() ([]*Stage, error) {
+	var stages []*Stage
+	return stages, r.db.Order("id").Find(&stages).Error
+}
+
+func (r *GormRepository) IsReferenced(id int64) (bool, error) {
+	var n int64
+	err := r.db.Raw(`
+		SELECT
+			(SELECT COUNT(*) FROM product_stages WHERE stage_id = ?) +
+			(SELECT COUNT(*) FROM stage_execution WHERE stage_id = ?)`,
+		id, id,
+	).Scan(&n).Error
+	return n > 0, err
+}
+
+func (r *GormRepository) ProductExists(productID int64) (bool, error) {
+	var n int64
+	err := r.db.Table("products").Where("id = ?", productID).Count(&n).Error
+	return n > 0, err
+}
+
+func (r *GormRepository) ListVersions(productID int64) ([]*RoutingVersion, error) {
+	var versions []*RoutingVersion
+	err := r.db.
+		Where("product_id = ?", productID).
+		Order("version").
+		Find(&versions).
+		Error
+	return versions, err
+}
+
+func (r *GormRepository) GetVersion(productID int64, version int) (*RoutingVersion, error) {
+	var v RoutingVersion
+	err := r.db.
+		Where("product_id = ? AND version = ?", productID, version).
+		First(&v).
+		Error
+	if err != nil {
+		return nil, err
+	}
+	return &v, nil
+}
+
+func (r *GormRepository) GetVersionByStatus(productID int64, status string) (*RoutingVersion, error) {
+	return versionByStatus(r.db, productID, status)
+}
+
+func (r *GormRepository) GetVersionSteps(versionID int64) ([]*ProductStage, error) {
+	return loadSteps(r.db, versionID)
+}
+
+func (r *GormRepository) UpdateDraft(productID, userID int64, mutate RoutingMutator) (*RoutingVersion, error) {
+	var draft *RoutingVersion
+
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		if err := lockProduct(tx, productID); err != nil {
+			return err
+		}
+
+		var err error
+		draft, err = versionByStatus(tx, productID, VersionDraft)
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			draft, err = createDraft(tx, productID, userID)
+		}
+		if err != nil {
+			return err
+		}
+
+		current, err := loadSteps(tx, draft.ID)
+		if err != nil {
+			return err
+		}
+
+		next, err := mutate(current)
+		if err != nil {
+			return err
+		}
+
+		if err := tx.Where("routing_version_id = ?", draft.ID).
+			Delete(&ProductStage{}).Error; err != nil {
+			return err
+		}
+
+		if userID > 0 {
+			draft.UpdatedBy = &userID
+			if err := tx.Model(draft).Update("updated_by", userID).Error; err != nil {
+				return err
+			}
+		}
+
+		return insertSteps(tx, draft, next)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return draft, nil
+}
+
+func (r *GormRepository) ReleaseVersion(productID int64, version int, approverID int64) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := lockProduct(tx, productID); err != nil {
+			return err
+		}
+
+		var v RoutingVersion
+		if err := tx.Where("product_id = ? AND version = ?", productID, version).
+			First(&v).Error; err != nil {
+			return err
+		}
+		if v.Status != VersionDraft {
+			return ErrNotDraft
+		}
+		if approverID > 0 && (pkg.EqualPtr(v.CreatedBy, &approverID) || pkg.EqualPtr(v.UpdatedBy, &approverID)) {
+			return ErrSelfApproval
+		}
+
+		now := time.Now()
+
+		if err := tx.Model(&RoutingVersion{}).
+			Where("product_id = ? AND status = ?", productID, VersionReleased).
+			Updates(map[string]any{
+				"status":       VersionObsolete,
+				"obsoleted_at": now,
+			}).Error; err != nil {
+			return err
+		}
+
+		updates := map[string]any{
+			"status":      VersionReleased,
+			"released_at": now,
+		}
+		if approverID > 0 {
+			updates["approved_by"] = approverID
+		}
+
+		return tx.Model(&v).Updates(updates).Error
+	})
+}
+
+func (r *GormRepository) DeleteDraft(productID int64, version int) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		var v RoutingVersion
+		if err := tx.Where("product_id = ? AND version = ?", productID, version).
+			First(&v).Error; err != nil {
+			return err
+		}
+		if v.Status != VersionDraft {
+			return ErrNotDraft
+		}
+
+		if err := tx.Where("routing_version_id = ?", v.ID).
+			Delete(&ProductStage{}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&v).Error
+	})
+}
+
+// lockProduct блокирует продукт, чтобы параллельные правки маршрута шли по очереди
+func lockProduct(tx *gorm.DB, productID int64) error {
+	var locked struct{ ID int64 }
+	return tx.Table("products").
+		Clauses(clause.Locking{Strength: "UPDATE"}).
+		Select("id").
+		Where("id = ?", productID).
+		Take(&locked).
+		Error
+}
+
+// createDraft создаёт новую версию-черновик с копией шагов действующей версии
+func createDraft(tx *gorm.DB, productID, userID int64) (*RoutingVersion, error) {
+	var maxVersion int
+	if err := tx.Model(&RoutingVersion{}).
+		Select("COALESCE(MAX(version), 0)").
+		Where("product_id = ?", productID).
+		Scan(&maxVersion).Error; err != nil {
+		return nil, err
+	}
+
+	draft := &RoutingVersion{
+		ProductID: productID,
+		Version:   maxVersion + 1,
+		Status:    VersionDraft,
+	}
+	if userID > 0 {
+		draft.CreatedBy = &userID
+	}
+	if err := tx.Create(draft).Error; err != nil {
+		return nil, err
+	}
+
+	released, err := versionByStatus(tx, productID, VersionReleased)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return draft, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	steps, err := loadSteps(tx, released.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	return draft, insertSteps(tx, draft, steps)
+}
+
+func insertSteps(tx *gorm.DB, v *RoutingVersion, steps []*ProductStage) error {
+	for _, step := range steps {
+		step.ID = 0
+		step.ProductID = v.ProductID
+		step.RoutingVersionID = v.ID
+		if err := tx.Omit("Stage").Create(step).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func versionByStatus(db *gorm.DB, productID int64, status string) (*RoutingVersion, error) {
+	var v RoutingVersion
+	err := db.
+		Where("product_id = ? AND status = ?", productID, status).
+		First(&v).
+		Error
+	if err != nil {
+		return nil, err
+	}
+	return &v, nil
+}
+
+func loadSteps(db *gorm.DB, versionID int64) ([]*ProductStage, error) {
+	var steps []*ProductStage
+	err := db.
+		Preload("Stage").
+		Where("routing_version_id = ?", versionID).
+		Order("stage_order").
+		Find(&steps).
+		Error
+	return steps, err
+}
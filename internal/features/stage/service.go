@@ -0,0 +1,426 @@
+package stage
+
+import (
+	"errors"
+	"slices"
+	"strings"
+
+	"mes-lite-back/pkg"
+
+	"gorm.io/gorm"
+)
+
+var (
+	ErrNotFound          = errors.New("stage not found")
+	ErrProductNotFound   = errors.New("product not found")
+	ErrNameEmpty         = errors.New("stage name required")
+	ErrInUse             = errors.New("stage is used in routings or executions")
+	ErrInvalidOrder      = errors.New("stage order must be contiguous starting from 1")
+	ErrDuplicateStage    = errors.New("stage appears in routing more than once")
+	ErrUnknownStage      = errors.New("routing references unknown stage")
+	ErrStageNotInRouting = errors.New("stage is not part of the routing")
+	ErrInvalidCycle      = errors.New("expected cycle time must be positive")
+	ErrVersionNotFound   = errors.New("routing version not found")
+	ErrNotDraft          = errors.New("routing version is not a draft")
+	ErrEmptyRouting      = errors.New("routing has no stages")
+	ErrSelfApproval      = errors.New("routing version cannot be released by its author")
+)
+
+// ServiceInterface определяет методы, используемые handler’ом
+type ServiceInterface interface {
+	CreateStage(s *Stage) error
+	GetStage(id int64) (*Stage, error)
+	ListStages() ([]*Stage, error)
+	UpdateStage(s *Stage) error
+	DeleteStage(id int64) error
+
+	GetRouting(productID int64, version int) (*Routing, error)
+	ReplaceRouting(productID, userID int64, steps []RoutingStep) (*Routing, error)
+	InsertStep(productID, userID int64, step RoutingStep) (*Routing, error)
+	RemoveStep(productID, userID, stageID int64) (*Routing, error)
+	ReorderSteps(productID, userID int64, stageIDs []int64) (*Routing, error)
+
+	ListVersions(productID int64) ([]*RoutingVersion, error)
+	ReleaseVersion(productID int64, version int, approverID int64) (*Routing, error)
+	DiscardDraft(productID int64, version int) error
+	DiffVersions(productID int64, from, to int) (*RoutingDiff, error)
+}
+
+type Service struct {
+	repo Repository
+}
+
+func NewService(repo Repository) *Service {
+	return &Service{repo: repo}
+}
+
+func (s *Service) CreateStage(st *Stage) error {
+	st.Name = strings.TrimSpace(st.Name)
+	if st.Name == "" {
+		return ErrNameEmpty
+	}
+	return s.repo.Create(st)
+}
+
+func (s *Service) GetStage(id int64) (*Stage, error) {
+	st, err := s.repo.GetByID(id)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrNotFound
+	}
+	return st, err
+}
+
+func (s *Service) ListStages() ([]*Stage, error) {
+	return s.repo.List()
+}
+
+func (s *Service) UpdateStage(st *Stage) error {
+	if _, err := s.GetStage(st.ID); err != nil {
+		return err
+	}
+	st.Name = strings.TrimSpace(st.Name)
+	if st.Name == "" {
+		return ErrNameEmpty
+	}
+	return s.repo.Update(st)
+}
+
+func (s *Service) DeleteStage(id int64) error {
+	st, err := s.GetStage(id)
+	if err != nil {
+		return err
+	}
+
+	used, err := s.repo.IsReferenced(id)
+	if err != nil {
+		return err
+	}
+	if used {
+		return ErrInUse
+	}
+
+	return s.repo.Delete(st)
+}
+
+// GetRouting возвращает указанную версию маршрута; version = 0 — действующую,
+// а если выпущенной версии нет, то черновик
+func (s *Service) GetRouting(productID int64, version int) (*Routing, error) {
+	if err := s.checkProduct(productID); err != nil {
+		return nil, err
+	}
+
+	v, err := s.findVersion(productID, version)
+	if errors.Is(err, ErrVersionNotFound) && version == 0 {
+		return buildRouting(productID, nil, nil), nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	steps, err := s.repo.GetVersionSteps(v.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	return buildRouting(productID, v, steps), nil
+}
+
+// ActiveVersion возвращает выпущенную версию маршрута, за которой закрепляется заказ
+func (s *Service) ActiveVersion(productID int64) (*RoutingVersion, error) {
+	v, err := s.repo.GetVersionByStatus(productID, VersionReleased)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrVersionNotFound
+	}
+	return v, err
+}
+
+func (s *Service) ListVersions(productID int64) ([]*RoutingVersion, error) {
+	if err := s.checkProduct(productID); err != nil {
+		return nil, err
+	}
+	return s.repo.ListVersions(productID)
+}
+
+// ReleaseVersion утверждает черновик: он становится действующим, прежняя версия — устаревшей.
+// Утверждающий не может быть автором или последним редактором черновика.
+func (s *Service) ReleaseVersion(productID int64, version int, approverID int64) (*Routing, error) {
+	rt, err := s.GetRouting(productID, version)
+	if err != nil {
+		return nil, err
+	}
+	if rt.Version == nil {
+		return nil, ErrVersionNotFound
+	}
+	if rt.Version.Status != VersionDraft {
+		return nil, ErrNotDraft
+	}
+	if len(rt.Steps) == 0 {
+		return nil, ErrEmptyRouting
+	}
+
+	if err := s.repo.ReleaseVersion(productID, version, approverID); err != nil {
+		return nil, err
+	}
+
+	return s.GetRouting(productID, version)
+}
+
+func (s *Service) DiscardDraft(productID int64, version int) error {
+	err := s.repo.DeleteDraft(productID, version)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return ErrVersionNotFound
+	}
+	return err
+}
+
+// DiffVersions сравнивает шаги двух версий маршрута по этапам
+func (s *Service) DiffVersions(productID int64, from, to int) (*RoutingDiff, error) {
+	fromRt, err := s.GetRouting(productID, from)
+	if err != nil {
+		return nil, err
+	}
+	toRt, err := s.GetRouting(productID, to)
+	if err != nil {
+		return nil, err
+	}
+
+	diff := &RoutingDiff{
+		ProductID:   productID,
+		FromVersion: from,
+		ToVersion:   to,
+		Added:       []*DiffStep{},
+		Removed:     []*DiffStep{},
+		Changed:     []*DiffChange{},
+	}
+
+	old := make(map[int64]*ProductStage, len(fromRt.Steps))
+	for _, st := range fromRt.Steps {
+		old[st.StageID] = st
+	}
+
+	for _, st := range toRt.Steps {
+		prev, ok := old[st.StageID]
+		if !ok {
+			diff.Added = append(diff.Added, diffStep(st))
+			continue
+		}
+		delete(old, st.StageID)
+
+		if prev.StageOrder != st.StageOrder || !pkg.EqualPtr(prev.ExpectedCycleMin, st.ExpectedCycleMin) {
+			diff.Changed = append(diff.Changed, &DiffChange{
+				StageID:     st.StageID,
+				StageName:   st.Stage.Name,
+				OldOrder:    prev.StageOrder,
+				NewOrder:    st.StageOrder,
+				OldCycleMin: prev.ExpectedCycleMin,
+				NewCycleMin: st.ExpectedCycleMin,
+			})
+		}
+	}
+
+	for _, st := range fromRt.Steps {
+		if _, ok := old[st.StageID]; ok {
+			diff.Removed = append(diff.Removed, diffStep(st))
+		}
+	}
+
+	return diff, nil
+}
+
+// ReplaceRouting полностью заменяет маршрут; порядок должен быть сплошным 1..N.
+// Как и остальные правки, применяется к черновику: действующая версия не меняется
+func (s *Service) ReplaceRouting(productID, userID int64, steps []RoutingStep) (*Routing, error) {
+	next := make([]*ProductStage, 0, len(steps))
+	for _, st := range steps {
+		next = append(next, &ProductStage{
+			StageID:          st.StageID,
+			StageOrder:       st.StageOrder,
+			ExpectedCycleMin: st.ExpectedCycleMin,
+		})
+	}
+	slices.SortFunc(next, func(a, b *ProductStage) int { return a.StageOrder - b.StageOrder })
+
+	if err := s.validateSteps(next); err != nil {
+		return nil, err
+	}
+
+	return s.mutate(productID, userID, func([]*ProductStage) ([]*ProductStage, error) {
+		return next, nil
+	})
+}
+
+// InsertStep вставляет этап на позицию StageOrder, сдвигая последующие;
+// позиция вне маршрута означает добавление в конец
+func (s *Service) InsertStep(productID, userID int64, step RoutingStep) (*Routing, error) {
+	if err := s.validateStages([]int64{step.StageID}); err != nil {
+		return nil, err
+	}
+	if step.ExpectedCycleMin != nil && *step.ExpectedCycleMin <= 0 {
+		return nil, ErrInvalidCycle
+	}
+
+	return s.mutate(productID, userID, func(current []*ProductStage) ([]*ProductStage, error) {
+		for _, ps := range current {
+			if ps.StageID == step.StageID {
+				return nil, ErrDuplicateStage
+			}
+		}
+
+		pos := step.StageOrder - 1
+		if pos < 0 || pos > len(current) {
+			pos = len(current)
+		}
+
+		return renumber(slices.Insert(current, pos, &ProductStage{
+			StageID:          step.StageID,
+			ExpectedCycleMin: step.ExpectedCycleMin,
+		})), nil
+	})
+}
+
+func (s *Service) RemoveStep(productID, userID, stageID int64) (*Routing, error) {
+	return s.mutate(productID, userID, func(current []*ProductStage) ([]*ProductStage, error) {
+		idx := slices.IndexFunc(current, func(ps *ProductStage) bool { return ps.StageID == stageID })
+		if idx < 0 {
+			return nil, ErrStageNotInRouting
+		}
+		return renumber(slices.Delete(current, idx, idx+1)), nil
+	})
+}
+
+// ReorderSteps переставляет этапы; stageIDs должен содержать ровно этапы текущего маршрута
+func (s *Service) ReorderSteps(productID, userID int64, stageIDs []int64) (*Routing, error) {
+	return s.mutate(productID, userID, func(current []*ProductStage) ([]*ProductStage, error) {
+		if len(stageIDs) != len(current) {
+			return nil, ErrStageNotInRouting
+		}
+
+		byStage := make(map[int64]*ProductStage, len(current))
+		for _, ps := range current {
+			byStage[ps.StageID] = ps
+		}
+
+		next := make([]*ProductStage, 0, len(stageIDs))
+		for _, id := range stageIDs {
+			ps, ok := byStage[id]
+			if !ok {
+				return nil, ErrStageNotInRouting
+			}
+			delete(byStage, id)
+			next = append(next, ps)
+		}
+
+		return renumber(next), nil
+	})
+}
+
+func (s *Service) mutate(productID, userID int64, fn RoutingMutator) (*Routing, error) {
+	if err := s.checkProduct(productID); err != nil {
+		return nil, err
+	}
+
+	draft, err := s.repo.UpdateDraft(productID, userID, fn)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.GetRouting(productID, draft.Version)
+}
+
+func (s *Service) findVersion(productID int64, version int) (*RoutingVersion, error) {
+	var (
+		v   *RoutingVersion
+		err error
+	)
+
+	if version > 0 {
+		v, err = s.repo.GetVersion(productID, version)
+	} else {
+		v, err = s.repo.GetVersionByStatus(productID, VersionReleased)
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			v, err = s.repo.GetVersionByStatus(productID, VersionDraft)
+		}
+	}
+
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrVersionNotFound
+	}
+	return v, err
+}
+
+func (s *Service) checkProduct(productID int64) error {
+	ok, err := s.repo.ProductExists(productID)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return ErrProductNotFound
+	}
+	return nil
+}
+
+// validateSteps проверяет отсортированные шаги: порядок 1..N без пропусков, этапы без повторов
+func (s *Service) validateSteps(steps []*ProductStage) error {
+	ids := make([]int64, 0, len(steps))
+	seen := make(map[int64]bool, len(steps))
+
+	for i, st := range steps {
+		if st.StageOrder != i+1 {
+			return ErrInvalidOrder
+		}
+		if seen[st.StageID] {
+			return ErrDuplicateStage
+		}
+		if st.ExpectedCycleMin != nil && *st.ExpectedCycleMin <= 0 {
+			return ErrInvalidCycle
+		}
+		seen[st.StageID] = true
+		ids = append(ids, st.StageID)
+	}
+
+	return s.validateStages(ids)
+}
+
+func (s *Service) validateStages(ids []int64) error {
+	if len(ids) == 0 {
+		return nil
+	}
+
+	stages, err := s.repo.GetByIDs(ids)
+	if err != nil {
+		return err
+	}
+	if len(stages) != len(ids) {
+		return ErrUnknownStage
+	}
+	return nil
+}
+
+func renumber(steps []*ProductStage) []*ProductStage {
+	for i, st := range steps {
+		st.StageOrder = i + 1
+	}
+	return steps
+}
+
+func buildRouting(productID int64, v *RoutingVersion, steps []*ProductStage) *Routing {
+	rt := &Routing{ProductID: productID, Version: v, Steps: steps}
+	if rt.Steps == nil {
+		rt.Steps = []*ProductStage{}
+	}
+	for _, st := range steps {
+		if st.ExpectedCycleMin != nil {
+			rt.TotalCycleMin += *st.ExpectedCycleMin
+		}
+	}
+	return rt
+}
+
+func diffStep(st *ProductStage) *DiffStep {
+	return &DiffStep{
+		StageID:          st.StageID,
+		StageName:        st.Stage.Name,
+		StageOrder:       st.StageOrder,
+		ExpectedCycleMin: st.ExpectedCycleMin,
+	}
+}
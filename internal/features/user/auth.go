@@ -37,13 +37,9 @@ func NewAuthService(
 }
 
 func (a *AuthService) Authenticate(username, password string) (string, string, *User, error) {
-	u, err := a.repo.GetByUsername(username)
-	if err != nil || u == nil {
-		return "", "", nil, ErrInvalidCreds
-	}
-
-	if bcrypt.CompareHashAndPassword([]byte(u.Password), []byte(password)) != nil {
-		return "", "", nil, ErrInvalidCreds
+	u, err := a.VerifyCredentials(username, password)
+	if err != nil {
+		return "", "", nil, err
 	}
 
 	access, err := a.newAccessToken(u)
@@ -59,6 +55,20 @@ func (a *AuthService) Authenticate(username, password string) (string, string, *
 	return access, refresh, u, nil
 }
 
+// VerifyCredentials проверяет логин и пароль без выдачи токенов (например, допуск мастера)
+func (a *AuthService) VerifyCredentials(username, password string) (*User, error) {
+	u, err := a.repo.GetByUsername(username)
+	if err != nil || u == nil {
+		return nil, ErrInvalidCreds
+	}
+
+	if bcrypt.CompareHashAndPassword([]byte(u.Password), []byte(password)) != nil {
+		return nil, ErrInvalidCreds
+	}
+
+	return u, nil
+}
+
 func (a *AuthService) Refresh(refreshToken string) (string, string, error) {
 	rt, err := a.rtRepo.Get(refreshToken)
 	if err != nil {
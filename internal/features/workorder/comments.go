@@ -0,0 +1,249 @@
+package workorder
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"mes-lite-back/internal/features/notification"
+
+	"gorm.io/gorm"
+)
+
+// Окна, в течение которых автор может изменить или удалить свой комментарий
+const (
+	CommentEditWindow   = 15 * time.Minute
+	CommentDeleteWindow = 5 * time.Minute
+)
+
+// maxCommentLength ограничение длины комментария в символах
+const maxCommentLength = 4000
+
+var (
+	ErrCommentNotFound  = errors.New("comment not found")
+	ErrCommentEmpty     = errors.New("comment message required")
+	ErrCommentTooLong   = errors.New("comment message is too long")
+	ErrNotCommentAuthor = errors.New("only the author can change the comment")
+	ErrEditWindowPassed = errors.New("comment edit window has passed")
+)
+
+// mentionPattern упоминание @username в начале текста или после пробела/знака препинания
+var mentionPattern = regexp.MustCompile(`(?:^|[^\w@])@([A-Za-z0-9][A-Za-z0-9._-]*)`)
+
+// Notifier рассылка уведомлений об упоминаниях
+type Notifier interface {
+	Notify(userIDs []int64, msg notification.Message) error
+}
+
+func (s *Service) ListComments(workOrderID int64) ([]*Comment, error) {
+	if _, err := s.GetWorkOrder(workOrderID); err != nil {
+		return nil, err
+	}
+	return s.repo.ListComments(workOrderID)
+}
+
+// AddComment добавляет комментарий и уведомляет упомянутых пользователей
+func (s *Service) AddComment(workOrderID, userID int64, message string) (*Comment, error) {
+	wo, err := s.GetWorkOrder(workOrderID)
+	if err != nil {
+		return nil, err
+	}
+
+	message, err = normalizeMessage(message)
+	if err != nil {
+		return nil, err
+	}
+
+	c := &Comment{
+		WorkOrderID: workOrderID,
+		UserID:      userID,
+		Message:     message,
+	}
+	if err := s.repo.CreateComment(c); err != nil {
+		return nil, err
+	}
+
+	s.notifyMentions(wo, c, mentions(message))
+	return c, nil
+}
+
+// EditComment меняет текст в пределах CommentEditWindow; уведомляются только новые упоминания
+func (s *Service) EditComment(workOrderID, commentID, userID int64, message string) (*Comment, error) {
+	wo, err := s.GetWorkOrder(workOrderID)
+	if err != nil {
+		return nil, err
+	}
+
+	c, err := s.comment(workOrderID, commentID)
+	if err != nil {
+		return nil, err
+	}
+	if c.UserID != userID {
+		return nil, ErrNotCommentAuthor
+	}
+
+	now := s.now()
+	if now.Sub(c.CreatedAt) > CommentEditWindow {
+		return nil, ErrEditWindowPassed
+	}
+
+	message, err = normalizeMessage(message)
+	if err != nil {
+		return nil, err
+	}
+
+	before := mentions(c.Message)
+	c.Message = message
+	c.EditedAt = &now
+
+	if err := s.repo.UpdateComment(c); err != nil {
+		return nil, err
+	}
+
+	var added []string
+	for _, name := range mentions(message) {
+		if !slices.Contains(before, name) {
+			added = append(added, name)
+		}
+	}
+	s.notifyMentions(wo, c, added)
+
+	return c, nil
+}
+
+// DeleteComment удаляет комментарий: автор — в пределах CommentDeleteWindow,
+// пользователь с правом order.delete — в любое время
+func (s *Service) DeleteComment(workOrderID, commentID, userID int64) error {
+	c, err := s.comment(workOrderID, commentID)
+	if err != nil {
+		return err
+	}
+
+	moderator, err := s.perms.HasPermission(userID, "order.delete")
+	if err != nil {
+		return err
+	}
+
+	if !moderator {
+		if c.UserID != userID {
+			return ErrNotCommentAuthor
+		}
+		if s.now().Sub(c.CreatedAt) > CommentDeleteWindow {
+			return ErrEditWindowPassed
+		}
+	}
+
+	return s.repo.DeleteComment(c)
+}
+
+// Activity лента заказа: история статусов и комментарии в хронологическом порядке
+func (s *Service) Activity(workOrderID int64) ([]*ActivityEvent, error) {
+	if _, err := s.GetWorkOrder(workOrderID); err != nil {
+		return nil, err
+	}
+
+	history, err := s.repo.ListHistory(workOrderID)
+	if err != nil {
+		return nil, err
+	}
+	comments, err := s.repo.ListComments(workOrderID)
+	if err != nil {
+		return nil, err
+	}
+
+	events := make([]*ActivityEvent, 0, len(history)+len(comments))
+	for _, h := range history {
+		events = append(events, &ActivityEvent{
+			Type:        EventStatus,
+			At:          h.ChangedAt,
+			UserID:      h.ChangedBy,
+			Action:      h.Action,
+			OldStatusID: h.OldStatusID,
+			NewStatusID: h.NewStatusID,
+			Message:     h.Comment,
+		})
+	}
+	for _, c := range comments {
+		events = append(events, &ActivityEvent{
+			Type:      EventComment,
+			At:        c.CreatedAt,
+			UserID:    &c.UserID,
+			CommentID: c.ID,
+			Message:   c.Message,
+			Edited:    c.EditedAt != nil,
+		})
+	}
+
+	sort.SliceStable(events, func(i, j int) bool { return events[i].At.Before(events[j].At) })
+	return events, nil
+}
+
+func (s *Service) comment(workOrderID, commentID int64) (*Comment, error) {
+	c, err := s.repo.GetComment(workOrderID, commentID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrCommentNotFound
+	}
+	return c, err
+}
+
+// notifyMentions уведомляет упомянутых пользователей, кроме автора.
+// Ошибка рассылки не отменяет сохраненный комментарий и только логируется.
+func (s *Service) notifyMentions(wo *WorkOrder, c *Comment, usernames []string) {
+	if len(usernames) == 0 || s.notifier == nil {
+		return
+	}
+
+	ids, err := s.repo.FindUserIDs(usernames)
+	if err != nil {
+		slog.Error("resolve mentions failed", slog.Int64("work_order_id", wo.ID), slog.Any("err", err))
+		return
+	}
+
+	var recipients []int64
+	for _, id := range ids {
+		if id != c.UserID {
+			recipients = append(recipients, id)
+		}
+	}
+
+	err = s.notifier.Notify(recipients, notification.Message{
+		Kind:       notification.KindMention,
+		Title:      fmt.Sprintf("Вас упомянули в заказе %s", wo.WONumber),
+		Body:       c.Message,
+		EntityType: "work_order",
+		EntityID:   wo.ID,
+		CreatedBy:  &c.UserID,
+	})
+	if err != nil {
+		slog.Error("mention notification failed", slog.Int64("work_order_id", wo.ID), slog.Any("err", err))
+	}
+}
+
+func normalizeMessage(message string) (string, error) {
+	message = strings.TrimSpace(message)
+	if message == "" {
+		return "", ErrCommentEmpty
+	}
+	if len([]rune(message)) > maxCommentLength {
+		return "", ErrCommentTooLong
+	}
+	return message, nil
+}
+
+// mentions имена пользователей из @упоминаний в нижнем регистре без повторов;
+// точка в конце имени считается концом предложения
+func mentions(message string) []string {
+	var names []string
+	for _, m := range mentionPattern.FindAllStringSubmatch(message, -1) {
+		name := strings.ToLower(strings.TrimRight(m[1], "."))
+		if name != "" && !slices.Contains(names, name) {
+			names = append(names, name)
+		}
+	}
+	return names
+}
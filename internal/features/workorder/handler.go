@@ -0,0 +1,793 @@
+package workorder
+
+import (
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"time"
+
+	"mes-lite-back/internal/http/middleware"
+	"mes-lite-back/pkg"
+
+	"github.com/go-chi/chi/v5"
+)
+
+type Handler struct {
+	service ServiceInterface
+	perms   middleware.PermissionChecker
+}
+
+func NewHandler(service ServiceInterface, perms middleware.PermissionChecker) *Handler {
+	return &Handler{
+		service: service,
+		perms:   perms,
+	}
+}
+
+func (h *Handler) Routes() chi.Router {
+	r := chi.NewRouter()
+
+	view := middleware.PermissionGuard(h.perms, "order.view")
+	create := middleware.PermissionGuard(h.perms, "order.create")
+	edit := middleware.PermissionGuard(h.perms, "order.edit")
+	del := middleware.PermissionGuard(h.perms, "order.delete")
+	comment := middleware.PermissionGuard(h.perms, "order.comment")
+
+	r.With(view).Get("/", h.list)
+	r.With(view).Get("/priorities", h.priorities)
+	r.With(view).Get("/statuses", h.statuses)
+	r.With(view).Get("/workflow", h.workflow)
+	r.With(view).Get("/{id}", h.getByID)
+	r.With(view).Get("/{id}/history", h.history)
+	r.With(view).Get("/{id}/activity", h.activity)
+	r.With(view).Get("/{id}/progress", h.progress)
+	r.With(view).Get("/{id}/yield", h.yield)
+	r.With(view).Get("/{id}/comments", h.listComments)
+	r.With(edit).Post("/{id}/split", h.split)
+	r.With(edit).Post("/{id}/merge", h.merge)
+	r.With(middleware.PermissionGuard(h.perms, "production.complete")).Post("/{id}/complete-partial", h.completePartial)
+	r.With(comment).Post("/{id}/comments", h.addComment)
+	r.With(comment).Put("/{id}/comments/{commentID}", h.editComment)
+	r.With(comment).Delete("/{id}/comments/{commentID}", h.deleteComment)
+	r.With(view).Get("/{id}/transitions", h.availableActions)
+	// право на конкретный переход проверяется по workflow
+	r.With(view).Post("/{id}/transitions", h.changeStatus)
+	r.With(create).Post("/", h.create)
+	r.With(edit).Put("/{id}", h.update)
+	r.With(del).Delete("/{id}", h.delete)
+
+	return r
+}
+
+type CreateRequest struct {
+	// WONumber необязателен: пустой номер генерируется по шаблону
+	WONumber   string `json:"wo_number,omitempty" example:"WO-2026-000123"`
+	ProductID  int64  `json:"product_id" example:"1"`
+	MachineID  *int64 `json:"machine_id,omitempty" example:"2"`
+	Quantity   int    `json:"quantity" example:"100"`
+	PriorityID int    `json:"priority_id,omitempty" example:"2"`
+	Deadline   string `json:"deadline,omitempty" example:"2026-11-30"`
+}
+
+type UpdateRequest struct {
+	ProductID  int64  `json:"product_id" example:"1"`
+	MachineID  *int64 `json:"machine_id,omitempty" example:"2"`
+	Quantity   int    `json:"quantity" example:"120"`
+	PriorityID int    `json:"priority_id,omitempty" example:"3"`
+	Deadline   string `json:"deadline,omitempty" example:"2026-12-15"`
+}
+
+type ErrorResponse struct {
+	Error string `json:"error" example:"Описание ошибки"`
+}
+
+type CommentRequest struct {
+	Message string `json:"message" example:"@ivanov проверь оснастку перед запуском"`
+}
+
+// TransitionErrorResponse отказ в переходе статуса
+type TransitionErrorResponse struct {
+	Error string `json:"error" example:"Переход недопустим из текущего статуса"`
+	// Code invalid_transition — действие недопустимо из статуса, guard_failed — не выполнено условие
+	Code           string   `json:"code" example:"invalid_transition"`
+	Action         string   `json:"action" example:"complete"`
+	StatusID       int      `json:"status_id,omitempty" example:"2"`
+	AllowedActions []string `json:"allowed_actions,omitempty" example:"start,hold,cancel"`
+	Guard          string   `json:"guard,omitempty" example:"no_open_executions"`
+}
+
+// ListWorkOrders godoc
+// @Summary Получить список заказов
+// @Description Возвращает заказы с фильтрами по статусу, приоритету, станку, продукту и сроку
+// @Tags work-orders
+// @Security BearerAuth
+// @Produce json
+// @Param q query string false "Поиск по номеру заказа"
+// @Param status_id query int false "ID статуса"
+// @Param priority_id query int false "ID приоритета"
+// @Param machine_id query int false "ID станка"
+// @Param product_id query int false "ID продукта"
+// @Param deadline_from query string false "Срок не раньше (ГГГГ-ММ-ДД)"
+// @Param deadline_to query string false "Срок не позже (ГГГГ-ММ-ДД)"
+// @Param limit query int false "Количество записей"
+// @Param offset query int false "Смещение"
+// @Success 200 {array} WorkOrder
+// @Failure 400 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /work-orders [get]
+func (h *Handler) list(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	statusID, _ := strconv.Atoi(query.Get("status_id"))
+	priorityID, _ := strconv.Atoi(query.Get("priority_id"))
+	machineID, _ := strconv.ParseInt(query.Get("machine_id"), 10, 64)
+	productID, _ := strconv.ParseInt(query.Get("product_id"), 10, 64)
+	limit, _ := strconv.Atoi(query.Get("limit"))
+	offset, _ := strconv.Atoi(query.Get("offset"))
+
+	from, ok := parseDate(w, query.Get("deadline_from"))
+	if !ok {
+		return
+	}
+	to, ok := parseDate(w, query.Get("deadline_to"))
+	if !ok {
+		return
+	}
+
+	orders, err := h.service.ListWorkOrders(ListFilter{
+		Query:        query.Get("q"),
+		StatusID:     statusID,
+		PriorityID:   priorityID,
+		MachineID:    machineID,
+		ProductID:    productID,
+		DeadlineFrom: from,
+		DeadlineTo:   to,
+		Limit:        limit,
+		Offset:       offset,
+	})
+	if err != nil {
+		h.respondError(w, err)
+		return
+	}
+
+	pkg.RespondJSON(w, http.StatusOK, orders)
+}
+
+// GetWorkOrder godoc
+// @Summary Получить заказ по ID
+// @Tags work-orders
+// @Security BearerAuth
+// @Produce json
+// @Param id path int true "ID заказа"
+// @Success 200 {object} WorkOrder
+// @Failure 400 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /work-orders/{id} [get]
+func (h *Handler) getByID(w http.ResponseWriter, r *http.Request) {
+	id := pkg.ParamID(r)
+	if id == 0 {
+		pkg.RespondJSON(w, http.StatusBadRequest, ErrorResponse{Error: "Некорректный ID заказа"})
+		return
+	}
+
+	wo, err := h.service.GetWorkOrder(id)
+	if err != nil {
+		h.respondError(w, err)
+		return
+	}
+
+	pkg.RespondJSON(w, http.StatusOK, wo)
+}
+
+// CreateWorkOrder godoc
+// @Summary Создать заказ
+// @Description Создает заказ в статусе «Запланирован»; без wo_number номер генерируется по шаблону
+// @Tags work-orders
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param request body CreateRequest true "Данные заказа"
+// @Success 201 {object} WorkOrder
+// @Failure 400 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /work-orders [post]
+func (h *Handler) create(w http.ResponseWriter, r *http.Request) {
+	var req CreateRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		pkg.RespondJSON(w, http.StatusBadRequest, ErrorResponse{Error: "Некорректный JSON"})
+		return
+	}
+
+	deadline, ok := parseDate(w, req.Deadline)
+	if !ok {
+		return
+	}
+
+	wo := &WorkOrder{
+		WONumber:   req.WONumber,
+		ProductID:  req.ProductID,
+		MachineID:  req.MachineID,
+		Quantity:   req.Quantity,
+		PriorityID: req.PriorityID,
+		Deadline:   deadline,
+	}
+	if userID, ok := middleware.UserIDFromContext(r.Context()); ok {
+		wo.CreatedBy = &userID
+	}
+
+	if err := h.service.CreateWorkOrder(wo); err != nil {
+		h.respondError(w, err)
+		return
+	}
+
+	pkg.RespondJSON(w, http.StatusCreated, wo)
+}
+
+// UpdateWorkOrder godoc
+// @Summary Обновить заказ
+// @Description Обновляет продукт, станок, количество, приоритет и срок; статус меняется отдельно
+// @Tags work-orders
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param id path int true "ID заказа"
+// @Param request body UpdateRequest true "Данные заказа"
+// @Success 200 {object} WorkOrder
+// @Failure 400 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /work-orders/{id} [put]
+func (h *Handler) update(w http.ResponseWriter, r *http.Request) {
+	id := pkg.ParamID(r)
+	if id == 0 {
+		pkg.RespondJSON(w, http.StatusBadRequest, ErrorResponse{Error: "Некорректный ID заказа"})
+		return
+	}
+
+	var req UpdateRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		pkg.RespondJSON(w, http.StatusBadRequest, ErrorResponse{Error: "Некорректный JSON"})
+		return
+	}
+
+	deadline, ok := parseDate(w, req.Deadline)
+	if !ok {
+		return
+	}
+
+	wo := &WorkOrder{
+		ID:         id,
+		ProductID:  req.ProductID,
+		MachineID:  req.MachineID,
+		Quantity:   req.Quantity,
+		PriorityID: req.PriorityID,
+		Deadline:   deadline,
+	}
+
+	if err := h.service.UpdateWorkOrder(wo); err != nil {
+		h.respondError(w, err)
+		return
+	}
+
+	pkg.RespondJSON(w, http.StatusOK, wo)
+}
+
+// DeleteWorkOrder godoc
+// @Summary Удалить заказ
+// @Description Удаляет запланированный или отмененный заказ без экземпляров, расписания и инцидентов
+// @Tags work-orders
+// @Security BearerAuth
+// @Param id path int true "ID заказа"
+// @Success 204
+// @Failure 400 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /work-orders/{id} [delete]
+func (h *Handler) delete(w http.ResponseWriter, r *http.Request) {
+	id := pkg.ParamID(r)
+	if id == 0 {
+		pkg.RespondJSON(w, http.StatusBadRequest, ErrorResponse{Error: "Некорректный ID заказа"})
+		return
+	}
+
+	if err := h.service.DeleteWorkOrder(id); err != nil {
+		h.respondError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// ListPriorities godoc
+// @Summary Справочник приоритетов заказов
+// @Tags work-orders
+// @Security BearerAuth
+// @Produce json
+// @Success 200 {array} Priority
+// @Failure 500 {object} ErrorResponse
+// @Router /work-orders/priorities [get]
+func (h *Handler) priorities(w http.ResponseWriter, r *http.Request) {
+	priorities, err := h.service.ListPriorities()
+	if err != nil {
+		h.respondError(w, err)
+		return
+	}
+	pkg.RespondJSON(w, http.StatusOK, priorities)
+}
+
+// ListStatuses godoc
+// @Summary Справочник статусов заказов
+// @Tags work-orders
+// @Security BearerAuth
+// @Produce json
+// @Success 200 {array} Status
+// @Failure 500 {object} ErrorResponse
+// @Router /work-orders/statuses [get]
+func (h *Handler) statuses(w http.ResponseWriter, r *http.Request) {
+	statuses, err := h.service.ListStatuses()
+	if err != nil {
+		h.respondError(w, err)
+		return
+	}
+	pkg.RespondJSON(w, http.StatusOK, statuses)
+}
+
+// ChangeWorkOrderStatus godoc
+// @Summary Изменить статус заказа
+// @Description Выполняет действие workflow: release, start, hold, resume, complete, cancel.
+// @Description Каждое действие требует своего права (order.status, production.start, production.complete)
+// @Tags work-orders
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param id path int true "ID заказа"
+// @Param request body TransitionRequest true "Действие"
+// @Success 200 {object} WorkOrder
+// @Failure 400 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 409 {object} TransitionErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /work-orders/{id}/transitions [post]
+func (h *Handler) changeStatus(w http.ResponseWriter, r *http.Request) {
+	id := pkg.ParamID(r)
+	if id == 0 {
+		pkg.RespondJSON(w, http.StatusBadRequest, ErrorResponse{Error: "Некорректный ID заказа"})
+		return
+	}
+
+	var req TransitionRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		pkg.RespondJSON(w, http.StatusBadRequest, ErrorResponse{Error: "Некорректный JSON"})
+		return
+	}
+
+	wo, err := h.service.ChangeStatus(id, req, currentUser(r))
+	if err != nil {
+		h.respondError(w, err)
+		return
+	}
+
+	pkg.RespondJSON(w, http.StatusOK, wo)
+}
+
+// ListWorkOrderActions godoc
+// @Summary Доступные действия над статусом заказа
+// @Description Возвращает действия, допустимые из текущего статуса, с отметкой о правах пользователя
+// @Tags work-orders
+// @Security BearerAuth
+// @Produce json
+// @Param id path int true "ID заказа"
+// @Success 200 {array} AvailableAction
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /work-orders/{id}/transitions [get]
+func (h *Handler) availableActions(w http.ResponseWriter, r *http.Request) {
+	actions, err := h.service.AvailableActions(pkg.ParamID(r), currentUser(r))
+	if err != nil {
+		h.respondError(w, err)
+		return
+	}
+	pkg.RespondJSON(w, http.StatusOK, actions)
+}
+
+// WorkOrderStatusHistory godoc
+// @Summary История статусов заказа
+// @Tags work-orders
+// @Security BearerAuth
+// @Produce json
+// @Param id path int true "ID заказа"
+// @Success 200 {array} StatusHistory
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /work-orders/{id}/history [get]
+func (h *Handler) history(w http.ResponseWriter, r *http.Request) {
+	history, err := h.service.StatusHistory(pkg.ParamID(r))
+	if err != nil {
+		h.respondError(w, err)
+		return
+	}
+	pkg.RespondJSON(w, http.StatusOK, history)
+}
+
+// GetWorkOrderWorkflow godoc
+// @Summary Схема статусов заказа
+// @Description Переходы workflow с требуемыми правами и условиями; to = 0 — возврат в статус до приостановки
+// @Tags work-orders
+// @Security BearerAuth
+// @Produce json
+// @Success 200 {array} Transition
+// @Router /work-orders/workflow [get]
+func (h *Handler) workflow(w http.ResponseWriter, r *http.Request) {
+	pkg.RespondJSON(w, http.StatusOK, Workflow)
+}
+
+// ListWorkOrderComments godoc
+// @Summary Комментарии к заказу
+// @Tags work-orders
+// @Security BearerAuth
+// @Produce json
+// @Param id path int true "ID заказа"
+// @Success 200 {array} Comment
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /work-orders/{id}/comments [get]
+func (h *Handler) listComments(w http.ResponseWriter, r *http.Request) {
+	comments, err := h.service.ListComments(pkg.ParamID(r))
+	if err != nil {
+		h.respondError(w, err)
+		return
+	}
+	pkg.RespondJSON(w, http.StatusOK, comments)
+}
+
+// AddWorkOrderComment godoc
+// @Summary Добавить комментарий к заказу
+// @Description Упомянутые через @username пользователи получают уведомление
+// @Tags work-orders
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param id path int true "ID заказа"
+// @Param request body CommentRequest true "Текст комментария"
+// @Success 201 {object} Comment
+// @Failure 400 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /work-orders/{id}/comments [post]
+func (h *Handler) addComment(w http.ResponseWriter, r *http.Request) {
+	var req CommentRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		pkg.RespondJSON(w, http.StatusBadRequest, ErrorResponse{Error: "Некорректный JSON"})
+		return
+	}
+
+	c, err := h.service.AddComment(pkg.ParamID(r), currentUser(r), req.Message)
+	if err != nil {
+		h.respondError(w, err)
+		return
+	}
+	pkg.RespondJSON(w, http.StatusCreated, c)
+}
+
+// EditWorkOrderComment godoc
+// @Summary Изменить комментарий
+// @Description Автор может изменить комментарий в течение 15 минут после создания
+// @Tags work-orders
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param id path int true "ID заказа"
+// @Param commentID path int true "ID комментария"
+// @Param request body CommentRequest true "Текст комментария"
+// @Success 200 {object} Comment
+// @Failure 400 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse
+// @Router /work-orders/{id}/comments/{commentID} [put]
+func (h *Handler) editComment(w http.ResponseWriter, r *http.Request) {
+	var req CommentRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		pkg.RespondJSON(w, http.StatusBadRequest, ErrorResponse{Error: "Некорректный JSON"})
+		return
+	}
+
+	c, err := h.service.EditComment(
+		pkg.ParamID(r),
+		pkg.ParamInt64(r, "commentID"),
+		currentUser(r),
+		req.Message,
+	)
+	if err != nil {
+		h.respondError(w, err)
+		return
+	}
+	pkg.RespondJSON(w, http.StatusOK, c)
+}
+
+// DeleteWorkOrderComment godoc
+// @Summary Удалить комментарий
+// @Description Автор может удалить комментарий в течение 5 минут; с правом order.delete — в любое время
+// @Tags work-orders
+// @Security BearerAuth
+// @Param id path int true "ID заказа"
+// @Param commentID path int true "ID комментария"
+// @Success 204
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse
+// @Router /work-orders/{id}/comments/{commentID} [delete]
+func (h *Handler) deleteComment(w http.ResponseWriter, r *http.Request) {
+	err := h.service.DeleteComment(pkg.ParamID(r), pkg.ParamInt64(r, "commentID"), currentUser(r))
+	if err != nil {
+		h.respondError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// WorkOrderActivity godoc
+// @Summary Лента активности заказа
+// @Description Смены статусов и комментарии в хронологическом порядке
+// @Tags work-orders
+// @Security BearerAuth
+// @Produce json
+// @Param id path int true "ID заказа"
+// @Success 200 {array} ActivityEvent
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /work-orders/{id}/activity [get]
+func (h *Handler) activity(w http.ResponseWriter, r *http.Request) {
+	events, err := h.service.Activity(pkg.ParamID(r))
+	if err != nil {
+		h.respondError(w, err)
+		return
+	}
+	pkg.RespondJSON(w, http.StatusOK, events)
+}
+
+// WorkOrderProgress godoc
+// @Summary Ход выполнения заказа
+// @Description Годные, брак и экземпляры в работе по этапам маршрута, прогноз завершения и признак опоздания к сроку.
+// @Description Время цикла этапа: медиана фактических выполнений, норматив этапа или такт продукта.
+// @Tags work-orders
+// @Security BearerAuth
+// @Produce json
+// @Param id path int true "ID заказа"
+// @Success 200 {object} Progress
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /work-orders/{id}/progress [get]
+func (h *Handler) progress(w http.ResponseWriter, r *http.Request) {
+	p, err := h.service.Progress(pkg.ParamID(r))
+	if err != nil {
+		h.respondError(w, err)
+		return
+	}
+	pkg.RespondJSON(w, http.StatusOK, p)
+}
+
+// WorkOrderYield godoc
+// @Summary Выход годного заказа
+// @Description Выход годного с первого предъявления (FPY), число доработок и брак по причинам списания
+// @Tags work-orders
+// @Security BearerAuth
+// @Produce json
+// @Param id path int true "ID заказа"
+// @Success 200 {object} Yield
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /work-orders/{id}/yield [get]
+func (h *Handler) yield(w http.ResponseWriter, r *http.Request) {
+	y, err := h.service.Yield(pkg.ParamID(r))
+	if err != nil {
+		h.respondError(w, err)
+		return
+	}
+	pkg.RespondJSON(w, http.StatusOK, y)
+}
+
+// SplitWorkOrder godoc
+// @Summary Разделить заказ
+// @Description Выделяет часть количества в дочерний заказ; выпущенные экземпляры остаются в исходном
+// @Tags work-orders
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param id path int true "ID заказа"
+// @Param request body SplitRequest true "Количество для выделения"
+// @Success 201 {object} SplitResult
+// @Failure 400 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse
+// @Router /work-orders/{id}/split [post]
+func (h *Handler) split(w http.ResponseWriter, r *http.Request) {
+	var req SplitRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		pkg.RespondJSON(w, http.StatusBadRequest, ErrorResponse{Error: "Некорректный JSON"})
+		return
+	}
+
+	res, err := h.service.Split(pkg.ParamID(r), req, currentUser(r))
+	if err != nil {
+		h.respondError(w, err)
+		return
+	}
+	pkg.RespondJSON(w, http.StatusCreated, res)
+}
+
+// MergeWorkOrders godoc
+// @Summary Объединить заказы
+// @Description Вливает заказы того же продукта и версии маршрута в текущий; источники отменяются
+// @Tags work-orders
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param id path int true "ID целевого заказа"
+// @Param request body MergeRequest true "Заказы-источники"
+// @Success 200 {object} WorkOrder
+// @Failure 400 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse
+// @Router /work-orders/{id}/merge [post]
+func (h *Handler) merge(w http.ResponseWriter, r *http.Request) {
+	var req MergeRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		pkg.RespondJSON(w, http.StatusBadRequest, ErrorResponse{Error: "Некорректный JSON"})
+		return
+	}
+
+	wo, err := h.service.Merge(pkg.ParamID(r), req, currentUser(r))
+	if err != nil {
+		h.respondError(w, err)
+		return
+	}
+	pkg.RespondJSON(w, http.StatusOK, wo)
+}
+
+// CompleteWorkOrderPartially godoc
+// @Summary Частично завершить заказ
+// @Description Закрывает заказ в работе по количеству годных; с backorder остаток выделяется в новый заказ
+// @Tags work-orders
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param id path int true "ID заказа"
+// @Param request body PartialCompleteRequest true "Параметры завершения"
+// @Success 200 {object} SplitResult
+// @Failure 404 {object} ErrorResponse
+// @Failure 409 {object} TransitionErrorResponse
+// @Router /work-orders/{id}/complete-partial [post]
+func (h *Handler) completePartial(w http.ResponseWriter, r *http.Request) {
+	var req PartialCompleteRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		pkg.RespondJSON(w, http.StatusBadRequest, ErrorResponse{Error: "Некорректный JSON"})
+		return
+	}
+
+	res, err := h.service.CompletePartial(pkg.ParamID(r), req, currentUser(r))
+	if err != nil {
+		h.respondError(w, err)
+		return
+	}
+	pkg.RespondJSON(w, http.StatusOK, res)
+}
+
+func currentUser(r *http.Request) int64 {
+	id, _ := middleware.UserIDFromContext(r.Context())
+	return id
+}
+
+// parseDate разбирает дату вида 2006-01-02; пустая строка — без даты
+func parseDate(w http.ResponseWriter, value string) (*time.Time, bool) {
+	if value == "" {
+		return nil, true
+	}
+	t, err := time.Parse(time.DateOnly, value)
+	if err != nil {
+		pkg.RespondJSON(w, http.StatusBadRequest, ErrorResponse{Error: "Дата должна быть в формате ГГГГ-ММ-ДД"})
+		return nil, false
+	}
+	return &t, true
+}
+
+func (h *Handler) respondError(w http.ResponseWriter, err error) {
+	var (
+		trErr    *TransitionError
+		guardErr *GuardError
+	)
+
+	switch {
+	case errors.As(err, &trErr):
+		pkg.RespondJSON(w, http.StatusConflict, TransitionErrorResponse{
+			Error:          "Переход недопустим из текущего статуса",
+			Code:           "invalid_transition",
+			Action:         trErr.Action,
+			StatusID:       trErr.StatusID,
+			AllowedActions: trErr.Allowed,
+		})
+	case errors.As(err, &guardErr):
+		pkg.RespondJSON(w, http.StatusConflict, TransitionErrorResponse{
+			Error:  guardMessage(guardErr.Reason),
+			Code:   "guard_failed",
+			Action: guardErr.Action,
+			Guard:  guardErr.Guard,
+		})
+	case errors.Is(err, ErrUnknownAction):
+		pkg.RespondJSON(w, http.StatusBadRequest, ErrorResponse{Error: "Неизвестное действие над статусом"})
+	case errors.Is(err, ErrForbidden):
+		pkg.RespondJSON(w, http.StatusForbidden, ErrorResponse{Error: "Недостаточно прав для этого действия"})
+	case errors.Is(err, ErrNotFound):
+		pkg.RespondJSON(w, http.StatusNotFound, ErrorResponse{Error: "Заказ не найден"})
+	case errors.Is(err, ErrInvalidSplit):
+		pkg.RespondJSON(w, http.StatusBadRequest, ErrorResponse{Error: "Выделяемое количество должно быть больше нуля и меньше количества заказа"})
+	case errors.Is(err, ErrNoSources):
+		pkg.RespondJSON(w, http.StatusBadRequest, ErrorResponse{Error: "Укажите заказы для объединения"})
+	case errors.Is(err, ErrNotSplittable):
+		pkg.RespondJSON(w, http.StatusConflict, ErrorResponse{Error: "Разделить можно только незакрытый заказ"})
+	case errors.Is(err, ErrNotMergeable):
+		pkg.RespondJSON(w, http.StatusConflict, ErrorResponse{Error: "Статус заказа не позволяет объединение"})
+	case errors.Is(err, ErrIncompatible):
+		pkg.RespondJSON(w, http.StatusConflict, ErrorResponse{Error: "Заказы различаются продуктом или версией маршрута"})
+	case errors.Is(err, ErrNothingCompleted):
+		pkg.RespondJSON(w, http.StatusConflict, ErrorResponse{Error: "По заказу еще нет годных изделий"})
+	case errors.Is(err, ErrFullyCompleted):
+		pkg.RespondJSON(w, http.StatusConflict, ErrorResponse{Error: "Заказ выполнен полностью, используйте обычное завершение"})
+	case errors.Is(err, ErrCommentNotFound):
+		pkg.RespondJSON(w, http.StatusNotFound, ErrorResponse{Error: "Комментарий не найден"})
+	case errors.Is(err, ErrCommentEmpty):
+		pkg.RespondJSON(w, http.StatusBadRequest, ErrorResponse{Error: "Текст комментария обязателен"})
+	case errors.Is(err, ErrCommentTooLong):
+		pkg.RespondJSON(w, http.StatusBadRequest, ErrorResponse{Error: "Комментарий слишком длинный"})
+	case errors.Is(err, ErrNotCommentAuthor):
+		pkg.RespondJSON(w, http.StatusForbidden, ErrorResponse{Error: "Изменять комментарий может только его автор"})
+	case errors.Is(err, ErrEditWindowPassed):
+		pkg.RespondJSON(w, http.StatusConflict, ErrorResponse{Error: "Время на изменение комментария истекло"})
+	case errors.Is(err, ErrProductNotFound):
+		pkg.RespondJSON(w, http.StatusBadRequest, ErrorResponse{Error: "Продукт не найден"})
+	case errors.Is(err, ErrMachineNotFound):
+		pkg.RespondJSON(w, http.StatusBadRequest, ErrorResponse{Error: "Станок не найден"})
+	case errors.Is(err, ErrInvalidPriority):
+		pkg.RespondJSON(w, http.StatusBadRequest, ErrorResponse{Error: "Неизвестный приоритет"})
+	case errors.Is(err, ErrInvalidQuantity):
+		pkg.RespondJSON(w, http.StatusBadRequest, ErrorResponse{Error: "Количество должно быть положительным"})
+	case errors.Is(err, ErrInvalidDateRange):
+		pkg.RespondJSON(w, http.StatusBadRequest, ErrorResponse{Error: "Начало периода позже его окончания"})
+	case errors.Is(err, ErrQuantityBelow):
+		pkg.RespondJSON(w, http.StatusConflict, ErrorResponse{Error: "Количество меньше уже выпущенных экземпляров"})
+	case errors.Is(err, ErrNumberTaken):
+		pkg.RespondJSON(w, http.StatusConflict, ErrorResponse{Error: "Заказ с таким номером уже существует"})
+	case errors.Is(err, ErrClosed):
+		pkg.RespondJSON(w, http.StatusConflict, ErrorResponse{Error: "Завершенный или отмененный заказ нельзя изменить"})
+	case errors.Is(err, ErrProductLocked):
+		pkg.RespondJSON(w, http.StatusConflict, ErrorResponse{Error: "Продукт можно сменить только у запланированного заказа"})
+	case errors.Is(err, ErrNotDeletable):
+		pkg.RespondJSON(w, http.StatusConflict, ErrorResponse{Error: "Удалить можно только запланированный или отмененный заказ"})
+	case errors.Is(err, ErrInUse):
+		pkg.RespondJSON(w, http.StatusConflict, ErrorResponse{Error: "По заказу есть экземпляры, расписание или инциденты"})
+	default:
+		slog.Error("work order request failed", slog.Any("err", err))
+		pkg.RespondJSON(w, http.StatusInternalServerError, ErrorResponse{Error: "Внутренняя ошибка сервера"})
+	}
+}
+
+func guardMessage(reason error) string {
+	switch {
+	case errors.Is(reason, ErrNoReleasedRouting):
+		return "У продукта нет утвержденного маршрута"
+	case errors.Is(reason, ErrNoMachine):
+		return "Заказу не назначен станок"
+	case errors.Is(reason, ErrOpenExecutions):
+		return "По заказу есть незавершенные этапы"
+	case errors.Is(reason, ErrNotProduced):
+		return "Готово меньше заказанного количества: не все экземпляры прошли последний этап"
+	default:
+		return "Не выполнено условие перехода"
+	}
+}
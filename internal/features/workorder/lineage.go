@@ -0,0 +1,295 @@
+package workorder
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	"mes-lite-back/pkg"
+
+	"gorm.io/gorm"
+)
+
+// Действия над заказом, записываемые в историю статусов
+const (
+	ActionSplit           = "split"
+	ActionMerge           = "merge"
+	ActionCompletePartial = "complete_partial"
+)
+
+// openStatuses статусы незакрытого заказа, допускающие разделение и объединение
+var openStatuses = []int{StatusPlanned, StatusReleased, StatusInProgress, StatusOnHold}
+
+var (
+	ErrInvalidSplit     = errors.New("split quantity must be positive and less than the order quantity")
+	ErrNotSplittable    = errors.New("only planned, released, in-progress or held orders can be split")
+	ErrNoSources        = errors.New("merge requires at least one other work order")
+	ErrIncompatible     = errors.New("work orders differ in product or routing version")
+	ErrNotMergeable     = errors.New("work order status does not allow merge")
+	ErrNothingCompleted = errors.New("no good units produced yet")
+	ErrFullyCompleted   = errors.New("ordered quantity is produced, use regular completion")
+)
+
+// partialCompletion частичное завершение допустимо только для заказа в работе
+var partialCompletion = &Transition{Action: ActionCompletePartial, From: []int{StatusInProgress}, To: StatusCompleted}
+
+// Split выделяет часть количества заказа в дочерний заказ.
+// Уже выпущенные экземпляры остаются в исходном заказе, дочерний наследует продукт,
+// закрепленную версию маршрута, приоритет и срок.
+func (s *Service) Split(id int64, req SplitRequest, userID int64) (*SplitResult, error) {
+	wo, err := s.GetWorkOrder(id)
+	if err != nil {
+		return nil, err
+	}
+	if req.MachineID != nil {
+		ok, err := s.repo.MachineExists(*req.MachineID)
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			return nil, ErrMachineNotFound
+		}
+	}
+
+	produced, err := s.repo.CountInstances(id)
+	if err != nil {
+		return nil, err
+	}
+	number, err := s.childNumber(wo)
+	if err != nil {
+		return nil, err
+	}
+	comment := strings.TrimSpace(req.Comment)
+
+	parent, child, err := s.repo.Split(id,
+		func(parent *WorkOrder) (*WorkOrder, error) {
+			if !slices.Contains(openStatuses, parent.StatusID) {
+				return nil, ErrNotSplittable
+			}
+			if req.Quantity <= 0 || req.Quantity >= parent.Quantity {
+				return nil, ErrInvalidSplit
+			}
+			if int64(parent.Quantity-req.Quantity) < produced {
+				return nil, ErrQuantityBelow
+			}
+
+			parent.Quantity -= req.Quantity
+			return s.newChild(parent, number, req.Quantity, req.MachineID, userID), nil
+		},
+		func(parent, child *WorkOrder) []*StatusHistory {
+			return []*StatusHistory{
+				s.lineageEntry(parent, parent.StatusID, ActionSplit, userID,
+					fmt.Sprintf("Выделен заказ %s (%d шт.)", child.WONumber, child.Quantity), comment),
+				s.lineageEntry(child, 0, ActionSplit, userID,
+					fmt.Sprintf("Выделен из заказа %s", parent.WONumber), comment),
+			}
+		},
+	)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &SplitResult{Parent: parent, Child: child}, nil
+}
+
+// Merge вливает заказы-источники в заказ id: количество суммируется, экземпляры переносятся,
+// источники отменяются со ссылкой на целевой заказ
+func (s *Service) Merge(id int64, req MergeRequest, userID int64) (*WorkOrder, error) {
+	sources := slices.Clone(req.SourceIDs)
+	slices.Sort(sources)
+	sources = slices.Compact(sources)
+	sources = slices.DeleteFunc(sources, func(src int64) bool { return src == id })
+	if len(sources) == 0 {
+		return nil, ErrNoSources
+	}
+
+	for _, src := range sources {
+		open, err := s.repo.CountOpenExecutions(src)
+		if err != nil {
+			return nil, err
+		}
+		if open > 0 {
+			return nil, &GuardError{Action: ActionMerge, Guard: guardNoOpenExecution.Name, Reason: ErrOpenExecutions}
+		}
+	}
+	comment := strings.TrimSpace(req.Comment)
+
+	target, err := s.repo.Merge(id, sources, func(target *WorkOrder, srcs []*WorkOrder) ([]*StatusHistory, error) {
+		if !slices.Contains(openStatuses, target.StatusID) {
+			return nil, ErrNotMergeable
+		}
+
+		var (
+			numbers []string
+			entries []*StatusHistory
+		)
+		for _, src := range srcs {
+			if !slices.Contains([]int{StatusPlanned, StatusReleased, StatusOnHold}, src.StatusID) {
+				return nil, ErrNotMergeable
+			}
+			if src.ProductID != target.ProductID || !pkg.EqualPtr(src.RoutingVersionID, target.RoutingVersionID) {
+				return nil, ErrIncompatible
+			}
+
+			target.Quantity += src.Quantity
+			numbers = append(numbers, src.WONumber)
+
+			old := src.StatusID
+			src.StatusID = StatusCancelled
+			src.MergedIntoID = &target.ID
+			entries = append(entries, s.lineageEntry(src, old, ActionMerge, userID,
+				fmt.Sprintf("Объединен в заказ %s", target.WONumber), comment))
+		}
+
+		entries = append(entries, s.lineageEntry(target, target.StatusID, ActionMerge, userID,
+			fmt.Sprintf("Присоединены заказы %s", strings.Join(numbers, ", ")), comment))
+		return entries, nil
+	})
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrNotFound
+	}
+	return target, err
+}
+
+// CompletePartial закрывает заказ по фактически сданному количеству годных.
+// С backorder недостающее количество выделяется в новый заказ в статусе «Выпущен».
+func (s *Service) CompletePartial(id int64, req PartialCompleteRequest, userID int64) (*SplitResult, error) {
+	progress, err := s.Progress(id)
+	if err != nil {
+		return nil, err
+	}
+
+	wo, err := s.GetWorkOrder(id)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkFrom(partialCompletion, wo.StatusID); err != nil {
+		return nil, err
+	}
+	if err := s.checkNoOpenExecutions(wo); isGuardReason(err) {
+		return nil, &GuardError{Action: ActionCompletePartial, Guard: guardNoOpenExecution.Name, Reason: err}
+	} else if err != nil {
+		return nil, err
+	}
+
+	good := progress.Good
+	if good <= 0 {
+		return nil, ErrNothingCompleted
+	}
+	if good >= wo.Quantity {
+		return nil, ErrFullyCompleted
+	}
+
+	var number string
+	if req.Backorder {
+		if number, err = s.childNumber(wo); err != nil {
+			return nil, err
+		}
+	}
+	comment := strings.TrimSpace(req.Comment)
+
+	parent, child, err := s.repo.Split(id,
+		func(parent *WorkOrder) (*WorkOrder, error) {
+			if parent.StatusID != StatusInProgress {
+				return nil, checkFrom(partialCompletion, parent.StatusID)
+			}
+
+			parent.StatusID = StatusCompleted
+			parent.CompletedQuantity = &good
+
+			if !req.Backorder {
+				return nil, nil
+			}
+			child := s.newChild(parent, number, parent.Quantity-good, parent.MachineID, userID)
+			child.StatusID = StatusReleased
+			return child, nil
+		},
+		func(parent, child *WorkOrder) []*StatusHistory {
+			note := fmt.Sprintf("Сдано %d из %d шт.", good, parent.Quantity)
+			if child != nil {
+				note += fmt.Sprintf(", остаток в заказе %s", child.WONumber)
+			}
+
+			entry := s.lineageEntry(parent, StatusInProgress, ActionCompletePartial, userID, note, comment)
+			entries := []*StatusHistory{entry}
+			if child != nil {
+				entries = append(entries, s.lineageEntry(child, 0, ActionCompletePartial, userID,
+					fmt.Sprintf("Остаток заказа %s", parent.WONumber), comment))
+			}
+			return entries
+		},
+	)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &SplitResult{Parent: parent, Child: child}, nil
+}
+
+// newChild дочерний заказ: статус planned у запланированного родителя, иначе released
+func (s *Service) newChild(parent *WorkOrder, number string, quantity int, machineID *int64, userID int64) *WorkOrder {
+	status := StatusReleased
+	if parent.StatusID == StatusPlanned {
+		status = StatusPlanned
+	}
+	if machineID == nil {
+		machineID = parent.MachineID
+	}
+
+	return &WorkOrder{
+		WONumber:         number,
+		ProductID:        parent.ProductID,
+		MachineID:        machineID,
+		Quantity:         quantity,
+		PriorityID:       parent.PriorityID,
+		StatusID:         status,
+		Deadline:         parent.Deadline,
+		RoutingVersionID: parent.RoutingVersionID,
+		ParentID:         &parent.ID,
+		CreatedBy:        &userID,
+	}
+}
+
+// childNumber номер дочернего заказа: номер родителя с порядковым суффиксом, например WO-2026-000123-02
+func (s *Service) childNumber(parent *WorkOrder) (string, error) {
+	n, err := s.repo.CountChildren(parent.ID)
+	if err != nil {
+		return "", err
+	}
+
+	for i := n + 1; i <= n+maxNumberAttempts; i++ {
+		number := fmt.Sprintf("%s-%02d", parent.WONumber, i)
+		if err := s.checkNumber(number); err == nil {
+			return number, nil
+		} else if !errors.Is(err, ErrNumberTaken) {
+			return "", err
+		}
+	}
+	return "", ErrNumberTaken
+}
+
+// lineageEntry запись истории; old = 0 — заказ только что создан
+func (s *Service) lineageEntry(wo *WorkOrder, old int, action string, userID int64, note, comment string) *StatusHistory {
+	if comment != "" {
+		note += ". " + comment
+	}
+
+	h := &StatusHistory{
+		WorkOrderID: wo.ID,
+		NewStatusID: wo.StatusID,
+		Action:      action,
+		Comment:     note,
+		ChangedBy:   &userID,
+	}
+	if old != 0 {
+		h.OldStatusID = &old
+	}
+	return h
+}
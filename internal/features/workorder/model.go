@@ -0,0 +1,262 @@
+package workorder
+
+import "time"
+
+// Статусы заказа (значения из справочника order_statuses)
+const (
+	StatusPlanned    = 1
+	StatusReleased   = 2
+	StatusInProgress = 3
+	StatusOnHold     = 4
+	StatusCompleted  = 5
+	StatusCancelled  = 6
+)
+
+// PriorityNormal приоритет заказа по умолчанию
+const PriorityNormal = 2
+
+// WorkOrder рабочий заказ на выпуск продукции.
+// ParentID — заказ, из которого выделен данный, MergedIntoID — заказ, в который он влит,
+// CompletedQuantity — сданное количество при частичном завершении.
+type WorkOrder struct {
+	ID                int64      `gorm:"primaryKey;autoIncrement" json:"id"`
+	WONumber          string     `gorm:"column:wo_number;unique;not null" json:"wo_number"`
+	ProductID         int64      `json:"product_id"`
+	MachineID         *int64     `json:"machine_id,omitempty"`
+	Quantity          int        `gorm:"not null" json:"quantity"`
+	PriorityID        int        `json:"priority_id"`
+	StatusID          int        `json:"status_id"`
+	Deadline          *time.Time `gorm:"type:date" json:"deadline,omitempty"`
+	RoutingVersionID  *int64     `json:"routing_version_id,omitempty"`
+	ParentID          *int64     `json:"parent_id,omitempty"`
+	MergedIntoID      *int64     `json:"merged_into_id,omitempty"`
+	CompletedQuantity *int       `json:"completed_quantity,omitempty"`
+	CreatedBy         *int64     `json:"created_by,omitempty"`
+	CreatedAt         time.Time  `gorm:"autoCreateTime" json:"created_at"`
+	UpdatedAt         time.Time  `gorm:"autoUpdateTime" json:"updated_at"`
+}
+
+func (WorkOrder) TableName() string {
+	return "work_orders"
+}
+
+// Priority приоритет заказа; Weight — порядок срочности
+type Priority struct {
+	ID     int    `gorm:"primaryKey" json:"id"`
+	Code   string `json:"code"`
+	Name   string `json:"name"`
+	Weight int    `json:"weight"`
+}
+
+func (Priority) TableName() string {
+	return "order_priorities"
+}
+
+// Status статус заказа
+type Status struct {
+	ID   int    `gorm:"primaryKey" json:"id"`
+	Code string `json:"code"`
+	Name string `json:"name"`
+}
+
+func (Status) TableName() string {
+	return "order_statuses"
+}
+
+// StatusHistory запись истории статусов заказа
+type StatusHistory struct {
+	ID          int64     `gorm:"primaryKey;autoIncrement" json:"id"`
+	WorkOrderID int64     `json:"work_order_id"`
+	OldStatusID *int      `json:"old_status_id,omitempty"`
+	NewStatusID int       `json:"new_status_id"`
+	Action      string    `json:"action"`
+	Comment     string    `json:"comment,omitempty"`
+	ChangedBy   *int64    `json:"changed_by,omitempty"`
+	ChangedAt   time.Time `gorm:"autoCreateTime" json:"changed_at"`
+}
+
+func (StatusHistory) TableName() string {
+	return "work_order_status_history"
+}
+
+// Comment комментарий к заказу
+type Comment struct {
+	ID          int64      `gorm:"primaryKey;autoIncrement" json:"id"`
+	WorkOrderID int64      `json:"work_order_id"`
+	UserID      int64      `json:"user_id"`
+	Message     string     `gorm:"not null" json:"message"`
+	CreatedAt   time.Time  `gorm:"autoCreateTime" json:"created_at"`
+	EditedAt    *time.Time `json:"edited_at,omitempty"`
+}
+
+func (Comment) TableName() string {
+	return "work_order_comments"
+}
+
+// Типы событий ленты активности заказа
+const (
+	EventStatus  = "status"
+	EventComment = "comment"
+)
+
+// ActivityEvent событие ленты активности заказа: смена статуса или комментарий
+type ActivityEvent struct {
+	Type        string    `json:"type" example:"comment"`
+	At          time.Time `json:"at"`
+	UserID      *int64    `json:"user_id,omitempty"`
+	Action      string    `json:"action,omitempty" example:"release"`
+	OldStatusID *int      `json:"old_status_id,omitempty"`
+	NewStatusID int       `json:"new_status_id,omitempty"`
+	CommentID   int64     `json:"comment_id,omitempty"`
+	Message     string    `json:"message,omitempty"`
+	Edited      bool      `json:"edited,omitempty"`
+}
+
+// RoutingStep этап маршрута заказа
+type RoutingStep struct {
+	StageID          int64
+	Name             string
+	StageOrder       int
+	ExpectedCycleMin *int
+}
+
+// ExecutionRow выполнение этапа по экземпляру заказа; Superseded — отменено доработкой
+type ExecutionRow struct {
+	ProductInstanceID int64
+	StageID           int64
+	StartTime         time.Time
+	EndTime           *time.Time
+	Superseded        bool
+}
+
+// YieldRow итоги контроля экземпляра заказа
+type YieldRow struct {
+	ProductInstanceID int64
+	Inspected         bool
+	Failed            bool
+	Reworks           int
+	Scrapped          bool
+	ScrapReasonID     *int
+}
+
+// ScrapCount списанные в брак экземпляры по причине
+type ScrapCount struct {
+	ReasonID int `json:"reason_id" example:"2"`
+	Quantity int `json:"quantity" example:"1"`
+}
+
+// Yield выход годного заказа. Оцененные экземпляры — прошедшие контроль или списанные;
+// с первого предъявления годны оцененные без браковочных результатов, доработок и списания.
+type Yield struct {
+	WorkOrderID int64 `json:"work_order_id" example:"1"`
+	Produced    int   `json:"produced" example:"45"`
+	Evaluated   int   `json:"evaluated" example:"40"`
+	FirstPass   int   `json:"first_pass" example:"36"`
+	// FirstPassYield доля годных с первого предъявления среди оцененных, %; не задана, пока нет оцененных
+	FirstPassYield *float64 `json:"first_pass_yield,omitempty" example:"90"`
+	// Reworks назначенные доработки; Reworked — экземпляры, отправленные на доработку хотя бы раз
+	Reworks       int           `json:"reworks" example:"4"`
+	Reworked      int           `json:"reworked" example:"3"`
+	Scrapped      int           `json:"scrapped" example:"1"`
+	ScrapByReason []*ScrapCount `json:"scrap_by_reason"`
+}
+
+// Источники времени цикла этапа в прогнозе
+const (
+	CycleObserved  = "observed"   // медиана фактических выполнений
+	CycleExpected  = "expected"   // норматив этапа в маршруте
+	CycleTechCycle = "tech_cycle" // такт продукта, распределенный по этапам
+)
+
+// StageProgress выполнение заказа на этапе маршрута
+type StageProgress struct {
+	StageID    int64  `json:"stage_id" example:"1"`
+	Name       string `json:"name" example:"Сборка"`
+	StageOrder int    `json:"stage_order" example:"1"`
+	// Completed экземпляры, прошедшие этап и не признанные браком
+	Completed int `json:"completed" example:"40"`
+	// Scrap брак, выявленный после этого этапа как последнего выполненного
+	Scrap      int `json:"scrap" example:"2"`
+	InProgress int `json:"in_progress" example:"3"`
+	Remaining  int `json:"remaining" example:"60"`
+	// CycleMin время обработки одного экземпляра, мин; 0 — неизвестно
+	CycleMin    float64 `json:"cycle_min" example:"4.5"`
+	CycleSource string  `json:"cycle_source,omitempty" example:"observed"`
+	Samples     int     `json:"samples" example:"38"`
+}
+
+// Progress ход выполнения заказа и прогноз завершения
+type Progress struct {
+	WorkOrderID int64 `json:"work_order_id" example:"1"`
+	StatusID    int   `json:"status_id" example:"3"`
+	Quantity    int   `json:"quantity" example:"100"`
+	Produced    int   `json:"produced" example:"45"`
+	// Good экземпляры, прошедшие весь маршрут без брака
+	Good            int              `json:"good" example:"38"`
+	Scrap           int              `json:"scrap" example:"2"`
+	InProgress      int              `json:"in_progress" example:"3"`
+	PercentComplete float64          `json:"percent_complete" example:"41.5"`
+	Stages          []*StageProgress `json:"stages"`
+	RemainingMin    float64          `json:"remaining_min" example:"270"`
+	// ETA не задан, если время цикла хотя бы одного этапа неизвестно
+	ETA      *time.Time `json:"eta,omitempty"`
+	Deadline *time.Time `json:"deadline,omitempty"`
+	// Late прогноз завершения позже срока заказа
+	Late bool `json:"late"`
+}
+
+// SplitRequest выделение части заказа в дочерний
+type SplitRequest struct {
+	Quantity int `json:"quantity" example:"40"`
+	// MachineID станок дочернего заказа; по умолчанию — станок исходного
+	MachineID *int64 `json:"machine_id,omitempty" example:"3"`
+	Comment   string `json:"comment,omitempty" example:"Станок 2 в ремонте"`
+}
+
+// MergeRequest объединение заказов в текущий
+type MergeRequest struct {
+	SourceIDs []int64 `json:"source_ids" example:"12,13"`
+	Comment   string  `json:"comment,omitempty" example:"Один SKU подряд"`
+}
+
+// PartialCompleteRequest частичное завершение заказа
+type PartialCompleteRequest struct {
+	// Backorder выделить недостающее количество в новый заказ
+	Backorder bool   `json:"backorder" example:"true"`
+	Comment   string `json:"comment,omitempty" example:"Закрываем по окончании смены"`
+}
+
+// SplitResult исходный и выделенный заказы
+type SplitResult struct {
+	Parent *WorkOrder `json:"parent"`
+	Child  *WorkOrder `json:"child,omitempty"`
+}
+
+// TransitionRequest действие над статусом заказа
+type TransitionRequest struct {
+	Action  string `json:"action" example:"release"`
+	Comment string `json:"comment,omitempty" example:"Материалы получены"`
+}
+
+// AvailableAction действие, доступное из текущего статуса заказа
+type AvailableAction struct {
+	Action     string `json:"action" example:"start"`
+	ToStatusID int    `json:"to_status_id" example:"3"`
+	Permission string `json:"permission" example:"production.start"`
+	// Permitted есть ли у пользователя право на действие; условия перехода проверяются при выполнении
+	Permitted bool `json:"permitted"`
+}
+
+// ListFilter параметры выборки заказов; нулевые значения не фильтруют
+type ListFilter struct {
+	// Query ищет по вхождению в wo_number без учёта регистра
+	Query        string
+	StatusID     int
+	PriorityID   int
+	MachineID    int64
+	ProductID    int64
+	DeadlineFrom *time.Time
+	DeadlineTo   *time.Time
+	Limit        int
+	Offset       int
+}
@@ -0,0 +1,51 @@
+package workorder
+
+import (
+	"time"
+
+	"mes-lite-back/internal/numbering"
+)
+
+// DefaultNumberPattern шаблон номера заказа по умолчанию, например WO-2026-000123
+const DefaultNumberPattern = "{PREFIX}-{YYYY}-{SEQ:6}"
+
+// DefaultNumberPrefix префикс номера, если в конфигурации он не задан
+const DefaultNumberPrefix = "WO"
+
+// NumberPattern шаблон номера заказа из токенов:
+// {PREFIX}, {YYYY}, {YY}, {MM}, {SEQ:n} — порядковый номер с дополнением нулями до n знаков
+type NumberPattern struct {
+	pattern *numbering.Pattern
+	prefix  string
+}
+
+func NewNumberPattern(pattern, prefix string) (*NumberPattern, error) {
+	if pattern == "" {
+		pattern = DefaultNumberPattern
+	}
+	if prefix == "" {
+		prefix = DefaultNumberPrefix
+	}
+
+	p, err := numbering.Parse(pattern, "PREFIX", "YYYY", "YY", "MM")
+	if err != nil {
+		return nil, err
+	}
+	return &NumberPattern{pattern: p, prefix: prefix}, nil
+}
+
+// Scope ключ счетчика в serial_counters; при шаблоне с {YYYY} нумерация начинается заново каждый год
+func (p *NumberPattern) Scope(at time.Time) string {
+	return "work_order:" + p.pattern.Scope(p.values(at))
+}
+
+// Format собирает номер заказа для порядкового номера seq
+func (p *NumberPattern) Format(at time.Time, seq int64) (string, error) {
+	return p.pattern.Format(p.values(at), seq)
+}
+
+func (p *NumberPattern) values(at time.Time) map[string]string {
+	values := numbering.DateValues(at)
+	values["PREFIX"] = p.prefix
+	return values
+}
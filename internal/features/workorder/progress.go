@@ -0,0 +1,185 @@
+package workorder
+
+import (
+	"math"
+	"slices"
+	"time"
+)
+
+// minObservedSamples минимальное число выполнений этапа, после которого прогноз
+// опирается на фактическое время вместо норматива
+const minObservedSamples = 3
+
+// Progress считает выполнение заказа по этапам маршрута и прогноз завершения.
+// Прогноз предполагает последовательную обработку оставшихся экземпляров на каждом этапе.
+func (s *Service) Progress(id int64) (*Progress, error) {
+	wo, err := s.GetWorkOrder(id)
+	if err != nil {
+		return nil, err
+	}
+
+	steps, err := s.repo.RoutingSteps(wo)
+	if err != nil {
+		return nil, err
+	}
+	executions, err := s.repo.ListExecutions(id)
+	if err != nil {
+		return nil, err
+	}
+	scrapped, err := s.repo.ScrappedInstances(id)
+	if err != nil {
+		return nil, err
+	}
+	produced, err := s.repo.CountInstances(id)
+	if err != nil {
+		return nil, err
+	}
+	techCycle, err := s.repo.ProductTechCycle(wo.ProductID)
+	if err != nil {
+		return nil, err
+	}
+
+	p := &Progress{
+		WorkOrderID: wo.ID,
+		StatusID:    wo.StatusID,
+		Quantity:    wo.Quantity,
+		Produced:    int(produced),
+		Scrap:       len(scrapped),
+		Deadline:    wo.Deadline,
+		Stages:      make([]*StageProgress, 0, len(steps)),
+	}
+
+	scrap := make(map[int64]bool, len(scrapped))
+	for _, instanceID := range scrapped {
+		scrap[instanceID] = true
+	}
+
+	// последний завершенный этап экземпляра: к нему относится выявленный брак
+	lastStage := make(map[int64]int64)
+	lastEnd := make(map[int64]time.Time)
+	finished := make(map[int64]map[int64]bool)
+	durations := make(map[int64][]float64)
+	open := make(map[int64]int)
+
+	for _, e := range executions {
+		if e.EndTime == nil {
+			open[e.StageID]++
+			p.InProgress++
+			continue
+		}
+		// выполнение, отмененное доработкой, учитывается только во времени цикла
+		if e.Superseded {
+			durations[e.StageID] = append(durations[e.StageID], e.EndTime.Sub(e.StartTime).Minutes())
+			continue
+		}
+		if finished[e.StageID] == nil {
+			finished[e.StageID] = make(map[int64]bool)
+		}
+		finished[e.StageID][e.ProductInstanceID] = true
+		durations[e.StageID] = append(durations[e.StageID], e.EndTime.Sub(e.StartTime).Minutes())
+
+		if e.EndTime.After(lastEnd[e.ProductInstanceID]) {
+			lastEnd[e.ProductInstanceID] = *e.EndTime
+			lastStage[e.ProductInstanceID] = e.StageID
+		}
+	}
+
+	var done, total float64
+	etaKnown := len(steps) > 0
+
+	for _, st := range steps {
+		sp := &StageProgress{
+			StageID:    st.StageID,
+			Name:       st.Name,
+			StageOrder: st.StageOrder,
+			InProgress: open[st.StageID],
+			Samples:    len(durations[st.StageID]),
+		}
+
+		for instanceID := range finished[st.StageID] {
+			if !scrap[instanceID] {
+				sp.Completed++
+			} else if lastStage[instanceID] == st.StageID {
+				sp.Scrap++
+			}
+		}
+		sp.Remaining = max(0, wo.Quantity-sp.Completed)
+
+		sp.CycleMin, sp.CycleSource = stageCycle(st, durations[st.StageID], techCycle, len(steps))
+		if sp.CycleMin == 0 && sp.Remaining > 0 {
+			etaKnown = false
+		}
+		p.RemainingMin += float64(sp.Remaining) * sp.CycleMin
+
+		done += float64(min(sp.Completed, wo.Quantity))
+		total += float64(wo.Quantity)
+		p.Stages = append(p.Stages, sp)
+	}
+
+	if len(steps) > 0 {
+		last := steps[len(steps)-1].StageID
+		for instanceID := range finished[last] {
+			if !scrap[instanceID] {
+				p.Good++
+			}
+		}
+	} else {
+		// без маршрута годными считаются все выпущенные экземпляры, кроме брака
+		p.Good = p.Produced - p.Scrap
+		remaining := max(0, wo.Quantity-p.Good)
+		if techCycle != nil {
+			p.RemainingMin = float64(remaining) * float64(*techCycle)
+			etaKnown = true
+		}
+		done, total = float64(min(p.Good, wo.Quantity)), float64(wo.Quantity)
+	}
+
+	if total > 0 {
+		p.PercentComplete = math.Round(done/total*1000) / 10
+	}
+	p.RemainingMin = math.Round(p.RemainingMin*10) / 10
+
+	if wo.StatusID == StatusCompleted || wo.StatusID == StatusCancelled {
+		p.RemainingMin = 0
+		return p, nil
+	}
+
+	if etaKnown {
+		eta := s.now().Add(time.Duration(p.RemainingMin * float64(time.Minute)))
+		p.ETA = &eta
+
+		if wo.Deadline != nil {
+			y, m, d := wo.Deadline.Date()
+			endOfDay := time.Date(y, m, d, 23, 59, 59, 0, eta.Location())
+			p.Late = eta.After(endOfDay)
+		}
+	}
+
+	return p, nil
+}
+
+// stageCycle время обработки одного экземпляра на этапе:
+// медиана фактических выполнений, затем норматив этапа, затем такт продукта на число этапов
+func stageCycle(st *RoutingStep, observed []float64, techCycle *int, stages int) (float64, string) {
+	if len(observed) >= minObservedSamples {
+		return math.Round(median(observed)*10) / 10, CycleObserved
+	}
+	if st.ExpectedCycleMin != nil {
+		return float64(*st.ExpectedCycleMin), CycleExpected
+	}
+	if techCycle != nil && stages > 0 {
+		return math.Round(float64(*techCycle)/float64(stages)*10) / 10, CycleTechCycle
+	}
+	return 0, ""
+}
+
+func median(values []float64) float64 {
+	sorted := slices.Clone(values)
+	slices.Sort(sorted)
+
+	n := len(sorted)
+	if n%2 == 1 {
+		return sorted[n/2]
+	}
+	return (sorted[n/2-1] + sorted[n/2]) / 2
+}
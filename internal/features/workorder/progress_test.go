@@ -0,0 +1,198 @@
+package workorder
+
+import (
+	"slices"
+	"testing"
+	"time"
+)
+
+func intPtr(v int) *int { return &v }
+
+func TestMedian(t *testing.T) {
+	tests := []struct {
+		in   []float64
+		want float64
+	}{
+		{[]float64{5}, 5},
+		{[]float64{3, 1, 2}, 2},
+		{[]float64{8, 6, 7, 5}, 6.5},
+		{[]float64{1, 100, 2, 3, 4}, 3},
+	}
+	for _, tt := range tests {
+		in := append([]float64(nil), tt.in...)
+		if got := median(tt.in); got != tt.want {
+			t.Errorf("median(%v) = %v, want %v", in, got, tt.want)
+		}
+		for i := range in {
+			if in[i] != tt.in[i] {
+				t.Errorf("median changed its input")
+			}
+		}
+	}
+}
+
+func TestStageCycle(t *testing.T) {
+	tests := []struct {
+		name       string
+		expected   *int
+		observed   []float64
+		techCycle  *int
+		stages     int
+		want       float64
+		wantSource string
+	}{
+		{"медиана при достаточной выборке", intPtr(10), []float64{4, 5.04, 9}, intPtr(30), 2, 5, CycleObserved},
+		{"норматив при малой выборке", intPtr(10), []float64{4, 5}, intPtr(30), 2, 10, CycleExpected},
+		{"такт продукта на число этапов", nil, []float64{4}, intPtr(10), 3, 3.3, CycleTechCycle},
+		{"неизвестно", nil, nil, nil, 3, 0, ""},
+		{"без этапов такт не делится", nil, nil, intPtr(10), 0, 0, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, source := stageCycle(&RoutingStep{ExpectedCycleMin: tt.expected}, tt.observed, tt.techCycle, tt.stages)
+			if got != tt.want || source != tt.wantSource {
+				t.Errorf("stageCycle = %v, %q, want %v, %q", got, source, tt.want, tt.wantSource)
+			}
+		})
+	}
+}
+
+// execution выполнение этапа: start — минуты от 8:00, duration < 0 — незавершено
+func execution(instanceID, stageID int64, start, duration int, superseded bool) *ExecutionRow {
+	base := time.Date(2026, 10, 5, 8, 0, 0, 0, time.UTC)
+	e := &ExecutionRow{
+		ProductInstanceID: instanceID,
+		StageID:           stageID,
+		StartTime:         base.Add(time.Duration(start) * time.Minute),
+		Superseded:        superseded,
+	}
+	if duration >= 0 {
+		end := e.StartTime.Add(time.Duration(duration) * time.Minute)
+		e.EndTime = &end
+	}
+	return e
+}
+
+func progressRepo(deadline *time.Time, status int) *fakeRepo {
+	return &fakeRepo{
+		wo: &WorkOrder{ID: 1, ProductID: 1, Quantity: 4, StatusID: status, Deadline: deadline},
+		steps: []*RoutingStep{
+			{StageID: 1, Name: "Сварка", StageOrder: 1, ExpectedCycleMin: intPtr(10)},
+			{StageID: 2, Name: "Сборка", StageOrder: 2},
+		},
+		executions: []*ExecutionRow{
+			execution(1, 1, 0, 6, false),
+			execution(2, 1, 10, 8, false),
+			execution(3, 1, 20, 7, false),
+			execution(4, 1, 30, 5, false),
+			// выполнение, отмененное доработкой, учитывается только во времени цикла
+			execution(3, 2, 40, 30, true),
+			execution(1, 2, 80, 10, false),
+			execution(2, 2, 100, 12, false),
+			execution(3, 2, 120, -1, false),
+		},
+		scrapped:  []int64{4},
+		instances: 4,
+		techCycle: intPtr(30),
+	}
+}
+
+func TestProgress(t *testing.T) {
+	day := time.Date(2026, 10, 5, 0, 0, 0, 0, time.UTC)
+	s := newTestService(progressRepo(&day, StatusInProgress), allPerms, fakeRouting{})
+
+	p, err := s.Progress(1)
+	if err != nil {
+		t.Fatalf("Progress: %v", err)
+	}
+
+	if p.Produced != 4 || p.Scrap != 1 || p.Good != 2 || p.InProgress != 1 {
+		t.Errorf("produced/scrap/good/in progress = %d/%d/%d/%d, want 4/1/2/1", p.Produced, p.Scrap, p.Good, p.InProgress)
+	}
+
+	want := []StageProgress{
+		// медиана 5, 6, 7, 8; брак экземпляра 4 относится к сварке
+		{StageID: 1, Completed: 3, Scrap: 1, Remaining: 1, CycleMin: 6.5, CycleSource: CycleObserved, Samples: 4},
+		// медиана 10, 12 и отмененного доработкой выполнения 30
+		{StageID: 2, Completed: 2, InProgress: 1, Remaining: 2, CycleMin: 12, CycleSource: CycleObserved, Samples: 3},
+	}
+	for i, w := range want {
+		got := *p.Stages[i]
+		got.Name, got.StageOrder = "", 0
+		if got != w {
+			t.Errorf("stage %d = %+v, want %+v", i, got, w)
+		}
+	}
+
+	// выполнено (3 + 2) из 4·2, осталось 1·6.5 + 2·12 минут
+	if p.PercentComplete != 62.5 || p.RemainingMin != 30.5 {
+		t.Errorf("percent/remaining = %v/%v, want 62.5/30.5", p.PercentComplete, p.RemainingMin)
+	}
+	wantETA := s.now().Add(30*time.Minute + 30*time.Second)
+	if p.ETA == nil || !p.ETA.Equal(wantETA) {
+		t.Errorf("ETA = %v, want %v", p.ETA, wantETA)
+	}
+	if p.Late {
+		t.Errorf("finishing on the deadline day must not be late")
+	}
+}
+
+func TestProgressLate(t *testing.T) {
+	yesterday := time.Date(2026, 10, 4, 0, 0, 0, 0, time.UTC)
+	s := newTestService(progressRepo(&yesterday, StatusInProgress), allPerms, fakeRouting{})
+
+	p, err := s.Progress(1)
+	if err != nil {
+		t.Fatalf("Progress: %v", err)
+	}
+	if !p.Late {
+		t.Errorf("ETA after the deadline day must be late")
+	}
+}
+
+func TestProgressClosed(t *testing.T) {
+	s := newTestService(progressRepo(nil, StatusCompleted), allPerms, fakeRouting{})
+
+	p, err := s.Progress(1)
+	if err != nil {
+		t.Fatalf("Progress: %v", err)
+	}
+	if p.RemainingMin != 0 || p.ETA != nil {
+		t.Errorf("closed order: remaining = %v, ETA = %v", p.RemainingMin, p.ETA)
+	}
+}
+
+func TestProgressUnknownCycle(t *testing.T) {
+	repo := progressRepo(nil, StatusInProgress)
+	repo.techCycle = nil
+	// на сборке две выборки и нет норматива
+	repo.executions = slices.DeleteFunc(repo.executions, func(e *ExecutionRow) bool { return e.Superseded })
+	s := newTestService(repo, allPerms, fakeRouting{})
+
+	p, err := s.Progress(1)
+	if err != nil {
+		t.Fatalf("Progress: %v", err)
+	}
+	if p.ETA != nil {
+		t.Errorf("ETA = %v, want none while a stage cycle is unknown", p.ETA)
+	}
+}
+
+func TestProgressWithoutRouting(t *testing.T) {
+	repo := &fakeRepo{
+		wo:        &WorkOrder{ID: 1, Quantity: 10, StatusID: StatusInProgress},
+		scrapped:  []int64{1},
+		instances: 5,
+		techCycle: intPtr(3),
+	}
+	s := newTestService(repo, allPerms, fakeRouting{})
+
+	p, err := s.Progress(1)
+	if err != nil {
+		t.Fatalf("Progress: %v", err)
+	}
+	// годные — выпущенные без брака: 4 из 10, остаток 6 по такту 3 минуты
+	if p.Good != 4 || p.PercentComplete != 40 || p.RemainingMin != 18 || p.ETA == nil {
+		t.Errorf("good/percent/remaining/eta = %d/%v/%v/%v", p.Good, p.PercentComplete, p.RemainingMin, p.ETA)
+	}
+}
@@ -0,0 +1,70 @@
+package workorder
+
+type Repository interface {
+	// Create создает заказ и первую запись истории статусов
+	Create(wo *WorkOrder, history *StatusHistory) error
+	Update(wo *WorkOrder) error
+	// Delete удаляет заказ вместе с комментариями и историей статусов
+	Delete(wo *WorkOrder) error
+
+	GetByID(id int64) (*WorkOrder, error)
+	GetByNumber(number string) (*WorkOrder, error)
+	List(filter ListFilter) ([]*WorkOrder, error)
+
+	// ChangeStatus под блокировкой строки заказа применяет change и записывает возвращенную им историю
+	ChangeStatus(id int64, change func(wo *WorkOrder) (*StatusHistory, error)) (*WorkOrder, error)
+	// Split под блокировкой заказа вызывает split, создает возвращенный им дочерний заказ
+	// и записывает историю, сформированную history
+	Split(
+		id int64,
+		split func(parent *WorkOrder) (*WorkOrder, error),
+		history func(parent, child *WorkOrder) []*StatusHistory,
+	) (*WorkOrder, *WorkOrder, error)
+	// Merge под блокировкой всех заказов вызывает merge, переносит экземпляры источников в целевой заказ
+	// и освобождает их слоты расписания
+	Merge(
+		targetID int64,
+		sourceIDs []int64,
+		merge func(target *WorkOrder, sources []*WorkOrder) ([]*StatusHistory, error),
+	) (*WorkOrder, error)
+	CountChildren(id int64) (int64, error)
+	ListHistory(id int64) ([]*StatusHistory, error)
+	// StatusBefore статус, из которого заказ последний раз перешел в status
+	StatusBefore(id int64, status int) (int, error)
+
+	CreateComment(c *Comment) error
+	UpdateComment(c *Comment) error
+	DeleteComment(c *Comment) error
+	GetComment(workOrderID, commentID int64) (*Comment, error)
+	ListComments(workOrderID int64) ([]*Comment, error)
+	// FindUserIDs сопоставляет имена пользователей (без учета регистра) с их ID
+	FindUserIDs(usernames []string) (map[string]int64, error)
+
+	// RoutingSteps этапы версии маршрута, закрепленной за заказом, или действующей версии продукта
+	RoutingSteps(wo *WorkOrder) ([]*RoutingStep, error)
+	ListExecutions(workOrderID int64) ([]*ExecutionRow, error)
+	// ScrappedInstances экземпляры заказа, списанные в брак или с последним браковочным результатом
+	// контроля, по которому не назначена доработка
+	ScrappedInstances(workOrderID int64) ([]int64, error)
+	// YieldRows признаки контроля, доработок и списания по каждому экземпляру заказа
+	YieldRows(workOrderID int64) ([]*YieldRow, error)
+	ProductTechCycle(productID int64) (*int, error)
+
+	// AllocateNumber атомарно выдает следующий номер в счетчике scope
+	AllocateNumber(scope string) (int64, error)
+
+	ListPriorities() ([]*Priority, error)
+	ListStatuses() ([]*Status, error)
+	PriorityExists(id int) (bool, error)
+	ProductExists(id int64) (bool, error)
+	MachineExists(id int64) (bool, error)
+
+	CountInstances(id int64) (int64, error)
+	// CountCompleted экземпляры заказа не в браке (в том же смысле, что ScrappedInstances) с завершенным
+	// и не замененным доработкой выполнением этапа finalStageID
+	CountCompleted(id, finalStageID int64) (int64, error)
+	// CountOpenExecutions незавершенные выполнения этапов по экземплярам заказа
+	CountOpenExecutions(id int64) (int64, error)
+	// CountReferences количество экземпляров, слотов расписания и инцидентов, ссылающихся на заказ
+	CountReferences(id int64) (int64, error)
+}
@@ -0,0 +1,478 @@
+package workorder
+
+import (
+	"mes-lite-back/internal/numbering"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type GormRepository struct {
+	db *gorm.DB
+}
+
+func NewGormRepository(db *gorm.DB) *GormRepository {
+	return &GormRepository{db: db}
+}
+
+func (r *GormRepository) Create(wo *WorkOrder, history *StatusHistory) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(wo).Error; err != nil {
+			return err
+		}
+		history.WorkOrderID = wo.ID
+		return tx.Create(history).Error
+	})
+}
+
+func (r *GormRepository) Update(wo *WorkOrder) error {
+	return r.db.Save(wo).Error
+}
+
+func (r *GormRepository) Delete(wo *WorkOrder) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Exec("DELETE FROM work_order_comments WHERE work_order_id = ?", wo.ID).Error; err != nil {
+			return err
+		}
+		if err := tx.Exec("DELETE FROM work_order_status_history WHERE work_order_id = ?", wo.ID).Error; err != nil {
+			return err
+		}
+		return tx.Delete(wo).Error
+	})
+}
+
+func (r *GormRepository) GetByID(id int64) (*WorkOrder, error) {
+	var wo WorkOrder
+	if err := r.db.First(&wo, id).Error; err != nil {
+		return nil, err
+	}
+	return &wo, nil
+}
+
+func (r *GormRepository) GetByNumber(number string) (*WorkOrder, error) {
+	var wo WorkOrder
+	if err := r.db.Where("wo_number = ?", number).First(&wo).Error; err != nil {
+		return nil, err
+	}
+	return &wo, nil
+}
+
+func (r *GormRepository) List(filter ListFilter) ([]*WorkOrder, error) {
+	var orders []*WorkOrder
+
+	q := r.db.Order("id DESC")
+	if filter.Query != "" {
+		q = q.Where("wo_number ILIKE ?", "%"+filter.Query+"%")
+	}
+	if filter.StatusID > 0 {
+		q = q.Where("status_id = ?", filter.StatusID)
+	}
+	if filter.PriorityID > 0 {
+		q = q.Where("priority_id = ?", filter.PriorityID)
+	}
+	if filter.MachineID > 0 {
+		q = q.Where("machine_id = ?", filter.MachineID)
+	}
+	if filter.ProductID > 0 {
+		q = q.Where("product_id = ?", filter.ProductID)
+	}
+	if filter.DeadlineFrom != nil {
+		q = q.Where("deadline >= ?", *filter.DeadlineFrom)
+	}
+	if filter.DeadlineTo != nil {
+		q = q.Where("deadline <= ?", *filter.DeadlineTo)
+	}
+	if filter.Limit > 0 {
+		q = q.Limit(filter.Limit)
+	}
+	if filter.Offset > 0 {
+		q = q.Offset(filter.Offset)
+	}
+
+	return orders, q.Find(&orders).Error
+}
+
+func (r *GormRepository) ChangeStatus(id int64, change func(wo *WorkOrder) (*StatusHistory, error)) (*WorkOrder, error) {
+	var wo WorkOrder
+
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&wo, id).Error; err != nil {
+			return err
+		}
+
+		history, err := change(&wo)
+		if err != nil {
+			return err
+		}
+
+		if err := tx.Save(&wo).Error; err != nil {
+			return err
+		}
+		history.WorkOrderID = wo.ID
+		return tx.Create(history).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &wo, nil
+}
+
+func (r *GormRepository) Split(
+	id int64,
+	split func(parent *WorkOrder) (*WorkOrder, error),
+	history func(parent, child *WorkOrder) []*StatusHistory,
+) (*WorkOrder, *WorkOrder, error) {
+	var (
+		parent WorkOrder
+		child  *WorkOrder
+	)
+
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&parent, id).Error; err != nil {
+			return err
+		}
+
+		var err error
+		if child, err = split(&parent); err != nil {
+			return err
+		}
+
+		if err := tx.Save(&parent).Error; err != nil {
+			return err
+		}
+		if child != nil {
+			if err := tx.Create(child).Error; err != nil {
+				return err
+			}
+		}
+
+		entries := history(&parent, child)
+		if len(entries) == 0 {
+			return nil
+		}
+		return tx.Create(entries).Error
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	return &parent, child, nil
+}
+
+func (r *GormRepository) Merge(
+	targetID int64,
+	sourceIDs []int64,
+	merge func(target *WorkOrder, sources []*WorkOrder) ([]*StatusHistory, error),
+) (*WorkOrder, error) {
+	var target WorkOrder
+
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		// блокировка в порядке id исключает взаимоблокировку встречных объединений
+		var locked []*WorkOrder
+		ids := append([]int64{targetID}, sourceIDs...)
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("id IN ?", ids).
+			Order("id").
+			Find(&locked).Error; err != nil {
+			return err
+		}
+		if len(locked) != len(ids) {
+			return gorm.ErrRecordNotFound
+		}
+
+		var sources []*WorkOrder
+		for _, wo := range locked {
+			if wo.ID == targetID {
+				target = *wo
+			} else {
+				sources = append(sources, wo)
+			}
+		}
+
+		entries, err := merge(&target, sources)
+		if err != nil {
+			return err
+		}
+
+		if err := tx.Table("product_instances").
+			Where("work_order_id IN ?", sourceIDs).
+			Update("work_order_id", targetID).Error; err != nil {
+			return err
+		}
+		if err := tx.Exec("DELETE FROM schedule WHERE work_order_id IN ?", sourceIDs).Error; err != nil {
+			return err
+		}
+
+		if err := tx.Save(&target).Error; err != nil {
+			return err
+		}
+		for _, src := range sources {
+			if err := tx.Save(src).Error; err != nil {
+				return err
+			}
+		}
+		return tx.Create(entries).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &target, nil
+}
+
+func (r *GormRepository) CountChildren(id int64) (int64, error) {
+	var n int64
+	err := r.db.Model(&WorkOrder{}).Where("parent_id = ?", id).Count(&n).Error
+	return n, err
+}
+
+func (r *GormRepository) ListHistory(id int64) ([]*StatusHistory, error) {
+	var history []*StatusHistory
+	err := r.db.
+		Where("work_order_id = ?", id).
+		Order("changed_at, id").
+		Find(&history).
+		Error
+	return history, err
+}
+
+func (r *GormRepository) StatusBefore(id int64, status int) (int, error) {
+	var h StatusHistory
+	err := r.db.
+		Where("work_order_id = ? AND new_status_id = ? AND old_status_id IS NOT NULL", id, status).
+		Order("changed_at DESC, id DESC").
+		First(&h).
+		Error
+	if err != nil {
+		return 0, err
+	}
+	return *h.OldStatusID, nil
+}
+
+func (r *GormRepository) CreateComment(c *Comment) error {
+	return r.db.Create(c).Error
+}
+
+func (r *GormRepository) UpdateComment(c *Comment) error {
+	return r.db.Save(c).Error
+}
+
+func (r *GormRepository) DeleteComment(c *Comment) error {
+	return r.db.Delete(c).Error
+}
+
+func (r *GormRepository) GetComment(workOrderID, commentID int64) (*Comment, error) {
+	var c Comment
+	err := r.db.
+		Where("id = ? AND work_order_id = ?", commentID, workOrderID).
+		First(&c).
+		Error
+	if err != nil {
+		return nil, err
+	}
+	return &c, nil
+}
+
+func (r *GormRepository) ListComments(workOrderID int64) ([]*Comment, error) {
+	var comments []*Comment
+	err := r.db.
+		Where("work_order_id = ?", workOrderID).
+		Order("created_at, id").
+		Find(&comments).
+		Error
+	return comments, err
+}
+
+func (r *GormRepository) FindUserIDs(usernames []string) (map[string]int64, error) {
+	var rows []struct {
+		ID       int64
+		Username string
+	}
+	err := r.db.Table("users").
+		Select("id, LOWER(username) AS username").
+		Where("LOWER(username) IN ?", usernames).
+		Scan(&rows).
+		Error
+	if err != nil {
+		return nil, err
+	}
+
+	ids := make(map[string]int64, len(rows))
+	for _, row := range rows {
+		ids[row.Username] = row.ID
+	}
+	return ids, nil
+}
+
+func (r *GormRepository) RoutingSteps(wo *WorkOrder) ([]*RoutingStep, error) {
+	var steps []*RoutingStep
+
+	q := r.db.Table("product_stages ps").
+		Select("ps.stage_id, s.name, ps.stage_order, ps.expected_cycle_min").
+		Joins("JOIN stages s ON s.id = ps.stage_id").
+		Order("ps.stage_order")
+
+	if wo.RoutingVersionID != nil {
+		q = q.Where("ps.routing_version_id = ?", *wo.RoutingVersionID)
+	} else {
+		q = q.Where("ps.routing_version_id = (?)", r.db.Table("routing_versions").
+			Select("id").
+			Where("product_id = ? AND status = 'released'", wo.ProductID))
+	}
+
+	return steps, q.Scan(&steps).Error
+}
+
+func (r *GormRepository) ListExecutions(workOrderID int64) ([]*ExecutionRow, error) {
+	var rows []*ExecutionRow
+	err := r.db.Table("stage_execution se").
+		Select("se.product_instance_id, se.stage_id, se.start_time, se.end_time, se.superseded_by IS NOT NULL AS superseded").
+		Joins("JOIN product_instances pi ON pi.id = se.product_instance_id").
+		Where("pi.work_order_id = ?", workOrderID).
+		Order("se.start_time").
+		Scan(&rows).
+		Error
+	return rows, err
+}
+
+// scrappedInstance условие «экземпляр pi в браке»: списан или его последний результат контроля
+// браковочный и по нему не назначена доработка. Общее для прогресса и проверки выпуска заказа,
+// чтобы они считали годные экземпляры одинаково; EXISTS не дает NULL, поэтому условие можно отрицать.
+const scrappedInstance = `(
+	pi.scrapped_at IS NOT NULL
+	OR EXISTS (
+		SELECT 1
+		FROM (
+			SELECT pq.id, qs.code
+			FROM product_quality pq
+			JOIN quality_statuses qs ON qs.id = pq.quality_status_id
+			WHERE pq.product_instance_id = pi.id
+			ORDER BY pq.inspected_at DESC, pq.id DESC
+			LIMIT 1
+		) latest
+		WHERE latest.code = 'failed'
+		  AND NOT EXISTS (SELECT 1 FROM instance_reworks ir WHERE ir.inspection_id = latest.id)
+	)
+)`
+
+func (r *GormRepository) ScrappedInstances(workOrderID int64) ([]int64, error) {
+	var ids []int64
+	err := r.db.Table("product_instances pi").
+		Where("pi.work_order_id = ?", workOrderID).
+		Where(scrappedInstance).
+		Pluck("pi.id", &ids).
+		Error
+	return ids, err
+}
+
+func (r *GormRepository) YieldRows(workOrderID int64) ([]*YieldRow, error) {
+	var rows []*YieldRow
+	err := r.db.Raw(`
+		SELECT pi.id AS product_instance_id,
+			EXISTS (SELECT 1 FROM product_quality pq WHERE pq.product_instance_id = pi.id) AS inspected,
+			EXISTS (
+				SELECT 1 FROM product_quality pq
+				JOIN quality_statuses qs ON qs.id = pq.quality_status_id
+				WHERE pq.product_instance_id = pi.id AND qs.code = 'failed'
+			) AS failed,
+			(SELECT COUNT(*) FROM instance_reworks ir WHERE ir.product_instance_id = pi.id) AS reworks,
+			pi.scrapped_at IS NOT NULL AS scrapped,
+			pi.scrap_reason_id
+		FROM product_instances pi
+		WHERE pi.work_order_id = ?
+		ORDER BY pi.id`,
+		workOrderID,
+	).Scan(&rows).Error
+	return rows, err
+}
+
+func (r *GormRepository) ProductTechCycle(productID int64) (*int, error) {
+	var row struct{ TechCycleMin *int }
+	err := r.db.Table("products").
+		Select("tech_cycle_min").
+		Where("id = ?", productID).
+		Take(&row).
+		Error
+	return row.TechCycleMin, err
+}
+
+func (r *GormRepository) AllocateNumber(scope string) (int64, error) {
+	return numbering.Allocate(r.db, scope, 1)
+}
+
+func (r *GormRepository) ListPriorities() ([]*Priority, error) {
+	var priorities []*Priority
+	err := r.db.Order("weight, id").Find(&priorities).Error
+	return priorities, err
+}
+
+func (r *GormRepository) ListStatuses() ([]*Status, error) {
+	var statuses []*Status
+	err := r.db.Order("id").Find(&statuses).Error
+	return statuses, err
+}
+
+func (r *GormRepository) PriorityExists(id int) (bool, error) {
+	var n int64
+	err := r.db.Model(&Priority{}).Where("id = ?", id).Count(&n).Error
+	return n > 0, err
+}
+
+func (r *GormRepository) ProductExists(id int64) (bool, error) {
+	var n int64
+	err := r.db.Table("products").Where("id = ?", id).Count(&n).Error
+	return n > 0, err
+}
+
+func (r *GormRepository) MachineExists(id int64) (bool, error) {
+	var n int64
+	err := r.db.Table("machines").Where("id = ?", id).Count(&n).Error
+	return n > 0, err
+}
+
+func (r *GormRepository) CountInstances(id int64) (int64, error) {
+	var n int64
+	err := r.db.Table("product_instances").Where("work_order_id = ?", id).Count(&n).Error
+	return n, err
+}
+
+func (r *GormRepository) CountCompleted(id, finalStageID int64) (int64, error) {
+	var n int64
+	err := r.db.Table("product_instances pi").
+		Where("pi.work_order_id = ?", id).
+		Where("NOT "+scrappedInstance).
+		Where(`EXISTS (
+			SELECT 1 FROM stage_execution se
+			WHERE se.product_instance_id = pi.id
+			  AND se.stage_id = ?
+			  AND se.end_time IS NOT NULL
+			  AND se.superseded_by IS NULL
+		)`, finalStageID).
+		Count(&n).
+		Error
+	return n, err
+}
+
+func (r *GormRepository) CountOpenExecutions(id int64) (int64, error) {
+	var n int64
+	err := r.db.Table("stage_execution AS se").
+		Joins("JOIN product_instances pi ON pi.id = se.product_instance_id").
+		Where("pi.work_order_id = ? AND se.end_time IS NULL", id).
+		Count(&n).
+		Error
+	return n, err
+}
+
+func (r *GormRepository) CountReferences(id int64) (int64, error) {
+	var total int64
+
+	for _, table := range []string{"product_instances", "schedule", "incidents"} {
+		var n int64
+		if err := r.db.Table(table).
+			Where("work_order_id = ?", id).
+			Count(&n).Error; err != nil {
+			return 0, err
+		}
+		total += n
+	}
+
+	return total, nil
+}
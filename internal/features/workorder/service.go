@@ -0,0 +1,408 @@
+package workorder
+
+import (
+	"errors"
+	"slices"
+	"strings"
+	"time"
+
+	"mes-lite-back/internal/features/stage"
+	"mes-lite-back/internal/http/middleware"
+
+	"gorm.io/gorm"
+)
+
+var (
+	ErrNotFound         = errors.New("work order not found")
+	ErrProductNotFound  = errors.New("product not found")
+	ErrMachineNotFound  = errors.New("machine not found")
+	ErrInvalidPriority  = errors.New("unknown work order priority")
+	ErrInvalidQuantity  = errors.New("quantity must be positive")
+	ErrQuantityBelow    = errors.New("quantity is less than already produced instances")
+	ErrNumberTaken      = errors.New("work order number already exists")
+	ErrInvalidDateRange = errors.New("deadline_from is after deadline_to")
+	ErrClosed           = errors.New("completed or cancelled work order cannot be changed")
+	ErrProductLocked    = errors.New("product can only be changed on a planned work order")
+	ErrNotDeletable     = errors.New("only planned or cancelled work orders can be deleted")
+	ErrInUse            = errors.New("work order has instances, schedule slots or incidents")
+)
+
+// maxNumberAttempts ограничивает поиск свободного номера, если часть номеров занята вручную
+const maxNumberAttempts = 10
+
+// ServiceInterface определяет методы, используемые handler’ом
+type ServiceInterface interface {
+	CreateWorkOrder(wo *WorkOrder) error
+	GetWorkOrder(id int64) (*WorkOrder, error)
+	ListWorkOrders(filter ListFilter) ([]*WorkOrder, error)
+	UpdateWorkOrder(wo *WorkOrder) error
+	DeleteWorkOrder(id int64) error
+	ListPriorities() ([]*Priority, error)
+	ListStatuses() ([]*Status, error)
+
+	ChangeStatus(id int64, req TransitionRequest, userID int64) (*WorkOrder, error)
+	AvailableActions(id, userID int64) ([]*AvailableAction, error)
+	StatusHistory(id int64) ([]*StatusHistory, error)
+
+	ListComments(workOrderID int64) ([]*Comment, error)
+	AddComment(workOrderID, userID int64, message string) (*Comment, error)
+	EditComment(workOrderID, commentID, userID int64, message string) (*Comment, error)
+	DeleteComment(workOrderID, commentID, userID int64) error
+	Activity(workOrderID int64) ([]*ActivityEvent, error)
+
+	Progress(id int64) (*Progress, error)
+	Yield(id int64) (*Yield, error)
+
+	Split(id int64, req SplitRequest, userID int64) (*SplitResult, error)
+	Merge(id int64, req MergeRequest, userID int64) (*WorkOrder, error)
+	CompletePartial(id int64, req PartialCompleteRequest, userID int64) (*SplitResult, error)
+}
+
+// RoutingResolver действующая версия маршрута продукта, закрепляемая при выпуске заказа
+type RoutingResolver interface {
+	ActiveVersion(productID int64) (*stage.RoutingVersion, error)
+}
+
+type Service struct {
+	repo     Repository
+	pattern  *NumberPattern
+	routing  RoutingResolver
+	perms    middleware.PermissionChecker
+	notifier Notifier
+	now      func() time.Time
+}
+
+func NewService(
+	repo Repository,
+	pattern *NumberPattern,
+	routing RoutingResolver,
+	perms middleware.PermissionChecker,
+	notifier Notifier,
+) *Service {
+	return &Service{
+		repo:     repo,
+		pattern:  pattern,
+		routing:  routing,
+		perms:    perms,
+		notifier: notifier,
+		now:      time.Now,
+	}
+}
+
+// CreateWorkOrder создает заказ в статусе «Запланирован»; пустой номер генерируется по шаблону
+func (s *Service) CreateWorkOrder(wo *WorkOrder) error {
+	if wo.PriorityID == 0 {
+		wo.PriorityID = PriorityNormal
+	}
+	wo.StatusID = StatusPlanned
+	wo.RoutingVersionID = nil
+
+	if err := s.validate(wo); err != nil {
+		return err
+	}
+
+	wo.WONumber = strings.TrimSpace(wo.WONumber)
+	if wo.WONumber == "" {
+		number, err := s.nextNumber()
+		if err != nil {
+			return err
+		}
+		wo.WONumber = number
+	} else if err := s.checkNumber(wo.WONumber); err != nil {
+		return err
+	}
+
+	return s.repo.Create(wo, &StatusHistory{
+		NewStatusID: StatusPlanned,
+		Action:      ActionCreate,
+		ChangedBy:   wo.CreatedBy,
+	})
+}
+
+func (s *Service) GetWorkOrder(id int64) (*WorkOrder, error) {
+	wo, err := s.repo.GetByID(id)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrNotFound
+	}
+	return wo, err
+}
+
+func (s *Service) ListWorkOrders(filter ListFilter) ([]*WorkOrder, error) {
+	filter.Query = strings.TrimSpace(filter.Query)
+	if filter.DeadlineFrom != nil && filter.DeadlineTo != nil && filter.DeadlineFrom.After(*filter.DeadlineTo) {
+		return nil, ErrInvalidDateRange
+	}
+	return s.repo.List(filter)
+}
+
+// UpdateWorkOrder меняет параметры заказа; номер, статус, автор, связи разделения и слияния
+// и выполненное количество не редактируются
+func (s *Service) UpdateWorkOrder(wo *WorkOrder) error {
+	existing, err := s.GetWorkOrder(wo.ID)
+	if err != nil {
+		return err
+	}
+
+	if existing.StatusID == StatusCompleted || existing.StatusID == StatusCancelled {
+		return ErrClosed
+	}
+	if wo.ProductID != existing.ProductID && existing.StatusID != StatusPlanned {
+		return ErrProductLocked
+	}
+	if wo.PriorityID == 0 {
+		wo.PriorityID = existing.PriorityID
+	}
+
+	wo.WONumber = existing.WONumber
+	wo.StatusID = existing.StatusID
+	wo.RoutingVersionID = existing.RoutingVersionID
+	wo.ParentID = existing.ParentID
+	wo.MergedIntoID = existing.MergedIntoID
+	wo.CompletedQuantity = existing.CompletedQuantity
+	wo.CreatedBy = existing.CreatedBy
+	wo.CreatedAt = existing.CreatedAt
+
+	if err := s.validate(wo); err != nil {
+		return err
+	}
+
+	produced, err := s.repo.CountInstances(wo.ID)
+	if err != nil {
+		return err
+	}
+	if int64(wo.Quantity) < produced {
+		return ErrQuantityBelow
+	}
+
+	return s.repo.Update(wo)
+}
+
+// DeleteWorkOrder удаляет заказ, по которому еще ничего не произведено и не запланировано
+func (s *Service) DeleteWorkOrder(id int64) error {
+	wo, err := s.GetWorkOrder(id)
+	if err != nil {
+		return err
+	}
+
+	if wo.StatusID != StatusPlanned && wo.StatusID != StatusCancelled {
+		return ErrNotDeletable
+	}
+
+	refs, err := s.repo.CountReferences(id)
+	if err != nil {
+		return err
+	}
+	if refs > 0 {
+		return ErrInUse
+	}
+
+	return s.repo.Delete(wo)
+}
+
+// ChangeStatus выполняет действие workflow над заказом от имени пользователя userID
+func (s *Service) ChangeStatus(id int64, req TransitionRequest, userID int64) (*WorkOrder, error) {
+	t, ok := findTransition(strings.TrimSpace(req.Action))
+	if !ok {
+		return nil, ErrUnknownAction
+	}
+
+	wo, err := s.GetWorkOrder(id)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkFrom(t, wo.StatusID); err != nil {
+		return nil, err
+	}
+
+	allowed, err := s.perms.HasPermission(userID, t.Permission)
+	if err != nil {
+		return nil, err
+	}
+	if !allowed {
+		return nil, ErrForbidden
+	}
+
+	for _, g := range t.Guards {
+		if err := g.Check(s, wo); err != nil {
+			if isGuardReason(err) {
+				return nil, &GuardError{Action: t.Action, Guard: g.Name, Reason: err}
+			}
+			return nil, err
+		}
+	}
+
+	to, err := s.target(id, t)
+	if err != nil {
+		return nil, err
+	}
+
+	updated, err := s.repo.ChangeStatus(id, func(locked *WorkOrder) (*StatusHistory, error) {
+		// статус мог измениться параллельным запросом после проверок
+		if err := checkFrom(t, locked.StatusID); err != nil {
+			return nil, err
+		}
+		if t.Apply != nil {
+			if err := t.Apply(s, locked); err != nil {
+				return nil, err
+			}
+		}
+
+		from := locked.StatusID
+		locked.StatusID = to
+
+		return &StatusHistory{
+			OldStatusID: &from,
+			NewStatusID: to,
+			Action:      t.Action,
+			Comment:     strings.TrimSpace(req.Comment),
+			ChangedBy:   &userID,
+		}, nil
+	})
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrNotFound
+	}
+	if errors.Is(err, ErrNoReleasedRouting) {
+		return nil, &GuardError{Action: t.Action, Guard: guardRoutingReleased.Name, Reason: err}
+	}
+	return updated, err
+}
+
+// AvailableActions действия, допустимые из текущего статуса заказа, с отметкой о правах пользователя
+func (s *Service) AvailableActions(id, userID int64) ([]*AvailableAction, error) {
+	wo, err := s.GetWorkOrder(id)
+	if err != nil {
+		return nil, err
+	}
+
+	var actions []*AvailableAction
+	for _, t := range transitionsFrom(wo.StatusID) {
+		permitted, err := s.perms.HasPermission(userID, t.Permission)
+		if err != nil {
+			return nil, err
+		}
+
+		to, err := s.target(id, t)
+		if err != nil {
+			return nil, err
+		}
+
+		actions = append(actions, &AvailableAction{
+			Action:     t.Action,
+			ToStatusID: to,
+			Permission: t.Permission,
+			Permitted:  permitted,
+		})
+	}
+	return actions, nil
+}
+
+func (s *Service) StatusHistory(id int64) ([]*StatusHistory, error) {
+	if _, err := s.GetWorkOrder(id); err != nil {
+		return nil, err
+	}
+	return s.repo.ListHistory(id)
+}
+
+// target целевой статус перехода; resume возвращает заказ в статус до приостановки
+func (s *Service) target(id int64, t *Transition) (int, error) {
+	if t.To != statusResume {
+		return t.To, nil
+	}
+
+	to, err := s.repo.StatusBefore(id, StatusOnHold)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return StatusReleased, nil
+	}
+	return to, err
+}
+
+func checkFrom(t *Transition, statusID int) error {
+	if slices.Contains(t.From, statusID) {
+		return nil
+	}
+
+	var allowed []string
+	for _, other := range transitionsFrom(statusID) {
+		allowed = append(allowed, other.Action)
+	}
+	return &TransitionError{Action: t.Action, StatusID: statusID, Allowed: allowed}
+}
+
+func (s *Service) ListPriorities() ([]*Priority, error) {
+	return s.repo.ListPriorities()
+}
+
+func (s *Service) ListStatuses() ([]*Status, error) {
+	return s.repo.ListStatuses()
+}
+
+func (s *Service) validate(wo *WorkOrder) error {
+	if wo.Quantity <= 0 {
+		return ErrInvalidQuantity
+	}
+
+	ok, err := s.repo.ProductExists(wo.ProductID)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return ErrProductNotFound
+	}
+
+	if wo.MachineID != nil {
+		ok, err := s.repo.MachineExists(*wo.MachineID)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			return ErrMachineNotFound
+		}
+	}
+
+	ok, err = s.repo.PriorityExists(wo.PriorityID)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return ErrInvalidPriority
+	}
+
+	return nil
+}
+
+// nextNumber выдает номер по шаблону, пропуская номера, уже занятые вручную
+func (s *Service) nextNumber() (string, error) {
+	now := s.now()
+	scope := s.pattern.Scope(now)
+
+	for range maxNumberAttempts {
+		seq, err := s.repo.AllocateNumber(scope)
+		if err != nil {
+			return "", err
+		}
+
+		number, err := s.pattern.Format(now, seq)
+		if err != nil {
+			return "", err
+		}
+
+		if err := s.checkNumber(number); err == nil {
+			return number, nil
+		} else if !errors.Is(err, ErrNumberTaken) {
+			return "", err
+		}
+	}
+
+	return "", ErrNumberTaken
+}
+
+func (s *Service) checkNumber(number string) error {
+	_, err := s.repo.GetByNumber(number)
+	if err == nil {
+		return ErrNumberTaken
+	}
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil
+	}
+	return err
+}
@@ -0,0 +1,224 @@
+package workorder
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+
+	"mes-lite-back/internal/features/stage"
+)
+
+// Действия над статусом заказа
+const (
+	ActionCreate   = "create"
+	ActionRelease  = "release"
+	ActionStart    = "start"
+	ActionHold     = "hold"
+	ActionResume   = "resume"
+	ActionComplete = "complete"
+	ActionCancel   = "cancel"
+)
+
+// statusResume целевой статус resume: тот, из которого заказ был приостановлен
+const statusResume = 0
+
+var (
+	ErrUnknownAction     = errors.New("unknown work order action")
+	ErrInvalidTransition = errors.New("transition is not allowed from the current status")
+	ErrGuardFailed       = errors.New("transition condition is not met")
+	ErrForbidden         = errors.New("permission denied for transition")
+
+	ErrNoReleasedRouting = errors.New("product has no released routing")
+	ErrNoMachine         = errors.New("work order has no machine assigned")
+	ErrOpenExecutions    = errors.New("work order has unfinished stage executions")
+	ErrNotProduced       = errors.New("completed quantity is less than ordered")
+)
+
+// guardReasons причины отказа guard'ов; прочие ошибки проверки считаются внутренними
+var guardReasons = []error{ErrNoReleasedRouting, ErrNoMachine, ErrOpenExecutions, ErrNotProduced}
+
+func isGuardReason(err error) bool {
+	return slices.ContainsFunc(guardReasons, func(reason error) bool { return errors.Is(err, reason) })
+}
+
+// Guard условие перехода; Check возвращает причину отказа
+type Guard struct {
+	Name  string                                `json:"name"`
+	Check func(s *Service, wo *WorkOrder) error `json:"-"`
+}
+
+// Transition переход workflow: действие Action переводит заказ из любого статуса From в To,
+// если у пользователя есть право Permission и выполнены все Guards.
+// Apply дополняет заказ перед сохранением (например, закрепляет версию маршрута).
+type Transition struct {
+	Action     string                                `json:"action"`
+	From       []int                                 `json:"from"`
+	To         int                                   `json:"to"`
+	Permission string                                `json:"permission"`
+	Guards     []Guard                               `json:"guards"`
+	Apply      func(s *Service, wo *WorkOrder) error `json:"-"`
+}
+
+var (
+	guardRoutingReleased = Guard{Name: "routing_released", Check: (*Service).checkRoutingReleased}
+	guardMachineAssigned = Guard{Name: "machine_assigned", Check: (*Service).checkMachineAssigned}
+	guardNoOpenExecution = Guard{Name: "no_open_executions", Check: (*Service).checkNoOpenExecutions}
+	guardQuantityDone    = Guard{Name: "quantity_produced", Check: (*Service).checkQuantityProduced}
+)
+
+// Workflow статусов заказа:
+// planned → released → in_progress → completed, приостановка и возобновление released/in_progress,
+// отмена до завершения
+var Workflow = []Transition{
+	{
+		Action:     ActionRelease,
+		From:       []int{StatusPlanned},
+		To:         StatusReleased,
+		Permission: "order.status",
+		Guards:     []Guard{guardRoutingReleased},
+		Apply:      (*Service).pinRouting,
+	},
+	{
+		Action:     ActionStart,
+		From:       []int{StatusReleased},
+		To:         StatusInProgress,
+		Permission: "production.start",
+		Guards:     []Guard{guardMachineAssigned},
+	},
+	{
+		Action:     ActionHold,
+		From:       []int{StatusReleased, StatusInProgress},
+		To:         StatusOnHold,
+		Permission: "order.status",
+	},
+	{
+		Action:     ActionResume,
+		From:       []int{StatusOnHold},
+		To:         statusResume,
+		Permission: "order.status",
+	},
+	{
+		Action:     ActionComplete,
+		From:       []int{StatusInProgress},
+		To:         StatusCompleted,
+		Permission: "production.complete",
+		Guards:     []Guard{guardNoOpenExecution, guardQuantityDone},
+	},
+	{
+		Action:     ActionCancel,
+		From:       []int{StatusPlanned, StatusReleased, StatusInProgress, StatusOnHold},
+		To:         StatusCancelled,
+		Permission: "order.status",
+		Guards:     []Guard{guardNoOpenExecution},
+	},
+}
+
+// TransitionError действие недопустимо из текущего статуса
+type TransitionError struct {
+	Action   string
+	StatusID int
+	Allowed  []string
+}
+
+func (e *TransitionError) Error() string {
+	return fmt.Sprintf("%s: %s from status %d", ErrInvalidTransition, e.Action, e.StatusID)
+}
+
+func (e *TransitionError) Unwrap() error {
+	return ErrInvalidTransition
+}
+
+// GuardError не выполнено условие перехода
+type GuardError struct {
+	Action string
+	Guard  string
+	Reason error
+}
+
+func (e *GuardError) Error() string {
+	return fmt.Sprintf("%s: %s (%s)", ErrGuardFailed, e.Guard, e.Reason)
+}
+
+func (e *GuardError) Unwrap() []error {
+	return []error{ErrGuardFailed, e.Reason}
+}
+
+func findTransition(action string) (*Transition, bool) {
+	i := slices.IndexFunc(Workflow, func(t Transition) bool { return t.Action == action })
+	if i < 0 {
+		return nil, false
+	}
+	return &Workflow[i], true
+}
+
+// transitionsFrom действия, допустимые из статуса
+func transitionsFrom(statusID int) []*Transition {
+	var out []*Transition
+	for i := range Workflow {
+		if slices.Contains(Workflow[i].From, statusID) {
+			out = append(out, &Workflow[i])
+		}
+	}
+	return out
+}
+
+func (s *Service) checkRoutingReleased(wo *WorkOrder) error {
+	_, err := s.routing.ActiveVersion(wo.ProductID)
+	if errors.Is(err, stage.ErrVersionNotFound) {
+		return ErrNoReleasedRouting
+	}
+	return err
+}
+
+func (s *Service) checkMachineAssigned(wo *WorkOrder) error {
+	if wo.MachineID == nil {
+		return ErrNoMachine
+	}
+	return nil
+}
+
+func (s *Service) checkNoOpenExecutions(wo *WorkOrder) error {
+	n, err := s.repo.CountOpenExecutions(wo.ID)
+	if err != nil {
+		return err
+	}
+	if n > 0 {
+		return ErrOpenExecutions
+	}
+	return nil
+}
+
+// checkQuantityProduced готовыми считаются экземпляры не в браке, завершившие последний этап маршрута заказа;
+// брак определяется так же, как в прогрессе заказа
+func (s *Service) checkQuantityProduced(wo *WorkOrder) error {
+	steps, err := s.repo.RoutingSteps(wo)
+	if err != nil {
+		return err
+	}
+	if len(steps) == 0 {
+		return ErrNotProduced
+	}
+
+	n, err := s.repo.CountCompleted(wo.ID, steps[len(steps)-1].StageID)
+	if err != nil {
+		return err
+	}
+	if n < int64(wo.Quantity) {
+		return ErrNotProduced
+	}
+	return nil
+}
+
+// pinRouting закрепляет за заказом действующую версию маршрута:
+// последующие изменения маршрута не затрагивают выпущенный заказ
+func (s *Service) pinRouting(wo *WorkOrder) error {
+	v, err := s.routing.ActiveVersion(wo.ProductID)
+	if errors.Is(err, stage.ErrVersionNotFound) {
+		return ErrNoReleasedRouting
+	}
+	if err != nil {
+		return err
+	}
+	wo.RoutingVersionID = &v.ID
+	return nil
+}
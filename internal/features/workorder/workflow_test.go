@@ -0,0 +1,292 @@
+package workorder
+
+import (
+	"errors"
+	"slices"
+	"testing"
+	"time"
+
+	"mes-lite-back/internal/features/stage"
+
+	"gorm.io/gorm"
+)
+
+// fakeRepo хранилище заказа в памяти; методы, не нужные тестам, достаются встроенному nil-интерфейсу
+type fakeRepo struct {
+	Repository
+	wo         *WorkOrder
+	steps      []*RoutingStep
+	executions []*ExecutionRow
+	scrapped   []int64
+	instances  int64
+	techCycle  *int
+	completed  map[int64]int64
+	open       int64
+	before     int
+	history    []*StatusHistory
+}
+
+func (r *fakeRepo) GetByID(id int64) (*WorkOrder, error) {
+	if r.wo == nil || r.wo.ID != id {
+		return nil, gorm.ErrRecordNotFound
+	}
+	wo := *r.wo
+	return &wo, nil
+}
+
+func (r *fakeRepo) ChangeStatus(id int64, change func(wo *WorkOrder) (*StatusHistory, error)) (*WorkOrder, error) {
+	wo, err := r.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+	h, err := change(wo)
+	if err != nil {
+		return nil, err
+	}
+	r.wo = wo
+	r.history = append(r.history, h)
+	return wo, nil
+}
+
+func (r *fakeRepo) StatusBefore(int64, int) (int, error) {
+	if r.before == 0 {
+		return 0, gorm.ErrRecordNotFound
+	}
+	return r.before, nil
+}
+
+func (r *fakeRepo) RoutingSteps(*WorkOrder) ([]*RoutingStep, error) { return r.steps, nil }
+func (r *fakeRepo) ListExecutions(int64) ([]*ExecutionRow, error)   { return r.executions, nil }
+func (r *fakeRepo) ScrappedInstances(int64) ([]int64, error)        { return r.scrapped, nil }
+func (r *fakeRepo) CountInstances(int64) (int64, error)             { return r.instances, nil }
+func (r *fakeRepo) ProductTechCycle(int64) (*int, error)            { return r.techCycle, nil }
+func (r *fakeRepo) CountOpenExecutions(int64) (int64, error)        { return r.open, nil }
+func (r *fakeRepo) CountCompleted(_ int64, finalStageID int64) (int64, error) {
+	return r.completed[finalStageID], nil
+}
+
+type fakePerms map[string]bool
+
+func (p fakePerms) HasPermission(_ int64, code string) (bool, error) {
+	return p[code], nil
+}
+
+type fakeRouting struct {
+	version *stage.RoutingVersion
+}
+
+func (f fakeRouting) ActiveVersion(int64) (*stage.RoutingVersion, error) {
+	if f.version == nil {
+		return nil, stage.ErrVersionNotFound
+	}
+	return f.version, nil
+}
+
+var allPerms = fakePerms{"order.status": true, "production.start": true, "production.complete": true}
+
+func newTestService(repo *fakeRepo, perms fakePerms, routing fakeRouting) *Service {
+	s := NewService(repo, nil, routing, perms, nil)
+	s.now = func() time.Time { return time.Date(2026, 10, 5, 12, 0, 0, 0, time.UTC) }
+	return s
+}
+
+func TestCheckFrom(t *testing.T) {
+	tests := []struct {
+		action  string
+		status  int
+		allowed []string
+	}{
+		{ActionRelease, StatusPlanned, nil},
+		{ActionStart, StatusReleased, nil},
+		{ActionHold, StatusInProgress, nil},
+		{ActionResume, StatusOnHold, nil},
+		{ActionComplete, StatusInProgress, nil},
+		{ActionCancel, StatusOnHold, nil},
+		{ActionStart, StatusPlanned, []string{ActionRelease, ActionCancel}},
+		{ActionComplete, StatusReleased, []string{ActionStart, ActionHold, ActionCancel}},
+		{ActionRelease, StatusOnHold, []string{ActionResume, ActionCancel}},
+		{ActionCancel, StatusCompleted, nil},
+		{ActionHold, StatusCancelled, nil},
+	}
+	for _, tt := range tests {
+		tr, ok := findTransition(tt.action)
+		if !ok {
+			t.Fatalf("no transition %q", tt.action)
+		}
+		err := checkFrom(tr, tt.status)
+
+		wantOK := slices.Contains(tr.From, tt.status)
+		if wantOK {
+			if err != nil {
+				t.Errorf("%s from %d: %v", tt.action, tt.status, err)
+			}
+			continue
+		}
+
+		var te *TransitionError
+		if !errors.As(err, &te) || !errors.Is(err, ErrInvalidTransition) {
+			t.Errorf("%s from %d: err = %v, want TransitionError", tt.action, tt.status, err)
+			continue
+		}
+		if !slices.Equal(te.Allowed, tt.allowed) {
+			t.Errorf("%s from %d: allowed = %v, want %v", tt.action, tt.status, te.Allowed, tt.allowed)
+		}
+	}
+
+	if _, ok := findTransition("archive"); ok {
+		t.Errorf("unknown action found")
+	}
+}
+
+func TestChangeStatusGuards(t *testing.T) {
+	machine := int64(3)
+
+	tests := []struct {
+		name      string
+		wo        WorkOrder
+		repo      fakeRepo
+		routing   fakeRouting
+		perms     fakePerms
+		action    string
+		wantGuard string
+		wantErr   error
+		want      int
+	}{
+		{
+			name:      "выпуск без действующего маршрута",
+			wo:        WorkOrder{StatusID: StatusPlanned},
+			action:    ActionRelease,
+			wantGuard: "routing_released",
+			wantErr:   ErrNoReleasedRouting,
+		},
+		{
+			name:    "выпуск закрепляет версию маршрута",
+			wo:      WorkOrder{StatusID: StatusPlanned},
+			routing: fakeRouting{version: &stage.RoutingVersion{ID: 7}},
+			action:  ActionRelease,
+			want:    StatusReleased,
+		},
+		{
+			name:      "запуск без машины",
+			wo:        WorkOrder{StatusID: StatusReleased},
+			action:    ActionStart,
+			wantGuard: "machine_assigned",
+			wantErr:   ErrNoMachine,
+		},
+		{
+			name:   "запуск с машиной",
+			wo:     WorkOrder{StatusID: StatusReleased, MachineID: &machine},
+			action: ActionStart,
+			want:   StatusInProgress,
+		},
+		{
+			name:    "запуск без права",
+			wo:      WorkOrder{StatusID: StatusReleased, MachineID: &machine},
+			perms:   fakePerms{"order.status": true},
+			action:  ActionStart,
+			wantErr: ErrForbidden,
+		},
+		{
+			name:      "завершение с незакрытым этапом",
+			wo:        WorkOrder{StatusID: StatusInProgress, Quantity: 2},
+			repo:      fakeRepo{open: 1, steps: []*RoutingStep{{StageID: 1}}, completed: map[int64]int64{1: 2}},
+			action:    ActionComplete,
+			wantGuard: "no_open_executions",
+			wantErr:   ErrOpenExecutions,
+		},
+		{
+			name:      "завершение: готовых меньше заказанного",
+			wo:        WorkOrder{StatusID: StatusInProgress, Quantity: 3},
+			repo:      fakeRepo{steps: []*RoutingStep{{StageID: 1}, {StageID: 2}}, completed: map[int64]int64{1: 3, 2: 2}},
+			action:    ActionComplete,
+			wantGuard: "quantity_produced",
+			wantErr:   ErrNotProduced,
+		},
+		{
+			name:      "завершение без маршрута",
+			wo:        WorkOrder{StatusID: StatusInProgress, Quantity: 1},
+			action:    ActionComplete,
+			wantGuard: "quantity_produced",
+			wantErr:   ErrNotProduced,
+		},
+		{
+			name:   "завершение: последний этап пройден",
+			wo:     WorkOrder{StatusID: StatusInProgress, Quantity: 3},
+			repo:   fakeRepo{steps: []*RoutingStep{{StageID: 1}, {StageID: 2}}, completed: map[int64]int64{1: 1, 2: 3}},
+			action: ActionComplete,
+			want:   StatusCompleted,
+		},
+		{
+			name:      "отмена с незакрытым этапом",
+			wo:        WorkOrder{StatusID: StatusOnHold},
+			repo:      fakeRepo{open: 2},
+			action:    ActionCancel,
+			wantGuard: "no_open_executions",
+			wantErr:   ErrOpenExecutions,
+		},
+		{
+			name:   "возобновление в статус до приостановки",
+			wo:     WorkOrder{StatusID: StatusOnHold},
+			repo:   fakeRepo{before: StatusInProgress},
+			action: ActionResume,
+			want:   StatusInProgress,
+		},
+		{
+			name:   "возобновление без истории",
+			wo:     WorkOrder{StatusID: StatusOnHold},
+			action: ActionResume,
+			want:   StatusReleased,
+		},
+		{
+			name:    "неизвестное действие",
+			wo:      WorkOrder{StatusID: StatusPlanned},
+			action:  "archive",
+			wantErr: ErrUnknownAction,
+		},
+		{
+			name:    "недопустимый переход",
+			wo:      WorkOrder{StatusID: StatusCompleted},
+			action:  ActionCancel,
+			wantErr: ErrInvalidTransition,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := tt.repo
+			tt.wo.ID = 1
+			repo.wo = &tt.wo
+			perms := tt.perms
+			if perms == nil {
+				perms = allPerms
+			}
+			s := newTestService(&repo, perms, tt.routing)
+
+			wo, err := s.ChangeStatus(1, TransitionRequest{Action: tt.action}, 10)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("err = %v, want %v", err, tt.wantErr)
+				}
+				var ge *GuardError
+				if tt.wantGuard != "" && (!errors.As(err, &ge) || ge.Guard != tt.wantGuard) {
+					t.Errorf("err = %v, want guard %s", err, tt.wantGuard)
+				}
+				if len(repo.history) != 0 {
+					t.Errorf("status changed despite error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ChangeStatus: %v", err)
+			}
+			if wo.StatusID != tt.want {
+				t.Errorf("status = %d, want %d", wo.StatusID, tt.want)
+			}
+			if len(repo.history) != 1 || repo.history[0].Action != tt.action || *repo.history[0].OldStatusID != tt.wo.StatusID {
+				t.Errorf("history = %+v", repo.history)
+			}
+			if tt.routing.version != nil && (wo.RoutingVersionID == nil || *wo.RoutingVersionID != tt.routing.version.ID) {
+				t.Errorf("routing version is not pinned")
+			}
+		})
+	}
+}
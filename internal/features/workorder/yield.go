@@ -0,0 +1,59 @@
+package workorder
+
+import (
+	"math"
+	"slices"
+)
+
+// Yield считает выход годного заказа с первого предъявления, доработки и брак по причинам
+func (s *Service) Yield(id int64) (*Yield, error) {
+	wo, err := s.GetWorkOrder(id)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := s.repo.YieldRows(wo.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	y := &Yield{
+		WorkOrderID:   wo.ID,
+		Produced:      len(rows),
+		ScrapByReason: []*ScrapCount{},
+	}
+	byReason := make(map[int]*ScrapCount)
+
+	for _, row := range rows {
+		if row.Inspected || row.Scrapped {
+			y.Evaluated++
+			if !row.Failed && row.Reworks == 0 && !row.Scrapped {
+				y.FirstPass++
+			}
+		}
+		y.Reworks += row.Reworks
+		if row.Reworks > 0 {
+			y.Reworked++
+		}
+		if row.Scrapped {
+			y.Scrapped++
+			if row.ScrapReasonID != nil {
+				c := byReason[*row.ScrapReasonID]
+				if c == nil {
+					c = &ScrapCount{ReasonID: *row.ScrapReasonID}
+					byReason[c.ReasonID] = c
+					y.ScrapByReason = append(y.ScrapByReason, c)
+				}
+				c.Quantity++
+			}
+		}
+	}
+
+	slices.SortFunc(y.ScrapByReason, func(a, b *ScrapCount) int { return b.Quantity - a.Quantity })
+
+	if y.Evaluated > 0 {
+		fpy := math.Round(float64(y.FirstPass)/float64(y.Evaluated)*1000) / 10
+		y.FirstPassYield = &fpy
+	}
+	return y, nil
+}
@@ -0,0 +1,62 @@
+package middleware
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+)
+
+// PermissionChecker проверяет наличие разрешения у пользователя
+type PermissionChecker interface {
+	HasPermission(userID int64, code string) (bool, error)
+}
+
+// PermissionGuard пропускает запрос, только если у пользователя из JWT есть разрешение code.
+// Должен использоваться после AuthMiddleware.
+func PermissionGuard(checker PermissionChecker, code string) func(next http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			userID, ok := UserIDFromContext(r.Context())
+			if !ok {
+				http.Error(w, "no user", http.StatusUnauthorized)
+				return
+			}
+
+			allowed, err := checker.HasPermission(userID, code)
+			if err != nil {
+				slog.Error("PermissionGuard: permission check failed",
+					slog.Int64("user_id", userID),
+					slog.String("permission", code),
+					slog.Any("err", err))
+				http.Error(w, "internal error", http.StatusInternalServerError)
+				return
+			}
+
+			if !allowed {
+				slog.Warn("PermissionGuard: access denied",
+					slog.Int64("user_id", userID),
+					slog.String("permission", code))
+				http.Error(w, "forbidden", http.StatusForbidden)
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// UserIDFromContext возвращает ID пользователя, положенный AuthMiddleware
+func UserIDFromContext(ctx context.Context) (int64, bool) {
+	switch v := ctx.Value(UserIDKey).(type) {
+	case float64:
+		return int64(v), v > 0
+	case int64:
+		return v, v > 0
+	case json.Number:
+		id, err := v.Int64()
+		return id, err == nil && id > 0
+	default:
+		return 0, false
+	}
+}
@@ -0,0 +1,23 @@
+package numbering
+
+import "gorm.io/gorm"
+
+// Allocate атомарно резервирует n номеров в счетчике scope таблицы serial_counters и возвращает первый из них.
+// Upsert с RETURNING блокирует строку счетчика на время обновления, поэтому параллельные запросы
+// получают непересекающиеся диапазоны; db может быть открытой транзакцией.
+func Allocate(db *gorm.DB, scope string, n int) (int64, error) {
+	var last int64
+	err := db.Raw(`
+		INSERT INTO serial_counters (scope, last_value, updated_at)
+		VALUES (?, ?, NOW())
+		ON CONFLICT (scope) DO UPDATE
+		SET last_value = serial_counters.last_value + EXCLUDED.last_value,
+		    updated_at = NOW()
+		RETURNING last_value`,
+		scope, n,
+	).Scan(&last).Error
+	if err != nil {
+		return 0, err
+	}
+	return last - int64(n) + 1, nil
+}
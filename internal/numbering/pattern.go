@@ -0,0 +1,132 @@
+package numbering
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Токены, значения которых вычисляются при выдаче номера
+const (
+	TokenSeq   = "SEQ"
+	TokenCheck = "CHECK"
+)
+
+var (
+	ErrPatternNoSeq = errors.New("number pattern must contain exactly one {SEQ} token")
+	ErrPatternToken = errors.New("unknown token in number pattern")
+	ErrSeqOverflow  = errors.New("sequence exceeds pattern width")
+)
+
+var tokenPattern = regexp.MustCompile(`\{([A-Z]+)(?::(\d+))?\}`)
+
+// Pattern шаблон номера из токенов вида {NAME} и {SEQ:n}.
+// Постоянные токены (префикс, SKU, дата) подставляются из values; {SEQ:n} — порядковый номер
+// с дополнением нулями до n знаков, {CHECK} — контрольная цифра (Luhn mod 10) по цифрам остальной части
+type Pattern struct {
+	raw string
+}
+
+// Parse проверяет шаблон: допускаются только токены allowed, {SEQ} — ровно один
+func Parse(raw string, allowed ...string) (*Pattern, error) {
+	seqCount := 0
+	for _, m := range tokenPattern.FindAllStringSubmatch(raw, -1) {
+		if m[1] != TokenSeq && !slices.Contains(allowed, m[1]) {
+			return nil, fmt.Errorf("%w: %s", ErrPatternToken, m[0])
+		}
+		if m[1] == TokenSeq {
+			seqCount++
+		}
+	}
+	if seqCount != 1 {
+		return nil, ErrPatternNoSeq
+	}
+
+	return &Pattern{raw: raw}, nil
+}
+
+// DateValues значения токенов даты {YYYY}, {YY}, {MM}, {DD}
+func DateValues(at time.Time) map[string]string {
+	return map[string]string{
+		"YYYY": at.Format("2006"),
+		"YY":   at.Format("06"),
+		"MM":   at.Format("01"),
+		"DD":   at.Format("02"),
+	}
+}
+
+// Scope ключ счетчика: шаблон с подставленными values, где {SEQ} и {CHECK} остаются токенами.
+// Номера идут подряд внутри одного scope, например по SKU за день.
+func (p *Pattern) Scope(values map[string]string) string {
+	return p.render(values, func(name string, _ int) string {
+		return "{" + name + "}"
+	})
+}
+
+// Format собирает номер для порядкового номера seq
+func (p *Pattern) Format(values map[string]string, seq int64) (string, error) {
+	var overflow bool
+
+	body := p.render(values, func(name string, width int) string {
+		if name == TokenCheck {
+			return "{" + TokenCheck + "}"
+		}
+		s := strconv.FormatInt(seq, 10)
+		if width > 0 && len(s) > width {
+			overflow = true
+		}
+		if width > len(s) {
+			s = strings.Repeat("0", width-len(s)) + s
+		}
+		return s
+	})
+	if overflow {
+		return "", ErrSeqOverflow
+	}
+
+	check := "{" + TokenCheck + "}"
+	if !strings.Contains(body, check) {
+		return body, nil
+	}
+
+	digit := LuhnDigit(strings.ReplaceAll(body, check, ""))
+	return strings.ReplaceAll(body, check, strconv.Itoa(digit)), nil
+}
+
+// render подставляет постоянные токены из values, а {SEQ}/{CHECK} отдает в dynamic
+func (p *Pattern) render(values map[string]string, dynamic func(name string, width int) string) string {
+	return tokenPattern.ReplaceAllStringFunc(p.raw, func(tok string) string {
+		m := tokenPattern.FindStringSubmatch(tok)
+		if m[1] == TokenSeq || m[1] == TokenCheck {
+			width, _ := strconv.Atoi(m[2])
+			return dynamic(m[1], width)
+		}
+		return values[m[1]]
+	})
+}
+
+// LuhnDigit контрольная цифра Luhn по цифрам строки; прочие символы пропускаются
+func LuhnDigit(s string) int {
+	sum := 0
+	double := true
+	for i := len(s) - 1; i >= 0; i-- {
+		c := s[i]
+		if c < '0' || c > '9' {
+			continue
+		}
+		d := int(c - '0')
+		if double {
+			d *= 2
+			if d > 9 {
+				d -= 9
+			}
+		}
+		sum += d
+		double = !double
+	}
+	return (10 - sum%10) % 10
+}
@@ -0,0 +1,136 @@
+package numbering
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestLuhnDigit(t *testing.T) {
+	tests := []struct {
+		in   string
+		want int
+	}{
+		{"", 0},
+		{"0", 0},
+		{"7992739871", 3},
+		{"453914880343646", 7},
+		{"7-99 27/39_871", 3},
+		{"MES-GB01-260105-000042-", 2},
+	}
+	for _, tt := range tests {
+		if got := LuhnDigit(tt.in); got != tt.want {
+			t.Errorf("LuhnDigit(%q) = %d, want %d", tt.in, got, tt.want)
+		}
+	}
+}
+
+// TestLuhnDetectsSingleDigitErrors контрольная цифра меняется при замене любой одной цифры
+func TestLuhnDetectsSingleDigitErrors(t *testing.T) {
+	base := "260105000042"
+	want := LuhnDigit(base)
+	for i := range base {
+		for d := byte('0'); d <= '9'; d++ {
+			if d == base[i] {
+				continue
+			}
+			changed := base[:i] + string(d) + base[i+1:]
+			if LuhnDigit(changed) == want {
+				t.Errorf("LuhnDigit(%s) = LuhnDigit(%s)", changed, base)
+			}
+		}
+	}
+}
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		raw     string
+		wantErr error
+	}{
+		{"{PREFIX}-{SEQ:6}", nil},
+		{"{PREFIX}-{YYYY}-{SEQ}-{CHECK}", nil},
+		{"WO{SEQ:4}", nil},
+		{"{PREFIX}-{YYYY}", ErrPatternNoSeq},
+		{"{SEQ}-{SEQ}", ErrPatternNoSeq},
+		{"{PREFIX}-{SKU}-{SEQ}", ErrPatternToken},
+		{"{prefix}-{SEQ}", nil},
+	}
+	for _, tt := range tests {
+		_, err := Parse(tt.raw, "PREFIX", "YYYY", TokenCheck)
+		if !errors.Is(err, tt.wantErr) {
+			t.Errorf("Parse(%q): err = %v, want %v", tt.raw, err, tt.wantErr)
+		}
+	}
+}
+
+func TestPatternFormat(t *testing.T) {
+	at := time.Date(2026, 1, 5, 23, 0, 0, 0, time.UTC)
+	values := DateValues(at)
+	values["PREFIX"] = "MES"
+	values["SKU"] = "GB01"
+
+	tests := []struct {
+		raw       string
+		seq       int64
+		want      string
+		wantScope string
+		wantErr   error
+	}{
+		{"{PREFIX}-{SKU}-{YY}{MM}{DD}-{SEQ:6}-{CHECK}", 42, "MES-GB01-260105-000042-2", "MES-GB01-260105-{SEQ}-{CHECK}", nil},
+		{"{PREFIX}-{YYYY}-{SEQ:6}", 123, "MES-2026-000123", "MES-2026-{SEQ}", nil},
+		{"{PREFIX}-{SEQ:3}", 999, "MES-999", "MES-{SEQ}", nil},
+		{"{PREFIX}-{SEQ:3}", 1000, "", "MES-{SEQ}", ErrSeqOverflow},
+		{"{PREFIX}-{SEQ}", 1234567, "MES-1234567", "MES-{SEQ}", nil},
+		{"{CHECK}{SEQ:2}", 7, "507", "{CHECK}{SEQ}", nil},
+	}
+	for _, tt := range tests {
+		p, err := Parse(tt.raw, "PREFIX", "SKU", "YYYY", "YY", "MM", "DD", TokenCheck)
+		if err != nil {
+			t.Fatalf("Parse(%q): %v", tt.raw, err)
+		}
+		if got := p.Scope(values); got != tt.wantScope {
+			t.Errorf("Scope(%q) = %q, want %q", tt.raw, got, tt.wantScope)
+		}
+		got, err := p.Format(values, tt.seq)
+		if !errors.Is(err, tt.wantErr) || got != tt.want {
+			t.Errorf("Format(%q, %d) = %q, %v, want %q, %v", tt.raw, tt.seq, got, err, tt.want, tt.wantErr)
+		}
+	}
+}
+
+// luhnValid проверка полного кода по Luhn: каждая вторая цифра справа, начиная с предпоследней, удваивается
+func luhnValid(code string) bool {
+	sum, n := 0, 0
+	for i := len(code) - 1; i >= 0; i-- {
+		c := code[i]
+		if c < '0' || c > '9' {
+			continue
+		}
+		d := int(c - '0')
+		if n%2 == 1 {
+			d *= 2
+			if d > 9 {
+				d -= 9
+			}
+		}
+		sum += d
+		n++
+	}
+	return sum%10 == 0
+}
+
+func TestFormatCheckDigitValidates(t *testing.T) {
+	p, err := Parse("{PREFIX}-{SEQ:6}-{CHECK}", "PREFIX", TokenCheck)
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	for seq := int64(1); seq <= 200; seq++ {
+		code, err := p.Format(map[string]string{"PREFIX": "A1"}, seq)
+		if err != nil {
+			t.Fatalf("Format(%d): %v", seq, err)
+		}
+		if !luhnValid(code) {
+			t.Errorf("Format(%d) = %s fails the Luhn check", seq, code)
+		}
+	}
+}
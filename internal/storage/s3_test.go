@@ -0,0 +1,263 @@
+package storage
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+const (
+	testAccessKey = "minio"
+	testSecretKey = "minio123"
+	testRegion    = "us-east-1"
+	testBucket    = "mes-attachments"
+)
+
+// fakeS3 локальная замена S3 с path-style адресацией: проверяет подпись V4 и хеш тела,
+// хранит объекты в памяти
+type fakeS3 struct {
+	secret  string
+	mu      sync.Mutex
+	objects map[string]fakeObject
+}
+
+type fakeObject struct {
+	data        []byte
+	contentType string
+}
+
+func newFakeS3(t *testing.T) (*fakeS3, *httptest.Server) {
+	f := &fakeS3{secret: testSecretKey, objects: map[string]fakeObject{}}
+	srv := httptest.NewServer(f)
+	t.Cleanup(srv.Close)
+	return f, srv
+}
+
+func (f *fakeS3) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	body, _ := io.ReadAll(r.Body)
+
+	if msg := f.verify(r, body); msg != "" {
+		http.Error(w, msg, http.StatusForbidden)
+		return
+	}
+
+	prefix := "/" + testBucket + "/"
+	if !strings.HasPrefix(r.URL.Path, prefix) {
+		http.Error(w, "NoSuchBucket", http.StatusNotFound)
+		return
+	}
+	key := strings.TrimPrefix(r.URL.Path, prefix)
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	switch r.Method {
+	case http.MethodPut:
+		f.objects[key] = fakeObject{data: body, contentType: r.Header.Get("Content-Type")}
+	case http.MethodGet:
+		obj, ok := f.objects[key]
+		if !ok {
+			http.Error(w, "NoSuchKey", http.StatusNotFound)
+			return
+		}
+		w.Header().Set("Content-Type", obj.contentType)
+		w.Write(obj.data)
+	case http.MethodDelete:
+		if _, ok := f.objects[key]; !ok {
+			http.Error(w, "NoSuchKey", http.StatusNotFound)
+			return
+		}
+		delete(f.objects, key)
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		http.Error(w, "MethodNotAllowed", http.StatusMethodNotAllowed)
+	}
+}
+
+// verify независимо пересчитывает подпись запроса; пустая строка — подпись верна
+func (f *fakeS3) verify(r *http.Request, body []byte) string {
+	sum := sha256.Sum256(body)
+	payloadHash := hex.EncodeToString(sum[:])
+	if r.Header.Get("X-Amz-Content-Sha256") != payloadHash {
+		return "XAmzContentSHA256Mismatch"
+	}
+
+	amzDate := r.Header.Get("X-Amz-Date")
+	if len(amzDate) != len("20060102T150405Z") {
+		return "MissingDate"
+	}
+	scope := amzDate[:8] + "/" + testRegion + "/s3/aws4_request"
+
+	auth := r.Header.Get("Authorization")
+	wantPrefix := "AWS4-HMAC-SHA256 Credential=" + testAccessKey + "/" + scope +
+		", SignedHeaders=host;x-amz-content-sha256;x-amz-date, Signature="
+	if !strings.HasPrefix(auth, wantPrefix) {
+		return "AuthorizationHeaderMalformed"
+	}
+
+	// путь берется из строки запроса как есть: подписывается закодированный путь
+	rawPath, _, _ := strings.Cut(r.RequestURI, "?")
+	canonical := r.Method + "\n" + rawPath + "\n\n" +
+		"host:" + r.Host + "\n" +
+		"x-amz-content-sha256:" + payloadHash + "\n" +
+		"x-amz-date:" + amzDate + "\n\n" +
+		"host;x-amz-content-sha256;x-amz-date\n" +
+		payloadHash
+	canonicalHash := sha256.Sum256([]byte(canonical))
+	toSign := "AWS4-HMAC-SHA256\n" + amzDate + "\n" + scope + "\n" + hex.EncodeToString(canonicalHash[:])
+
+	mac := func(key []byte, data string) []byte {
+		h := hmac.New(sha256.New, key)
+		h.Write([]byte(data))
+		return h.Sum(nil)
+	}
+	key := mac([]byte("AWS4"+f.secret), amzDate[:8])
+	key = mac(key, testRegion)
+	key = mac(key, "s3")
+	key = mac(key, "aws4_request")
+
+	if strings.TrimPrefix(auth, wantPrefix) != hex.EncodeToString(mac(key, toSign)) {
+		return "SignatureDoesNotMatch"
+	}
+	return ""
+}
+
+func newTestS3(t *testing.T, endpoint, secret string) *S3 {
+	t.Helper()
+	s, err := NewS3(S3Config{
+		Endpoint:  endpoint,
+		Region:    testRegion,
+		Bucket:    testBucket,
+		AccessKey: testAccessKey,
+		SecretKey: secret,
+		PathStyle: true,
+	})
+	if err != nil {
+		t.Fatalf("NewS3: %v", err)
+	}
+	return s
+}
+
+func TestS3PutGetDelete(t *testing.T) {
+	fake, srv := newFakeS3(t)
+	s := newTestS3(t, srv.URL, testSecretKey)
+
+	key := "2026/01/отчет о браке 1.pdf"
+	data := []byte("%PDF-1.4 test")
+
+	if err := s.Put(key, data, "application/pdf"); err != nil {
+		t.Fatalf("Put: %v", err)
+	}
+	if got := fake.objects[key].contentType; got != "application/pdf" {
+		t.Errorf("stored content type = %q, want application/pdf", got)
+	}
+
+	rc, err := s.Get(key)
+	if err != nil {
+		t.Fatalf("Get: %v", err)
+	}
+	got, _ := io.ReadAll(rc)
+	rc.Close()
+	if !bytes.Equal(got, data) {
+		t.Errorf("Get = %q, want %q", got, data)
+	}
+
+	if err := s.Delete(key); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+	if _, err := s.Get(key); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Get after delete: err = %v, want ErrNotFound", err)
+	}
+	if err := s.Delete(key); err != nil {
+		t.Errorf("Delete of missing object: %v, want nil", err)
+	}
+}
+
+func TestS3EmptyObject(t *testing.T) {
+	_, srv := newFakeS3(t)
+	s := newTestS3(t, srv.URL, testSecretKey)
+
+	if err := s.Put("empty.txt", nil, "text/plain"); err != nil {
+		t.Fatalf("Put: %v", err)
+	}
+	rc, err := s.Get("empty.txt")
+	if err != nil {
+		t.Fatalf("Get: %v", err)
+	}
+	defer rc.Close()
+	if got, _ := io.ReadAll(rc); len(got) != 0 {
+		t.Errorf("Get = %q, want empty", got)
+	}
+}
+
+func TestS3WrongSecretRejected(t *testing.T) {
+	_, srv := newFakeS3(t)
+	s := newTestS3(t, srv.URL, "wrong-secret")
+
+	err := s.Put("a.txt", []byte("x"), "text/plain")
+	if err == nil || !strings.Contains(err.Error(), "SignatureDoesNotMatch") {
+		t.Fatalf("Put with wrong secret: err = %v, want SignatureDoesNotMatch", err)
+	}
+}
+
+func TestS3InvalidKey(t *testing.T) {
+	s := newTestS3(t, "http://minio:9000", testSecretKey)
+
+	for _, key := range []string{"", "/abs", "../escape", "a/../../b", `a\b`} {
+		if err := s.Put(key, nil, ""); !errors.Is(err, ErrInvalidKey) {
+			t.Errorf("Put(%q): err = %v, want ErrInvalidKey", key, err)
+		}
+	}
+}
+
+// TestS3SignatureVector сверяет подпись с эталоном, посчитанным отдельной реализацией SigV4
+func TestS3SignatureVector(t *testing.T) {
+	s := newTestS3(t, "http://minio:9000", testSecretKey)
+	s.now = func() time.Time { return time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC) }
+
+	req, err := s.request(http.MethodPut, "2026/01/отчет 1.pdf", []byte("hello"))
+	if err != nil {
+		t.Fatalf("request: %v", err)
+	}
+
+	if got, want := req.URL.EscapedPath(), "/mes-attachments/2026/01/%D0%BE%D1%82%D1%87%D0%B5%D1%82%201.pdf"; got != want {
+		t.Errorf("path = %s, want %s", got, want)
+	}
+	if got := req.Header.Get("X-Amz-Date"); got != "20260102T030405Z" {
+		t.Errorf("X-Amz-Date = %s", got)
+	}
+	want := "AWS4-HMAC-SHA256 Credential=minio/20260102/us-east-1/s3/aws4_request, " +
+		"SignedHeaders=host;x-amz-content-sha256;x-amz-date, " +
+		"Signature=9b4bcea1a17a78b76000bce8050161d10667027283615b59da620064dcb9ecba"
+	if got := req.Header.Get("Authorization"); got != want {
+		t.Errorf("Authorization =\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestS3VirtualHostedStyle(t *testing.T) {
+	s, err := NewS3(S3Config{
+		Endpoint: "https://s3.eu-central-1.amazonaws.com",
+		Region:   "eu-central-1",
+		Bucket:   testBucket,
+	})
+	if err != nil {
+		t.Fatalf("NewS3: %v", err)
+	}
+
+	req, err := s.request(http.MethodGet, "a/b.png", nil)
+	if err != nil {
+		t.Fatalf("request: %v", err)
+	}
+	if got, want := req.URL.String(), "https://mes-attachments.s3.eu-central-1.amazonaws.com/a/b.png"; got != want {
+		t.Errorf("URL = %s, want %s", got, want)
+	}
+}
@@ -0,0 +1,57 @@
+package storage
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"strings"
+)
+
+// Драйверы хранилища
+const (
+	DriverLocal = "local"
+	DriverS3    = "s3"
+)
+
+var (
+	ErrNotFound   = errors.New("object not found")
+	ErrInvalidKey = errors.New("invalid object key")
+)
+
+// Storage хранилище файлов; ключ — относительный путь с разделителем /
+type Storage interface {
+	Put(key string, data []byte, contentType string) error
+	Get(key string) (io.ReadCloser, error)
+	Delete(key string) error
+}
+
+type Config struct {
+	Driver   string
+	LocalDir string
+	S3       S3Config
+}
+
+// New создает хранилище выбранного драйвера; по умолчанию — локальный каталог
+func New(cfg Config) (Storage, error) {
+	switch cfg.Driver {
+	case "", DriverLocal:
+		return NewLocal(cfg.LocalDir)
+	case DriverS3:
+		return NewS3(cfg.S3)
+	default:
+		return nil, fmt.Errorf("unknown storage driver %q", cfg.Driver)
+	}
+}
+
+// cleanKey отбрасывает ключи, выходящие за пределы хранилища
+func cleanKey(key string) (string, error) {
+	if key == "" || strings.HasPrefix(key, "/") || strings.Contains(key, "\\") {
+		return "", ErrInvalidKey
+	}
+	clean := path.Clean(key)
+	if clean != key || clean == "." || clean == ".." || strings.HasPrefix(clean, "../") {
+		return "", ErrInvalidKey
+	}
+	return clean, nil
+}
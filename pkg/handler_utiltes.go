@@ -19,3 +19,8 @@ func ParamID(r *http.Request) int64 {
 	id, _ := strconv.ParseInt(idStr, 10, 64)
 	return id
 }
+
+func ParamInt64(r *http.Request, name string) int64 {
+	v, _ := strconv.ParseInt(chi.URLParam(r, name), 10, 64)
+	return v
+}
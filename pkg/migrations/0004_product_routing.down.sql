@@ -0,0 +1,7 @@
+DROP INDEX IF EXISTS idx_product_stages_product;
+
+ALTER TABLE product_stages
+    DROP CONSTRAINT IF EXISTS uq_product_stages_order;
+
+ALTER TABLE product_stages
+    DROP COLUMN IF EXISTS expected_cycle_min;
@@ -0,0 +1,11 @@
+-- =========================
+-- МАРШРУТЫ ПРОДУКЦИИ
+-- =========================
+ALTER TABLE product_stages
+    ADD COLUMN expected_cycle_min INTEGER;
+
+-- порядок этапов внутри маршрута продукта уникален
+ALTER TABLE product_stages
+    ADD CONSTRAINT uq_product_stages_order UNIQUE (product_id, stage_order);
+
+CREATE INDEX idx_product_stages_product ON product_stages(product_id, stage_order);
@@ -0,0 +1,21 @@
+ALTER TABLE work_orders
+    DROP CONSTRAINT IF EXISTS fk_work_orders_routing_versions,
+    DROP COLUMN IF EXISTS routing_version_id;
+
+-- оставляем только действующие версии, иначе старые уникальные ключи не восстановить
+DELETE FROM product_stages ps
+USING routing_versions rv
+WHERE rv.id = ps.routing_version_id
+  AND rv.status <> 'released';
+
+ALTER TABLE product_stages
+    DROP CONSTRAINT IF EXISTS uq_product_stages_version_order,
+    DROP CONSTRAINT IF EXISTS uq_product_stages_version_stage,
+    DROP CONSTRAINT IF EXISTS fk_product_stages_routing_versions,
+    DROP COLUMN IF EXISTS routing_version_id;
+
+ALTER TABLE product_stages
+    ADD CONSTRAINT product_stages_product_id_stage_id_key UNIQUE (product_id, stage_id),
+    ADD CONSTRAINT uq_product_stages_order UNIQUE (product_id, stage_order);
+
+DROP TABLE IF EXISTS routing_versions;
@@ -0,0 +1,53 @@
+-- =========================
+-- ВЕРСИИ МАРШРУТОВ
+-- =========================
+CREATE TABLE routing_versions (
+    id BIGSERIAL PRIMARY KEY,
+    product_id BIGINT NOT NULL,
+    version INTEGER NOT NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'draft',
+    created_by BIGINT,
+    created_at TIMESTAMP DEFAULT NOW(),
+    approved_by BIGINT,
+    released_at TIMESTAMP,
+    obsoleted_at TIMESTAMP,
+    CONSTRAINT fk_routing_versions_products FOREIGN KEY(product_id) REFERENCES products(id),
+    CONSTRAINT fk_routing_versions_created_by FOREIGN KEY(created_by) REFERENCES users(id),
+    CONSTRAINT fk_routing_versions_approved_by FOREIGN KEY(approved_by) REFERENCES users(id),
+    CONSTRAINT chk_routing_versions_status CHECK (status IN ('draft', 'released', 'obsolete')),
+    UNIQUE(product_id, version)
+);
+
+-- у продукта не больше одного черновика и одной действующей версии
+CREATE UNIQUE INDEX uq_routing_versions_draft ON routing_versions(product_id) WHERE status = 'draft';
+CREATE UNIQUE INDEX uq_routing_versions_released ON routing_versions(product_id) WHERE status = 'released';
+
+-- существующие маршруты становятся выпущенной версией 1
+INSERT INTO routing_versions (product_id, version, status, released_at)
+SELECT DISTINCT product_id, 1, 'released', NOW()
+FROM product_stages
+WHERE product_id IS NOT NULL;
+
+ALTER TABLE product_stages
+    ADD COLUMN routing_version_id BIGINT,
+    ADD CONSTRAINT fk_product_stages_routing_versions FOREIGN KEY(routing_version_id) REFERENCES routing_versions(id) ON DELETE CASCADE;
+
+UPDATE product_stages ps
+SET routing_version_id = rv.id
+FROM routing_versions rv
+WHERE rv.product_id = ps.product_id;
+
+-- уникальность этапов и порядка теперь в пределах версии
+ALTER TABLE product_stages DROP CONSTRAINT IF EXISTS uq_product_stages_order;
+ALTER TABLE product_stages DROP CONSTRAINT IF EXISTS product_stages_product_id_stage_id_key;
+
+ALTER TABLE product_stages
+    ADD CONSTRAINT uq_product_stages_version_stage UNIQUE (routing_version_id, stage_id),
+    ADD CONSTRAINT uq_product_stages_version_order UNIQUE (routing_version_id, stage_order);
+
+-- =========================
+-- ЗАКАЗ ЗАКРЕПЛЯЕТСЯ ЗА ВЕРСИЕЙ МАРШРУТА ПРИ ВЫПУСКЕ
+-- =========================
+ALTER TABLE work_orders
+    ADD COLUMN routing_version_id BIGINT,
+    ADD CONSTRAINT fk_work_orders_routing_versions FOREIGN KEY(routing_version_id) REFERENCES routing_versions(id);
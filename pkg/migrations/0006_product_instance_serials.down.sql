@@ -0,0 +1,7 @@
+DROP INDEX IF EXISTS idx_product_instances_work_order;
+
+ALTER TABLE product_instances
+    DROP CONSTRAINT IF EXISTS fk_product_instances_work_orders,
+    DROP COLUMN IF EXISTS work_order_id;
+
+DROP TABLE IF EXISTS serial_counters;
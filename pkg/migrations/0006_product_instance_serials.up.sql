@@ -0,0 +1,19 @@
+-- =========================
+-- СЧЕТЧИКИ СЕРИЙНЫХ НОМЕРОВ
+-- =========================
+-- scope — шаблон штрихкода с подставленными значениями, кроме порядкового номера,
+-- поэтому нумерация ведется отдельно по каждому префиксу/SKU/дате
+CREATE TABLE serial_counters (
+    scope VARCHAR PRIMARY KEY,
+    last_value BIGINT NOT NULL DEFAULT 0,
+    updated_at TIMESTAMP DEFAULT NOW()
+);
+
+-- =========================
+-- ЭКЗЕМПЛЯРЫ ПРИВЯЗЫВАЮТСЯ К ЗАКАЗУ
+-- =========================
+ALTER TABLE product_instances
+    ADD COLUMN work_order_id BIGINT,
+    ADD CONSTRAINT fk_product_instances_work_orders FOREIGN KEY(work_order_id) REFERENCES work_orders(id);
+
+CREATE INDEX idx_product_instances_work_order ON product_instances(work_order_id);
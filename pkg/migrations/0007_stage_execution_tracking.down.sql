@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_stage_execution_instance_stage;
+DROP INDEX IF EXISTS uq_stage_execution_open;
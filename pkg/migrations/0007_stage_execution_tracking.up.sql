@@ -0,0 +1,7 @@
+-- =========================
+-- ИСПОЛНЕНИЕ ЭТАПОВ
+-- =========================
+-- у экземпляра не может быть двух незавершенных выполнений одновременно
+CREATE UNIQUE INDEX uq_stage_execution_open ON stage_execution(product_instance_id) WHERE end_time IS NULL;
+
+CREATE INDEX idx_stage_execution_instance_stage ON stage_execution(product_instance_id, stage_id);
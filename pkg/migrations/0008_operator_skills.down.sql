@@ -0,0 +1,13 @@
+DELETE FROM permissions WHERE code = 'stage.override';
+
+DROP TABLE IF EXISTS stage_execution_overrides;
+
+ALTER TABLE user_stages
+    DROP CONSTRAINT IF EXISTS uq_user_stages_user_stage,
+    DROP CONSTRAINT IF EXISTS chk_user_stages_level,
+    DROP CONSTRAINT IF EXISTS fk_user_stages_trainer,
+    DROP COLUMN IF EXISTS created_at,
+    DROP COLUMN IF EXISTS signed_off_at,
+    DROP COLUMN IF EXISTS trainer_id,
+    DROP COLUMN IF EXISTS certified_until,
+    DROP COLUMN IF EXISTS qualification_level;
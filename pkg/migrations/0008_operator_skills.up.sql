@@ -0,0 +1,42 @@
+-- =========================
+-- МАТРИЦА КВАЛИФИКАЦИЙ (ПОЛЬЗОВАТЕЛИ → ЭТАПЫ)
+-- =========================
+-- уровни: 1 — ученик, 2 — квалифицированный оператор, 3 — наставник
+DELETE FROM user_stages a
+USING user_stages b
+WHERE a.user_id = b.user_id
+  AND a.stage_id = b.stage_id
+  AND a.id > b.id;
+
+ALTER TABLE user_stages
+    ADD COLUMN qualification_level SMALLINT NOT NULL DEFAULT 2,
+    ADD COLUMN certified_until DATE,
+    ADD COLUMN trainer_id BIGINT,
+    ADD COLUMN signed_off_at TIMESTAMP,
+    ADD COLUMN created_at TIMESTAMP DEFAULT NOW(),
+    ADD CONSTRAINT fk_user_stages_trainer FOREIGN KEY(trainer_id) REFERENCES users(id),
+    ADD CONSTRAINT chk_user_stages_level CHECK (qualification_level BETWEEN 1 AND 3),
+    ADD CONSTRAINT uq_user_stages_user_stage UNIQUE (user_id, stage_id);
+
+-- уже существующие связи считаем подтвержденными на момент миграции
+UPDATE user_stages SET signed_off_at = NOW() WHERE signed_off_at IS NULL;
+
+-- =========================
+-- ДОПУСК К ЭТАПУ ПОД ОТВЕТСТВЕННОСТЬ МАСТЕРА
+-- =========================
+CREATE TABLE stage_execution_overrides (
+    id BIGSERIAL PRIMARY KEY,
+    execution_id BIGINT NOT NULL,
+    supervisor_id BIGINT NOT NULL,
+    reason TEXT,
+    created_at TIMESTAMP DEFAULT NOW(),
+    CONSTRAINT fk_overrides_executions FOREIGN KEY(execution_id) REFERENCES stage_execution(id) ON DELETE CASCADE,
+    CONSTRAINT fk_overrides_users FOREIGN KEY(supervisor_id) REFERENCES users(id)
+);
+
+INSERT INTO permissions (code, name, description, category) VALUES
+('stage.override', 'Допуск без квалификации', 'Разрешение на выполнение этапа оператором без действующей квалификации', 'Производство')
+ON CONFLICT (code) DO NOTHING;
+
+SELECT assign_role_permissions('Администратор', ARRAY['stage.override']);
+SELECT assign_role_permissions('Менеджер', ARRAY['stage.override']);
@@ -0,0 +1,14 @@
+DROP INDEX IF EXISTS idx_work_orders_deadline;
+DROP INDEX IF EXISTS idx_work_orders_machine;
+DROP INDEX IF EXISTS idx_work_orders_status;
+
+ALTER TABLE work_orders
+    DROP CONSTRAINT IF EXISTS chk_work_orders_quantity,
+    DROP COLUMN IF EXISTS updated_at;
+
+-- строки справочников остаются: на них могут ссылаться заказы
+ALTER TABLE order_statuses DROP COLUMN IF EXISTS code;
+
+ALTER TABLE order_priorities
+    DROP COLUMN IF EXISTS weight,
+    DROP COLUMN IF EXISTS code;
@@ -0,0 +1,41 @@
+-- =========================
+-- СПРАВОЧНИКИ ЗАКАЗОВ
+-- =========================
+-- code — стабильный идентификатор для кода, weight — порядок срочности (больше — срочнее)
+ALTER TABLE order_priorities
+    ADD COLUMN code VARCHAR UNIQUE,
+    ADD COLUMN weight INT NOT NULL DEFAULT 0;
+
+INSERT INTO order_priorities (id, code, name, weight) VALUES
+(1, 'low', 'Низкий', 10),
+(2, 'normal', 'Обычный', 20),
+(3, 'high', 'Высокий', 30),
+(4, 'urgent', 'Срочный', 40)
+ON CONFLICT (id) DO UPDATE SET code = EXCLUDED.code, weight = EXCLUDED.weight;
+
+SELECT setval(pg_get_serial_sequence('order_priorities', 'id'), (SELECT MAX(id) FROM order_priorities));
+
+ALTER TABLE order_statuses
+    ADD COLUMN code VARCHAR UNIQUE;
+
+INSERT INTO order_statuses (id, code, name) VALUES
+(1, 'planned', 'Запланирован'),
+(2, 'released', 'Выпущен в производство'),
+(3, 'in_progress', 'В работе'),
+(4, 'on_hold', 'Приостановлен'),
+(5, 'completed', 'Завершен'),
+(6, 'cancelled', 'Отменен')
+ON CONFLICT (id) DO UPDATE SET code = EXCLUDED.code;
+
+SELECT setval(pg_get_serial_sequence('order_statuses', 'id'), (SELECT MAX(id) FROM order_statuses));
+
+-- =========================
+-- РАБОЧИЕ ЗАКАЗЫ
+-- =========================
+ALTER TABLE work_orders
+    ADD COLUMN updated_at TIMESTAMP DEFAULT NOW(),
+    ADD CONSTRAINT chk_work_orders_quantity CHECK (quantity > 0);
+
+CREATE INDEX idx_work_orders_status ON work_orders(status_id);
+CREATE INDEX idx_work_orders_machine ON work_orders(machine_id);
+CREATE INDEX idx_work_orders_deadline ON work_orders(deadline);
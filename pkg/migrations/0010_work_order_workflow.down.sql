@@ -0,0 +1,5 @@
+DROP INDEX IF EXISTS idx_work_order_status_history_order;
+
+ALTER TABLE work_order_status_history
+    DROP COLUMN IF EXISTS comment,
+    DROP COLUMN IF EXISTS action;
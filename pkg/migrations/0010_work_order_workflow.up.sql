@@ -0,0 +1,8 @@
+-- =========================
+-- ИСТОРИЯ СТАТУСОВ: КОММЕНТАРИЙ И ДЕЙСТВИЕ
+-- =========================
+ALTER TABLE work_order_status_history
+    ADD COLUMN action VARCHAR,
+    ADD COLUMN comment TEXT;
+
+CREATE INDEX idx_work_order_status_history_order ON work_order_status_history(work_order_id, changed_at);
@@ -0,0 +1,6 @@
+DROP TABLE IF EXISTS notifications;
+
+DROP INDEX IF EXISTS idx_work_order_comments_order;
+
+ALTER TABLE work_order_comments
+    DROP COLUMN IF EXISTS edited_at;
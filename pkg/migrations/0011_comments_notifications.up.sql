@@ -0,0 +1,28 @@
+-- =========================
+-- КОММЕНТАРИИ К ЗАКАЗАМ: РЕДАКТИРОВАНИЕ
+-- =========================
+ALTER TABLE work_order_comments
+    ADD COLUMN edited_at TIMESTAMP;
+
+CREATE INDEX idx_work_order_comments_order ON work_order_comments(work_order_id, created_at);
+
+-- =========================
+-- УВЕДОМЛЕНИЯ ПОЛЬЗОВАТЕЛЕЙ
+-- =========================
+-- kind — тип события (mention, ...), entity_type/entity_id — объект, к которому относится уведомление
+CREATE TABLE notifications (
+    id BIGSERIAL PRIMARY KEY,
+    user_id BIGINT NOT NULL,
+    kind VARCHAR NOT NULL,
+    title VARCHAR NOT NULL,
+    body TEXT,
+    entity_type VARCHAR,
+    entity_id BIGINT,
+    created_by BIGINT,
+    created_at TIMESTAMP DEFAULT NOW(),
+    read_at TIMESTAMP,
+    CONSTRAINT fk_notifications_users FOREIGN KEY(user_id) REFERENCES users(id) ON DELETE CASCADE,
+    CONSTRAINT fk_notifications_created_by FOREIGN KEY(created_by) REFERENCES users(id)
+);
+
+CREATE INDEX idx_notifications_user_unread ON notifications(user_id, created_at) WHERE read_at IS NULL;
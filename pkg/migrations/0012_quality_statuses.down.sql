@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS idx_product_quality_instance;
+
+-- строки справочника остаются: на них могут ссылаться результаты контроля
+ALTER TABLE quality_statuses DROP COLUMN IF EXISTS code;
@@ -0,0 +1,16 @@
+-- =========================
+-- СТАТУСЫ КОНТРОЛЯ КАЧЕСТВА
+-- =========================
+-- failed — брак: экземпляр с последним результатом failed не засчитывается в годные
+ALTER TABLE quality_statuses
+    ADD COLUMN code VARCHAR UNIQUE;
+
+INSERT INTO quality_statuses (id, code, name) VALUES
+(1, 'passed', 'Годен'),
+(2, 'failed', 'Брак'),
+(3, 'conditional', 'Условно годен')
+ON CONFLICT (id) DO UPDATE SET code = EXCLUDED.code;
+
+SELECT setval(pg_get_serial_sequence('quality_statuses', 'id'), (SELECT MAX(id) FROM quality_statuses));
+
+CREATE INDEX idx_product_quality_instance ON product_quality(product_instance_id, inspected_at);
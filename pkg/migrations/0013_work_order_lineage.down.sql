@@ -0,0 +1,8 @@
+DROP INDEX IF EXISTS idx_work_orders_parent;
+
+ALTER TABLE work_orders
+    DROP CONSTRAINT IF EXISTS fk_work_orders_merged_into,
+    DROP CONSTRAINT IF EXISTS fk_work_orders_parent,
+    DROP COLUMN IF EXISTS completed_quantity,
+    DROP COLUMN IF EXISTS merged_into_id,
+    DROP COLUMN IF EXISTS parent_id;
@@ -0,0 +1,13 @@
+-- =========================
+-- РАЗДЕЛЕНИЕ И ОБЪЕДИНЕНИЕ ЗАКАЗОВ
+-- =========================
+-- parent_id — заказ, из которого выделен данный; merged_into_id — заказ, в который влит данный;
+-- completed_quantity — фактически сданное количество при частичном завершении
+ALTER TABLE work_orders
+    ADD COLUMN parent_id BIGINT,
+    ADD COLUMN merged_into_id BIGINT,
+    ADD COLUMN completed_quantity INTEGER,
+    ADD CONSTRAINT fk_work_orders_parent FOREIGN KEY(parent_id) REFERENCES work_orders(id),
+    ADD CONSTRAINT fk_work_orders_merged_into FOREIGN KEY(merged_into_id) REFERENCES work_orders(id);
+
+CREATE INDEX idx_work_orders_parent ON work_orders(parent_id);
@@ -0,0 +1,14 @@
+DROP INDEX IF EXISTS idx_schedule_period;
+DROP INDEX IF EXISTS idx_schedule_work_order;
+
+ALTER TABLE schedule
+    DROP CONSTRAINT IF EXISTS excl_schedule_machine_overlap,
+    DROP CONSTRAINT IF EXISTS chk_schedule_period,
+    DROP CONSTRAINT IF EXISTS fk_schedule_users,
+    DROP COLUMN IF EXISTS updated_at,
+    DROP COLUMN IF EXISTS created_at,
+    DROP COLUMN IF EXISTS created_by,
+    ALTER COLUMN machine_id DROP NOT NULL,
+    ALTER COLUMN work_order_id DROP NOT NULL;
+
+-- расширение btree_gist не удаляется: его могут использовать другие объекты
@@ -0,0 +1,22 @@
+-- =========================
+-- ПРОИЗВОДСТВЕННОЕ РАСПИСАНИЕ
+-- =========================
+-- btree_gist нужен для ограничения исключения по равенству machine_id
+CREATE EXTENSION IF NOT EXISTS btree_gist;
+
+ALTER TABLE schedule
+    ALTER COLUMN work_order_id SET NOT NULL,
+    ALTER COLUMN machine_id SET NOT NULL,
+    ADD COLUMN created_by BIGINT,
+    ADD COLUMN created_at TIMESTAMP DEFAULT NOW(),
+    ADD COLUMN updated_at TIMESTAMP DEFAULT NOW(),
+    ADD CONSTRAINT fk_schedule_users FOREIGN KEY(created_by) REFERENCES users(id),
+    ADD CONSTRAINT chk_schedule_period CHECK (end_time > start_time);
+
+-- слоты одной машины не пересекаются; интервал полуоткрытый, слоты встык допустимы
+ALTER TABLE schedule
+    ADD CONSTRAINT excl_schedule_machine_overlap
+    EXCLUDE USING gist (machine_id WITH =, tsrange(start_time, end_time) WITH &&);
+
+CREATE INDEX idx_schedule_work_order ON schedule(work_order_id);
+CREATE INDEX idx_schedule_period ON schedule(start_time, end_time);
@@ -0,0 +1,4 @@
+DROP TABLE IF EXISTS product_machines;
+
+-- строки справочника остаются: на них могут ссылаться машины
+ALTER TABLE machine_statuses DROP COLUMN IF EXISTS code;
@@ -0,0 +1,31 @@
+-- =========================
+-- СТАТУСЫ МАШИН
+-- =========================
+-- down, maintenance и offline — машина недоступна для планирования
+ALTER TABLE machine_statuses
+    ADD COLUMN code VARCHAR UNIQUE;
+
+INSERT INTO machine_statuses (id, code, name) VALUES
+(1, 'idle', 'Свободна'),
+(2, 'running', 'В работе'),
+(3, 'setup', 'Наладка'),
+(4, 'down', 'Неисправна'),
+(5, 'maintenance', 'Обслуживание'),
+(6, 'offline', 'Выключена')
+ON CONFLICT (id) DO UPDATE SET code = EXCLUDED.code;
+
+SELECT setval(pg_get_serial_sequence('machine_statuses', 'id'), (SELECT MAX(id) FROM machine_statuses));
+
+-- =========================
+-- ДОПУСТИМЫЕ МАШИНЫ ПРОДУКТА
+-- =========================
+-- машины, на которых может выпускаться продукт; используются автопланированием
+CREATE TABLE product_machines (
+    product_id BIGINT NOT NULL,
+    machine_id BIGINT NOT NULL,
+    PRIMARY KEY (product_id, machine_id),
+    CONSTRAINT fk_product_machines_products FOREIGN KEY(product_id) REFERENCES products(id) ON DELETE CASCADE,
+    CONSTRAINT fk_product_machines_machines FOREIGN KEY(machine_id) REFERENCES machines(id) ON DELETE CASCADE
+);
+
+CREATE INDEX idx_product_machines_machine ON product_machines(machine_id);
@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS calendar_exceptions;
+DROP TABLE IF EXISTS shifts;
@@ -0,0 +1,43 @@
+-- =========================
+-- СМЕНЫ
+-- =========================
+-- start_time/end_time — местное время ЧЧ:ММ; end_time <= start_time — смена переходит через полночь.
+-- weekdays — битовая маска дней начала смены: пн=1, вт=2, ср=4, чт=8, пт=16, сб=32, вс=64.
+-- Смены с line_id заменяют общезаводские смены для своей линии.
+CREATE TABLE shifts (
+    id BIGSERIAL PRIMARY KEY,
+    name VARCHAR NOT NULL,
+    line_id BIGINT,
+    start_time VARCHAR(5) NOT NULL,
+    end_time VARCHAR(5) NOT NULL,
+    weekdays INT NOT NULL DEFAULT 127,
+    active BOOLEAN NOT NULL DEFAULT TRUE,
+    created_at TIMESTAMP DEFAULT NOW(),
+    CONSTRAINT fk_shifts_lines FOREIGN KEY(line_id) REFERENCES lines(id) ON DELETE CASCADE,
+    CONSTRAINT chk_shifts_time CHECK (start_time ~ '^([01][0-9]|2[0-3]):[0-5][0-9]$' AND end_time ~ '^([01][0-9]|2[0-3]):[0-5][0-9]$'),
+    CONSTRAINT chk_shifts_weekdays CHECK (weekdays BETWEEN 1 AND 127)
+);
+
+CREATE INDEX idx_shifts_line ON shifts(line_id);
+
+-- =========================
+-- ЗАВОДСКОЙ КАЛЕНДАРЬ
+-- =========================
+-- holiday и shutdown исключают время из рабочего, working добавляет сверх смен.
+-- Исключение без line_id действует на весь завод.
+CREATE TABLE calendar_exceptions (
+    id BIGSERIAL PRIMARY KEY,
+    kind VARCHAR NOT NULL,
+    name VARCHAR NOT NULL,
+    line_id BIGINT,
+    start_time TIMESTAMP NOT NULL,
+    end_time TIMESTAMP NOT NULL,
+    created_by BIGINT,
+    created_at TIMESTAMP DEFAULT NOW(),
+    CONSTRAINT fk_calendar_exceptions_lines FOREIGN KEY(line_id) REFERENCES lines(id) ON DELETE CASCADE,
+    CONSTRAINT fk_calendar_exceptions_users FOREIGN KEY(created_by) REFERENCES users(id),
+    CONSTRAINT chk_calendar_exceptions_kind CHECK (kind IN ('holiday', 'shutdown', 'working')),
+    CONSTRAINT chk_calendar_exceptions_period CHECK (end_time > start_time)
+);
+
+CREATE INDEX idx_calendar_exceptions_period ON calendar_exceptions(start_time, end_time);
@@ -0,0 +1,10 @@
+DROP TABLE IF EXISTS machine_status_history;
+
+-- слоты наладки удаляются: без kind их нельзя отличить от выпуска
+DELETE FROM schedule WHERE kind = 'setup';
+
+ALTER TABLE schedule
+    DROP CONSTRAINT IF EXISTS chk_schedule_kind,
+    DROP COLUMN IF EXISTS kind;
+
+DROP TABLE IF EXISTS changeovers;
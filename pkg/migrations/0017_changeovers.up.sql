@@ -0,0 +1,55 @@
+-- =========================
+-- МАТРИЦА ПЕРЕНАЛАДОК
+-- =========================
+-- setup_min — время переналадки с from_product_id на to_product_id.
+-- Строка машины важнее строки линии, строка линии — общезаводской (без machine_id и line_id).
+CREATE TABLE changeovers (
+    id BIGSERIAL PRIMARY KEY,
+    from_product_id BIGINT NOT NULL,
+    to_product_id BIGINT NOT NULL,
+    machine_id BIGINT,
+    line_id BIGINT,
+    setup_min INT NOT NULL,
+    created_at TIMESTAMP DEFAULT NOW(),
+    updated_at TIMESTAMP DEFAULT NOW(),
+    CONSTRAINT fk_changeovers_from_product FOREIGN KEY(from_product_id) REFERENCES products(id) ON DELETE CASCADE,
+    CONSTRAINT fk_changeovers_to_product FOREIGN KEY(to_product_id) REFERENCES products(id) ON DELETE CASCADE,
+    CONSTRAINT fk_changeovers_machines FOREIGN KEY(machine_id) REFERENCES machines(id) ON DELETE CASCADE,
+    CONSTRAINT fk_changeovers_lines FOREIGN KEY(line_id) REFERENCES lines(id) ON DELETE CASCADE,
+    CONSTRAINT chk_changeovers_scope CHECK (machine_id IS NULL OR line_id IS NULL),
+    CONSTRAINT chk_changeovers_products CHECK (from_product_id <> to_product_id),
+    CONSTRAINT chk_changeovers_setup CHECK (setup_min >= 0)
+);
+
+CREATE UNIQUE INDEX uq_changeovers_scope
+    ON changeovers(from_product_id, to_product_id, COALESCE(machine_id, 0), COALESCE(line_id, 0));
+
+-- =========================
+-- НАЛАДКА В РАСПИСАНИИ
+-- =========================
+-- kind: run — выпуск заказа, setup — переналадка машины под заказ
+ALTER TABLE schedule
+    ADD COLUMN kind VARCHAR NOT NULL DEFAULT 'run',
+    ADD CONSTRAINT chk_schedule_kind CHECK (kind IN ('run', 'setup'));
+
+-- =========================
+-- ИСТОРИЯ СТАТУСОВ МАШИН
+-- =========================
+-- открытая запись (ended_at IS NULL) — текущий статус машины
+CREATE TABLE machine_status_history (
+    id BIGSERIAL PRIMARY KEY,
+    machine_id BIGINT NOT NULL,
+    status_id INT NOT NULL,
+    started_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    ended_at TIMESTAMP,
+    work_order_id BIGINT,
+    comment TEXT,
+    changed_by BIGINT,
+    CONSTRAINT fk_machine_status_history_machines FOREIGN KEY(machine_id) REFERENCES machines(id) ON DELETE CASCADE,
+    CONSTRAINT fk_machine_status_history_status FOREIGN KEY(status_id) REFERENCES machine_statuses(id),
+    CONSTRAINT fk_machine_status_history_work_orders FOREIGN KEY(work_order_id) REFERENCES work_orders(id) ON DELETE SET NULL,
+    CONSTRAINT fk_machine_status_history_users FOREIGN KEY(changed_by) REFERENCES users(id)
+);
+
+CREATE INDEX idx_machine_status_history_machine ON machine_status_history(machine_id, started_at);
+CREATE UNIQUE INDEX uq_machine_status_history_open ON machine_status_history(machine_id) WHERE ended_at IS NULL;
@@ -0,0 +1,5 @@
+DROP TABLE IF EXISTS reschedule_changes;
+DROP TABLE IF EXISTS reschedule_proposals;
+
+ALTER TABLE machine_status_history
+    DROP COLUMN IF EXISTS expected_until;
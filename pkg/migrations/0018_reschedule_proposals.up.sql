@@ -0,0 +1,58 @@
+-- =========================
+-- ПРОГНОЗ ОКОНЧАНИЯ ПРОСТОЯ
+-- =========================
+ALTER TABLE machine_status_history
+    ADD COLUMN expected_until TIMESTAMP;
+
+-- =========================
+-- ПРЕДЛОЖЕНИЯ ПЕРЕПЛАНИРОВАНИЯ
+-- =========================
+-- Создаются при остановке машины; расписание меняется только после подтверждения планировщиком.
+-- planned_from — момент, с которого перепланированы слоты машины; available_from — прогноз возврата машины.
+CREATE TABLE reschedule_proposals (
+    id BIGSERIAL PRIMARY KEY,
+    machine_id BIGINT NOT NULL,
+    status_history_id BIGINT,
+    reason TEXT,
+    planned_from TIMESTAMP NOT NULL,
+    available_from TIMESTAMP,
+    status VARCHAR NOT NULL DEFAULT 'pending',
+    created_by BIGINT,
+    created_at TIMESTAMP DEFAULT NOW(),
+    decided_by BIGINT,
+    decided_at TIMESTAMP,
+    CONSTRAINT fk_reschedule_proposals_machines FOREIGN KEY(machine_id) REFERENCES machines(id) ON DELETE CASCADE,
+    CONSTRAINT fk_reschedule_proposals_history FOREIGN KEY(status_history_id) REFERENCES machine_status_history(id) ON DELETE SET NULL,
+    CONSTRAINT fk_reschedule_proposals_created_by FOREIGN KEY(created_by) REFERENCES users(id),
+    CONSTRAINT fk_reschedule_proposals_decided_by FOREIGN KEY(decided_by) REFERENCES users(id),
+    CONSTRAINT chk_reschedule_proposals_status CHECK (status IN ('pending', 'committed', 'rejected', 'superseded'))
+);
+
+CREATE INDEX idx_reschedule_proposals_machine ON reschedule_proposals(machine_id, status);
+
+-- Изменение по заказу: from_* — занятость остановленной машины, to_* — новое размещение
+-- (наладка [to_start, run_start), выпуск [run_start, to_end)); для unplaced размещения нет.
+CREATE TABLE reschedule_changes (
+    id BIGSERIAL PRIMARY KEY,
+    proposal_id BIGINT NOT NULL,
+    work_order_id BIGINT NOT NULL,
+    wo_number VARCHAR NOT NULL,
+    action VARCHAR NOT NULL,
+    from_start TIMESTAMP NOT NULL,
+    from_end TIMESTAMP NOT NULL,
+    to_machine_id BIGINT,
+    to_start TIMESTAMP,
+    run_start TIMESTAMP,
+    to_end TIMESTAMP,
+    deadline DATE,
+    lateness_before_min DOUBLE PRECISION NOT NULL DEFAULT 0,
+    lateness_after_min DOUBLE PRECISION NOT NULL DEFAULT 0,
+    delay_min DOUBLE PRECISION NOT NULL DEFAULT 0,
+    reason VARCHAR,
+    CONSTRAINT fk_reschedule_changes_proposals FOREIGN KEY(proposal_id) REFERENCES reschedule_proposals(id) ON DELETE CASCADE,
+    CONSTRAINT fk_reschedule_changes_work_orders FOREIGN KEY(work_order_id) REFERENCES work_orders(id) ON DELETE CASCADE,
+    CONSTRAINT fk_reschedule_changes_machines FOREIGN KEY(to_machine_id) REFERENCES machines(id) ON DELETE CASCADE,
+    CONSTRAINT chk_reschedule_changes_action CHECK (action IN ('moved', 'delayed', 'unplaced'))
+);
+
+CREATE INDEX idx_reschedule_changes_proposal ON reschedule_changes(proposal_id);
@@ -0,0 +1,27 @@
+DROP TABLE IF EXISTS incident_events;
+
+DROP INDEX IF EXISTS idx_incidents_work_order;
+DROP INDEX IF EXISTS idx_incidents_machine;
+DROP INDEX IF EXISTS idx_incidents_status;
+
+ALTER TABLE incidents
+    DROP CONSTRAINT IF EXISTS chk_incidents_status,
+    DROP CONSTRAINT IF EXISTS fk_incidents_resolved_by,
+    DROP CONSTRAINT IF EXISTS fk_incidents_assigned_to,
+    DROP CONSTRAINT IF EXISTS fk_incidents_reported_by,
+    DROP COLUMN IF EXISTS updated_at,
+    DROP COLUMN IF EXISTS machine_stopped,
+    DROP COLUMN IF EXISTS resolution,
+    DROP COLUMN IF EXISTS resolved_by,
+    DROP COLUMN IF EXISTS assigned_at,
+    DROP COLUMN IF EXISTS assigned_to,
+    DROP COLUMN IF EXISTS reported_by,
+    DROP COLUMN IF EXISTS status,
+    DROP COLUMN IF EXISTS title,
+    ALTER COLUMN description DROP NOT NULL,
+    ALTER COLUMN description DROP DEFAULT;
+
+ALTER TABLE incident_severities
+    DROP COLUMN IF EXISTS stops_machine,
+    DROP COLUMN IF EXISTS level,
+    DROP COLUMN IF EXISTS code;
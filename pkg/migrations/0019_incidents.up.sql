@@ -0,0 +1,66 @@
+-- =========================
+-- СЕРЬЕЗНОСТЬ ИНЦИДЕНТОВ
+-- =========================
+-- level — порядок серьезности; stops_machine — инцидент по умолчанию переводит машину в статус down
+ALTER TABLE incident_severities
+    ADD COLUMN code VARCHAR UNIQUE,
+    ADD COLUMN level INT NOT NULL DEFAULT 0,
+    ADD COLUMN stops_machine BOOLEAN NOT NULL DEFAULT FALSE;
+
+INSERT INTO incident_severities (id, code, name, level, stops_machine) VALUES
+(1, 'low', 'Низкая', 10, FALSE),
+(2, 'medium', 'Средняя', 20, FALSE),
+(3, 'high', 'Высокая', 30, TRUE),
+(4, 'critical', 'Критическая', 40, TRUE)
+ON CONFLICT (id) DO UPDATE SET
+    code = EXCLUDED.code,
+    level = EXCLUDED.level,
+    stops_machine = EXCLUDED.stops_machine;
+
+SELECT setval(pg_get_serial_sequence('incident_severities', 'id'), (SELECT MAX(id) FROM incident_severities));
+
+-- =========================
+-- ИНЦИДЕНТЫ
+-- =========================
+-- status: open — зарегистрирован, in_progress — назначен исполнитель, resolved — решен (resolved_at)
+UPDATE incidents SET description = '' WHERE description IS NULL;
+
+ALTER TABLE incidents
+    ALTER COLUMN description SET DEFAULT '',
+    ALTER COLUMN description SET NOT NULL,
+    ADD COLUMN title VARCHAR NOT NULL DEFAULT '',
+    ADD COLUMN status VARCHAR NOT NULL DEFAULT 'open',
+    ADD COLUMN reported_by BIGINT,
+    ADD COLUMN assigned_to BIGINT,
+    ADD COLUMN assigned_at TIMESTAMP,
+    ADD COLUMN resolved_by BIGINT,
+    ADD COLUMN resolution TEXT,
+    ADD COLUMN machine_stopped BOOLEAN NOT NULL DEFAULT FALSE,
+    ADD COLUMN updated_at TIMESTAMP DEFAULT NOW(),
+    ADD CONSTRAINT fk_incidents_reported_by FOREIGN KEY(reported_by) REFERENCES users(id),
+    ADD CONSTRAINT fk_incidents_assigned_to FOREIGN KEY(assigned_to) REFERENCES users(id),
+    ADD CONSTRAINT fk_incidents_resolved_by FOREIGN KEY(resolved_by) REFERENCES users(id),
+    ADD CONSTRAINT chk_incidents_status CHECK (status IN ('open', 'in_progress', 'resolved'));
+
+UPDATE incidents SET status = 'resolved' WHERE resolved_at IS NOT NULL;
+
+CREATE INDEX idx_incidents_status ON incidents(status, severity_id);
+CREATE INDEX idx_incidents_machine ON incidents(machine_id);
+CREATE INDEX idx_incidents_work_order ON incidents(work_order_id);
+
+-- =========================
+-- ЖУРНАЛ ИНЦИДЕНТОВ
+-- =========================
+-- action: created, updated, assigned, resolved, reopened
+CREATE TABLE incident_events (
+    id BIGSERIAL PRIMARY KEY,
+    incident_id BIGINT NOT NULL,
+    action VARCHAR NOT NULL,
+    user_id BIGINT,
+    comment TEXT,
+    created_at TIMESTAMP DEFAULT NOW(),
+    CONSTRAINT fk_incident_events_incidents FOREIGN KEY(incident_id) REFERENCES incidents(id) ON DELETE CASCADE,
+    CONSTRAINT fk_incident_events_users FOREIGN KEY(user_id) REFERENCES users(id)
+);
+
+CREATE INDEX idx_incident_events_incident ON incident_events(incident_id, created_at);
@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_incidents_created;
+DROP TABLE IF EXISTS incident_escalations;
+DROP TABLE IF EXISTS escalation_rules;
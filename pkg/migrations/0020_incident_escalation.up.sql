@@ -0,0 +1,44 @@
+-- =========================
+-- ПРАВИЛА ЭСКАЛАЦИИ ИНЦИДЕНТОВ
+-- =========================
+-- Через after_min минут после регистрации инцидента серьезности severity_id уведомляются
+-- пользователи роли notify_role_id и/или пользователь notify_user_id.
+-- stop_on: assigned — правило не срабатывает, если назначен исполнитель; resolved — пока инцидент не решен.
+CREATE TABLE escalation_rules (
+    id BIGSERIAL PRIMARY KEY,
+    severity_id INT NOT NULL,
+    name VARCHAR NOT NULL DEFAULT '',
+    after_min INT NOT NULL,
+    notify_role_id BIGINT,
+    notify_user_id BIGINT,
+    stop_on VARCHAR NOT NULL DEFAULT 'resolved',
+    active BOOLEAN NOT NULL DEFAULT TRUE,
+    created_at TIMESTAMP DEFAULT NOW(),
+    updated_at TIMESTAMP DEFAULT NOW(),
+    CONSTRAINT fk_escalation_rules_severity FOREIGN KEY(severity_id) REFERENCES incident_severities(id) ON DELETE CASCADE,
+    CONSTRAINT fk_escalation_rules_roles FOREIGN KEY(notify_role_id) REFERENCES roles(id) ON DELETE CASCADE,
+    CONSTRAINT fk_escalation_rules_users FOREIGN KEY(notify_user_id) REFERENCES users(id) ON DELETE CASCADE,
+    CONSTRAINT chk_escalation_rules_after CHECK (after_min > 0),
+    CONSTRAINT chk_escalation_rules_target CHECK (notify_role_id IS NOT NULL OR notify_user_id IS NOT NULL),
+    CONSTRAINT chk_escalation_rules_stop_on CHECK (stop_on IN ('assigned', 'resolved'))
+);
+
+CREATE INDEX idx_escalation_rules_severity ON escalation_rules(severity_id, after_min);
+
+-- =========================
+-- ИСТОРИЯ ЭСКАЛАЦИЙ
+-- =========================
+-- каждое правило срабатывает по инциденту один раз
+CREATE TABLE incident_escalations (
+    id BIGSERIAL PRIMARY KEY,
+    incident_id BIGINT NOT NULL,
+    rule_id BIGINT NOT NULL,
+    after_min INT NOT NULL,
+    recipients INT NOT NULL DEFAULT 0,
+    escalated_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    CONSTRAINT fk_incident_escalations_incidents FOREIGN KEY(incident_id) REFERENCES incidents(id) ON DELETE CASCADE,
+    CONSTRAINT fk_incident_escalations_rules FOREIGN KEY(rule_id) REFERENCES escalation_rules(id) ON DELETE CASCADE,
+    CONSTRAINT uq_incident_escalations UNIQUE (incident_id, rule_id)
+);
+
+CREATE INDEX idx_incidents_created ON incidents(created_at);
@@ -0,0 +1,24 @@
+-- =========================
+-- ВЛОЖЕНИЯ (ФОТО И ФАЙЛЫ)
+-- =========================
+-- Файл, прикрепленный к объекту entity_type/entity_id; содержимое лежит в хранилище по storage_key.
+-- Для изображений рядом хранится уменьшенная копия (has_thumbnail).
+CREATE TABLE attachments (
+    id BIGSERIAL PRIMARY KEY,
+    entity_type VARCHAR NOT NULL,
+    entity_id BIGINT NOT NULL,
+    file_name VARCHAR NOT NULL,
+    content_type VARCHAR NOT NULL,
+    size_bytes BIGINT NOT NULL,
+    storage_key VARCHAR NOT NULL UNIQUE,
+    has_thumbnail BOOLEAN NOT NULL DEFAULT FALSE,
+    width INT,
+    height INT,
+    uploaded_by BIGINT,
+    created_at TIMESTAMP DEFAULT NOW(),
+    CONSTRAINT fk_attachments_users FOREIGN KEY(uploaded_by) REFERENCES users(id) ON DELETE SET NULL,
+    CONSTRAINT chk_attachments_entity CHECK (entity_type IN ('incident', 'work_order')),
+    CONSTRAINT chk_attachments_size CHECK (size_bytes > 0)
+);
+
+CREATE INDEX idx_attachments_entity ON attachments(entity_type, entity_id);
@@ -0,0 +1,20 @@
+DELETE FROM attachments WHERE entity_type = 'inspection';
+ALTER TABLE attachments DROP CONSTRAINT chk_attachments_entity;
+ALTER TABLE attachments
+    ADD CONSTRAINT chk_attachments_entity CHECK (entity_type IN ('incident', 'work_order'));
+
+DROP TABLE IF EXISTS product_quality_defects;
+
+DROP INDEX IF EXISTS idx_product_quality_product;
+DROP INDEX IF EXISTS idx_product_quality_work_order;
+DROP INDEX IF EXISTS idx_product_quality_inspected;
+
+ALTER TABLE product_quality
+    ALTER COLUMN description DROP NOT NULL,
+    ALTER COLUMN description DROP DEFAULT,
+    DROP CONSTRAINT IF EXISTS fk_product_quality_work_orders,
+    DROP CONSTRAINT IF EXISTS fk_product_quality_products,
+    DROP COLUMN IF EXISTS work_order_id,
+    DROP COLUMN IF EXISTS product_id;
+
+DROP TABLE IF EXISTS defect_codes;
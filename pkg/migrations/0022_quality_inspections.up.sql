@@ -0,0 +1,67 @@
+-- =========================
+-- КОДЫ ДЕФЕКТОВ
+-- =========================
+CREATE TABLE defect_codes (
+    id SERIAL PRIMARY KEY,
+    code VARCHAR NOT NULL UNIQUE,
+    name VARCHAR NOT NULL,
+    description TEXT NOT NULL DEFAULT '',
+    active BOOLEAN NOT NULL DEFAULT TRUE
+);
+
+INSERT INTO defect_codes (code, name) VALUES
+('SCRATCH', 'Царапина'),
+('DENT', 'Вмятина'),
+('CRACK', 'Трещина'),
+('DIMENSION', 'Несоответствие размеров'),
+('SURFACE', 'Дефект покрытия'),
+('INCOMPLETE', 'Некомплект'),
+('OTHER', 'Прочее');
+
+-- =========================
+-- РЕЗУЛЬТАТЫ КОНТРОЛЯ
+-- =========================
+-- product_id и work_order_id копируются из экземпляра для фильтрации без соединений;
+-- последний результат по экземпляру определяет его состояние
+ALTER TABLE product_quality
+    ADD COLUMN product_id BIGINT,
+    ADD COLUMN work_order_id BIGINT,
+    ADD CONSTRAINT fk_product_quality_products FOREIGN KEY(product_id) REFERENCES products(id),
+    ADD CONSTRAINT fk_product_quality_work_orders FOREIGN KEY(work_order_id) REFERENCES work_orders(id);
+
+UPDATE product_quality pq
+SET product_id = pi.product_id,
+    work_order_id = pi.work_order_id
+FROM product_instances pi
+WHERE pi.id = pq.product_instance_id;
+
+UPDATE product_quality SET description = '' WHERE description IS NULL;
+ALTER TABLE product_quality
+    ALTER COLUMN description SET DEFAULT '',
+    ALTER COLUMN description SET NOT NULL;
+
+CREATE INDEX idx_product_quality_inspected ON product_quality(inspected_at);
+CREATE INDEX idx_product_quality_work_order ON product_quality(work_order_id, inspected_at);
+CREATE INDEX idx_product_quality_product ON product_quality(product_id, inspected_at);
+
+-- =========================
+-- ДЕФЕКТЫ В РЕЗУЛЬТАТЕ КОНТРОЛЯ
+-- =========================
+CREATE TABLE product_quality_defects (
+    id BIGSERIAL PRIMARY KEY,
+    quality_id BIGINT NOT NULL,
+    defect_code_id INT NOT NULL,
+    quantity INT NOT NULL DEFAULT 1,
+    comment TEXT NOT NULL DEFAULT '',
+    CONSTRAINT fk_product_quality_defects_quality FOREIGN KEY(quality_id) REFERENCES product_quality(id) ON DELETE CASCADE,
+    CONSTRAINT fk_product_quality_defects_codes FOREIGN KEY(defect_code_id) REFERENCES defect_codes(id),
+    CONSTRAINT chk_product_quality_defects_quantity CHECK (quantity > 0)
+);
+
+CREATE INDEX idx_product_quality_defects_quality ON product_quality_defects(quality_id);
+CREATE INDEX idx_product_quality_defects_code ON product_quality_defects(defect_code_id);
+
+-- вложения (фото дефектов) к результатам контроля
+ALTER TABLE attachments DROP CONSTRAINT chk_attachments_entity;
+ALTER TABLE attachments
+    ADD CONSTRAINT chk_attachments_entity CHECK (entity_type IN ('incident', 'work_order', 'inspection'));
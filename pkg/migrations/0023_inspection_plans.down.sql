@@ -0,0 +1,13 @@
+DROP TABLE IF EXISTS inspection_measurements;
+
+DROP INDEX IF EXISTS idx_product_quality_instance_stage;
+
+ALTER TABLE product_quality
+    DROP CONSTRAINT IF EXISTS fk_product_quality_plans,
+    DROP CONSTRAINT IF EXISTS fk_product_quality_stages,
+    DROP COLUMN IF EXISTS plan_id,
+    DROP COLUMN IF EXISTS stage_id;
+
+DROP TABLE IF EXISTS inspection_choice_options;
+DROP TABLE IF EXISTS inspection_characteristics;
+DROP TABLE IF EXISTS inspection_plans;
@@ -0,0 +1,82 @@
+-- =========================
+-- ПЛАНЫ КОНТРОЛЯ
+-- =========================
+-- План контроля этапа маршрута продукта; действует во всех версиях маршрута, где есть этап.
+-- Обязательный план блокирует следующие этапы, пока последний результат контроля этапа не годен.
+CREATE TABLE inspection_plans (
+    id BIGSERIAL PRIMARY KEY,
+    product_id BIGINT NOT NULL,
+    stage_id BIGINT NOT NULL,
+    name VARCHAR NOT NULL DEFAULT '',
+    mandatory BOOLEAN NOT NULL DEFAULT TRUE,
+    active BOOLEAN NOT NULL DEFAULT TRUE,
+    created_at TIMESTAMP DEFAULT NOW(),
+    updated_at TIMESTAMP DEFAULT NOW(),
+    CONSTRAINT fk_inspection_plans_products FOREIGN KEY(product_id) REFERENCES products(id) ON DELETE CASCADE,
+    CONSTRAINT fk_inspection_plans_stages FOREIGN KEY(stage_id) REFERENCES stages(id) ON DELETE CASCADE,
+    CONSTRAINT uq_inspection_plans_product_stage UNIQUE (product_id, stage_id)
+);
+
+-- =========================
+-- ХАРАКТЕРИСТИКИ ПЛАНА
+-- =========================
+-- numeric — годно в [nominal - tol_minus, nominal + tol_plus];
+-- boolean — годно, если значение равно expected; choice — годно, если выбран допустимый вариант
+CREATE TABLE inspection_characteristics (
+    id BIGSERIAL PRIMARY KEY,
+    plan_id BIGINT NOT NULL,
+    position INT NOT NULL,
+    name VARCHAR NOT NULL,
+    kind VARCHAR NOT NULL,
+    unit VARCHAR NOT NULL DEFAULT '',
+    nominal DOUBLE PRECISION,
+    tol_minus DOUBLE PRECISION,
+    tol_plus DOUBLE PRECISION,
+    expected BOOLEAN,
+    required BOOLEAN NOT NULL DEFAULT TRUE,
+    CONSTRAINT fk_inspection_characteristics_plans FOREIGN KEY(plan_id) REFERENCES inspection_plans(id) ON DELETE CASCADE,
+    CONSTRAINT chk_inspection_characteristics_kind CHECK (kind IN ('numeric', 'boolean', 'choice')),
+    CONSTRAINT chk_inspection_characteristics_tolerance CHECK (tol_minus >= 0 AND tol_plus >= 0),
+    CONSTRAINT uq_inspection_characteristics_position UNIQUE (plan_id, position)
+);
+
+CREATE TABLE inspection_choice_options (
+    id BIGSERIAL PRIMARY KEY,
+    characteristic_id BIGINT NOT NULL,
+    position INT NOT NULL,
+    value VARCHAR NOT NULL,
+    accepted BOOLEAN NOT NULL DEFAULT TRUE,
+    CONSTRAINT fk_inspection_choice_options_characteristics FOREIGN KEY(characteristic_id) REFERENCES inspection_characteristics(id) ON DELETE CASCADE,
+    CONSTRAINT uq_inspection_choice_options_value UNIQUE (characteristic_id, value)
+);
+
+-- =========================
+-- КОНТРОЛЬ ПО ПЛАНУ
+-- =========================
+ALTER TABLE product_quality
+    ADD COLUMN stage_id BIGINT,
+    ADD COLUMN plan_id BIGINT,
+    ADD CONSTRAINT fk_product_quality_stages FOREIGN KEY(stage_id) REFERENCES stages(id),
+    ADD CONSTRAINT fk_product_quality_plans FOREIGN KEY(plan_id) REFERENCES inspection_plans(id) ON DELETE SET NULL;
+
+CREATE INDEX idx_product_quality_instance_stage ON product_quality(product_instance_id, stage_id, inspected_at);
+
+-- Измерение хранит копию характеристики на момент контроля: план может измениться позже
+CREATE TABLE inspection_measurements (
+    id BIGSERIAL PRIMARY KEY,
+    quality_id BIGINT NOT NULL,
+    characteristic_id BIGINT,
+    name VARCHAR NOT NULL,
+    kind VARCHAR NOT NULL,
+    unit VARCHAR NOT NULL DEFAULT '',
+    lower_limit DOUBLE PRECISION,
+    upper_limit DOUBLE PRECISION,
+    numeric_value DOUBLE PRECISION,
+    bool_value BOOLEAN,
+    choice_value VARCHAR,
+    passed BOOLEAN NOT NULL,
+    CONSTRAINT fk_inspection_measurements_quality FOREIGN KEY(quality_id) REFERENCES product_quality(id) ON DELETE CASCADE,
+    CONSTRAINT fk_inspection_measurements_characteristics FOREIGN KEY(characteristic_id) REFERENCES inspection_characteristics(id) ON DELETE SET NULL
+);
+
+CREATE INDEX idx_inspection_measurements_quality ON inspection_measurements(quality_id);
@@ -0,0 +1,8 @@
+DROP TABLE IF EXISTS spc_alerts;
+
+DROP INDEX IF EXISTS idx_inspection_measurements_characteristic;
+DROP INDEX IF EXISTS idx_product_quality_machine;
+
+ALTER TABLE product_quality
+    DROP CONSTRAINT IF EXISTS fk_product_quality_machines,
+    DROP COLUMN IF EXISTS machine_id;
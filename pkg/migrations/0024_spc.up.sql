@@ -0,0 +1,34 @@
+-- =========================
+-- МАШИНА В РЕЗУЛЬТАТАХ КОНТРОЛЯ
+-- =========================
+-- Машина, на которой изготовлен экземпляр; по умолчанию — машина заказа
+ALTER TABLE product_quality
+    ADD COLUMN machine_id BIGINT,
+    ADD CONSTRAINT fk_product_quality_machines FOREIGN KEY(machine_id) REFERENCES machines(id) ON DELETE SET NULL;
+
+UPDATE product_quality pq
+SET machine_id = wo.machine_id
+FROM work_orders wo
+WHERE wo.id = pq.work_order_id;
+
+CREATE INDEX idx_product_quality_machine ON product_quality(machine_id, inspected_at);
+CREATE INDEX idx_inspection_measurements_characteristic ON inspection_measurements(characteristic_id);
+
+-- =========================
+-- ИНЦИДЕНТЫ ПО КОНТРОЛЬНЫМ КАРТАМ
+-- =========================
+-- Последний результат контроля, по которому нарушения правил карты уже переданы в инцидент;
+-- machine_id = NULL — карта по всем машинам
+CREATE TABLE spc_alerts (
+    id BIGSERIAL PRIMARY KEY,
+    characteristic_id BIGINT NOT NULL,
+    machine_id BIGINT,
+    last_inspection_id BIGINT NOT NULL,
+    incident_id BIGINT,
+    created_at TIMESTAMP DEFAULT NOW(),
+    CONSTRAINT fk_spc_alerts_characteristics FOREIGN KEY(characteristic_id) REFERENCES inspection_characteristics(id) ON DELETE CASCADE,
+    CONSTRAINT fk_spc_alerts_machines FOREIGN KEY(machine_id) REFERENCES machines(id) ON DELETE CASCADE,
+    CONSTRAINT fk_spc_alerts_incidents FOREIGN KEY(incident_id) REFERENCES incidents(id) ON DELETE SET NULL
+);
+
+CREATE UNIQUE INDEX uq_spc_alerts_chart ON spc_alerts(characteristic_id, COALESCE(machine_id, 0));
@@ -0,0 +1,10 @@
+DELETE FROM permissions WHERE code = 'product.instance.ship';
+
+ALTER TABLE product_instances
+    DROP CONSTRAINT IF EXISTS fk_product_instances_shipped_by,
+    DROP COLUMN IF EXISTS shipped_by,
+    DROP COLUMN IF EXISTS shipped_at;
+
+DROP TABLE IF EXISTS hold_events;
+DROP TABLE IF EXISTS hold_items;
+DROP TABLE IF EXISTS holds;
@@ -0,0 +1,79 @@
+-- =========================
+-- КАРАНТИН (БЛОКИРОВКИ ЭКЗЕМПЛЯРОВ)
+-- =========================
+-- scope: instances — по штрихкодам, work_order — экземпляры заказа,
+-- machine — экземпляры, изготовленные на машине в окне [window_from, window_to).
+-- Состав блокировки фиксируется при постановке; status = released, когда сняты все экземпляры.
+CREATE TABLE holds (
+    id BIGSERIAL PRIMARY KEY,
+    reason TEXT NOT NULL,
+    scope VARCHAR NOT NULL,
+    work_order_id BIGINT,
+    machine_id BIGINT,
+    window_from TIMESTAMP,
+    window_to TIMESTAMP,
+    status VARCHAR NOT NULL DEFAULT 'active',
+    created_by BIGINT,
+    created_at TIMESTAMP DEFAULT NOW(),
+    released_at TIMESTAMP,
+    CONSTRAINT fk_holds_work_orders FOREIGN KEY(work_order_id) REFERENCES work_orders(id) ON DELETE SET NULL,
+    CONSTRAINT fk_holds_machines FOREIGN KEY(machine_id) REFERENCES machines(id) ON DELETE SET NULL,
+    CONSTRAINT fk_holds_users FOREIGN KEY(created_by) REFERENCES users(id),
+    CONSTRAINT chk_holds_scope CHECK (scope IN ('instances', 'work_order', 'machine')),
+    CONSTRAINT chk_holds_status CHECK (status IN ('active', 'released'))
+);
+
+CREATE INDEX idx_holds_status ON holds(status, created_at);
+
+-- Экземпляр под блокировкой; снимается с решением: use_as_is — использовать как есть,
+-- rework — на доработку, scrap — в брак (экземпляр остается заблокированным)
+CREATE TABLE hold_items (
+    id BIGSERIAL PRIMARY KEY,
+    hold_id BIGINT NOT NULL,
+    product_instance_id BIGINT NOT NULL,
+    status VARCHAR NOT NULL DEFAULT 'active',
+    disposition VARCHAR,
+    comment TEXT NOT NULL DEFAULT '',
+    released_by BIGINT,
+    released_at TIMESTAMP,
+    CONSTRAINT fk_hold_items_holds FOREIGN KEY(hold_id) REFERENCES holds(id) ON DELETE CASCADE,
+    CONSTRAINT fk_hold_items_instances FOREIGN KEY(product_instance_id) REFERENCES product_instances(id) ON DELETE CASCADE,
+    CONSTRAINT fk_hold_items_users FOREIGN KEY(released_by) REFERENCES users(id),
+    CONSTRAINT chk_hold_items_status CHECK (status IN ('active', 'released')),
+    CONSTRAINT chk_hold_items_disposition CHECK (disposition IN ('use_as_is', 'rework', 'scrap')),
+    CONSTRAINT uq_hold_items_instance UNIQUE (hold_id, product_instance_id)
+);
+
+CREATE INDEX idx_hold_items_instance ON hold_items(product_instance_id, status);
+
+-- Журнал блокировки: постановка и снятие экземпляров
+CREATE TABLE hold_events (
+    id BIGSERIAL PRIMARY KEY,
+    hold_id BIGINT NOT NULL,
+    product_instance_id BIGINT,
+    action VARCHAR NOT NULL,
+    disposition VARCHAR,
+    user_id BIGINT,
+    comment TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMP DEFAULT NOW(),
+    CONSTRAINT fk_hold_events_holds FOREIGN KEY(hold_id) REFERENCES holds(id) ON DELETE CASCADE,
+    CONSTRAINT fk_hold_events_instances FOREIGN KEY(product_instance_id) REFERENCES product_instances(id) ON DELETE CASCADE,
+    CONSTRAINT fk_hold_events_users FOREIGN KEY(user_id) REFERENCES users(id)
+);
+
+CREATE INDEX idx_hold_events_hold ON hold_events(hold_id, created_at);
+
+-- =========================
+-- ОТГРУЗКА ЭКЗЕМПЛЯРОВ
+-- =========================
+ALTER TABLE product_instances
+    ADD COLUMN shipped_at TIMESTAMP,
+    ADD COLUMN shipped_by BIGINT,
+    ADD CONSTRAINT fk_product_instances_shipped_by FOREIGN KEY(shipped_by) REFERENCES users(id);
+
+INSERT INTO permissions (code, name, description, category) VALUES
+('product.instance.ship', 'Отгрузка экземпляров', 'Отметка отгрузки экземпляров продукции', 'Продукция')
+ON CONFLICT (code) DO NOTHING;
+
+SELECT assign_role_permissions('Администратор', ARRAY['product.instance.ship']);
+SELECT assign_role_permissions('Менеджер', ARRAY['product.instance.ship']);
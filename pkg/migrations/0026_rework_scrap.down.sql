@@ -0,0 +1,20 @@
+DROP INDEX IF EXISTS uq_product_instances_scrap_inspection;
+
+ALTER TABLE product_instances
+    DROP CONSTRAINT IF EXISTS fk_product_instances_scrap_inspection,
+    DROP CONSTRAINT IF EXISTS fk_product_instances_scrap_reason,
+    DROP CONSTRAINT IF EXISTS fk_product_instances_scrapped_by,
+    DROP COLUMN IF EXISTS scrap_comment,
+    DROP COLUMN IF EXISTS scrap_inspection_id,
+    DROP COLUMN IF EXISTS scrap_reason_id,
+    DROP COLUMN IF EXISTS scrapped_by,
+    DROP COLUMN IF EXISTS scrapped_at;
+
+ALTER TABLE stage_execution
+    DROP CONSTRAINT IF EXISTS fk_stage_execution_superseded,
+    DROP CONSTRAINT IF EXISTS fk_stage_execution_rework,
+    DROP COLUMN IF EXISTS superseded_by,
+    DROP COLUMN IF EXISTS rework_id;
+
+DROP TABLE IF EXISTS instance_reworks;
+DROP TABLE IF EXISTS scrap_reasons;
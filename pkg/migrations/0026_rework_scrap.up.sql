@@ -0,0 +1,62 @@
+-- =========================
+-- ПРИЧИНЫ БРАКА
+-- =========================
+CREATE TABLE scrap_reasons (
+    id SERIAL PRIMARY KEY,
+    code VARCHAR NOT NULL UNIQUE,
+    name VARCHAR NOT NULL,
+    active BOOLEAN NOT NULL DEFAULT TRUE
+);
+
+INSERT INTO scrap_reasons (code, name) VALUES
+('DIMENSION', 'Размеры вне допуска, исправление невозможно'),
+('MATERIAL', 'Дефект материала'),
+('DAMAGE', 'Механическое повреждение'),
+('PROCESS', 'Нарушение технологии'),
+('REWORK_LIMIT', 'Исчерпан лимит доработок'),
+('OTHER', 'Прочее')
+ON CONFLICT (code) DO NOTHING;
+
+-- =========================
+-- ДОРАБОТКА ЭКЗЕМПЛЯРОВ
+-- =========================
+-- Решение по браку: экземпляр возвращается на этап to_stage_id маршрута.
+-- Выполнения этапов начиная с to_stage_id помечаются замененными (superseded_by),
+-- повторные выполнения ссылаются на доработку (rework_id).
+CREATE TABLE instance_reworks (
+    id BIGSERIAL PRIMARY KEY,
+    product_instance_id BIGINT NOT NULL,
+    inspection_id BIGINT NOT NULL,
+    to_stage_id BIGINT NOT NULL,
+    comment TEXT NOT NULL DEFAULT '',
+    created_by BIGINT,
+    created_at TIMESTAMP DEFAULT NOW(),
+    CONSTRAINT fk_instance_reworks_instances FOREIGN KEY(product_instance_id) REFERENCES product_instances(id) ON DELETE CASCADE,
+    CONSTRAINT fk_instance_reworks_quality FOREIGN KEY(inspection_id) REFERENCES product_quality(id) ON DELETE CASCADE,
+    CONSTRAINT fk_instance_reworks_stages FOREIGN KEY(to_stage_id) REFERENCES stages(id),
+    CONSTRAINT fk_instance_reworks_users FOREIGN KEY(created_by) REFERENCES users(id)
+);
+
+CREATE UNIQUE INDEX uq_instance_reworks_inspection ON instance_reworks(inspection_id);
+CREATE INDEX idx_instance_reworks_instance ON instance_reworks(product_instance_id, id);
+
+ALTER TABLE stage_execution
+    ADD COLUMN rework_id BIGINT,
+    ADD COLUMN superseded_by BIGINT,
+    ADD CONSTRAINT fk_stage_execution_rework FOREIGN KEY(rework_id) REFERENCES instance_reworks(id) ON DELETE SET NULL,
+    ADD CONSTRAINT fk_stage_execution_superseded FOREIGN KEY(superseded_by) REFERENCES instance_reworks(id) ON DELETE SET NULL;
+
+-- =========================
+-- СПИСАНИЕ В БРАК
+-- =========================
+ALTER TABLE product_instances
+    ADD COLUMN scrapped_at TIMESTAMP,
+    ADD COLUMN scrapped_by BIGINT,
+    ADD COLUMN scrap_reason_id INT,
+    ADD COLUMN scrap_inspection_id BIGINT,
+    ADD COLUMN scrap_comment TEXT NOT NULL DEFAULT '',
+    ADD CONSTRAINT fk_product_instances_scrapped_by FOREIGN KEY(scrapped_by) REFERENCES users(id),
+    ADD CONSTRAINT fk_product_instances_scrap_reason FOREIGN KEY(scrap_reason_id) REFERENCES scrap_reasons(id),
+    ADD CONSTRAINT fk_product_instances_scrap_inspection FOREIGN KEY(scrap_inspection_id) REFERENCES product_quality(id) ON DELETE SET NULL;
+
+CREATE UNIQUE INDEX uq_product_instances_scrap_inspection ON product_instances(scrap_inspection_id);
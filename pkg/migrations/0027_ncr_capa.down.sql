@@ -0,0 +1,9 @@
+DELETE FROM permissions WHERE code IN ('ncr.view', 'ncr.create', 'ncr.approve', 'ncr.close');
+
+DROP TABLE IF EXISTS ncr_events;
+DROP TABLE IF EXISTS ncr_approvals;
+DROP TABLE IF EXISTS capa_actions;
+DROP TABLE IF EXISTS ncr_incidents;
+DROP TABLE IF EXISTS ncr_work_orders;
+DROP TABLE IF EXISTS ncr_instances;
+DROP TABLE IF EXISTS ncrs;
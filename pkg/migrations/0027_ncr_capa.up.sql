@@ -0,0 +1,132 @@
+-- =========================
+-- ОТЧЕТЫ О НЕСООТВЕТСТВИИ (NCR) И CAPA
+-- =========================
+-- Жизненный цикл: open (анализ причины и план действий) → pending_approval (план на утверждении)
+-- → in_progress (выполнение действий) → verification (проверка эффективности) → verified → closed.
+-- Неутвержденный план и неэффективные действия возвращают отчет в open; cancelled — отменен до утверждения.
+CREATE TABLE ncrs (
+    id BIGSERIAL PRIMARY KEY,
+    number VARCHAR NOT NULL UNIQUE,
+    title VARCHAR NOT NULL,
+    description TEXT NOT NULL DEFAULT '',
+    defect_code_id INT,
+    status VARCHAR NOT NULL DEFAULT 'open',
+    root_cause TEXT NOT NULL DEFAULT '',
+    submitted_by BIGINT,
+    submitted_at TIMESTAMP,
+    effective BOOLEAN,
+    effectiveness_comment TEXT NOT NULL DEFAULT '',
+    verified_by BIGINT,
+    verified_at TIMESTAMP,
+    closure_comment TEXT NOT NULL DEFAULT '',
+    closed_by BIGINT,
+    closed_at TIMESTAMP,
+    created_by BIGINT,
+    created_at TIMESTAMP DEFAULT NOW(),
+    updated_at TIMESTAMP DEFAULT NOW(),
+    CONSTRAINT fk_ncrs_defect_codes FOREIGN KEY(defect_code_id) REFERENCES defect_codes(id),
+    CONSTRAINT fk_ncrs_submitted_by FOREIGN KEY(submitted_by) REFERENCES users(id),
+    CONSTRAINT fk_ncrs_verified_by FOREIGN KEY(verified_by) REFERENCES users(id),
+    CONSTRAINT fk_ncrs_closed_by FOREIGN KEY(closed_by) REFERENCES users(id),
+    CONSTRAINT fk_ncrs_created_by FOREIGN KEY(created_by) REFERENCES users(id),
+    CONSTRAINT chk_ncrs_status CHECK (status IN ('open', 'pending_approval', 'in_progress', 'verification', 'verified', 'closed', 'cancelled'))
+);
+
+CREATE INDEX idx_ncrs_status ON ncrs(status, created_at);
+
+-- Затронутые экземпляры, заказы и инциденты
+CREATE TABLE ncr_instances (
+    ncr_id BIGINT NOT NULL,
+    product_instance_id BIGINT NOT NULL,
+    PRIMARY KEY (ncr_id, product_instance_id),
+    CONSTRAINT fk_ncr_instances_ncrs FOREIGN KEY(ncr_id) REFERENCES ncrs(id) ON DELETE CASCADE,
+    CONSTRAINT fk_ncr_instances_instances FOREIGN KEY(product_instance_id) REFERENCES product_instances(id) ON DELETE CASCADE
+);
+
+CREATE INDEX idx_ncr_instances_instance ON ncr_instances(product_instance_id);
+
+CREATE TABLE ncr_work_orders (
+    ncr_id BIGINT NOT NULL,
+    work_order_id BIGINT NOT NULL,
+    PRIMARY KEY (ncr_id, work_order_id),
+    CONSTRAINT fk_ncr_work_orders_ncrs FOREIGN KEY(ncr_id) REFERENCES ncrs(id) ON DELETE CASCADE,
+    CONSTRAINT fk_ncr_work_orders_work_orders FOREIGN KEY(work_order_id) REFERENCES work_orders(id) ON DELETE CASCADE
+);
+
+CREATE INDEX idx_ncr_work_orders_work_order ON ncr_work_orders(work_order_id);
+
+CREATE TABLE ncr_incidents (
+    ncr_id BIGINT NOT NULL,
+    incident_id BIGINT NOT NULL,
+    PRIMARY KEY (ncr_id, incident_id),
+    CONSTRAINT fk_ncr_incidents_ncrs FOREIGN KEY(ncr_id) REFERENCES ncrs(id) ON DELETE CASCADE,
+    CONSTRAINT fk_ncr_incidents_incidents FOREIGN KEY(incident_id) REFERENCES incidents(id) ON DELETE CASCADE
+);
+
+CREATE INDEX idx_ncr_incidents_incident ON ncr_incidents(incident_id);
+
+-- Корректирующие и предупреждающие действия с ответственным и сроком
+CREATE TABLE capa_actions (
+    id BIGSERIAL PRIMARY KEY,
+    ncr_id BIGINT NOT NULL,
+    kind VARCHAR NOT NULL,
+    description TEXT NOT NULL,
+    owner_id BIGINT NOT NULL,
+    due_date DATE NOT NULL,
+    status VARCHAR NOT NULL DEFAULT 'open',
+    result TEXT NOT NULL DEFAULT '',
+    completed_by BIGINT,
+    completed_at TIMESTAMP,
+    created_at TIMESTAMP DEFAULT NOW(),
+    CONSTRAINT fk_capa_actions_ncrs FOREIGN KEY(ncr_id) REFERENCES ncrs(id) ON DELETE CASCADE,
+    CONSTRAINT fk_capa_actions_owner FOREIGN KEY(owner_id) REFERENCES users(id),
+    CONSTRAINT fk_capa_actions_completed_by FOREIGN KEY(completed_by) REFERENCES users(id),
+    CONSTRAINT chk_capa_actions_kind CHECK (kind IN ('corrective', 'preventive')),
+    CONSTRAINT chk_capa_actions_status CHECK (status IN ('open', 'done'))
+);
+
+CREATE INDEX idx_capa_actions_ncr ON capa_actions(ncr_id);
+CREATE INDEX idx_capa_actions_owner ON capa_actions(owner_id, status, due_date);
+
+-- Решения по утверждению: plan — план CAPA, closure — закрытие отчета
+CREATE TABLE ncr_approvals (
+    id BIGSERIAL PRIMARY KEY,
+    ncr_id BIGINT NOT NULL,
+    step VARCHAR NOT NULL,
+    decision VARCHAR NOT NULL,
+    user_id BIGINT NOT NULL,
+    comment TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMP DEFAULT NOW(),
+    CONSTRAINT fk_ncr_approvals_ncrs FOREIGN KEY(ncr_id) REFERENCES ncrs(id) ON DELETE CASCADE,
+    CONSTRAINT fk_ncr_approvals_users FOREIGN KEY(user_id) REFERENCES users(id),
+    CONSTRAINT chk_ncr_approvals_step CHECK (step IN ('plan', 'closure')),
+    CONSTRAINT chk_ncr_approvals_decision CHECK (decision IN ('approved', 'rejected'))
+);
+
+CREATE INDEX idx_ncr_approvals_ncr ON ncr_approvals(ncr_id, created_at);
+
+CREATE TABLE ncr_events (
+    id BIGSERIAL PRIMARY KEY,
+    ncr_id BIGINT NOT NULL,
+    action VARCHAR NOT NULL,
+    user_id BIGINT,
+    comment TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMP DEFAULT NOW(),
+    CONSTRAINT fk_ncr_events_ncrs FOREIGN KEY(ncr_id) REFERENCES ncrs(id) ON DELETE CASCADE,
+    CONSTRAINT fk_ncr_events_users FOREIGN KEY(user_id) REFERENCES users(id)
+);
+
+CREATE INDEX idx_ncr_events_ncr ON ncr_events(ncr_id, created_at);
+
+INSERT INTO permissions (code, name, description, category) VALUES
+('ncr.view', 'Просмотр NCR', 'Просмотр отчетов о несоответствии и действий CAPA', 'Качество'),
+('ncr.create', 'Ведение NCR', 'Регистрация отчетов о несоответствии, анализ причины и план действий CAPA', 'Качество'),
+('ncr.approve', 'Утверждение CAPA', 'Утверждение и отклонение плана корректирующих действий', 'Качество'),
+('ncr.close', 'Закрытие NCR', 'Проверка эффективности действий, закрытие и отмена отчетов', 'Качество')
+ON CONFLICT (code) DO NOTHING;
+
+SELECT assign_role_permissions('Администратор', ARRAY['ncr.view', 'ncr.create', 'ncr.approve', 'ncr.close']);
+SELECT assign_role_permissions('Менеджер', ARRAY['ncr.view', 'ncr.create', 'ncr.approve']);
+SELECT assign_role_permissions('Контролер качества', ARRAY['ncr.view', 'ncr.create', 'ncr.close']);
+SELECT assign_role_permissions('Планировщик', ARRAY['ncr.view']);
+SELECT assign_role_permissions('Рабочий', ARRAY['ncr.view']);
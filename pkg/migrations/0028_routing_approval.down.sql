@@ -0,0 +1,3 @@
+ALTER TABLE routing_versions
+    DROP CONSTRAINT IF EXISTS fk_routing_versions_updated_by,
+    DROP COLUMN IF EXISTS updated_by;
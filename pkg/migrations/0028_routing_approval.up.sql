@@ -0,0 +1,9 @@
+-- =========================
+-- УТВЕРЖДЕНИЕ ВЕРСИЙ МАРШРУТА
+-- =========================
+-- updated_by — последний редактор черновика; ни автор, ни редактор не могут утвердить версию
+ALTER TABLE routing_versions
+    ADD COLUMN updated_by BIGINT,
+    ADD CONSTRAINT fk_routing_versions_updated_by FOREIGN KEY(updated_by) REFERENCES users(id);
+
+UPDATE routing_versions SET updated_by = created_by WHERE status = 'draft';
@@ -0,0 +1,2 @@
+-- подтверждение наставников не откатывается: отметки неотличимы от поставленных наставником
+SELECT 1;
@@ -0,0 +1,9 @@
+-- =========================
+-- ПОДТВЕРЖДЕНИЕ НАСТАВНИКОВ
+-- =========================
+-- квалификация любого уровня действует только после подтверждения;
+-- наставники, назначенные до этого правила, считаются подтвержденными на дату назначения
+UPDATE user_stages
+SET signed_off_at = COALESCE(created_at, NOW())
+WHERE qualification_level = 3
+  AND signed_off_at IS NULL;
@@ -0,0 +1,9 @@
+DROP INDEX IF EXISTS uq_instance_reworks_hold;
+
+DELETE FROM instance_reworks WHERE inspection_id IS NULL;
+
+ALTER TABLE instance_reworks
+    DROP CONSTRAINT IF EXISTS chk_instance_reworks_source,
+    DROP CONSTRAINT IF EXISTS fk_instance_reworks_holds,
+    DROP COLUMN IF EXISTS hold_id,
+    ALTER COLUMN inspection_id SET NOT NULL;
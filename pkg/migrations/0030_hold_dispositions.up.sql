@@ -0,0 +1,11 @@
+-- =========================
+-- РЕШЕНИЯ ПРИ СНЯТИИ БЛОКИРОВКИ
+-- =========================
+-- Доработка назначается по браковочному результату контроля или при снятии блокировки с решением rework
+ALTER TABLE instance_reworks
+    ALTER COLUMN inspection_id DROP NOT NULL,
+    ADD COLUMN hold_id BIGINT,
+    ADD CONSTRAINT fk_instance_reworks_holds FOREIGN KEY(hold_id) REFERENCES holds(id),
+    ADD CONSTRAINT chk_instance_reworks_source CHECK (inspection_id IS NOT NULL OR hold_id IS NOT NULL);
+
+CREATE UNIQUE INDEX uq_instance_reworks_hold ON instance_reworks(hold_id, product_instance_id);
@@ -0,0 +1,9 @@
+package pkg
+
+// EqualPtr сравнивает значения по указателям; два nil равны, nil и не nil — нет
+func EqualPtr[T comparable](a, b *T) bool {
+	if a == nil || b == nil {
+		return a == nil && b == nil
+	}
+	return *a == *b
+}